@@ -2,25 +2,53 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"strconv"
+	"sync"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/api/grpcserver"
 	"github.com/mibrahim2344/notification-service/internal/api/handlers"
 	apiservices "github.com/mibrahim2344/notification-service/internal/api/services"
 	"github.com/mibrahim2344/notification-service/internal/application/notification"
+	templateloader "github.com/mibrahim2344/notification-service/internal/application/template"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
 	"github.com/mibrahim2344/notification-service/internal/infrastructure/db"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/events/kafka"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/providers/apns"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/providers/mock"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/providers/sandbox"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/providers/sendgrid"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/providers/ses"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/providers/twilio"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/providers/whatsapp"
 	"github.com/mibrahim2344/notification-service/internal/infrastructure/repositories/postgres"
+	redisrepo "github.com/mibrahim2344/notification-service/internal/infrastructure/repositories/redis"
 	"go.uber.org/zap"
 )
 
 func main() {
 	// Initialize logger
-	logger, _ := zap.NewProduction()
+	logConfig := logging.DefaultConfig()
+	logConfig.Level = getEnv("LOG_LEVEL", logConfig.Level)
+	logConfig.Format = getEnv("LOG_FORMAT", logConfig.Format)
+	logger, err := logging.NewLogger(logConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
 	defer logger.Sync()
 
 	// Initialize database connection
@@ -31,6 +59,7 @@ func main() {
 	dbConfig.Password = getEnv("DB_PASSWORD", dbConfig.Password)
 	dbConfig.DBName = getEnv("DB_NAME", dbConfig.DBName)
 	dbConfig.SSLMode = getEnv("DB_SSLMODE", dbConfig.SSLMode)
+	dbConfig.ApplicationName = getEnv("DB_APPLICATION_NAME", db.ApplicationNameWithHost(dbConfig.ApplicationName))
 
 	// Configure connection pool based on environment
 	dbConfig.MaxOpenConns = getEnvAsInt("DB_MAX_OPEN_CONNS", dbConfig.MaxOpenConns)
@@ -38,6 +67,11 @@ func main() {
 	dbConfig.ConnMaxLifetime = getEnvAsDuration("DB_CONN_MAX_LIFETIME", dbConfig.ConnMaxLifetime)
 	dbConfig.ConnMaxIdleTime = getEnvAsDuration("DB_CONN_MAX_IDLE_TIME", dbConfig.ConnMaxIdleTime)
 
+	// Configure how long to wait for the database at startup
+	dbConfig.ConnectTimeout = getEnvAsDuration("DB_CONNECT_TIMEOUT", dbConfig.ConnectTimeout)
+	dbConfig.ConnectRetryInterval = getEnvAsDuration("DB_CONNECT_RETRY_INTERVAL", dbConfig.ConnectRetryInterval)
+	dbConfig.Logger = logger
+
 	database, err := db.NewPostgresDB(dbConfig)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
@@ -45,35 +79,518 @@ func main() {
 	defer db.Close(database)
 
 	// Initialize health checker
-	healthChecker := db.NewHealthChecker(database, 30*time.Second, 5*time.Second)
+	healthChecker := db.NewHealthChecker(database, 30*time.Second, 5*time.Second, logger)
 	healthChecker.Start()
 	defer healthChecker.Stop()
 
 	// Initialize repositories
-	notificationRepo := postgres.NewNotificationRepository(database)
-	templateRepo := postgres.NewTemplateRepository(database)
+	postgresNotificationRepo, err := postgres.NewNotificationRepository(database)
+	if err != nil {
+		logger.Fatal("Failed to prepare notification repository", zap.Error(err))
+	}
+	defer postgresNotificationRepo.Close()
+	var notificationRepo repository.NotificationRepository = postgresNotificationRepo
+	var templateRepo interface {
+		repository.TemplateRepository
+		services.TemplateEngine
+	} = postgres.NewTemplateRepository(database)
+	suppressionRepo := postgres.NewSuppressionRepository(database)
+
+	// Cache template lookups in Redis if enabled
+	if getEnvAsBool("TEMPLATE_CACHE_ENABLED", false) {
+		redisClient, err := redisrepo.NewRedisClient(redisConfig())
+		if err != nil {
+			logger.Fatal("Failed to connect to Redis", zap.Error(err))
+		}
+		defer redisClient.Close()
+
+		ttl := getEnvAsDuration("TEMPLATE_CACHE_TTL", redisrepo.DefaultTemplateCacheTTL)
+		templateRepo = redisrepo.NewCachedTemplateRepository(templateRepo, redisClient, ttl, getEnv("REDIS_NAMESPACE", ""))
+	}
+
+	// Load templates kept as files, e.g. checked out from git, on startup and on demand
+	var templateSyncer handlers.TemplateSyncer
+	if dir := getEnv("TEMPLATES_DIR", ""); dir != "" {
+		loader := templateloader.NewLoader(templateRepo, dir, logger)
+		templateSyncer = loader
+		if getEnvAsBool("TEMPLATES_SYNC_ON_STARTUP", false) {
+			result, err := loader.Sync(context.Background())
+			if err != nil {
+				logger.Fatal("Failed to sync templates", zap.Error(err))
+			}
+			for _, failed := range result.Failed {
+				logger.Warn("Failed to sync template file", zap.String("file", failed.File), zap.String("error", failed.Error))
+			}
+		}
+	}
+
+	// Render templates from the database, or straight from TEMPLATES_DIR with
+	// TEMPLATE_ENGINE=files, reloading the files as they change
+	var templateEngine services.TemplateEngine = templateRepo
+	switch engine := getEnv("TEMPLATE_ENGINE", "database"); engine {
+	case "database":
+	case "files":
+		dir := getEnv("TEMPLATES_DIR", "")
+		if dir == "" {
+			logger.Fatal("TEMPLATE_ENGINE=files requires TEMPLATES_DIR")
+		}
+		fileEngine, err := templateloader.NewFileEngine(dir, logger)
+		if err != nil {
+			logger.Fatal("Failed to load template files", zap.Error(err))
+		}
+		reloadCtx, stopReloading := context.WithCancel(context.Background())
+		defer stopReloading()
+		go fileEngine.Run(reloadCtx, getEnvAsDuration("TEMPLATES_RELOAD_INTERVAL", templateloader.DefaultReloadInterval))
+		templateEngine = fileEngine
+	default:
+		logger.Fatal("Unknown TEMPLATE_ENGINE, expected database or files", zap.String("engine", engine))
+	}
+
+	// Queue notifications in the transactional outbox unless disabled
+	var outbox services.NotificationOutbox
+	if getEnvAsBool("OUTBOX_ENABLED", true) {
+		outbox = postgres.NewOutboxRepository(database)
+	}
+
+	// The mock provider only records what it is asked to send, so the service
+	// runs end to end without external services; select it per channel, e.g.
+	// EMAIL_PROVIDER=mock
+	mockSelected := false
+	for _, key := range []string{"EMAIL_PROVIDER", "SMS_PROVIDER", "PUSH_PROVIDER", "WHATSAPP_PROVIDER"} {
+		mockSelected = mockSelected || getEnv(key, "") == mock.ProviderName
+	}
+	var mockProvider *mock.Provider
+	if mockSelected {
+		mockConfig := mock.DefaultConfig()
+		mockConfig.Capacity = getEnvAsInt("MOCK_PROVIDER_CAPACITY", mockConfig.Capacity)
+		mockConfig.FilePath = getEnv("MOCK_PROVIDER_FILE", "")
+
+		var err error
+		if mockProvider, err = mock.NewProvider(mockConfig, logger); err != nil {
+			logger.Fatal("Failed to initialize mock provider", zap.Error(err))
+		}
+		defer mockProvider.Close()
+		logger.Warn("The mock provider is selected: notifications it is given are recorded, not sent")
+	}
+
+	// Send email through Amazon SES when selected
+	var emailProvider services.EmailProvider
+	switch getEnv("EMAIL_PROVIDER", "") {
+	case mock.ProviderName:
+		emailProvider = mockProvider
+	case ses.ProviderName:
+		sesConfig := ses.DefaultConfig()
+		sesConfig.Region = getEnv("SES_REGION", sesConfig.Region)
+		sesConfig.Senders = getEnvAsSenders("SES_FROM_ADDRESS", "EMAIL_FROM_NAME", "EMAIL_SENDERS")
+		sesConfig.ConfigurationSetName = getEnv("SES_CONFIGURATION_SET", sesConfig.ConfigurationSetName)
+		sesConfig.DKIM.Domain = getEnv("DKIM_DOMAIN", "")
+		sesConfig.DKIM.Selector = getEnv("DKIM_SELECTOR", "")
+		if keyPath := getEnv("DKIM_PRIVATE_KEY_PATH", ""); keyPath != "" {
+			key, err := os.ReadFile(keyPath)
+			if err != nil {
+				logger.Fatal("Failed to read DKIM private key", zap.Error(err))
+			}
+			sesConfig.DKIM.PrivateKey = key
+		}
+
+		sesProvider, err := ses.NewProviderFromEnvironment(context.Background(), sesConfig)
+		if err != nil {
+			logger.Fatal("Failed to initialize SES provider", zap.Error(err))
+		}
+		emailProvider = sesProvider
+	}
+
+	// SMS is only sent through the mock provider for now
+	var smsProvider services.SMSProvider
+	if getEnv("SMS_PROVIDER", "") == mock.ProviderName {
+		smsProvider = mockProvider
+	}
+
+	// Send push notifications to iOS devices through APNs when configured
+	var pushProvider services.PushProvider
+	if getEnv("PUSH_PROVIDER", "") == mock.ProviderName {
+		pushProvider = mockProvider
+	} else if keyPath := getEnv("APNS_KEY_PATH", ""); keyPath != "" {
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			logger.Fatal("Failed to read APNs signing key", zap.Error(err))
+		}
+
+		apnsConfig := apns.DefaultConfig()
+		apnsConfig.PrivateKey = key
+		apnsConfig.KeyID = getEnv("APNS_KEY_ID", "")
+		apnsConfig.TeamID = getEnv("APNS_TEAM_ID", "")
+		apnsConfig.BundleID = getEnv("APNS_BUNDLE_ID", "")
+		apnsConfig.Environment = getEnv("APNS_ENVIRONMENT", apnsConfig.Environment)
+
+		apnsProvider, err := apns.NewProvider(apnsConfig)
+		if err != nil {
+			logger.Fatal("Failed to initialize APNs provider", zap.Error(err))
+		}
+		pushProvider = apnsProvider
+	}
+
+	// Send WhatsApp notifications through the Cloud API when configured
+	var whatsAppProvider services.WhatsAppProvider
+	if getEnv("WHATSAPP_PROVIDER", "") == mock.ProviderName {
+		whatsAppProvider = mockProvider
+	} else if token := getEnv("WHATSAPP_ACCESS_TOKEN", ""); token != "" {
+		whatsAppConfig := whatsapp.DefaultConfig()
+		whatsAppConfig.AccessToken = token
+		whatsAppConfig.PhoneNumberID = getEnv("WHATSAPP_PHONE_NUMBER_ID", "")
+		whatsAppConfig.BaseURL = getEnv("WHATSAPP_API_URL", whatsAppConfig.BaseURL)
+		whatsAppProvider = whatsapp.NewProvider(whatsAppConfig)
+	}
+
+	// In sandbox mode every send is redirected to catch-all recipients, so a
+	// non-production environment never reaches real users
+	var sandboxRecipients *model.Sandbox
+	if getEnvAsBool("SANDBOX_ENABLED", false) {
+		sandboxRecipients = &model.Sandbox{
+			Email:     getEnv("SANDBOX_EMAIL", ""),
+			Phone:     getEnv("SANDBOX_PHONE", ""),
+			PushToken: getEnv("SANDBOX_PUSH_TOKEN", ""),
+		}
+		if emailProvider != nil {
+			emailProvider = sandbox.NewEmailProvider(emailProvider, *sandboxRecipients)
+		}
+		if smsProvider != nil {
+			smsProvider = sandbox.NewSMSProvider(smsProvider, *sandboxRecipients)
+		}
+		if pushProvider != nil {
+			pushProvider = sandbox.NewPushProvider(pushProvider, *sandboxRecipients)
+		}
+		if whatsAppProvider != nil {
+			whatsAppProvider = sandbox.NewWhatsAppProvider(whatsAppProvider, *sandboxRecipients)
+		}
+		logger.Warn("SANDBOX MODE IS ON: every notification is redirected to a catch-all recipient and channels without one are not sent",
+			zap.String("email", sandboxRecipients.Email),
+			zap.String("phone", sandboxRecipients.Phone),
+			zap.Bool("push", sandboxRecipients.PushToken != ""),
+		)
+	}
 
 	// Initialize services
 	notificationService := notification.NewService(
 		notificationRepo,
-		nil, // email provider
-		nil, // sms provider
-		nil, // push provider
-		templateRepo,
+		emailProvider,
+		smsProvider,
+		pushProvider,
+		whatsAppProvider,
+		templateEngine,
+		outbox,
+		suppressionRepo,
 		logger,
 	)
+	notificationService.SetDryRun(getEnvAsBool("DRY_RUN", false))
+	notificationService.SetSandbox(sandboxRecipients)
+	notificationService.SetEmailSubjectPrefix(getEnv("EMAIL_SUBJECT_PREFIX", ""))
+
+	// Content limits are set per channel, e.g. CONTENT_MAX_LENGTH_SMS and CONTENT_POLICY_SMS
+	contentLimits := notification.DefaultContentLimits()
+	for _, notificationType := range []model.NotificationType{model.EmailNotification, model.SMSNotification, model.PushNotification} {
+		suffix := strings.ToUpper(string(notificationType))
+		limit := contentLimits[notificationType]
+		limit.MaxLength = getEnvAsInt("CONTENT_MAX_LENGTH_"+suffix, limit.MaxLength)
+		limit.Policy = notification.ContentPolicy(getEnv("CONTENT_POLICY_"+suffix, string(limit.Policy)))
+		if !limit.Policy.IsValid() {
+			logger.Fatal("Invalid content policy", zap.String("type", string(notificationType)), zap.String("policy", string(limit.Policy)))
+		}
+		contentLimits[notificationType] = limit
+	}
+	notificationService.SetContentLimits(contentLimits)
+	notificationService.SetMaxSMSSegments(getEnvAsInt("SMS_MAX_SEGMENTS", notification.DefaultMaxSMSSegments))
+	notificationService.SetRecipientNormalizer(model.RecipientNormalizer{StripGmailDots: getEnvAsBool("RECIPIENT_STRIP_GMAIL_DOTS", false)})
+	// A/B test event templates per type, e.g. TEMPLATE_VARIANTS=welcome_email=recipient,password_reset=weight
+	if variants := getEnvAsTemplateVariants("TEMPLATE_VARIANTS"); len(variants) > 0 {
+		for templateType, selection := range variants {
+			if !selection.IsValid() {
+				logger.Fatal("Invalid template variant selection", zap.String("type", string(templateType)), zap.String("selection", string(selection)))
+			}
+		}
+		notificationService.SetTemplateVariantSelector(templateloader.NewVariantSelector(templateRepo, variants))
+	}
+	// Event templates are rendered in the locale the event or caller prefers when a translation
+	// exists, e.g. TEMPLATE_LOCALES=fr,de-DE for welcome.fr.html, and in DEFAULT_LOCALE otherwise
+	notificationService.SetLocales(model.Locales{
+		Default:   getEnv("DEFAULT_LOCALE", model.DefaultLocale),
+		Available: getEnvAsList("TEMPLATE_LOCALES"),
+	})
+	// Rendering an event's template is bounded so a pathological template can't hold up a worker
+	notificationService.SetRenderTimeout(getEnvAsDuration("TEMPLATE_RENDER_TIMEOUT", notification.DefaultRenderTimeout))
+	// Each provider's health check gives up after PROVIDER_HEALTH_TIMEOUT, e.g. 3s
+	notificationService.SetProviderHealthTimeout(getEnvAsDuration("PROVIDER_HEALTH_TIMEOUT", notification.DefaultProviderHealthTimeout))
+	// Missing event templates fail the event unless its type falls back, e.g. TEMPLATE_FALLBACK_PASSWORD_RESET=plain
+	defaultFallback := getEnv("TEMPLATE_FALLBACK", string(notification.TemplateFallbackFail))
+	for _, templateType := range []model.TemplateType{model.WelcomeEmail, model.AccountActivation, model.PasswordReset, model.PasswordChanged} {
+		fallback := notification.TemplateFallback(getEnv("TEMPLATE_FALLBACK_"+strings.ToUpper(string(templateType)), defaultFallback))
+		if !fallback.IsValid() {
+			logger.Fatal("Invalid template fallback", zap.String("type", string(templateType)), zap.String("fallback", string(fallback)))
+		}
+		notificationService.SetTemplateFallback(templateType, fallback)
+	}
+	// Notifications without a priority get their channel's default, e.g. DEFAULT_PRIORITY_SMS=high, and
+	// priorities a channel disallows are rejected, e.g. DISALLOWED_PRIORITIES_SMS=low
+	defaultPriority := getEnv("DEFAULT_PRIORITY", string(model.PriorityMedium))
+	for _, notificationType := range model.NotificationTypes {
+		suffix := strings.ToUpper(string(notificationType))
+		priority := model.Priority(getEnv("DEFAULT_PRIORITY_"+suffix, defaultPriority))
+		if !priority.IsValid() {
+			logger.Fatal("Invalid default priority", zap.String("type", string(notificationType)), zap.String("priority", string(priority)))
+		}
+		notificationService.SetDefaultPriority(notificationType, priority)
+
+		var disallowed []model.Priority
+		for _, item := range getEnvAsList("DISALLOWED_PRIORITIES_" + suffix) {
+			if !model.Priority(item).IsValid() || model.Priority(item) == priority {
+				logger.Fatal("Invalid disallowed priority", zap.String("type", string(notificationType)), zap.String("priority", item))
+			}
+			disallowed = append(disallowed, model.Priority(item))
+		}
+		notificationService.SetDisallowedPriorities(notificationType, disallowed)
+	}
+	// Sends are paced per channel to the provider's quota, e.g. RATE_LIMIT_SMS=10 messages per second
+	for _, notificationType := range model.NotificationTypes {
+		if perSecond := getEnvAsFloat("RATE_LIMIT_"+strings.ToUpper(string(notificationType)), 0); perSecond > 0 {
+			notificationService.SetRateLimiter(notificationType, notification.NewRateLimiter(string(notificationType), perSecond))
+		}
+	}
+	// Emergency brake: pause sending once more than SEND_LIMIT_PER_MINUTE notifications arrive in a minute
+	if perMinute := getEnvAsInt("SEND_LIMIT_PER_MINUTE", 0); perMinute > 0 {
+		notificationService.SetSendLimit(notification.NewSendLimit(perMinute))
+	}
+	// Categories such as marketing only go out in their send window, e.g. SEND_WINDOWS="marketing=mon-fri 09:00-17:00 America/New_York"
+	if windows, err := getEnvAsSendWindows("SEND_WINDOWS"); err != nil {
+		logger.Fatal("Invalid SEND_WINDOWS", zap.Error(err))
+	} else if len(windows) > 0 {
+		if outbox == nil {
+			logger.Fatal("SEND_WINDOWS requires the outbox to defer notifications; set OUTBOX_ENABLED=true")
+		}
+		notificationService.SetSendWindows(notification.NewSendWindows(windows))
+	}
+	// announcement.published events are fanned out BROADCAST_PAGE_SIZE recipients at a time, resuming after a restart
+	notificationService.SetBroadcastProgress(postgres.NewBroadcastProgressRepository(database))
+	notificationService.SetBroadcastPageSize(getEnvAsInt("BROADCAST_PAGE_SIZE", notification.DefaultBroadcastPageSize))
+	// Provider calls are bounded per channel, e.g. SEND_TIMEOUT_EMAIL=5s, falling back to SEND_TIMEOUT
+	sendTimeout := getEnvAsDuration("SEND_TIMEOUT", 30*time.Second)
+	for _, notificationType := range model.NotificationTypes {
+		notificationService.SetSendTimeout(notificationType, getEnvAsDuration("SEND_TIMEOUT_"+strings.ToUpper(string(notificationType)), sendTimeout))
+	}
+	if finder, ok := notificationRepo.(repository.NotificationMetadataFinder); ok {
+		notificationService.SetMetadataFinder(finder)
+	}
+	if finder, ok := notificationRepo.(repository.NotificationCategoryFinder); ok {
+		notificationService.SetCategoryFinder(finder)
+	}
+	if finder, ok := notificationRepo.(repository.NotificationThreadFinder); ok {
+		notificationService.SetThreadFinder(finder)
+	}
+	// Threaded emails get Message-IDs under the domain they are sent from unless EMAIL_MESSAGE_ID_DOMAIN is set
+	_, fromDomain, _ := strings.Cut(getEnv("SES_FROM_ADDRESS", ""), "@")
+	notificationService.SetMessageIDDomain(getEnv("EMAIL_MESSAGE_ID_DOMAIN", fromDomain))
+	if scanner, ok := notificationRepo.(repository.NotificationScanner); ok {
+		notificationService.SetNotificationScanner(scanner)
+	}
+	if purger, ok := notificationRepo.(repository.NotificationPurger); ok {
+		notificationService.SetNotificationPurger(purger)
+	}
+	if eraser, ok := notificationRepo.(repository.NotificationEraser); ok {
+		notificationService.SetNotificationEraser(eraser)
+	}
+	if counter, ok := notificationRepo.(repository.NotificationStatsCounter); ok {
+		notificationService.SetStatsCounter(counter, getEnvAsDuration("STATS_CACHE_TTL", notification.DefaultStatsCacheTTL))
+	}
+
+	// Send queued notifications and handle events concurrently, in order per recipient
+	kafkaBrokers := getEnvAsList("KAFKA_BROKERS")
+	var pool *notification.WorkerPool
+	if outbox != nil || len(kafkaBrokers) > 0 {
+		poolConfig := notification.DefaultWorkerPoolConfig()
+		poolConfig.Workers = getEnvAsInt("DISPATCH_WORKERS", poolConfig.Workers)
+		poolConfig.QueueSize = getEnvAsInt("DISPATCH_QUEUE_SIZE", poolConfig.QueueSize)
+		pool = notification.NewWorkerPool(poolConfig, logger)
+	}
+
+	// Start the outbox dispatcher
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	defer stopDispatcher()
+
+	// Jobs run by one instance at a time, see LEADER_ELECTION_ENABLED
+	var leaderJobs []func(ctx context.Context)
+	if outbox != nil {
+		outboxConfig := notification.DefaultOutboxConfig()
+		outboxConfig.PollInterval = getEnvAsDuration("OUTBOX_POLL_INTERVAL", outboxConfig.PollInterval)
+		outboxConfig.BatchSize = getEnvAsInt("OUTBOX_BATCH_SIZE", outboxConfig.BatchSize)
+		outboxConfig.BatchPause = getEnvAsDuration("OUTBOX_BATCH_PAUSE", outboxConfig.BatchPause)
+		outboxConfig.Lease = getEnvAsDuration("OUTBOX_LEASE", outboxConfig.Lease)
+		outboxConfig.MaxAttempts = getEnvAsInt("OUTBOX_MAX_ATTEMPTS", outboxConfig.MaxAttempts)
+		outboxConfig.MaxRetryAge = getEnvAsDuration("OUTBOX_MAX_RETRY_AGE", outboxConfig.MaxRetryAge)
+
+		dispatcher := notification.NewOutboxDispatcher(notificationService, outbox, pool, outboxConfig, logger)
+
+		// Claim new entries as soon as the database announces them; polling
+		// catches any announcement that is missed
+		if getEnvAsBool("OUTBOX_LISTEN_ENABLED", true) {
+			listener, err := postgres.NewOutboxListener(db.ConnectionString(dbConfig), logger)
+			if err != nil {
+				logger.Fatal("Failed to listen for outbox entries", zap.Error(err))
+			}
+			defer listener.Close()
+			go listener.Run(dispatcherCtx)
+			dispatcher.SetWakeups(listener.Wakeups())
+		}
+		if getEnvAsBool("OUTBOX_LEADER_ONLY", false) {
+			leaderJobs = append(leaderJobs, dispatcher.Run)
+		} else {
+			go dispatcher.Run(dispatcherCtx)
+		}
+	}
+
+	// Consume user events from Kafka when brokers are configured, waiting for
+	// them at startup unless KAFKA_CONNECT_FAIL_FAST is set
+	var consumer *kafka.Consumer
+	if len(kafkaBrokers) > 0 {
+		retryConfig := kafka.DefaultConnectRetryConfig()
+		retryConfig.MaxAttempts = getEnvAsInt("KAFKA_CONNECT_MAX_ATTEMPTS", retryConfig.MaxAttempts)
+		retryConfig.Timeout = getEnvAsDuration("KAFKA_CONNECT_TIMEOUT", retryConfig.Timeout)
+		retryConfig.InitialInterval = getEnvAsDuration("KAFKA_CONNECT_RETRY_INTERVAL", retryConfig.InitialInterval)
+		retryConfig.MaxInterval = getEnvAsDuration("KAFKA_CONNECT_MAX_RETRY_INTERVAL", retryConfig.MaxInterval)
+		if getEnvAsBool("KAFKA_CONNECT_FAIL_FAST", false) {
+			retryConfig.MaxAttempts = 1
+		}
+
+		topics := getEnvAsList("KAFKA_TOPICS")
+		if len(topics) == 0 {
+			topics = []string{"user-events"}
+		}
+		consumer, err = kafka.NewConsumer(
+			kafkaBrokers,
+			getEnv("KAFKA_GROUP_ID", "notification-service"),
+			topics,
+			kafkaClientConfig(),
+			retryConfig,
+			notificationService,
+			pool,
+			logger,
+		)
+		if err != nil {
+			logger.Fatal("Failed to create Kafka consumer", zap.Error(err))
+		}
+		if topic := getEnv("KAFKA_DLQ_TOPIC", ""); topic != "" {
+			deadLetters, err := kafka.NewDeadLetterProducer(kafkaBrokers, kafkaClientConfig(), topic)
+			if err != nil {
+				logger.Fatal("Failed to create Kafka dead-letter producer", zap.Error(err))
+			}
+			defer deadLetters.Close()
+			consumer.SetDeadLetters(deadLetters)
+		}
+		go func() {
+			if err := consumer.Start(); err != nil {
+				logger.Error("Failed to start Kafka consumer", zap.Error(err))
+			}
+		}()
+	}
+
+	// Keep the notification status gauge in line with the database
+	counter, canCount := notificationRepo.(metrics.StatusCounter)
+	if interval := getEnvAsDuration("STATUS_RECONCILE_INTERVAL", time.Minute); interval > 0 && canCount {
+		reconciler := metrics.NewStatusReconciler(counter, interval, logger)
+		leaderJobs = append(leaderJobs, reconciler.Run)
+	}
+
+	// Run the single-instance jobs here, or only while this instance is the
+	// leader when several replicas are deployed
+	runLeaderJobs := func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for _, job := range leaderJobs {
+			wg.Add(1)
+			go func(job func(ctx context.Context)) {
+				defer wg.Done()
+				job(ctx)
+			}(job)
+		}
+		wg.Wait()
+	}
+	if getEnvAsBool("LEADER_ELECTION_ENABLED", false) {
+		redisClient, err := redisrepo.NewRedisClient(redisConfig())
+		if err != nil {
+			logger.Fatal("Failed to connect to Redis", zap.Error(err))
+		}
+		defer redisClient.Close()
+
+		lock := redisrepo.NewLeaderLock(redisClient, getEnv("REDIS_NAMESPACE", ""))
+		elector := notification.NewLeaderElector(lock, getEnvAsDuration("LEADER_TTL", notification.DefaultLeaderTTL), logger)
+		go elector.Run(dispatcherCtx, runLeaderJobs)
+	} else {
+		metrics.SetLeader(true)
+		go runLeaderJobs(dispatcherCtx)
+	}
 
 	// Initialize adapter and handlers
 	notificationServiceAdapter := apiservices.NewNotificationServiceAdapter(notificationService)
 	notificationHandler := handlers.NewNotificationHandler(notificationServiceAdapter, logger)
+	adminHandler := handlers.NewAdminHandler(notificationServiceAdapter, logger)
+	adminHandler.SetOperatorKeys(getEnvAsList("OPERATOR_KEYS"))
+	if mockProvider != nil {
+		adminHandler.SetSentMessages(mockProvider)
+	}
+	if len(kafkaBrokers) > 0 {
+		replayer, err := kafka.NewReplayer(kafkaBrokers, kafkaClientConfig(), notificationService, logger)
+		if err != nil {
+			logger.Fatal("Failed to create Kafka event replayer", zap.Error(err))
+		}
+		defer replayer.Close()
+		adminHandler.SetEventReplayer(replayer)
+	}
+	templateHandler := handlers.NewTemplateHandler(templateRepo, templateSyncer, logger)
+	templateHandler.SetPreviewer(notificationService)
+	suppressionHandler := handlers.NewSuppressionHandler(suppressionRepo, logger)
+
+	// Accept delivery webhooks from the providers whose signatures we can verify
+	webhooks := make(map[string]handlers.DeliveryWebhook)
+	if key := getEnv("SENDGRID_WEBHOOK_VERIFICATION_KEY", ""); key != "" {
+		sendGridWebhook, err := sendgrid.NewWebhook(key)
+		if err != nil {
+			logger.Fatal("Failed to initialize SendGrid webhook", zap.Error(err))
+		}
+		webhooks[sendgrid.ProviderName] = sendGridWebhook
+	}
+	if topicARN := getEnv("SES_WEBHOOK_TOPIC_ARN", ""); topicARN != "" {
+		webhooks[ses.ProviderName] = ses.NewWebhook(topicARN)
+	}
+	if token := getEnv("TWILIO_AUTH_TOKEN", ""); token != "" {
+		webhooks[twilio.ProviderName] = twilio.NewWebhook(token, getEnv("TWILIO_WEBHOOK_BASE_URL", ""))
+	}
+	webhookHandler := handlers.NewWebhookHandler(notificationServiceAdapter, webhooks, logger)
+	apiKeys := getEnvAsAPIKeys("API_KEYS")
+
+	// Initialize HTTP server. Slow or oversize requests are cut off so a client
+	// can't tie up connections or memory.
+	maxBodyBytes := int64(getEnvAsInt("HTTP_MAX_REQUEST_BODY_BYTES", handlers.DefaultMaxRequestBodyBytes))
+
+	// Browsers may only call the API from the origins listed; none by default
+	corsConfig := handlers.DefaultCORSConfig()
+	corsConfig.AllowedOrigins = getEnvAsList("CORS_ALLOWED_ORIGINS")
+	if methods := getEnvAsList("CORS_ALLOWED_METHODS"); len(methods) > 0 {
+		corsConfig.AllowedMethods = methods
+	}
+	if headers := getEnvAsList("CORS_ALLOWED_HEADERS"); len(headers) > 0 {
+		corsConfig.AllowedHeaders = headers
+	}
+	corsConfig.AllowCredentials = getEnvAsBool("CORS_ALLOW_CREDENTIALS", false)
+	corsConfig.MaxAge = getEnvAsDuration("CORS_MAX_AGE", corsConfig.MaxAge)
+	if err := corsConfig.Validate(); err != nil {
+		logger.Fatal("Invalid CORS configuration", zap.Error(err))
+	}
 
-	// Initialize HTTP server
 	server := &http.Server{
-		Addr:         ":8080",
-		Handler:      setupRoutes(notificationHandler),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              ":8080",
+		Handler:           setupRoutes(notificationHandler, adminHandler, templateHandler, suppressionHandler, webhookHandler, apiKeys, maxBodyBytes, corsConfig, logger),
+		ReadTimeout:       getEnvAsDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+		ReadHeaderTimeout: getEnvAsDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		WriteTimeout:      getEnvAsDuration("HTTP_WRITE_TIMEOUT", 15*time.Second),
+		IdleTimeout:       getEnvAsDuration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+		MaxHeaderBytes:    getEnvAsInt("HTTP_MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes),
 	}
 
 	// Start server in a goroutine
@@ -84,6 +601,21 @@ func main() {
 		}
 	}()
 
+	// Initialize gRPC server
+	grpcServer := grpcserver.NewGRPCServer(notificationServiceAdapter, apiKeys, logger)
+	grpcAddr := fmt.Sprintf(":%d", getEnvAsInt("GRPC_PORT", 9090))
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		logger.Fatal("Failed to listen for gRPC", zap.Error(err))
+	}
+
+	go func() {
+		logger.Info("Starting gRPC server", zap.String("addr", grpcAddr))
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Fatal("Failed to start gRPC server", zap.Error(err))
+		}
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -91,16 +623,68 @@ func main() {
 
 	// Shutdown gracefully
 	logger.Info("Shutting down server...")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
 	defer cancel()
 
+	// Stop taking events, then stop claiming outbox entries and finish sending
+	// the claimed ones. Entries still queued at the deadline keep their
+	// notification pending and are sent again once their lease expires.
+	if consumer != nil {
+		if err := consumer.Stop(); err != nil {
+			logger.Warn("Failed to stop Kafka consumer", zap.Error(err))
+		}
+	}
+	stopDispatcher()
+	if pool != nil {
+		if err := pool.Shutdown(ctx); err != nil {
+			logger.Warn("Dispatch queue not drained before shutdown deadline", zap.Error(err))
+		}
+	}
+
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Let in-flight RPCs finish, but don't wait past the shutdown deadline
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+
 	logger.Info("Server stopped")
 }
 
+// redisConfig reads the Redis connection settings from the environment
+func redisConfig() *redisrepo.Config {
+	return &redisrepo.Config{
+		Mode:             getEnv("REDIS_MODE", redisrepo.ModeStandalone),
+		Host:             getEnv("REDIS_HOST", "localhost"),
+		Port:             getEnvAsInt("REDIS_PORT", 6379),
+		Addrs:            getEnvAsList("REDIS_ADDRS"),
+		MasterName:       getEnv("REDIS_SENTINEL_MASTER", ""),
+		Password:         getEnv("REDIS_PASSWORD", ""),
+		SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+		DB:               getEnvAsInt("REDIS_DB", 0),
+	}
+}
+
+func kafkaClientConfig() kafka.ClientConfig {
+	return kafka.ClientConfig{
+		Version:       getEnv("KAFKA_VERSION", ""),
+		SASLMechanism: getEnv("KAFKA_SASL_MECHANISM", ""),
+		SASLUsername:  getEnv("KAFKA_SASL_USERNAME", ""),
+		SASLPassword:  getEnv("KAFKA_SASL_PASSWORD", ""),
+		TLSEnabled:    getEnvAsBool("KAFKA_TLS_ENABLED", false),
+		TLSCAFile:     getEnv("KAFKA_TLS_CA_FILE", ""),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -126,8 +710,136 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
-func setupRoutes(notificationHandler *handlers.NotificationHandler) http.Handler {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/notifications", notificationHandler.SendNotification)
-	return mux
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList parses a comma-separated list, skipping empty entries
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvAsAPIKeys parses a comma-separated list of "tenant:key" pairs into a key -> tenant map
+func getEnvAsAPIKeys(key string) map[string]string {
+	apiKeys := make(map[string]string)
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return apiKeys
+	}
+	for _, pair := range strings.Split(value, ",") {
+		tenantID, apiKey, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && tenantID != "" && apiKey != "" {
+			apiKeys[apiKey] = tenantID
+		}
+	}
+	return apiKeys
+}
+
+// getEnvAsTemplateVariants parses a comma-separated list of "type=selection"
+// pairs. Unknown selections are kept so that validation reports them at startup.
+func getEnvAsTemplateVariants(key string) map[model.TemplateType]model.VariantSelection {
+	variants := make(map[model.TemplateType]model.VariantSelection)
+	for _, pair := range getEnvAsList(key) {
+		templateType, selection, _ := strings.Cut(pair, "=")
+		if templateType = strings.TrimSpace(templateType); templateType != "" {
+			variants[model.TemplateType(templateType)] = model.VariantSelection(strings.TrimSpace(selection))
+		}
+	}
+	return variants
+}
+
+// getEnvAsSenders builds the email sender identities from a default address, its
+// display name and a comma-separated list of "category=Name <address>" overrides.
+// Malformed entries are kept so that validation reports them at startup.
+func getEnvAsSenders(addressKey, nameKey, categoriesKey string) model.SenderResolver {
+	senders := model.SenderResolver{
+		Default:    model.Sender{Name: getEnv(nameKey, ""), Address: getEnv(addressKey, "")},
+		Categories: make(map[string]model.Sender),
+	}
+	value, exists := os.LookupEnv(categoriesKey)
+	if !exists {
+		return senders
+	}
+	for _, pair := range strings.Split(value, ",") {
+		category, address, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if category == "" {
+			continue
+		}
+		sender, err := model.ParseSender(address)
+		if err != nil {
+			sender = model.Sender{Address: address}
+		}
+		senders.Categories[category] = sender
+	}
+	return senders
+}
+
+// getEnvAsSendWindows parses a semicolon-separated list of "category=window"
+// pairs, each window as model.ParseSendWindow reads it
+func getEnvAsSendWindows(key string) (map[string]model.SendWindow, error) {
+	windows := make(map[string]model.SendWindow)
+	for _, pair := range strings.Split(os.Getenv(key), ";") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		category, value, _ := strings.Cut(pair, "=")
+		window, err := model.ParseSendWindow(value)
+		if err != nil {
+			return nil, fmt.Errorf("send window of %q: %w", strings.TrimSpace(category), err)
+		}
+		windows[strings.TrimSpace(category)] = window
+	}
+	return windows, nil
+}
+
+func setupRoutes(
+	notificationHandler *handlers.NotificationHandler,
+	adminHandler *handlers.AdminHandler,
+	templateHandler *handlers.TemplateHandler,
+	suppressionHandler *handlers.SuppressionHandler,
+	webhookHandler *handlers.WebhookHandler,
+	apiKeys map[string]string,
+	maxBodyBytes int64,
+	corsConfig handlers.CORSConfig,
+	logger *zap.Logger,
+) http.Handler {
+	r := chi.NewRouter()
+	r.Use(handlers.RequestIDMiddleware)
+	r.Use(handlers.PreferredLocalesMiddleware)
+	r.Use(handlers.RecoverPanics(logger))
+	// Preflights carry no API key, so they're answered before authentication
+	r.Use(handlers.CORS(corsConfig))
+	r.Use(handlers.LimitRequestBody(maxBodyBytes))
+
+	// Provider webhooks are authenticated by their signatures, not API keys
+	webhookHandler.RegisterRoutes(r)
+
+	r.Group(func(r chi.Router) {
+		r.Use(handlers.TenantMiddleware(apiKeys))
+		notificationHandler.RegisterRoutes(r)
+		adminHandler.RegisterRoutes(r)
+		templateHandler.RegisterRoutes(r)
+		suppressionHandler.RegisterRoutes(r)
+	})
+	return r
 }
@@ -0,0 +1,241 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/api/grpcserver/notificationpb"
+	"github.com/mibrahim2344/notification-service/internal/api/handlers"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultListLimit matches the page size used by the HTTP list endpoint
+const defaultListLimit = 10
+
+// Server implements the gRPC NotificationService on top of the same service used by the HTTP handlers
+type Server struct {
+	notificationpb.UnimplementedNotificationServiceServer
+	notificationService handlers.NotificationService
+	validate            *validator.Validate
+	logger              *zap.Logger
+}
+
+// NewServer creates a new gRPC notification server
+func NewServer(service handlers.NotificationService, logger *zap.Logger) *Server {
+	return &Server{
+		notificationService: service,
+		validate:            handlers.NewValidator(),
+		logger:              logger,
+	}
+}
+
+// NewGRPCServer creates a gRPC server with the notification service and tenant scoping registered
+func NewGRPCServer(service handlers.NotificationService, apiKeys map[string]string, logger *zap.Logger) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(RequestIDInterceptor, TenantInterceptor(apiKeys)))
+	notificationpb.RegisterNotificationServiceServer(server, NewServer(service, logger))
+	return server
+}
+
+// RequestIDInterceptor gives every call a correlation ID, like the HTTP
+// middleware: the x-request-id metadata value if it is usable, a new one otherwise
+func RequestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := firstValue(md, handlers.RequestIDHeader)
+	if !model.IsValidCorrelationID(requestID) {
+		requestID = uuid.NewString()
+	}
+	return handler(model.ContextWithCorrelationID(ctx, requestID), req)
+}
+
+// TenantInterceptor scopes every call to a tenant using the same rules as the HTTP middleware,
+// reading the API key and tenant ID from the call metadata
+func TenantInterceptor(apiKeys map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		tenantID, err := handlers.ResolveTenant(apiKeys, firstValue(md, handlers.APIKeyHeader), firstValue(md, handlers.TenantHeader))
+		if errors.Is(err, handlers.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return handler(model.ContextWithTenant(ctx, tenantID), req)
+	}
+}
+
+// SendNotification validates and dispatches a notification
+func (s *Server) SendNotification(ctx context.Context, req *notificationpb.SendNotificationRequest) (*notificationpb.Notification, error) {
+	start := time.Now()
+	operation := "send_notification"
+
+	notification, err := handlers.BuildNotification(s.validate, handlers.SendNotificationRequest{
+		Recipient:    req.GetRecipient(),
+		Type:         req.GetType(),
+		Subject:      req.GetSubject(),
+		Content:      req.GetContent(),
+		Priority:     req.GetPriority(),
+		TemplateID:   req.GetTemplateId(),
+		TemplateData: model.NewTemplateData(req.GetTemplateData()),
+		Metadata:     req.GetMetadata(),
+	})
+	if err != nil {
+		logging.WithContext(ctx, s.logger).Error("invalid request", zap.Error(err))
+		metrics.RecordOperationDuration("grpc_"+operation, "error", time.Since(start).Seconds())
+		return nil, invalidArgument(err)
+	}
+
+	if err := s.notificationService.SendNotification(ctx, notification); err != nil {
+		logging.WithContext(ctx, s.logger).Error("failed to send notification",
+			zap.Error(err),
+			zap.String("recipient", req.GetRecipient()),
+			zap.String("type", req.GetType()),
+		)
+		metrics.RecordOperationDuration("grpc_"+operation, "error", time.Since(start).Seconds())
+		return nil, status.Error(codeForError(err), "failed to send notification")
+	}
+
+	metrics.RecordOperationDuration("grpc_"+operation, "success", time.Since(start).Seconds())
+	return toProto(notification), nil
+}
+
+// GetNotification retrieves a notification by ID
+func (s *Server) GetNotification(ctx context.Context, req *notificationpb.GetNotificationRequest) (*notificationpb.Notification, error) {
+	start := time.Now()
+	operation := "get_notification"
+
+	if req.GetId() == "" {
+		metrics.RecordOperationDuration("grpc_"+operation, "error", time.Since(start).Seconds())
+		return nil, status.Error(codes.InvalidArgument, "notification ID is required")
+	}
+
+	notification, err := s.notificationService.GetNotification(ctx, req.GetId())
+	if err != nil {
+		if code := codeForError(err); code == codes.NotFound {
+			metrics.RecordOperationDuration("grpc_"+operation, "not_found", time.Since(start).Seconds())
+			return nil, status.Error(code, "notification not found")
+		}
+		logging.WithContext(ctx, s.logger).Error("failed to get notification",
+			zap.Error(err),
+			zap.String("id", req.GetId()),
+		)
+		metrics.RecordOperationDuration("grpc_"+operation, "error", time.Since(start).Seconds())
+		return nil, status.Error(codeForError(err), "failed to get notification")
+	}
+
+	metrics.RecordOperationDuration("grpc_"+operation, "success", time.Since(start).Seconds())
+	return toProto(notification), nil
+}
+
+// ListNotificationsByRecipient retrieves a page of a recipient's notifications
+func (s *Server) ListNotificationsByRecipient(ctx context.Context, req *notificationpb.ListNotificationsByRecipientRequest) (*notificationpb.ListNotificationsByRecipientResponse, error) {
+	start := time.Now()
+	operation := "get_notifications_by_recipient"
+
+	if req.GetRecipient() == "" {
+		metrics.RecordOperationDuration("grpc_"+operation, "error", time.Since(start).Seconds())
+		return nil, status.Error(codes.InvalidArgument, "recipient is required")
+	}
+
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > handlers.MaxAdminPageSize {
+		limit = handlers.MaxAdminPageSize
+	}
+	offset := int(req.GetOffset())
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, err := s.notificationService.GetNotificationsByRecipient(ctx, req.GetRecipient(), model.SortDescending, limit, offset)
+	if err != nil {
+		logging.WithContext(ctx, s.logger).Error("failed to get notifications",
+			zap.Error(err),
+			zap.String("recipient", req.GetRecipient()),
+		)
+		metrics.RecordOperationDuration("grpc_"+operation, "error", time.Since(start).Seconds())
+		return nil, status.Error(codeForError(err), "failed to get notifications")
+	}
+
+	response := &notificationpb.ListNotificationsByRecipientResponse{
+		Notifications: make([]*notificationpb.Notification, 0, len(notifications)),
+	}
+	for _, notification := range notifications {
+		response.Notifications = append(response.Notifications, toProto(notification))
+	}
+
+	metrics.RecordOperationDuration("grpc_"+operation, "success", time.Since(start).Seconds())
+	return response, nil
+}
+
+// toProto converts a notification into its gRPC representation
+func toProto(notification *model.Notification) *notificationpb.Notification {
+	return &notificationpb.Notification{
+		Id:            notification.ID.String(),
+		Recipient:     notification.Recipient,
+		Type:          string(notification.Type),
+		Subject:       notification.Subject,
+		Content:       notification.Content,
+		Status:        string(notification.Status),
+		Priority:      string(notification.Priority),
+		ErrorMessage:  notification.ErrorMessage,
+		Category:      notification.Category,
+		CorrelationId: notification.CorrelationID,
+		Metadata:      notification.Metadata,
+		CreatedAt:     timestamppb.New(notification.CreatedAt),
+		UpdatedAt:     timestamppb.New(notification.UpdatedAt),
+	}
+}
+
+// invalidArgument reports request validation failures, listing each failing field
+func invalidArgument(err error) error {
+	fields := handlers.FieldErrors(err)
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+	return status.Error(codes.InvalidArgument, "validation failed: "+strings.Join(messages, "; "))
+}
+
+// codeForError maps a service error to a gRPC code using the HTTP handler's classification
+func codeForError(err error) codes.Code {
+	switch handlers.StatusForError(err) {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// firstValue returns the first metadata value for a header, matching gRPC's lowercase keys
+func firstValue(md metadata.MD, header string) string {
+	if values := md.Get(strings.ToLower(header)); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
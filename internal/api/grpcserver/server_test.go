@@ -0,0 +1,351 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/api/grpcserver/notificationpb"
+	"github.com/mibrahim2344/notification-service/internal/api/handlers"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MockNotificationService is a mock implementation of handlers.NotificationService
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) SendNotification(ctx context.Context, notification *model.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) GetNotification(ctx context.Context, id string) (*model.Notification, error) {
+	args := m.Called(ctx, id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, nil
+	}
+	return args.Get(0).(*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error) {
+	args := m.Called(ctx, ids)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, recipient, order, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, filters, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, category, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, correlationID, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) ExportNotifications(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error {
+	args := m.Called(ctx, recipient, from, to)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) RetryNotification(ctx context.Context, id string) (*model.Notification, error) {
+	args := m.Called(ctx, id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Notification), nil
+}
+
+func (m *MockNotificationService) ResendNotification(ctx context.Context, id, recipient string) (*model.Notification, error) {
+	args := m.Called(ctx, id, recipient)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Notification), nil
+}
+
+func (m *MockNotificationService) SendMultiChannel(ctx context.Context, notification *model.Notification, targets []model.ChannelTarget) (*model.NotificationGroup, error) {
+	args := m.Called(ctx, notification, targets)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NotificationGroup), nil
+}
+
+func (m *MockNotificationService) GetNotificationGroup(ctx context.Context, groupID string) ([]*model.Notification, error) {
+	args := m.Called(ctx, groupID)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func TestServer_SendNotification(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      *notificationpb.SendNotificationRequest
+		setupMock    func(*MockNotificationService)
+		expectedCode codes.Code
+	}{
+		{
+			name: "successful notification send",
+			request: &notificationpb.SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+				Priority:  "high",
+			},
+			setupMock: func(m *MockNotificationService) {
+				m.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "invalid request",
+			request: &notificationpb.SendNotificationRequest{
+				Type: "email",
+			},
+			setupMock:    func(m *MockNotificationService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "service error",
+			request: &notificationpb.SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+				Priority:  "high",
+			},
+			setupMock: func(m *MockNotificationService) {
+				m.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(errors.New("service error"))
+			},
+			expectedCode: codes.Internal,
+		},
+		{
+			name: "provider unavailable",
+			request: &notificationpb.SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+				Priority:  "high",
+			},
+			setupMock: func(m *MockNotificationService) {
+				err := model.ErrProviderFailure{Type: model.EmailNotification, Err: errors.New("smtp timeout")}
+				m.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(err)
+			},
+			expectedCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			tt.setupMock(mockService)
+			server := NewServer(mockService, zap.NewNop())
+
+			resp, err := server.SendNotification(context.Background(), tt.request)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, tt.request.Recipient, resp.Recipient)
+				assert.Equal(t, string(model.StatusPending), resp.Status)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetNotification(t *testing.T) {
+	notificationID := uuid.New()
+	notification := &model.Notification{
+		ID:            notificationID,
+		Recipient:     "test@example.com",
+		Type:          model.EmailNotification,
+		Status:        model.StatusFailed,
+		Priority:      model.PriorityHigh,
+		ErrorMessage:  "mailbox full",
+		Category:      model.CategorySecurity,
+		CorrelationID: "order-42",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	tests := []struct {
+		name         string
+		id           string
+		setupMock    func(*MockNotificationService)
+		expectedCode codes.Code
+	}{
+		{
+			name: "successful get",
+			id:   notificationID.String(),
+			setupMock: func(m *MockNotificationService) {
+				m.On("GetNotification", mock.Anything, notificationID.String()).Return(notification, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "not found",
+			id:   notificationID.String(),
+			setupMock: func(m *MockNotificationService) {
+				m.On("GetNotification", mock.Anything, notificationID.String()).Return(nil, model.ErrNotificationNotFound{ID: notificationID.String()})
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "missing id",
+			setupMock:    func(m *MockNotificationService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			tt.setupMock(mockService)
+			server := NewServer(mockService, zap.NewNop())
+
+			resp, err := server.GetNotification(context.Background(), &notificationpb.GetNotificationRequest{Id: tt.id})
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, notificationID.String(), resp.Id)
+				assert.Equal(t, string(model.StatusFailed), resp.Status)
+				assert.Equal(t, string(model.PriorityHigh), resp.Priority)
+				assert.Equal(t, "mailbox full", resp.ErrorMessage)
+				assert.Equal(t, model.CategorySecurity, resp.Category)
+				assert.Equal(t, "order-42", resp.CorrelationId)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ListNotificationsByRecipient_Limit(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         int32
+		expectedLimit int
+	}{
+		{name: "default", limit: 0, expectedLimit: defaultListLimit},
+		{name: "requested", limit: 25, expectedLimit: 25},
+		{name: "clamped", limit: 100000, expectedLimit: handlers.MaxAdminPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			mockService.On("GetNotificationsByRecipient", mock.Anything, "test@example.com", model.SortDescending, tt.expectedLimit, 0).
+				Return([]*model.Notification{}, nil)
+			server := NewServer(mockService, zap.NewNop())
+
+			_, err := server.ListNotificationsByRecipient(context.Background(), &notificationpb.ListNotificationsByRecipientRequest{
+				Recipient: "test@example.com",
+				Limit:     tt.limit,
+			})
+
+			require.NoError(t, err)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTenantInterceptor(t *testing.T) {
+	apiKeys := map[string]string{"secret-key": "acme"}
+
+	tests := []struct {
+		name           string
+		apiKeys        map[string]string
+		md             metadata.MD
+		expectedCode   codes.Code
+		expectedTenant string
+	}{
+		{
+			name:           "default tenant without keys",
+			md:             metadata.MD{},
+			expectedCode:   codes.OK,
+			expectedTenant: model.DefaultTenantID,
+		},
+		{
+			name:           "tenant from metadata",
+			md:             metadata.Pairs("x-tenant-id", "acme"),
+			expectedCode:   codes.OK,
+			expectedTenant: "acme",
+		},
+		{
+			name:         "malformed tenant",
+			md:           metadata.Pairs("x-tenant-id", "acme:evil"),
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:           "tenant from api key",
+			apiKeys:        apiKeys,
+			md:             metadata.Pairs("x-api-key", "secret-key", "x-tenant-id", "other"),
+			expectedCode:   codes.OK,
+			expectedTenant: "acme",
+		},
+		{
+			name:         "unknown api key",
+			apiKeys:      apiKeys,
+			md:           metadata.Pairs("x-api-key", "wrong"),
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenantID string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				tenantID = model.TenantFromContext(ctx)
+				return nil, nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := TenantInterceptor(tt.apiKeys)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedTenant, tenantID)
+		})
+	}
+}
@@ -0,0 +1,582 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAdminPageSize = 50
+	// MaxAdminPageSize is the most items a list request returns; larger limits are clamped to it
+	MaxAdminPageSize = 500
+
+	defaultStatsWindow = 24 * time.Hour
+	maxStatsWindow     = 30 * 24 * time.Hour
+)
+
+// AdminHandler handles operator-facing HTTP requests
+type AdminHandler struct {
+	adminService AdminService
+	operatorKeys []string
+	sentMessages SentMessageRecorder
+	replayer     EventReplayer
+	logger       *zap.Logger
+}
+
+// AdminService defines the interface for operator notification queries
+type AdminService interface {
+	GetNotificationsByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error)
+	RetryFailedSince(ctx context.Context, since time.Time) (retried, failed int, err error)
+	PurgeNotificationsBefore(ctx context.Context, cutoff time.Time) (int, error)
+	ExportRecipientData(ctx context.Context, recipient string) (*model.RecipientData, error)
+	EraseRecipientData(ctx context.Context, recipient string, dryRun bool) (*model.RecipientErasure, error)
+	GetNotificationStats(ctx context.Context, window time.Duration) (*model.NotificationStats, error)
+	GetSendLimitState() (*model.SendLimitState, error)
+	ClearSendLimit() (*model.SendLimitState, error)
+	CheckProviderHealth(ctx context.Context) []model.ProviderHealth
+}
+
+// SentMessageRecorder keeps the messages a recording provider, such as the
+// mock provider, pretended to send
+type SentMessageRecorder interface {
+	SentMessages() []model.SentMessage
+	ClearSentMessages()
+}
+
+// EventReplayer handles a range of user events from the event stream again
+type EventReplayer interface {
+	Replay(ctx context.Context, rng model.EventReplayRange, options model.EventReplayOptions) (model.EventReplayResult, error)
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(service AdminService, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		adminService: service,
+		logger:       logger,
+	}
+}
+
+// SetOperatorKeys sets the keys accepted for actions affecting every tenant,
+// such as clearing the send limit. It must be called before RegisterRoutes.
+func (h *AdminHandler) SetOperatorKeys(keys []string) {
+	h.operatorKeys = keys
+}
+
+// SetSentMessages sets where GET /admin/outbox reads the messages a recording
+// provider pretended to send; without one, the outbox is reported as unavailable
+func (h *AdminHandler) SetSentMessages(recorder SentMessageRecorder) {
+	h.sentMessages = recorder
+}
+
+// SetEventReplayer sets what POST /admin/events/replay replays user events
+// with; without one, replaying is reported as unavailable
+func (h *AdminHandler) SetEventReplayer(replayer EventReplayer) {
+	h.replayer = replayer
+}
+
+// AdminNotificationResponse represents a notification with its delivery diagnostics
+type AdminNotificationResponse struct {
+	NotificationResponse
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// AdminNotificationListResponse represents a page of notifications
+type AdminNotificationListResponse struct {
+	Notifications []AdminNotificationResponse `json:"notifications"`
+	Limit         int                         `json:"limit"`
+	Offset        int                         `json:"offset"`
+}
+
+// RetryFailedResponse reports the outcome of a bulk retry
+type RetryFailedResponse struct {
+	Retried int `json:"retried"`
+	Failed  int `json:"failed"`
+}
+
+// PurgeNotificationsResponse reports the outcome of a purge
+type PurgeNotificationsResponse struct {
+	Before  time.Time `json:"before"`
+	Deleted int       `json:"deleted"`
+}
+
+// NotificationStatsResponse summarizes the notifications created within a window
+type NotificationStatsResponse struct {
+	Window     string                             `json:"window"`
+	Since      time.Time                          `json:"since"`
+	Total      int64                              `json:"total"`
+	ByStatus   map[model.NotificationStatus]int64 `json:"by_status"`
+	ByType     map[model.NotificationType]int64   `json:"by_type"`
+	ByPriority map[model.Priority]int64           `json:"by_priority"`
+	SendLimit  *model.SendLimitState              `json:"send_limit,omitempty"`
+}
+
+// ProviderHealthResponse reports the health of each channel's provider
+type ProviderHealthResponse struct {
+	// Healthy is false if any provider was found unreachable, unauthorized or
+	// unhealthy; unchecked providers don't count against it
+	Healthy   bool                                            `json:"healthy"`
+	Providers map[model.NotificationType]model.ProviderHealth `json:"providers"`
+}
+
+// SentMessagesResponse lists the messages a recording provider pretended to send
+type SentMessagesResponse struct {
+	Messages []model.SentMessage `json:"messages"`
+}
+
+// EventReplayRequest selects the user events to replay. A partition is read
+// from from_offset or since, or else its oldest event, through to_offset or up
+// to until, or else its newest event.
+type EventReplayRequest struct {
+	Topic      string    `json:"topic"`
+	Partitions []int32   `json:"partitions,omitempty"`
+	FromOffset *int64    `json:"from_offset,omitempty"`
+	ToOffset   *int64    `json:"to_offset,omitempty"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+	DryRun     bool      `json:"dry_run"`
+	Force      bool      `json:"force"`
+}
+
+// EventReplayResponse reports the outcome of an event replay
+type EventReplayResponse struct {
+	model.EventReplayResult
+	DryRun bool `json:"dry_run"`
+}
+
+// RegisterRoutes registers the admin routes
+func (h *AdminHandler) RegisterRoutes(r chi.Router) {
+	r = r.With(NegotiateContentType(JSONMediaType))
+	r.Get("/admin/notifications", h.ListNotifications)
+	r.With(RequireAuthentication).Delete("/admin/notifications", h.PurgeNotifications)
+	r.With(RequireAuthentication).Post("/admin/notifications/retry-failed", h.RetryFailed)
+	r.With(RequireAuthentication).Get("/admin/recipients/{recipient}/export", h.ExportRecipientData)
+	r.With(RequireAuthentication).Delete("/admin/recipients/{recipient}", h.EraseRecipientData)
+	r.Get("/admin/stats", h.GetStats)
+	r.With(RequireOperator(h.operatorKeys)).Post("/admin/send-limit/clear", h.ClearSendLimit)
+	r.With(RequireOperator(h.operatorKeys)).Get("/admin/providers/health", h.GetProviderHealth)
+	r.With(RequireOperator(h.operatorKeys)).Post("/admin/events/replay", h.ReplayEvents)
+	r.Get("/admin/outbox", h.ListSentMessages)
+	r.Delete("/admin/outbox", h.ClearSentMessages)
+}
+
+// ListNotifications handles the request to list notifications in a given status, e.g. ?status=failed
+func (h *AdminHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_list_notifications"
+
+	status := model.NotificationStatus(r.URL.Query().Get("status"))
+	if !status.IsValid() {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A valid status is required", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset, ok := parsePagination(r, defaultAdminPageSize, MaxAdminPageSize)
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Invalid limit or offset", http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := h.adminService.GetNotificationsByStatus(r.Context(), status, limit, offset)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to list notifications",
+			zap.Error(err),
+			zap.String("status", string(status)),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to list notifications", err)
+		return
+	}
+
+	response := AdminNotificationListResponse{
+		Notifications: make([]AdminNotificationResponse, 0, len(notifications)),
+		Limit:         limit,
+		Offset:        offset,
+	}
+	for _, notification := range notifications {
+		response.Notifications = append(response.Notifications, AdminNotificationResponse{
+			NotificationResponse: newNotificationResponse(notification),
+			ErrorMessage:         notification.ErrorMessage,
+		})
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// RetryFailed handles the request to retry every notification that failed since a point in time,
+// given as an RFC 3339 timestamp in the since query parameter
+func (h *AdminHandler) RetryFailed(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_retry_failed"
+
+	since, err := time.Parse(time.RFC3339, r.URL.Query().Get("since"))
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "since must be an RFC 3339 timestamp", http.StatusBadRequest)
+		return
+	}
+
+	retried, failed, err := h.adminService.RetryFailedSince(r.Context(), since)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to retry notifications",
+			zap.Error(err),
+			zap.Time("since", since),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to retry notifications", err)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("retried failed notifications",
+		zap.Time("since", since),
+		zap.Int("retried", retried),
+		zap.Int("failed", failed),
+	)
+
+	if err := writeResponse(w, RetryFailedResponse{Retried: retried, Failed: failed}, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// PurgeNotifications handles the request to delete every notification created
+// before the before query parameter, an RFC 3339 timestamp or a date such as
+// 2025-01-31. The cutoff may not be in the future, so a typo can't delete
+// notifications still being sent.
+func (h *AdminHandler) PurgeNotifications(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_purge_notifications"
+
+	cutoff, err := parseCutoff(r.URL.Query().Get("before"))
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "before must be an RFC 3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	if cutoff.After(start) {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "before may not be in the future", http.StatusBadRequest)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("purging notifications",
+		zap.String("tenant_id", model.TenantFromContext(r.Context())),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.Time("before", cutoff),
+	)
+
+	deleted, err := h.adminService.PurgeNotificationsBefore(r.Context(), cutoff)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to purge notifications",
+			zap.Error(err),
+			zap.Time("before", cutoff),
+			zap.Int("deleted", deleted),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to purge notifications", err)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("purged notifications",
+		zap.String("tenant_id", model.TenantFromContext(r.Context())),
+		zap.Time("before", cutoff),
+		zap.Int("deleted", deleted),
+	)
+
+	if err := writeResponse(w, PurgeNotificationsResponse{Before: cutoff, Deleted: deleted}, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// parseCutoff reads a point in time given as an RFC 3339 timestamp or as a
+// date, which stands for midnight UTC at its start
+func parseCutoff(value string) (time.Time, error) {
+	if cutoff, err := time.Parse(time.RFC3339, value); err == nil {
+		return cutoff, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// GetStats handles the request to count recent notifications by status, type and
+// priority, over a window given as a duration in the window query parameter, e.g. ?window=1h
+func (h *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_get_stats"
+
+	window := defaultStatsWindow
+	if value := r.URL.Query().Get("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxStatsWindow {
+			metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+			writeError(w, "window must be a positive duration of at most 720h", http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+
+	stats, err := h.adminService.GetNotificationStats(r.Context(), window)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to get notification stats",
+			zap.Error(err),
+			zap.Duration("window", window),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to get notification stats", err)
+		return
+	}
+
+	response := NotificationStatsResponse{
+		Window:     window.String(),
+		Since:      stats.Since,
+		Total:      stats.Total,
+		ByStatus:   stats.ByStatus,
+		ByType:     stats.ByType,
+		ByPriority: stats.ByPriority,
+	}
+	// The send limit is live, not part of the cached counts
+	if state, err := h.adminService.GetSendLimitState(); err == nil {
+		response.SendLimit = state
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ClearSendLimit handles the request to let notifications through again after the send limit tripped
+func (h *AdminHandler) ClearSendLimit(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_clear_send_limit"
+
+	requestLogger(h.logger, r).Info("clearing send limit",
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	state, err := h.adminService.ClearSendLimit()
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to clear send limit", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to clear send limit", err)
+		return
+	}
+
+	if err := writeResponse(w, state, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// GetProviderHealth handles the request to check that every channel's provider
+// is reachable and accepts its credentials, without sending anything. Provider
+// accounts are shared by every tenant, so it takes an operator key.
+func (h *AdminHandler) GetProviderHealth(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_provider_health"
+
+	response := ProviderHealthResponse{
+		Healthy:   true,
+		Providers: make(map[model.NotificationType]model.ProviderHealth),
+	}
+	for _, health := range h.adminService.CheckProviderHealth(r.Context()) {
+		response.Providers[health.Type] = health
+		if !health.Healthy() {
+			response.Healthy = false
+			requestLogger(h.logger, r).Warn("provider health check failed",
+				zap.String("type", string(health.Type)),
+				zap.String("provider", health.Provider),
+				zap.String("status", string(health.Status)),
+				zap.String("error", health.Error),
+			)
+		}
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ReplayEvents handles the request to handle a range of user events again,
+// e.g. after an outage kept them from producing their notifications. Events
+// that already produced one are skipped. Events are read from every tenant,
+// so it takes an operator key. The response is written once the replay
+// finishes, however long that takes.
+func (h *AdminHandler) ReplayEvents(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_replay_events"
+
+	if h.replayer == nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Replaying events requires Kafka", http.StatusNotImplemented)
+		return
+	}
+
+	var req EventReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestLogger(h.logger, r).Error("failed to decode request body", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeBodyError(w, err)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("replaying events",
+		zap.String("topic", req.Topic),
+		zap.Bool("dry_run", req.DryRun),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	// A long replay would otherwise outlive the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	result, err := h.replayer.Replay(r.Context(), model.EventReplayRange{
+		Topic:      req.Topic,
+		Partitions: req.Partitions,
+		FromOffset: req.FromOffset,
+		ToOffset:   req.ToOffset,
+		Since:      req.Since,
+		Until:      req.Until,
+	}, model.EventReplayOptions{DryRun: req.DryRun, Force: req.Force})
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to replay events",
+			zap.Error(err),
+			zap.Int("replayed", result.Replayed),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to replay events", err)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("replayed events",
+		zap.Int("replayed", result.Replayed),
+		zap.Int("already_sent", result.AlreadySent),
+		zap.Int("unguarded", result.Unguarded),
+		zap.Int("failed", result.Failed),
+	)
+
+	if err := writeResponse(w, EventReplayResponse{EventReplayResult: result, DryRun: req.DryRun}, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ListSentMessages handles the request to list the tenant's messages the
+// recording provider pretended to send, oldest first, optionally only those of a
+// type or to a recipient, e.g. ?type=email&to=user@example.com
+func (h *AdminHandler) ListSentMessages(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_list_sent_messages"
+
+	if h.sentMessages == nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "The outbox is only available with the mock provider", http.StatusNotImplemented)
+		return
+	}
+
+	tenantID := model.TenantFromContext(r.Context())
+	notificationType := model.NotificationType(r.URL.Query().Get("type"))
+	recipient := r.URL.Query().Get("to")
+
+	response := SentMessagesResponse{Messages: []model.SentMessage{}}
+	for _, message := range h.sentMessages.SentMessages() {
+		if message.TenantID != tenantID ||
+			(notificationType != "" && message.Type != notificationType) ||
+			(recipient != "" && message.To != recipient) {
+			continue
+		}
+		response.Messages = append(response.Messages, message)
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ClearSentMessages handles the request to forget every message the recording
+// provider pretended to send, e.g. between integration tests
+func (h *AdminHandler) ClearSentMessages(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_clear_sent_messages"
+
+	if h.sentMessages == nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "The outbox is only available with the mock provider", http.StatusNotImplemented)
+		return
+	}
+
+	h.sentMessages.ClearSentMessages()
+	w.WriteHeader(http.StatusNoContent)
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// parsePagination reads the limit and offset query parameters, applying the default and
+// maximum page size. It reports false if either parameter is malformed or negative.
+func parsePagination(r *http.Request, defaultLimit, maxLimit int) (limit, offset int, ok bool) {
+	limit = defaultLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, false
+		}
+		limit = parsed
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	if value := r.URL.Query().Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, false
+		}
+		offset = parsed
+	}
+
+	return limit, offset, true
+}
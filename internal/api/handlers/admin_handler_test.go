@@ -0,0 +1,781 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockAdminService is a mock implementation of AdminService
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) GetNotificationsByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, status, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockAdminService) RetryFailedSince(ctx context.Context, since time.Time) (retried, failed int, err error) {
+	args := m.Called(ctx, since)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockAdminService) PurgeNotificationsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAdminService) ExportRecipientData(ctx context.Context, recipient string) (*model.RecipientData, error) {
+	args := m.Called(ctx, recipient)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RecipientData), args.Error(1)
+}
+
+func (m *MockAdminService) EraseRecipientData(ctx context.Context, recipient string, dryRun bool) (*model.RecipientErasure, error) {
+	args := m.Called(ctx, recipient, dryRun)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RecipientErasure), args.Error(1)
+}
+
+func (m *MockAdminService) GetNotificationStats(ctx context.Context, window time.Duration) (*model.NotificationStats, error) {
+	args := m.Called(ctx, window)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NotificationStats), nil
+}
+
+func (m *MockAdminService) GetSendLimitState() (*model.SendLimitState, error) {
+	args := m.Called()
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SendLimitState), nil
+}
+
+func (m *MockAdminService) ClearSendLimit() (*model.SendLimitState, error) {
+	args := m.Called()
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SendLimitState), nil
+}
+
+func (m *MockAdminService) CheckProviderHealth(ctx context.Context) []model.ProviderHealth {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.ProviderHealth)
+}
+
+func TestAdminHandler_ListNotifications(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService, logger)
+
+	failed := &model.Notification{
+		ID:           uuid.New(),
+		Recipient:    "test@example.com",
+		Type:         model.EmailNotification,
+		Status:       model.StatusFailed,
+		ErrorMessage: "provider unavailable",
+		RetryCount:   3,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expectedCount  int
+	}{
+		{
+			name:  "failed notifications with default page",
+			query: "?status=failed",
+			setupMock: func() {
+				mockService.On("GetNotificationsByStatus", mock.Anything, model.StatusFailed, defaultAdminPageSize, 0).Return([]*model.Notification{failed}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:  "explicit page is capped",
+			query: "?status=failed&limit=10000&offset=20",
+			setupMock: func() {
+				mockService.On("GetNotificationsByStatus", mock.Anything, model.StatusFailed, MaxAdminPageSize, 20).Return([]*model.Notification{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing status",
+			query:          "",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "unknown status",
+			query:          "?status=broken",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "invalid limit",
+			query:          "?status=failed&limit=abc",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "service error",
+			query: "?status=failed",
+			setupMock: func() {
+				mockService.On("GetNotificationsByStatus", mock.Anything, model.StatusFailed, defaultAdminPageSize, 0).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodGet, "/admin/notifications"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.ListNotifications(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response AdminNotificationListResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Len(t, response.Notifications, tt.expectedCount)
+				if tt.expectedCount > 0 {
+					assert.Equal(t, "provider unavailable", response.Notifications[0].ErrorMessage)
+					assert.Equal(t, 3, response.Notifications[0].RetryCount)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_RetryFailed(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService, logger)
+
+	since := time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expected       RetryFailedResponse
+	}{
+		{
+			name:  "successful bulk retry",
+			query: "?since=2025-01-07T09:00:00Z",
+			setupMock: func() {
+				mockService.On("RetryFailedSince", mock.Anything, since).Return(4, 1, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expected:       RetryFailedResponse{Retried: 4, Failed: 1},
+		},
+		{
+			name:           "missing since",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "invalid since",
+			query:          "?since=yesterday",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "service error",
+			query: "?since=2025-01-07T09:00:00Z",
+			setupMock: func() {
+				mockService.On("RetryFailedSince", mock.Anything, since).Return(0, 0, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/admin/notifications/retry-failed"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.RetryFailed(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response RetryFailedResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tt.expected, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("requires an API key", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.Calls = nil
+
+		router := chi.NewRouter()
+		router.Use(TenantMiddleware(nil))
+		handler.RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/notifications/retry-failed?since=2025-01-07T09:00:00Z", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockService.AssertNotCalled(t, "RetryFailedSince", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAdminHandler_PurgeNotifications(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService, logger)
+
+	before := time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expected       PurgeNotificationsResponse
+	}{
+		{
+			name:  "timestamp",
+			query: "?before=2025-01-07T09:00:00Z",
+			setupMock: func() {
+				mockService.On("PurgeNotificationsBefore", mock.Anything, before).Return(1200, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expected:       PurgeNotificationsResponse{Before: before, Deleted: 1200},
+		},
+		{
+			name:  "date",
+			query: "?before=2025-01-07",
+			setupMock: func() {
+				mockService.On("PurgeNotificationsBefore", mock.Anything, time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)).Return(0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expected:       PurgeNotificationsResponse{Before: time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:           "missing before",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "future before",
+			query:          "?before=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "store can't purge",
+			query: "?before=2025-01-07T09:00:00Z",
+			setupMock: func() {
+				mockService.On("PurgeNotificationsBefore", mock.Anything, before).Return(0, model.ErrPurgeUnsupported{})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "service error",
+			query: "?before=2025-01-07T09:00:00Z",
+			setupMock: func() {
+				mockService.On("PurgeNotificationsBefore", mock.Anything, before).Return(500, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodDelete, "/admin/notifications"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.PurgeNotifications(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response PurgeNotificationsResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.True(t, tt.expected.Before.Equal(response.Before))
+				assert.Equal(t, tt.expected.Deleted, response.Deleted)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("requires an API key", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.Calls = nil
+
+		router := chi.NewRouter()
+		router.Use(TenantMiddleware(nil))
+		handler.RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodDelete, "/admin/notifications?before=2025-01-07", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockService.AssertNotCalled(t, "PurgeNotificationsBefore", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAdminHandler_GetStats(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService, logger)
+
+	stats := model.NewNotificationStats(time.Date(2025, 1, 13, 8, 0, 0, 0, time.UTC))
+	stats.Total = 5
+	stats.ByStatus[model.StatusSent] = 4
+	stats.ByStatus[model.StatusFailed] = 1
+	stats.ByType[model.EmailNotification] = 5
+	stats.ByPriority[model.PriorityMedium] = 5
+	sendLimit := &model.SendLimitState{PerMinute: 1000, Sent: 1001, Tripped: true}
+
+	tests := []struct {
+		name              string
+		query             string
+		setupMock         func()
+		expectedStatus    int
+		expectedCode      string
+		expectedWindow    string
+		expectedSendLimit *model.SendLimitState
+	}{
+		{
+			name: "default window",
+			setupMock: func() {
+				mockService.On("GetNotificationStats", mock.Anything, 24*time.Hour).Return(stats, nil)
+				mockService.On("GetSendLimitState").Return(sendLimit, nil)
+			},
+			expectedStatus:    http.StatusOK,
+			expectedWindow:    "24h0m0s",
+			expectedSendLimit: sendLimit,
+		},
+		{
+			name:  "custom window without a send limit",
+			query: "?window=1h",
+			setupMock: func() {
+				mockService.On("GetNotificationStats", mock.Anything, time.Hour).Return(stats, nil)
+				mockService.On("GetSendLimitState").Return(nil, model.ErrSendLimitNotConfigured{})
+			},
+			expectedStatus: http.StatusOK,
+			expectedWindow: "1h0m0s",
+		},
+		{
+			name:           "invalid window",
+			query:          "?window=yesterday",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "window too long",
+			query:          "?window=1000h",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name: "stats unsupported",
+			setupMock: func() {
+				mockService.On("GetNotificationStats", mock.Anything, 24*time.Hour).Return(nil, model.ErrStatsUnsupported{})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodGet, "/admin/stats"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.GetStats(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response NotificationStatsResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tt.expectedWindow, response.Window)
+				assert.Equal(t, int64(5), response.Total)
+				assert.Equal(t, int64(4), response.ByStatus[model.StatusSent])
+				assert.Equal(t, int64(0), response.ByStatus[model.StatusBounced])
+				assert.Len(t, response.ByStatus, len(model.NotificationStatuses))
+				assert.Equal(t, int64(5), response.ByPriority[model.PriorityMedium])
+				assert.Equal(t, tt.expectedSendLimit, response.SendLimit)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_ClearSendLimit(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService, logger)
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "cleared",
+			setupMock: func() {
+				mockService.On("ClearSendLimit").Return(&model.SendLimitState{PerMinute: 1000}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "send limit not configured",
+			setupMock: func() {
+				mockService.On("ClearSendLimit").Return(nil, model.ErrSendLimitNotConfigured{})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/admin/send-limit/clear", nil)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.ClearSendLimit(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response model.SendLimitState
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, model.SendLimitState{PerMinute: 1000}, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("requires an operator key", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.Calls = nil
+
+		handler.SetOperatorKeys([]string{"op-1"})
+		router := chi.NewRouter()
+		router.Use(TenantMiddleware(map[string]string{"secret-a": "tenant-a"}))
+		handler.RegisterRoutes(router)
+
+		// A tenant's API key isn't enough to clear the limit for every tenant
+		req := httptest.NewRequest(http.MethodPost, "/admin/send-limit/clear", nil)
+		req.Header.Set(APIKeyHeader, "secret-a")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockService.AssertNotCalled(t, "ClearSendLimit")
+
+		mockService.On("ClearSendLimit").Return(&model.SendLimitState{PerMinute: 1000}, nil)
+		req = httptest.NewRequest(http.MethodPost, "/admin/send-limit/clear", nil)
+		req.Header.Set(APIKeyHeader, "secret-a")
+		req.Header.Set(OperatorKeyHeader, "op-1")
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestAdminHandler_GetProviderHealth(t *testing.T) {
+	mockService := new(MockAdminService)
+	handler := NewAdminHandler(mockService, zap.NewNop())
+	handler.SetOperatorKeys([]string{"op-1"})
+	router := chi.NewRouter()
+	router.Use(TenantMiddleware(map[string]string{"secret-a": "tenant-a"}))
+	handler.RegisterRoutes(router)
+
+	get := func(operatorKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/providers/health", nil)
+		req.Header.Set(APIKeyHeader, "secret-a")
+		if operatorKey != "" {
+			req.Header.Set(OperatorKeyHeader, operatorKey)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("requires an operator key", func(t *testing.T) {
+		rec := get("")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assertErrorCode(t, rec, ErrorCodeUnauthenticated)
+		mockService.AssertNotCalled(t, "CheckProviderHealth", mock.Anything)
+	})
+
+	t.Run("reports each provider", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("CheckProviderHealth", mock.Anything).Return([]model.ProviderHealth{
+			{Type: model.EmailNotification, Provider: "ses", Status: model.ProviderHealthy, LatencyMs: 42},
+			{Type: model.PushNotification, Provider: "apns", Status: model.ProviderUnchecked},
+		})
+
+		rec := get("op-1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response ProviderHealthResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.True(t, response.Healthy)
+		require.Len(t, response.Providers, 2)
+		assert.Equal(t, model.ProviderHealthy, response.Providers[model.EmailNotification].Status)
+		assert.Equal(t, int64(42), response.Providers[model.EmailNotification].LatencyMs)
+	})
+
+	t.Run("unhealthy if any provider fails its check", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.On("CheckProviderHealth", mock.Anything).Return([]model.ProviderHealth{
+			{Type: model.EmailNotification, Provider: "ses", Status: model.ProviderHealthy},
+			{Type: model.WhatsAppNotification, Provider: "whatsapp", Status: model.ProviderUnauthorized, Error: "whatsapp rejected the credentials: Session has expired"},
+		})
+
+		rec := get("op-1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response ProviderHealthResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.False(t, response.Healthy)
+		assert.Equal(t, model.ProviderUnauthorized, response.Providers[model.WhatsAppNotification].Status)
+	})
+}
+
+// memorySentMessages keeps sent messages in memory
+type memorySentMessages struct {
+	messages []model.SentMessage
+}
+
+func (m *memorySentMessages) SentMessages() []model.SentMessage { return m.messages }
+
+func (m *memorySentMessages) ClearSentMessages() { m.messages = nil }
+
+func TestAdminHandler_SentMessages(t *testing.T) {
+	recorder := &memorySentMessages{messages: []model.SentMessage{
+		{ID: "mock-1", TenantID: "tenant-a", Type: model.EmailNotification, To: "user@example.com", Subject: "Welcome"},
+		{ID: "mock-2", TenantID: "tenant-a", Type: model.SMSNotification, To: "+14155552671"},
+		{ID: "mock-3", TenantID: "tenant-b", Type: model.EmailNotification, To: "other@example.com"},
+	}}
+	handler := NewAdminHandler(new(MockAdminService), zap.NewNop())
+	handler.SetSentMessages(recorder)
+	router := chi.NewRouter()
+	router.Use(TenantMiddleware(map[string]string{"secret-a": "tenant-a"}))
+	handler.RegisterRoutes(router)
+
+	list := func(query string) []string {
+		req := httptest.NewRequest(http.MethodGet, "/admin/outbox"+query, nil)
+		req.Header.Set(APIKeyHeader, "secret-a")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response SentMessagesResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		ids := []string{}
+		for _, message := range response.Messages {
+			ids = append(ids, message.ID)
+		}
+		return ids
+	}
+
+	// Only the tenant's own messages are listed
+	assert.Equal(t, []string{"mock-1", "mock-2"}, list(""))
+	assert.Equal(t, []string{"mock-2"}, list("?type=sms"))
+	assert.Equal(t, []string{"mock-1"}, list("?to=user@example.com"))
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/outbox", nil)
+	req.Header.Set(APIKeyHeader, "secret-a")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, list(""))
+
+	t.Run("unavailable without the mock provider", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewAdminHandler(new(MockAdminService), zap.NewNop()).ListSentMessages(rec, httptest.NewRequest(http.MethodGet, "/admin/outbox", nil))
+		assert.Equal(t, http.StatusNotImplemented, rec.Code)
+		assertErrorCode(t, rec, ErrorCodeNotImplemented)
+	})
+}
+
+// recordingReplayer records the replays asked of it
+type recordingReplayer struct {
+	rng     model.EventReplayRange
+	options model.EventReplayOptions
+	result  model.EventReplayResult
+}
+
+func (r *recordingReplayer) Replay(ctx context.Context, rng model.EventReplayRange, options model.EventReplayOptions) (model.EventReplayResult, error) {
+	if err := rng.Validate(); err != nil {
+		return model.EventReplayResult{}, err
+	}
+	r.rng, r.options = rng, options
+	return r.result, nil
+}
+
+func TestAdminHandler_ReplayEvents(t *testing.T) {
+	replayer := &recordingReplayer{result: model.EventReplayResult{Replayed: 3, AlreadySent: 2}}
+	handler := NewAdminHandler(new(MockAdminService), zap.NewNop())
+	handler.SetOperatorKeys([]string{"op-1"})
+	handler.SetEventReplayer(replayer)
+	router := chi.NewRouter()
+	router.Use(TenantMiddleware(map[string]string{"secret-a": "tenant-a"}))
+	handler.RegisterRoutes(router)
+
+	replay := func(body, operatorKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/events/replay", strings.NewReader(body))
+		req.Header.Set(APIKeyHeader, "secret-a")
+		if operatorKey != "" {
+			req.Header.Set(OperatorKeyHeader, operatorKey)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("requires an operator key", func(t *testing.T) {
+		rec := replay(`{"topic":"user-events"}`, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, replayer.rng.Topic)
+	})
+
+	t.Run("replays the range", func(t *testing.T) {
+		rec := replay(`{"topic":"user-events","partitions":[1],"from_offset":10,"until":"2025-01-20T00:00:00Z","dry_run":true}`, "op-1")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response EventReplayResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, EventReplayResponse{EventReplayResult: replayer.result, DryRun: true}, response)
+
+		assert.Equal(t, "user-events", replayer.rng.Topic)
+		assert.Equal(t, []int32{1}, replayer.rng.Partitions)
+		require.NotNil(t, replayer.rng.FromOffset)
+		assert.Equal(t, int64(10), *replayer.rng.FromOffset)
+		assert.Nil(t, replayer.rng.ToOffset)
+		assert.True(t, replayer.rng.Until.Equal(time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, model.EventReplayOptions{DryRun: true}, replayer.options)
+	})
+
+	t.Run("rejects an invalid range", func(t *testing.T) {
+		rec := replay(`{"topic":"user-events","from_offset":10,"since":"2025-01-20T00:00:00Z"}`, "op-1")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assertErrorCode(t, rec, ErrorCodeValidationFailed)
+	})
+
+	t.Run("unavailable without Kafka", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewAdminHandler(new(MockAdminService), zap.NewNop()).ReplayEvents(rec, httptest.NewRequest(http.MethodPost, "/admin/events/replay", nil))
+		assert.Equal(t, http.StatusNotImplemented, rec.Code)
+		assertErrorCode(t, rec, ErrorCodeNotImplemented)
+	})
+}
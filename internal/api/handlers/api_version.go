@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIVersionHeader selects the version of a request body's shape. Requests
+// without it use version 1, so existing clients keep working unchanged.
+const APIVersionHeader = "X-API-Version"
+
+// API versions of the send request. Version 2 adds email content types, CC
+// addresses, inline images and threads; both versions map to the same notification.
+const (
+	APIVersion1 = "1"
+	APIVersion2 = "2"
+)
+
+// ErrUnsupportedAPIVersion is returned when a request asks for an API version that doesn't exist
+type ErrUnsupportedAPIVersion struct {
+	Version string
+}
+
+func (e ErrUnsupportedAPIVersion) Error() string {
+	return fmt.Sprintf("unsupported API version %q, supported versions are %s and %s", e.Version, APIVersion1, APIVersion2)
+}
+
+// RequestAPIVersion returns the API version r asks for, version 1 if it doesn't ask
+func RequestAPIVersion(r *http.Request) (string, error) {
+	version := strings.TrimSpace(r.Header.Get(APIVersionHeader))
+	switch version {
+	case "":
+		return APIVersion1, nil
+	case APIVersion1, APIVersion2:
+		return version, nil
+	}
+	return "", ErrUnsupportedAPIVersion{Version: version}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationHandler_SendNotificationVersions(t *testing.T) {
+	body := `{
+		"recipient": "user@example.com",
+		"type": "email",
+		"subject": "Your invoice",
+		"content": "Invoice attached",
+		"priority": "medium",
+		"content_type": "text/plain",
+		"cc": ["billing@example.com"]
+	}`
+
+	tests := []struct {
+		name            string
+		version         string
+		body            string
+		expectSend      func(*model.Notification) bool
+		expectedStatus  int
+		expectedVersion string
+	}{
+		{
+			name: "defaults to version 1, which ignores version 2 fields",
+			body: body,
+			expectSend: func(n *model.Notification) bool {
+				return n.Recipient == "user@example.com" && n.ContentType == "" && n.CC == nil
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedVersion: APIVersion1,
+		},
+		{
+			name:    "version 2 maps email options",
+			version: APIVersion2,
+			body:    body,
+			expectSend: func(n *model.Notification) bool {
+				return n.ContentType == model.EmailContentText && assert.ObjectsAreEqual([]string{"billing@example.com"}, n.CC)
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedVersion: APIVersion2,
+		},
+		{
+			name:           "version 2 validates version 1 fields",
+			version:        APIVersion2,
+			body:           `{"recipient": "not-an-email", "type": "email", "subject": "s", "content": "c", "priority": "high"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "version 2 validates CC addresses",
+			version:        APIVersion2,
+			body:           `{"recipient": "user@example.com", "type": "email", "subject": "s", "content": "c", "priority": "high", "cc": ["nobody"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "CC only applies to email",
+			version:        APIVersion2,
+			body:           `{"recipient": "+14155552671", "type": "sms", "subject": "s", "content": "c", "priority": "high", "cc": ["billing@example.com"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "version 2 maps inline images",
+			version: APIVersion2,
+			body:    `{"recipient": "user@example.com", "type": "email", "subject": "s", "content": "<img src=\"cid:logo\">", "priority": "high", "inline_images": [{"content_id": "logo", "content_type": "image/png", "data": "iVBORw0K"}]}`,
+			expectSend: func(n *model.Notification) bool {
+				return len(n.InlineImages) == 1 && n.InlineImages[0].ContentID == "logo" && string(n.InlineImages[0].Data) == "\x89PNG\r\n"
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedVersion: APIVersion2,
+		},
+		{
+			name:           "inline images must be images",
+			version:        APIVersion2,
+			body:           `{"recipient": "user@example.com", "type": "email", "subject": "s", "content": "c", "priority": "high", "inline_images": [{"content_id": "logo", "content_type": "text/html", "data": "iVBORw0K"}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "inline images only apply to email",
+			version:        APIVersion2,
+			body:           `{"recipient": "+14155552671", "type": "sms", "subject": "s", "content": "c", "priority": "high", "inline_images": [{"content_id": "logo", "content_type": "image/png", "data": "iVBORw0K"}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "version 2 maps the thread ID",
+			version: APIVersion2,
+			body:    `{"recipient": "user@example.com", "type": "email", "subject": "s", "content": "c", "priority": "high", "thread_id": "order-42"}`,
+			expectSend: func(n *model.Notification) bool {
+				return n.ThreadID == "order-42"
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedVersion: APIVersion2,
+		},
+		{
+			name:           "unknown version",
+			version:        "3",
+			body:           body,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			if tt.expectSend != nil {
+				mockService.On("SendNotification", mock.Anything, mock.MatchedBy(tt.expectSend)).Return(nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/notifications", bytes.NewBufferString(tt.body))
+			if tt.version != "" {
+				req.Header.Set(APIVersionHeader, tt.version)
+			}
+			rec := httptest.NewRecorder()
+			newNotificationRouter(mockService).ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedVersion != "" {
+				assert.Equal(t, tt.expectedVersion, rec.Header().Get(APIVersionHeader))
+			}
+			if tt.expectedVersion == APIVersion2 && tt.body == body {
+				var response NotificationResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, "text/plain", response.ContentType)
+				assert.Equal(t, []string{"billing@example.com"}, response.CC)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
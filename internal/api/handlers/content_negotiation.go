@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types responses are written in
+const (
+	JSONMediaType   = "application/json"
+	CSVMediaType    = "text/csv"
+	NDJSONMediaType = "application/x-ndjson"
+)
+
+// NegotiateContentType rejects requests with 406 Not Acceptable when their
+// Accept header rules out every one of offered, the media types the route
+// responds with. Requests without an Accept header accept anything.
+func NegotiateContentType(offered ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept := r.Header.Values("Accept")
+			if len(accept) > 0 && !acceptsAny(strings.Join(accept, ","), offered) {
+				writeError(w, fmt.Sprintf("Not acceptable: responses are %s", strings.Join(offered, " or ")), http.StatusNotAcceptable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acceptsAny reports whether an Accept header allows any of the media types
+// offered. Media ranges such as "*/*" and "application/*" match every type
+// they cover, media types are compared ignoring case and parameters other than
+// q are ignored. Ranges with q=0 accept nothing; malformed entries are left
+// out, so a header of nothing but malformed entries accepts anything.
+func acceptsAny(header string, offered []string) bool {
+	ranges := 0
+	for _, part := range strings.Split(header, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+		rangeType, rangeSubtype, ok := strings.Cut(mediaRange, "/")
+		if !ok || rangeType == "" || rangeSubtype == "" || (rangeType == "*" && rangeSubtype != "*") {
+			continue
+		}
+		ranges++
+
+		if acceptQuality(params) == 0 {
+			continue
+		}
+		for _, mediaType := range offered {
+			offeredType, offeredSubtype, _ := strings.Cut(mediaType, "/")
+			if (rangeType == "*" || rangeType == offeredType) && (rangeSubtype == "*" || rangeSubtype == offeredSubtype) {
+				return true
+			}
+		}
+	}
+	return ranges == 0
+}
+
+// acceptQuality returns the q parameter among an Accept entry's parameters, 1
+// if it has none or it is malformed
+func acceptQuality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return 1
+		}
+		return quality
+	}
+	return 1
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   []string
+		expected int
+	}{
+		{name: "no Accept header", expected: http.StatusOK},
+		{name: "JSON", accept: []string{"application/json"}, expected: http.StatusOK},
+		{name: "any type", accept: []string{"*/*"}, expected: http.StatusOK},
+		{name: "any application type", accept: []string{"application/*"}, expected: http.StatusOK},
+		{name: "JSON among others", accept: []string{"text/html, application/xml;q=0.9, application/json;q=0.8"}, expected: http.StatusOK},
+		{name: "JSON in a second header", accept: []string{"application/xml", "application/json"}, expected: http.StatusOK},
+		{name: "case and parameters", accept: []string{"Application/JSON; charset=utf-8"}, expected: http.StatusOK},
+		{name: "only malformed entries", accept: []string{"json"}, expected: http.StatusOK},
+		{name: "XML", accept: []string{"application/xml"}, expected: http.StatusNotAcceptable},
+		{name: "another type family", accept: []string{"text/*"}, expected: http.StatusNotAcceptable},
+		{name: "JSON refused", accept: []string{"application/json;q=0"}, expected: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NegotiateContentType(JSONMediaType)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/notifications", nil)
+			for _, accept := range tt.accept {
+				req.Header.Add("Accept", accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusNotAcceptable {
+				assert.JSONEq(t, `{"code": "not_acceptable", "message": "Not acceptable: responses are application/json"}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestNotificationRoutesNegotiateContentType(t *testing.T) {
+	router := chi.NewRouter()
+	NewNotificationHandler(new(MockNotificationService), zap.NewNop()).RegisterRoutes(router)
+
+	// JSON routes refuse CSV, while the export offers it; export requests are
+	// refused further on since they aren't authenticated
+	for path, expected := range map[string]int{
+		"/notifications/42":     http.StatusNotAcceptable,
+		"/notifications/export": http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "text/csv")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, expected, rec.Code, path)
+	}
+}
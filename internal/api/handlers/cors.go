@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig controls which browser origins may call the API. With no allowed
+// origins, the default, browsers are denied every cross-origin request.
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed, such as https://admin.example.com;
+	// "*" allows any origin
+	AllowedOrigins []string
+	// AllowedMethods are the methods cross-origin requests may use
+	AllowedMethods []string
+	// AllowedHeaders are the request headers cross-origin requests may send
+	AllowedHeaders []string
+	// AllowCredentials lets browsers send cookies and authorization with requests
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig returns a config that allows no origins, with the methods
+// and headers the API uses ready for when origins are allowed
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders: []string{"Content-Type", APIKeyHeader, TenantHeader, RequestIDHeader, APIVersionHeader},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// Validate checks the config is safe to serve
+func (c CORSConfig) Validate() error {
+	if c.AllowCredentials && containsFold(c.AllowedOrigins, "*") {
+		return errors.New("CORS credentials can't be allowed for every origin; list the origins instead")
+	}
+	return nil
+}
+
+// CORS answers preflight requests and adds CORS headers to the responses to
+// the allowed origins. Requests from other origins get no CORS headers, so
+// browsers refuse to hand their responses to the page; their preflights are
+// rejected with 403. Requests without an Origin aren't affected.
+func CORS(config CORSConfig) func(http.Handler) http.Handler {
+	allowedMethods := strings.Join(config.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Responses differ by origin, so caches must keep them apart
+			w.Header().Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !config.allowsOrigin(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if containsFold(config.AllowedOrigins, "*") {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader+", "+APIVersionHeader)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			if !config.allowsPreflight(r) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			if allowedHeaders != "" {
+				w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			}
+			if config.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// allowsOrigin reports whether origin may make cross-origin requests
+func (c CORSConfig) allowsOrigin(origin string) bool {
+	return containsFold(c.AllowedOrigins, "*") || containsFold(c.AllowedOrigins, origin)
+}
+
+// allowsPreflight reports whether the method and headers a preflight asks for are allowed
+func (c CORSConfig) allowsPreflight(r *http.Request) bool {
+	if !containsFold(c.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+		return false
+	}
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header != "" && !containsFold(c.AllowedHeaders, header) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
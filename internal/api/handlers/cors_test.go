@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	allowed := DefaultCORSConfig()
+	allowed.AllowedOrigins = []string{"https://admin.example.com"}
+
+	withCredentials := allowed
+	withCredentials.AllowCredentials = true
+
+	anyOrigin := DefaultCORSConfig()
+	anyOrigin.AllowedOrigins = []string{"*"}
+
+	tests := []struct {
+		name           string
+		config         CORSConfig
+		method         string
+		headers        map[string]string
+		expectedStatus int
+		expectedOrigin string
+		reachesHandler bool
+	}{
+		{
+			name:           "denies every origin by default",
+			config:         DefaultCORSConfig(),
+			method:         http.MethodGet,
+			headers:        map[string]string{"Origin": "https://admin.example.com"},
+			expectedStatus: http.StatusOK,
+			reachesHandler: true,
+		},
+		{
+			name:           "rejects preflights by default",
+			config:         DefaultCORSConfig(),
+			method:         http.MethodOptions,
+			headers:        map[string]string{"Origin": "https://admin.example.com", "Access-Control-Request-Method": "GET"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "requests without an origin are unaffected",
+			config:         allowed,
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			reachesHandler: true,
+		},
+		{
+			name:           "allowed origin",
+			config:         allowed,
+			method:         http.MethodGet,
+			headers:        map[string]string{"Origin": "https://admin.example.com"},
+			expectedStatus: http.StatusOK,
+			expectedOrigin: "https://admin.example.com",
+			reachesHandler: true,
+		},
+		{
+			name:           "other origin",
+			config:         allowed,
+			method:         http.MethodGet,
+			headers:        map[string]string{"Origin": "https://evil.example.com"},
+			expectedStatus: http.StatusOK,
+			reachesHandler: true,
+		},
+		{
+			name:   "preflight",
+			config: allowed,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://admin.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "content-type, x-api-key",
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedOrigin: "https://admin.example.com",
+		},
+		{
+			name:   "preflight for a header that isn't allowed",
+			config: allowed,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://admin.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "X-Operator-Key",
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedOrigin: "https://admin.example.com",
+		},
+		{
+			name:           "preflight for a method that isn't allowed",
+			config:         allowed,
+			method:         http.MethodOptions,
+			headers:        map[string]string{"Origin": "https://admin.example.com", "Access-Control-Request-Method": "PATCH"},
+			expectedStatus: http.StatusForbidden,
+			expectedOrigin: "https://admin.example.com",
+		},
+		{
+			name:           "any origin",
+			config:         anyOrigin,
+			method:         http.MethodGet,
+			headers:        map[string]string{"Origin": "https://anywhere.example.com"},
+			expectedStatus: http.StatusOK,
+			expectedOrigin: "*",
+			reachesHandler: true,
+		},
+		{
+			name:           "credentials",
+			config:         withCredentials,
+			method:         http.MethodGet,
+			headers:        map[string]string{"Origin": "https://admin.example.com"},
+			expectedStatus: http.StatusOK,
+			expectedOrigin: "https://admin.example.com",
+			reachesHandler: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reached = true
+			})
+
+			req := httptest.NewRequest(tt.method, "/notifications", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			CORS(tt.config)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.reachesHandler, reached)
+			assert.Equal(t, tt.expectedOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+			if tt.expectedStatus == http.StatusNoContent {
+				assert.Equal(t, "GET, POST, PUT, DELETE", rec.Header().Get("Access-Control-Allow-Methods"))
+				assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+			}
+			if tt.config.AllowCredentials && tt.expectedOrigin != "" {
+				assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+			} else {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"*"}
+	assert.NoError(t, config.Validate())
+
+	config.AllowCredentials = true
+	assert.Error(t, config.Validate())
+
+	config.AllowedOrigins = []string{"https://admin.example.com"}
+	assert.NoError(t, config.Validate())
+}
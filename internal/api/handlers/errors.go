@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// Error codes reported to clients, letting integrators react to a failure
+// without parsing its message
+const (
+	ErrorCodeInvalidRecipient    = "invalid_recipient"
+	ErrorCodeValidationFailed    = "validation_failed"
+	ErrorCodeNotFound            = "not_found"
+	ErrorCodeConflict            = "conflict"
+	ErrorCodeRejected            = "rejected"
+	ErrorCodeThrottled           = "throttled"
+	ErrorCodeProviderUnavailable = "provider_unavailable"
+	ErrorCodeUnauthenticated     = "unauthenticated"
+	ErrorCodeNotAcceptable       = "not_acceptable"
+	ErrorCodeRequestTooLarge     = "request_too_large"
+	ErrorCodeNotImplemented      = "not_implemented"
+	ErrorCodeInternal            = "internal_error"
+)
+
+// ErrorResponse is returned when the service fails to handle a request. Code
+// is stable for clients to branch on; Message is meant for people and may change.
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Reason    string `json:"reason,omitempty"`     // What went wrong, when it is safe to show
+	RequestID string `json:"request_id,omitempty"` // The X-Request-ID the failure is logged under
+}
+
+// StatusForError maps a service error to the HTTP status reported to clients.
+// The gRPC API derives its status codes from the same classification.
+func StatusForError(err error) int {
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrRejected):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, model.ErrThrottled):
+		return http.StatusTooManyRequests
+	case errors.Is(err, model.ErrProviderUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ErrorDetails classifies a service error into a client-facing code and a
+// reason that is safe to show. Errors are wrapped with internal context on
+// their way up, so the reason is only ever the domain error's own message or
+// a fixed description; the full error belongs in the logs.
+func ErrorDetails(err error) (code, reason string) {
+	var invalidRecipient model.ErrInvalidRecipient
+	var providerFailure model.ErrProviderFailure
+	switch {
+	case errors.As(err, &invalidRecipient):
+		return ErrorCodeInvalidRecipient, invalidRecipient.Error()
+	case errors.Is(err, model.ErrValidation):
+		return ErrorCodeValidationFailed, domainMessage(err, model.ErrValidation)
+	case errors.Is(err, model.ErrNotFound):
+		return ErrorCodeNotFound, domainMessage(err, model.ErrNotFound)
+	case errors.Is(err, model.ErrConflict):
+		return ErrorCodeConflict, domainMessage(err, model.ErrConflict)
+	case errors.Is(err, model.ErrRejected):
+		return ErrorCodeRejected, "the provider rejected the message"
+	case errors.Is(err, model.ErrThrottled):
+		return ErrorCodeThrottled, domainMessage(err, model.ErrThrottled)
+	case errors.As(err, &providerFailure):
+		return ErrorCodeProviderUnavailable, fmt.Sprintf("the %s provider is unavailable", providerFailure.Type)
+	case errors.Is(err, model.ErrProviderUnavailable):
+		return ErrorCodeProviderUnavailable, "the delivery provider is unavailable"
+	default:
+		return ErrorCodeInternal, ""
+	}
+}
+
+// domainMessage returns the message of the error in err's chain that
+// classifies itself as category
+func domainMessage(err, category error) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if classified, ok := err.(interface{ Is(error) bool }); ok && classified.Is(category) {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// ErrorCodeForStatus returns the code reported with an error response of the
+// given status when no more specific code applies
+func ErrorCodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeValidationFailed
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthenticated
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusNotAcceptable:
+		return ErrorCodeNotAcceptable
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodeRequestTooLarge
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeRejected
+	case http.StatusTooManyRequests:
+		return ErrorCodeThrottled
+	case http.StatusNotImplemented:
+		return ErrorCodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return ErrorCodeProviderUnavailable
+	default:
+		return ErrorCodeInternal
+	}
+}
+
+// writeError reports a failed request with a human-readable message and the
+// code for its status
+func writeError(w http.ResponseWriter, message string, status int) {
+	writeErrorResponse(w, ErrorResponse{Code: ErrorCodeForStatus(status), Message: message}, status)
+}
+
+// writeErrorResponse writes an error response, stamped with the request's ID
+// as RequestIDMiddleware set it on the response
+func writeErrorResponse(w http.ResponseWriter, response ErrorResponse, status int) {
+	response.RequestID = w.Header().Get(RequestIDHeader)
+	writeResponse(w, response, status)
+}
+
+// writeBodyError reports a request body that couldn't be read: 413 if it was
+// over the size limit and 400 otherwise
+func writeBodyError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeError(w, fmt.Sprintf("Request body exceeds %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+	writeError(w, "Invalid request body", http.StatusBadRequest)
+}
+
+// writeServiceError reports a failed service call with its status, code and safe reason
+func writeServiceError(w http.ResponseWriter, message string, err error) {
+	code, reason := ErrorDetails(err)
+	writeErrorResponse(w, ErrorResponse{
+		Code:    code,
+		Message: message,
+		Reason:  reason,
+	}, StatusForError(err))
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rejectedError stands in for a provider's permanent rejection
+type rejectedError struct{}
+
+func (rejectedError) Error() string { return "rejected for smtp-admin" }
+
+func (rejectedError) Is(target error) bool { return target == model.ErrRejected }
+
+func TestErrorDetails(t *testing.T) {
+	secret := errors.New("dial tcp 10.0.0.12:587: auth failed for user smtp-admin")
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedReason string
+	}{
+		{
+			name:           "invalid recipient",
+			err:            fmt.Errorf("error sending notification: %w", model.ErrInvalidRecipient{Recipient: "nobody@example"}),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeInvalidRecipient,
+			expectedReason: "invalid recipient: nobody@example",
+		},
+		{
+			name:           "invalid notification",
+			err:            fmt.Errorf("invalid notification: %w", model.ErrInvalidNotification{Message: "invalid priority: urgent"}),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+			expectedReason: "invalid priority: urgent",
+		},
+		{
+			name:           "content too long",
+			err:            fmt.Errorf("invalid notification: %w", model.ErrContentTooLong{Type: model.EmailNotification, Length: 2000000, MaxLength: 1000000}),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+			expectedReason: "email content is 2000000 characters, the limit is 1000000",
+		},
+		{
+			name:           "too many SMS segments",
+			err:            fmt.Errorf("invalid notification: %w", model.ErrTooManySegments{Segments: 12, MaxSegments: 10}),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+			expectedReason: "SMS content is 12 segments, the limit is 10",
+		},
+		{
+			name:           "not found",
+			err:            fmt.Errorf("error loading notification: %w", model.ErrNotificationNotFound{ID: "42"}),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+			expectedReason: "notification not found: 42",
+		},
+		{
+			name:           "conflict",
+			err:            model.ErrNotificationNotRetryable{ID: "42", Status: model.StatusSent},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   ErrorCodeConflict,
+			expectedReason: "notification 42 is sent, only failed or throttled notifications can be retried",
+		},
+		{
+			name:           "provider failure",
+			err:            fmt.Errorf("error sending notification: %w", model.ErrProviderFailure{Type: model.EmailNotification, Err: secret}),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   ErrorCodeProviderUnavailable,
+			expectedReason: "the email provider is unavailable",
+		},
+		{
+			name:           "rejected by provider",
+			err:            model.ErrProviderFailure{Type: model.EmailNotification, Err: rejectedError{}},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   ErrorCodeRejected,
+			expectedReason: "the provider rejected the message",
+		},
+		{
+			name:           "send limit exceeded",
+			err:            model.ErrSendLimitExceeded{PerMinute: 1000},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   ErrorCodeThrottled,
+			expectedReason: "more than 1000 notifications were sent in a minute; sending is paused",
+		},
+		{
+			name:           "unclassified",
+			err:            fmt.Errorf("error saving notification: %w", secret),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, reason := ErrorDetails(tt.err)
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedReason, reason)
+
+			rec := httptest.NewRecorder()
+			writeServiceError(rec, "Failed to send notification", tt.err)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "smtp-admin")
+
+			var response ErrorResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, "Failed to send notification", response.Message)
+			assert.Equal(t, tt.expectedCode, response.Code)
+			assert.Equal(t, tt.expectedReason, response.Reason)
+		})
+	}
+}
+
+// assertErrorCode asserts that rec holds an error response with the given code
+func assertErrorCode(t *testing.T, rec *httptest.ResponseRecorder, code string) {
+	t.Helper()
+	var response struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response), rec.Body.String())
+	assert.Equal(t, code, response.Code, rec.Body.String())
+}
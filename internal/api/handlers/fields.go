@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// notificationFields are the fields a notification list can be narrowed to
+// with ?fields=, by their JSON names
+var notificationFields = jsonFieldNames(reflect.TypeOf(NotificationResponse{}))
+
+// jsonFieldNames returns the JSON names of a struct type's fields
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// parseNotificationFields reads the comma-separated fields query parameter a
+// notification list is narrowed to, e.g. ?fields=status,created_at. The ID is
+// always included. It returns nil if the parameter is missing, and an error
+// naming the first field notifications don't have.
+func parseNotificationFields(r *http.Request) ([]string, error) {
+	value := r.URL.Query().Get("fields")
+	if value == "" {
+		return nil, nil
+	}
+
+	fields := []string{"id"}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || field == "id" {
+			continue
+		}
+		if !notificationFields[field] {
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// sparseNotifications narrows each notification to fields, leaving out those
+// it has no value for; with no fields, notifications are returned whole
+func sparseNotifications(notifications []NotificationResponse, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return notifications, nil
+	}
+
+	sparse := make([]map[string]json.RawMessage, 0, len(notifications))
+	for _, notification := range notifications {
+		encoded, err := json.Marshal(notification)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(encoded, &all); err != nil {
+			return nil, err
+		}
+
+		narrowed := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				narrowed[field] = value
+			}
+		}
+		sparse = append(sparse, narrowed)
+	}
+	return sparse, nil
+}
+
+// utcTime returns t in UTC, or nil if t is nil
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// APIKeyHeader carries the caller's API key
+	APIKeyHeader = "X-API-Key"
+
+	// OperatorKeyHeader carries the operator key required for actions affecting every tenant
+	OperatorKeyHeader = "X-Operator-Key"
+
+	// TenantHeader carries the caller's tenant when API keys are not configured
+	TenantHeader = "X-Tenant-ID"
+
+	// RequestIDHeader carries the ID correlating a request with the notifications it produces
+	RequestIDHeader = "X-Request-ID"
+
+	// DefaultMaxRequestBodyBytes is the largest request body accepted by default
+	DefaultMaxRequestBodyBytes = 1 << 20
+)
+
+var (
+	// ErrUnauthenticated is returned when API keys are configured and the caller's key is missing or unknown
+	ErrUnauthenticated = errors.New("invalid or missing API key")
+
+	// ErrInvalidTenant is returned when the requested tenant ID is malformed
+	ErrInvalidTenant = errors.New("invalid tenant ID")
+)
+
+// authenticatedContextKey marks requests whose caller presented a valid API key
+type authenticatedContextKey struct{}
+
+// tenantIDPattern restricts tenant IDs to characters that are safe in storage keys
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// RequestIDMiddleware gives every request a correlation ID: the caller's
+// X-Request-ID if it is usable, a new one otherwise. The ID is echoed in the
+// response header and stamped on the notifications the request produces.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !model.IsValidCorrelationID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(model.ContextWithCorrelationID(r.Context(), requestID)))
+	})
+}
+
+// PreferredLocalesMiddleware records the locales the caller prefers, from its
+// Accept-Language header, so templates rendered for the request are rendered in
+// the best match among the available locales
+func PreferredLocalesMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if locales := model.ParseAcceptLanguage(r.Header.Get("Accept-Language")); len(locales) > 0 {
+			r = r.WithContext(model.ContextWithPreferredLocales(r.Context(), locales))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RecoverPanics turns a panicking handler into a 500 response, logging the panic
+// with its stack and the request's correlation ID, so one bad request can't take
+// the server down or drop the connection without a trace. It belongs after
+// RequestIDMiddleware.
+func RecoverPanics(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				// Handlers abort a response on purpose with http.ErrAbortHandler
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				metrics.RecordHTTPPanic()
+				requestLogger(logger, r).Error("handler panicked",
+					zap.Any("panic", recovered),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Stack("stack"),
+				)
+				writeError(w, "Internal server error", http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LimitRequestBody caps request bodies at maxBytes; handlers reading past it
+// fail with a *http.MaxBytesError and respond 413. maxBytes <= 0 disables the cap.
+func LimitRequestBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBytes > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestLogger returns logger annotated with the request's correlation ID
+func requestLogger(logger *zap.Logger, r *http.Request) *zap.Logger {
+	return logging.WithContext(r.Context(), logger)
+}
+
+// TenantMiddleware scopes every request to a tenant. When API keys are configured
+// (key -> tenant ID) the tenant is derived from the caller's key and requests
+// without a valid key are rejected; otherwise the X-Tenant-ID header is used,
+// falling back to the default tenant.
+func TenantMiddleware(apiKeys map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, err := ResolveTenant(apiKeys, r.Header.Get(APIKeyHeader), r.Header.Get(TenantHeader))
+			if errors.Is(err, ErrUnauthenticated) {
+				writeError(w, "Invalid or missing API key", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				writeError(w, "Invalid tenant ID", http.StatusBadRequest)
+				return
+			}
+
+			ctx := model.ContextWithTenant(r.Context(), tenantID)
+			if len(apiKeys) > 0 {
+				ctx = context.WithValue(ctx, authenticatedContextKey{}, true)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireAuthentication rejects requests whose caller wasn't authenticated by
+// TenantMiddleware with an API key. It guards routes that must not be open even
+// when API keys aren't configured, such as bulk exports.
+func RequireAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authenticated, _ := r.Context().Value(authenticatedContextKey{}).(bool); !authenticated {
+			writeError(w, "This endpoint requires an API key", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireOperator rejects requests that don't carry one of operatorKeys in the
+// X-Operator-Key header. It guards actions that affect every tenant, for which
+// a tenant's API key isn't enough. Without operator keys, the routes it guards
+// are disabled.
+func RequireOperator(operatorKeys []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isOperatorKey(operatorKeys, r.Header.Get(OperatorKeyHeader)) {
+				writeError(w, "This endpoint requires an operator key", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isOperatorKey reports whether key is one of operatorKeys without leaking timing information
+func isOperatorKey(operatorKeys []string, key string) bool {
+	if key == "" {
+		return false
+	}
+
+	found := false
+	for _, candidate := range operatorKeys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			found = true
+		}
+	}
+	return found
+}
+
+// ResolveTenant determines the caller's tenant from its API key or requested tenant ID.
+// It is shared by the HTTP middleware and the gRPC interceptor.
+func ResolveTenant(apiKeys map[string]string, apiKey, requestedTenantID string) (string, error) {
+	tenantID := requestedTenantID
+	if len(apiKeys) > 0 {
+		var ok bool
+		if tenantID, ok = lookupAPIKey(apiKeys, apiKey); !ok {
+			return "", ErrUnauthenticated
+		}
+	} else if tenantID == "" {
+		tenantID = model.DefaultTenantID
+	}
+
+	if !tenantIDPattern.MatchString(tenantID) {
+		return "", ErrInvalidTenant
+	}
+	return tenantID, nil
+}
+
+// lookupAPIKey resolves an API key to its tenant without leaking timing information
+func lookupAPIKey(apiKeys map[string]string, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	var tenantID string
+	found := false
+	for candidate, tenant := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			tenantID = tenant
+			found = true
+		}
+	}
+	return tenantID, found
+}
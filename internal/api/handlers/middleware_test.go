@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiKeys        map[string]string
+		headers        map[string]string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{
+			name:           "defaults to the default tenant",
+			expectedStatus: http.StatusOK,
+			expectedTenant: model.DefaultTenantID,
+		},
+		{
+			name:           "uses tenant header without api keys",
+			headers:        map[string]string{TenantHeader: "tenant-a"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "tenant-a",
+		},
+		{
+			name:           "rejects malformed tenant header",
+			headers:        map[string]string{TenantHeader: "tenant:a"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "derives tenant from api key",
+			apiKeys:        map[string]string{"secret-a": "tenant-a"},
+			headers:        map[string]string{APIKeyHeader: "secret-a"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "tenant-a",
+		},
+		{
+			name:           "api key tenant wins over tenant header",
+			apiKeys:        map[string]string{"secret-a": "tenant-a"},
+			headers:        map[string]string{APIKeyHeader: "secret-a", TenantHeader: "tenant-b"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "tenant-a",
+		},
+		{
+			name:           "rejects unknown api key",
+			apiKeys:        map[string]string{"secret-a": "tenant-a"},
+			headers:        map[string]string{APIKeyHeader: "secret-b"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects missing api key",
+			apiKeys:        map[string]string{"secret-a": "tenant-a"},
+			headers:        map[string]string{TenantHeader: "tenant-a"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenantID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenantID = model.TenantFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/notifications", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			TenantMiddleware(tt.apiKeys)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedTenant, tenantID)
+		})
+	}
+}
+
+func TestRequireAuthentication(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiKeys        map[string]string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "allows callers with an api key",
+			apiKeys:        map[string]string{"secret-a": "tenant-a"},
+			headers:        map[string]string{APIKeyHeader: "secret-a"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rejects callers when api keys aren't configured",
+			headers:        map[string]string{TenantHeader: "tenant-a"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/notifications/export", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			TenantMiddleware(tt.apiKeys)(RequireAuthentication(next)).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestRequireOperator(t *testing.T) {
+	tests := []struct {
+		name           string
+		operatorKeys   []string
+		key            string
+		expectedStatus int
+	}{
+		{
+			name:           "allows callers with an operator key",
+			operatorKeys:   []string{"op-1", "op-2"},
+			key:            "op-2",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rejects unknown keys",
+			operatorKeys:   []string{"op-1"},
+			key:            "secret-a",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects callers without a key",
+			operatorKeys:   []string{"op-1"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects every caller when operator keys aren't configured",
+			key:            "op-1",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/send-limit/clear", nil)
+			if tt.key != "" {
+				req.Header.Set(OperatorKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+
+			RequireOperator(tt.operatorKeys)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		keepsID   bool
+	}{
+		{name: "keeps the caller's ID", requestID: "req-123", keepsID: true},
+		{name: "generates an ID when missing"},
+		{name: "replaces an ID with spaces", requestID: "req 123"},
+		{name: "replaces an overlong ID", requestID: strings.Repeat("a", model.MaxCorrelationIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var correlationID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				correlationID = model.CorrelationIDFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/notifications", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+
+			RequestIDMiddleware(next).ServeHTTP(rec, req)
+
+			assert.NotEmpty(t, correlationID)
+			assert.Equal(t, correlationID, rec.Header().Get(RequestIDHeader))
+			if tt.keepsID {
+				assert.Equal(t, tt.requestID, correlationID)
+			} else {
+				assert.NotEqual(t, tt.requestID, correlationID)
+			}
+		})
+	}
+}
+
+func TestPreferredLocalesMiddleware(t *testing.T) {
+	var locales []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locales = model.PreferredLocalesFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/notifications", nil)
+	req.Header.Set("Accept-Language", "en;q=0.8, fr-CA")
+	PreferredLocalesMiddleware(next).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, []string{"fr-CA", "en"}, locales)
+
+	req = httptest.NewRequest(http.MethodGet, "/notifications", nil)
+	PreferredLocalesMiddleware(next).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Nil(t, locales)
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	body := `{"recipient": "user@example.com", "type": "email", "subject": "Hi", "content": "` + strings.Repeat("a", 512) + `", "priority": "low"}`
+
+	tests := []struct {
+		name           string
+		maxBytes       int64
+		expectedStatus int
+	}{
+		{name: "rejects an oversize body", maxBytes: 256, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "accepts a body within the limit", maxBytes: 1024, expectedStatus: http.StatusCreated},
+		{name: "no limit", maxBytes: 0, expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			mockService.On("SendNotification", mock.Anything, mock.Anything).Return(nil).Maybe()
+			router := chi.NewRouter()
+			router.Use(LimitRequestBody(tt.maxBytes))
+			NewNotificationHandler(mockService, zap.NewNop()).RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/notifications", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusRequestEntityTooLarge {
+				mockService.AssertNotCalled(t, "SendNotification", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var provider interface{ Name() string }
+		provider.Name()
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	RequestIDMiddleware(RecoverPanics(zap.New(core))(panicking)).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"code": "internal_error", "message": "Internal server error", "request_id": "req-123"}`, rec.Body.String())
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "handler panicked", entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, "req-123", fields["correlation_id"])
+	assert.Equal(t, "/notifications", fields["path"])
+	assert.Contains(t, fields["stack"], "TestRecoverPanics")
+
+	t.Run("aborted responses are left to the server", func(t *testing.T) {
+		aborting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		})
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			RecoverPanics(zap.NewNop())(aborting).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxExportRange is the longest period a single export may cover
+	maxExportRange = 31 * 24 * time.Hour
+
+	// exportFlushRows is how many rows are written between flushes to the client
+	exportFlushRows = 500
+)
+
+// exportColumns are the columns of a CSV export, in order
+var exportColumns = []string{"id", "recipient", "type", "status", "created_at", "error"}
+
+// ExportedNotification represents a notification in an NDJSON export
+type ExportedNotification struct {
+	ID        string    `json:"id"`
+	Recipient string    `json:"recipient"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// notificationExporter writes notifications to an export in one format
+type notificationExporter interface {
+	writeHeader() error
+	write(notification *model.Notification) error
+	flush() error
+}
+
+// csvExporter writes a CSV export with a header row
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) writeHeader() error {
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvExporter) write(notification *model.Notification) error {
+	return e.w.Write([]string{
+		notification.ID.String(),
+		notification.Recipient,
+		string(notification.Type),
+		string(notification.Status),
+		notification.CreatedAt.UTC().Format(time.RFC3339),
+		notification.ErrorMessage,
+	})
+}
+
+func (e *csvExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonExporter writes an export of one JSON object per line
+type ndjsonExporter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonExporter) writeHeader() error {
+	return nil
+}
+
+func (e *ndjsonExporter) write(notification *model.Notification) error {
+	return e.enc.Encode(ExportedNotification{
+		ID:        notification.ID.String(),
+		Recipient: notification.Recipient,
+		Type:      string(notification.Type),
+		Status:    string(notification.Status),
+		CreatedAt: notification.CreatedAt.UTC(),
+		Error:     notification.ErrorMessage,
+	})
+}
+
+func (e *ndjsonExporter) flush() error {
+	return e.w.Flush()
+}
+
+// ExportNotifications handles the request to download a recipient's notifications
+// created between from (RFC 3339) and to (RFC 3339, default now), oldest first,
+// as CSV or, with format=ndjson, one JSON object per line. Notifications are
+// streamed as they are read. An error after the first row can't change the
+// status code, so it ends the response early and is only logged.
+func (h *NotificationHandler) ExportNotifications(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "export_notifications"
+
+	query := r.URL.Query()
+	recipient := query.Get("recipient")
+	if recipient == "" {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "recipient is required", http.StatusBadRequest)
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "from must be an RFC 3339 timestamp", http.StatusBadRequest)
+		return
+	}
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+			writeError(w, "to must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+	if !from.Before(to) {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+	if to.Sub(from) > maxExportRange {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, fmt.Sprintf("An export can cover at most %s", maxExportRange), http.StatusBadRequest)
+		return
+	}
+
+	var exporter notificationExporter
+	var contentType, extension string
+	switch query.Get("format") {
+	case "", "csv":
+		exporter = &csvExporter{w: csv.NewWriter(w)}
+		contentType, extension = CSVMediaType, "csv"
+	case "ndjson":
+		buffered := bufio.NewWriter(w)
+		exporter = &ndjsonExporter{w: buffered, enc: json.NewEncoder(buffered)}
+		contentType, extension = NDJSONMediaType, "ndjson"
+	default:
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "format must be csv or ndjson", http.StatusBadRequest)
+		return
+	}
+
+	// The response starts with the first notification, so errors finding it can still be reported
+	started := false
+	begin := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="notifications.`+extension+`"`)
+		w.WriteHeader(http.StatusOK)
+		return exporter.writeHeader()
+	}
+	flusher, _ := w.(http.Flusher)
+	rows := 0
+
+	err = h.notificationService.ExportNotifications(r.Context(), recipient, from, to, func(notification *model.Notification) error {
+		if !started {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		if err := exporter.write(notification); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := exporter.flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = begin()
+	}
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to export notifications",
+			zap.Error(err),
+			zap.String("recipient", recipient),
+			zap.Int("rows", rows),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		if !started {
+			writeServiceError(w, "Failed to export notifications", err)
+			return
+		}
+		// Keep the complete rows already written
+		exporter.flush()
+		return
+	}
+
+	if err := exporter.flush(); err != nil {
+		requestLogger(h.logger, r).Error("failed to write export", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNotificationHandler_ExportNotifications(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService, logger)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	sent := &model.Notification{
+		ID:        uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"),
+		Recipient: "user@example.com",
+		Type:      model.EmailNotification,
+		Status:    model.StatusSent,
+		CreatedAt: time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC),
+	}
+	failed := &model.Notification{
+		ID:           uuid.MustParse("9b2e7f3a-1c4d-4e5f-8a6b-7c8d9e0f1a2b"),
+		Recipient:    "user@example.com",
+		Type:         model.EmailNotification,
+		Status:       model.StatusFailed,
+		ErrorMessage: `provider said "no", twice`,
+		CreatedAt:    time.Date(2025, 1, 11, 9, 0, 0, 0, time.UTC),
+	}
+	period := "&from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z"
+
+	tests := []struct {
+		name                string
+		query               string
+		setupMock           func()
+		expectedStatus      int
+		expectedCode        string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:  "csv",
+			query: "?recipient=user@example.com" + period,
+			setupMock: func() {
+				mockService.On("ExportNotifications", mock.Anything, "user@example.com", from, to).
+					Return([]*model.Notification{sent, failed}, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody: "id,recipient,type,status,created_at,error\n" +
+				"7c9e6679-7425-40de-944b-e07fc1f90ae7,user@example.com,email,sent,2025-01-10T09:00:00Z,\n" +
+				`9b2e7f3a-1c4d-4e5f-8a6b-7c8d9e0f1a2b,user@example.com,email,failed,2025-01-11T09:00:00Z,"provider said ""no"", twice"` + "\n",
+		},
+		{
+			name:  "ndjson",
+			query: "?recipient=user@example.com&format=ndjson" + period,
+			setupMock: func() {
+				mockService.On("ExportNotifications", mock.Anything, "user@example.com", from, to).
+					Return([]*model.Notification{sent}, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody: `{"id":"7c9e6679-7425-40de-944b-e07fc1f90ae7","recipient":"user@example.com","type":"email",` +
+				`"status":"sent","created_at":"2025-01-10T09:00:00Z"}` + "\n",
+		},
+		{
+			name:  "no notifications",
+			query: "?recipient=user@example.com" + period,
+			setupMock: func() {
+				mockService.On("ExportNotifications", mock.Anything, "user@example.com", from, to).Return(nil, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody:        "id,recipient,type,status,created_at,error\n",
+		},
+		{
+			name:  "export unsupported",
+			query: "?recipient=user@example.com" + period,
+			setupMock: func() {
+				mockService.On("ExportNotifications", mock.Anything, "user@example.com", from, to).
+					Return(nil, model.ErrExportUnsupported{})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "missing recipient",
+			query:          "?from=2025-01-01T00:00:00Z",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "missing from",
+			query:          "?recipient=user@example.com",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "from after to",
+			query:          "?recipient=user@example.com&from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "range too long",
+			query:          "?recipient=user@example.com&from=2025-01-01T00:00:00Z&to=2025-03-01T00:00:00Z",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "unknown format",
+			query:          "?recipient=user@example.com&format=xlsx" + period,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodGet, "/notifications/export"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.ExportNotifications(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedContentType, rec.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("error after the first row ends the export", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.Calls = nil
+		mockService.On("ExportNotifications", mock.Anything, "user@example.com", from, to).
+			Return([]*model.Notification{sent}, errors.New("connection reset"))
+
+		req := httptest.NewRequest(http.MethodGet, "/notifications/export?recipient=user@example.com&format=ndjson"+period, nil)
+		rec := httptest.NewRecorder()
+		handler.ExportNotifications(rec, req)
+
+		// The status was already sent; the export is cut short after the rows written so far
+		assert.Equal(t, http.StatusOK, rec.Code)
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		require.Len(t, lines, 1)
+		var exported ExportedNotification
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &exported))
+		assert.Equal(t, sent.ID.String(), exported.ID)
+	})
+}
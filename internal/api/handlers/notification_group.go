@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// SendMultiChannelRequest represents the request body for sending one
+// notification on several channels at once
+type SendMultiChannelRequest struct {
+	Channels []ChannelTargetRequest `json:"channels" validate:"required,min=1,max=10,dive"`
+	Subject  string                 `json:"subject" validate:"required"`
+	Content  string                 `json:"content" validate:"required"`
+	Priority string                 `json:"priority,omitempty" validate:"omitempty,oneof=high medium low"`
+	Category string                 `json:"category,omitempty" validate:"omitempty,max=64"`
+	Tags     []string               `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=64"`
+	Metadata map[string]string      `json:"metadata,omitempty"`
+	// DryRun validates and records the notifications without sending them
+	DryRun bool `json:"dry_run,omitempty"`
+	// AllowDuplicates sends to every channel listed, even those repeating an
+	// earlier channel's type and recipient, which are otherwise sent to once
+	AllowDuplicates bool `json:"allow_duplicates,omitempty"`
+}
+
+// ChannelTargetRequest is a channel of a multi-channel notification and its recipient there
+type ChannelTargetRequest struct {
+	Type      string `json:"type" validate:"required,oneof=email sms push"`
+	Recipient string `json:"recipient" validate:"required"`
+}
+
+// ChannelResultResponse reports how sending on one channel of a multi-channel
+// notification went. Notification is missing if the channel's notification was
+// rejected before it was stored.
+type ChannelResultResponse struct {
+	Type         string                `json:"type"`
+	Recipient    string                `json:"recipient"`
+	Notification *NotificationResponse `json:"notification,omitempty"`
+	Error        *ErrorResponse        `json:"error,omitempty"`
+}
+
+// NotificationGroupResponse represents the response to a multi-channel send
+type NotificationGroupResponse struct {
+	GroupID  string                  `json:"group_id"`
+	Channels []ChannelResultResponse `json:"channels"`
+	// DuplicatesRemoved is how many channels listed were left out as duplicates
+	DuplicatesRemoved int `json:"duplicates_removed"`
+}
+
+// GroupNotificationsResponse lists the notifications of a multi-channel send
+type GroupNotificationsResponse struct {
+	GroupID       string                 `json:"group_id"`
+	Notifications []NotificationResponse `json:"notifications"`
+}
+
+// buildMultiChannel validates a multi-channel send request and converts it into
+// the notification each channel's copy is made from, and the channels to send on
+func buildMultiChannel(validate *validator.Validate, req SendMultiChannelRequest) (*model.Notification, []model.ChannelTarget, error) {
+	if err := validate.Struct(req); err != nil {
+		return nil, nil, err
+	}
+
+	// The type and recipient are set per channel
+	notification := model.NewNotification("", "", "", uuid.Nil, nil)
+	notification.Subject = req.Subject
+	notification.Content = req.Content
+	notification.Priority = model.Priority(req.Priority)
+	notification.Category = req.Category
+	notification.Tags = req.Tags
+	if req.Metadata != nil {
+		notification.Metadata = req.Metadata
+	}
+
+	targets := make([]model.ChannelTarget, 0, len(req.Channels))
+	for _, channel := range req.Channels {
+		targets = append(targets, model.ChannelTarget{
+			Type:      model.NotificationType(channel.Type),
+			Recipient: channel.Recipient,
+		})
+	}
+	return notification, targets, nil
+}
+
+// newNotificationGroupResponse converts a multi-channel send's outcome into its API representation
+func newNotificationGroupResponse(group *model.NotificationGroup) NotificationGroupResponse {
+	response := NotificationGroupResponse{
+		GroupID:           group.ID,
+		Channels:          make([]ChannelResultResponse, 0, len(group.Results)),
+		DuplicatesRemoved: group.Duplicates,
+	}
+	for _, result := range group.Results {
+		channel := ChannelResultResponse{
+			Type:      string(result.Target.Type),
+			Recipient: result.Target.Recipient,
+		}
+		if result.Notification != nil {
+			notification := newNotificationResponse(result.Notification)
+			channel.Notification = &notification
+		}
+		if result.Err != nil {
+			code, reason := ErrorDetails(result.Err)
+			channel.Error = &ErrorResponse{Code: code, Message: "Failed to send notification", Reason: reason}
+		}
+		response.Channels = append(response.Channels, channel)
+	}
+	return response
+}
+
+// SendMultiChannel handles the request to send one notification on several
+// channels at once. It responds 201 if every channel was sent and 207 if any
+// failed, reporting each channel's outcome either way.
+func (h *NotificationHandler) SendMultiChannel(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "send_multi_channel"
+
+	var req SendMultiChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestLogger(h.logger, r).Error("failed to decode request body", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeBodyError(w, err)
+		return
+	}
+
+	notification, targets, err := buildMultiChannel(h.validate, req)
+	if err != nil {
+		requestLogger(h.logger, r).Error("invalid request", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeValidationError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	if req.DryRun {
+		ctx = model.ContextWithDryRun(ctx)
+	}
+	if req.AllowDuplicates {
+		ctx = model.ContextAllowingDuplicates(ctx)
+	}
+
+	group, err := h.notificationService.SendMultiChannel(ctx, notification, targets)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to send multi-channel notification",
+			zap.Error(err),
+			zap.Int("channels", len(targets)),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to send notification", err)
+		return
+	}
+
+	code := http.StatusCreated
+	if group.Failed() > 0 {
+		code = http.StatusMultiStatus
+	}
+
+	if err := writeResponse(w, newNotificationGroupResponse(group), code); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// GetNotificationGroup handles the request to list a multi-channel send's
+// notifications and their statuses, oldest first
+func (h *NotificationHandler) GetNotificationGroup(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "get_notification_group"
+
+	groupID := chi.URLParam(r, "id")
+	if groupID == "" {
+		requestLogger(h.logger, r).Error("group ID is required")
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Group ID is required", http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotificationGroup(r.Context(), groupID)
+	if err != nil {
+		if code := StatusForError(err); code == http.StatusNotFound {
+			metrics.RecordOperationDuration("http_"+operation, "not_found", time.Since(start).Seconds())
+			writeServiceError(w, "Notification group not found", err)
+			return
+		}
+		requestLogger(h.logger, r).Error("failed to get notification group",
+			zap.Error(err),
+			zap.String("group_id", groupID),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to get notification group", err)
+		return
+	}
+
+	response := GroupNotificationsResponse{
+		GroupID:       groupID,
+		Notifications: make([]NotificationResponse, 0, len(notifications)),
+	}
+	for _, notification := range notifications {
+		response.Notifications = append(response.Notifications, newNotificationResponse(notification))
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
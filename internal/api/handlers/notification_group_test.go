@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newNotificationRouter serves the notification routes
+func newNotificationRouter(service NotificationService) http.Handler {
+	router := chi.NewRouter()
+	NewNotificationHandler(service, zap.NewNop()).RegisterRoutes(router)
+	return router
+}
+
+func TestNotificationHandler_SendMultiChannel(t *testing.T) {
+	groupID := model.NewGroupID()
+	body := `{
+		"channels": [
+			{"type": "email", "recipient": "user@example.com"},
+			{"type": "sms", "recipient": "+14155552671"}
+		],
+		"subject": "Suspicious sign-in",
+		"content": "Was this you?",
+		"priority": "high"
+	}`
+	targets := []model.ChannelTarget{
+		{Type: model.EmailNotification, Recipient: "user@example.com"},
+		{Type: model.SMSNotification, Recipient: "+14155552671"},
+	}
+
+	// group builds the outcome of sending on targets, failing the SMS channel if smsErr is set
+	group := func(smsErr error) *model.NotificationGroup {
+		results := make([]model.ChannelResult, 0, len(targets))
+		for _, target := range targets {
+			notification := &model.Notification{ID: uuid.New(), Type: target.Type, Recipient: target.Recipient, Status: model.StatusSent, GroupID: groupID}
+			result := model.ChannelResult{Target: target, Notification: notification}
+			if target.Type == model.SMSNotification && smsErr != nil {
+				notification.Status = model.StatusFailed
+				result.Err = smsErr
+			}
+			results = append(results, result)
+		}
+		return &model.NotificationGroup{ID: groupID, Results: results}
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockNotificationService)
+		expectedStatus int
+		expectedErrors []string
+	}{
+		{
+			name: "every channel sent",
+			body: body,
+			setupMock: func(m *MockNotificationService) {
+				m.On("SendMultiChannel", mock.Anything, mock.MatchedBy(func(n *model.Notification) bool {
+					return n.Subject == "Suspicious sign-in" && n.Priority == model.PriorityHigh
+				}), targets).Return(group(nil), nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedErrors: []string{"", ""},
+		},
+		{
+			name: "one channel failed",
+			body: body,
+			setupMock: func(m *MockNotificationService) {
+				m.On("SendMultiChannel", mock.Anything, mock.Anything, targets).
+					Return(group(model.ErrProviderFailure{Type: model.SMSNotification, Err: assert.AnError}), nil)
+			},
+			expectedStatus: http.StatusMultiStatus,
+			expectedErrors: []string{"", ErrorCodeProviderUnavailable},
+		},
+		{
+			name:           "recipient doesn't suit the channel",
+			body:           `{"channels": [{"type": "sms", "recipient": "user@example.com"}], "subject": "s", "content": "c", "priority": "high"}`,
+			setupMock:      func(m *MockNotificationService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "requires a channel",
+			body:           `{"channels": [], "subject": "s", "content": "c", "priority": "high"}`,
+			setupMock:      func(m *MockNotificationService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/notifications/multi-channel", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			newNotificationRouter(mockService).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedErrors != nil {
+				var response NotificationGroupResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, groupID, response.GroupID)
+				require.Len(t, response.Channels, len(tt.expectedErrors))
+				for i, channel := range response.Channels {
+					require.NotNil(t, channel.Notification)
+					assert.Equal(t, groupID, channel.Notification.GroupID)
+					if tt.expectedErrors[i] == "" {
+						assert.Nil(t, channel.Error)
+					} else {
+						require.NotNil(t, channel.Error)
+						assert.Equal(t, tt.expectedErrors[i], channel.Error.Code)
+					}
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_SendMultiChannelDuplicates(t *testing.T) {
+	body := func(allowDuplicates bool) string {
+		return fmt.Sprintf(`{
+			"channels": [
+				{"type": "email", "recipient": "User@Example.com"},
+				{"type": "email", "recipient": "user@example.com"}
+			],
+			"subject": "Suspicious sign-in",
+			"content": "Was this you?",
+			"allow_duplicates": %t
+		}`, allowDuplicates)
+	}
+	group := &model.NotificationGroup{ID: model.NewGroupID(), Duplicates: 1}
+
+	t.Run("reports the duplicates removed", func(t *testing.T) {
+		mockService := new(MockNotificationService)
+		mockService.On("SendMultiChannel", mock.MatchedBy(func(ctx context.Context) bool {
+			return !model.DuplicatesAllowed(ctx)
+		}), mock.Anything, mock.Anything).Return(group, nil)
+
+		rec := httptest.NewRecorder()
+		newNotificationRouter(mockService).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/notifications/multi-channel", bytes.NewBufferString(body(false))))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var response NotificationGroupResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, 1, response.DuplicatesRemoved)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("passes on that duplicates are allowed", func(t *testing.T) {
+		mockService := new(MockNotificationService)
+		mockService.On("SendMultiChannel", mock.MatchedBy(model.DuplicatesAllowed), mock.Anything, mock.Anything).
+			Return(&model.NotificationGroup{ID: group.ID}, nil)
+
+		rec := httptest.NewRecorder()
+		newNotificationRouter(mockService).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/notifications/multi-channel", bytes.NewBufferString(body(true))))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestNotificationHandler_GetNotificationGroup(t *testing.T) {
+	groupID := model.NewGroupID()
+	notifications := []*model.Notification{
+		{ID: uuid.New(), Type: model.EmailNotification, Status: model.StatusDelivered, GroupID: groupID},
+		{ID: uuid.New(), Type: model.PushNotification, Status: model.StatusFailed, GroupID: groupID},
+	}
+
+	t.Run("lists the group's notifications", func(t *testing.T) {
+		mockService := new(MockNotificationService)
+		mockService.On("GetNotificationGroup", mock.Anything, groupID).Return(notifications, nil)
+
+		rec := httptest.NewRecorder()
+		newNotificationRouter(mockService).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notifications/group/"+groupID, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response GroupNotificationsResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, groupID, response.GroupID)
+		require.Len(t, response.Notifications, 2)
+		assert.Equal(t, string(model.StatusDelivered), response.Notifications[0].Status)
+		assert.Equal(t, string(model.StatusFailed), response.Notifications[1].Status)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		mockService := new(MockNotificationService)
+		mockService.On("GetNotificationGroup", mock.Anything, "missing").
+			Return(nil, model.ErrNotificationGroupNotFound{ID: "missing"})
+
+		rec := httptest.NewRecorder()
+		newNotificationRouter(mockService).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notifications/group/missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -3,77 +3,188 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
+	"github.com/go-playground/validator/v10"
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
 	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
 	"go.uber.org/zap"
 )
 
+// MaxStatusLookupIDs caps the number of notifications a single status lookup may request
+const MaxStatusLookupIDs = 100
+
+// metadataQueryPrefix prefixes the query parameters that filter notifications
+// by metadata, e.g. meta.userId=123
+const metadataQueryPrefix = "meta."
+
 // NotificationHandler handles HTTP requests for notifications
 type NotificationHandler struct {
 	notificationService NotificationService
-	logger             *zap.Logger
+	validate            *validator.Validate
+	logger              *zap.Logger
 }
 
 // NotificationService defines the interface for notification operations
 type NotificationService interface {
-	SendNotification(notification *model.Notification) error
+	SendNotification(ctx context.Context, notification *model.Notification) error
 	GetNotification(ctx context.Context, id string) (*model.Notification, error)
-	GetNotificationsByRecipient(recipient string, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error)
+	GetNotificationsByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error)
+	ExportNotifications(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error
+	RetryNotification(ctx context.Context, id string) (*model.Notification, error)
+	ResendNotification(ctx context.Context, id, recipient string) (*model.Notification, error)
+	SendMultiChannel(ctx context.Context, notification *model.Notification, targets []model.ChannelTarget) (*model.NotificationGroup, error)
+	GetNotificationGroup(ctx context.Context, groupID string) ([]*model.Notification, error)
 }
 
 // NewNotificationHandler creates a new notification handler
 func NewNotificationHandler(service NotificationService, logger *zap.Logger) *NotificationHandler {
 	return &NotificationHandler{
 		notificationService: service,
-		logger:             logger,
+		validate:            NewValidator(),
+		logger:              logger,
 	}
 }
 
 // SendNotificationRequest represents the request body for sending a notification
 type SendNotificationRequest struct {
-	Recipient    string            `json:"recipient" validate:"required,email"`
-	Type         string            `json:"type" validate:"required,oneof=email sms push"`
-	Subject      string            `json:"subject" validate:"required"`
-	Content      string            `json:"content" validate:"required"`
-	Priority     string            `json:"priority" validate:"required,oneof=high medium low"`
-	TemplateID   string            `json:"template_id,omitempty"`
-	TemplateData map[string]string `json:"template_data,omitempty"`
-	Metadata     map[string]string `json:"metadata,omitempty"`
+	Recipient string `json:"recipient" validate:"required"`
+	Type      string `json:"type" validate:"required,oneof=email sms push whatsapp"`
+	Subject   string `json:"subject" validate:"required_unless=Type whatsapp"`
+	Content   string `json:"content" validate:"required_unless=Type whatsapp"`
+	// Priority defaults to the type's default priority when omitted
+	Priority   string   `json:"priority,omitempty" validate:"omitempty,oneof=high medium low"`
+	Category   string   `json:"category,omitempty" validate:"omitempty,max=64"`
+	Tags       []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=64"`
+	TemplateID string   `json:"template_id,omitempty"`
+	// TemplateData holds strings or any JSON value, such as a list of order items
+	TemplateData model.TemplateData `json:"template_data,omitempty"`
+	Metadata     map[string]string  `json:"metadata,omitempty"`
+	// DryRun validates and records the notification without sending it
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// SendNotificationRequestV2 represents the request body for sending a
+// notification with API version 2, which adds email options to version 1's fields
+type SendNotificationRequestV2 struct {
+	SendNotificationRequest
+	// ContentType is the format email content is sent in: text/html, the default, or text/plain
+	ContentType string `json:"content_type,omitempty" validate:"omitempty,oneof=text/html text/plain"`
+	// CC lists the addresses an email is copied to
+	CC []string `json:"cc,omitempty" validate:"omitempty,max=10,dive,email"`
+	// InlineImages are embedded in an HTML email, which references them as cid:<content_id>
+	InlineImages []InlineImageRequest `json:"inline_images,omitempty" validate:"omitempty,max=10,dive"`
+	// ThreadID groups emails mail clients show as one conversation; other channels ignore it
+	ThreadID string `json:"thread_id,omitempty" validate:"omitempty,max=128"`
+}
+
+// InlineImageRequest represents an image embedded in an email; data is base64-encoded
+type InlineImageRequest struct {
+	ContentID   string `json:"content_id" validate:"required,max=64"`
+	ContentType string `json:"content_type" validate:"required,startswith=image/"`
+	Data        []byte `json:"data" validate:"required"`
+}
+
+// ResendNotificationRequest represents the optional request body for re-sending a notification
+type ResendNotificationRequest struct {
+	Recipient string `json:"recipient,omitempty" validate:"omitempty,email"`
 }
 
-// NotificationResponse represents the response for notification operations
+// NotificationResponse represents the response for notification operations.
+// Timestamps are in UTC, and optional fields are left out when empty.
 type NotificationResponse struct {
-	ID        string            `json:"id"`
-	Recipient string            `json:"recipient"`
-	Type      string            `json:"type"`
-	Subject   string            `json:"subject"`
-	Content   string            `json:"content"`
-	Status    string            `json:"status"`
-	Metadata  map[string]string `json:"metadata,omitempty"`
-	CreatedAt time.Time         `json:"created_at"`
-	UpdatedAt time.Time         `json:"updated_at"`
+	ID                string            `json:"id"`
+	Recipient         string            `json:"recipient"`
+	Type              string            `json:"type"`
+	Subject           string            `json:"subject"`
+	Content           string            `json:"content"`
+	Status            string            `json:"status"`
+	Category          string            `json:"category,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	ProviderMessageID string            `json:"provider_message_id,omitempty"`
+	Provider          string            `json:"provider,omitempty"`
+	CorrelationID     string            `json:"correlation_id,omitempty"`
+	GroupID           string            `json:"group_id,omitempty"`
+	ContentType       string            `json:"content_type,omitempty"`
+	CC                []string          `json:"cc,omitempty"`
+	ThreadID          string            `json:"thread_id,omitempty"`
+	EmailMessageID    string            `json:"email_message_id,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	RetryCount        int               `json:"retry_count"`
+	NextRetryAt       *time.Time        `json:"next_retry_at,omitempty"`
+	ScheduledAt       *time.Time        `json:"scheduled_at,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// NotificationStatusRequest represents the request body for a batch status lookup
+type NotificationStatusRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,dive,uuid"`
+}
+
+// NotificationStatusResponse represents the current status of a single notification
+type NotificationStatusResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// newNotificationResponse converts a notification into its API representation
+func newNotificationResponse(notification *model.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:                notification.ID.String(),
+		Recipient:         notification.Recipient,
+		Type:              string(notification.Type),
+		Subject:           notification.Subject,
+		Content:           notification.Content,
+		Status:            string(notification.Status),
+		Category:          notification.Category,
+		Tags:              notification.Tags,
+		ProviderMessageID: notification.ProviderMessageID,
+		Provider:          notification.Provider,
+		CorrelationID:     notification.CorrelationID,
+		GroupID:           notification.GroupID,
+		ContentType:       string(notification.ContentType),
+		CC:                notification.CC,
+		ThreadID:          notification.ThreadID,
+		EmailMessageID:    notification.EmailMessageID,
+		Metadata:          notification.Metadata,
+		RetryCount:        notification.RetryCount,
+		NextRetryAt:       utcTime(notification.NextRetryAt),
+		ScheduledAt:       utcTime(notification.ScheduledAt),
+		CreatedAt:         notification.CreatedAt.UTC(),
+		UpdatedAt:         notification.UpdatedAt.UTC(),
+	}
 }
 
 // RegisterRoutes registers the notification routes
 func (h *NotificationHandler) RegisterRoutes(r chi.Router) {
+	r.With(RequireAuthentication, NegotiateContentType(CSVMediaType, NDJSONMediaType)).Get("/notifications/export", h.ExportNotifications)
+
+	r = r.With(NegotiateContentType(JSONMediaType))
 	r.Post("/notifications", h.SendNotification)
+	r.Post("/notifications/status", h.GetNotificationStatuses)
+	r.Post("/notifications/multi-channel", h.SendMultiChannel)
+	r.Get("/notifications/group/{id}", h.GetNotificationGroup)
 	r.Get("/notifications/{id}", h.GetNotification)
-	r.Get("/notifications", h.GetNotificationsByRecipient)
-}
-
-func writeError(w http.ResponseWriter, err string, code int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(map[string]string{"error": err})
+	r.Post("/notifications/{id}/retry", h.RetryNotification)
+	r.Post("/notifications/{id}/resend", h.ResendNotification)
+	r.Get("/notifications", h.ListNotifications)
 }
 
 func writeResponse(w http.ResponseWriter, data interface{}, code int) error {
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", JSONMediaType)
 	w.WriteHeader(code)
 	return json.NewEncoder(w).Encode(data)
 }
@@ -83,100 +194,56 @@ func (h *NotificationHandler) SendNotification(w http.ResponseWriter, r *http.Re
 	start := time.Now()
 	operation := "send_notification"
 
-	var req SendNotificationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("failed to decode request body", zap.Error(err))
+	version, err := RequestAPIVersion(r)
+	if err != nil {
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Validate required fields
-	if req.Recipient == "" {
-		h.logger.Error("missing recipient")
-		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Recipient is required", http.StatusBadRequest)
-		return
+	w.Header().Set(APIVersionHeader, version)
+
+	// Version 1 bodies are decoded into version 2's embedded version 1 fields,
+	// leaving the fields version 2 adds at their defaults
+	var req SendNotificationRequestV2
+	body := interface{}(&req)
+	if version == APIVersion1 {
+		body = &req.SendNotificationRequest
 	}
-
-	// Validate notification type
-	validTypes := map[string]bool{"email": true, "sms": true, "push": true}
-	if !validTypes[req.Type] {
-		h.logger.Error("invalid notification type", zap.String("type", req.Type))
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		requestLogger(h.logger, r).Error("failed to decode request body", zap.Error(err))
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Invalid notification type. Must be one of: email, sms, push", http.StatusBadRequest)
+		writeBodyError(w, err)
 		return
 	}
 
-	// Validate content
-	if req.Content == "" {
-		h.logger.Error("missing content")
-		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Content is required", http.StatusBadRequest)
-		return
-	}
-
-	// Validate priority
-	validPriorities := map[string]bool{"high": true, "medium": true, "low": true}
-	if !validPriorities[req.Priority] {
-		h.logger.Error("invalid priority", zap.String("priority", req.Priority))
+	notification, err := BuildNotificationV2(h.validate, req)
+	if err != nil {
+		requestLogger(h.logger, r).Error("invalid request", zap.Error(err))
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Invalid priority. Must be one of: high, medium, low", http.StatusBadRequest)
+		writeValidationError(w, err)
 		return
 	}
 
-	// Convert string templateID to UUID
-	var templateID uuid.UUID
-	if req.TemplateID != "" {
-		var err error
-		templateID, err = uuid.Parse(req.TemplateID)
-		if err != nil {
-			h.logger.Error("invalid template ID format", zap.Error(err))
-			writeError(w, "invalid template ID format", http.StatusBadRequest)
-			return
-		}
+	ctx := r.Context()
+	if req.DryRun {
+		ctx = model.ContextWithDryRun(ctx)
 	}
 
-	notification := &model.Notification{
-		ID:           uuid.New(),
-		Recipient:    req.Recipient,
-		Type:         model.NotificationType(req.Type),
-		Subject:      req.Subject,
-		Content:      req.Content,
-		Priority:     model.Priority(req.Priority),
-		Status:       model.StatusPending,
-		TemplateID:   templateID,
-		TemplateData: req.TemplateData,
-		Metadata:     req.Metadata,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-
-	if err := h.notificationService.SendNotification(notification); err != nil {
-		h.logger.Error("failed to send notification",
+	if err := h.notificationService.SendNotification(ctx, notification); err != nil {
+		requestLogger(h.logger, r).Error("failed to send notification",
 			zap.Error(err),
 			zap.String("recipient", req.Recipient),
 			zap.String("type", req.Type),
 		)
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Failed to send notification", http.StatusFailedDependency)
+		writeServiceError(w, "Failed to send notification", err)
 		return
 	}
 
-	response := NotificationResponse{
-		ID:        notification.ID.String(),
-		Recipient: notification.Recipient,
-		Type:      string(notification.Type),
-		Subject:   notification.Subject,
-		Content:   notification.Content,
-		Status:    string(notification.Status),
-		Metadata:  notification.Metadata,
-		CreatedAt: notification.CreatedAt,
-		UpdatedAt: notification.UpdatedAt,
-	}
+	response := newNotificationResponse(notification)
 
 	if err := writeResponse(w, response, http.StatusCreated); err != nil {
-		h.logger.Error("failed to encode response", zap.Error(err))
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
 		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 		return
@@ -192,7 +259,7 @@ func (h *NotificationHandler) GetNotification(w http.ResponseWriter, r *http.Req
 
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		h.logger.Error("notification ID is required")
+		requestLogger(h.logger, r).Error("notification ID is required")
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
 		writeError(w, "Notification ID is required", http.StatusBadRequest)
 		return
@@ -201,35 +268,24 @@ func (h *NotificationHandler) GetNotification(w http.ResponseWriter, r *http.Req
 	// Get notification by ID
 	notification, err := h.notificationService.GetNotification(r.Context(), id)
 	if err != nil {
-		h.logger.Error("failed to get notification",
+		if code := StatusForError(err); code == http.StatusNotFound {
+			metrics.RecordOperationDuration("http_"+operation, "not_found", time.Since(start).Seconds())
+			writeServiceError(w, "Notification not found", err)
+			return
+		}
+		requestLogger(h.logger, r).Error("failed to get notification",
 			zap.Error(err),
 			zap.String("id", id),
 		)
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Failed to get notification", http.StatusFailedDependency)
+		writeServiceError(w, "Failed to get notification", err)
 		return
 	}
 
-	if notification == nil {
-		metrics.RecordOperationDuration("http_"+operation, "not_found", time.Since(start).Seconds())
-		writeError(w, "Notification not found", http.StatusNotFound)
-		return
-	}
-
-	response := NotificationResponse{
-		ID:        notification.ID.String(),
-		Recipient: notification.Recipient,
-		Type:      string(notification.Type),
-		Subject:   notification.Subject,
-		Content:   notification.Content,
-		Status:    string(notification.Status),
-		Metadata:  notification.Metadata,
-		CreatedAt: notification.CreatedAt,
-		UpdatedAt: notification.UpdatedAt,
-	}
+	response := newNotificationResponse(notification)
 
 	if err := writeResponse(w, response, http.StatusOK); err != nil {
-		h.logger.Error("failed to encode response", zap.Error(err))
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
 		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 		return
@@ -238,50 +294,455 @@ func (h *NotificationHandler) GetNotification(w http.ResponseWriter, r *http.Req
 	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
 }
 
-// GetNotificationsByRecipient handles the request to get notifications for a recipient
+// GetNotificationsByRecipient handles the request to get notifications for a
+// recipient, newest first or, with ?order=asc, oldest first
 func (h *NotificationHandler) GetNotificationsByRecipient(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	operation := "get_notifications_by_recipient"
 
+	fields, err := parseNotificationFields(r)
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, fmt.Sprintf("Invalid fields: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	recipient := r.URL.Query().Get("recipient")
 	if recipient == "" {
-		h.logger.Error("recipient is required")
+		requestLogger(h.logger, r).Error("recipient is required")
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
 		writeError(w, "Recipient is required", http.StatusBadRequest)
 		return
 	}
 
+	// Newest first unless asked otherwise, e.g. ?order=asc for chronological displays
+	order := model.SortDescending
+	if value := r.URL.Query().Get("order"); value != "" {
+		order = model.SortOrder(value)
+		if !order.IsValid() {
+			metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+			writeError(w, "Order must be asc or desc", http.StatusBadRequest)
+			return
+		}
+	}
+
 	limit := 10 // Default limit
 	offset := 0 // Default offset
 
-	notifications, err := h.notificationService.GetNotificationsByRecipient(recipient, limit, offset)
+	notifications, err := h.notificationService.GetNotificationsByRecipient(r.Context(), recipient, order, limit, offset)
 	if err != nil {
-		h.logger.Error("failed to get notifications",
+		requestLogger(h.logger, r).Error("failed to get notifications",
 			zap.Error(err),
 			zap.String("recipient", recipient),
 		)
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
-		writeError(w, "Failed to get notifications", http.StatusFailedDependency)
+		writeServiceError(w, "Failed to get notifications", err)
+		return
+	}
+
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, newNotificationResponse(notification))
+	}
+
+	response, err := sparseNotifications(responses, fields)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to narrow notifications", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ListNotifications handles the request to list notifications, filtered by
+// correlation ID or category when either parameter is given, by metadata when
+// any meta.* parameter is given and by recipient otherwise
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("correlation_id") {
+		h.GetNotificationsByCorrelationID(w, r)
+		return
+	}
+	if r.URL.Query().Has("category") {
+		h.GetNotificationsByCategory(w, r)
+		return
+	}
+	if len(metadataFilters(r.URL.Query())) > 0 {
+		h.GetNotificationsByMetadata(w, r)
+		return
+	}
+	h.GetNotificationsByRecipient(w, r)
+}
+
+// GetNotificationsByMetadata handles the request to find notifications whose
+// metadata matches every meta.<key>=<value> query parameter, newest first
+func (h *NotificationHandler) GetNotificationsByMetadata(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "get_notifications_by_metadata"
+
+	fields, err := parseNotificationFields(r)
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, fmt.Sprintf("Invalid fields: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filters := metadataFilters(query)
+	if len(filters) == 0 {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "At least one meta.<key> filter is required", http.StatusBadRequest)
+		return
+	}
+	for key := range filters {
+		if key == "" || len(query[metadataQueryPrefix+key]) > 1 {
+			metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+			writeError(w, "Each metadata filter needs a key and a single value", http.StatusBadRequest)
+			return
+		}
+	}
+	if query.Get("recipient") != "" {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Filter by recipient or by metadata, not both", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset, ok := parsePagination(r, defaultAdminPageSize, MaxAdminPageSize)
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Invalid limit or offset", http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotificationsByMetadata(r.Context(), filters, limit, offset)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to get notifications by metadata",
+			zap.Error(err),
+			zap.Any("filters", filters),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to get notifications", err)
 		return
 	}
 
-	response := make([]NotificationResponse, 0, len(notifications))
+	responses := make([]NotificationResponse, 0, len(notifications))
 	for _, notification := range notifications {
-		response = append(response, NotificationResponse{
-			ID:        notification.ID.String(),
-			Recipient: notification.Recipient,
-			Type:      string(notification.Type),
-			Subject:   notification.Subject,
-			Content:   notification.Content,
-			Status:    string(notification.Status),
-			Metadata:  notification.Metadata,
-			CreatedAt: notification.CreatedAt,
-			UpdatedAt: notification.UpdatedAt,
-		})
+		responses = append(responses, newNotificationResponse(notification))
+	}
+
+	response, err := sparseNotifications(responses, fields)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to narrow notifications", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
 	}
 
 	if err := writeResponse(w, response, http.StatusOK); err != nil {
-		h.logger.Error("failed to encode response", zap.Error(err))
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// GetNotificationsByCategory handles the request to find the notifications in
+// the category query parameter, newest first
+func (h *NotificationHandler) GetNotificationsByCategory(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "get_notifications_by_category"
+
+	fields, err := parseNotificationFields(r)
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, fmt.Sprintf("Invalid fields: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	category := query.Get("category")
+	if category == "" || len(query["category"]) > 1 {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A single non-empty category is required", http.StatusBadRequest)
+		return
+	}
+	if query.Get("recipient") != "" || len(metadataFilters(query)) > 0 {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Filter by category, recipient or metadata, not several", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset, ok := parsePagination(r, defaultAdminPageSize, MaxAdminPageSize)
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Invalid limit or offset", http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotificationsByCategory(r.Context(), category, limit, offset)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to get notifications by category",
+			zap.Error(err),
+			zap.String("category", category),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to get notifications", err)
+		return
+	}
+
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, newNotificationResponse(notification))
+	}
+
+	response, err := sparseNotifications(responses, fields)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to narrow notifications", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// GetNotificationsByCorrelationID handles the request to find the notifications
+// produced by the request or event in the correlation_id query parameter, oldest first
+func (h *NotificationHandler) GetNotificationsByCorrelationID(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "get_notifications_by_correlation_id"
+
+	fields, err := parseNotificationFields(r)
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, fmt.Sprintf("Invalid fields: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	correlationID := query.Get("correlation_id")
+	if correlationID == "" || len(query["correlation_id"]) > 1 {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A single non-empty correlation_id is required", http.StatusBadRequest)
+		return
+	}
+	if query.Get("recipient") != "" || query.Has("category") || len(metadataFilters(query)) > 0 {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Filter by correlation ID, category, recipient or metadata, not several", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset, ok := parsePagination(r, defaultAdminPageSize, MaxAdminPageSize)
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Invalid limit or offset", http.StatusBadRequest)
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotificationsByCorrelationID(r.Context(), correlationID, limit, offset)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to get notifications by correlation ID",
+			zap.Error(err),
+			zap.String("lookup_correlation_id", correlationID),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to get notifications", err)
+		return
+	}
+
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, newNotificationResponse(notification))
+	}
+
+	response, err := sparseNotifications(responses, fields)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to narrow notifications", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// metadataFilters collects the meta.<key>=<value> query parameters into a map
+// of metadata key to value
+func metadataFilters(query url.Values) map[string]string {
+	filters := make(map[string]string)
+	for name, values := range query {
+		if key, ok := strings.CutPrefix(name, metadataQueryPrefix); ok && len(values) > 0 {
+			filters[key] = values[0]
+		}
+	}
+	return filters
+}
+
+// GetNotificationStatuses handles the request to look up the status of several notifications at once.
+// Notifications that don't exist are omitted from the response.
+func (h *NotificationHandler) GetNotificationStatuses(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "get_notification_statuses"
+
+	var req NotificationStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestLogger(h.logger, r).Error("failed to decode request body", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeBodyError(w, err)
+		return
+	}
+
+	if len(req.IDs) > MaxStatusLookupIDs {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, fmt.Sprintf("At most %d IDs may be looked up per request", MaxStatusLookupIDs), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		requestLogger(h.logger, r).Error("invalid request", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeValidationError(w, err)
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotificationsByIDs(r.Context(), req.IDs)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to get notifications",
+			zap.Error(err),
+			zap.Int("count", len(req.IDs)),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to get notification statuses", err)
+		return
+	}
+
+	response := make([]NotificationStatusResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		status := NotificationStatusResponse{
+			ID:     notification.ID.String(),
+			Status: string(notification.Status),
+		}
+		if notification.Status == model.StatusFailed {
+			status.Error = notification.ErrorMessage
+		}
+		response = append(response, status)
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// RetryNotification handles the request to re-send a failed notification
+func (h *NotificationHandler) RetryNotification(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "retry_notification"
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		requestLogger(h.logger, r).Error("notification ID is required")
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Notification ID is required", http.StatusBadRequest)
+		return
+	}
+
+	notification, err := h.notificationService.RetryNotification(r.Context(), id)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to retry notification",
+			zap.Error(err),
+			zap.String("id", id),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to retry notification", err)
+		return
+	}
+
+	response := newNotificationResponse(notification)
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ResendNotification handles the request to send a copy of a notification,
+// optionally to another recipient
+func (h *NotificationHandler) ResendNotification(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "resend_notification"
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		requestLogger(h.logger, r).Error("notification ID is required")
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Notification ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional; an empty one resends to the original recipient
+	var req ResendNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		requestLogger(h.logger, r).Error("failed to decode request body", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeBodyError(w, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		requestLogger(h.logger, r).Error("invalid request", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeValidationError(w, err)
+		return
+	}
+
+	notification, err := h.notificationService.ResendNotification(r.Context(), id, req.Recipient)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to resend notification",
+			zap.Error(err),
+			zap.String("id", id),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to resend notification", err)
+		return
+	}
+
+	response := newNotificationResponse(notification)
+
+	if err := writeResponse(w, response, http.StatusCreated); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
 		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
 		writeError(w, "Failed to encode response", http.StatusInternalServerError)
 		return
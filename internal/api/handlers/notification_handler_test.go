@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -22,8 +25,8 @@ type MockNotificationService struct {
 	mock.Mock
 }
 
-func (m *MockNotificationService) SendNotification(notification *model.Notification) error {
-	args := m.Called(notification)
+func (m *MockNotificationService) SendNotification(ctx context.Context, notification *model.Notification) error {
+	args := m.Called(ctx, notification)
 	return args.Error(0)
 }
 
@@ -38,8 +41,85 @@ func (m *MockNotificationService) GetNotification(ctx context.Context, id string
 	return args.Get(0).(*model.Notification), nil
 }
 
-func (m *MockNotificationService) GetNotificationsByRecipient(recipient string, limit, offset int) ([]*model.Notification, error) {
-	args := m.Called(recipient, limit, offset)
+func (m *MockNotificationService) GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error) {
+	args := m.Called(ctx, ids)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, recipient, order, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, filters, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, category, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+func (m *MockNotificationService) GetNotificationsByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error) {
+	args := m.Called(ctx, correlationID, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Notification), nil
+}
+
+// ExportNotifications passes the notifications the mock returns to fn, then returns the mock's error
+func (m *MockNotificationService) ExportNotifications(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error {
+	args := m.Called(ctx, recipient, from, to)
+	if notifications, ok := args.Get(0).([]*model.Notification); ok {
+		for _, notification := range notifications {
+			if err := fn(notification); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockNotificationService) RetryNotification(ctx context.Context, id string) (*model.Notification, error) {
+	args := m.Called(ctx, id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Notification), nil
+}
+
+func (m *MockNotificationService) ResendNotification(ctx context.Context, id, recipient string) (*model.Notification, error) {
+	args := m.Called(ctx, id, recipient)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Notification), nil
+}
+
+func (m *MockNotificationService) SendMultiChannel(ctx context.Context, notification *model.Notification, targets []model.ChannelTarget) (*model.NotificationGroup, error) {
+	args := m.Called(ctx, notification, targets)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.NotificationGroup), nil
+}
+
+func (m *MockNotificationService) GetNotificationGroup(ctx context.Context, groupID string) ([]*model.Notification, error) {
+	args := m.Called(ctx, groupID)
 	if args.Error(1) != nil {
 		return nil, args.Error(1)
 	}
@@ -56,6 +136,8 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 		request        SendNotificationRequest
 		setupMock      func()
 		expectedStatus int
+		expectedCode   string
+		expectedField  string
 	}{
 		{
 			name: "successful notification send",
@@ -67,7 +149,22 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 				Priority:  "high",
 			},
 			setupMock: func() {
-				mockService.On("SendNotification", mock.AnythingOfType("*model.Notification")).Return(nil)
+				mockService.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "dry run",
+			request: SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+				Priority:  "high",
+				DryRun:    true,
+			},
+			setupMock: func() {
+				mockService.On("SendNotification", mock.MatchedBy(model.IsDryRun), mock.AnythingOfType("*model.Notification")).Return(nil)
 			},
 			expectedStatus: http.StatusCreated,
 		},
@@ -81,9 +178,42 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 				Priority:  "high",
 			},
 			setupMock: func() {
-				mockService.On("SendNotification", mock.AnythingOfType("*model.Notification")).Return(assert.AnError)
+				mockService.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(assert.AnError)
 			},
-			expectedStatus: http.StatusFailedDependency,
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+		{
+			name: "provider unavailable",
+			request: SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+				Priority:  "high",
+			},
+			setupMock: func() {
+				err := fmt.Errorf("error sending notification: %w", model.ErrProviderFailure{Type: model.EmailNotification, Err: assert.AnError})
+				mockService.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(err)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   ErrorCodeProviderUnavailable,
+		},
+		{
+			name: "rejected by service",
+			request: SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+				Priority:  "high",
+			},
+			setupMock: func() {
+				err := fmt.Errorf("invalid notification: %w", model.ErrInvalidNotification{Message: "invalid priority"})
+				mockService.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(err)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
 		},
 		{
 			name: "missing recipient",
@@ -95,6 +225,92 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 			},
 			setupMock:      func() {},
 			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+			expectedField:  "recipient",
+		},
+		{
+			name: "invalid email",
+			request: SendNotificationRequest{
+				Recipient: "not-an-email",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+				Priority:  "high",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeInvalidRecipient,
+			expectedField:  "recipient",
+		},
+		{
+			name: "push to a device token",
+			request: SendNotificationRequest{
+				Recipient: "740f4707bebcf74f9b7c25d48e3358945f6aa01da5ddb387462c7eaf61bb78ad",
+				Type:      "push",
+				Subject:   "Order shipped",
+				Content:   "Your order is on its way",
+				Priority:  "high",
+			},
+			setupMock: func() {
+				mockService.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "push to an email address",
+			request: SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "push",
+				Subject:   "Order shipped",
+				Content:   "Your order is on its way",
+				Priority:  "high",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeInvalidRecipient,
+			expectedField:  "recipient",
+		},
+		{
+			name: "whatsapp template message",
+			request: SendNotificationRequest{
+				Recipient: "+14155552671",
+				Type:      "whatsapp",
+				Priority:  "high",
+				TemplateData: model.TemplateData{
+					model.TemplateDataWhatsAppTemplate: "order_shipped",
+					"1":                                "Jane",
+				},
+			},
+			setupMock: func() {
+				mockService.On("SendNotification", mock.Anything, mock.AnythingOfType("*model.Notification")).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "whatsapp to an email address",
+			request: SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "whatsapp",
+				Priority:  "high",
+				TemplateData: model.TemplateData{
+					model.TemplateDataWhatsAppTemplate: "order_shipped",
+				},
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeInvalidRecipient,
+			expectedField:  "recipient",
+		},
+		{
+			name: "whatsapp without a template",
+			request: SendNotificationRequest{
+				Recipient: "+14155552671",
+				Type:      "whatsapp",
+				Priority:  "high",
+			},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
 		},
 		{
 			name: "invalid notification type",
@@ -107,6 +323,8 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 			},
 			setupMock:      func() {},
 			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+			expectedField:  "type",
 		},
 		{
 			name: "missing content",
@@ -118,6 +336,23 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 			},
 			setupMock:      func() {},
 			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+			expectedField:  "content",
+		},
+		{
+			name: "priority defaults per type",
+			request: SendNotificationRequest{
+				Recipient: "test@example.com",
+				Type:      "email",
+				Subject:   "Test Subject",
+				Content:   "Test Content",
+			},
+			setupMock: func() {
+				mockService.On("SendNotification", mock.Anything, mock.MatchedBy(func(n *model.Notification) bool {
+					return n.Priority == ""
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
 		},
 		{
 			name: "invalid priority",
@@ -130,6 +365,8 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 			},
 			setupMock:      func() {},
 			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+			expectedField:  "priority",
 		},
 	}
 
@@ -152,6 +389,18 @@ func TestNotificationHandler_SendNotification(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedField != "" {
+				var response ValidationErrorResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				fields := make([]string, 0, len(response.Fields))
+				for _, field := range response.Fields {
+					fields = append(fields, field.Field)
+				}
+				assert.Equal(t, []string{tt.expectedField}, fields)
+			}
 			mockService.AssertExpectations(t)
 		})
 	}
@@ -162,15 +411,19 @@ func TestNotificationHandler_GetNotification(t *testing.T) {
 	mockService := new(MockNotificationService)
 	handler := NewNotificationHandler(mockService, logger)
 
+	nextRetryAt := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
 	notification := &model.Notification{
-		ID:        uuid.New(),
-		Recipient: "test@example.com",
-		Type:      model.EmailNotification,
-		Subject:   "Test Subject",
-		Content:   "Test Content",
-		Status:    model.StatusSent,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:                uuid.New(),
+		Recipient:         "test@example.com",
+		Type:              model.EmailNotification,
+		Subject:           "Test Subject",
+		Content:           "Test Content",
+		Status:            model.StatusPending,
+		RetryCount:        2,
+		NextRetryAt:       &nextRetryAt,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		ProviderMessageID: "message-1",
 	}
 
 	tests := []struct {
@@ -178,6 +431,7 @@ func TestNotificationHandler_GetNotification(t *testing.T) {
 		notificationID string
 		setupMock      func()
 		expectedStatus int
+		expectedCode   string
 	}{
 		{
 			name:           "successful get",
@@ -191,9 +445,10 @@ func TestNotificationHandler_GetNotification(t *testing.T) {
 			name:           "not found",
 			notificationID: "non-existent",
 			setupMock: func() {
-				mockService.On("GetNotification", mock.Anything, "non-existent").Return(nil, nil)
+				mockService.On("GetNotification", mock.Anything, "non-existent").Return(nil, model.ErrNotificationNotFound{ID: "non-existent"})
 			},
 			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
 		},
 		{
 			name:           "service error",
@@ -201,7 +456,8 @@ func TestNotificationHandler_GetNotification(t *testing.T) {
 			setupMock: func() {
 				mockService.On("GetNotification", mock.Anything, notification.ID.String()).Return(nil, assert.AnError)
 			},
-			expectedStatus: http.StatusFailedDependency,
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
 		},
 	}
 
@@ -228,6 +484,17 @@ func TestNotificationHandler_GetNotification(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response NotificationResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, "message-1", response.ProviderMessageID)
+				assert.Equal(t, 2, response.RetryCount)
+				require.NotNil(t, response.NextRetryAt)
+				assert.True(t, nextRetryAt.Equal(*response.NextRetryAt))
+			}
 			mockService.AssertExpectations(t)
 		})
 	}
@@ -264,30 +531,51 @@ func TestNotificationHandler_GetNotificationsByRecipient(t *testing.T) {
 	tests := []struct {
 		name           string
 		recipient      string
+		order          string
 		setupMock      func()
 		expectedStatus int
+		expectedCode   string
 	}{
 		{
 			name:      "successful get",
 			recipient: "test@example.com",
 			setupMock: func() {
-				mockService.On("GetNotificationsByRecipient", "test@example.com", 10, 0).Return(notifications, nil)
+				mockService.On("GetNotificationsByRecipient", mock.Anything, "test@example.com", model.SortDescending, 10, 0).Return(notifications, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "oldest first",
+			recipient: "test@example.com",
+			order:     "asc",
+			setupMock: func() {
+				mockService.On("GetNotificationsByRecipient", mock.Anything, "test@example.com", model.SortAscending, 10, 0).Return(notifications, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "invalid order",
+			recipient:      "test@example.com",
+			order:          "newest",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
 		{
 			name:           "missing recipient",
 			recipient:      "",
 			setupMock:      func() {},
 			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
 		},
 		{
 			name:      "service error",
 			recipient: "test@example.com",
 			setupMock: func() {
-				mockService.On("GetNotificationsByRecipient", "test@example.com", 10, 0).Return([]*model.Notification(nil), assert.AnError)
+				mockService.On("GetNotificationsByRecipient", mock.Anything, "test@example.com", model.SortDescending, 10, 0).Return([]*model.Notification(nil), assert.AnError)
 			},
-			expectedStatus: http.StatusFailedDependency,
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
 		},
 	}
 
@@ -305,6 +593,9 @@ func TestNotificationHandler_GetNotificationsByRecipient(t *testing.T) {
 			if tt.recipient != "" {
 				url += "?recipient=" + tt.recipient
 			}
+			if tt.order != "" {
+				url += "&order=" + tt.order
+			}
 			req := httptest.NewRequest(http.MethodGet, url, nil)
 			rec := httptest.NewRecorder()
 
@@ -313,7 +604,667 @@ func TestNotificationHandler_GetNotificationsByRecipient(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_GetNotificationsByMetadata(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService, logger)
+
+	notifications := []*model.Notification{
+		{
+			ID:        uuid.New(),
+			Recipient: "test@example.com",
+			Type:      model.EmailNotification,
+			Status:    model.StatusSent,
+			Metadata:  map[string]string{"userId": "user-1", "eventType": "user.registered"},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expectedCount  int
+	}{
+		{
+			name:  "single filter",
+			query: "?meta.userId=user-1",
+			setupMock: func() {
+				mockService.On("GetNotificationsByMetadata", mock.Anything, map[string]string{"userId": "user-1"}, defaultAdminPageSize, 0).Return(notifications, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:  "several filters with a page",
+			query: "?meta.userId=user-1&meta.eventType=user.registered&limit=5&offset=10",
+			setupMock: func() {
+				mockService.On("GetNotificationsByMetadata", mock.Anything, map[string]string{"userId": "user-1", "eventType": "user.registered"}, 5, 10).Return([]*model.Notification{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "repeated filter",
+			query:          "?meta.userId=user-1&meta.userId=user-2",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "empty key",
+			query:          "?meta.=user-1",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "with recipient",
+			query:          "?meta.userId=user-1&recipient=test@example.com",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "store can't query metadata",
+			query: "?meta.userId=user-1",
+			setupMock: func() {
+				mockService.On("GetNotificationsByMetadata", mock.Anything, map[string]string{"userId": "user-1"}, defaultAdminPageSize, 0).Return([]*model.Notification(nil), model.ErrMetadataQueryUnsupported{})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "service error",
+			query: "?meta.userId=user-1",
+			setupMock: func() {
+				mockService.On("GetNotificationsByMetadata", mock.Anything, map[string]string{"userId": "user-1"}, defaultAdminPageSize, 0).Return([]*model.Notification(nil), assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			router := chi.NewRouter()
+			handler.RegisterRoutes(router)
+
+			// Execute request
+			req := httptest.NewRequest(http.MethodGet, "/notifications"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response []NotificationResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Len(t, response, tt.expectedCount)
+			}
 			mockService.AssertExpectations(t)
 		})
 	}
 }
+
+func TestNotificationHandler_GetNotificationsByCategory(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService, logger)
+
+	notifications := []*model.Notification{
+		{
+			ID:        uuid.New(),
+			Recipient: "test@example.com",
+			Type:      model.EmailNotification,
+			Status:    model.StatusSent,
+			Category:  model.CategorySecurity,
+			Tags:      []string{"password"},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expectedCount  int
+	}{
+		{
+			name:  "category",
+			query: "?category=security",
+			setupMock: func() {
+				mockService.On("GetNotificationsByCategory", mock.Anything, "security", defaultAdminPageSize, 0).Return(notifications, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:  "category with a page",
+			query: "?category=security&limit=5&offset=10",
+			setupMock: func() {
+				mockService.On("GetNotificationsByCategory", mock.Anything, "security", 5, 10).Return([]*model.Notification{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "empty category",
+			query:          "?category=",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "with metadata filter",
+			query:          "?category=security&meta.userId=user-1",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "store can't query categories",
+			query: "?category=security",
+			setupMock: func() {
+				mockService.On("GetNotificationsByCategory", mock.Anything, "security", defaultAdminPageSize, 0).Return([]*model.Notification(nil), model.ErrCategoryQueryUnsupported{})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			router := chi.NewRouter()
+			handler.RegisterRoutes(router)
+
+			// Execute request
+			req := httptest.NewRequest(http.MethodGet, "/notifications"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response []NotificationResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				require.Len(t, response, tt.expectedCount)
+				if tt.expectedCount > 0 {
+					assert.Equal(t, "security", response[0].Category)
+					assert.Equal(t, []string{"password"}, response[0].Tags)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_GetNotificationsByCorrelationID(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService, logger)
+
+	notifications := []*model.Notification{
+		{
+			ID:            uuid.New(),
+			Recipient:     "first@example.com",
+			Type:          model.EmailNotification,
+			Status:        model.StatusSent,
+			CorrelationID: "req-123",
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+		{
+			ID:            uuid.New(),
+			Recipient:     "second@example.com",
+			Type:          model.EmailNotification,
+			Status:        model.StatusSent,
+			CorrelationID: "req-123",
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expectedCount  int
+	}{
+		{
+			name:  "correlation ID",
+			query: "?correlation_id=req-123",
+			setupMock: func() {
+				mockService.On("GetNotificationsByCorrelationID", mock.Anything, "req-123", defaultAdminPageSize, 0).Return(notifications, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  2,
+		},
+		{
+			name:           "empty correlation ID",
+			query:          "?correlation_id=",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "with category",
+			query:          "?correlation_id=req-123&category=security",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			router := chi.NewRouter()
+			router.Use(RequestIDMiddleware)
+			handler.RegisterRoutes(router)
+
+			// Execute request
+			req := httptest.NewRequest(http.MethodGet, "/notifications"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response []NotificationResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				require.Len(t, response, tt.expectedCount)
+				for _, notification := range response {
+					assert.Equal(t, "req-123", notification.CorrelationID)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_GetNotificationStatuses(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService, logger)
+
+	sent := &model.Notification{ID: uuid.New(), Status: model.StatusSent}
+	failed := &model.Notification{ID: uuid.New(), Status: model.StatusFailed, ErrorMessage: "provider unavailable"}
+	ids := []string{sent.ID.String(), failed.ID.String()}
+
+	tooMany := make([]string, MaxStatusLookupIDs+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New().String()
+	}
+
+	tests := []struct {
+		name             string
+		ids              []string
+		setupMock        func()
+		expectedStatus   int
+		expectedCode     string
+		expectedStatuses []NotificationStatusResponse
+	}{
+		{
+			name: "successful lookup",
+			ids:  ids,
+			setupMock: func() {
+				mockService.On("GetNotificationsByIDs", mock.Anything, ids).Return([]*model.Notification{sent, failed}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedStatuses: []NotificationStatusResponse{
+				{ID: sent.ID.String(), Status: "sent"},
+				{ID: failed.ID.String(), Status: "failed", Error: "provider unavailable"},
+			},
+		},
+		{
+			name:           "no ids",
+			ids:            []string{},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "invalid id",
+			ids:            []string{"not-a-uuid"},
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "too many ids",
+			ids:            tooMany,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name: "service error",
+			ids:  ids,
+			setupMock: func() {
+				mockService.On("GetNotificationsByIDs", mock.Anything, ids).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			body, _ := json.Marshal(NotificationStatusRequest{IDs: tt.ids})
+			req := httptest.NewRequest(http.MethodPost, "/notifications/status", bytes.NewBuffer(body))
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.GetNotificationStatuses(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatuses != nil {
+				var response []NotificationStatusResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tt.expectedStatuses, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_RetryNotification(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService, logger)
+
+	notification := &model.Notification{
+		ID:         uuid.New(),
+		Recipient:  "test@example.com",
+		Type:       model.EmailNotification,
+		Status:     model.StatusSent,
+		RetryCount: 1,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	id := notification.ID.String()
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "successful retry",
+			setupMock: func() {
+				mockService.On("RetryNotification", mock.Anything, id).Return(notification, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not found",
+			setupMock: func() {
+				mockService.On("RetryNotification", mock.Anything, id).Return(nil, model.ErrNotificationNotFound{ID: id})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+		{
+			name: "not in failed state",
+			setupMock: func() {
+				mockService.On("RetryNotification", mock.Anything, id).Return(nil, model.ErrNotificationNotRetryable{ID: id, Status: model.StatusSent})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   ErrorCodeConflict,
+		},
+		{
+			name: "send fails again",
+			setupMock: func() {
+				mockService.On("RetryNotification", mock.Anything, id).Return(nil, model.ErrProviderFailure{Type: model.EmailNotification, Err: assert.AnError})
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   ErrorCodeProviderUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/notifications/"+id+"/retry", nil)
+			rec := httptest.NewRecorder()
+
+			// Setup chi router context
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			// Execute request
+			handler.RetryNotification(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_ResendNotification(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService, logger)
+
+	id := uuid.New().String()
+	resend := &model.Notification{
+		ID:        uuid.New(),
+		Recipient: "test@example.com",
+		Type:      model.EmailNotification,
+		Status:    model.StatusPending,
+		Metadata:  map[string]string{model.MetadataResendOf: id},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "resend to original recipient",
+			setupMock: func() {
+				mockService.On("ResendNotification", mock.Anything, id, "").Return(resend, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "resend to another recipient",
+			body: `{"recipient": "other@example.com"}`,
+			setupMock: func() {
+				mockService.On("ResendNotification", mock.Anything, id, "other@example.com").Return(resend, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid recipient",
+			body:           `{"recipient": "not-an-email"}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeInvalidRecipient,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"recipient":`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name: "not found",
+			setupMock: func() {
+				mockService.On("ResendNotification", mock.Anything, id, "").Return(nil, model.ErrNotificationNotFound{ID: id})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/notifications/"+id+"/resend", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			// Setup chi router context
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			// Execute request
+			handler.ResendNotification(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusCreated {
+				var response NotificationResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, id, response.Metadata[model.MetadataResendOf])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNotificationHandler_ResponseShape(t *testing.T) {
+	created := time.Date(2025, 1, 20, 9, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	notifications := []*model.Notification{{
+		ID:        uuid.New(),
+		Recipient: "user@example.com",
+		Type:      model.EmailNotification,
+		Subject:   "Welcome",
+		Content:   "Hi",
+		Status:    model.StatusSent,
+		Metadata:  map[string]string{},
+		Tags:      []string{},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}}
+
+	list := func(t *testing.T, query string) (*httptest.ResponseRecorder, []map[string]interface{}) {
+		mockService := new(MockNotificationService)
+		mockService.On("GetNotificationsByRecipient", mock.Anything, "user@example.com", model.SortDescending, 10, 0).Return(notifications, nil).Maybe()
+
+		rec := httptest.NewRecorder()
+		newNotificationRouter(mockService).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notifications?recipient=user@example.com"+query, nil))
+		var response []map[string]interface{}
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		}
+		return rec, response
+	}
+
+	t.Run("leaves out empty optional fields and writes timestamps in UTC", func(t *testing.T) {
+		rec, response := list(t, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, response, 1)
+
+		for _, field := range []string{"metadata", "tags", "cc", "category", "next_retry_at", "scheduled_at", "provider_message_id"} {
+			assert.NotContains(t, response[0], field)
+		}
+		// Fields clients already rely on are still written when empty
+		assert.Contains(t, response[0], "retry_count")
+		assert.Equal(t, "2025-01-20T14:30:00Z", response[0]["created_at"])
+		assert.Equal(t, "2025-01-20T14:30:00Z", response[0]["updated_at"])
+	})
+
+	t.Run("narrows to the fields asked for", func(t *testing.T) {
+		rec, response := list(t, "&fields=status,%20created_at,category")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, response, 1)
+		assert.Equal(t, map[string]interface{}{
+			"id":         notifications[0].ID.String(),
+			"status":     "sent",
+			"created_at": "2025-01-20T14:30:00Z",
+		}, response[0])
+	})
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		rec, _ := list(t, "&fields=status,password")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assertErrorCode(t, rec, ErrorCodeValidationFailed)
+		assert.Contains(t, rec.Body.String(), "unknown field: password")
+	})
+}
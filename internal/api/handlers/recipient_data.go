@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// RecipientNotificationExport is one of a recipient's notifications with
+// everything stored about it
+type RecipientNotificationExport struct {
+	AdminNotificationResponse
+	Priority     string             `json:"priority"`
+	TemplateData model.TemplateData `json:"template_data,omitempty"`
+}
+
+// RecipientDataResponse is everything stored about a recipient
+type RecipientDataResponse struct {
+	Recipient     string                        `json:"recipient"`
+	ExportedAt    time.Time                     `json:"exported_at"`
+	Notifications []RecipientNotificationExport `json:"notifications"`
+	Suppression   *SuppressionResponse          `json:"suppression,omitempty"`
+}
+
+// RecipientErasureResponse reports a recipient's data being erased, or what
+// would be erased for a dry run
+type RecipientErasureResponse struct {
+	Recipient     string `json:"recipient"`
+	ErasedAs      string `json:"erased_as,omitempty"`
+	Notifications int    `json:"notifications"`
+	DryRun        bool   `json:"dry_run"`
+}
+
+// recipientParam reads the recipient from the URL path
+func recipientParam(r *http.Request) (string, bool) {
+	recipient, err := url.PathUnescape(chi.URLParam(r, "recipient"))
+	return recipient, err == nil && recipient != ""
+}
+
+// ExportRecipientData handles the request to export everything stored about a recipient
+func (h *AdminHandler) ExportRecipientData(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_export_recipient_data"
+
+	recipient, ok := recipientParam(r)
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A recipient is required", http.StatusBadRequest)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("exporting recipient data",
+		zap.String("tenant_id", model.TenantFromContext(r.Context())),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	data, err := h.adminService.ExportRecipientData(r.Context(), recipient)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to export recipient data", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to export recipient data", err)
+		return
+	}
+
+	response := RecipientDataResponse{
+		Recipient:     data.Recipient,
+		ExportedAt:    start,
+		Notifications: make([]RecipientNotificationExport, 0, len(data.Notifications)),
+	}
+	for _, notification := range data.Notifications {
+		response.Notifications = append(response.Notifications, RecipientNotificationExport{
+			AdminNotificationResponse: AdminNotificationResponse{
+				NotificationResponse: newNotificationResponse(notification),
+				ErrorMessage:         notification.ErrorMessage,
+			},
+			Priority:     string(notification.Priority),
+			TemplateData: notification.TemplateData,
+		})
+	}
+	if data.Suppression != nil {
+		suppression := newSuppressionResponse(data.Suppression)
+		response.Suppression = &suppression
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// EraseRecipientData handles the request to erase a recipient's personal data.
+// With ?dry_run=true nothing is erased; the response reports what would be.
+func (h *AdminHandler) EraseRecipientData(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "admin_erase_recipient_data"
+
+	recipient, ok := recipientParam(r)
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A recipient is required", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+			writeError(w, "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	requestLogger(h.logger, r).Info("erasing recipient data",
+		zap.String("tenant_id", model.TenantFromContext(r.Context())),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.Bool("dry_run", dryRun),
+	)
+
+	erasure, err := h.adminService.EraseRecipientData(r.Context(), recipient, dryRun)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to erase recipient data", zap.Error(err), zap.Bool("dry_run", dryRun))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to erase recipient data", err)
+		return
+	}
+
+	response := RecipientErasureResponse{
+		Recipient:     erasure.Recipient,
+		ErasedAs:      erasure.ErasedAs,
+		Notifications: erasure.Notifications,
+		DryRun:        erasure.DryRun,
+	}
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newRecipientDataRouter serves the admin routes behind an API key, as the recipient data routes require
+func newRecipientDataRouter(service AdminService) http.Handler {
+	router := chi.NewRouter()
+	router.Use(TenantMiddleware(map[string]string{"admin-key": "acme"}))
+	NewAdminHandler(service, zap.NewNop()).RegisterRoutes(router)
+	return router
+}
+
+func TestAdminHandler_ExportRecipientData(t *testing.T) {
+	mockService := new(MockAdminService)
+	router := newRecipientDataRouter(mockService)
+
+	notification := &model.Notification{
+		ID:           uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"),
+		Recipient:    "user@example.com",
+		Type:         model.EmailNotification,
+		Subject:      "Welcome",
+		Status:       model.StatusSent,
+		Priority:     model.PriorityMedium,
+		TemplateData: model.TemplateData{"name": "Ada"},
+		CreatedAt:    time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC),
+	}
+	suppression := &model.Suppression{
+		Recipient: "user@example.com",
+		Reason:    model.SuppressionHardBounce,
+		Provider:  "sendgrid",
+		CreatedAt: time.Date(2025, 1, 11, 9, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		apiKey         string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:   "exports notifications and suppression",
+			path:   "/admin/recipients/user@example.com/export",
+			apiKey: "admin-key",
+			setupMock: func() {
+				mockService.On("ExportRecipientData", mock.Anything, "user@example.com").Return(&model.RecipientData{
+					Recipient:     "user@example.com",
+					Notifications: []*model.Notification{notification},
+					Suppression:   suppression,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "escaped recipient",
+			path:   "/admin/recipients/%2B14155552671/export",
+			apiKey: "admin-key",
+			setupMock: func() {
+				mockService.On("ExportRecipientData", mock.Anything, "+14155552671").
+					Return(&model.RecipientData{Recipient: "+14155552671"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "requires an API key",
+			path:           "/admin/recipients/user@example.com/export",
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   ErrorCodeUnauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("response body", func(t *testing.T) {
+		mockService.ExpectedCalls = nil
+		mockService.Calls = nil
+		mockService.On("ExportRecipientData", mock.Anything, "user@example.com").Return(&model.RecipientData{
+			Recipient:     "user@example.com",
+			Notifications: []*model.Notification{notification},
+			Suppression:   suppression,
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/recipients/user@example.com/export", nil)
+		req.Header.Set(APIKeyHeader, "admin-key")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response RecipientDataResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, "user@example.com", response.Recipient)
+		require.Len(t, response.Notifications, 1)
+		assert.Equal(t, notification.ID.String(), response.Notifications[0].ID)
+		assert.Equal(t, "Welcome", response.Notifications[0].Subject)
+		assert.Equal(t, "medium", response.Notifications[0].Priority)
+		assert.Equal(t, model.TemplateData{"name": "Ada"}, response.Notifications[0].TemplateData)
+		require.NotNil(t, response.Suppression)
+		assert.Equal(t, "hard_bounce", response.Suppression.Reason)
+	})
+}
+
+func TestAdminHandler_EraseRecipientData(t *testing.T) {
+	mockService := new(MockAdminService)
+	router := newRecipientDataRouter(mockService)
+
+	erasedAs := model.NewErasedRecipient()
+
+	tests := []struct {
+		name           string
+		query          string
+		apiKey         string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expected       RecipientErasureResponse
+	}{
+		{
+			name:   "erases",
+			apiKey: "admin-key",
+			setupMock: func() {
+				mockService.On("EraseRecipientData", mock.Anything, "user@example.com", false).Return(&model.RecipientErasure{
+					Recipient:     "user@example.com",
+					ErasedAs:      erasedAs,
+					Notifications: 3,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expected:       RecipientErasureResponse{Recipient: "user@example.com", ErasedAs: erasedAs, Notifications: 3},
+		},
+		{
+			name:   "dry run",
+			query:  "?dry_run=true",
+			apiKey: "admin-key",
+			setupMock: func() {
+				mockService.On("EraseRecipientData", mock.Anything, "user@example.com", true).Return(&model.RecipientErasure{
+					Recipient:     "user@example.com",
+					Notifications: 3,
+					DryRun:        true,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expected:       RecipientErasureResponse{Recipient: "user@example.com", Notifications: 3, DryRun: true},
+		},
+		{
+			name:           "invalid dry_run",
+			query:          "?dry_run=maybe",
+			apiKey:         "admin-key",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:   "erasure unsupported",
+			apiKey: "admin-key",
+			setupMock: func() {
+				mockService.On("EraseRecipientData", mock.Anything, "user@example.com", false).
+					Return(nil, model.ErrErasureUnsupported{})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "requires an API key",
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   ErrorCodeUnauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodDelete, "/admin/recipients/user@example.com"+tt.query, nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response RecipientErasureResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tt.expected, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// SuppressionHandler handles HTTP requests for the suppression list
+type SuppressionHandler struct {
+	suppressionService SuppressionService
+	logger             *zap.Logger
+}
+
+// SuppressionService defines the interface for suppression list operations
+type SuppressionService interface {
+	List(ctx context.Context, limit, offset int) ([]*model.Suppression, error)
+	Delete(ctx context.Context, recipient string) error
+}
+
+// NewSuppressionHandler creates a new suppression handler
+func NewSuppressionHandler(service SuppressionService, logger *zap.Logger) *SuppressionHandler {
+	return &SuppressionHandler{
+		suppressionService: service,
+		logger:             logger,
+	}
+}
+
+// SuppressionResponse represents a recipient on the suppression list
+type SuppressionResponse struct {
+	Recipient string    `json:"recipient"`
+	Reason    string    `json:"reason"`
+	Provider  string    `json:"provider"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newSuppressionResponse converts a suppression list entry to its response
+func newSuppressionResponse(suppression *model.Suppression) SuppressionResponse {
+	return SuppressionResponse{
+		Recipient: suppression.Recipient,
+		Reason:    string(suppression.Reason),
+		Provider:  suppression.Provider,
+		Detail:    suppression.Detail,
+		CreatedAt: suppression.CreatedAt,
+	}
+}
+
+// SuppressionListResponse represents a page of the suppression list, newest first
+type SuppressionListResponse struct {
+	Suppressions []SuppressionResponse `json:"suppressions"`
+	Limit        int                   `json:"limit"`
+	Offset       int                   `json:"offset"`
+}
+
+// RegisterRoutes registers the suppression routes
+func (h *SuppressionHandler) RegisterRoutes(r chi.Router) {
+	r = r.With(NegotiateContentType(JSONMediaType))
+	r.Get("/suppressions", h.ListSuppressions)
+	r.Delete("/suppressions/{recipient}", h.DeleteSuppression)
+}
+
+// ListSuppressions handles the request to list suppressed recipients
+func (h *SuppressionHandler) ListSuppressions(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "list_suppressions"
+
+	limit, offset, ok := parsePagination(r, defaultAdminPageSize, MaxAdminPageSize)
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Invalid limit or offset", http.StatusBadRequest)
+		return
+	}
+
+	suppressions, err := h.suppressionService.List(r.Context(), limit, offset)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to list suppressions", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to list suppressions", err)
+		return
+	}
+
+	response := SuppressionListResponse{
+		Suppressions: make([]SuppressionResponse, 0, len(suppressions)),
+		Limit:        limit,
+		Offset:       offset,
+	}
+	for _, suppression := range suppressions {
+		response.Suppressions = append(response.Suppressions, newSuppressionResponse(suppression))
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// DeleteSuppression handles the request to remove a recipient from the suppression list,
+// so they can be emailed again
+func (h *SuppressionHandler) DeleteSuppression(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "delete_suppression"
+
+	recipient, err := url.PathUnescape(chi.URLParam(r, "recipient"))
+	if err != nil || recipient == "" {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A recipient is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.suppressionService.Delete(r.Context(), recipient); err != nil {
+		requestLogger(h.logger, r).Error("failed to delete suppression",
+			zap.Error(err),
+			zap.String("recipient", recipient),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to delete suppression", err)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("removed recipient from suppression list", zap.String("recipient", recipient))
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockSuppressionService is a mock implementation of SuppressionService
+type MockSuppressionService struct {
+	mock.Mock
+}
+
+func (m *MockSuppressionService) List(ctx context.Context, limit, offset int) ([]*model.Suppression, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Suppression), nil
+}
+
+func (m *MockSuppressionService) Delete(ctx context.Context, recipient string) error {
+	args := m.Called(ctx, recipient)
+	return args.Error(0)
+}
+
+func TestSuppressionHandler_ListSuppressions(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockSuppressionService)
+	handler := NewSuppressionHandler(mockService, logger)
+
+	bounced := &model.Suppression{
+		Recipient: "user@example.com",
+		Reason:    model.SuppressionHardBounce,
+		Provider:  "ses",
+		Detail:    "smtp; 550 5.1.1 user unknown",
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expectedCount  int
+	}{
+		{
+			name:  "default page",
+			query: "",
+			setupMock: func() {
+				mockService.On("List", mock.Anything, defaultAdminPageSize, 0).Return([]*model.Suppression{bounced}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:  "explicit page",
+			query: "?limit=10&offset=20",
+			setupMock: func() {
+				mockService.On("List", mock.Anything, 10, 20).Return([]*model.Suppression{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid offset",
+			query:          "?offset=-1",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:  "service error",
+			query: "",
+			setupMock: func() {
+				mockService.On("List", mock.Anything, defaultAdminPageSize, 0).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodGet, "/suppressions"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.ListSuppressions(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response SuppressionListResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Len(t, response.Suppressions, tt.expectedCount)
+				if tt.expectedCount > 0 {
+					assert.Equal(t, "user@example.com", response.Suppressions[0].Recipient)
+					assert.Equal(t, "hard_bounce", response.Suppressions[0].Reason)
+				}
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSuppressionHandler_DeleteSuppression(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockSuppressionService)
+	handler := NewSuppressionHandler(mockService, logger)
+
+	tests := []struct {
+		name           string
+		path           string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "removed",
+			path: "/suppressions/user@example.com",
+			setupMock: func() {
+				mockService.On("Delete", mock.Anything, "user@example.com").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "escaped recipient",
+			path: "/suppressions/user%2Btag@example.com",
+			setupMock: func() {
+				mockService.On("Delete", mock.Anything, "user+tag@example.com").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "not suppressed",
+			path: "/suppressions/user@example.com",
+			setupMock: func() {
+				mockService.On("Delete", mock.Anything, "user@example.com").Return(model.ErrSuppressionNotFound{Recipient: "user@example.com"})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+		{
+			name: "service error",
+			path: "/suppressions/user@example.com",
+			setupMock: func() {
+				mockService.On("Delete", mock.Anything, "user@example.com").Return(assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			router := chi.NewRouter()
+			handler.RegisterRoutes(router)
+
+			// Execute request
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// TemplateHandler handles HTTP requests for templates
+type TemplateHandler struct {
+	templateService TemplateService
+	templateSyncer  TemplateSyncer
+	previewer       TemplatePreviewer
+	logger          *zap.Logger
+}
+
+// TemplateService defines the interface for template history operations
+type TemplateService interface {
+	GetTemplateVersions(ctx context.Context, id uuid.UUID) ([]*model.TemplateVersion, error)
+	RollbackTemplate(ctx context.Context, id uuid.UUID, version int) (*model.Template, error)
+	SetTemplateActive(ctx context.Context, id uuid.UUID, active bool) (*model.Template, error)
+}
+
+// TemplateSyncer defines the interface for loading templates from files
+type TemplateSyncer interface {
+	Sync(ctx context.Context) (*model.TemplateSyncResult, error)
+}
+
+// TemplatePreviewer defines the interface for rendering a template in every available locale
+type TemplatePreviewer interface {
+	PreviewTemplateLocales(ctx context.Context, name string, data map[string]interface{}) (*model.TemplateLocalePreview, error)
+}
+
+// NewTemplateHandler creates a new template handler. syncer may be nil when
+// templates aren't loaded from files.
+func NewTemplateHandler(service TemplateService, syncer TemplateSyncer, logger *zap.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: service,
+		templateSyncer:  syncer,
+		logger:          logger,
+	}
+}
+
+// SetPreviewer sets what renders templates for GET /templates/{name}/preview-all;
+// without one, previews are reported as unavailable
+func (h *TemplateHandler) SetPreviewer(previewer TemplatePreviewer) {
+	h.previewer = previewer
+}
+
+// RollbackTemplateRequest represents the request to restore a previous template version
+type RollbackTemplateRequest struct {
+	Version int `json:"version"`
+}
+
+// TemplateVersionResponse represents a previous version of a template
+type TemplateVersionResponse struct {
+	Version   int       `json:"version"`
+	Subject   string    `json:"subject"`
+	Content   string    `json:"content"`
+	Variables []string  `json:"variables"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TemplateVersionListResponse represents a template's version history, newest first
+type TemplateVersionListResponse struct {
+	TemplateID string                    `json:"template_id"`
+	Versions   []TemplateVersionResponse `json:"versions"`
+}
+
+// TemplateResponse represents the current state of a template
+type TemplateResponse struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Type      model.TemplateType `json:"type"`
+	Subject   string             `json:"subject"`
+	Content   string             `json:"content"`
+	Variables []string           `json:"variables"`
+	Version   int                `json:"version"`
+	IsActive  bool               `json:"is_active"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+func newTemplateResponse(template *model.Template) TemplateResponse {
+	return TemplateResponse{
+		ID:        template.ID.String(),
+		Name:      template.Name,
+		Type:      template.Type,
+		Subject:   template.Subject,
+		Content:   template.Content,
+		Variables: template.Variables,
+		Version:   template.Version,
+		IsActive:  template.IsActive,
+		UpdatedAt: template.UpdatedAt,
+	}
+}
+
+// TemplatePreviewResponse represents a template rendered in every available
+// locale. Missing lists the locales it has no translation into, and Errors why
+// a translation failed to render.
+type TemplatePreviewResponse struct {
+	Name          string            `json:"name"`
+	DefaultLocale string            `json:"default_locale"`
+	Rendered      map[string]string `json:"rendered"`
+	Missing       []string          `json:"missing"`
+	Errors        map[string]string `json:"errors"`
+}
+
+// ValidateTemplateRequest represents a template to check before saving it
+type ValidateTemplateRequest struct {
+	Name      string   `json:"name"`
+	Content   string   `json:"content"`
+	Variables []string `json:"variables"`
+}
+
+// ValidateTemplateResponse reports the problems found in a template. Errors make
+// the template invalid; undeclared and unused variables are warnings.
+type ValidateTemplateResponse struct {
+	Valid               bool     `json:"valid"`
+	Errors              []string `json:"errors"`
+	UndeclaredVariables []string `json:"undeclared_variables"`
+	UnusedVariables     []string `json:"unused_variables"`
+}
+
+// RegisterRoutes registers the template routes
+func (h *TemplateHandler) RegisterRoutes(r chi.Router) {
+	r = r.With(NegotiateContentType(JSONMediaType))
+	r.Post("/templates/validate", h.ValidateTemplate)
+	r.Get("/templates/{id}/versions", h.GetTemplateVersions)
+	r.Get("/templates/{name}/preview-all", h.PreviewTemplateLocales)
+	r.Post("/templates/{id}/rollback", h.RollbackTemplate)
+	r.Post("/templates/{id}/activate", h.ActivateTemplate)
+	r.Post("/templates/{id}/deactivate", h.DeactivateTemplate)
+	r.Post("/admin/templates/sync", h.SyncTemplates)
+}
+
+// GetTemplateVersions handles the request to list a template's previous versions
+func (h *TemplateHandler) GetTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "get_template_versions"
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A valid template ID is required", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.templateService.GetTemplateVersions(r.Context(), id)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to get template versions",
+			zap.Error(err),
+			zap.String("id", id.String()),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to get template versions", err)
+		return
+	}
+
+	response := TemplateVersionListResponse{
+		TemplateID: id.String(),
+		Versions:   make([]TemplateVersionResponse, 0, len(versions)),
+	}
+	for _, version := range versions {
+		response.Versions = append(response.Versions, TemplateVersionResponse{
+			Version:   version.Version,
+			Subject:   version.Subject,
+			Content:   version.Content,
+			Variables: version.Variables,
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// RollbackTemplate handles the request to restore a previous template version as the current one
+func (h *TemplateHandler) RollbackTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "rollback_template"
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A valid template ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req RollbackTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestLogger(h.logger, r).Error("failed to decode request body", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeBodyError(w, err)
+		return
+	}
+
+	if req.Version < 1 {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "version must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.templateService.RollbackTemplate(r.Context(), id, req.Version)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to roll back template",
+			zap.Error(err),
+			zap.String("id", id.String()),
+			zap.Int("version", req.Version),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to roll back template", err)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("rolled back template",
+		zap.String("id", id.String()),
+		zap.Int("restored_version", req.Version),
+		zap.Int("version", template.Version),
+	)
+
+	if err := writeResponse(w, newTemplateResponse(template), http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ActivateTemplate handles the request to activate a template
+func (h *TemplateHandler) ActivateTemplate(w http.ResponseWriter, r *http.Request) {
+	h.setTemplateActive(w, r, "activate_template", true)
+}
+
+// DeactivateTemplate handles the request to deactivate a template
+func (h *TemplateHandler) DeactivateTemplate(w http.ResponseWriter, r *http.Request) {
+	h.setTemplateActive(w, r, "deactivate_template", false)
+}
+
+// setTemplateActive activates or deactivates the template named by the request's ID
+func (h *TemplateHandler) setTemplateActive(w http.ResponseWriter, r *http.Request, operation string, active bool) {
+	start := time.Now()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "A valid template ID is required", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.templateService.SetTemplateActive(r.Context(), id, active)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to set template active state",
+			zap.Error(err),
+			zap.String("id", id.String()),
+			zap.Bool("active", active),
+		)
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to update template", err)
+		return
+	}
+
+	requestLogger(h.logger, r).Info("set template active state",
+		zap.String("id", id.String()),
+		zap.Bool("active", active),
+		zap.Int("version", template.Version),
+	)
+
+	if err := writeResponse(w, newTemplateResponse(template), http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// SyncTemplates handles the request to reload templates from the templates directory
+func (h *TemplateHandler) SyncTemplates(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "sync_templates"
+
+	if h.templateSyncer == nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to sync templates", model.ErrTemplateSyncNotConfigured{})
+		return
+	}
+
+	result, err := h.templateSyncer.Sync(r.Context())
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to sync templates", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to sync templates", err)
+		return
+	}
+
+	if err := writeResponse(w, result, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// ValidateTemplate handles the request to check a template's content without saving it
+func (h *TemplateHandler) ValidateTemplate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "validate_template"
+
+	var req ValidateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestLogger(h.logger, r).Error("failed to decode request body", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeBodyError(w, err)
+		return
+	}
+
+	if req.Content == "" {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "content is required", http.StatusBadRequest)
+		return
+	}
+
+	template := &model.Template{Name: req.Name, Content: req.Content, Variables: req.Variables}
+	lint := template.Lint()
+
+	response := ValidateTemplateResponse{
+		Valid:               lint.Valid(),
+		Errors:              lint.Errors,
+		UndeclaredVariables: lint.Undeclared,
+		UnusedVariables:     lint.Unused,
+	}
+
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
+
+// PreviewTemplateLocales handles the request to render a template in every
+// available locale with the same sample data, given as a JSON object in the data
+// query parameter, e.g. ?data={"FirstName":"Jane"}
+func (h *TemplateHandler) PreviewTemplateLocales(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "preview_template_locales"
+
+	if h.previewer == nil {
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Template previews are not available", http.StatusNotImplemented)
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	data := make(map[string]interface{})
+	if raw := r.URL.Query().Get("data"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &data); err != nil || data == nil {
+			metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+			writeError(w, "data must be a JSON object", http.StatusBadRequest)
+			return
+		}
+	}
+
+	preview, err := h.previewer.PreviewTemplateLocales(r.Context(), name, data)
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to preview template", zap.Error(err), zap.String("name", name))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeServiceError(w, "Failed to preview template", err)
+		return
+	}
+
+	response := TemplatePreviewResponse{
+		Name:          preview.Name,
+		DefaultLocale: preview.DefaultLocale,
+		Rendered:      preview.Rendered,
+		Missing:       preview.Missing,
+		Errors:        preview.Errors,
+	}
+	if err := writeResponse(w, response, http.StatusOK); err != nil {
+		requestLogger(h.logger, r).Error("failed to encode response", zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		writeError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
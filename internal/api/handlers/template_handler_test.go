@@ -0,0 +1,537 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockTemplateService is a mock implementation of TemplateService
+type MockTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTemplateService) GetTemplateVersions(ctx context.Context, id uuid.UUID) ([]*model.TemplateVersion, error) {
+	args := m.Called(ctx, id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.TemplateVersion), nil
+}
+
+func (m *MockTemplateService) RollbackTemplate(ctx context.Context, id uuid.UUID, version int) (*model.Template, error) {
+	args := m.Called(ctx, id, version)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Template), nil
+}
+
+func (m *MockTemplateService) SetTemplateActive(ctx context.Context, id uuid.UUID, active bool) (*model.Template, error) {
+	args := m.Called(ctx, id, active)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Template), nil
+}
+
+func withTemplateID(req *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestTemplateHandler_GetTemplateVersions(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, nil, logger)
+
+	id := uuid.New()
+	versions := []*model.TemplateVersion{
+		{TemplateID: id, Version: 2, Subject: "Welcome", Content: "Hello v2", CreatedAt: time.Now()},
+		{TemplateID: id, Version: 1, Subject: "Welcome", Content: "Hello v1", CreatedAt: time.Now()},
+	}
+
+	tests := []struct {
+		name           string
+		templateID     string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+		expectedCount  int
+	}{
+		{
+			name:       "template with history",
+			templateID: id.String(),
+			setupMock: func() {
+				mockService.On("GetTemplateVersions", mock.Anything, id).Return(versions, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  2,
+		},
+		{
+			name:           "invalid ID",
+			templateID:     "not-a-uuid",
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:       "unknown template",
+			templateID: id.String(),
+			setupMock: func() {
+				mockService.On("GetTemplateVersions", mock.Anything, id).Return(nil, model.ErrTemplateNotFound{ID: id.String()})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+		{
+			name:       "service error",
+			templateID: id.String(),
+			setupMock: func() {
+				mockService.On("GetTemplateVersions", mock.Anything, id).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodGet, "/templates/"+tt.templateID+"/versions", nil)
+			req = withTemplateID(req, tt.templateID)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.GetTemplateVersions(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response TemplateVersionListResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, id.String(), response.TemplateID)
+				assert.Len(t, response.Versions, tt.expectedCount)
+				assert.Equal(t, 2, response.Versions[0].Version)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateHandler_RollbackTemplate(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, nil, logger)
+
+	id := uuid.New()
+	restored := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	restored.ID = id
+	restored.Version = 3
+
+	tests := []struct {
+		name           string
+		templateID     string
+		body           string
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:       "successful rollback",
+			templateID: id.String(),
+			body:       `{"version": 1}`,
+			setupMock: func() {
+				mockService.On("RollbackTemplate", mock.Anything, id, 1).Return(restored, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid ID",
+			templateID:     "not-a-uuid",
+			body:           `{"version": 1}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "invalid body",
+			templateID:     id.String(),
+			body:           `{`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "missing version",
+			templateID:     id.String(),
+			body:           `{}`,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:       "unknown version",
+			templateID: id.String(),
+			body:       `{"version": 7}`,
+			setupMock: func() {
+				mockService.On("RollbackTemplate", mock.Anything, id, 7).Return(nil, model.ErrTemplateVersionNotFound{ID: id.String(), Version: 7})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/templates/"+tt.templateID+"/rollback", bytes.NewBufferString(tt.body))
+			req = withTemplateID(req, tt.templateID)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			handler.RollbackTemplate(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response TemplateResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, 3, response.Version)
+				assert.Equal(t, "Hello v1", response.Content)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateHandler_SetTemplateActive(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, nil, logger)
+
+	id := uuid.New()
+	deactivated := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	deactivated.ID = id
+	deactivated.Version = 2
+	deactivated.IsActive = false
+
+	tests := []struct {
+		name           string
+		templateID     string
+		active         bool
+		setupMock      func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:       "successful deactivation",
+			templateID: id.String(),
+			active:     false,
+			setupMock: func() {
+				mockService.On("SetTemplateActive", mock.Anything, id, false).Return(deactivated, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid ID",
+			templateID:     "not-a-uuid",
+			active:         true,
+			setupMock:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:       "unknown template",
+			templateID: id.String(),
+			active:     true,
+			setupMock: func() {
+				mockService.On("SetTemplateActive", mock.Anything, id, true).Return(nil, model.ErrTemplateNotFound{ID: id.String()})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+		{
+			name:       "already active",
+			templateID: id.String(),
+			active:     true,
+			setupMock: func() {
+				mockService.On("SetTemplateActive", mock.Anything, id, true).Return(nil, model.ErrTemplateActiveState{ID: id.String(), Active: true})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   ErrorCodeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reset mock
+			mockService.ExpectedCalls = nil
+			mockService.Calls = nil
+
+			// Setup
+			tt.setupMock()
+
+			// Create request
+			action, serve := "deactivate", handler.DeactivateTemplate
+			if tt.active {
+				action, serve = "activate", handler.ActivateTemplate
+			}
+			req := httptest.NewRequest(http.MethodPost, "/templates/"+tt.templateID+"/"+action, nil)
+			req = withTemplateID(req, tt.templateID)
+			rec := httptest.NewRecorder()
+
+			// Execute request
+			serve(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response TemplateResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.False(t, response.IsActive)
+				assert.Equal(t, 2, response.Version)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateHandler_ValidateTemplate(t *testing.T) {
+	logger := zap.NewNop()
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, nil, logger)
+
+	tests := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedCode     string
+		expectedResponse ValidateTemplateResponse
+	}{
+		{
+			name:           "valid template",
+			body:           `{"content": "Hello {{.Username}}", "variables": ["Username"]}`,
+			expectedStatus: http.StatusOK,
+			expectedResponse: ValidateTemplateResponse{
+				Valid:               true,
+				Errors:              []string{},
+				UndeclaredVariables: []string{},
+				UnusedVariables:     []string{},
+			},
+		},
+		{
+			name:           "variable warnings",
+			body:           `{"content": "Hello {{.Username}}", "variables": ["Year"]}`,
+			expectedStatus: http.StatusOK,
+			expectedResponse: ValidateTemplateResponse{
+				Valid:               true,
+				Errors:              []string{},
+				UndeclaredVariables: []string{"Username"},
+				UnusedVariables:     []string{"Year"},
+			},
+		},
+		{
+			name:           "invalid body",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "missing content",
+			body:           `{"variables": ["Username"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/templates/validate", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ValidateTemplate(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var response ValidateTemplateResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, tt.expectedResponse, response)
+			}
+		})
+	}
+
+	t.Run("parse error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/templates/validate", bytes.NewBufferString(`{"content": "Hello {{.Username"}`))
+		rec := httptest.NewRecorder()
+
+		handler.ValidateTemplate(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response ValidateTemplateResponse
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.False(t, response.Valid)
+		assert.Len(t, response.Errors, 1)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+// MockTemplateSyncer is a mock implementation of TemplateSyncer
+type MockTemplateSyncer struct {
+	mock.Mock
+}
+
+func (m *MockTemplateSyncer) Sync(ctx context.Context) (*model.TemplateSyncResult, error) {
+	args := m.Called(ctx)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TemplateSyncResult), nil
+}
+
+func TestTemplateHandler_SyncTemplates(t *testing.T) {
+	logger := zap.NewNop()
+
+	t.Run("synced", func(t *testing.T) {
+		syncer := new(MockTemplateSyncer)
+		result := model.NewTemplateSyncResult()
+		result.Created = []string{"welcome"}
+		result.Unchanged = []string{"password_reset"}
+		syncer.On("Sync", mock.Anything).Return(result, nil)
+		handler := NewTemplateHandler(new(MockTemplateService), syncer, logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/templates/sync", nil)
+		rec := httptest.NewRecorder()
+		handler.SyncTemplates(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response model.TemplateSyncResult
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, *result, response)
+		syncer.AssertExpectations(t)
+	})
+
+	t.Run("no templates directory", func(t *testing.T) {
+		handler := NewTemplateHandler(new(MockTemplateService), nil, logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/templates/sync", nil)
+		rec := httptest.NewRecorder()
+		handler.SyncTemplates(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// stubTemplatePreviewer previews the templates in previews, recording the data it was given
+type stubTemplatePreviewer struct {
+	previews map[string]*model.TemplateLocalePreview
+	data     map[string]interface{}
+}
+
+func (p *stubTemplatePreviewer) PreviewTemplateLocales(ctx context.Context, name string, data map[string]interface{}) (*model.TemplateLocalePreview, error) {
+	p.data = data
+	preview, ok := p.previews[name]
+	if !ok {
+		return nil, model.ErrTemplateNotFound{ID: name}
+	}
+	return preview, nil
+}
+
+func TestTemplateHandler_PreviewTemplateLocales(t *testing.T) {
+	previewer := &stubTemplatePreviewer{previews: map[string]*model.TemplateLocalePreview{
+		"welcome.html": {
+			Name:          "welcome.html",
+			DefaultLocale: "en",
+			Rendered:      map[string]string{"en": "Hello Jane", "fr": "Bonjour Jane"},
+			Missing:       []string{"de"},
+			Errors:        map[string]string{},
+		},
+	}}
+	handler := NewTemplateHandler(new(MockTemplateService), nil, zap.NewNop())
+	handler.SetPreviewer(previewer)
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCode   string
+		expectedBody   string
+	}{
+		{
+			name:           "renders every locale",
+			path:           "/templates/welcome.html/preview-all?data=" + url.QueryEscape(`{"FirstName": "Jane"}`),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name": "welcome.html", "default_locale": "en", "rendered": {"en": "Hello Jane", "fr": "Bonjour Jane"}, "missing": ["de"], "errors": {}}`,
+		},
+		{
+			name:           "data that isn't an object",
+			path:           "/templates/welcome.html/preview-all?data=" + url.QueryEscape(`["Jane"]`),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrorCodeValidationFailed,
+		},
+		{
+			name:           "unknown template",
+			path:           "/templates/goodbye.html/preview-all",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, rec, tt.expectedCode)
+			}
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+				assert.Equal(t, map[string]interface{}{"FirstName": "Jane"}, previewer.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// FieldError describes a single request field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is returned when a request body fails validation.
+// Its code is invalid_recipient when the recipient's format is what failed.
+type ValidationErrorResponse struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// NewValidator creates a validator that reports fields by their JSON names
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterStructValidation(validateRecipient, SendNotificationRequest{})
+	v.RegisterStructValidation(validateChannelRecipient, ChannelTargetRequest{})
+	return v
+}
+
+// validateRecipient checks the recipient's format against the notification type
+func validateRecipient(sl validator.StructLevel) {
+	req := sl.Current().Interface().(SendNotificationRequest)
+	reportInvalidRecipient(sl, req.Recipient, req.Type)
+}
+
+// validateChannelRecipient checks a multi-channel target's recipient against its channel
+func validateChannelRecipient(sl validator.StructLevel) {
+	target := sl.Current().Interface().(ChannelTargetRequest)
+	reportInvalidRecipient(sl, target.Recipient, target.Type)
+}
+
+// reportInvalidRecipient reports a recipient whose format doesn't suit the
+// notification type: SMS and WhatsApp messages go to E.164 phone numbers,
+// pushes to hex-encoded device tokens and everything else to email
+func reportInvalidRecipient(sl validator.StructLevel, recipient, notificationType string) {
+	if recipient == "" {
+		return
+	}
+
+	tag := "email"
+	switch model.NotificationType(notificationType) {
+	case model.SMSNotification, model.WhatsAppNotification:
+		tag = "e164"
+	case model.PushNotification:
+		tag = "hexadecimal"
+	}
+	if err := sl.Validator().Var(recipient, tag); err != nil {
+		sl.ReportError(recipient, "recipient", "Recipient", tag, "")
+	}
+}
+
+// BuildNotification validates a send request and converts it into a pending
+// notification. It is shared by the HTTP and gRPC APIs so both enforce the same rules.
+func BuildNotification(validate *validator.Validate, req SendNotificationRequest) (*model.Notification, error) {
+	if err := validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	notification, err := newNotification(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := notification.Validate(); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// BuildNotificationV2 validates an API version 2 send request and converts it
+// into a pending notification
+func BuildNotificationV2(validate *validator.Validate, req SendNotificationRequestV2) (*model.Notification, error) {
+	if err := validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	notification, err := newNotification(req.SendNotificationRequest)
+	if err != nil {
+		return nil, err
+	}
+	notification.ContentType = model.EmailContentType(req.ContentType)
+	notification.CC = req.CC
+	notification.ThreadID = req.ThreadID
+	for _, image := range req.InlineImages {
+		notification.InlineImages = append(notification.InlineImages, model.InlineImage{
+			ContentID:   image.ContentID,
+			ContentType: image.ContentType,
+			Data:        image.Data,
+		})
+	}
+
+	if err := notification.Validate(); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// newNotification converts a validated send request into a pending notification
+func newNotification(req SendNotificationRequest) (*model.Notification, error) {
+	// Convert string templateID to UUID
+	var templateID uuid.UUID
+	if req.TemplateID != "" {
+		var err error
+		templateID, err = uuid.Parse(req.TemplateID)
+		if err != nil {
+			return nil, errors.New("invalid template ID format")
+		}
+	}
+
+	now := time.Now()
+	notification := &model.Notification{
+		ID:           uuid.New(),
+		Recipient:    req.Recipient,
+		Type:         model.NotificationType(req.Type),
+		Subject:      req.Subject,
+		Content:      req.Content,
+		Priority:     model.Priority(req.Priority),
+		Category:     req.Category,
+		Tags:         req.Tags,
+		Status:       model.StatusPending,
+		TemplateID:   templateID,
+		TemplateData: req.TemplateData,
+		Metadata:     req.Metadata,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	return notification, nil
+}
+
+// FieldErrors converts a validation error into a field-level error list
+func FieldErrors(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []FieldError{{Message: err.Error()}}
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: fieldErrorMessage(fe),
+		})
+	}
+	return fields
+}
+
+// fieldErrorMessage renders a human-readable message for a failed validation rule
+func fieldErrorMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_unless":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fe.Field())
+	case "e164":
+		return fmt.Sprintf("%s must be a phone number in E.164 format", fe.Field())
+	case "hexadecimal":
+		return fmt.Sprintf("%s must be a hex-encoded device token", fe.Field())
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid UUID", fe.Field())
+	case "min":
+		return fmt.Sprintf("%s must contain at least %s item(s)", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("%s failed the %q rule", fe.Field(), fe.Tag())
+	}
+}
+
+func writeValidationError(w http.ResponseWriter, err error) {
+	fields := FieldErrors(err)
+	code := ErrorCodeValidationFailed
+	for _, field := range fields {
+		// The recipient's format rule depends on the notification type; see reportInvalidRecipient
+		if field.Field == "recipient" && field.Rule != "required" {
+			code = ErrorCodeInvalidRecipient
+		}
+	}
+
+	writeResponse(w, ValidationErrorResponse{
+		Code:      code,
+		Message:   "Validation failed",
+		Fields:    fields,
+		RequestID: w.Header().Get(RequestIDHeader),
+	}, http.StatusBadRequest)
+}
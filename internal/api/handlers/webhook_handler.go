@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBodySize bounds the webhook payloads read into memory
+const maxWebhookBodySize = 1 << 20
+
+// DeliveryWebhook parses a provider's delivery webhook after verifying its
+// signature, returning model.ErrInvalidSignature when it doesn't verify
+type DeliveryWebhook interface {
+	ParseDeliveryEvents(r *http.Request) ([]model.DeliveryEvent, error)
+}
+
+// DeliveryEventService defines the interface for recording delivery receipts
+type DeliveryEventService interface {
+	HandleDeliveryEvent(ctx context.Context, event model.DeliveryEvent) error
+}
+
+// WebhookHandler handles delivery webhooks sent by providers. Providers can't
+// send an API key, so these routes are authenticated by their signatures instead.
+type WebhookHandler struct {
+	service  DeliveryEventService
+	webhooks map[string]DeliveryWebhook
+	logger   *zap.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler for the given providers' webhooks, keyed by provider name
+func NewWebhookHandler(service DeliveryEventService, webhooks map[string]DeliveryWebhook, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service:  service,
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// RegisterRoutes registers the webhook routes
+func (h *WebhookHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/webhooks/providers/{provider}", h.HandleProviderWebhook)
+}
+
+// HandleProviderWebhook handles a provider's delivery, bounce, open and click events
+func (h *WebhookHandler) HandleProviderWebhook(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	operation := "provider_webhook"
+
+	provider := chi.URLParam(r, "provider")
+	webhook, ok := h.webhooks[provider]
+	if !ok {
+		metrics.RecordOperationDuration("http_"+operation, "not_found", time.Since(start).Seconds())
+		writeError(w, "Unknown provider", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBodySize)
+	events, err := webhook.ParseDeliveryEvents(r)
+	if errors.Is(err, model.ErrInvalidSignature) {
+		requestLogger(h.logger, r).Warn("rejected provider webhook with an invalid signature", zap.String("provider", provider), zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "unauthorized", time.Since(start).Seconds())
+		writeError(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		requestLogger(h.logger, r).Error("failed to parse provider webhook", zap.String("provider", provider), zap.Error(err))
+		metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeBodyError(w, err)
+			return
+		}
+		writeError(w, "Invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range events {
+		err := h.service.HandleDeliveryEvent(r.Context(), event)
+		if errors.Is(err, model.ErrNotFound) {
+			// Not one of ours, or already purged; retrying won't change that
+			requestLogger(h.logger, r).Debug("ignoring delivery event for unknown message",
+				zap.String("provider", provider),
+				zap.String("messageId", event.ProviderMessageID),
+			)
+			continue
+		}
+		if err != nil {
+			// Fail the delivery so the provider sends it again
+			requestLogger(h.logger, r).Error("failed to record delivery event",
+				zap.Error(err),
+				zap.String("provider", provider),
+				zap.String("messageId", event.ProviderMessageID),
+				zap.String("event", string(event.Type)),
+			)
+			metrics.RecordOperationDuration("http_"+operation, "error", time.Since(start).Seconds())
+			writeError(w, "Failed to record delivery event", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	metrics.RecordOperationDuration("http_"+operation, "success", time.Since(start).Seconds())
+}
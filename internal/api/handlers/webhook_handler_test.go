@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockDeliveryEventService is a mock implementation of DeliveryEventService
+type MockDeliveryEventService struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryEventService) HandleDeliveryEvent(ctx context.Context, event model.DeliveryEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+// fakeWebhook returns fixed events, or an error, for every request
+type fakeWebhook struct {
+	events []model.DeliveryEvent
+	err    error
+}
+
+func (f fakeWebhook) ParseDeliveryEvents(r *http.Request) ([]model.DeliveryEvent, error) {
+	return f.events, f.err
+}
+
+func TestWebhookHandler_HandleProviderWebhook(t *testing.T) {
+	delivered := model.DeliveryEvent{Provider: "sendgrid", ProviderMessageID: "abc123", Type: model.DeliveryEventDelivered}
+	opened := model.DeliveryEvent{Provider: "sendgrid", ProviderMessageID: "def456", Type: model.DeliveryEventOpened}
+
+	tests := []struct {
+		name           string
+		provider       string
+		webhook        fakeWebhook
+		setupMock      func(m *MockDeliveryEventService)
+		expectedStatus int
+	}{
+		{
+			name:     "events are recorded",
+			provider: "sendgrid",
+			webhook:  fakeWebhook{events: []model.DeliveryEvent{delivered, opened}},
+			setupMock: func(m *MockDeliveryEventService) {
+				m.On("HandleDeliveryEvent", mock.Anything, delivered).Return(nil)
+				m.On("HandleDeliveryEvent", mock.Anything, opened).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:     "unknown messages are acknowledged",
+			provider: "sendgrid",
+			webhook:  fakeWebhook{events: []model.DeliveryEvent{delivered, opened}},
+			setupMock: func(m *MockDeliveryEventService) {
+				m.On("HandleDeliveryEvent", mock.Anything, delivered).Return(model.ErrNotificationNotFound{ID: "abc123"})
+				m.On("HandleDeliveryEvent", mock.Anything, opened).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:     "service error is retried by the provider",
+			provider: "sendgrid",
+			webhook:  fakeWebhook{events: []model.DeliveryEvent{delivered}},
+			setupMock: func(m *MockDeliveryEventService) {
+				m.On("HandleDeliveryEvent", mock.Anything, delivered).Return(assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "invalid signature",
+			provider:       "sendgrid",
+			webhook:        fakeWebhook{err: model.ErrInvalidSignature},
+			setupMock:      func(m *MockDeliveryEventService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "malformed payload",
+			provider:       "sendgrid",
+			webhook:        fakeWebhook{err: assert.AnError},
+			setupMock:      func(m *MockDeliveryEventService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown provider",
+			provider:       "mailchimp",
+			setupMock:      func(m *MockDeliveryEventService) {},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockDeliveryEventService)
+			tt.setupMock(mockService)
+
+			handler := NewWebhookHandler(mockService, map[string]DeliveryWebhook{"sendgrid": tt.webhook}, zap.NewNop())
+			router := chi.NewRouter()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/providers/"+tt.provider, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
 )
@@ -11,7 +12,26 @@ type NotificationServiceAdapter struct {
 	service interface {
 		SendNotification(ctx context.Context, notification *model.Notification) error
 		GetNotification(ctx context.Context, id string) (*model.Notification, error)
-		GetNotificationsByRecipient(recipient string, limit, offset int) ([]*model.Notification, error)
+		GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error)
+		GetNotificationsByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error)
+		GetNotificationsByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error)
+		GetNotificationsByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error)
+		GetNotificationsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error)
+		GetNotificationsByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error)
+		ExportNotifications(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error
+		RetryNotification(ctx context.Context, id string) (*model.Notification, error)
+		ResendNotification(ctx context.Context, id, recipient string) (*model.Notification, error)
+		SendMultiChannel(ctx context.Context, notification *model.Notification, targets []model.ChannelTarget) (*model.NotificationGroup, error)
+		GetNotificationGroup(ctx context.Context, groupID string) ([]*model.Notification, error)
+		RetryFailedSince(ctx context.Context, since time.Time) (retried, failed int, err error)
+		PurgeNotificationsBefore(ctx context.Context, cutoff time.Time) (int, error)
+		ExportRecipientData(ctx context.Context, recipient string) (*model.RecipientData, error)
+		EraseRecipientData(ctx context.Context, recipient string, dryRun bool) (*model.RecipientErasure, error)
+		GetNotificationStats(ctx context.Context, window time.Duration) (*model.NotificationStats, error)
+		GetSendLimitState() (*model.SendLimitState, error)
+		ClearSendLimit() (*model.SendLimitState, error)
+		CheckProviderHealth(ctx context.Context) []model.ProviderHealth
+		HandleDeliveryEvent(ctx context.Context, event model.DeliveryEvent) error
 	}
 }
 
@@ -19,7 +39,26 @@ type NotificationServiceAdapter struct {
 func NewNotificationServiceAdapter(service interface {
 	SendNotification(ctx context.Context, notification *model.Notification) error
 	GetNotification(ctx context.Context, id string) (*model.Notification, error)
-	GetNotificationsByRecipient(recipient string, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error)
+	GetNotificationsByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error)
+	GetNotificationsByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error)
+	ExportNotifications(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error
+	RetryNotification(ctx context.Context, id string) (*model.Notification, error)
+	ResendNotification(ctx context.Context, id, recipient string) (*model.Notification, error)
+	SendMultiChannel(ctx context.Context, notification *model.Notification, targets []model.ChannelTarget) (*model.NotificationGroup, error)
+	GetNotificationGroup(ctx context.Context, groupID string) ([]*model.Notification, error)
+	RetryFailedSince(ctx context.Context, since time.Time) (retried, failed int, err error)
+	PurgeNotificationsBefore(ctx context.Context, cutoff time.Time) (int, error)
+	ExportRecipientData(ctx context.Context, recipient string) (*model.RecipientData, error)
+	EraseRecipientData(ctx context.Context, recipient string, dryRun bool) (*model.RecipientErasure, error)
+	GetNotificationStats(ctx context.Context, window time.Duration) (*model.NotificationStats, error)
+	GetSendLimitState() (*model.SendLimitState, error)
+	ClearSendLimit() (*model.SendLimitState, error)
+	CheckProviderHealth(ctx context.Context) []model.ProviderHealth
+	HandleDeliveryEvent(ctx context.Context, event model.DeliveryEvent) error
 }) *NotificationServiceAdapter {
 	return &NotificationServiceAdapter{
 		service: service,
@@ -27,8 +66,8 @@ func NewNotificationServiceAdapter(service interface {
 }
 
 // SendNotification adapts the domain service's SendNotification method to the handler interface
-func (a *NotificationServiceAdapter) SendNotification(notification *model.Notification) error {
-	return a.service.SendNotification(context.Background(), notification)
+func (a *NotificationServiceAdapter) SendNotification(ctx context.Context, notification *model.Notification) error {
+	return a.service.SendNotification(ctx, notification)
 }
 
 // GetNotification adapts the domain service's GetNotification method to the handler interface
@@ -36,7 +75,102 @@ func (a *NotificationServiceAdapter) GetNotification(ctx context.Context, id str
 	return a.service.GetNotification(ctx, id)
 }
 
+// GetNotificationsByIDs adapts the domain service's GetNotificationsByIDs method to the handler interface
+func (a *NotificationServiceAdapter) GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error) {
+	return a.service.GetNotificationsByIDs(ctx, ids)
+}
+
 // GetNotificationsByRecipient adapts the domain service's GetNotificationsByRecipient method to the handler interface
-func (a *NotificationServiceAdapter) GetNotificationsByRecipient(recipient string, limit, offset int) ([]*model.Notification, error) {
-	return a.service.GetNotificationsByRecipient(recipient, limit, offset)
+func (a *NotificationServiceAdapter) GetNotificationsByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
+	return a.service.GetNotificationsByRecipient(ctx, recipient, order, limit, offset)
+}
+
+// GetNotificationsByStatus adapts the domain service's GetNotificationsByStatus method to the admin handler interface
+func (a *NotificationServiceAdapter) GetNotificationsByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error) {
+	return a.service.GetNotificationsByStatus(ctx, status, limit, offset)
+}
+
+// GetNotificationsByMetadata adapts the domain service's GetNotificationsByMetadata method to the handler interface
+func (a *NotificationServiceAdapter) GetNotificationsByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error) {
+	return a.service.GetNotificationsByMetadata(ctx, filters, limit, offset)
+}
+
+// GetNotificationsByCategory adapts the domain service's GetNotificationsByCategory method to the handler interface
+func (a *NotificationServiceAdapter) GetNotificationsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error) {
+	return a.service.GetNotificationsByCategory(ctx, category, limit, offset)
+}
+
+// GetNotificationsByCorrelationID adapts the domain service's GetNotificationsByCorrelationID method to the handler interface
+func (a *NotificationServiceAdapter) GetNotificationsByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error) {
+	return a.service.GetNotificationsByCorrelationID(ctx, correlationID, limit, offset)
+}
+
+// ExportNotifications adapts the domain service's ExportNotifications method to the handler interface
+func (a *NotificationServiceAdapter) ExportNotifications(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error {
+	return a.service.ExportNotifications(ctx, recipient, from, to, fn)
+}
+
+// RetryNotification adapts the domain service's RetryNotification method to the handler interface
+func (a *NotificationServiceAdapter) RetryNotification(ctx context.Context, id string) (*model.Notification, error) {
+	return a.service.RetryNotification(ctx, id)
+}
+
+// ResendNotification adapts the domain service's ResendNotification method to the handler interface
+func (a *NotificationServiceAdapter) ResendNotification(ctx context.Context, id, recipient string) (*model.Notification, error) {
+	return a.service.ResendNotification(ctx, id, recipient)
+}
+
+// SendMultiChannel adapts the domain service's SendMultiChannel method to the handler interface
+func (a *NotificationServiceAdapter) SendMultiChannel(ctx context.Context, notification *model.Notification, targets []model.ChannelTarget) (*model.NotificationGroup, error) {
+	return a.service.SendMultiChannel(ctx, notification, targets)
+}
+
+// GetNotificationGroup adapts the domain service's GetNotificationGroup method to the handler interface
+func (a *NotificationServiceAdapter) GetNotificationGroup(ctx context.Context, groupID string) ([]*model.Notification, error) {
+	return a.service.GetNotificationGroup(ctx, groupID)
+}
+
+// RetryFailedSince adapts the domain service's RetryFailedSince method to the admin handler interface
+func (a *NotificationServiceAdapter) RetryFailedSince(ctx context.Context, since time.Time) (retried, failed int, err error) {
+	return a.service.RetryFailedSince(ctx, since)
+}
+
+// PurgeNotificationsBefore adapts the domain service's PurgeNotificationsBefore method to the admin handler interface
+func (a *NotificationServiceAdapter) PurgeNotificationsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	return a.service.PurgeNotificationsBefore(ctx, cutoff)
+}
+
+// ExportRecipientData adapts the domain service's ExportRecipientData method to the admin handler interface
+func (a *NotificationServiceAdapter) ExportRecipientData(ctx context.Context, recipient string) (*model.RecipientData, error) {
+	return a.service.ExportRecipientData(ctx, recipient)
+}
+
+// EraseRecipientData adapts the domain service's EraseRecipientData method to the admin handler interface
+func (a *NotificationServiceAdapter) EraseRecipientData(ctx context.Context, recipient string, dryRun bool) (*model.RecipientErasure, error) {
+	return a.service.EraseRecipientData(ctx, recipient, dryRun)
+}
+
+// GetNotificationStats adapts the domain service's GetNotificationStats method to the admin handler interface
+func (a *NotificationServiceAdapter) GetNotificationStats(ctx context.Context, window time.Duration) (*model.NotificationStats, error) {
+	return a.service.GetNotificationStats(ctx, window)
+}
+
+// GetSendLimitState adapts the domain service's GetSendLimitState method to the admin handler interface
+func (a *NotificationServiceAdapter) GetSendLimitState() (*model.SendLimitState, error) {
+	return a.service.GetSendLimitState()
+}
+
+// ClearSendLimit adapts the domain service's ClearSendLimit method to the admin handler interface
+func (a *NotificationServiceAdapter) ClearSendLimit() (*model.SendLimitState, error) {
+	return a.service.ClearSendLimit()
+}
+
+// CheckProviderHealth adapts the domain service's CheckProviderHealth method to the admin handler interface
+func (a *NotificationServiceAdapter) CheckProviderHealth(ctx context.Context) []model.ProviderHealth {
+	return a.service.CheckProviderHealth(ctx)
+}
+
+// HandleDeliveryEvent adapts the domain service's HandleDeliveryEvent method to the webhook handler interface
+func (a *NotificationServiceAdapter) HandleDeliveryEvent(ctx context.Context, event model.DeliveryEvent) error {
+	return a.service.HandleDeliveryEvent(ctx, event)
 }
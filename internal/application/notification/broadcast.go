@@ -0,0 +1,270 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"go.uber.org/zap"
+)
+
+// DefaultBroadcastPageSize is how many recipients of a broadcast are fanned
+// out at a time unless set otherwise
+const DefaultBroadcastPageSize = 500
+
+// SetBroadcastProgress sets where the progress of broadcasts is recorded, so a
+// broadcast interrupted by a restart resumes from the last page enqueued when
+// its event is delivered again; nil starts every broadcast from the beginning
+func (s *Service) SetBroadcastProgress(progress repository.BroadcastProgressRepository) {
+	s.broadcastProgress = progress
+}
+
+// SetSegmentResolver sets what resolves the segments broadcasts are addressed
+// to; without one, broadcasts must list their recipients
+func (s *Service) SetSegmentResolver(resolver services.RecipientSegmentResolver) {
+	s.segmentResolver = resolver
+}
+
+// SetBroadcastPageSize sets how many recipients of a broadcast are fanned out
+// at a time; 0 or less uses DefaultBroadcastPageSize
+func (s *Service) SetBroadcastPageSize(size int) {
+	s.broadcastPageSize = size
+}
+
+// announcementEvent is the payload of an announcement.published event: one
+// notification sent to every recipient listed, or every recipient in a segment
+type announcementEvent struct {
+	BroadcastID string                 `json:"broadcastId"`
+	Type        model.NotificationType `json:"type"` // Defaults to email
+	Category    string                 `json:"category"`
+	Subject     string                 `json:"subject"`
+	Content     string                 `json:"content"`
+	Recipients  []string               `json:"recipients"`
+	Segment     string                 `json:"segment"`
+}
+
+// handleAnnouncementPublished fans an announcement out to its recipients a page
+// at a time: each page is queued with a single batch before the next one is
+// fetched, so memory use doesn't grow with the audience, and the progress is
+// recorded after every page. A page interrupted by a restart is queued again
+// when the broadcast resumes, so its recipients may be notified twice.
+func (s *Service) handleAnnouncementPublished(ctx context.Context, payload []byte) error {
+	var event announcementEvent
+	if err := decodeEvent("announcement.published", payload, &event); err != nil {
+		return err
+	}
+	if event.Type == "" {
+		event.Type = model.EmailNotification
+	}
+	if err := s.checkAnnouncement(event); err != nil {
+		return err
+	}
+
+	logger := logging.WithContext(ctx, s.logger).With(zap.String("broadcastId", event.BroadcastID))
+
+	progress, err := s.findBroadcastProgress(ctx, event.BroadcastID)
+	if err != nil {
+		return err
+	}
+	if progress.Completed {
+		logger.Info("broadcast already completed, ignoring event", zap.Int("enqueued", progress.Enqueued))
+		return nil
+	}
+	if progress.Cursor != "" {
+		logger.Info("resuming broadcast", zap.String("cursor", progress.Cursor), zap.Int("enqueued", progress.Enqueued))
+	}
+
+	for !progress.Completed {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("broadcast %s interrupted: %w", event.BroadcastID, err)
+		}
+
+		recipients, next, err := s.broadcastPage(ctx, event, progress.Cursor)
+		if err != nil {
+			return err
+		}
+
+		notifications, skipped, err := s.broadcastNotifications(ctx, event, recipients)
+		if err != nil {
+			return err
+		}
+		if err := s.deliverBatch(ctx, notifications); err != nil {
+			return fmt.Errorf("error enqueuing broadcast %s: %w", event.BroadcastID, err)
+		}
+
+		progress.Advance(next, len(notifications), skipped)
+		if s.broadcastProgress != nil {
+			if err := s.broadcastProgress.SaveBroadcastProgress(ctx, progress); err != nil {
+				return fmt.Errorf("error saving broadcast progress: %w", err)
+			}
+		}
+	}
+
+	logger.Info("broadcast completed", zap.Int("enqueued", progress.Enqueued), zap.Int("skipped", progress.Skipped))
+	return nil
+}
+
+// checkAnnouncement rejects an announcement that can't be fanned out
+func (s *Service) checkAnnouncement(event announcementEvent) error {
+	invalid := func(message string) error {
+		return model.ErrInvalidBroadcast{BroadcastID: event.BroadcastID, Message: message}
+	}
+
+	switch {
+	case event.BroadcastID == "":
+		return invalid("broadcastId is required")
+	case len(event.BroadcastID) > model.MaxBroadcastIDLength:
+		return invalid(fmt.Sprintf("broadcastId must be at most %d characters", model.MaxBroadcastIDLength))
+	case !event.Type.IsValid():
+		return invalid(fmt.Sprintf("unknown notification type: %s", event.Type))
+	case event.Content == "":
+		return invalid("content is required")
+	case event.Segment != "" && len(event.Recipients) > 0:
+		return invalid("recipients and segment are mutually exclusive")
+	case event.Segment != "" && s.segmentResolver == nil:
+		return invalid("segments are not supported; list the recipients instead")
+	}
+	return nil
+}
+
+// findBroadcastProgress returns the recorded progress of a broadcast, or new
+// progress if it hasn't started or progress isn't recorded
+func (s *Service) findBroadcastProgress(ctx context.Context, broadcastID string) (*model.BroadcastProgress, error) {
+	if s.broadcastProgress == nil {
+		return model.NewBroadcastProgress(broadcastID), nil
+	}
+
+	progress, err := s.broadcastProgress.FindBroadcastProgress(ctx, broadcastID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding broadcast progress: %w", err)
+	}
+	if progress == nil {
+		progress = model.NewBroadcastProgress(broadcastID)
+	}
+	return progress, nil
+}
+
+// broadcastPage returns the page of a broadcast's recipients starting at
+// cursor and the cursor of the next page, or "" if it is the last
+func (s *Service) broadcastPage(ctx context.Context, event announcementEvent, cursor string) ([]string, string, error) {
+	pageSize := s.broadcastPageSize
+	if pageSize <= 0 {
+		pageSize = DefaultBroadcastPageSize
+	}
+
+	if event.Segment != "" {
+		recipients, next, err := s.segmentResolver.ResolveSegment(ctx, event.Segment, cursor, pageSize)
+		if err != nil {
+			return nil, "", fmt.Errorf("error resolving segment %s: %w", event.Segment, err)
+		}
+		return recipients, next, nil
+	}
+
+	offset := 0
+	if cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(cursor); err != nil || offset < 0 || offset > len(event.Recipients) {
+			return nil, "", model.ErrInvalidBroadcast{BroadcastID: event.BroadcastID, Message: fmt.Sprintf("recorded cursor %q is outside its recipients", cursor)}
+		}
+	}
+
+	end := offset + pageSize
+	if end >= len(event.Recipients) {
+		return event.Recipients[offset:], "", nil
+	}
+	return event.Recipients[offset:end], strconv.Itoa(end), nil
+}
+
+// broadcastNotifications creates the notifications of a page of a broadcast's
+// recipients. Recipients whose notification is invalid, such as a blank
+// address, are skipped rather than failing the whole broadcast.
+func (s *Service) broadcastNotifications(ctx context.Context, event announcementEvent, recipients []string) ([]*model.Notification, int, error) {
+	notifications := make([]*model.Notification, 0, len(recipients))
+	skipped := 0
+	for _, recipient := range recipients {
+		notification := model.NewNotification(
+			s.recipients.Normalize(recipient),
+			event.Type,
+			"",
+			uuid.Nil,
+			model.TemplateData{
+				"subject":     event.Subject,
+				"content":     event.Content,
+				"eventType":   "announcement.published",
+				"broadcastId": event.BroadcastID,
+			},
+		)
+		notification.Subject = event.Subject
+		notification.Content = event.Content
+		notification.Category = event.Category
+		notification.Priority = s.defaultPriority(notification.Type)
+		notification.CorrelationID = model.CorrelationIDFromContext(ctx)
+		notification.Metadata[model.MetadataBroadcastID] = event.BroadcastID
+
+		err := s.prepareBroadcastNotification(notification)
+		if errors.Is(err, model.ErrValidation) {
+			logging.WithContext(ctx, s.logger).Warn("skipping broadcast recipient",
+				zap.String("broadcastId", event.BroadcastID),
+				zap.String("recipient", notification.Recipient),
+				zap.Error(err),
+			)
+			skipped++
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, skipped, nil
+}
+
+// prepareBroadcastNotification runs the checks SendNotification runs before
+// queuing a notification, except the send limit: a broadcast is a deliberate
+// burst, not the runaway producer the limit guards against
+func (s *Service) prepareBroadcastNotification(notification *model.Notification) error {
+	if err := notification.Validate(); err != nil {
+		return fmt.Errorf("invalid notification: %w", err)
+	}
+	if err := s.enforceContentLimit(notification); err != nil {
+		return err
+	}
+	return s.applySendWindow(notification)
+}
+
+// deliverBatch is deliver for many notifications: they are queued in the outbox
+// with a single batch when there is one, and saved with a single batch and sent
+// inline otherwise. A notification that fails to send inline is recorded as
+// failed without stopping the others.
+func (s *Service) deliverBatch(ctx context.Context, notifications []*model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	if s.outbox != nil && !s.isDryRun(ctx) {
+		if err := s.outbox.SaveAndEnqueueBatch(ctx, notifications); err != nil {
+			return fmt.Errorf("error saving notifications: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.repo.SaveBatch(ctx, notifications); err != nil {
+		return fmt.Errorf("error saving notifications: %w", err)
+	}
+
+	for _, notification := range notifications {
+		if err := s.dispatch(ctx, notification); err != nil {
+			logging.WithCorrelationID(s.logger, notification.CorrelationID).Warn("error sending broadcast notification",
+				zap.String("id", notification.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
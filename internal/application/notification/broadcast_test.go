@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// batchOutbox records the batches queued for delivery, failing once failAt
+// batches have been queued
+type batchOutbox struct {
+	services.NotificationOutbox
+	batches [][]*model.Notification
+	failAt  int
+}
+
+func (o *batchOutbox) SaveAndEnqueueBatch(ctx context.Context, notifications []*model.Notification) error {
+	if o.failAt > 0 && len(o.batches) == o.failAt {
+		return errors.New("connection reset")
+	}
+	o.batches = append(o.batches, notifications)
+	return nil
+}
+
+func (o *batchOutbox) recipients() []string {
+	var recipients []string
+	for _, batch := range o.batches {
+		for _, notification := range batch {
+			recipients = append(recipients, notification.Recipient)
+		}
+	}
+	return recipients
+}
+
+// memoryBroadcastProgress keeps broadcast progress in memory
+type memoryBroadcastProgress struct {
+	progress map[string]model.BroadcastProgress
+}
+
+func (m *memoryBroadcastProgress) FindBroadcastProgress(ctx context.Context, broadcastID string) (*model.BroadcastProgress, error) {
+	progress, ok := m.progress[broadcastID]
+	if !ok {
+		return nil, nil
+	}
+	return &progress, nil
+}
+
+func (m *memoryBroadcastProgress) SaveBroadcastProgress(ctx context.Context, progress *model.BroadcastProgress) error {
+	m.progress[progress.BroadcastID] = *progress
+	return nil
+}
+
+// numberedSegment resolves every segment to size numbered recipients
+type numberedSegment struct {
+	size int
+}
+
+func (s numberedSegment) ResolveSegment(ctx context.Context, segment, cursor string, limit int) ([]string, string, error) {
+	first, _ := strconv.Atoi(cursor)
+	var recipients []string
+	for i := first; i < first+limit && i < s.size; i++ {
+		recipients = append(recipients, fmt.Sprintf("%s-%d@example.com", segment, i))
+	}
+	if first+limit >= s.size {
+		return recipients, "", nil
+	}
+	return recipients, strconv.Itoa(first + limit), nil
+}
+
+func announcement(broadcastID string, recipients ...string) []byte {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"broadcastId": broadcastID,
+		"subject":     "We launched",
+		"content":     "<p>Come and see</p>",
+		"category":    "marketing",
+		"recipients":  recipients,
+	})
+	return payload
+}
+
+func TestService_AnnouncementPublished(t *testing.T) {
+	recipients := []string{"a@example.com", "b@example.com", "", "c@example.com", "d@example.com"}
+
+	t.Run("queues a batch per page", func(t *testing.T) {
+		outbox := &batchOutbox{}
+		progress := &memoryBroadcastProgress{progress: make(map[string]model.BroadcastProgress)}
+		service := NewService(&fakeNotificationRepository{notifications: make(map[string]*model.Notification)}, &recordingEmailProvider{}, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+		service.SetBroadcastProgress(progress)
+		service.SetBroadcastPageSize(2)
+
+		require.NoError(t, service.HandleUserEvent(context.Background(), "announcement.published", announcement("launch", recipients...)))
+
+		require.Len(t, outbox.batches, 3)
+		assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"}, outbox.recipients())
+		notification := outbox.batches[0][0]
+		assert.Equal(t, "We launched", notification.Subject)
+		assert.Equal(t, "marketing", notification.Category)
+		assert.Equal(t, "launch", notification.Metadata[model.MetadataBroadcastID])
+
+		saved := progress.progress["launch"]
+		assert.True(t, saved.Completed)
+		assert.Equal(t, 4, saved.Enqueued)
+		assert.Equal(t, 1, saved.Skipped)
+
+		// A completed broadcast delivered again isn't sent again
+		require.NoError(t, service.HandleUserEvent(context.Background(), "announcement.published", announcement("launch", recipients...)))
+		assert.Len(t, outbox.batches, 3)
+	})
+
+	t.Run("resumes after the last page queued", func(t *testing.T) {
+		outbox := &batchOutbox{failAt: 1}
+		progress := &memoryBroadcastProgress{progress: make(map[string]model.BroadcastProgress)}
+		service := NewService(&fakeNotificationRepository{notifications: make(map[string]*model.Notification)}, &recordingEmailProvider{}, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+		service.SetBroadcastProgress(progress)
+		service.SetBroadcastPageSize(2)
+
+		err := service.HandleUserEvent(context.Background(), "announcement.published", announcement("launch", recipients...))
+		require.Error(t, err)
+		assert.Equal(t, "2", progress.progress["launch"].Cursor)
+
+		outbox.failAt = 0
+		require.NoError(t, service.HandleUserEvent(context.Background(), "announcement.published", announcement("launch", recipients...)))
+		assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"}, outbox.recipients())
+		assert.Equal(t, 4, progress.progress["launch"].Enqueued)
+	})
+
+	t.Run("resolves a segment", func(t *testing.T) {
+		outbox := &batchOutbox{}
+		service := NewService(&fakeNotificationRepository{notifications: make(map[string]*model.Notification)}, &recordingEmailProvider{}, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+		service.SetSegmentResolver(numberedSegment{size: 1200})
+
+		payload := []byte(`{"broadcastId": "newsletter-42", "subject": "News", "content": "<p>News</p>", "segment": "newsletter"}`)
+		require.NoError(t, service.HandleUserEvent(context.Background(), "announcement.published", payload))
+
+		require.Len(t, outbox.batches, 3)
+		assert.Len(t, outbox.batches[0], DefaultBroadcastPageSize)
+		assert.Len(t, outbox.recipients(), 1200)
+		assert.Equal(t, "newsletter-1199@example.com", outbox.recipients()[1199])
+	})
+
+	t.Run("sends inline without an outbox", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		provider := &recordingEmailProvider{}
+		service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+		require.NoError(t, service.HandleUserEvent(context.Background(), "announcement.published", announcement("launch", "a@example.com", "b@example.com")))
+		assert.ElementsMatch(t, []string{"a@example.com", "b@example.com"}, provider.recipients)
+		assert.Len(t, repo.notifications, 2)
+	})
+
+	for name, payload := range map[string]string{
+		"without a broadcast ID":            `{"content": "Hi", "recipients": ["a@example.com"]}`,
+		"without content":                   `{"broadcastId": "launch", "recipients": ["a@example.com"]}`,
+		"with recipients and a segment":     `{"broadcastId": "launch", "content": "Hi", "recipients": ["a@example.com"], "segment": "all"}`,
+		"with a segment and no resolver":    `{"broadcastId": "launch", "content": "Hi", "segment": "all"}`,
+		"with an unknown notification type": `{"broadcastId": "launch", "type": "fax", "content": "Hi", "recipients": ["a@example.com"]}`,
+	} {
+		t.Run("rejects an announcement "+name, func(t *testing.T) {
+			service := NewService(&fakeNotificationRepository{notifications: make(map[string]*model.Notification)}, &recordingEmailProvider{}, nil, nil, nil, nil, &batchOutbox{}, nil, zap.NewNop())
+
+			err := service.HandleUserEvent(context.Background(), "announcement.published", []byte(payload))
+			assert.ErrorIs(t, err, model.ErrValidation)
+		})
+	}
+}
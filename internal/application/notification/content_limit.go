@@ -0,0 +1,138 @@
+package notification
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// ContentPolicy decides what happens to content over its channel's limit
+type ContentPolicy string
+
+const (
+	// ContentPolicyReject fails the notification with model.ErrContentTooLong
+	ContentPolicyReject ContentPolicy = "reject"
+	// ContentPolicyTruncate cuts the content to the limit, ending it with an ellipsis
+	ContentPolicyTruncate ContentPolicy = "truncate"
+)
+
+// IsValid reports whether the policy is a known content policy
+func (p ContentPolicy) IsValid() bool {
+	return p == ContentPolicyReject || p == ContentPolicyTruncate
+}
+
+// DefaultMaxSMSSegments is the default limit on segments per SMS
+const DefaultMaxSMSSegments = 10
+
+// ContentLimit caps the content length of one channel
+type ContentLimit struct {
+	MaxLength int           // Maximum content length in characters; 0 disables the limit
+	Policy    ContentPolicy // What to do with content over MaxLength
+}
+
+// ContentLimits holds the content limit for each notification type
+type ContentLimits map[model.NotificationType]ContentLimit
+
+// DefaultContentLimits returns ContentLimits with recommended default values.
+// Truncated HTML email is likely broken, so oversized email is rejected; SMS is
+// capped at ten GSM-7 segments and push alerts at what fits an APNs payload.
+func DefaultContentLimits() ContentLimits {
+	return ContentLimits{
+		model.EmailNotification: {MaxLength: 1_000_000, Policy: ContentPolicyReject},
+		model.SMSNotification:   {MaxLength: 1530, Policy: ContentPolicyTruncate},
+		model.PushNotification:  {MaxLength: 4000, Policy: ContentPolicyTruncate},
+	}
+}
+
+// enforceContentLimit applies the content limit of the notification's channel,
+// truncating the content or returning model.ErrContentTooLong
+func (s *Service) enforceContentLimit(notification *model.Notification) error {
+	limit := s.contentLimits[notification.Type]
+	content := []rune(notification.Content)
+
+	if limit.MaxLength > 0 && len(content) > limit.MaxLength {
+		if limit.Policy != ContentPolicyTruncate {
+			metrics.RecordOversizeContent(string(notification.Type), "rejected")
+			return fmt.Errorf("invalid notification: %w", model.ErrContentTooLong{
+				Type:      notification.Type,
+				Length:    len(content),
+				MaxLength: limit.MaxLength,
+			})
+		}
+
+		metrics.RecordOversizeContent(string(notification.Type), "truncated")
+		logging.WithCorrelationID(s.logger, notification.CorrelationID).Warn("truncating oversized notification content",
+			zap.String("type", string(notification.Type)),
+			zap.Int("length", len(content)),
+			zap.Int("maxLength", limit.MaxLength),
+		)
+		notification.Content = truncate(content, limit.MaxLength, ellipsisFor(notification.Type))
+	}
+
+	if notification.Type == model.SMSNotification {
+		return s.enforceSMSSegments(notification)
+	}
+
+	return nil
+}
+
+// enforceSMSSegments records how an SMS is split in its metadata and rejects it
+// with model.ErrTooManySegments when it is over the configured maximum
+func (s *Service) enforceSMSSegments(notification *model.Notification) error {
+	segmentation := s.segmentSMS(notification.Content)
+
+	if s.maxSMSSegments > 0 && segmentation.Segments > s.maxSMSSegments {
+		metrics.RecordOversizeContent(string(notification.Type), "rejected")
+		return fmt.Errorf("invalid notification: %w", model.ErrTooManySegments{
+			Segments:    segmentation.Segments,
+			MaxSegments: s.maxSMSSegments,
+		})
+	}
+
+	if segmentation.Segments > 1 {
+		logging.WithCorrelationID(s.logger, notification.CorrelationID).Warn("SMS content spans multiple segments",
+			zap.String("recipient", notification.Recipient),
+			zap.String("encoding", string(segmentation.Encoding)),
+			zap.Int("segments", segmentation.Segments),
+		)
+	}
+
+	if notification.Metadata == nil {
+		notification.Metadata = make(map[string]string)
+	}
+	notification.Metadata[model.MetadataSMSEncoding] = string(segmentation.Encoding)
+	notification.Metadata[model.MetadataSMSSegments] = strconv.Itoa(segmentation.Segments)
+
+	return nil
+}
+
+// segmentSMS asks the SMS provider how content is split, as providers may
+// encode differently; without one it falls back to model.SegmentSMS
+func (s *Service) segmentSMS(content string) model.SMSSegmentation {
+	if s.smsProvider != nil {
+		return s.smsProvider.Segment(content)
+	}
+	return model.SegmentSMS(content)
+}
+
+// ellipsisFor returns the ellipsis ending truncated content. SMS uses three dots,
+// as "…" is outside the GSM alphabet and would halve the segment size.
+func ellipsisFor(notificationType model.NotificationType) string {
+	if notificationType == model.SMSNotification {
+		return "..."
+	}
+	return "…"
+}
+
+// truncate cuts content to maxLength characters including the ellipsis
+func truncate(content []rune, maxLength int, ellipsis string) string {
+	keep := maxLength - len([]rune(ellipsis))
+	if keep < 0 {
+		keep = 0
+	}
+	return string(content[:keep]) + ellipsis
+}
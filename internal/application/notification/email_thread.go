@@ -0,0 +1,69 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"go.uber.org/zap"
+)
+
+// DefaultMessageIDDomain is the domain emails' Message-IDs are generated under
+// when none is set
+const DefaultMessageIDDomain = "localhost"
+
+// SetThreadFinder enables threading an email with the earlier emails of its
+// thread through finder, typically the notification repository when its store
+// supports it. Without one, threaded emails get a Message-ID but reference nothing.
+func (s *Service) SetThreadFinder(finder repository.NotificationThreadFinder) {
+	s.threadFinder = finder
+}
+
+// SetMessageIDDomain sets the domain emails' Message-IDs are generated under,
+// typically the domain they are sent from
+func (s *Service) SetMessageIDDomain(domain string) {
+	s.messageIDDomain = domain
+}
+
+// emailThreading returns the headers threading an email with the earlier
+// emails sent in its thread, giving it a Message-ID if it doesn't have one yet.
+// The Message-ID is stored with the notification, so retries keep it. Failing
+// to find the earlier emails only costs the threading, not the send.
+func (s *Service) emailThreading(ctx context.Context, notification *model.Notification) model.EmailThreading {
+	if notification.EmailMessageID == "" {
+		notification.EmailMessageID = model.NewEmailMessageID(notification.ID, s.emailMessageIDDomain())
+	}
+	if s.threadFinder == nil {
+		return model.ThreadEmail(notification.EmailMessageID, nil)
+	}
+
+	thread, err := s.threadFinder.FindByThreadID(ctx, notification.ThreadID)
+	if err != nil {
+		logging.WithCorrelationID(s.logger, notification.CorrelationID).Warn("error finding earlier emails in thread",
+			zap.String("thread_id", notification.ThreadID),
+			zap.Error(err),
+		)
+		return model.ThreadEmail(notification.EmailMessageID, nil)
+	}
+
+	var earlier []string
+	for _, previous := range thread {
+		// Only emails that went out can be replied to
+		if previous.ID == notification.ID || previous.EmailMessageID == "" {
+			continue
+		}
+		if previous.Status == model.StatusSent || previous.Status == model.StatusDelivered {
+			earlier = append(earlier, previous.EmailMessageID)
+		}
+	}
+	return model.ThreadEmail(notification.EmailMessageID, earlier)
+}
+
+// emailMessageIDDomain returns the domain emails' Message-IDs are generated under
+func (s *Service) emailMessageIDDomain() string {
+	if s.messageIDDomain == "" {
+		return DefaultMessageIDDomain
+	}
+	return s.messageIDDomain
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestService_EmailThreading(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &optionsEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	service.SetThreadFinder(repo)
+	service.SetMessageIDDomain("example.com")
+
+	newEmail := func(threadID string) *model.Notification {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, nil)
+		notification.ThreadID = threadID
+		return notification
+	}
+
+	first := newEmail("order-42")
+	require.NoError(t, service.SendNotification(context.Background(), first))
+	assert.Equal(t, first.ID.String()+"@example.com", first.EmailMessageID)
+	assert.Equal(t, first.EmailMessageID, repo.notifications[first.ID.String()].EmailMessageID)
+
+	second := newEmail("order-42")
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	require.NoError(t, service.SendNotification(context.Background(), second))
+
+	require.Len(t, provider.options, 2)
+	assert.Equal(t, model.EmailThreading{MessageID: first.EmailMessageID}, provider.options[0].Threading)
+	assert.Equal(t, model.EmailThreading{
+		MessageID:  second.EmailMessageID,
+		InReplyTo:  first.EmailMessageID,
+		References: []string{first.EmailMessageID},
+	}, provider.options[1].Threading)
+
+	t.Run("other threads aren't referenced", func(t *testing.T) {
+		other := newEmail("order-7")
+		require.NoError(t, service.SendNotification(context.Background(), other))
+		assert.Empty(t, provider.options[2].Threading.References)
+	})
+
+	t.Run("unthreaded emails get no Message-ID", func(t *testing.T) {
+		unthreaded := newEmail("")
+		require.NoError(t, service.SendNotification(context.Background(), unthreaded))
+		assert.Empty(t, unthreaded.EmailMessageID)
+	})
+
+	t.Run("rejected if the provider can't thread", func(t *testing.T) {
+		service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, nil, nil, nil, zap.NewNop())
+		err := service.SendNotification(context.Background(), newEmail("order-42"))
+		assert.ErrorIs(t, err, model.ErrValidation)
+	})
+}
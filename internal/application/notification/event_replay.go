@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// eventHandledPageSize is how many notifications of a correlation ID are
+// checked at a time when looking for one an event produced
+const eventHandledPageSize = 100
+
+// UserEventHandled reports whether the user event with the correlation ID in
+// ctx already produced a notification, so handling it again would send a
+// second one. A notification that failed, was throttled or was only a dry run
+// doesn't count, so handling the event again retries it. Broadcasts are never
+// reported handled: they resume from their recorded progress instead.
+func (s *Service) UserEventHandled(ctx context.Context, eventType string) (bool, error) {
+	correlationID := model.CorrelationIDFromContext(ctx)
+	if correlationID == "" || eventType == "announcement.published" {
+		return false, nil
+	}
+
+	for offset := 0; ; offset += eventHandledPageSize {
+		notifications, err := s.repo.FindByCorrelationID(ctx, correlationID, eventHandledPageSize, offset)
+		if err != nil {
+			return false, fmt.Errorf("error finding notifications of the event: %w", err)
+		}
+		for _, notification := range notifications {
+			if notification.TemplateData["eventType"] != eventType {
+				continue
+			}
+			switch notification.Status {
+			case model.StatusFailed, model.StatusThrottled, model.StatusDryRun:
+			default:
+				return true, nil
+			}
+		}
+		if len(notifications) < eventHandledPageSize {
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestService_UserEventHandled(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	save := func(correlationID, eventType string, status model.NotificationStatus) {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{"eventType": eventType})
+		notification.CorrelationID = correlationID
+		notification.Status = status
+		require.NoError(t, repo.Save(context.Background(), notification))
+	}
+	save("req-1", "user.registered", model.StatusSent)
+	save("req-2", "user.password.reset", model.StatusFailed)
+	save("req-3", "announcement.published", model.StatusSent)
+	service := NewService(repo, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	tests := []struct {
+		name          string
+		correlationID string
+		eventType     string
+		handled       bool
+	}{
+		{"sent by the event", "req-1", "user.registered", true},
+		{"another event of the same request", "req-1", "user.verified", false},
+		{"failed, so retried", "req-2", "user.password.reset", false},
+		{"unknown correlation ID", "req-4", "user.registered", false},
+		{"no correlation ID", "", "user.registered", false},
+		{"broadcasts resume instead", "req-3", "announcement.published", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.ContextWithCorrelationID(context.Background(), tt.correlationID)
+			handled, err := service.UserEventHandled(ctx, tt.eventType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.handled, handled)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// DefaultLeaderTTL is how long the leader lock outlives a leader that stopped
+// renewing it by default, bounding how long failover takes
+const DefaultLeaderTTL = 15 * time.Second
+
+// leaderReleaseTimeout bounds giving up the lock when leading ends
+const leaderReleaseTimeout = 5 * time.Second
+
+// LeaderElector makes one of several instances the leader, which runs the
+// background jobs that must not run on every instance at once. The others
+// keep campaigning and one takes over within the lock's TTL if the leader dies.
+type LeaderElector struct {
+	lock   services.LeaderLock
+	ttl    time.Duration
+	logger *zap.Logger
+
+	leading atomic.Bool
+}
+
+// NewLeaderElector creates an elector campaigning with lock, which is held for
+// ttl at a time and renewed every third of it; ttl of 0 or less uses DefaultLeaderTTL
+func NewLeaderElector(lock services.LeaderLock, ttl time.Duration, logger *zap.Logger) *LeaderElector {
+	if ttl <= 0 {
+		ttl = DefaultLeaderTTL
+	}
+	return &LeaderElector{
+		lock:   lock,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// IsLeader reports whether this instance currently leads
+func (e *LeaderElector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// Run campaigns for leadership until ctx is cancelled. Each time this instance
+// becomes leader, lead is started with a context that is cancelled once
+// leadership is lost, and Run waits for it to return before campaigning
+// again. Leadership is given up when ctx is cancelled, so another instance
+// takes over without waiting for the lock to expire.
+func (e *LeaderElector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := e.lock.Acquire(ctx, e.ttl)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warn("error acquiring leader lock", zap.Error(err))
+		}
+		if acquired {
+			e.leadUntilLost(ctx, ticker, lead)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// leadUntilLost runs lead while renewing the lock on every tick, stopping it
+// when a renewal fails or ctx is cancelled. A failed renewal steps down even if
+// it was only the lock's store that was unreachable: the lock may have
+// expired and been taken by another instance meanwhile.
+func (e *LeaderElector) leadUntilLost(ctx context.Context, ticker *time.Ticker, lead func(ctx context.Context)) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leaderCtx)
+	}()
+
+	e.setLeading(true)
+	e.logger.Info("became leader")
+
+	for held := true; held; {
+		select {
+		case <-ctx.Done():
+			held = false
+		case <-ticker.C:
+			var err error
+			if held, err = e.lock.Renew(ctx, e.ttl); err != nil {
+				if ctx.Err() == nil {
+					e.logger.Warn("error renewing leader lock", zap.Error(err))
+				}
+				held = false
+			}
+		}
+	}
+
+	cancel()
+	<-done
+	e.setLeading(false)
+	e.logger.Info("no longer leader")
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), leaderReleaseTimeout)
+	defer cancelRelease()
+	if err := e.lock.Release(releaseCtx); err != nil {
+		e.logger.Warn("error releasing leader lock", zap.Error(err))
+	}
+}
+
+func (e *LeaderElector) setLeading(leading bool) {
+	e.leading.Store(leading)
+	metrics.SetLeader(leading)
+}
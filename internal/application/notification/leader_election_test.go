@@ -0,0 +1,86 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeLeaderLocks is a lock store shared by the fake locks of several instances
+type fakeLeaderLocks struct {
+	mu     sync.Mutex
+	holder string
+}
+
+// fakeLeaderLock is one instance's handle on a fakeLeaderLocks; it never expires
+type fakeLeaderLock struct {
+	locks *fakeLeaderLocks
+	name  string
+}
+
+func (l *fakeLeaderLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	l.locks.mu.Lock()
+	defer l.locks.mu.Unlock()
+	if l.locks.holder == "" {
+		l.locks.holder = l.name
+	}
+	return l.locks.holder == l.name, nil
+}
+
+func (l *fakeLeaderLock) Renew(ctx context.Context, ttl time.Duration) (bool, error) {
+	l.locks.mu.Lock()
+	defer l.locks.mu.Unlock()
+	return l.locks.holder == l.name, nil
+}
+
+func (l *fakeLeaderLock) Release(ctx context.Context) error {
+	l.locks.mu.Lock()
+	defer l.locks.mu.Unlock()
+	if l.locks.holder == l.name {
+		l.locks.holder = ""
+	}
+	return nil
+}
+
+func TestLeaderElector_OneLeaderWithFailover(t *testing.T) {
+	locks := &fakeLeaderLocks{}
+	first := NewLeaderElector(&fakeLeaderLock{locks: locks, name: "first"}, 30*time.Millisecond, zap.NewNop())
+	second := NewLeaderElector(&fakeLeaderLock{locks: locks, name: "second"}, 30*time.Millisecond, zap.NewNop())
+
+	led := make(chan string, 4)
+	lead := func(name string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			led <- name
+			<-ctx.Done()
+		}
+	}
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	defer stopFirst()
+	go first.Run(firstCtx, lead("first"))
+	assert.Equal(t, "first", <-led)
+	assert.Eventually(t, first.IsLeader, time.Second, time.Millisecond)
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	go second.Run(secondCtx, lead("second"))
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, second.IsLeader())
+
+	// The leader shutting down hands over to the other instance
+	stopFirst()
+	assert.Equal(t, "second", <-led)
+	assert.Eventually(t, second.IsLeader, time.Second, time.Millisecond)
+	assert.False(t, first.IsLeader())
+
+	// A leader whose lock was lost stops leading
+	locks.mu.Lock()
+	locks.holder = "someone else"
+	locks.mu.Unlock()
+	assert.Eventually(t, func() bool { return !second.IsLeader() }, time.Second, time.Millisecond)
+	assert.Empty(t, led)
+}
@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// SetLocales sets the locales event templates are available in; templates are
+// rendered in locales.Default, model.DefaultLocale unless set, when none of the
+// recipient's preferred locales is available
+func (s *Service) SetLocales(locales model.Locales) {
+	s.locales = locales
+}
+
+// localizedTemplateName returns the name of the template name translated into
+// locale: "welcome.html" in French is "welcome.fr.html"
+func localizedTemplateName(name, locale string) string {
+	if base, found := strings.CutSuffix(name, ".html"); found {
+		return base + "." + locale + ".html"
+	}
+	return name + "." + locale
+}
+
+// resolveLocale returns the available locale an event's template is rendered
+// in: requested, the locale the event asks for, if available, and otherwise the
+// best match for the locales preferred by the caller ctx came from
+func (s *Service) resolveLocale(ctx context.Context, requested string) string {
+	locales := s.locales
+	locales.Default = s.defaultLocale()
+
+	preferred := model.PreferredLocalesFromContext(ctx)
+	if requested != "" {
+		preferred = append([]string{requested}, preferred...)
+	}
+	return locales.Resolve(preferred...)
+}
+
+// defaultLocale returns the locale templates are rendered in when none of the
+// recipient's preferred locales is available
+func (s *Service) defaultLocale() string {
+	if s.locales.Default == "" {
+		return model.DefaultLocale
+	}
+	return s.locales.Default
+}
+
+// PreviewTemplateLocales renders the template name, such as "welcome.html", with
+// data in the default locale and each available locale. Locales the template
+// isn't translated into are reported as missing rather than rendered from the
+// untranslated template, and a translation that fails to render is reported
+// without hiding the others. The untranslated template must exist.
+func (s *Service) PreviewTemplateLocales(ctx context.Context, name string, data map[string]interface{}) (*model.TemplateLocalePreview, error) {
+	if s.renderTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.renderTimeout)
+		defer cancel()
+	}
+
+	preview := &model.TemplateLocalePreview{
+		Name:          name,
+		DefaultLocale: s.defaultLocale(),
+		Rendered:      make(map[string]string),
+		Missing:       []string{},
+		Errors:        make(map[string]string),
+	}
+
+	for _, locale := range append([]string{preview.DefaultLocale}, s.locales.Available...) {
+		templateName := name
+		if locale != preview.DefaultLocale {
+			templateName = localizedTemplateName(name, locale)
+		}
+
+		localeData := make(map[string]interface{}, len(data)+1)
+		for key, value := range data {
+			localeData[key] = value
+		}
+		localeData["Locale"] = locale
+
+		content, err := s.templateEngine.ProcessTemplate(ctx, templateName, localeData)
+		// A missing partial the template includes is a rendering error, not a missing translation
+		var notFound model.ErrTemplateNotFound
+		switch {
+		case errors.As(err, &notFound) && notFound.ID == templateName:
+			if locale == preview.DefaultLocale {
+				return nil, notFound
+			}
+			preview.Missing = append(preview.Missing, locale)
+		case err != nil:
+			preview.Errors[locale] = err.Error()
+		default:
+			preview.Rendered[locale] = content
+		}
+	}
+
+	return preview, nil
+}
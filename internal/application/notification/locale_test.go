@@ -0,0 +1,103 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// knownTemplateEngine renders the templates it knows as their name and locale
+type knownTemplateEngine map[string]bool
+
+func (e knownTemplateEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	if !e[templateName] {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
+	return templateName + " " + data.(map[string]interface{})["Locale"].(string), nil
+}
+
+func (e knownTemplateEngine) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	return "", nil
+}
+
+func TestService_Locales(t *testing.T) {
+	engine := knownTemplateEngine{"welcome.html": true, "welcome.fr.html": true}
+
+	tests := []struct {
+		name     string
+		locale   string
+		header   []string
+		expected string
+	}{
+		{name: "default locale", expected: "welcome.html en"},
+		{name: "event locale", locale: "fr-CA", expected: "welcome.fr.html fr"},
+		{name: "caller's preferred locale", header: []string{"ja", "fr"}, expected: "welcome.fr.html fr"},
+		{name: "event locale before the caller's", locale: "de", header: []string{"fr"}, expected: "welcome.html de"},
+		{name: "unavailable locale", locale: "ja", expected: "welcome.html en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+			service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, engine, nil, nil, zap.NewNop())
+			service.SetLocales(model.Locales{Default: "en", Available: []string{"fr", "de"}})
+
+			ctx := context.Background()
+			if tt.header != nil {
+				ctx = model.ContextWithPreferredLocales(ctx, tt.header)
+			}
+			payload := []byte(`{"userId": "42", "email": "user@example.com", "username": "jane", "locale": "` + tt.locale + `"}`)
+			require.NoError(t, service.HandleUserEvent(ctx, "user.registered", payload))
+
+			require.Len(t, repo.notifications, 1)
+			for _, notification := range repo.notifications {
+				assert.Equal(t, tt.expected, notification.Content)
+			}
+		})
+	}
+}
+
+// failingTemplateEngine fails to render the templates in failures, and renders
+// the others as knownTemplateEngine does
+type failingTemplateEngine struct {
+	knownTemplateEngine
+	failures map[string]error
+}
+
+func (e failingTemplateEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	if err, ok := e.failures[templateName]; ok {
+		return "", err
+	}
+	return e.knownTemplateEngine.ProcessTemplate(ctx, templateName, data)
+}
+
+func TestService_PreviewTemplateLocales(t *testing.T) {
+	engine := failingTemplateEngine{
+		knownTemplateEngine: knownTemplateEngine{"welcome.html": true, "welcome.fr.html": true},
+		failures: map[string]error{
+			"welcome.es.html": model.ErrTemplateNotFound{ID: "footer.html"},
+		},
+	}
+	service := NewService(&fakeNotificationRepository{}, nil, nil, nil, nil, engine, nil, nil, zap.NewNop())
+	service.SetLocales(model.Locales{Default: "en", Available: []string{"fr", "de", "es"}})
+
+	t.Run("renders every locale", func(t *testing.T) {
+		preview, err := service.PreviewTemplateLocales(context.Background(), "welcome.html", map[string]interface{}{"FirstName": "Jane"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "en", preview.DefaultLocale)
+		assert.Equal(t, map[string]string{"en": "welcome.html en", "fr": "welcome.fr.html fr"}, preview.Rendered)
+		assert.Equal(t, []string{"de"}, preview.Missing)
+		// A missing partial isn't mistaken for a missing translation
+		assert.Equal(t, map[string]string{"es": "template not found: footer.html"}, preview.Errors)
+	})
+
+	t.Run("requires the untranslated template", func(t *testing.T) {
+		_, err := service.PreviewTemplateLocales(context.Background(), "goodbye.html", nil)
+		assert.ErrorIs(t, err, model.ErrNotFound)
+	})
+}
@@ -0,0 +1,342 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"go.uber.org/zap"
+)
+
+// OutboxConfig holds the configuration for the outbox dispatcher
+type OutboxConfig struct {
+	PollInterval time.Duration // How often to look for pending entries when the outbox is idle
+	BatchSize    int           // Maximum entries claimed per poll
+	BatchPause   time.Duration // Wait between full batches while draining a backlog, e.g. after an outage, so it reaches the providers spread out; 0 for none
+	Lease        time.Duration // How long a claimed entry is hidden from other dispatchers
+	MaxAttempts  int           // Claims of an entry before its notification is failed instead of sent; 0 for no limit
+	// MaxRetryAge is how long after an entry was queued, or after the send
+	// window its notification was deferred to opened, it is retried before its
+	// notification is failed instead of sent; 0 for no limit. Whichever of it
+	// and MaxAttempts is reached first gives up on the entry.
+	MaxRetryAge time.Duration
+}
+
+// DefaultOutboxConfig returns an OutboxConfig with recommended default values
+func DefaultOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		PollInterval: time.Second,
+		BatchSize:    50,
+		BatchPause:   50 * time.Millisecond,
+		Lease:        time.Minute,
+		MaxAttempts:  5,
+		MaxRetryAge:  24 * time.Hour,
+	}
+}
+
+// OutboxDispatcher delivers notifications queued in the transactional outbox. An
+// entry is only marked done after its send was attempted, so a crash mid-send
+// results in a redelivery once the lease expires: delivery is at-least-once.
+type OutboxDispatcher struct {
+	service *Service
+	outbox  services.NotificationOutbox
+	pool    *WorkerPool
+	config  OutboxConfig
+	logger  *zap.Logger
+
+	// wakeups announces when new entries become due; nil relies on polling alone
+	wakeups <-chan time.Time
+}
+
+// NewOutboxDispatcher creates a dispatcher sending through the service's providers.
+// When pool is nil, a batch's notifications are sent one at a time; otherwise
+// they are sent concurrently on the pool, in order per recipient.
+func NewOutboxDispatcher(service *Service, outbox services.NotificationOutbox, pool *WorkerPool, config OutboxConfig, logger *zap.Logger) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		service: service,
+		outbox:  outbox,
+		pool:    pool,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// SetWakeups sets where the dispatcher hears about new entries, such as a
+// listener on the database's announcements. An entry due now is claimed at
+// once, and one due later when its time comes, instead of at the next poll;
+// the zero time claims at once. Polling continues as a safety net for
+// announcements that are missed or dropped.
+func (d *OutboxDispatcher) SetWakeups(wakeups <-chan time.Time) {
+	d.wakeups = wakeups
+}
+
+// Run drains the outbox until ctx is cancelled
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	// due fires when the earliest announced entry that wasn't due yet becomes due
+	due := time.NewTimer(0)
+	defer due.Stop()
+	<-due.C
+	var nextDue time.Time
+
+	wakeups := d.wakeups
+
+	for {
+		// Keep draining while full batches come back, then wait for the next tick
+		for {
+			claimed, err := d.DispatchPending(ctx)
+			if err != nil {
+				d.logger.Error("error dispatching outbox entries", zap.Error(err))
+				break
+			}
+			if claimed < d.config.BatchSize {
+				break
+			}
+			if d.config.BatchPause > 0 {
+				pause := time.NewTimer(d.config.BatchPause)
+				select {
+				case <-ctx.Done():
+					pause.Stop()
+					return
+				case <-pause.C:
+				}
+			}
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				break wait
+			case <-due.C:
+				nextDue = time.Time{}
+				break wait
+			case at, ok := <-wakeups:
+				if !ok {
+					wakeups = nil
+					continue
+				}
+				delay := time.Until(at)
+				if delay <= 0 {
+					break wait
+				}
+				if nextDue.IsZero() || at.Before(nextDue) {
+					if !due.Stop() && !nextDue.IsZero() {
+						<-due.C
+					}
+					due.Reset(delay)
+					nextDue = at
+				}
+			}
+		}
+	}
+}
+
+// DispatchPending claims one batch of pending entries and sends them, returning
+// how many were claimed once every send has finished
+func (d *OutboxDispatcher) DispatchPending(ctx context.Context) (int, error) {
+	entries, err := d.outbox.ClaimPending(ctx, d.config.BatchSize, d.config.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming outbox entries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			// Unprocessed entries are picked up again after their lease expires
+			return len(entries), ctx.Err()
+		}
+
+		notification, ok := d.loadEntry(ctx, entry)
+		if !ok {
+			continue
+		}
+
+		if reason := d.giveUpReason(entry, notification); reason != "" {
+			d.abandonEntry(ctx, entry, notification, reason)
+			continue
+		}
+
+		if d.pool == nil {
+			d.dispatchEntry(ctx, entry, notification)
+			continue
+		}
+
+		// Sends run under the pool's context, so stopping the dispatcher doesn't
+		// abort them; the pool cancels them if shutdown runs out of time
+		entry := entry
+		wg.Add(1)
+		err := d.pool.Submit(ctx, dispatchOrderingKey(entry.TenantID, notification), func(ctx context.Context) {
+			defer wg.Done()
+			d.dispatchEntry(ctx, entry, notification)
+		})
+		if err != nil {
+			wg.Done()
+			return len(entries), err
+		}
+	}
+
+	return len(entries), nil
+}
+
+// loadEntry loads an entry's notification, reporting false if the entry failed
+// and is left to be retried
+func (d *OutboxDispatcher) loadEntry(ctx context.Context, entry *model.OutboxEntry) (*model.Notification, bool) {
+	ctx = model.ContextWithTenant(ctx, entry.TenantID)
+
+	notification, err := d.service.repo.FindByID(ctx, entry.NotificationID.String())
+	if err != nil {
+		logger := d.entryLogger(entry)
+		logger.Error("error loading outbox notification", zap.Error(err))
+		if err := d.outbox.MarkFailed(ctx, entry.ID, err.Error()); err != nil {
+			logger.Error("error marking outbox entry failed", zap.Error(err))
+		}
+		return nil, false
+	}
+	return notification, true
+}
+
+// dispatchEntry sends a single entry's notification, which is nil if it was
+// deleted. Provider failures are recorded on the notification and complete the entry.
+// If ctx is already done, typically because the service is shutting down, the
+// entry is left as is: its notification stays pending and is sent once the lease
+// expires. A send that has started isn't cancelled, so its outcome is recorded.
+// A send that panics marks the entry failed, leaving it to be retried when its
+// lease expires, which is recorded as the notification's next retry.
+func (d *OutboxDispatcher) dispatchEntry(ctx context.Context, entry *model.OutboxEntry, notification *model.Notification) {
+	if ctx.Err() != nil {
+		return
+	}
+	ctx = model.ContextWithTenant(context.WithoutCancel(ctx), entry.TenantID)
+	logger := d.entryLogger(entry)
+	if notification != nil {
+		ctx = model.ContextWithCorrelationID(ctx, notification.CorrelationID)
+		logger = logging.WithCorrelationID(logger, notification.CorrelationID)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("outbox notification delivery panicked", zap.Any("panic", r), zap.Stack("stack"))
+			if err := d.outbox.MarkFailed(ctx, entry.ID, fmt.Sprintf("panic: %v", r)); err != nil {
+				logger.Error("error marking outbox entry failed", zap.Error(err))
+			}
+			d.scheduleRetry(ctx, entry, logger)
+		}
+	}()
+
+	// Already sent by an earlier attempt that crashed before completing the entry,
+	// or deleted since: there is nothing left to deliver
+	if notification != nil && notification.Status == model.StatusPending {
+		if err := d.service.dispatch(ctx, notification); err != nil {
+			logger.Warn("outbox notification delivery failed", zap.Error(err))
+		}
+	}
+
+	if err := d.outbox.MarkDone(ctx, entry.ID); err != nil {
+		logger.Error("error marking outbox entry done", zap.Error(err))
+	}
+}
+
+// scheduleRetry records on an entry's pending notification that delivery is
+// attempted again once the entry's lease expires. The notification is reloaded
+// since the attempt that failed may have left the loaded copy half updated.
+func (d *OutboxDispatcher) scheduleRetry(ctx context.Context, entry *model.OutboxEntry, logger *zap.Logger) {
+	if entry.AvailableAt.IsZero() {
+		return
+	}
+
+	notification, err := d.service.repo.FindByID(ctx, entry.NotificationID.String())
+	if err != nil {
+		logger.Error("error loading outbox notification", zap.Error(err))
+		return
+	}
+	if notification == nil || notification.Status != model.StatusPending {
+		return
+	}
+
+	notification.ScheduleRetry(entry.AvailableAt)
+	if err := d.service.repo.Update(ctx, notification); err != nil {
+		logger.Error("error recording next retry", zap.Error(err))
+	}
+}
+
+// giveUpReason returns why an entry is given up on rather than retried, or ""
+// if it is sent: it was claimed more than MaxAttempts times without an outcome
+// being recorded, e.g. because its send keeps panicking, or it is a retry more
+// than MaxRetryAge after the entry first became due
+func (d *OutboxDispatcher) giveUpReason(entry *model.OutboxEntry, notification *model.Notification) string {
+	if d.config.MaxAttempts > 0 && entry.Attempts > d.config.MaxAttempts {
+		return fmt.Sprintf("gave up after %d delivery attempts", entry.Attempts-1)
+	}
+
+	// A first attempt is never too late, however long the outbox took to reach it
+	if d.config.MaxRetryAge <= 0 || entry.Attempts <= 1 {
+		return ""
+	}
+	due := entry.CreatedAt
+	if notification != nil && notification.ScheduledAt != nil && notification.ScheduledAt.After(due) {
+		due = *notification.ScheduledAt
+	}
+	if age := time.Since(due); age > d.config.MaxRetryAge {
+		return fmt.Sprintf("gave up after retrying for %s", age.Round(time.Second))
+	}
+	return ""
+}
+
+// abandonEntry gives up on an entry for reason: its pending notification is
+// failed with the reason and the entry's last error, and the entry completed.
+// The notification is nil if it was deleted.
+func (d *OutboxDispatcher) abandonEntry(ctx context.Context, entry *model.OutboxEntry, notification *model.Notification, reason string) {
+	ctx = model.ContextWithTenant(ctx, entry.TenantID)
+	logger := d.entryLogger(entry)
+	logger.Error("giving up on outbox entry",
+		zap.Int("attempts", entry.Attempts-1),
+		zap.String("reason", reason),
+		zap.String("lastError", entry.LastError),
+	)
+
+	if notification != nil && notification.Status == model.StatusPending {
+		if entry.LastError != "" {
+			reason += ": " + entry.LastError
+		}
+		if err := notification.TransitionTo(model.StatusFailed, reason); err != nil {
+			logger.Error("error updating notification status", zap.Error(err))
+		} else if err := d.service.repo.Update(ctx, notification); err != nil {
+			// Left to be claimed again, so the notification isn't stranded as pending
+			logger.Error("error updating notification status", zap.Error(err))
+			return
+		} else {
+			recordEndToEndLatency(notification)
+		}
+	}
+
+	if err := d.outbox.MarkDone(ctx, entry.ID); err != nil {
+		logger.Error("error marking outbox entry done", zap.Error(err))
+	}
+}
+
+func (d *OutboxDispatcher) entryLogger(entry *model.OutboxEntry) *zap.Logger {
+	return d.logger.With(
+		zap.Int64("outboxId", entry.ID),
+		zap.String("notificationId", entry.NotificationID.String()),
+	)
+}
+
+// dispatchOrderingKey keeps a recipient's notifications on one worker, so they are
+// sent in the order they were queued
+func dispatchOrderingKey(tenantID string, notification *model.Notification) string {
+	if notification == nil {
+		return ""
+	}
+	return tenantID + ":" + notification.Recipient
+}
@@ -0,0 +1,428 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeNotificationRepository keeps notifications in memory; only the methods
+// the dispatcher uses are implemented
+type fakeNotificationRepository struct {
+	repository.NotificationRepository
+
+	mu            sync.Mutex
+	notifications map[string]*model.Notification
+}
+
+func (r *fakeNotificationRepository) FindByID(ctx context.Context, id string) (*model.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.notifications[id], nil
+}
+
+func (r *fakeNotificationRepository) Save(ctx context.Context, notification *model.Notification) error {
+	return r.Update(ctx, notification)
+}
+
+func (r *fakeNotificationRepository) SaveBatch(ctx context.Context, notifications []*model.Notification) error {
+	for _, notification := range notifications {
+		if err := r.Save(ctx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeNotificationRepository) Update(ctx context.Context, notification *model.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications[notification.ID.String()] = notification
+	return nil
+}
+
+func (r *fakeNotificationRepository) FindByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var notifications []*model.Notification
+	for _, notification := range r.notifications {
+		if notification.Recipient == recipient {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications, nil
+}
+
+func (r *fakeNotificationRepository) FindByProviderMessageID(ctx context.Context, provider, messageID string) (*model.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, notification := range r.notifications {
+		if notification.Provider == provider && notification.ProviderMessageID == messageID {
+			return notification, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeNotificationRepository) FindByGroupID(ctx context.Context, groupID string) ([]*model.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var notifications []*model.Notification
+	for _, notification := range r.notifications {
+		if notification.GroupID == groupID {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications, nil
+}
+
+func (r *fakeNotificationRepository) FindByThreadID(ctx context.Context, threadID string) ([]*model.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var notifications []*model.Notification
+	for _, notification := range r.notifications {
+		if notification.ThreadID == threadID {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].CreatedAt.Before(notifications[j].CreatedAt) })
+	return notifications, nil
+}
+
+func (r *fakeNotificationRepository) FindByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var notifications []*model.Notification
+	for _, notification := range r.notifications {
+		if notification.CorrelationID == correlationID {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].CreatedAt.Before(notifications[j].CreatedAt) })
+	if offset >= len(notifications) {
+		return nil, nil
+	}
+	notifications = notifications[offset:]
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (r *fakeNotificationRepository) status(id uuid.UUID) model.NotificationStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.notifications[id.String()].Status
+}
+
+// fakeOutbox hands out its entries once, up to limit per claim, and records
+// which were completed
+type fakeOutbox struct {
+	services.NotificationOutbox
+
+	mu      sync.Mutex
+	entries []*model.OutboxEntry
+	done    []int64
+	failed  map[int64]string
+}
+
+func (o *fakeOutbox) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := o.entries
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	o.entries = o.entries[len(entries):]
+	return entries, nil
+}
+
+func (o *fakeOutbox) MarkDone(ctx context.Context, id int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done = append(o.done, id)
+	return nil
+}
+
+func (o *fakeOutbox) MarkFailed(ctx context.Context, id int64, reason string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.failed == nil {
+		o.failed = make(map[int64]string)
+	}
+	o.failed[id] = reason
+	return nil
+}
+
+// blockingEmailProvider holds every send until released
+type blockingEmailProvider struct {
+	started chan string
+	release chan struct{}
+}
+
+func (p *blockingEmailProvider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	p.started <- to
+	<-p.release
+	return "message-" + to, nil
+}
+
+func TestOutboxDispatcher_ShutdownLeavesUnsentNotificationsPending(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	outbox := &fakeOutbox{}
+	for i, recipient := range []string{"first@example.com", "second@example.com"} {
+		notification := model.NewNotification(recipient, model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		repo.notifications[notification.ID.String()] = notification
+		outbox.entries = append(outbox.entries, &model.OutboxEntry{ID: int64(i + 1), TenantID: model.DefaultTenantID, NotificationID: notification.ID})
+	}
+	first, second := outbox.entries[0].NotificationID, outbox.entries[1].NotificationID
+
+	provider := &blockingEmailProvider{started: make(chan string, 2), release: make(chan struct{})}
+	service := NewService(repo, provider, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 10}, zap.NewNop())
+	dispatcher := NewOutboxDispatcher(service, outbox, pool, DefaultOutboxConfig(), zap.NewNop())
+
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		_, err := dispatcher.DispatchPending(context.Background())
+		assert.NoError(t, err)
+	}()
+
+	// The first send is in flight and the second is queued behind it when the
+	// shutdown deadline passes
+	assert.Equal(t, "first@example.com", <-provider.started)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(provider.release)
+	<-dispatched
+
+	// The send in flight completes; the queued one is left for the next claim
+	assert.Equal(t, model.StatusSent, repo.status(first))
+	assert.Equal(t, model.StatusPending, repo.status(second))
+	assert.Equal(t, []int64{1}, outbox.done)
+	assert.Empty(t, provider.started)
+}
+
+func TestOutboxDispatcher_DispatchesBatchOnPool(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	outbox := &fakeOutbox{}
+	for i := 0; i < 3; i++ {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		repo.notifications[notification.ID.String()] = notification
+		outbox.entries = append(outbox.entries, &model.OutboxEntry{ID: int64(i + 1), TenantID: model.DefaultTenantID, NotificationID: notification.ID})
+	}
+
+	provider := &blockingEmailProvider{started: make(chan string, 3), release: make(chan struct{})}
+	close(provider.release)
+	service := NewService(repo, provider, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 2, QueueSize: 10}, zap.NewNop())
+	dispatcher := NewOutboxDispatcher(service, outbox, pool, DefaultOutboxConfig(), zap.NewNop())
+
+	claimed, err := dispatcher.DispatchPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, claimed)
+	require.NoError(t, pool.Shutdown(context.Background()))
+
+	for _, notification := range repo.notifications {
+		assert.Equal(t, model.StatusSent, notification.Status)
+	}
+	assert.ElementsMatch(t, []int64{1, 2, 3}, outbox.done)
+}
+
+// panickingEmailProvider panics on every send
+type panickingEmailProvider struct{}
+
+func (panickingEmailProvider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	panic("provider bug")
+}
+
+func TestOutboxDispatcher_PanickingSendMarksEntryFailed(t *testing.T) {
+	for _, withPool := range []bool{false, true} {
+		t.Run(fmt.Sprintf("pool=%t", withPool), func(t *testing.T) {
+			repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+			notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+			repo.notifications[notification.ID.String()] = notification
+			availableAt := time.Now().Add(time.Minute)
+			outbox := &fakeOutbox{entries: []*model.OutboxEntry{{ID: 1, TenantID: model.DefaultTenantID, NotificationID: notification.ID, Attempts: 1, AvailableAt: availableAt}}}
+
+			service := NewService(repo, panickingEmailProvider{}, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+			var pool *WorkerPool
+			if withPool {
+				pool = NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 10}, zap.NewNop())
+			}
+			dispatcher := NewOutboxDispatcher(service, outbox, pool, DefaultOutboxConfig(), zap.NewNop())
+
+			_, err := dispatcher.DispatchPending(context.Background())
+			require.NoError(t, err)
+			if pool != nil {
+				require.NoError(t, pool.Shutdown(context.Background()))
+			}
+
+			// The entry is left to be retried, with the panic as its last error
+			assert.Equal(t, map[int64]string{1: "panic: provider bug"}, outbox.failed)
+			assert.Empty(t, outbox.done)
+			assert.Equal(t, model.StatusPending, repo.status(notification.ID))
+
+			// The retry is recorded for when the lease expires
+			found, err := repo.FindByID(context.Background(), notification.ID.String())
+			require.NoError(t, err)
+			require.NotNil(t, found.NextRetryAt)
+			assert.True(t, availableAt.Equal(*found.NextRetryAt))
+		})
+	}
+}
+
+func TestOutboxDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	repo.notifications[notification.ID.String()] = notification
+	outbox := &fakeOutbox{entries: []*model.OutboxEntry{
+		{ID: 1, TenantID: model.DefaultTenantID, NotificationID: notification.ID, Attempts: 4, LastError: "panic: provider bug"},
+	}}
+
+	service := NewService(repo, panickingEmailProvider{}, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+	config := DefaultOutboxConfig()
+	config.MaxAttempts = 3
+	dispatcher := NewOutboxDispatcher(service, outbox, nil, config, zap.NewNop())
+
+	_, err := dispatcher.DispatchPending(context.Background())
+	require.NoError(t, err)
+
+	// The notification is failed without another send, and the entry completed
+	assert.Equal(t, model.StatusFailed, repo.status(notification.ID))
+	assert.Equal(t, "gave up after 3 delivery attempts: panic: provider bug", notification.ErrorMessage)
+	assert.Equal(t, []int64{1}, outbox.done)
+	assert.Empty(t, outbox.failed)
+}
+
+func TestOutboxDispatcher_GivesUpAfterMaxRetryAge(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	repo.notifications[notification.ID.String()] = notification
+	deferred := model.NewNotification("later@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	scheduledAt := time.Now().Add(-time.Minute)
+	deferred.ScheduledAt = &scheduledAt
+	repo.notifications[deferred.ID.String()] = deferred
+	queuedAt := time.Now().Add(-2 * time.Hour)
+	outbox := &fakeOutbox{entries: []*model.OutboxEntry{
+		{ID: 1, TenantID: model.DefaultTenantID, NotificationID: notification.ID, Attempts: 2, LastError: "provider timeout", CreatedAt: queuedAt},
+		{ID: 2, TenantID: model.DefaultTenantID, NotificationID: deferred.ID, Attempts: 2, CreatedAt: queuedAt},
+	}}
+
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+	config := DefaultOutboxConfig()
+	config.MaxAttempts = 100
+	config.MaxRetryAge = time.Hour
+	dispatcher := NewOutboxDispatcher(service, outbox, nil, config, zap.NewNop())
+
+	_, err := dispatcher.DispatchPending(context.Background())
+	require.NoError(t, err)
+
+	// The age cap is reached long before the attempt cap
+	assert.Equal(t, model.StatusFailed, repo.status(notification.ID))
+	assert.Equal(t, "gave up after retrying for 2h0m0s: provider timeout", notification.ErrorMessage)
+
+	// A notification deferred to its send window is aged from when the window opened
+	assert.Equal(t, model.StatusSent, repo.status(deferred.ID))
+	assert.Equal(t, []string{"later@example.com"}, provider.recipients)
+	assert.ElementsMatch(t, []int64{1, 2}, outbox.done)
+}
+
+func TestOutboxDispatcher_ClaimsAnnouncedEntriesWhenDue(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	outbox := &fakeOutbox{}
+	provider := &blockingEmailProvider{started: make(chan string, 2), release: make(chan struct{})}
+	close(provider.release)
+	service := NewService(repo, provider, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+
+	// Polling alone would take an hour to find either entry
+	config := DefaultOutboxConfig()
+	config.PollInterval = time.Hour
+	dispatcher := NewOutboxDispatcher(service, outbox, nil, config, zap.NewNop())
+	wakeups := make(chan time.Time)
+	dispatcher.SetWakeups(wakeups)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+
+	enqueue := func(id int64, recipient string) {
+		notification := model.NewNotification(recipient, model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		require.NoError(t, repo.Save(context.Background(), notification))
+		outbox.mu.Lock()
+		defer outbox.mu.Unlock()
+		outbox.entries = append(outbox.entries, &model.OutboxEntry{ID: id, TenantID: model.DefaultTenantID, NotificationID: notification.ID})
+	}
+	sent := func() string {
+		select {
+		case to := <-provider.started:
+			return to
+		case <-time.After(time.Second):
+			t.Fatal("no notification sent")
+			return ""
+		}
+	}
+
+	// An entry due now is claimed at once
+	enqueue(1, "now@example.com")
+	wakeups <- time.Now()
+	assert.Equal(t, "now@example.com", sent())
+
+	// One due later is claimed when its time comes
+	enqueue(2, "later@example.com")
+	announced := time.Now()
+	wakeups <- announced.Add(50 * time.Millisecond)
+	assert.Equal(t, "later@example.com", sent())
+	assert.GreaterOrEqual(t, time.Since(announced), 50*time.Millisecond)
+}
+
+func TestOutboxDispatcher_PausesBetweenFullBatches(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	outbox := &fakeOutbox{}
+	for i := 0; i < 5; i++ {
+		notification := model.NewNotification(fmt.Sprintf("user%d@example.com", i), model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		repo.notifications[notification.ID.String()] = notification
+		outbox.entries = append(outbox.entries, &model.OutboxEntry{ID: int64(i + 1), TenantID: model.DefaultTenantID, NotificationID: notification.ID})
+	}
+
+	provider := &blockingEmailProvider{started: make(chan string, 5), release: make(chan struct{})}
+	close(provider.release)
+	service := NewService(repo, provider, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+	config := DefaultOutboxConfig()
+	config.PollInterval = time.Hour
+	config.BatchSize = 2
+	config.BatchPause = 30 * time.Millisecond
+	dispatcher := NewOutboxDispatcher(service, outbox, nil, config, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	go dispatcher.Run(ctx)
+
+	for i := 0; i < 5; i++ {
+		select {
+		case <-provider.started:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 5 notifications sent", i)
+		}
+	}
+
+	// The backlog drains without waiting for a poll, pausing after each of the
+	// two full batches
+	assert.GreaterOrEqual(t, time.Since(start), 2*config.BatchPause)
+	assert.Less(t, time.Since(start), config.PollInterval)
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"fmt"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// SetDefaultPriority sets the priority notifications of notificationType get
+// when they don't name one; model.PriorityMedium is the default
+func (s *Service) SetDefaultPriority(notificationType model.NotificationType, priority model.Priority) {
+	if s.defaultPriorities == nil {
+		s.defaultPriorities = make(map[model.NotificationType]model.Priority)
+	}
+	s.defaultPriorities[notificationType] = priority
+}
+
+// SetDisallowedPriorities rejects notifications of notificationType sent with
+// any of priorities, e.g. low priority SMS; nil allows every priority
+func (s *Service) SetDisallowedPriorities(notificationType model.NotificationType, priorities []model.Priority) {
+	if s.disallowedPriorities == nil {
+		s.disallowedPriorities = make(map[model.NotificationType][]model.Priority)
+	}
+	s.disallowedPriorities[notificationType] = priorities
+}
+
+// defaultPriority returns the priority notifications of notificationType get
+// when they don't name one
+func (s *Service) defaultPriority(notificationType model.NotificationType) model.Priority {
+	if priority, ok := s.defaultPriorities[notificationType]; ok {
+		return priority
+	}
+	return model.PriorityMedium
+}
+
+// applyPriority gives a notification without a priority its type's default, and
+// rejects a priority its type doesn't allow with model.ErrInvalidNotification
+func (s *Service) applyPriority(notification *model.Notification) error {
+	if notification.Priority == "" {
+		notification.Priority = s.defaultPriority(notification.Type)
+	}
+	for _, disallowed := range s.disallowedPriorities[notification.Type] {
+		if notification.Priority == disallowed {
+			return model.ErrInvalidNotification{Message: fmt.Sprintf("%s priority is not allowed for %s notifications", notification.Priority, notification.Type)}
+		}
+	}
+	return nil
+}
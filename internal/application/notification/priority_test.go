@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestService_DefaultPriority(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, staticTemplateEngine{content: "<p>Welcome</p>"}, nil, nil, zap.NewNop())
+	service.SetDefaultPriority(model.EmailNotification, model.PriorityHigh)
+
+	t.Run("fills in a missing priority", func(t *testing.T) {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, nil)
+		notification.Priority = ""
+
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		assert.Equal(t, model.PriorityHigh, notification.Priority)
+	})
+
+	t.Run("keeps a requested priority", func(t *testing.T) {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, nil)
+		notification.Priority = model.PriorityLow
+
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		assert.Equal(t, model.PriorityLow, notification.Priority)
+	})
+
+	t.Run("medium for types without a default", func(t *testing.T) {
+		notification := model.NewNotification("+14155552671", model.SMSNotification, model.SMSTemplate, uuid.Nil, nil)
+		notification.Priority = ""
+
+		// There is no SMS provider, so the send fails after the priority is set
+		_ = service.SendNotification(context.Background(), notification)
+		assert.Equal(t, model.PriorityMedium, notification.Priority)
+	})
+
+	t.Run("applies to event notifications", func(t *testing.T) {
+		repo.notifications = make(map[string]*model.Notification)
+		payload := []byte(`{"userId": "42", "email": "user@example.com", "username": "jane"}`)
+		require.NoError(t, service.HandleUserEvent(context.Background(), "user.registered", payload))
+
+		require.Len(t, repo.notifications, 1)
+		for _, notification := range repo.notifications {
+			assert.Equal(t, model.PriorityHigh, notification.Priority)
+		}
+	})
+}
+
+func TestService_DisallowedPriorities(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	service.SetDefaultPriority(model.SMSNotification, model.PriorityHigh)
+	service.SetDisallowedPriorities(model.SMSNotification, []model.Priority{model.PriorityLow})
+
+	t.Run("rejects a disallowed combination", func(t *testing.T) {
+		notification := model.NewNotification("+14155552671", model.SMSNotification, model.SMSTemplate, uuid.Nil, nil)
+		notification.Priority = model.PriorityLow
+
+		err := service.SendNotification(context.Background(), notification)
+		assert.ErrorIs(t, err, model.ErrValidation)
+		assert.EqualError(t, err, "invalid notification: low priority is not allowed for sms notifications")
+		assert.Empty(t, repo.notifications)
+	})
+
+	t.Run("allows the priority on other types", func(t *testing.T) {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, nil)
+		notification.Priority = model.PriorityLow
+
+		assert.NoError(t, service.SendNotification(context.Background(), notification))
+	})
+}
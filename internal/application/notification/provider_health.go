@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+)
+
+// DefaultProviderHealthTimeout is how long checking a provider's health may take by default
+const DefaultProviderHealthTimeout = 5 * time.Second
+
+// SetProviderHealthTimeout bounds how long checking each provider's health may
+// take; 0 or less uses DefaultProviderHealthTimeout
+func (s *Service) SetProviderHealthTimeout(timeout time.Duration) {
+	s.providerHealthTimeout = timeout
+}
+
+// CheckProviderHealth checks the provider of every channel configured, at
+// once, without sending anything, and returns the outcomes in channel order.
+// Providers that can't be checked without sending are reported unchecked, and
+// a check that runs out of time unreachable.
+func (s *Service) CheckProviderHealth(ctx context.Context) []model.ProviderHealth {
+	timeout := s.providerHealthTimeout
+	if timeout <= 0 {
+		timeout = DefaultProviderHealthTimeout
+	}
+
+	var types []model.NotificationType
+	for _, t := range []model.NotificationType{model.EmailNotification, model.SMSNotification, model.PushNotification, model.WhatsAppNotification} {
+		if s.hasProvider(t) {
+			types = append(types, t)
+		}
+	}
+
+	results := make([]model.ProviderHealth, len(types))
+	var wg sync.WaitGroup
+	for i, t := range types {
+		wg.Add(1)
+		go func(health *model.ProviderHealth, t model.NotificationType) {
+			defer wg.Done()
+			*health = s.checkProviderHealth(ctx, t, timeout)
+		}(&results[i], t)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkProviderHealth checks the provider sending notifications of type t,
+// giving up after timeout
+func (s *Service) checkProviderHealth(ctx context.Context, t model.NotificationType, timeout time.Duration) model.ProviderHealth {
+	health := model.ProviderHealth{Type: t, Provider: s.providerName(t)}
+
+	checker, ok := s.provider(t).(services.HealthCheckedProvider)
+	if !ok {
+		health.Status = model.ProviderUnchecked
+		health.Error = model.ErrHealthCheckUnsupported.Error()
+		return health
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.CheckHealth(ctx)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	health.Status = model.HealthStatusForError(err)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("no answer within %s", timeout)
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
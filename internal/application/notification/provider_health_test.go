@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// checkedEmailProvider reports err from its health check
+type checkedEmailProvider struct {
+	recordingEmailProvider
+	err error
+}
+
+func (p *checkedEmailProvider) Name() string { return "ses" }
+
+func (p *checkedEmailProvider) CheckHealth(ctx context.Context) error { return p.err }
+
+// uncheckedPushProvider can't check its health
+type uncheckedPushProvider struct{}
+
+func (p uncheckedPushProvider) SendPush(ctx context.Context, token, title, message string) (string, error) {
+	return "", nil
+}
+
+// hungWhatsAppProvider never answers its health check
+type hungWhatsAppProvider struct{}
+
+func (p hungWhatsAppProvider) SendWhatsApp(ctx context.Context, to string, message model.WhatsAppTemplateMessage) (string, error) {
+	return "", nil
+}
+
+func (p hungWhatsAppProvider) CheckHealth(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestService_CheckProviderHealth(t *testing.T) {
+	email := &checkedEmailProvider{}
+	service := NewService(&fakeNotificationRepository{notifications: make(map[string]*model.Notification)}, email, nil, uncheckedPushProvider{}, hungWhatsAppProvider{}, nil, nil, nil, zap.NewNop())
+	service.SetProviderHealthTimeout(10 * time.Millisecond)
+
+	health := service.CheckProviderHealth(context.Background())
+	require.Len(t, health, 3)
+
+	assert.Equal(t, model.EmailNotification, health[0].Type)
+	assert.Equal(t, "ses", health[0].Provider)
+	assert.Equal(t, model.ProviderHealthy, health[0].Status)
+	assert.Empty(t, health[0].Error)
+
+	assert.Equal(t, model.PushNotification, health[1].Type)
+	assert.Equal(t, model.ProviderUnchecked, health[1].Status)
+
+	assert.Equal(t, model.WhatsAppNotification, health[2].Type)
+	assert.Equal(t, model.ProviderUnreachable, health[2].Status)
+	assert.Equal(t, "no answer within 10ms", health[2].Error)
+
+	// Checking never sends
+	assert.Empty(t, email.recipients)
+
+	email.err = model.ErrProviderUnauthorized{Provider: "ses", Reason: "The security token included in the request is invalid"}
+	health = service.CheckProviderHealth(context.Background())
+	assert.Equal(t, model.ProviderUnauthorized, health[0].Status)
+	assert.Contains(t, health[0].Error, "security token")
+}
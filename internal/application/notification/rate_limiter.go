@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// RateLimiter paces calls to a provider to a fixed rate shared by every caller,
+// so the service stays under the provider account's send quota. Calls above
+// the rate wait their turn instead of failing; with the dispatch worker pool,
+// that holds up the workers and, once the pool's queue is full, the producers.
+type RateLimiter struct {
+	name     string
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // When the next call may start
+}
+
+// NewRateLimiter creates a limiter allowing perSecond calls per second, which
+// must be positive, reported in metrics under name
+func NewRateLimiter(name string, perSecond float64) *RateLimiter {
+	metrics.SetProviderRateLimit(name, perSecond)
+	return &RateLimiter{
+		name:     name,
+		interval: time.Duration(float64(time.Second) / perSecond),
+	}
+}
+
+// Wait blocks until the caller's turn to call the provider, returning ctx's
+// error if ctx is done first
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	turn := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(turn)
+	if delay <= 0 {
+		metrics.RecordProviderCall(l.name)
+		return nil
+	}
+
+	metrics.RecordProviderCallWaiting(l.name, 1)
+	defer metrics.RecordProviderCallWaiting(l.name, -1)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		metrics.RecordProviderCall(l.name)
+		return nil
+	case <-ctx.Done():
+		// The turn is given up; later callers keep theirs, so the rate is never exceeded
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// SendLimit is an emergency brake on runaway sending, e.g. a loop producing
+// events without end. It counts notifications per calendar minute; once more
+// than the limit arrive in one minute it trips, and every notification is
+// throttled until the next minute starts or an operator clears it.
+//
+// Unlike RateLimiter, which paces sends to a provider's quota, the send limit
+// is a ceiling that should never be reached in normal operation.
+type SendLimit struct {
+	perMinute int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	minute  time.Time // Start of the minute being counted
+	sent    int
+	tripped bool
+}
+
+// NewSendLimit creates a send limit allowing perMinute notifications per minute
+func NewSendLimit(perMinute int) *SendLimit {
+	metrics.SetSendLimit(perMinute)
+	metrics.SetSendLimitTripped(false)
+	return &SendLimit{perMinute: perMinute, now: time.Now}
+}
+
+// Allow counts a notification and reports whether it may be sent. tripped is
+// true for the one call that trips the limit.
+func (l *SendLimit) Allow() (allowed, tripped bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if minute := l.now().Truncate(time.Minute); minute.After(l.minute) {
+		l.minute = minute
+		l.sent = 0
+		if l.tripped {
+			l.tripped = false
+			metrics.SetSendLimitTripped(false)
+		}
+	}
+	if l.tripped {
+		return false, false
+	}
+
+	l.sent++
+	metrics.RecordSendLimitSend()
+	if l.sent > l.perMinute {
+		l.tripped = true
+		metrics.SetSendLimitTripped(true)
+		return false, true
+	}
+	return true, false
+}
+
+// Clear resets the count for the current minute, letting notifications through again
+func (l *SendLimit) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sent = 0
+	l.tripped = false
+	metrics.SetSendLimitTripped(false)
+}
+
+// State returns the limit's current state
+func (l *SendLimit) State() model.SendLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := model.SendLimitState{PerMinute: l.perMinute, Sent: l.sent, Tripped: l.tripped}
+	// The count belongs to a minute that has passed
+	if l.now().Truncate(time.Minute).After(l.minute) {
+		state.Sent = 0
+		state.Tripped = false
+	}
+	return state
+}
@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendLimit_TripsUntilTheMinuteEnds(t *testing.T) {
+	now := time.Date(2025, 1, 14, 9, 0, 10, 0, time.UTC)
+	limit := NewSendLimit(2)
+	limit.now = func() time.Time { return now }
+
+	allowed, tripped := limit.Allow()
+	assert.True(t, allowed)
+	assert.False(t, tripped)
+	allowed, _ = limit.Allow()
+	assert.True(t, allowed)
+
+	// The third notification in the minute trips the limit, once
+	allowed, tripped = limit.Allow()
+	assert.False(t, allowed)
+	assert.True(t, tripped)
+	allowed, tripped = limit.Allow()
+	assert.False(t, allowed)
+	assert.False(t, tripped)
+	assert.Equal(t, model.SendLimitState{PerMinute: 2, Sent: 3, Tripped: true}, limit.State())
+
+	// A new minute starts the count again
+	now = now.Add(time.Minute)
+	assert.Equal(t, model.SendLimitState{PerMinute: 2}, limit.State())
+	allowed, _ = limit.Allow()
+	assert.True(t, allowed)
+	assert.Equal(t, model.SendLimitState{PerMinute: 2, Sent: 1}, limit.State())
+}
+
+func TestSendLimit_Clear(t *testing.T) {
+	now := time.Date(2025, 1, 14, 9, 0, 10, 0, time.UTC)
+	limit := NewSendLimit(1)
+	limit.now = func() time.Time { return now }
+
+	limit.Allow()
+	_, tripped := limit.Allow()
+	assert.True(t, tripped)
+
+	limit.Clear()
+	assert.Equal(t, model.SendLimitState{PerMinute: 1}, limit.State())
+	allowed, _ := limit.Allow()
+	assert.True(t, allowed)
+}
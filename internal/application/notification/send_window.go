@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"go.uber.org/zap"
+)
+
+// SendWindows keeps notifications of some categories to when they may go out,
+// such as marketing emails to business hours. Categories without a window,
+// such as transactional ones, go out at any time, and so do high priority
+// notifications whatever their category.
+type SendWindows struct {
+	windows map[string]model.SendWindow
+	now     func() time.Time
+}
+
+// NewSendWindows creates send windows keeping notifications of each category
+// in windows to its window
+func NewSendWindows(windows map[string]model.SendWindow) *SendWindows {
+	return &SendWindows{windows: windows, now: time.Now}
+}
+
+// SetSendWindows defers notifications arriving outside their category's send
+// window to when it next opens; nil sends every notification right away.
+// Deferred notifications wait in the outbox, so without one they are rejected
+// with model.ErrOutsideSendWindow instead.
+func (s *Service) SetSendWindows(windows *SendWindows) {
+	s.sendWindows = windows
+}
+
+// applySendWindow schedules a notification arriving outside its category's send
+// window for when the window next opens
+func (s *Service) applySendWindow(notification *model.Notification) error {
+	if s.sendWindows == nil || notification.Priority == model.PriorityHigh {
+		return nil
+	}
+	window, ok := s.sendWindows.windows[notification.Category]
+	if !ok {
+		return nil
+	}
+
+	now := s.sendWindows.now()
+	opensAt := window.Next(now)
+	if !opensAt.After(now) {
+		return nil
+	}
+	if s.outbox == nil {
+		return model.ErrOutsideSendWindow{Category: notification.Category, OpensAt: opensAt}
+	}
+
+	notification.ScheduledAt = &opensAt
+	logging.WithCorrelationID(s.logger, notification.CorrelationID).Info("deferred notification to its send window",
+		zap.String("id", notification.ID.String()),
+		zap.String("category", notification.Category),
+		zap.Time("scheduled_at", opensAt),
+	)
+	return nil
+}
@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// enqueuingOutbox records the notifications queued for delivery
+type enqueuingOutbox struct {
+	services.NotificationOutbox
+	enqueued []*model.Notification
+}
+
+func (o *enqueuingOutbox) SaveAndEnqueue(ctx context.Context, notification *model.Notification) error {
+	o.enqueued = append(o.enqueued, notification)
+	return nil
+}
+
+func TestService_SendWindows(t *testing.T) {
+	window, err := model.ParseSendWindow("mon-fri 09:00-17:00 America/New_York")
+	require.NoError(t, err)
+	newYork := window.Location
+
+	newService := func(now time.Time) (*Service, *enqueuingOutbox) {
+		outbox := &enqueuingOutbox{}
+		service := NewService(&fakeNotificationRepository{notifications: make(map[string]*model.Notification)}, &recordingEmailProvider{}, nil, nil, nil, nil, outbox, nil, zap.NewNop())
+		windows := NewSendWindows(map[string]model.SendWindow{"marketing": window})
+		windows.now = func() time.Time { return now }
+		service.SetSendWindows(windows)
+		return service, outbox
+	}
+	newEmail := func(category string, priority model.Priority) *model.Notification {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, nil)
+		notification.Category = category
+		notification.Priority = priority
+		return notification
+	}
+
+	saturday := time.Date(2025, 1, 18, 12, 0, 0, 0, newYork)
+
+	t.Run("weekend marketing waits for Monday", func(t *testing.T) {
+		service, outbox := newService(saturday)
+		notification := newEmail("marketing", model.PriorityMedium)
+
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		require.NotNil(t, notification.ScheduledAt)
+		assert.True(t, time.Date(2025, 1, 20, 9, 0, 0, 0, newYork).Equal(*notification.ScheduledAt))
+		assert.Equal(t, []*model.Notification{notification}, outbox.enqueued)
+	})
+
+	t.Run("in the window in its time zone", func(t *testing.T) {
+		// 15:00 UTC is 10:00 in New York
+		service, outbox := newService(time.Date(2025, 1, 15, 15, 0, 0, 0, time.UTC))
+		notification := newEmail("marketing", model.PriorityMedium)
+
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		assert.Nil(t, notification.ScheduledAt)
+		assert.Len(t, outbox.enqueued, 1)
+	})
+
+	t.Run("out of the window in its time zone", func(t *testing.T) {
+		// 23:00 UTC on Friday is 18:00 in New York, after the window closed
+		service, _ := newService(time.Date(2025, 1, 17, 23, 0, 0, 0, time.UTC))
+		notification := newEmail("marketing", model.PriorityMedium)
+
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		require.NotNil(t, notification.ScheduledAt)
+		assert.True(t, time.Date(2025, 1, 20, 9, 0, 0, 0, newYork).Equal(*notification.ScheduledAt))
+	})
+
+	t.Run("high priority bypasses the window", func(t *testing.T) {
+		service, _ := newService(saturday)
+		notification := newEmail("marketing", model.PriorityHigh)
+
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		assert.Nil(t, notification.ScheduledAt)
+	})
+
+	t.Run("categories without a window go out any time", func(t *testing.T) {
+		service, _ := newService(saturday)
+		notification := newEmail(model.CategorySecurity, model.PriorityMedium)
+
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		assert.Nil(t, notification.ScheduledAt)
+	})
+
+	t.Run("rejected without an outbox to wait in", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, nil, nil, nil, zap.NewNop())
+		windows := NewSendWindows(map[string]model.SendWindow{"marketing": window})
+		windows.now = func() time.Time { return saturday }
+		service.SetSendWindows(windows)
+
+		err := service.SendNotification(context.Background(), newEmail("marketing", model.PriorityMedium))
+		assert.ErrorIs(t, err, model.ErrConflict)
+		assert.Empty(t, repo.notifications)
+	})
+}
@@ -3,47 +3,217 @@ package notification
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
 	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
 	"go.uber.org/zap"
 )
 
 // Service implements the NotificationService interface
 type Service struct {
-	repo           services.NotificationRepository
-	emailProvider  services.EmailProvider
-	smsProvider    services.SMSProvider
-	pushProvider   services.PushProvider
-	templateEngine services.TemplateEngine
-	logger         *zap.Logger
+	repo                  repository.NotificationRepository
+	emailProvider         services.EmailProvider
+	smsProvider           services.SMSProvider
+	pushProvider          services.PushProvider
+	whatsAppProvider      services.WhatsAppProvider
+	templateEngine        services.TemplateEngine
+	variants              services.TemplateVariantSelector
+	outbox                services.NotificationOutbox
+	suppressions          repository.SuppressionRepository
+	metadataFinder        repository.NotificationMetadataFinder
+	categoryFinder        repository.NotificationCategoryFinder
+	statsCounter          repository.NotificationStatsCounter
+	scanner               repository.NotificationScanner
+	purger                repository.NotificationPurger
+	eraser                repository.NotificationEraser
+	threadFinder          repository.NotificationThreadFinder
+	broadcastProgress     repository.BroadcastProgressRepository
+	segmentResolver       services.RecipientSegmentResolver
+	broadcastPageSize     int
+	providerHealthTimeout time.Duration
+	messageIDDomain       string
+	statsCache            *statsCache
+	rateLimiters          map[model.NotificationType]*RateLimiter
+	sendTimeouts          map[model.NotificationType]time.Duration
+	locales               model.Locales
+	renderTimeout         time.Duration
+	templateFallbacks     map[model.TemplateType]TemplateFallback
+	defaultPriorities     map[model.NotificationType]model.Priority
+	disallowedPriorities  map[model.NotificationType][]model.Priority
+	logger                *zap.Logger
+	dryRun                bool
+	contentLimits         ContentLimits
+	maxSMSSegments        int
+	recipients            model.RecipientNormalizer
+	sandbox               *model.Sandbox
+	sendLimit             *SendLimit
+	sendWindows           *SendWindows
+	subjectPrefix         string
 }
 
-// NewService creates a new notification service
+// NewService creates a new notification service. When outbox is nil, notifications
+// are sent inline by SendNotification; otherwise they are queued for the OutboxDispatcher.
+// When suppressions is nil, no recipient is ever suppressed.
 func NewService(
-	repo services.NotificationRepository,
+	repo repository.NotificationRepository,
 	emailProvider services.EmailProvider,
 	smsProvider services.SMSProvider,
 	pushProvider services.PushProvider,
+	whatsAppProvider services.WhatsAppProvider,
 	templateEngine services.TemplateEngine,
+	outbox services.NotificationOutbox,
+	suppressions repository.SuppressionRepository,
 	logger *zap.Logger,
 ) *Service {
 	return &Service{
-		repo:           repo,
-		emailProvider:  emailProvider,
-		smsProvider:    smsProvider,
-		pushProvider:   pushProvider,
-		templateEngine: templateEngine,
-		logger:         logger,
+		repo:             repo,
+		emailProvider:    emailProvider,
+		smsProvider:      smsProvider,
+		pushProvider:     pushProvider,
+		whatsAppProvider: whatsAppProvider,
+		templateEngine:   templateEngine,
+		outbox:           outbox,
+		suppressions:     suppressions,
+		logger:           logger,
+		contentLimits:    DefaultContentLimits(),
+		maxSMSSegments:   DefaultMaxSMSSegments,
 	}
 }
 
+// SetDryRun makes every notification a dry run: it is validated, rendered and
+// recorded as model.StatusDryRun, but never handed to a provider
+func (s *Service) SetDryRun(enabled bool) {
+	s.dryRun = enabled
+}
+
+// SetContentLimits replaces the per-channel content limits; channels without an
+// entry are not limited
+func (s *Service) SetContentLimits(limits ContentLimits) {
+	s.contentLimits = limits
+}
+
+// SetMaxSMSSegments sets how many segments an SMS may be split into; 0 disables the limit
+func (s *Service) SetMaxSMSSegments(maxSegments int) {
+	s.maxSMSSegments = maxSegments
+}
+
+// SetRecipientNormalizer replaces how recipients are normalized before
+// notifications are saved and looked up
+func (s *Service) SetRecipientNormalizer(normalizer model.RecipientNormalizer) {
+	s.recipients = normalizer
+}
+
+// SetSendLimit throttles every notification once limit is tripped; nil removes the limit
+func (s *Service) SetSendLimit(limit *SendLimit) {
+	s.sendLimit = limit
+}
+
+// SetSandbox records that the providers are wrapped in sandbox mode, so each
+// notification keeps its original recipient in its metadata; nil turns it off.
+// The redirect itself happens in the sandbox provider wrappers.
+func (s *Service) SetSandbox(sandbox *model.Sandbox) {
+	s.sandbox = sandbox
+}
+
+// SetEmailSubjectPrefix puts prefix, such as "[STAGING]", in front of every
+// email subject as it is sent, so test emails can't be mistaken for real ones.
+// The stored subject is left as rendered; "" turns the prefix off.
+func (s *Service) SetEmailSubjectPrefix(prefix string) {
+	s.subjectPrefix = prefix
+}
+
+// SetMetadataFinder enables querying notifications by metadata through finder,
+// typically the notification repository when its store supports it
+func (s *Service) SetMetadataFinder(finder repository.NotificationMetadataFinder) {
+	s.metadataFinder = finder
+}
+
+// SetCategoryFinder enables querying notifications by category through finder,
+// typically the notification repository when its store supports it
+func (s *Service) SetCategoryFinder(finder repository.NotificationCategoryFinder) {
+	s.categoryFinder = finder
+}
+
+// SetNotificationScanner enables exporting notifications through scanner,
+// typically the notification repository when its store supports it
+func (s *Service) SetNotificationScanner(scanner repository.NotificationScanner) {
+	s.scanner = scanner
+}
+
+// SetNotificationPurger enables purging old notifications through purger,
+// typically the notification repository when its store supports it
+func (s *Service) SetNotificationPurger(purger repository.NotificationPurger) {
+	s.purger = purger
+}
+
+// SetNotificationEraser enables erasing recipients' data through eraser,
+// typically the notification repository when its store supports it
+func (s *Service) SetNotificationEraser(eraser repository.NotificationEraser) {
+	s.eraser = eraser
+}
+
+// SetTemplateVariantSelector A/B tests event notifications' templates: for the
+// types selector has variants enabled for, the variant it picks for the recipient
+// is rendered instead of the default template. nil disables variants.
+func (s *Service) SetTemplateVariantSelector(selector services.TemplateVariantSelector) {
+	s.variants = selector
+}
+
+// SetStatsCounter enables notification stats through counter, typically the
+// notification repository when its store supports it. Stats are reused for ttl
+// before being counted again; 0 counts them on every request.
+func (s *Service) SetStatsCounter(counter repository.NotificationStatsCounter, ttl time.Duration) {
+	s.statsCounter = counter
+	s.statsCache = newStatsCache(ttl)
+}
+
+// SetRateLimiter paces sends on a channel with limiter, which may be shared with
+// other services calling the same provider account; nil removes the limit
+func (s *Service) SetRateLimiter(notificationType model.NotificationType, limiter *RateLimiter) {
+	if s.rateLimiters == nil {
+		s.rateLimiters = make(map[model.NotificationType]*RateLimiter)
+	}
+	s.rateLimiters[notificationType] = limiter
+}
+
+// SetSendTimeout bounds how long a channel's provider call may take, so a hung
+// upstream fails the notification instead of holding up its worker; 0 removes
+// the bound. Time spent waiting for the rate limit doesn't count.
+func (s *Service) SetSendTimeout(notificationType model.NotificationType, timeout time.Duration) {
+	if s.sendTimeouts == nil {
+		s.sendTimeouts = make(map[model.NotificationType]time.Duration)
+	}
+	s.sendTimeouts[notificationType] = timeout
+}
+
+// DefaultRenderTimeout is how long rendering an event notification's template may take by default
+const DefaultRenderTimeout = 5 * time.Second
+
+// SetRenderTimeout bounds how long rendering an event notification's template
+// may take, lookups of the template and its includes included, so a
+// pathological template fails its event with an error wrapping
+// context.DeadlineExceeded instead of holding up its worker; 0 removes the bound
+func (s *Service) SetRenderTimeout(timeout time.Duration) {
+	s.renderTimeout = timeout
+}
+
+// isDryRun reports whether the service or the request asked for a dry run
+func (s *Service) isDryRun(ctx context.Context) bool {
+	return s.dryRun || model.IsDryRun(ctx)
+}
+
 // HandleUserEvent processes user-related events and sends appropriate notifications
 func (s *Service) HandleUserEvent(ctx context.Context, eventType string, payload []byte) error {
-	s.logger.Info("handling user event", zap.String("eventType", eventType))
+	logging.WithContext(ctx, s.logger).Info("handling user event", zap.String("eventType", eventType))
 
 	switch eventType {
 	case "user.registered":
@@ -54,11 +224,22 @@ func (s *Service) HandleUserEvent(ctx context.Context, eventType string, payload
 		return s.handlePasswordReset(ctx, payload)
 	case "user.password.changed":
 		return s.handlePasswordChanged(ctx, payload)
+	case "announcement.published":
+		return s.handleAnnouncementPublished(ctx, payload)
 	default:
 		return fmt.Errorf("unknown event type: %s", eventType)
 	}
 }
 
+// decodeEvent unmarshals an event's payload, reporting one that can't be
+// decoded as malformed
+func decodeEvent(eventType string, payload []byte, event interface{}) error {
+	if err := json.Unmarshal(payload, event); err != nil {
+		return model.ErrMalformedEvent{EventType: eventType, Err: err}
+	}
+	return nil
+}
+
 func (s *Service) handleUserRegistered(ctx context.Context, payload []byte) error {
 	var event struct {
 		UserID    string `json:"userId"`
@@ -66,262 +247,877 @@ func (s *Service) handleUserRegistered(ctx context.Context, payload []byte) erro
 		Username  string `json:"username"`
 		FirstName string `json:"firstName"`
 		LastName  string `json:"lastName"`
+		Locale    string `json:"locale"`
 	}
 
-	if err := json.Unmarshal(payload, &event); err != nil {
-		return fmt.Errorf("error unmarshaling user registered event: %w", err)
+	if err := decodeEvent("user.registered", payload, &event); err != nil {
+		return err
 	}
 
-	// Process welcome email template
-	data := map[string]interface{}{
+	_, err := s.SendNotificationWithTemplate(ctx, event.Email, "welcome.html", map[string]interface{}{
 		"FirstName": event.FirstName,
 		"Username":  event.Username,
 		"Email":     event.Email,
-		"Year":      time.Now().Year(),
-	}
+	}, TemplateSendOptions{
+		TemplateType: model.WelcomeEmail,
+		Locale:       event.Locale,
+		Subject:      "Welcome to Our Service",
+		Category:     model.CategoryWelcome,
+		TemplateData: model.TemplateData{"eventType": "user.registered", "userId": event.UserID},
+	})
+	return err
+}
 
-	content, err := s.templateEngine.ProcessTemplate(ctx, "welcome.html", data)
-	if err != nil {
-		return fmt.Errorf("error processing welcome template: %w", err)
-	}
-
-	notification := model.NewNotification(
-		event.Email,
-		model.EmailNotification,
-		model.EmailTemplate,
-		uuid.Nil,
-		map[string]string{
-			"subject":   "Welcome to Our Service",
-			"content":   content,
-			"eventType": "user.registered",
-			"userId":    event.UserID,
-		},
-	)
+func (s *Service) handleUserVerified(ctx context.Context, payload []byte) error {
+	var event struct {
+		UserID string `json:"userId"`
+		Email  string `json:"email"`
+		Locale string `json:"locale"`
+	}
 
-	if err := s.repo.Save(ctx, notification); err != nil {
-		return fmt.Errorf("error saving notification: %w", err)
+	if err := decodeEvent("user.verified", payload, &event); err != nil {
+		return err
 	}
 
-	if err := s.emailProvider.SendEmail(ctx, notification.Recipient, notification.Subject, notification.Content); err != nil {
-		notification.UpdateStatus(model.StatusFailed, err.Error())
-		if err := s.repo.Update(ctx, notification); err != nil {
-			s.logger.Error("error updating notification status", zap.Error(err))
-		}
-		return fmt.Errorf("error sending welcome email: %w", err)
+	_, err := s.SendNotificationWithTemplate(ctx, event.Email, "email_verified.html", map[string]interface{}{
+		"Email": event.Email,
+	}, TemplateSendOptions{
+		TemplateType: model.AccountActivation,
+		Locale:       event.Locale,
+		Subject:      "Email Verification Successful",
+		Category:     model.CategorySecurity,
+		TemplateData: model.TemplateData{"eventType": "user.verified", "userId": event.UserID},
+	})
+	return err
+}
+
+func (s *Service) handlePasswordReset(ctx context.Context, payload []byte) error {
+	var event struct {
+		UserID    string `json:"userId"`
+		Email     string `json:"email"`
+		ResetLink string `json:"resetLink"`
+		Locale    string `json:"locale"`
 	}
 
-	notification.UpdateStatus(model.StatusSent, "")
-	if err := s.repo.Update(ctx, notification); err != nil {
-		s.logger.Error("error updating notification status", zap.Error(err))
+	if err := decodeEvent("user.password.reset", payload, &event); err != nil {
+		return err
 	}
 
-	return nil
+	_, err := s.SendNotificationWithTemplate(ctx, event.Email, "password_reset.html", map[string]interface{}{
+		"Email":     event.Email,
+		"ResetLink": event.ResetLink,
+	}, TemplateSendOptions{
+		TemplateType: model.PasswordReset,
+		Locale:       event.Locale,
+		Subject:      "Password Reset Request",
+		Category:     model.CategorySecurity,
+		TemplateData: model.TemplateData{"eventType": "user.password.reset", "userId": event.UserID},
+	})
+	return err
 }
 
-func (s *Service) handleUserVerified(ctx context.Context, payload []byte) error {
+func (s *Service) handlePasswordChanged(ctx context.Context, payload []byte) error {
 	var event struct {
 		UserID string `json:"userId"`
 		Email  string `json:"email"`
+		Locale string `json:"locale"`
 	}
 
-	if err := json.Unmarshal(payload, &event); err != nil {
-		return fmt.Errorf("error unmarshaling user verified event: %w", err)
+	if err := decodeEvent("user.password.changed", payload, &event); err != nil {
+		return err
 	}
 
-	// Process verification success template
-	data := map[string]interface{}{
+	_, err := s.SendNotificationWithTemplate(ctx, event.Email, "password_changed.html", map[string]interface{}{
 		"Email": event.Email,
-		"Year":  time.Now().Year(),
-	}
+	}, TemplateSendOptions{
+		TemplateType: model.PasswordChanged,
+		Locale:       event.Locale,
+		Subject:      "Password Changed Successfully",
+		Category:     model.CategorySecurity,
+		TemplateData: model.TemplateData{"eventType": "user.password.changed", "userId": event.UserID},
+	})
+	return err
+}
 
-	content, err := s.templateEngine.ProcessTemplate(ctx, "email_verified.html", data)
-	if err != nil {
-		return fmt.Errorf("error processing verification template: %w", err)
-	}
-
-	notification := model.NewNotification(
-		event.Email,
-		model.EmailNotification,
-		model.EmailTemplate,
-		uuid.Nil,
-		map[string]string{
-			"subject":   "Email Verification Successful",
-			"content":   content,
-			"eventType": "user.verified",
-			"userId":    event.UserID,
-		},
-	)
+// processTemplate renders an event notification's content in locale from the
+// variant of templateType picked for the recipient, if variants are enabled for
+// the type, and from the template named defaultName otherwise: its translation
+// into locale (see localizedTemplateName) if there is one, else defaultName
+// itself. The variant is nil when the default template was rendered. A missing
+// default template is replaced by the type's plain fallback body if the type is
+// set to fall back. Processing is bounded by the render timeout.
+func (s *Service) processTemplate(ctx context.Context, templateType model.TemplateType, defaultName, recipient, locale string, data map[string]interface{}) (string, *model.Template, error) {
+	if s.renderTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.renderTimeout)
+		defer cancel()
+	}
+	data["Locale"] = locale
 
-	if err := s.repo.Save(ctx, notification); err != nil {
-		return fmt.Errorf("error saving notification: %w", err)
+	if s.variants != nil {
+		variant, err := s.variants.SelectVariant(ctx, templateType, recipient)
+		if err != nil {
+			return "", nil, err
+		}
+		if variant != nil {
+			content, err := s.templateEngine.ProcessTemplate(ctx, variant.Name, data)
+			return content, variant, err
+		}
 	}
 
-	if err := s.emailProvider.SendEmail(ctx, notification.Recipient, notification.Subject, notification.Content); err != nil {
-		notification.UpdateStatus(model.StatusFailed, err.Error())
-		if err := s.repo.Update(ctx, notification); err != nil {
-			s.logger.Error("error updating notification status", zap.Error(err))
+	if locale != s.defaultLocale() {
+		content, err := s.templateEngine.ProcessTemplate(ctx, localizedTemplateName(defaultName, locale), data)
+		if !errors.Is(err, model.ErrNotFound) {
+			return content, nil, err
 		}
-		return fmt.Errorf("error sending verification email: %w", err)
 	}
 
-	notification.UpdateStatus(model.StatusSent, "")
-	if err := s.repo.Update(ctx, notification); err != nil {
-		s.logger.Error("error updating notification status", zap.Error(err))
+	content, err := s.templateEngine.ProcessTemplate(ctx, defaultName, data)
+	if err != nil {
+		content, err = s.renderFallback(ctx, templateType, defaultName, data, err)
 	}
+	return content, nil, err
+}
 
-	return nil
+// applyTemplateVariant gives the notification the variant's subject and records
+// which variant it was rendered from; a nil variant changes nothing
+func applyTemplateVariant(notification *model.Notification, variant *model.Template) {
+	if variant == nil {
+		return
+	}
+	notification.TemplateID = variant.ID
+	notification.Subject = variant.Subject
+	notification.TemplateData["subject"] = variant.Subject
+	notification.Metadata[model.MetadataTemplateVariantID] = variant.ID.String()
 }
 
-func (s *Service) handlePasswordReset(ctx context.Context, payload []byte) error {
-	var event struct {
-		UserID    string `json:"userId"`
-		Email     string `json:"email"`
-		ResetLink string `json:"resetLink"`
+// Other interface methods implementation...
+func (s *Service) SendNotification(ctx context.Context, notification *model.Notification) error {
+	if notification.CorrelationID == "" {
+		notification.CorrelationID = model.CorrelationIDFromContext(ctx)
 	}
+	notification.Recipient = s.recipients.Normalize(notification.Recipient)
+	return s.validateAndDeliver(ctx, notification)
+}
 
-	if err := json.Unmarshal(payload, &event); err != nil {
-		return fmt.Errorf("error unmarshaling password reset event: %w", err)
+// validateAndDeliver takes a new notification through every check a send
+// makes, its priority, validation, content limit, email options, send window
+// and send limit, then delivers it
+func (s *Service) validateAndDeliver(ctx context.Context, notification *model.Notification) error {
+	if err := s.applyPriority(notification); err != nil {
+		return fmt.Errorf("invalid notification: %w", err)
+	}
+	if err := notification.Validate(); err != nil {
+		return fmt.Errorf("invalid notification: %w", err)
 	}
 
-	data := map[string]interface{}{
-		"Email":     event.Email,
-		"ResetLink": event.ResetLink,
-		"Year":      time.Now().Year(),
+	if err := s.enforceContentLimit(notification); err != nil {
+		return err
 	}
 
-	content, err := s.templateEngine.ProcessTemplate(ctx, "password_reset.html", data)
-	if err != nil {
-		return fmt.Errorf("error processing password reset template: %w", err)
-	}
-
-	notification := model.NewNotification(
-		event.Email,
-		model.EmailNotification,
-		model.EmailTemplate,
-		uuid.Nil,
-		map[string]string{
-			"subject":   "Password Reset Request",
-			"content":   content,
-			"eventType": "user.password.reset",
-			"userId":    event.UserID,
-		},
-	)
+	if err := s.checkEmailOptions(notification); err != nil {
+		return err
+	}
+
+	if err := s.applySendWindow(notification); err != nil {
+		return err
+	}
+
+	if err := s.checkSendLimit(ctx, notification); err != nil {
+		return err
+	}
+
+	return s.deliver(ctx, notification)
+}
+
+// deliver saves a validated notification and hands it over for delivery: it is
+// queued in the outbox when there is one, so a crash between saving and sending
+// can't lose it, and sent inline otherwise
+func (s *Service) deliver(ctx context.Context, notification *model.Notification) error {
+	// Dry runs are recorded inline; there is nothing to queue for delivery
+	if s.outbox != nil && !s.isDryRun(ctx) {
+		if err := s.outbox.SaveAndEnqueue(ctx, notification); err != nil {
+			return fmt.Errorf("error saving notification: %w", err)
+		}
+		return nil
+	}
 
 	if err := s.repo.Save(ctx, notification); err != nil {
 		return fmt.Errorf("error saving notification: %w", err)
 	}
 
-	if err := s.emailProvider.SendEmail(ctx, notification.Recipient, notification.Subject, notification.Content); err != nil {
-		notification.UpdateStatus(model.StatusFailed, err.Error())
-		if err := s.repo.Update(ctx, notification); err != nil {
-			s.logger.Error("error updating notification status", zap.Error(err))
-		}
-		return fmt.Errorf("error sending password reset email: %w", err)
+	return s.dispatch(ctx, notification)
+}
+
+// RetryNotification resets a failed notification to pending and dispatches it again
+func (s *Service) RetryNotification(ctx context.Context, id string) (*model.Notification, error) {
+	notification, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding notification: %w", err)
+	}
+	if notification == nil {
+		return nil, model.ErrNotificationNotFound{ID: id}
+	}
+
+	if err := notification.ResetForRetry(); err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceSendLimit(ctx, notification, s.repo.Update); err != nil {
+		return notification, err
 	}
 
-	notification.UpdateStatus(model.StatusSent, "")
 	if err := s.repo.Update(ctx, notification); err != nil {
-		s.logger.Error("error updating notification status", zap.Error(err))
+		return nil, fmt.Errorf("error updating notification: %w", err)
 	}
 
-	return nil
+	if err := s.dispatch(ctx, notification); err != nil {
+		return notification, err
+	}
+
+	return notification, nil
 }
 
-func (s *Service) handlePasswordChanged(ctx context.Context, payload []byte) error {
-	var event struct {
-		UserID string `json:"userId"`
-		Email  string `json:"email"`
+// SendMultiChannel sends one logical notification on each of targets at once,
+// as a child notification per channel sharing a new group ID. A channel failing
+// doesn't hold up or stop the others; each channel's outcome is in the group's results.
+// Targets repeating an earlier target's channel and normalized recipient are
+// sent once and counted in the group's Duplicates, unless ctx was marked with
+// model.ContextAllowingDuplicates.
+func (s *Service) SendMultiChannel(ctx context.Context, notification *model.Notification, targets []model.ChannelTarget) (*model.NotificationGroup, error) {
+	if len(targets) == 0 {
+		return nil, model.ErrInvalidNotification{Message: "at least one channel is required"}
+	}
+	if len(targets) > model.MaxGroupChannels {
+		return nil, model.ErrInvalidNotification{Message: fmt.Sprintf("at most %d channels may be sent to at once", model.MaxGroupChannels)}
 	}
 
-	if err := json.Unmarshal(payload, &event); err != nil {
-		return fmt.Errorf("error unmarshaling password changed event: %w", err)
+	group := &model.NotificationGroup{ID: model.NewGroupID()}
+	if !model.DuplicatesAllowed(ctx) {
+		targets, group.Duplicates = s.uniqueTargets(targets)
 	}
+	group.Results = make([]model.ChannelResult, len(targets))
 
-	data := map[string]interface{}{
-		"Email": event.Email,
-		"Year":  time.Now().Year(),
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		result := &group.Results[i]
+		result.Target = target
+		result.Notification = notification.ForChannel(target.Type, target.Recipient, group.ID)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					result.Err = fmt.Errorf("panic: %v", r)
+				}
+			}()
+
+			result.Err = s.SendNotification(ctx, result.Notification)
+			// Notifications rejected as invalid were never stored
+			if errors.Is(result.Err, model.ErrValidation) {
+				result.Notification = nil
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed := group.Failed(); failed > 0 {
+		logging.WithContext(ctx, s.logger).Warn("multi-channel notification failed on some channels",
+			zap.String("groupId", group.ID),
+			zap.Int("channels", len(targets)),
+			zap.Int("failed", failed),
+		)
+	}
+	return group, nil
+}
+
+// uniqueTargets returns targets without those repeating an earlier target's
+// channel and normalized recipient, and how many were left out
+func (s *Service) uniqueTargets(targets []model.ChannelTarget) ([]model.ChannelTarget, int) {
+	seen := make(map[model.ChannelTarget]bool, len(targets))
+	unique := make([]model.ChannelTarget, 0, len(targets))
+	for _, target := range targets {
+		key := model.ChannelTarget{Type: target.Type, Recipient: s.recipients.Normalize(target.Recipient)}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, target)
+	}
+	return unique, len(targets) - len(unique)
+}
+
+// GetNotificationGroup returns the notifications of a multi-channel send, oldest
+// first, or model.ErrNotificationGroupNotFound if there are none
+func (s *Service) GetNotificationGroup(ctx context.Context, groupID string) ([]*model.Notification, error) {
+	notifications, err := s.repo.FindByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding notification group: %w", err)
 	}
+	if len(notifications) == 0 {
+		return nil, model.ErrNotificationGroupNotFound{ID: groupID}
+	}
+	return notifications, nil
+}
 
-	content, err := s.templateEngine.ProcessTemplate(ctx, "password_changed.html", data)
+// ResendNotification sends a copy of a notification under a new ID, optionally to
+// another recipient. The original is left untouched.
+func (s *Service) ResendNotification(ctx context.Context, id, recipient string) (*model.Notification, error) {
+	original, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("error processing password changed template: %w", err)
-	}
-
-	notification := model.NewNotification(
-		event.Email,
-		model.EmailNotification,
-		model.EmailTemplate,
-		uuid.Nil,
-		map[string]string{
-			"subject":   "Password Changed Successfully",
-			"content":   content,
-			"eventType": "user.password.changed",
-			"userId":    event.UserID,
-		},
-	)
+		return nil, fmt.Errorf("error finding notification: %w", err)
+	}
+	if original == nil {
+		return nil, model.ErrNotificationNotFound{ID: id}
+	}
 
-	if err := s.repo.Save(ctx, notification); err != nil {
-		return fmt.Errorf("error saving notification: %w", err)
+	resend := original.CloneForResend(recipient)
+	if err := s.SendNotification(ctx, resend); err != nil {
+		return resend, err
 	}
 
-	if err := s.emailProvider.SendEmail(ctx, notification.Recipient, notification.Subject, notification.Content); err != nil {
-		notification.UpdateStatus(model.StatusFailed, err.Error())
-		if err := s.repo.Update(ctx, notification); err != nil {
-			s.logger.Error("error updating notification status", zap.Error(err))
-		}
-		return fmt.Errorf("error sending password changed email: %w", err)
+	return resend, nil
+}
+
+// HandleDeliveryEvent records a delivery receipt from a provider webhook on the
+// notification the provider's message ID belongs to
+func (s *Service) HandleDeliveryEvent(ctx context.Context, event model.DeliveryEvent) error {
+	notification, err := s.repo.FindByProviderMessageID(ctx, event.Provider, event.ProviderMessageID)
+	if err != nil {
+		return fmt.Errorf("error finding notification: %w", err)
+	}
+	if notification == nil {
+		return model.ErrNotificationNotFound{ID: event.ProviderMessageID}
 	}
 
-	notification.UpdateStatus(model.StatusSent, "")
-	if err := s.repo.Update(ctx, notification); err != nil {
-		s.logger.Error("error updating notification status", zap.Error(err))
+	previousStatus := notification.Status
+	notification.ApplyDeliveryEvent(event)
+
+	// Webhooks aren't tenant-scoped, so update within the notification's own tenant
+	tenantCtx := model.ContextWithTenant(ctx, notification.TenantID)
+	if err := s.repo.Update(tenantCtx, notification); err != nil {
+		return fmt.Errorf("error updating notification: %w", err)
+	}
+	if notification.Status != previousStatus {
+		recordEndToEndLatency(notification)
+	}
+
+	// Stop emailing addresses that hard bounced or complained
+	reason, suppress := event.SuppressionReason()
+	if suppress && notification.Type == model.EmailNotification && s.suppressions != nil {
+		suppression := model.NewSuppression(notification.Recipient, reason, event.Provider, event.Reason)
+		if err := s.suppressions.Save(tenantCtx, suppression); err != nil {
+			return fmt.Errorf("error suppressing recipient: %w", err)
+		}
+		logging.WithContext(ctx, s.logger).Info("suppressed recipient",
+			zap.String("tenantId", notification.TenantID),
+			zap.String("recipient", notification.Recipient),
+			zap.String("reason", string(reason)),
+		)
 	}
 
 	return nil
 }
 
-// Other interface methods implementation...
-func (s *Service) SendNotification(ctx context.Context, notification *model.Notification) error {
-	if err := s.repo.Save(ctx, notification); err != nil {
-		return fmt.Errorf("error saving notification: %w", err)
+// send hands a notification to its channel's provider, waiting first for the
+// channel's rate limit, and returns the provider's message ID. A call still
+// running at the channel's send timeout fails with model.ErrProviderTimeout, and
+// a channel without a provider fails with model.ErrProviderNotConfigured.
+func (s *Service) send(ctx context.Context, notification *model.Notification) (messageID string, err error) {
+	if notification.Type.IsValid() && !s.hasProvider(notification.Type) {
+		return "", model.ErrProviderNotConfigured{Type: notification.Type}
+	}
+
+	if limiter := s.rateLimiters[notification.Type]; limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	if timeout := s.sendTimeouts[notification.Type]; timeout > 0 {
+		callerCtx := ctx
+		sendCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		defer func() {
+			// Only the send timeout is reported as one, not the caller's own deadline
+			if err != nil && sendCtx.Err() == context.DeadlineExceeded && callerCtx.Err() == nil {
+				metrics.RecordProviderTimeout(string(notification.Type))
+				err = model.ErrProviderTimeout{Timeout: timeout}
+			}
+		}()
+		ctx = sendCtx
 	}
 
-	var err error
 	switch notification.Type {
 	case model.EmailNotification:
-		err = s.emailProvider.SendEmail(ctx, notification.Recipient, notification.Subject, notification.Content)
+		emailCtx := model.ContextWithEmailCategory(ctx, notification.Category)
+		subject := s.emailSubject(notification.Subject)
+		options := notification.EmailOptions()
+		if notification.ThreadID != "" {
+			options.Threading = s.emailThreading(ctx, notification)
+		}
+		if !options.IsZero() {
+			provider, ok := s.emailProvider.(services.EmailOptionsProvider)
+			if !ok {
+				return "", model.ErrEmailOptionsUnsupported{}
+			}
+			messageID, err = provider.SendEmailWithOptions(emailCtx, notification.Recipient, subject, notification.Content, options)
+		} else {
+			messageID, err = s.emailProvider.SendEmail(emailCtx, notification.Recipient, subject, notification.Content)
+		}
 	case model.SMSNotification:
-		err = s.smsProvider.SendSMS(ctx, notification.Recipient, notification.Content)
+		if messageID, err = s.smsProvider.SendSMS(ctx, notification.Recipient, notification.Content); err == nil {
+			segmentation := s.smsProvider.Segment(notification.Content)
+			metrics.RecordSMSSegments(string(segmentation.Encoding), segmentation.Segments)
+		}
 	case model.PushNotification:
-		err = s.pushProvider.SendPush(ctx, notification.Recipient, notification.Subject, notification.Content)
+		messageID, err = s.pushProvider.SendPush(ctx, notification.Recipient, notification.Subject, notification.Content)
+	case model.WhatsAppNotification:
+		var message model.WhatsAppTemplateMessage
+		if message, err = notification.WhatsAppTemplate(); err == nil {
+			messageID, err = s.whatsAppProvider.SendWhatsApp(ctx, notification.Recipient, message)
+		}
 	default:
-		err = fmt.Errorf("unsupported notification type: %s", notification.Type)
+		err = model.ErrInvalidNotification{Message: fmt.Sprintf("unsupported notification type: %s", notification.Type)}
+	}
+	return messageID, err
+}
+
+// checkEmailOptions rejects an email that asks for a content type, CC
+// addresses, inline images or a thread the email provider can't send with,
+// before it is stored
+func (s *Service) checkEmailOptions(notification *model.Notification) error {
+	if notification.Type != model.EmailNotification || (notification.EmailOptions().IsZero() && notification.ThreadID == "") || s.emailProvider == nil {
+		return nil
+	}
+	if _, ok := s.emailProvider.(services.EmailOptionsProvider); !ok {
+		return model.ErrEmailOptionsUnsupported{}
+	}
+	return nil
+}
+
+// hasProvider reports whether the service was given a provider for notifications of type t
+func (s *Service) hasProvider(t model.NotificationType) bool {
+	switch t {
+	case model.EmailNotification:
+		return s.emailProvider != nil
+	case model.SMSNotification:
+		return s.smsProvider != nil
+	case model.PushNotification:
+		return s.pushProvider != nil
+	case model.WhatsAppNotification:
+		return s.whatsAppProvider != nil
+	}
+	return false
+}
+
+// providerName returns the name of the provider that sends notifications of
+// type t, or "" if it doesn't report one
+func (s *Service) providerName(t model.NotificationType) string {
+	if named, ok := s.provider(t).(services.NamedProvider); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// provider returns the provider that sends notifications of type t
+func (s *Service) provider(t model.NotificationType) interface{} {
+	switch t {
+	case model.EmailNotification:
+		return s.emailProvider
+	case model.SMSNotification:
+		return s.smsProvider
+	case model.PushNotification:
+		return s.pushProvider
+	case model.WhatsAppNotification:
+		return s.whatsAppProvider
+	}
+	return nil
+}
+
+// emailSubject puts the subject prefix in front of subject, unless the
+// template already starts the subject with it
+func (s *Service) emailSubject(subject string) string {
+	prefix := strings.TrimSpace(s.subjectPrefix)
+	if prefix == "" || strings.HasPrefix(subject, prefix) {
+		return subject
+	}
+	return prefix + " " + subject
+}
+
+// checkSendLimit counts a new notification about to be sent against the send limit.
+// Once the limit is tripped, the notification is saved as throttled instead and
+// model.ErrSendLimitExceeded returned. Dry runs send nothing and aren't counted.
+func (s *Service) checkSendLimit(ctx context.Context, notification *model.Notification) error {
+	return s.enforceSendLimit(ctx, notification, s.repo.Save)
+}
+
+// enforceSendLimit is checkSendLimit, storing a throttled notification with
+// store: Save for new notifications, Update for retried ones
+func (s *Service) enforceSendLimit(ctx context.Context, notification *model.Notification, store func(context.Context, *model.Notification) error) error {
+	if s.sendLimit == nil || s.isDryRun(ctx) {
+		return nil
+	}
+
+	allowed, tripped := s.sendLimit.Allow()
+	if tripped {
+		logging.WithContext(ctx, s.logger).Error("send limit exceeded, throttling every notification until the minute ends or the limit is cleared",
+			zap.Int("perMinute", s.sendLimit.perMinute),
+		)
+	}
+	if allowed {
+		return nil
+	}
+
+	limitErr := model.ErrSendLimitExceeded{PerMinute: s.sendLimit.perMinute}
+	if err := notification.TransitionTo(model.StatusThrottled, limitErr.Error()); err != nil {
+		return err
+	}
+	if err := store(ctx, notification); err != nil {
+		return fmt.Errorf("error saving throttled notification: %w", err)
+	}
+	recordEndToEndLatency(notification)
+	return limitErr
+}
+
+// GetSendLimitState returns the state of the send limit
+func (s *Service) GetSendLimitState() (*model.SendLimitState, error) {
+	if s.sendLimit == nil {
+		return nil, model.ErrSendLimitNotConfigured{}
+	}
+	state := s.sendLimit.State()
+	return &state, nil
+}
+
+// ClearSendLimit lets notifications through again after the send limit tripped
+func (s *Service) ClearSendLimit() (*model.SendLimitState, error) {
+	if s.sendLimit == nil {
+		return nil, model.ErrSendLimitNotConfigured{}
+	}
+	s.sendLimit.Clear()
+	s.logger.Warn("send limit cleared by an operator")
+	state := s.sendLimit.State()
+	return &state, nil
+}
+
+// recordEndToEndLatency records how long the notification took to reach its
+// status, if the status is terminal
+func recordEndToEndLatency(notification *model.Notification) {
+	if notification.Status.IsTerminal() {
+		latency := notification.UpdatedAt.Sub(notification.CreatedAt)
+		metrics.RecordEndToEndLatency(string(notification.Type), string(notification.Status), latency.Seconds())
+	}
+}
+
+// findSuppression returns the suppression list entry that keeps an email from
+// being sent to its recipient, or nil if it may be sent
+func (s *Service) findSuppression(ctx context.Context, notification *model.Notification) (*model.Suppression, error) {
+	if s.suppressions == nil || notification.Type != model.EmailNotification {
+		return nil, nil
 	}
 
+	suppression, err := s.suppressions.FindByRecipient(ctx, notification.Recipient)
 	if err != nil {
-		notification.UpdateStatus(model.StatusFailed, err.Error())
-		if updateErr := s.repo.Update(ctx, notification); updateErr != nil {
-			s.logger.Error("error updating notification status", zap.Error(updateErr))
+		return nil, fmt.Errorf("error checking suppression list: %w", err)
+	}
+	return suppression, nil
+}
+
+// RetryFailedSince retries every failed notification created at or after since.
+// It returns how many retries were dispatched successfully and how many failed again.
+func (s *Service) RetryFailedSince(ctx context.Context, since time.Time) (retried, failed int, err error) {
+	const pageSize = 100
+
+	// Collect the window first: retrying moves notifications out of the failed
+	// index, which would otherwise shift the pages underneath us.
+	var ids []string
+	for offset := 0; ; offset += pageSize {
+		notifications, err := s.repo.FindByStatus(ctx, model.StatusFailed, pageSize, offset)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error finding failed notifications: %w", err)
+		}
+
+		done := len(notifications) < pageSize
+		for _, notification := range notifications {
+			if notification.CreatedAt.Before(since) {
+				done = true
+				break
+			}
+			ids = append(ids, notification.ID.String())
+		}
+		if done {
+			break
+		}
+	}
+
+	for _, id := range ids {
+		if _, err := s.RetryNotification(ctx, id); err != nil {
+			logging.WithContext(ctx, s.logger).Error("error retrying notification", zap.String("id", id), zap.Error(err))
+			failed++
+			continue
+		}
+		retried++
+	}
+
+	return retried, failed, nil
+}
+
+// dispatch sends a saved notification through its provider and records the outcome
+func (s *Service) dispatch(ctx context.Context, notification *model.Notification) error {
+	suppression, err := s.findSuppression(ctx, notification)
+	if err != nil {
+		return err
+	}
+	if suppression != nil {
+		if err := notification.TransitionTo(model.StatusSuppressed, fmt.Sprintf("recipient is suppressed: %s", suppression.Reason)); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, notification); err != nil {
+			return fmt.Errorf("error recording suppressed notification: %w", err)
+		}
+		metrics.RecordSuppressedNotification(string(suppression.Reason))
+		recordEndToEndLatency(notification)
+		return nil
+	}
+
+	if s.isDryRun(ctx) {
+		if err := notification.TransitionTo(model.StatusDryRun, ""); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, notification); err != nil {
+			return fmt.Errorf("error recording dry run: %w", err)
+		}
+		recordEndToEndLatency(notification)
+		return nil
+	}
+
+	if s.sandbox != nil {
+		if notification.Metadata == nil {
+			notification.Metadata = make(map[string]string)
+		}
+		notification.Metadata[model.MetadataOriginalRecipient] = notification.Recipient
+	}
+
+	messageID, err := s.send(ctx, notification)
+	// Providers report input they reject, such as an invalid recipient, as a
+	// validation error; anything else is a provider failure
+	if err != nil && notification.Type.IsValid() && !errors.Is(err, model.ErrValidation) {
+		err = model.ErrProviderFailure{Type: notification.Type, Err: err}
+	}
+
+	if err != nil {
+		if transitionErr := notification.TransitionTo(model.StatusFailed, err.Error()); transitionErr != nil {
+			logging.WithCorrelationID(s.logger, notification.CorrelationID).Error("error updating notification status", zap.Error(transitionErr))
+		} else if updateErr := s.repo.Update(ctx, notification); updateErr != nil {
+			logging.WithCorrelationID(s.logger, notification.CorrelationID).Error("error updating notification status", zap.Error(updateErr))
+		} else {
+			recordEndToEndLatency(notification)
 		}
 		return fmt.Errorf("error sending notification: %w", err)
 	}
 
-	notification.UpdateStatus(model.StatusSent, "")
+	notification.ProviderMessageID = messageID
+	notification.Provider = s.providerName(notification.Type)
+	if err := notification.TransitionTo(model.StatusSent, ""); err != nil {
+		return err
+	}
 	if err := s.repo.Update(ctx, notification); err != nil {
-		s.logger.Error("error updating notification status", zap.Error(err))
+		logging.WithCorrelationID(s.logger, notification.CorrelationID).Error("error updating notification status", zap.Error(err))
 	}
 
 	return nil
 }
 
+// GetNotification returns a notification, or model.ErrNotificationNotFound if there is none
 func (s *Service) GetNotification(ctx context.Context, id string) (*model.Notification, error) {
-	return s.repo.FindByID(ctx, id)
+	notification, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notification == nil {
+		return nil, model.ErrNotificationNotFound{ID: id}
+	}
+	return notification, nil
+}
+
+func (s *Service) GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error) {
+	return s.repo.FindByIDs(ctx, ids)
 }
 
-func (s *Service) GetNotificationHistory(ctx context.Context, recipient string, limit, offset int) ([]*model.Notification, error) {
-	return s.repo.FindByRecipient(ctx, recipient, limit, offset)
+func (s *Service) GetNotificationHistory(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
+	return s.repo.FindByRecipient(ctx, s.recipients.Normalize(recipient), order, limit, offset)
 }
 
-func (s *Service) GetNotificationsByRecipient(recipient string, limit, offset int) ([]*model.Notification, error) {
-	return s.GetNotificationHistory(context.Background(), recipient, limit, offset)
+func (s *Service) GetNotificationsByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
+	return s.GetNotificationHistory(ctx, recipient, order, limit, offset)
+}
+
+func (s *Service) GetNotificationsByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error) {
+	return s.repo.FindByStatus(ctx, status, limit, offset)
+}
+
+// GetNotificationsByMetadata finds notifications whose metadata contains every
+// key/value pair in filters, newest first
+func (s *Service) GetNotificationsByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error) {
+	if s.metadataFinder == nil {
+		return nil, model.ErrMetadataQueryUnsupported{}
+	}
+	return s.metadataFinder.FindByMetadata(ctx, filters, limit, offset)
+}
+
+// GetNotificationsByCategory finds notifications in category, newest first
+func (s *Service) GetNotificationsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error) {
+	if s.categoryFinder == nil {
+		return nil, model.ErrCategoryQueryUnsupported{}
+	}
+	return s.categoryFinder.FindByCategory(ctx, category, limit, offset)
+}
+
+// GetNotificationsByCorrelationID finds the notifications produced by the
+// request or event with the given correlation ID, oldest first
+func (s *Service) GetNotificationsByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error) {
+	return s.repo.FindByCorrelationID(ctx, correlationID, limit, offset)
+}
+
+// ExportNotifications calls fn with each of the recipient's notifications
+// created in [from, to), oldest first, without loading them all at once
+func (s *Service) ExportNotifications(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error {
+	if s.scanner == nil {
+		return model.ErrExportUnsupported{}
+	}
+	return s.scanner.ScanByRecipient(ctx, s.recipients.Normalize(recipient), from, to, fn)
+}
+
+// PurgeNotificationsBefore deletes the tenant's notifications created before
+// cutoff and returns how many were deleted
+func (s *Service) PurgeNotificationsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	if s.purger == nil {
+		return 0, model.ErrPurgeUnsupported{}
+	}
+	deleted, err := s.purger.DeleteOlderThan(ctx, cutoff)
+	if err != nil {
+		return deleted, fmt.Errorf("error purging notifications: %w", err)
+	}
+	return deleted, nil
+}
+
+// ExportRecipientData collects everything stored about the recipient: their
+// notifications, oldest first, and their suppression list entry
+func (s *Service) ExportRecipientData(ctx context.Context, recipient string) (*model.RecipientData, error) {
+	recipient = s.recipients.Normalize(recipient)
+	data := &model.RecipientData{Recipient: recipient, Notifications: []*model.Notification{}}
+	err := s.eachRecipientNotification(ctx, recipient, func(notification *model.Notification) error {
+		data.Notifications = append(data.Notifications, notification)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.suppressions != nil {
+		suppression, err := s.suppressions.FindByRecipient(ctx, recipient)
+		if err != nil {
+			return nil, fmt.Errorf("error checking suppression list: %w", err)
+		}
+		data.Suppression = suppression
+	}
+	return data, nil
+}
+
+// EraseRecipientData anonymizes the recipient's notifications, see
+// model.Notification.Erase. A dry run only counts what would be erased. The
+// recipient's suppression list entry is kept, so a bounced or complaining
+// address is never sent to again; it can be removed through the suppression API.
+func (s *Service) EraseRecipientData(ctx context.Context, recipient string, dryRun bool) (*model.RecipientErasure, error) {
+	if s.eraser == nil {
+		return nil, model.ErrErasureUnsupported{}
+	}
+
+	recipient = s.recipients.Normalize(recipient)
+	erasure := &model.RecipientErasure{
+		Recipient: recipient,
+		DryRun:    dryRun,
+	}
+
+	if dryRun {
+		err := s.eachRecipientNotification(ctx, recipient, func(*model.Notification) error {
+			erasure.Notifications++
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return erasure, nil
+	}
+
+	erasure.ErasedAs = model.NewErasedRecipient()
+	erased, err := s.eraser.EraseRecipient(ctx, recipient, erasure.ErasedAs)
+	if err != nil {
+		return nil, fmt.Errorf("error erasing recipient data: %w", err)
+	}
+	erasure.Notifications = erased
+
+	// Log the tombstone rather than the recipient, so the erasure itself leaves no trace of them
+	logging.WithContext(ctx, s.logger).Info("erased recipient data",
+		zap.String("tenant_id", model.TenantFromContext(ctx)),
+		zap.String("erased_as", erasure.ErasedAs),
+		zap.Int("notifications", erased))
+	return erasure, nil
+}
+
+// eachRecipientNotification calls fn with each of the recipient's notifications,
+// streaming them when the store supports it and paging through them otherwise
+func (s *Service) eachRecipientNotification(ctx context.Context, recipient string, fn func(*model.Notification) error) error {
+	if s.scanner != nil {
+		if err := s.scanner.ScanByRecipient(ctx, recipient, time.Time{}, time.Now(), fn); err != nil {
+			return fmt.Errorf("error finding notifications: %w", err)
+		}
+		return nil
+	}
+
+	const pageSize = 100
+	var notifications []*model.Notification
+	for offset := 0; ; offset += pageSize {
+		page, err := s.repo.FindByRecipient(ctx, recipient, model.SortDescending, pageSize, offset)
+		if err != nil {
+			return fmt.Errorf("error finding notifications: %w", err)
+		}
+		notifications = append(notifications, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	// Pages come newest first; hand them over oldest first, as a scan would
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.Before(notifications[j].CreatedAt)
+	})
+	for _, notification := range notifications {
+		if err := fn(notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNotificationStats counts the tenant's notifications created within window
+// by status, type and priority. The counts may be up to the stats cache TTL old.
+func (s *Service) GetNotificationStats(ctx context.Context, window time.Duration) (*model.NotificationStats, error) {
+	if s.statsCounter == nil {
+		return nil, model.ErrStatsUnsupported{}
+	}
+
+	now := time.Now()
+	key := statsCacheKey{tenantID: model.TenantFromContext(ctx), window: window}
+	if stats, ok := s.statsCache.get(key, now); ok {
+		return stats, nil
+	}
+
+	stats, err := s.statsCounter.CountSince(ctx, now.Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("error counting notifications: %w", err)
+	}
+	s.statsCache.put(key, stats, now)
+	return stats, nil
 }
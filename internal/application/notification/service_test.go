@@ -0,0 +1,634 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// hangingEmailProvider never responds; it returns only once ctx is done
+type hangingEmailProvider struct{}
+
+func (hangingEmailProvider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestService_SendTimeout(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, hangingEmailProvider{}, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	service.SetSendTimeout(model.EmailNotification, 20*time.Millisecond)
+
+	t.Run("hung provider fails the notification", func(t *testing.T) {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+
+		err := service.SendNotification(context.Background(), notification)
+		assert.ErrorIs(t, err, model.ErrProviderTimeout{Timeout: 20 * time.Millisecond})
+		assert.ErrorIs(t, err, model.ErrProviderUnavailable)
+		assert.Equal(t, model.StatusFailed, repo.status(notification.ID))
+		assert.Equal(t, "email provider failed: timeout: no response within 20ms", notification.ErrorMessage)
+	})
+
+	t.Run("caller's deadline is not a send timeout", func(t *testing.T) {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := service.SendNotification(ctx, notification)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, model.ErrProviderTimeout{Timeout: 20 * time.Millisecond})
+	})
+}
+
+func TestService_SendWithoutProvider(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	notification := model.NewNotification("+14155552671", model.SMSNotification, model.SMSTemplate, uuid.Nil, model.TemplateData{})
+	err := service.SendNotification(context.Background(), notification)
+	assert.ErrorIs(t, err, model.ErrProviderNotConfigured{Type: model.SMSNotification})
+	assert.ErrorIs(t, err, model.ErrProviderUnavailable)
+	assert.Equal(t, model.StatusFailed, repo.status(notification.ID))
+	assert.Equal(t, "sms provider failed: no sms provider is configured", notification.ErrorMessage)
+}
+
+func TestService_SendMultiChannel(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	notification := model.NewNotification("", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	notification.Subject = "Suspicious sign-in"
+	notification.Content = "Was this you?"
+	notification.Priority = model.PriorityHigh
+	targets := []model.ChannelTarget{
+		{Type: model.EmailNotification, Recipient: "user@example.com"},
+		{Type: model.SMSNotification, Recipient: "+14155552671"},
+		{Type: model.EmailNotification, Recipient: ""},
+	}
+
+	group, err := service.SendMultiChannel(context.Background(), notification, targets)
+	require.NoError(t, err)
+	require.Len(t, group.Results, 3)
+	assert.Equal(t, 2, group.Failed())
+
+	// Each channel gets its own notification in the group
+	sent := group.Results[0]
+	require.NoError(t, sent.Err)
+	assert.Equal(t, model.StatusSent, repo.status(sent.Notification.ID))
+	assert.Equal(t, group.ID, sent.Notification.GroupID)
+	assert.Equal(t, "Suspicious sign-in", sent.Notification.Subject)
+	assert.Equal(t, []string{"user@example.com"}, provider.recipients)
+
+	// A channel without a provider fails without stopping the others
+	failed := group.Results[1]
+	assert.ErrorIs(t, failed.Err, model.ErrProviderUnavailable)
+	require.NotNil(t, failed.Notification)
+	assert.Equal(t, model.StatusFailed, repo.status(failed.Notification.ID))
+	assert.Equal(t, model.SMSNotification, failed.Notification.Type)
+
+	// An invalid channel is reported but never stored
+	invalid := group.Results[2]
+	assert.ErrorIs(t, invalid.Err, model.ErrValidation)
+	assert.Nil(t, invalid.Notification)
+
+	members, err := service.GetNotificationGroup(context.Background(), group.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	_, err = service.GetNotificationGroup(context.Background(), model.NewGroupID())
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	t.Run("requires a channel", func(t *testing.T) {
+		_, err := service.SendMultiChannel(context.Background(), notification, nil)
+		assert.ErrorIs(t, err, model.ErrValidation)
+	})
+
+	t.Run("sends to a repeated recipient once", func(t *testing.T) {
+		provider.recipients = nil
+		group, err := service.SendMultiChannel(context.Background(), notification, []model.ChannelTarget{
+			{Type: model.EmailNotification, Recipient: "User@Example.com"},
+			{Type: model.EmailNotification, Recipient: "user@example.com "},
+			{Type: model.SMSNotification, Recipient: "+1 415 555 2671"},
+			{Type: model.EmailNotification, Recipient: "USER@EXAMPLE.COM"},
+			{Type: model.SMSNotification, Recipient: "+14155552671"},
+		})
+		require.NoError(t, err)
+		require.Len(t, group.Results, 2)
+		assert.Equal(t, 3, group.Duplicates)
+		assert.Equal(t, "User@Example.com", group.Results[0].Target.Recipient)
+		assert.Equal(t, []string{"user@example.com"}, provider.recipients)
+	})
+
+	t.Run("keeps repeated recipients when duplicates are allowed", func(t *testing.T) {
+		targets := []model.ChannelTarget{
+			{Type: model.SMSNotification, Recipient: "+14155552671"},
+			{Type: model.SMSNotification, Recipient: "+1 415 555 2671"},
+		}
+		group, err := service.SendMultiChannel(model.ContextAllowingDuplicates(context.Background()), notification, targets)
+		require.NoError(t, err)
+		assert.Len(t, group.Results, 2)
+		assert.Zero(t, group.Duplicates)
+	})
+}
+
+// recordingEmailProvider accepts every email, recording the recipients and subjects
+type recordingEmailProvider struct {
+	recipients []string
+	subjects   []string
+}
+
+func (p *recordingEmailProvider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	p.recipients = append(p.recipients, to)
+	p.subjects = append(p.subjects, subject)
+	return "message-" + to, nil
+}
+
+func TestService_NormalizesRecipients(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	service.SetRecipientNormalizer(model.RecipientNormalizer{StripGmailDots: true})
+
+	for _, recipient := range []string{"First.Last@Gmail.com", "firstlast@gmail.com"} {
+		notification := model.NewNotification(recipient, model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+	}
+	assert.Equal(t, []string{"firstlast@gmail.com", "firstlast@gmail.com"}, provider.recipients)
+
+	// Both notifications share one history, however the recipient is written
+	history, err := service.GetNotificationHistory(context.Background(), "FIRST.last@gmail.com", model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
+
+// namedEmailProvider is a recordingEmailProvider that reports its name
+type namedEmailProvider struct {
+	recordingEmailProvider
+}
+
+func (p *namedEmailProvider) Name() string { return "ses" }
+
+func TestService_RecordsProvider(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, &namedEmailProvider{}, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	require.NoError(t, service.SendNotification(context.Background(), notification))
+	assert.Equal(t, "ses", notification.Provider)
+	assert.Equal(t, "message-user@example.com", notification.ProviderMessageID)
+
+	// Delivery events are matched on the provider as well as the message ID
+	event := model.DeliveryEvent{Provider: "sendgrid", ProviderMessageID: "message-user@example.com", Type: model.DeliveryEventDelivered}
+	assert.ErrorAs(t, service.HandleDeliveryEvent(context.Background(), event), &model.ErrNotificationNotFound{})
+
+	event.Provider = "ses"
+	require.NoError(t, service.HandleDeliveryEvent(context.Background(), event))
+	assert.Equal(t, model.StatusDelivered, repo.status(notification.ID))
+}
+
+// staticTemplateEngine renders every template as the same content
+type staticTemplateEngine struct {
+	content string
+}
+
+func (e staticTemplateEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	return e.content, nil
+}
+
+func (e staticTemplateEngine) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	return e.content, nil
+}
+
+// hangingTemplateEngine never finishes rendering; it returns only once ctx is done
+type hangingTemplateEngine struct{}
+
+func (hangingTemplateEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	<-ctx.Done()
+	return "", fmt.Errorf("error rendering template %s: %w", templateName, ctx.Err())
+}
+
+func (hangingTemplateEngine) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	return "", nil
+}
+
+func TestService_RenderTimeout(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, hangingTemplateEngine{}, nil, nil, zap.NewNop())
+	service.SetRenderTimeout(20 * time.Millisecond)
+
+	payload := []byte(`{"userId": "42", "email": "user@example.com", "username": "jane"}`)
+	err := service.HandleUserEvent(context.Background(), "user.registered", payload)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, repo.notifications)
+}
+
+func TestService_MalformedEvent(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, staticTemplateEngine{content: "<p>Welcome</p>"}, nil, nil, zap.NewNop())
+
+	// A payload that can't be decoded is told apart from an event that failed downstream
+	err := service.HandleUserEvent(context.Background(), "user.registered", []byte(`{"email": "user@example.com"`))
+	var malformed model.ErrMalformedEvent
+	require.ErrorAs(t, err, &malformed)
+	assert.Equal(t, "user.registered", malformed.EventType)
+	assert.ErrorIs(t, err, model.ErrValidation)
+	assert.Empty(t, repo.notifications)
+
+	err = service.HandleUserEvent(context.Background(), "user.registered", []byte(`{"email": 42}`))
+	assert.ErrorAs(t, err, &malformed)
+}
+
+func TestService_StampsCorrelationID(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, staticTemplateEngine{content: "<p>Welcome</p>"}, nil, nil, zap.NewNop())
+	ctx := model.ContextWithCorrelationID(context.Background(), "req-123")
+
+	payload := []byte(`{"userId": "42", "email": "user@example.com", "username": "jane"}`)
+	require.NoError(t, service.HandleUserEvent(ctx, "user.registered", payload))
+
+	sent := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	require.NoError(t, service.SendNotification(ctx, sent))
+
+	// A correlation ID set by the caller is kept
+	own := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	own.CorrelationID = "batch-7"
+	require.NoError(t, service.SendNotification(ctx, own))
+
+	require.Len(t, repo.notifications, 3)
+	for _, notification := range repo.notifications {
+		if notification.ID == own.ID {
+			assert.Equal(t, "batch-7", notification.CorrelationID)
+		} else {
+			assert.Equal(t, "req-123", notification.CorrelationID)
+		}
+	}
+}
+
+func TestService_HandleUserEventSetsCategory(t *testing.T) {
+	tests := []struct {
+		eventType string
+		category  string
+	}{
+		{"user.registered", model.CategoryWelcome},
+		{"user.verified", model.CategorySecurity},
+		{"user.password.reset", model.CategorySecurity},
+		{"user.password.changed", model.CategorySecurity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+			service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, staticTemplateEngine{content: "<p>Hello</p>"}, nil, nil, zap.NewNop())
+
+			payload := []byte(`{"userId": "42", "email": "user@example.com", "username": "jane", "resetLink": "https://example.com/reset"}`)
+			require.NoError(t, service.HandleUserEvent(context.Background(), tt.eventType, payload))
+
+			require.Len(t, repo.notifications, 1)
+			for _, notification := range repo.notifications {
+				assert.Equal(t, tt.category, notification.Category)
+			}
+		})
+	}
+}
+
+// queueingOutbox saves notifications to repo and records which were queued
+type queueingOutbox struct {
+	services.NotificationOutbox
+	repo   *fakeNotificationRepository
+	queued []uuid.UUID
+}
+
+func (o *queueingOutbox) SaveAndEnqueue(ctx context.Context, notification *model.Notification) error {
+	o.queued = append(o.queued, notification.ID)
+	return o.repo.Save(ctx, notification)
+}
+
+func TestService_HandleUserEventQueuesInOutbox(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	outbox := &queueingOutbox{repo: repo}
+	service := NewService(repo, provider, nil, nil, nil, staticTemplateEngine{content: "<p>Hi</p>"}, outbox, nil, zap.NewNop())
+
+	for eventType, payload := range map[string]string{
+		"user.registered":       `{"userId": "42", "email": "user@example.com", "username": "jane"}`,
+		"user.verified":         `{"userId": "42", "email": "user@example.com"}`,
+		"user.password.reset":   `{"userId": "42", "email": "user@example.com", "resetLink": "https://example.com/reset"}`,
+		"user.password.changed": `{"userId": "42", "email": "user@example.com"}`,
+	} {
+		require.NoError(t, service.HandleUserEvent(context.Background(), eventType, []byte(payload)), eventType)
+	}
+
+	// Every event notification is left pending for the outbox dispatcher to send
+	require.Len(t, outbox.queued, 4)
+	for _, id := range outbox.queued {
+		assert.Equal(t, model.StatusPending, repo.status(id))
+	}
+	assert.Empty(t, provider.recipients)
+}
+
+// namedTemplateEngine renders every template as its name
+type namedTemplateEngine struct{}
+
+func (namedTemplateEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	return templateName, nil
+}
+
+func (namedTemplateEngine) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	return templateName, nil
+}
+
+// fixedVariantSelector picks the same variant for every recipient of its types
+type fixedVariantSelector map[model.TemplateType]*model.Template
+
+func (s fixedVariantSelector) SelectVariant(ctx context.Context, templateType model.TemplateType, recipient string) (*model.Template, error) {
+	return s[templateType], nil
+}
+
+func TestService_TemplateVariants(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, namedTemplateEngine{}, nil, nil, zap.NewNop())
+	welcome := model.NewTemplate("welcome_b", model.WelcomeEmail, "You're in!", "<p>Hi</p>")
+	changed := model.NewTemplate("password_changed_b", model.PasswordChanged, "Your password was changed", "<p>Hi</p>")
+	service.SetTemplateVariantSelector(fixedVariantSelector{model.WelcomeEmail: welcome, model.PasswordChanged: changed})
+
+	registered := []byte(`{"userId": "42", "email": "user@example.com", "username": "jane"}`)
+	require.NoError(t, service.HandleUserEvent(context.Background(), "user.registered", registered))
+	reset := []byte(`{"userId": "42", "email": "user@example.com", "resetLink": "https://example.com/reset"}`)
+	require.NoError(t, service.HandleUserEvent(context.Background(), "user.password.reset", reset))
+	passwordChanged := []byte(`{"userId": "42", "email": "user@example.com"}`)
+	require.NoError(t, service.HandleUserEvent(context.Background(), "user.password.changed", passwordChanged))
+
+	require.Len(t, repo.notifications, 3)
+	for _, notification := range repo.notifications {
+		switch notification.TemplateData["eventType"] {
+		case "user.password.reset":
+			// Types without variants keep their default template
+			assert.Equal(t, "password_reset.html", notification.Content)
+			assert.Equal(t, "Password Reset Request", notification.Subject)
+			assert.NotContains(t, notification.Metadata, model.MetadataTemplateVariantID)
+		default:
+			// The variant replaces the default template and is recorded for attribution
+			variant := welcome
+			if notification.TemplateData["eventType"] == "user.password.changed" {
+				variant = changed
+			}
+			assert.Equal(t, variant.Name, notification.Content)
+			assert.Equal(t, variant.Subject, notification.Subject)
+			assert.Equal(t, variant.ID, notification.TemplateID)
+			assert.Equal(t, variant.ID.String(), notification.Metadata[model.MetadataTemplateVariantID])
+		}
+	}
+	assert.ElementsMatch(t, []string{"You're in!", "Password Reset Request", "Your password was changed"}, provider.subjects)
+}
+
+func TestService_SandboxKeepsOriginalRecipient(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	service.SetSandbox(&model.Sandbox{Email: "qa@example.com"})
+
+	notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	require.NoError(t, service.SendNotification(context.Background(), notification))
+
+	assert.Equal(t, "user@example.com", notification.Metadata[model.MetadataOriginalRecipient])
+	assert.Equal(t, "user@example.com", notification.Recipient)
+}
+
+func TestService_EmailSubjectPrefix(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	service.SetEmailSubjectPrefix("[STAGING]")
+
+	for _, subject := range []string{"Welcome", "[STAGING] Welcome"} {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		notification.Subject = subject
+		require.NoError(t, service.SendNotification(context.Background(), notification))
+		assert.Equal(t, subject, notification.Subject)
+	}
+	assert.Equal(t, []string{"[STAGING] Welcome", "[STAGING] Welcome"}, provider.subjects)
+}
+
+// optionsEmailProvider is a recordingEmailProvider that also sends with email options
+type optionsEmailProvider struct {
+	recordingEmailProvider
+	options []model.EmailOptions
+}
+
+func (p *optionsEmailProvider) SendEmailWithOptions(ctx context.Context, to, subject, content string, options model.EmailOptions) (string, error) {
+	p.options = append(p.options, options)
+	return p.SendEmail(ctx, to, subject, content)
+}
+
+func TestService_EmailOptions(t *testing.T) {
+	newEmail := func() *model.Notification {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		notification.ContentType = model.EmailContentText
+		notification.CC = []string{"billing@example.com"}
+		return notification
+	}
+
+	t.Run("sent with the options", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		provider := &optionsEmailProvider{}
+		service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+		require.NoError(t, service.SendNotification(context.Background(), newEmail()))
+		assert.Equal(t, []model.EmailOptions{{ContentType: model.EmailContentText, CC: []string{"billing@example.com"}}}, provider.options)
+	})
+
+	t.Run("rejected before it is stored if the provider can't send them", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		provider := &recordingEmailProvider{}
+		service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+		err := service.SendNotification(context.Background(), newEmail())
+		assert.ErrorIs(t, err, model.ErrValidation)
+		assert.Empty(t, repo.notifications)
+		assert.Empty(t, provider.recipients)
+	})
+}
+
+func TestService_SendLimitThrottles(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	_, err := service.GetSendLimitState()
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	service.SetSendLimit(NewSendLimit(1))
+
+	first := model.NewNotification("first@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	require.NoError(t, service.SendNotification(context.Background(), first))
+
+	second := model.NewNotification("second@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	err = service.SendNotification(context.Background(), second)
+	assert.Equal(t, model.ErrSendLimitExceeded{PerMinute: 1}, err)
+	assert.ErrorIs(t, err, model.ErrThrottled)
+	assert.Equal(t, model.StatusThrottled, repo.status(second.ID))
+	assert.Equal(t, []string{"first@example.com"}, provider.recipients)
+
+	state, err := service.GetSendLimitState()
+	require.NoError(t, err)
+	assert.True(t, state.Tripped)
+
+	state, err = service.ClearSendLimit()
+	require.NoError(t, err)
+	assert.False(t, state.Tripped)
+
+	third := model.NewNotification("third@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	require.NoError(t, service.SendNotification(context.Background(), third))
+	assert.Equal(t, []string{"first@example.com", "third@example.com"}, provider.recipients)
+}
+
+func TestService_SendLimitThrottlesRetries(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	service.SetSendLimit(NewSendLimit(1))
+
+	var failed []*model.Notification
+	for _, recipient := range []string{"first@example.com", "second@example.com"} {
+		notification := model.NewNotification(recipient, model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		require.NoError(t, notification.TransitionTo(model.StatusFailed, "provider down"))
+		require.NoError(t, repo.Save(context.Background(), notification))
+		failed = append(failed, notification)
+	}
+
+	_, err := service.RetryNotification(context.Background(), failed[0].ID.String())
+	require.NoError(t, err)
+
+	// Retries count against the limit like new notifications
+	retried, err := service.RetryNotification(context.Background(), failed[1].ID.String())
+	assert.ErrorIs(t, err, model.ErrThrottled)
+	assert.Equal(t, model.StatusThrottled, retried.Status)
+	assert.Equal(t, model.StatusThrottled, repo.status(failed[1].ID))
+	assert.Equal(t, []string{"first@example.com"}, provider.recipients)
+}
+
+// fakeNotificationScanner streams a fixed list of notifications, recording the recipient asked for
+type fakeNotificationScanner struct {
+	notifications []*model.Notification
+	recipient     string
+}
+
+func (s *fakeNotificationScanner) ScanByRecipient(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error {
+	s.recipient = recipient
+	for _, notification := range s.notifications {
+		if err := fn(notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestService_ExportNotifications(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	from, to := time.Now().Add(-time.Hour), time.Now()
+
+	err := service.ExportNotifications(context.Background(), "user@example.com", from, to, func(*model.Notification) error { return nil })
+	assert.ErrorIs(t, err, model.ErrExportUnsupported{})
+
+	scanner := &fakeNotificationScanner{notifications: []*model.Notification{
+		model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{}),
+		model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{}),
+	}}
+	service.SetNotificationScanner(scanner)
+
+	var exported []*model.Notification
+	err = service.ExportNotifications(context.Background(), " User@Example.com", from, to, func(notification *model.Notification) error {
+		exported = append(exported, notification)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, scanner.notifications, exported)
+	assert.Equal(t, "user@example.com", scanner.recipient)
+}
+
+// fakeNotificationEraser erases notifications in a fakeNotificationRepository
+type fakeNotificationEraser struct {
+	repo *fakeNotificationRepository
+}
+
+func (e *fakeNotificationEraser) EraseRecipient(ctx context.Context, recipient, erasedAs string) (int, error) {
+	e.repo.mu.Lock()
+	defer e.repo.mu.Unlock()
+	erased := 0
+	for _, notification := range e.repo.notifications {
+		if notification.Recipient == recipient {
+			notification.Erase(erasedAs)
+			erased++
+		}
+	}
+	return erased, nil
+}
+
+func TestService_RecipientData(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	service := NewService(repo, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	older := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{"name": "Ada"})
+	older.CreatedAt = time.Now().Add(-time.Hour)
+	newer := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{"name": "Ada"})
+	other := model.NewNotification("other@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	for _, notification := range []*model.Notification{newer, older, other} {
+		require.NoError(t, repo.Save(ctx, notification))
+	}
+
+	_, err := service.EraseRecipientData(ctx, "user@example.com", false)
+	assert.ErrorIs(t, err, model.ErrValidation)
+	service.SetNotificationEraser(&fakeNotificationEraser{repo: repo})
+
+	t.Run("export pages through the recipient's notifications oldest first", func(t *testing.T) {
+		data, err := service.ExportRecipientData(ctx, " User@Example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", data.Recipient)
+		assert.Equal(t, []*model.Notification{older, newer}, data.Notifications)
+		assert.Nil(t, data.Suppression)
+	})
+
+	t.Run("dry run only counts", func(t *testing.T) {
+		erasure, err := service.EraseRecipientData(ctx, "User@Example.com", true)
+		require.NoError(t, err)
+		assert.Equal(t, &model.RecipientErasure{
+			Recipient:     "user@example.com",
+			Notifications: 2,
+			DryRun:        true,
+		}, erasure)
+		assert.Equal(t, "user@example.com", older.Recipient)
+	})
+
+	t.Run("erase", func(t *testing.T) {
+		erasure, err := service.EraseRecipientData(ctx, "user@example.com", false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, erasure.Notifications)
+		assert.False(t, erasure.DryRun)
+		assert.True(t, strings.HasPrefix(erasure.ErasedAs, "erased:"), erasure.ErasedAs)
+		assert.Equal(t, erasure.ErasedAs, older.Recipient)
+		assert.Equal(t, erasure.ErasedAs, newer.Recipient)
+		assert.Nil(t, older.TemplateData)
+		assert.Equal(t, "other@example.com", other.Recipient)
+
+		erasure, err = service.EraseRecipientData(ctx, "user@example.com", false)
+		require.NoError(t, err)
+		assert.Zero(t, erasure.Notifications)
+
+		data, err := service.ExportRecipientData(ctx, "user@example.com")
+		require.NoError(t, err)
+		assert.Empty(t, data.Notifications)
+	})
+}
@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// DefaultStatsCacheTTL is how long computed notification stats are reused by default
+const DefaultStatsCacheTTL = 30 * time.Second
+
+// statsCache keeps recently computed notification stats per tenant and window,
+// so dashboards refreshing the stats don't each hit the store. Entries are
+// only replaced once they expire, keeping at most one per tenant and window.
+type statsCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[statsCacheKey]statsCacheEntry
+}
+
+type statsCacheKey struct {
+	tenantID string
+	window   time.Duration
+}
+
+type statsCacheEntry struct {
+	stats   *model.NotificationStats
+	expires time.Time
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{
+		ttl:     ttl,
+		entries: make(map[statsCacheKey]statsCacheEntry),
+	}
+}
+
+// get returns the cached stats for key, if they haven't expired
+func (c *statsCache) get(key statsCacheKey, now time.Time) (*model.NotificationStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		return nil, false
+	}
+	return entry.stats, true
+}
+
+// put caches stats for key, dropping any other expired entries
+func (c *statsCache) put(key statsCacheKey, stats *model.NotificationStats, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for cached, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, cached)
+		}
+	}
+	c.entries[key] = statsCacheEntry{stats: stats, expires: now.Add(c.ttl)}
+}
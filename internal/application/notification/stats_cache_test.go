@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingStatsCounter counts how often stats are computed
+type countingStatsCounter struct {
+	calls int
+}
+
+func (c *countingStatsCounter) CountSince(ctx context.Context, since time.Time) (*model.NotificationStats, error) {
+	c.calls++
+	stats := model.NewNotificationStats(since)
+	stats.Total = int64(c.calls)
+	return stats, nil
+}
+
+func TestService_GetNotificationStats(t *testing.T) {
+	t.Run("stats are reused within the TTL", func(t *testing.T) {
+		counter := &countingStatsCounter{}
+		service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+		service.SetStatsCounter(counter, time.Minute)
+
+		ctx := context.Background()
+		first, err := service.GetNotificationStats(ctx, time.Hour)
+		require.NoError(t, err)
+		again, err := service.GetNotificationStats(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.Same(t, first, again)
+		assert.Equal(t, 1, counter.calls)
+
+		// Other tenants and windows are counted separately
+		_, err = service.GetNotificationStats(model.ContextWithTenant(ctx, "acme"), time.Hour)
+		require.NoError(t, err)
+		_, err = service.GetNotificationStats(ctx, 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 3, counter.calls)
+	})
+
+	t.Run("zero TTL counts every time", func(t *testing.T) {
+		counter := &countingStatsCounter{}
+		service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+		service.SetStatsCounter(counter, 0)
+
+		for i := 0; i < 2; i++ {
+			_, err := service.GetNotificationStats(context.Background(), time.Hour)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, counter.calls)
+	})
+
+	t.Run("unsupported store", func(t *testing.T) {
+		service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+		_, err := service.GetNotificationStats(context.Background(), time.Hour)
+		assert.ErrorIs(t, err, model.ErrValidation)
+	})
+}
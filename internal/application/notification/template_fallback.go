@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"strings"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// TemplateFallback decides what happens to an event notification whose template doesn't exist
+type TemplateFallback string
+
+const (
+	// TemplateFallbackFail fails the event, so the notification isn't sent; it is the default
+	TemplateFallbackFail TemplateFallback = "fail"
+	// TemplateFallbackPlain sends the notification with a plain built-in body instead
+	TemplateFallbackPlain TemplateFallback = "plain"
+)
+
+// IsValid reports whether the fallback is a known template fallback
+func (f TemplateFallback) IsValid() bool {
+	return f == TemplateFallbackFail || f == TemplateFallbackPlain
+}
+
+// fallbackTemplates are the plain bodies sent, per event template type, when
+// TemplateFallbackPlain is set and the event's template doesn't exist. They
+// only use data every event of the type carries.
+var fallbackTemplates = map[model.TemplateType]*template.Template{
+	model.WelcomeEmail: template.Must(template.New("welcome_email").Parse(
+		`<p>Hi {{.FirstName}},</p><p>Welcome to our service. Your username is {{.Username}}.</p>`)),
+	model.AccountActivation: template.Must(template.New("account_activation").Parse(
+		`<p>Your email address {{.Email}} has been verified.</p>`)),
+	model.PasswordReset: template.Must(template.New("password_reset").Parse(
+		`<p>We received a request to reset your password. Reset it here: <a href="{{.ResetLink}}">{{.ResetLink}}</a></p><p>If you didn't ask to reset your password, you can ignore this email.</p>`)),
+	model.PasswordChanged: template.Must(template.New("password_changed").Parse(
+		`<p>The password of your account {{.Email}} was changed. If you didn't change it, reset your password and contact support.</p>`)),
+}
+
+// SetTemplateFallback sets what happens to an event notification of
+// templateType whose template doesn't exist; TemplateFallbackFail is the default
+func (s *Service) SetTemplateFallback(templateType model.TemplateType, fallback TemplateFallback) {
+	if s.templateFallbacks == nil {
+		s.templateFallbacks = make(map[model.TemplateType]TemplateFallback)
+	}
+	s.templateFallbacks[templateType] = fallback
+}
+
+// renderFallback renders the plain body of templateType in place of the
+// template named name, when err reports that template missing and the type
+// falls back. Otherwise err is returned as is.
+func (s *Service) renderFallback(ctx context.Context, templateType model.TemplateType, name string, data interface{}, err error) (string, error) {
+	fallback, ok := fallbackTemplates[templateType]
+	if !ok || s.templateFallbacks[templateType] != TemplateFallbackPlain || !errors.Is(err, model.ErrNotFound) {
+		return "", err
+	}
+
+	var content strings.Builder
+	if err := fallback.Execute(&content, data); err != nil {
+		return "", err
+	}
+
+	metrics.RecordTemplateFallback(string(templateType))
+	logging.WithContext(ctx, s.logger).Warn("template not found, sending the plain fallback",
+		zap.String("template", name),
+		zap.String("type", string(templateType)),
+	)
+	return content.String(), nil
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// missingTemplateEngine fails every render with err
+type missingTemplateEngine struct {
+	err error
+}
+
+func (e missingTemplateEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	return "", e.err
+}
+
+func (e missingTemplateEngine) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	return "", e.err
+}
+
+func TestService_TemplateFallback(t *testing.T) {
+	registered := []byte(`{"userId": "42", "email": "user@example.com", "username": "jane", "firstName": "Jane"}`)
+	notFound := model.ErrTemplateNotFound{ID: "welcome.html"}
+
+	t.Run("fails by default", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, missingTemplateEngine{err: notFound}, nil, nil, zap.NewNop())
+
+		err := service.HandleUserEvent(context.Background(), "user.registered", registered)
+		assert.ErrorIs(t, err, model.ErrNotFound)
+		assert.Empty(t, repo.notifications)
+	})
+
+	t.Run("sends the plain body", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		provider := &recordingEmailProvider{}
+		service := NewService(repo, provider, nil, nil, nil, missingTemplateEngine{err: notFound}, nil, nil, zap.NewNop())
+		service.SetTemplateFallback(model.WelcomeEmail, TemplateFallbackPlain)
+
+		require.NoError(t, service.HandleUserEvent(context.Background(), "user.registered", registered))
+
+		require.Len(t, repo.notifications, 1)
+		for _, notification := range repo.notifications {
+			assert.Equal(t, "<p>Hi Jane,</p><p>Welcome to our service. Your username is jane.</p>", notification.Content)
+			assert.Equal(t, "Welcome to Our Service", notification.Subject)
+		}
+		assert.Equal(t, []string{"user@example.com"}, provider.recipients)
+	})
+
+	t.Run("only for the configured types", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, missingTemplateEngine{err: notFound}, nil, nil, zap.NewNop())
+		service.SetTemplateFallback(model.WelcomeEmail, TemplateFallbackPlain)
+
+		err := service.HandleUserEvent(context.Background(), "user.password.changed", []byte(`{"userId": "42", "email": "user@example.com"}`))
+		assert.ErrorIs(t, err, model.ErrNotFound)
+	})
+
+	t.Run("only when the template is missing", func(t *testing.T) {
+		repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+		service := NewService(repo, &recordingEmailProvider{}, nil, nil, nil, missingTemplateEngine{err: assert.AnError}, nil, nil, zap.NewNop())
+		service.SetTemplateFallback(model.WelcomeEmail, TemplateFallbackPlain)
+
+		err := service.HandleUserEvent(context.Background(), "user.registered", registered)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, repo.notifications)
+	})
+}
+
+func TestFallbackTemplates(t *testing.T) {
+	// Every event type renders from the data its handler passes
+	data := map[string]interface{}{
+		"FirstName": "Jane",
+		"Username":  "jane",
+		"Email":     "user@example.com",
+		"ResetLink": "https://example.com/reset?token=a&b",
+		"Year":      2025,
+	}
+	for _, templateType := range []model.TemplateType{model.WelcomeEmail, model.AccountActivation, model.PasswordReset, model.PasswordChanged} {
+		fallback, ok := fallbackTemplates[templateType]
+		require.True(t, ok, templateType)
+
+		var content strings.Builder
+		require.NoError(t, fallback.Execute(&content, data), templateType)
+		assert.NotContains(t, content.String(), "<no value>", templateType)
+	}
+
+	// Data is escaped
+	var content strings.Builder
+	require.NoError(t, fallbackTemplates[model.PasswordReset].Execute(&content, data))
+	assert.Contains(t, content.String(), `href="https://example.com/reset?token=a&amp;b"`)
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// TemplateSendOptions describes the notification SendNotificationWithTemplate creates
+type TemplateSendOptions struct {
+	// Type is the channel the notification is sent on, email unless set
+	Type model.NotificationType
+	// TemplateType picks the template's A/B variant and plain fallback, if
+	// either is configured for the type
+	TemplateType model.TemplateType
+	// Locale is the recipient's preferred locale, resolved like an event's
+	Locale   string
+	Subject  string
+	Category string
+	// TemplateData is stored on the notification alongside its subject and
+	// content, e.g. the event that caused it
+	TemplateData model.TemplateData
+}
+
+// SendNotificationWithTemplate renders templateName with data for recipient,
+// then creates a notification with the rendered content and sends it like
+// SendNotification, returning it once it has been sent or queued. The
+// template is picked and rendered like an event's: see processTemplate.
+// data["Year"] defaults to the current year; data itself isn't changed.
+func (s *Service) SendNotificationWithTemplate(ctx context.Context, recipient, templateName string, data map[string]interface{}, opts TemplateSendOptions) (*model.Notification, error) {
+	// Defaults go in a copy, as callers may reuse data
+	templateVariables := make(map[string]interface{}, len(data)+1)
+	for key, value := range data {
+		templateVariables[key] = value
+	}
+	if _, ok := templateVariables["Year"]; !ok {
+		templateVariables["Year"] = time.Now().Year()
+	}
+
+	notificationType := opts.Type
+	if notificationType == "" {
+		notificationType = model.EmailNotification
+	}
+
+	recipient = s.recipients.Normalize(recipient)
+	content, variant, err := s.processTemplate(ctx, opts.TemplateType, templateName, recipient, s.resolveLocale(ctx, opts.Locale), templateVariables)
+	if err != nil {
+		return nil, fmt.Errorf("error processing template %s: %w", templateName, err)
+	}
+
+	templateData := model.TemplateData{
+		"subject": opts.Subject,
+		"content": content,
+	}
+	for key, value := range opts.TemplateData {
+		templateData[key] = value
+	}
+	// Template types are named after the channels they are for
+	notification := model.NewNotification(recipient, notificationType, model.TemplateType(notificationType), uuid.Nil, templateData)
+	notification.Subject = opts.Subject
+	notification.Category = opts.Category
+	notification.Content = content
+	notification.Priority = s.defaultPriority(notification.Type)
+	notification.CorrelationID = model.CorrelationIDFromContext(ctx)
+	applyTemplateVariant(notification, variant)
+
+	if err := s.validateAndDeliver(ctx, notification); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
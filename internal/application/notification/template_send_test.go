@@ -0,0 +1,100 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingTemplateEngine renders every template as its name, recording the
+// data it was given
+type recordingTemplateEngine struct {
+	data map[string]interface{}
+}
+
+func (e *recordingTemplateEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	e.data = data.(map[string]interface{})
+	return templateName, nil
+}
+
+func (e *recordingTemplateEngine) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	return "", nil
+}
+
+func TestService_SendNotificationWithTemplate(t *testing.T) {
+	repo := &fakeNotificationRepository{notifications: make(map[string]*model.Notification)}
+	provider := &recordingEmailProvider{}
+	service := NewService(repo, provider, nil, nil, nil, knownTemplateEngine{"invoice.html": true}, nil, nil, zap.NewNop())
+	ctx := model.ContextWithCorrelationID(context.Background(), "req-9")
+
+	data := map[string]interface{}{"Amount": "12.00"}
+	notification, err := service.SendNotificationWithTemplate(ctx, " User@Example.com ", "invoice.html", data, TemplateSendOptions{
+		Subject:      "Your invoice",
+		Category:     "billing",
+		TemplateData: model.TemplateData{"invoiceId": "inv-1"},
+	})
+	require.NoError(t, err)
+
+	// The notification is rendered, stored and sent in one call
+	assert.Equal(t, "user@example.com", notification.Recipient)
+	assert.Equal(t, "invoice.html en", notification.Content)
+	assert.Equal(t, "Your invoice", notification.Subject)
+	assert.Equal(t, "billing", notification.Category)
+	assert.Equal(t, "req-9", notification.CorrelationID)
+	assert.Equal(t, model.TemplateData{"subject": "Your invoice", "content": "invoice.html en", "invoiceId": "inv-1"}, notification.TemplateData)
+	assert.Equal(t, map[string]interface{}{"Amount": "12.00"}, data, "the caller's data is left alone")
+	assert.Contains(t, repo.notifications, notification.ID.String())
+	assert.Equal(t, []string{"user@example.com"}, provider.recipients)
+
+	_, err = service.SendNotificationWithTemplate(ctx, "user@example.com", "missing.html", nil, TemplateSendOptions{Subject: "Missing"})
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	assert.Len(t, provider.recipients, 1)
+}
+
+func TestService_SendNotificationWithTemplate_SendsLikeSendNotification(t *testing.T) {
+	window, err := model.ParseSendWindow("mon-fri 09:00-17:00")
+	require.NoError(t, err)
+	saturday := time.Date(2025, 1, 18, 12, 0, 0, 0, time.UTC)
+
+	engine := &recordingTemplateEngine{}
+	outbox := &enqueuingOutbox{}
+	service := NewService(&fakeNotificationRepository{notifications: make(map[string]*model.Notification)}, &recordingEmailProvider{}, nil, nil, nil, engine, outbox, nil, zap.NewNop())
+	windows := NewSendWindows(map[string]model.SendWindow{"marketing": window})
+	windows.now = func() time.Time { return saturday }
+	service.SetSendWindows(windows)
+	service.SetDisallowedPriorities(model.PushNotification, []model.Priority{model.PriorityMedium})
+
+	// The year defaults in the data the template sees, which the caller doesn't
+	data := map[string]interface{}{"Name": "Jane"}
+	notification, err := service.SendNotificationWithTemplate(context.Background(), "user@example.com", "promo.html", data, TemplateSendOptions{Subject: "Sale", Category: "marketing"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().Year(), engine.data["Year"])
+	assert.Equal(t, "Jane", engine.data["Name"])
+	assert.NotContains(t, data, "Year")
+
+	// Email unless another channel is asked for, with the channel's default
+	// priority and held back by the category's send window
+	assert.Equal(t, model.EmailNotification, notification.Type)
+	assert.Equal(t, model.EmailTemplate, notification.TemplateType)
+	assert.Equal(t, uuid.Nil, notification.TemplateID)
+	assert.Equal(t, model.PriorityMedium, notification.Priority)
+	require.NotNil(t, notification.ScheduledAt)
+	assert.True(t, time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC).Equal(*notification.ScheduledAt))
+	assert.Equal(t, []*model.Notification{notification}, outbox.enqueued)
+
+	sms, err := service.SendNotificationWithTemplate(context.Background(), "+15555550100", "code.txt", nil, TemplateSendOptions{Type: model.SMSNotification})
+	require.NoError(t, err)
+	assert.Equal(t, model.SMSNotification, sms.Type)
+	assert.Equal(t, model.SMSTemplate, sms.TemplateType)
+
+	// Priorities the channel disallows are refused, so nothing is queued
+	_, err = service.SendNotificationWithTemplate(context.Background(), "device-token", "alert.txt", nil, TemplateSendOptions{Type: model.PushNotification})
+	assert.ErrorIs(t, err, model.ErrValidation)
+	assert.Len(t, outbox.enqueued, 2)
+}
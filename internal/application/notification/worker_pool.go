@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"go.uber.org/zap"
+)
+
+// ErrWorkerPoolClosed is returned when submitting to a closed worker pool
+var ErrWorkerPoolClosed = errors.New("worker pool is closed")
+
+// WorkerPoolConfig holds the configuration for the dispatch worker pool
+type WorkerPoolConfig struct {
+	Workers   int // Number of jobs run concurrently
+	QueueSize int // Jobs each worker holds before Submit blocks
+}
+
+// DefaultWorkerPoolConfig returns a WorkerPoolConfig with recommended default values
+func DefaultWorkerPoolConfig() WorkerPoolConfig {
+	return WorkerPoolConfig{
+		Workers:   8,
+		QueueSize: 100,
+	}
+}
+
+// WorkerPool dispatches notifications on a fixed number of workers, so one slow
+// provider call doesn't hold up the others. Jobs submitted with the same ordering
+// key, such as a recipient, run on the same worker in the order they were
+// submitted. Each worker's queue is bounded: Submit blocks while the queue its
+// key maps to is full, pushing back on the producer instead of buffering without
+// limit.
+//
+// Jobs are passed a context that is cancelled once Shutdown stops waiting for
+// them. A job whose context is done must not start its work, and should leave
+// it to be retried instead, e.g. by not completing its outbox entry. A job that
+// panics is logged and doesn't take its worker down with it.
+type WorkerPool struct {
+	queues  []chan func(context.Context)
+	next    atomic.Uint64 // Spreads jobs without an ordering key across workers
+	wg      sync.WaitGroup
+	submits sync.WaitGroup // Submit calls that may still send to a queue
+	ctx     context.Context
+	cancel  context.CancelFunc
+	logger  *zap.Logger
+
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{} // Closed by Shutdown, releasing Submit calls waiting on a full queue
+}
+
+// NewWorkerPool creates a worker pool and starts its workers
+func NewWorkerPool(config WorkerPoolConfig, logger *zap.Logger) *WorkerPool {
+	workers := config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queueSize := config.QueueSize
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &WorkerPool{
+		queues:  make([]chan func(context.Context), workers),
+		ctx:     ctx,
+		cancel:  cancel,
+		logger:  logger,
+		closing: make(chan struct{}),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan func(context.Context), queueSize)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+	return p
+}
+
+// work runs the jobs of one queue until it is closed
+func (p *WorkerPool) work(queue chan func(context.Context)) {
+	defer p.wg.Done()
+	for job := range queue {
+		metrics.RecordDispatchDequeued()
+		p.run(job)
+	}
+}
+
+// run runs a job, recovering from a panic so the worker carries on with the next one
+func (p *WorkerPool) run(job func(context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("dispatch job panicked", zap.Any("panic", r), zap.Stack("stack"))
+		}
+	}()
+	job(p.ctx)
+}
+
+// Submit queues job to run on the worker key maps to; an empty key runs it on
+// any worker. It blocks while that worker's queue is full, returning ctx's error
+// if ctx is done first, or ErrWorkerPoolClosed if the pool is shut down first.
+func (p *WorkerPool) Submit(ctx context.Context, key string, job func(ctx context.Context)) error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrWorkerPoolClosed
+	}
+	p.submits.Add(1)
+	p.mu.RUnlock()
+	defer p.submits.Done()
+
+	// Counted before the send so a worker can't dequeue the job first
+	metrics.RecordDispatchQueued()
+	select {
+	case p.queues[p.queueIndex(key)] <- job:
+		return nil
+	case <-p.closing:
+		metrics.RecordDispatchDequeued()
+		return ErrWorkerPoolClosed
+	case <-ctx.Done():
+		metrics.RecordDispatchDequeued()
+		return ctx.Err()
+	}
+}
+
+// queueIndex maps an ordering key to its worker's queue
+func (p *WorkerPool) queueIndex(key string) int {
+	if key == "" {
+		return int(p.next.Add(1) % uint64(len(p.queues)))
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(len(p.queues)))
+}
+
+// Shutdown stops accepting jobs and waits for the queued ones to finish. If ctx
+// is done first, it cancels the jobs' context so the jobs still queued skip their
+// work, and returns ctx's error without waiting for the jobs already running.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	first := !p.closed
+	if first {
+		p.closed = true
+		close(p.closing)
+	}
+	p.mu.Unlock()
+
+	if first {
+		// Submit calls already past the closed check return promptly now that
+		// closing is closed; the queues can be closed once none can send to them
+		p.submits.Wait()
+		for _, queue := range p.queues {
+			close(queue)
+		}
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
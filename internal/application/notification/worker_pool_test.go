@@ -0,0 +1,185 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWorkerPool_PreservesOrderPerKey(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 4, QueueSize: 10}, zap.NewNop())
+
+	var mu sync.Mutex
+	order := make(map[string][]int)
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("user-%d@example.com", i%5)
+		i := i
+		require.NoError(t, pool.Submit(context.Background(), key, func(context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			order[key] = append(order[key], i)
+		}))
+	}
+	require.NoError(t, pool.Shutdown(context.Background()))
+
+	require.Len(t, order, 5)
+	for key, jobs := range order {
+		assert.IsIncreasing(t, jobs, key)
+		assert.Len(t, jobs, 10)
+	}
+}
+
+func TestWorkerPool_RunsKeysConcurrently(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 2, QueueSize: 1}, zap.NewNop())
+	defer pool.Shutdown(context.Background())
+
+	// A job blocked on one worker doesn't hold up jobs on the other
+	release := make(chan struct{})
+	done := make(chan struct{})
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) { <-release }))
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) { close(done) }))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("second job didn't run while the first was blocked")
+	}
+	close(release)
+}
+
+func TestWorkerPool_SubmitBlocksWhenQueueIsFull(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 1}, zap.NewNop())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) {
+		close(started)
+		<-release
+	}))
+	<-started
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) {}))
+
+	// The worker is busy and its queue is full
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Submit(ctx, "", func(context.Context) {}), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, pool.Shutdown(context.Background()))
+	assert.ErrorIs(t, pool.Submit(context.Background(), "", func(context.Context) {}), ErrWorkerPoolClosed)
+}
+
+func TestWorkerPool_ShutdownDrainsQueuedJobs(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 10}, zap.NewNop())
+
+	release := make(chan struct{})
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) { <-release }))
+
+	var mu sync.Mutex
+	var ran int
+	for i := 0; i < 5; i++ {
+		require.NoError(t, pool.Submit(context.Background(), "", func(ctx context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			if ctx.Err() == nil {
+				ran++
+			}
+		}))
+	}
+
+	shutdown := make(chan error)
+	go func() { shutdown <- pool.Shutdown(context.Background()) }()
+
+	// No new work is accepted while the queue drains
+	assert.Eventually(t, func() bool {
+		return pool.Submit(context.Background(), "", func(context.Context) {}) == ErrWorkerPoolClosed
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	require.NoError(t, <-shutdown)
+	assert.Equal(t, 5, ran)
+}
+
+func TestWorkerPool_ShutdownDeadlineSkipsQueuedJobs(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 10}, zap.NewNop())
+
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) { <-release }))
+
+	skipped := make(chan bool, 1)
+	require.NoError(t, pool.Submit(context.Background(), "", func(ctx context.Context) {
+		skipped <- ctx.Err() != nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Shutdown(ctx), context.DeadlineExceeded)
+
+	// Once the running job returns, the queued one is told not to start
+	release <- struct{}{}
+	assert.True(t, <-skipped)
+}
+
+func TestWorkerPool_RecoversFromPanickingJob(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 10}, zap.NewNop())
+
+	ran := make(chan struct{})
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) { panic("boom") }))
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) { close(ran) }))
+
+	// The worker carries on with the next job
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("job after the panicking one didn't run")
+	}
+	require.NoError(t, pool.Shutdown(context.Background()))
+}
+
+func TestWorkerPool_ShutdownDeadlineWithFullQueue(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 1}, zap.NewNop())
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) {
+		close(started)
+		<-release
+	}))
+	<-started
+	require.NoError(t, pool.Submit(context.Background(), "", func(context.Context) {}))
+
+	// A producer is waiting on the full queue when shutdown starts
+	submitted := make(chan error)
+	go func() {
+		submitted <- pool.Submit(context.Background(), "", func(context.Context) {})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	shutdown := make(chan error)
+	go func() { shutdown <- pool.Shutdown(ctx) }()
+
+	// Shutdown gives up at its deadline instead of waiting for the queue to drain,
+	// and the waiting producer is told the pool is closed
+	select {
+	case err := <-shutdown:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("shutdown didn't return at its deadline")
+	}
+	select {
+	case err := <-submitted:
+		assert.ErrorIs(t, err, ErrWorkerPoolClosed)
+	case <-time.After(time.Second):
+		t.Fatal("submit waiting on the full queue didn't return")
+	}
+}
@@ -0,0 +1,373 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"go.uber.org/zap"
+)
+
+// DefaultReloadInterval is how often a FileEngine checks its directory for
+// changes when it can't watch it
+const DefaultReloadInterval = 2 * time.Second
+
+// watchSettleDelay is how long a FileEngine waits after a change is reported
+// before reloading, so an editor's burst of writes is reloaded once; changes
+// reported meanwhile don't postpone it further
+const watchSettleDelay = 50 * time.Millisecond
+
+// engineFileExtensions are the extensions of the files a FileEngine renders
+var engineFileExtensions = []string{".html", ".txt"}
+
+// FileEngine renders templates straight from the .html and .txt files under a
+// directory, for deployments that keep templates out of the database. A
+// template is named by its path under the directory, e.g. "welcome.html" or
+// "welcome.fr.html", as the service looks templates up; includes may leave
+// out the extension, and GetTemplate finds a locale's translation by the
+// untranslated name. Files may start with a front-matter block, see
+// model.ParseTemplateFile, whose name the template can be found by too.
+// Every tenant shares the same templates.
+//
+// Run reloads the files as they change. A file that no longer parses is
+// logged and its previous version kept, so a bad edit can't break sending.
+type FileEngine struct {
+	dir    string
+	logger *zap.Logger
+
+	mu          sync.RWMutex
+	templates   map[string]*model.Template // By path and name
+	files       map[string]*model.Template // By path under dir, the last good version of each file
+	fingerprint string                     // Of the files as last loaded
+}
+
+// NewFileEngine loads the templates under dir. It fails if dir can't be read;
+// files that can't be parsed are logged and left out.
+func NewFileEngine(dir string, logger *zap.Logger) (*FileEngine, error) {
+	e := &FileEngine{
+		dir:       dir,
+		logger:    logger,
+		templates: make(map[string]*model.Template),
+		files:     make(map[string]*model.Template),
+	}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Run reloads the templates whenever a file is added, changed or removed,
+// until ctx is cancelled. The directory and its subdirectories are watched
+// with inotify or its platform's equivalent; where they can't be, such as
+// when the system's watch limit is reached, the directory is polled every
+// interval instead. Both notice files swapped in through symlinks, as
+// Kubernetes does when updating a mounted ConfigMap.
+func (e *FileEngine) Run(ctx context.Context, interval time.Duration) {
+	if err := e.watch(ctx); err != nil {
+		e.logger.Warn("can't watch template files, polling for changes instead",
+			zap.String("dir", e.dir),
+			zap.Duration("interval", interval),
+			zap.Error(err),
+		)
+		e.poll(ctx, interval)
+	}
+}
+
+// watch reloads the templates as the directory's watcher reports changes,
+// until ctx is cancelled. It returns an error if the directory can't be
+// watched, or stops being watched, and nil once ctx is cancelled.
+func (e *FileEngine) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := e.watchTree(watcher, e.dir); err != nil {
+		return err
+	}
+
+	settle := time.NewTimer(watchSettleDelay)
+	settle.Stop()
+	defer settle.Stop()
+	settling := false
+	changed := func() {
+		if !settling {
+			settle.Reset(watchSettleDelay)
+			settling = true
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return fmt.Errorf("template file watcher stopped")
+			}
+			// Directories created later are watched too, with the files already in them
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := e.watchTree(watcher, event.Name); err != nil {
+						return err
+					}
+				}
+			}
+			changed()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return fmt.Errorf("template file watcher stopped")
+			}
+			// Events may have been lost, e.g. to a queue overflow, so check anyway
+			e.logger.Error("error watching template files", zap.String("dir", e.dir), zap.Error(err))
+			changed()
+		case <-settle.C:
+			settling = false
+			e.reloadIfChanged()
+		}
+	}
+}
+
+// watchTree adds root and every directory under it to watcher
+func (e *FileEngine) watchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// poll reloads the templates every interval if any file changed, until ctx
+// is cancelled
+func (e *FileEngine) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.reloadIfChanged()
+		}
+	}
+}
+
+// reloadIfChanged reloads the templates if any file was added, changed or
+// removed since they were last loaded
+func (e *FileEngine) reloadIfChanged() {
+	fingerprint, err := e.scan()
+	if err != nil {
+		e.logger.Error("error checking template files for changes", zap.String("dir", e.dir), zap.Error(err))
+		return
+	}
+	e.mu.RLock()
+	changed := fingerprint != e.fingerprint
+	e.mu.RUnlock()
+	if !changed {
+		return
+	}
+	if err := e.Reload(); err != nil {
+		e.logger.Error("error reloading template files", zap.String("dir", e.dir), zap.Error(err))
+	}
+}
+
+// Reload reads every template file again. A file that can't be read or
+// parsed keeps its previous version, if it had one; removed files are dropped.
+func (e *FileEngine) Reload() error {
+	fingerprint, err := e.scan()
+	if err != nil {
+		return err
+	}
+	paths, err := e.paths()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	files := make(map[string]*model.Template, len(paths))
+	templates := make(map[string]*model.Template, len(paths))
+	for _, path := range paths {
+		template, err := e.readFile(path)
+		if err != nil {
+			previous, ok := e.files[path]
+			e.logger.Error("error loading template file",
+				zap.String("file", path),
+				zap.Bool("keptPrevious", ok),
+				zap.Error(err),
+			)
+			if !ok {
+				continue
+			}
+			template = previous
+		}
+		files[path] = template
+		for _, name := range []string{path, template.Name} {
+			if other, ok := templates[name]; ok && other != template {
+				e.logger.Error("template is defined by more than one file, keeping the first",
+					zap.String("template", name),
+					zap.String("file", path),
+				)
+				continue
+			}
+			templates[name] = template
+		}
+	}
+
+	e.files, e.templates, e.fingerprint = files, templates, fingerprint
+	e.logger.Info("loaded template files", zap.String("dir", e.dir), zap.Int("templates", len(templates)))
+	return nil
+}
+
+// paths returns the template files under the directory, relative to it and
+// with forward slashes, in lexical order
+func (e *FileEngine) paths() ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(e.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isEngineFile(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(e.dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template files from %s: %w", e.dir, err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// scan returns a fingerprint of the template files' paths, sizes and
+// modification times, which changes whenever a file is added, edited or removed
+func (e *FileEngine) scan() (string, error) {
+	paths, err := e.paths()
+	if err != nil {
+		return "", err
+	}
+	var fingerprint strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(filepath.Join(e.dir, filepath.FromSlash(path)))
+		if err != nil {
+			return "", fmt.Errorf("failed to read template file %s: %w", path, err)
+		}
+		fmt.Fprintf(&fingerprint, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return fingerprint.String(), nil
+}
+
+// readFile parses the template file at path under the directory
+func (e *FileEngine) readFile(path string) (*model.Template, error) {
+	content, err := os.ReadFile(filepath.Join(e.dir, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	if strings.HasPrefix(text, "---") {
+		return model.ParseTemplateFile(path, text)
+	}
+
+	template := model.NewTemplate(path, "", "", text)
+	if err := template.ValidateContent(); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// find returns the template with the name, or with the name and a template
+// file extension, or nil if there is none
+func (e *FileEngine) find(name string) *model.Template {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if template, ok := e.templates[name]; ok {
+		return template
+	}
+	for _, extension := range engineFileExtensions {
+		if template, ok := e.templates[name+extension]; ok {
+			return template
+		}
+	}
+	return nil
+}
+
+// ProcessTemplate renders the template with data, including the partial
+// templates it names; see model.Template.RenderWithIncludes
+func (e *FileEngine) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	template := e.find(templateName)
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
+
+	content, err := template.RenderWithIncludes(ctx, data, func(name string) (*model.Template, error) {
+		return e.find(name), nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error rendering template %s: %w", templateName, err)
+	}
+	return content, nil
+}
+
+// GetTemplate returns the content of the template's translation into locale,
+// named with the locale before the extension ("welcome.fr.html" for
+// "welcome.html" in French), or of the template itself when it has none
+func (e *FileEngine) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	template := e.findLocalized(templateName, locale)
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
+	return template.Content, nil
+}
+
+// findLocalized returns the template with the name translated into locale,
+// or with the name if there is no translation, or nil if there is neither
+func (e *FileEngine) findLocalized(name, locale string) *model.Template {
+	if locale == "" {
+		return e.find(name)
+	}
+	for _, extension := range engineFileExtensions {
+		if base, found := strings.CutSuffix(name, extension); found {
+			if template := e.find(base + "." + locale + extension); template != nil {
+				return template
+			}
+			return e.find(name)
+		}
+	}
+	// Without an extension, find tries each of them
+	if template := e.find(name + "." + locale); template != nil {
+		return template
+	}
+	return e.find(name)
+}
+
+// isEngineFile reports whether path has an extension a FileEngine renders
+func isEngineFile(path string) bool {
+	for _, extension := range engineFileExtensions {
+		if strings.EqualFold(filepath.Ext(path), extension) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var _ services.TemplateEngine = (*FileEngine)(nil)
+
+func TestFileEngine_Renders(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "welcome.html", `<p>Hello {{.Username}}</p>{{template "footer" .}}`)
+	writeTemplateFile(t, dir, "welcome.fr.html", `<p>Bonjour {{.Username}}</p>`)
+	writeTemplateFile(t, dir, "footer.txt", `{{define "footer"}}<small>{{.Year}}</small>{{end}}`)
+	writeTemplateFile(t, dir, "reset.html", "---\nname: password_reset\ntype: password_reset\nsubject: Reset\n---\n<a href=\"{{.Link}}\">Reset</a>")
+	writeTemplateFile(t, dir, "notes.md", `not a template`)
+
+	engine, err := NewFileEngine(dir, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Templates are found by their path, includes also without the extension
+	content, err := engine.ProcessTemplate(ctx, "welcome.html", map[string]interface{}{"Username": "<jane>", "Year": 2025})
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Hello &lt;jane&gt;</p><small>2025</small>", content)
+
+	content, err = engine.ProcessTemplate(ctx, "welcome.fr.html", map[string]interface{}{"Username": "jane"})
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Bonjour jane</p>", content)
+
+	// A front-matter name finds the template as well as its path
+	for _, name := range []string{"reset.html", "password_reset"} {
+		content, err = engine.ProcessTemplate(ctx, name, map[string]interface{}{"Link": "https://example.com/reset"})
+		require.NoError(t, err)
+		assert.Equal(t, `<a href="https://example.com/reset">Reset</a>`, content)
+	}
+
+	raw, err := engine.GetTemplate(ctx, "welcome.fr.html", "fr")
+	require.NoError(t, err)
+	assert.Equal(t, `<p>Bonjour {{.Username}}</p>`, raw)
+
+	// A locale's translation is found by the untranslated name, which is used
+	// for locales without one
+	for _, tt := range []struct{ name, locale, want string }{
+		{"welcome.html", "fr", `<p>Bonjour {{.Username}}</p>`},
+		{"welcome", "fr", `<p>Bonjour {{.Username}}</p>`},
+		{"welcome.html", "de", `<p>Hello {{.Username}}</p>{{template "footer" .}}`},
+		{"welcome.html", "", `<p>Hello {{.Username}}</p>{{template "footer" .}}`},
+	} {
+		raw, err = engine.GetTemplate(ctx, tt.name, tt.locale)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, raw, "%s in %q", tt.name, tt.locale)
+	}
+	_, err = engine.GetTemplate(ctx, "goodbye.html", "fr")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	_, err = engine.ProcessTemplate(ctx, "notes.md", nil)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	_, err = NewFileEngine(filepath.Join(dir, "missing"), zap.NewNop())
+	assert.Error(t, err)
+}
+
+// TestFileEngine_HotReload covers polling, which Run falls back to when the
+// directory can't be watched
+func TestFileEngine_HotReload(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "welcome.html", `<p>Hello {{.Username}}</p>`)
+	writeTemplateFile(t, dir, "goodbye.html", `<p>Bye</p>`)
+
+	engine, err := NewFileEngine(dir, zap.NewNop())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.poll(ctx, 10*time.Millisecond)
+
+	render := func(name string) string {
+		content, err := engine.ProcessTemplate(context.Background(), name, map[string]interface{}{"Username": "jane"})
+		if err != nil {
+			return err.Error()
+		}
+		return content
+	}
+
+	// An edit is picked up without a restart
+	writeTemplateFile(t, dir, "welcome.html", `<p>Welcome aboard, {{.Username}}</p>`)
+	assert.Eventually(t, func() bool { return render("welcome.html") == "<p>Welcome aboard, jane</p>" }, time.Second, 5*time.Millisecond)
+
+	// A file that no longer parses keeps its previous version, while other
+	// changes are still picked up
+	writeTemplateFile(t, dir, "welcome.html", `<p>Hello {{.Username</p>`)
+	writeTemplateFile(t, dir, "new.html", `<p>New</p>`)
+	assert.Eventually(t, func() bool { return render("new.html") == "<p>New</p>" }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "<p>Welcome aboard, jane</p>", render("welcome.html"))
+
+	// Removed files are dropped
+	require.NoError(t, os.Remove(filepath.Join(dir, "goodbye.html")))
+	assert.Eventually(t, func() bool { return render("goodbye.html") == "template not found: goodbye.html" }, time.Second, 5*time.Millisecond)
+}
+
+func TestFileEngine_WatchesForChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "welcome.html", `<p>Hello {{.Username}}</p>`)
+
+	engine, err := NewFileEngine(dir, zap.NewNop())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Polling would take an hour, so changes are seen through the watcher
+	go engine.Run(ctx, time.Hour)
+
+	render := func(name string) string {
+		content, err := engine.ProcessTemplate(context.Background(), name, map[string]interface{}{"Username": "jane"})
+		if err != nil {
+			return err.Error()
+		}
+		return content
+	}
+
+	assert.Eventually(t, func() bool {
+		// Until the watcher is set up, writes may go unnoticed, so keep writing
+		if err := os.WriteFile(filepath.Join(dir, "welcome.html"), []byte(`<p>Welcome aboard, {{.Username}}</p>`), 0o644); err != nil {
+			return false
+		}
+		return render("welcome.html") == "<p>Welcome aboard, jane</p>"
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Files in directories created after Run started are picked up too
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "billing"), 0o755))
+	writeTemplateFile(t, dir, "billing/invoice.html", `<p>Invoice</p>`)
+	assert.Eventually(t, func() bool { return render("billing/invoice.html") == "<p>Invoice</p>" }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "welcome.html")))
+	assert.Eventually(t, func() bool { return render("welcome.html") == "template not found: welcome.html" }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,157 @@
+// Package template loads notification templates kept as files, such as
+// templates versioned in a git repository, into the template repository or
+// renders them directly, and picks between the variants of a template type
+// being A/B tested.
+package template
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"go.uber.org/zap"
+)
+
+// templateFileExtension is the extension of the files the loader reads
+const templateFileExtension = ".html"
+
+// Loader syncs the .html template files under a directory into the template
+// repository. Each file is a front-matter block and a body, see
+// model.ParseTemplateFile. New templates are created; changed templates are
+// updated by name, which keeps their previous version in the template's history.
+type Loader struct {
+	repo   repository.TemplateRepository
+	dir    string
+	logger *zap.Logger
+
+	mu sync.Mutex // Serializes syncs so two can't create the same template
+}
+
+// NewLoader creates a loader for the templates under dir
+func NewLoader(repo repository.TemplateRepository, dir string, logger *zap.Logger) *Loader {
+	return &Loader{repo: repo, dir: dir, logger: logger}
+}
+
+// Sync upserts every template file under the directory for the context's
+// tenant. Files that can't be parsed or saved are reported as failed without
+// stopping the sync; it only stops if the directory or the repository can't be read.
+func (l *Loader) Sync(ctx context.Context) (*model.TemplateSyncResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := model.NewTemplateSyncResult()
+	files := make(map[string]string) // Template name to the file it was read from
+
+	err := filepath.WalkDir(l.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(path), templateFileExtension) {
+			return nil
+		}
+
+		file, _ := filepath.Rel(l.dir, path)
+		template, err := l.readFile(path)
+		if err == nil {
+			if other, ok := files[template.Name]; ok {
+				err = fmt.Errorf("template %s is already defined by %s", template.Name, other)
+			}
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, model.TemplateSyncError{File: file, Error: err.Error()})
+			return nil
+		}
+		files[template.Name] = file
+
+		return l.upsert(ctx, file, template, result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync templates from %s: %w", l.dir, err)
+	}
+
+	l.logger.Info("synced templates",
+		zap.String("dir", l.dir),
+		zap.Int("created", len(result.Created)),
+		zap.Int("updated", len(result.Updated)),
+		zap.Int("unchanged", len(result.Unchanged)),
+		zap.Int("failed", len(result.Failed)),
+	)
+	return result, nil
+}
+
+// readFile parses the template file at path, named after the file unless its front-matter says otherwise
+func (l *Loader) readFile(path string) (*model.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return model.ParseTemplateFile(name, string(content))
+}
+
+// upsert saves template unless the repository already has the same content
+// under its name. A template with the name is updated in place, keeping any
+// metadata the file doesn't set.
+func (l *Loader) upsert(ctx context.Context, file string, template *model.Template, result *model.TemplateSyncResult) error {
+	existing, err := l.repo.FindByName(ctx, template.Name)
+	if err != nil {
+		return fmt.Errorf("failed to find template %s: %w", template.Name, err)
+	}
+
+	if existing != nil {
+		if existing.SameContent(template) {
+			result.Unchanged = append(result.Unchanged, template.Name)
+			return nil
+		}
+
+		metadata := make(map[string]string, len(existing.Metadata))
+		for key, value := range existing.Metadata {
+			metadata[key] = value
+		}
+		for _, key := range []string{model.MetadataTemplateLocale, model.MetadataTemplateWeight} {
+			if value, ok := template.Metadata[key]; ok {
+				metadata[key] = value
+			} else {
+				delete(metadata, key)
+			}
+		}
+		template.Metadata = metadata
+	}
+
+	// Upserting by name, rather than saving when no active template was found,
+	// updates a template that exists but is inactive instead of failing on its name
+	created, err := l.repo.Upsert(ctx, template)
+	if err != nil {
+		return l.saveFailed(file, err, result)
+	}
+
+	if created {
+		l.logger.Info("created template from file", zap.String("template", template.Name), zap.String("file", file))
+		result.Created = append(result.Created, template.Name)
+		return nil
+	}
+	l.logger.Info("updated template from file",
+		zap.String("template", template.Name),
+		zap.String("file", file),
+		zap.Int("version", template.Version),
+	)
+	result.Updated = append(result.Updated, template.Name)
+	return nil
+}
+
+// saveFailed reports a template the repository refused as failed, and stops
+// the sync on any other error
+func (l *Loader) saveFailed(file string, err error, result *model.TemplateSyncResult) error {
+	if errors.Is(err, model.ErrValidation) || errors.Is(err, model.ErrConflict) {
+		result.Failed = append(result.Failed, model.TemplateSyncError{File: file, Error: err.Error()})
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTemplateRepository keeps templates in memory by name
+type fakeTemplateRepository struct {
+	repository.TemplateRepository
+	templates map[string]*model.Template
+}
+
+func (r *fakeTemplateRepository) FindByName(ctx context.Context, name string) (*model.Template, error) {
+	if template := r.templates[name]; template != nil && template.IsActive {
+		return template, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeTemplateRepository) Upsert(ctx context.Context, template *model.Template) (bool, error) {
+	existing, ok := r.templates[template.Name]
+	if ok {
+		template.ID = existing.ID
+		template.IsActive = existing.IsActive
+		template.Version = existing.Version + 1
+	}
+	r.templates[template.Name] = template
+	return !ok, nil
+}
+
+func writeTemplateFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestLoader_Sync(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "welcome.html", "---\ntype: welcome_email\nsubject: Welcome\nvariables: [Username]\n---\nHello {{.Username}}\n")
+	writeTemplateFile(t, dir, "auth/password_reset.html", "---\ntype: password_reset\nsubject: Reset\nvariables: [Link]\nlocale: en\n---\n{{.Link}}\n")
+	writeTemplateFile(t, dir, "README.md", "Not a template")
+
+	repo := &fakeTemplateRepository{templates: make(map[string]*model.Template)}
+	loader := NewLoader(repo, dir, zap.NewNop())
+
+	t.Run("creates new templates", func(t *testing.T) {
+		result, err := loader.Sync(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"password_reset", "welcome"}, result.Created)
+		assert.Empty(t, result.Updated)
+		assert.Empty(t, result.Failed)
+		assert.Equal(t, "Hello {{.Username}}", repo.templates["welcome"].Content)
+		assert.Equal(t, "en", repo.templates["password_reset"].Metadata[model.MetadataTemplateLocale])
+	})
+
+	t.Run("leaves unchanged templates alone", func(t *testing.T) {
+		result, err := loader.Sync(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, result.Created)
+		assert.Empty(t, result.Updated)
+		assert.Equal(t, []string{"password_reset", "welcome"}, result.Unchanged)
+	})
+
+	t.Run("updates changed templates as a new version", func(t *testing.T) {
+		writeTemplateFile(t, dir, "welcome.html", "---\ntype: welcome_email\nsubject: Welcome aboard\nvariables: [Username]\n---\nHello {{.Username}}\n")
+		id := repo.templates["welcome"].ID
+
+		result, err := loader.Sync(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"welcome"}, result.Updated)
+		assert.Equal(t, []string{"password_reset"}, result.Unchanged)
+		assert.Equal(t, id, repo.templates["welcome"].ID)
+		assert.Equal(t, "Welcome aboard", repo.templates["welcome"].Subject)
+		assert.Equal(t, 2, repo.templates["welcome"].Version)
+	})
+
+	t.Run("updates inactive templates instead of failing on their name", func(t *testing.T) {
+		repo.templates["password_reset"].IsActive = false
+		id := repo.templates["password_reset"].ID
+
+		result, err := loader.Sync(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, result.Created)
+		assert.Equal(t, []string{"password_reset"}, result.Updated)
+		assert.Empty(t, result.Failed)
+		assert.Equal(t, id, repo.templates["password_reset"].ID)
+		assert.False(t, repo.templates["password_reset"].IsActive)
+	})
+
+	t.Run("reports invalid and duplicate files", func(t *testing.T) {
+		writeTemplateFile(t, dir, "broken.html", "---\ntype: welcome_email\nsubject: Broken\n---\nHello {{.Username")
+		writeTemplateFile(t, dir, "welcome_copy.html", "---\nname: welcome\ntype: welcome_email\nsubject: Copy\n---\nHello\n")
+
+		result, err := loader.Sync(context.Background())
+		require.NoError(t, err)
+		require.Len(t, result.Failed, 2)
+		assert.Equal(t, "broken.html", result.Failed[0].File)
+		assert.Equal(t, model.TemplateSyncError{File: "welcome_copy.html", Error: "template welcome is already defined by welcome.html"}, result.Failed[1])
+		assert.Equal(t, "Welcome aboard", repo.templates["welcome"].Subject)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := NewLoader(repo, filepath.Join(dir, "missing"), zap.NewNop()).Sync(context.Background())
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
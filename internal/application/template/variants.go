@@ -0,0 +1,37 @@
+package template
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+)
+
+// VariantSelector implements services.TemplateVariantSelector. Variant selection is
+// opt-in per template type: only the types it is configured with are A/B tested,
+// every other type keeps rendering its default template.
+type VariantSelector struct {
+	repo       repository.TemplateRepository
+	selections map[model.TemplateType]model.VariantSelection
+}
+
+// NewVariantSelector creates a selector picking between the active templates of
+// the given types, each in its own way
+func NewVariantSelector(repo repository.TemplateRepository, selections map[model.TemplateType]model.VariantSelection) *VariantSelector {
+	return &VariantSelector{repo: repo, selections: selections}
+}
+
+// SelectVariant picks one of the type's active templates for the recipient; see model.SelectVariant
+func (s *VariantSelector) SelectVariant(ctx context.Context, templateType model.TemplateType, recipient string) (*model.Template, error) {
+	selection, ok := s.selections[templateType]
+	if !ok {
+		return nil, nil
+	}
+
+	variants, err := s.repo.FindActiveByType(ctx, templateType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s variants: %w", templateType, err)
+	}
+	return model.SelectVariant(variants, recipient, selection), nil
+}
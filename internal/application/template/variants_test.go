@@ -0,0 +1,61 @@
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// variantTemplateRepository lists its templates as the active ones of their type
+type variantTemplateRepository struct {
+	fakeTemplateRepository
+}
+
+func (r *variantTemplateRepository) FindActiveByType(ctx context.Context, templateType model.TemplateType) ([]*model.Template, error) {
+	var templates []*model.Template
+	for _, template := range r.templates {
+		if template.Type == templateType && template.IsActive {
+			templates = append(templates, template)
+		}
+	}
+	return templates, nil
+}
+
+func TestVariantSelector_SelectVariant(t *testing.T) {
+	a := model.NewTemplate("welcome_a", model.WelcomeEmail, "Welcome", "Hello")
+	b := model.NewTemplate("welcome_b", model.WelcomeEmail, "You're in", "Hello")
+	reset := model.NewTemplate("password_reset", model.PasswordReset, "Reset", "{{.ResetLink}}")
+	repo := &variantTemplateRepository{fakeTemplateRepository{templates: map[string]*model.Template{
+		a.Name: a, b.Name: b, reset.Name: reset,
+	}}}
+
+	selector := NewVariantSelector(repo, map[model.TemplateType]model.VariantSelection{
+		model.WelcomeEmail: model.VariantByRecipient,
+	})
+	ctx := context.Background()
+
+	t.Run("picks the recipient's variant", func(t *testing.T) {
+		variant, err := selector.SelectVariant(ctx, model.WelcomeEmail, "user@example.com")
+		require.NoError(t, err)
+		require.NotNil(t, variant)
+		assert.Equal(t, model.SelectVariant([]*model.Template{a, b}, "user@example.com", model.VariantByRecipient), variant)
+	})
+
+	t.Run("types without variants enabled", func(t *testing.T) {
+		variant, err := selector.SelectVariant(ctx, model.PasswordReset, "user@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, variant)
+	})
+
+	t.Run("enabled type without templates", func(t *testing.T) {
+		empty := NewVariantSelector(repo, map[model.TemplateType]model.VariantSelection{
+			model.AccountActivation: model.VariantByWeight,
+		})
+		variant, err := empty.SelectVariant(ctx, model.AccountActivation, "user@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, variant)
+	})
+}
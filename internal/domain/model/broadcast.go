@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MetadataBroadcastID is the metadata key linking a notification to the broadcast it was sent for
+const MetadataBroadcastID = "broadcast_id"
+
+// MaxBroadcastIDLength is the longest broadcast ID accepted
+const MaxBroadcastIDLength = 128
+
+// BroadcastProgress records how far the fan-out of a broadcast got, so a
+// consumer restarted mid-broadcast carries on from where it stopped instead of
+// notifying every recipient again
+type BroadcastProgress struct {
+	TenantID    string `json:"tenant_id"`
+	BroadcastID string `json:"broadcast_id"`
+	// Cursor is where the next page of recipients starts: an offset into the
+	// event's recipient list, or the segment resolver's cursor
+	Cursor    string    `json:"cursor,omitempty"`
+	Enqueued  int       `json:"enqueued"` // Notifications queued for delivery so far
+	Skipped   int       `json:"skipped"`  // Recipients left out because their notification was invalid
+	Completed bool      `json:"completed"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewBroadcastProgress creates the progress of a broadcast that hasn't enqueued anything yet
+func NewBroadcastProgress(broadcastID string) *BroadcastProgress {
+	now := time.Now()
+	return &BroadcastProgress{
+		BroadcastID: broadcastID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Advance records a page of the broadcast: enqueued notifications were queued,
+// skipped recipients left out, and the next page starts at cursor, or there is
+// none if cursor is empty
+func (p *BroadcastProgress) Advance(cursor string, enqueued, skipped int) {
+	p.Cursor = cursor
+	p.Enqueued += enqueued
+	p.Skipped += skipped
+	p.Completed = cursor == ""
+	p.UpdatedAt = time.Now()
+}
+
+// ErrInvalidBroadcast is returned for a broadcast event that can't be fanned out
+type ErrInvalidBroadcast struct {
+	BroadcastID string
+	Message     string
+}
+
+func (e ErrInvalidBroadcast) Error() string {
+	if e.BroadcastID == "" {
+		return fmt.Sprintf("invalid broadcast: %s", e.Message)
+	}
+	return fmt.Sprintf("invalid broadcast %s: %s", e.BroadcastID, e.Message)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrInvalidBroadcast) Is(target error) bool { return target == ErrValidation }
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// SMS segment sizes, in GSM-7 septets or UCS-2 code units. Messages longer than
+// a single segment are split, and each part loses room to the concatenation header.
+const (
+	smsGSMSingleSegment  = 160
+	smsGSMMultiSegment   = 153
+	smsUCS2SingleSegment = 70
+	smsUCS2MultiSegment  = 67
+)
+
+// gsmBasicCharset is the GSM 03.38 default alphabet; each character is one septet
+const gsmBasicCharset = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsmExtendedCharset holds the characters sent as an escape plus a septet
+const gsmExtendedCharset = "\f^{}\\[~]|€"
+
+// ErrContentTooLong is returned when a notification's content exceeds the
+// limit configured for its channel
+type ErrContentTooLong struct {
+	Type      NotificationType
+	Length    int
+	MaxLength int
+}
+
+func (e ErrContentTooLong) Error() string {
+	return fmt.Sprintf("%s content is %d characters, the limit is %d", e.Type, e.Length, e.MaxLength)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrContentTooLong) Is(target error) bool { return target == ErrValidation }
+
+// ErrTooManySegments is returned when an SMS would be split into more segments
+// than allowed
+type ErrTooManySegments struct {
+	Segments    int
+	MaxSegments int
+}
+
+func (e ErrTooManySegments) Error() string {
+	return fmt.Sprintf("SMS content is %d segments, the limit is %d", e.Segments, e.MaxSegments)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrTooManySegments) Is(target error) bool { return target == ErrValidation }
+
+// SMSEncoding is the character set an SMS is sent in
+type SMSEncoding string
+
+// SMS encodings
+const (
+	SMSEncodingGSM7 SMSEncoding = "GSM-7"
+	SMSEncodingUCS2 SMSEncoding = "UCS-2"
+)
+
+// Metadata keys recording how an SMS notification is split
+const (
+	MetadataSMSEncoding = "sms_encoding"
+	MetadataSMSSegments = "sms_segments"
+)
+
+// SMSSegmentation describes how an SMS is encoded and how many segments it is
+// billed and delivered as
+type SMSSegmentation struct {
+	Encoding SMSEncoding
+	Segments int
+}
+
+// SegmentSMS works out the encoding and segment count of an SMS. Content that
+// fits the GSM-7 alphabet uses 160-character segments; anything else is sent as
+// UCS-2 with 70-character segments.
+func SegmentSMS(content string) SMSSegmentation {
+	encoding, single, multi := SMSEncodingGSM7, smsGSMSingleSegment, smsGSMMultiSegment
+	units, ok := gsmSeptets(content)
+	if !ok {
+		encoding, single, multi = SMSEncodingUCS2, smsUCS2SingleSegment, smsUCS2MultiSegment
+		units = len(utf16.Encode([]rune(content)))
+	}
+
+	segments := 1
+	if units > single {
+		segments = (units + multi - 1) / multi
+	}
+	return SMSSegmentation{Encoding: encoding, Segments: segments}
+}
+
+// gsmSeptets returns the number of septets content takes in GSM-7, or false if
+// it has characters outside the GSM alphabet
+func gsmSeptets(content string) (int, bool) {
+	septets := 0
+	for _, r := range content {
+		switch {
+		case strings.ContainsRune(gsmBasicCharset, r):
+			septets++
+		case strings.ContainsRune(gsmExtendedCharset, r):
+			septets += 2
+		default:
+			return 0, false
+		}
+	}
+	return septets, true
+}
@@ -0,0 +1,35 @@
+package model
+
+import "context"
+
+// MaxCorrelationIDLength bounds the correlation IDs accepted from callers
+const MaxCorrelationIDLength = 128
+
+type correlationIDContextKey struct{}
+
+// ContextWithCorrelationID returns a copy of ctx carrying the correlation ID of
+// the request or event being handled
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID ctx carries, or "" if it carries none
+func CorrelationIDFromContext(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return correlationID
+}
+
+// IsValidCorrelationID reports whether a caller-supplied correlation ID can be
+// stored and logged as is: non-empty, at most MaxCorrelationIDLength bytes and
+// made of printable ASCII
+func IsValidCorrelationID(correlationID string) bool {
+	if correlationID == "" || len(correlationID) > MaxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(correlationID); i++ {
+		if correlationID[i] < 0x21 || correlationID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
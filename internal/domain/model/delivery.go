@@ -0,0 +1,93 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidSignature is returned when a provider webhook's signature does not
+// verify, so its payload cannot be trusted
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// DeliveryEventType is what a provider reported happening to a message
+type DeliveryEventType string
+
+// Delivery event types
+const (
+	DeliveryEventDelivered  DeliveryEventType = "delivered"
+	DeliveryEventBounced    DeliveryEventType = "bounced"
+	DeliveryEventOpened     DeliveryEventType = "opened"
+	DeliveryEventClicked    DeliveryEventType = "clicked"
+	DeliveryEventComplained DeliveryEventType = "complained"
+)
+
+// Metadata keys recording engagement reported by delivery webhooks
+const (
+	MetadataOpenCount      = "open_count"
+	MetadataLastOpenedAt   = "last_opened_at"
+	MetadataClickCount     = "click_count"
+	MetadataLastClickedAt  = "last_clicked_at"
+	MetadataLastClickedURL = "last_clicked_url"
+	MetadataComplainedAt   = "complained_at"
+)
+
+// DeliveryEvent is a delivery receipt parsed from a provider webhook
+type DeliveryEvent struct {
+	Provider          string
+	ProviderMessageID string
+	Type              DeliveryEventType
+	Reason            string // Why the message bounced, if it did
+	Permanent         bool   // Whether a bounce is hard, so the address won't accept mail later either
+	URL               string // The link that was clicked, if any
+	OccurredAt        time.Time
+}
+
+// SuppressionReason reports whether the event means its recipient must not be
+// emailed again, and why: hard bounces and spam complaints do
+func (e DeliveryEvent) SuppressionReason() (SuppressionReason, bool) {
+	switch {
+	case e.Type == DeliveryEventBounced && e.Permanent:
+		return SuppressionHardBounce, true
+	case e.Type == DeliveryEventComplained:
+		return SuppressionComplaint, true
+	}
+	return "", false
+}
+
+// ApplyDeliveryEvent records a delivery receipt: deliveries and bounces advance
+// a sent notification's status, opens and clicks are counted in the metadata,
+// and complaints are stamped there. Duplicate or out-of-order receipts, such as
+// a delivery reported after a bounce, leave the status alone.
+func (n *Notification) ApplyDeliveryEvent(event DeliveryEvent) {
+	switch event.Type {
+	case DeliveryEventDelivered:
+		_ = n.TransitionTo(StatusDelivered, "")
+	case DeliveryEventBounced:
+		_ = n.TransitionTo(StatusBounced, event.Reason)
+	case DeliveryEventOpened:
+		n.recordEngagement(MetadataOpenCount, MetadataLastOpenedAt, event.OccurredAt)
+	case DeliveryEventClicked:
+		n.recordEngagement(MetadataClickCount, MetadataLastClickedAt, event.OccurredAt)
+		if event.URL != "" {
+			n.Metadata[MetadataLastClickedURL] = event.URL
+		}
+	case DeliveryEventComplained:
+		if n.Metadata == nil {
+			n.Metadata = make(map[string]string)
+		}
+		n.Metadata[MetadataComplainedAt] = event.OccurredAt.UTC().Format(time.RFC3339)
+		n.UpdatedAt = time.Now()
+	}
+}
+
+// recordEngagement increments a counter in the metadata and stamps when it last happened
+func (n *Notification) recordEngagement(countKey, atKey string, at time.Time) {
+	if n.Metadata == nil {
+		n.Metadata = make(map[string]string)
+	}
+	count, _ := strconv.Atoi(n.Metadata[countKey])
+	n.Metadata[countKey] = strconv.Itoa(count + 1)
+	n.Metadata[atKey] = at.UTC().Format(time.RFC3339)
+	n.UpdatedAt = time.Now()
+}
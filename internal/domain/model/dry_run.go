@@ -0,0 +1,17 @@
+package model
+
+import "context"
+
+type dryRunContextKey struct{}
+
+// ContextWithDryRun returns a copy of ctx in which notifications are recorded
+// as they would be sent, without calling any provider
+func ContextWithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunContextKey{}, true)
+}
+
+// IsDryRun reports whether ctx was marked with ContextWithDryRun
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunContextKey{}).(bool)
+	return dryRun
+}
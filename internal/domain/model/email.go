@@ -0,0 +1,115 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EmailContentType is the format an email's content is sent in
+type EmailContentType string
+
+const (
+	// EmailContentHTML sends the content as HTML; it is the default
+	EmailContentHTML EmailContentType = "text/html"
+	// EmailContentText sends the content as plain text
+	EmailContentText EmailContentType = "text/plain"
+)
+
+// MaxEmailCC caps how many addresses a single email may be copied to
+const MaxEmailCC = 10
+
+const (
+	// MaxInlineImages caps how many images a single email may embed
+	MaxInlineImages = 10
+	// MaxInlineImagesSize caps the combined size in bytes of an email's inline
+	// images. Base64-encoded in a request, they still fit the default 1 MiB body.
+	MaxInlineImagesSize = 512 << 10
+)
+
+// InlineImage is an image embedded in an HTML email, which its content
+// references as cid:<ContentID>, e.g. <img src="cid:logo">. Embedded images
+// are shown by mail clients that block images linked from other sites.
+type InlineImage struct {
+	ContentID   string `json:"content_id"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// contentIDPattern matches the content IDs images can be embedded under. They
+// are kept to characters that need no quoting in a header or escaping in a URL.
+var contentIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidContentID reports whether id can name an inline image
+func ValidContentID(id string) bool {
+	return contentIDPattern.MatchString(id)
+}
+
+// validateInlineImages checks that images have distinct valid content IDs and
+// image content types, and fit the count and size limits
+func validateInlineImages(images []InlineImage) error {
+	if len(images) > MaxInlineImages {
+		return ErrInvalidNotification{Message: fmt.Sprintf("an email can embed at most %d images", MaxInlineImages)}
+	}
+
+	size := 0
+	seen := make(map[string]bool, len(images))
+	for _, image := range images {
+		if !ValidContentID(image.ContentID) {
+			return ErrInvalidNotification{Message: fmt.Sprintf("invalid inline image content ID: %q", image.ContentID)}
+		}
+		if seen[image.ContentID] {
+			return ErrInvalidNotification{Message: fmt.Sprintf("duplicate inline image content ID: %s", image.ContentID)}
+		}
+		seen[image.ContentID] = true
+		if !strings.HasPrefix(image.ContentType, "image/") {
+			return ErrInvalidNotification{Message: fmt.Sprintf("inline image %s must have an image content type, not %q", image.ContentID, image.ContentType)}
+		}
+		if len(image.Data) == 0 {
+			return ErrInvalidNotification{Message: fmt.Sprintf("inline image %s is empty", image.ContentID)}
+		}
+		size += len(image.Data)
+	}
+	if size > MaxInlineImagesSize {
+		return ErrInvalidNotification{Message: fmt.Sprintf("inline images are %d bytes, over the limit of %d", size, MaxInlineImagesSize)}
+	}
+	return nil
+}
+
+// IsValid reports whether t is a supported content type; empty means HTML
+func (t EmailContentType) IsValid() bool {
+	switch t {
+	case "", EmailContentHTML, EmailContentText:
+		return true
+	}
+	return false
+}
+
+// EmailOptions control how an email is sent beyond its recipient, subject and content
+type EmailOptions struct {
+	ContentType EmailContentType
+	// CC lists the addresses copied on the email
+	CC []string
+	// InlineImages are embedded in the email for its HTML content to reference
+	InlineImages []InlineImage
+	// Threading sets the headers mail clients thread the email by
+	Threading EmailThreading
+}
+
+// IsZero reports whether the options are the defaults every email provider
+// sends with: HTML content, nobody copied, no embedded images and no threading
+func (o EmailOptions) IsZero() bool {
+	return (o.ContentType == "" || o.ContentType == EmailContentHTML) && len(o.CC) == 0 && len(o.InlineImages) == 0 && o.Threading.IsZero()
+}
+
+// ErrEmailOptionsUnsupported is returned when a notification asks for a content
+// type, CC addresses, inline images or a thread but the email provider can't
+// send with them
+type ErrEmailOptionsUnsupported struct{}
+
+func (e ErrEmailOptionsUnsupported) Error() string {
+	return "the email provider doesn't support content types, CC addresses, inline images or threads"
+}
+
+// Is reports the error as ErrValidation
+func (e ErrEmailOptionsUnsupported) Is(target error) bool { return target == ErrValidation }
@@ -0,0 +1,49 @@
+package model
+
+import "github.com/google/uuid"
+
+// MaxThreadIDLength caps the length of the ID grouping emails into a thread
+const MaxThreadIDLength = 128
+
+// MaxThreadReferences caps how many of a thread's earlier emails an email's
+// References header lists. The thread's first email is always kept, as RFC
+// 5322 suggests, with the most recent ones.
+const MaxThreadReferences = 20
+
+// EmailThreading holds the headers mail clients such as Gmail and Outlook
+// thread an email by. Message IDs are kept without their angle brackets.
+type EmailThreading struct {
+	// MessageID is the email's own Message-ID
+	MessageID string
+	// InReplyTo is the Message-ID of the thread's previous email
+	InReplyTo string
+	// References lists the Message-IDs of the thread's earlier emails, oldest first
+	References []string
+}
+
+// IsZero reports whether the email isn't threaded
+func (t EmailThreading) IsZero() bool {
+	return t.MessageID == "" && t.InReplyTo == "" && len(t.References) == 0
+}
+
+// NewEmailMessageID returns the Message-ID of the email sent for the
+// notification with the given ID, which makes it unique under domain
+func NewEmailMessageID(id uuid.UUID, domain string) string {
+	return id.String() + "@" + domain
+}
+
+// ThreadEmail returns the threading headers of the email with messageID that
+// follows earlier, the Message-IDs of the thread's earlier emails oldest first
+func ThreadEmail(messageID string, earlier []string) EmailThreading {
+	threading := EmailThreading{MessageID: messageID}
+	if len(earlier) == 0 {
+		return threading
+	}
+
+	threading.InReplyTo = earlier[len(earlier)-1]
+	if len(earlier) > MaxThreadReferences {
+		earlier = append([]string{earlier[0]}, earlier[len(earlier)-MaxThreadReferences+1:]...)
+	}
+	threading.References = earlier
+	return threading
+}
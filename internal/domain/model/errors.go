@@ -0,0 +1,29 @@
+package model
+
+import "errors"
+
+// Error categories shared by the domain's typed errors. Callers classify a
+// failure with errors.Is, e.g. errors.Is(err, ErrNotFound), without knowing
+// which concrete error was returned.
+var (
+	// ErrNotFound is matched by errors about a missing notification or template
+	ErrNotFound = errors.New("not found")
+
+	// ErrValidation is matched by errors about invalid input
+	ErrValidation = errors.New("validation failed")
+
+	// ErrConflict is matched by errors about a request the resource's current state doesn't allow
+	ErrConflict = errors.New("conflict")
+
+	// ErrProviderUnavailable is matched by errors from a delivery provider that
+	// may succeed if the notification is sent again later
+	ErrProviderUnavailable = errors.New("provider unavailable")
+
+	// ErrRejected is matched by errors about a message a provider refused to
+	// deliver; sending it again will fail the same way
+	ErrRejected = errors.New("rejected by provider")
+
+	// ErrThrottled is matched by errors about a request refused because the
+	// service is sending too much; it may succeed once the load drops
+	ErrThrottled = errors.New("throttled")
+)
@@ -0,0 +1,20 @@
+package model
+
+import "fmt"
+
+// ErrMalformedEvent is returned for a user event whose payload can't be
+// decoded, as opposed to a valid event that failed to be handled. Handling it
+// again will fail the same way.
+type ErrMalformedEvent struct {
+	EventType string
+	Err       error
+}
+
+func (e ErrMalformedEvent) Error() string {
+	return fmt.Sprintf("malformed %s event: %v", e.EventType, e.Err)
+}
+
+func (e ErrMalformedEvent) Unwrap() error { return e.Err }
+
+// Is reports the error as ErrValidation
+func (e ErrMalformedEvent) Is(target error) bool { return target == ErrValidation }
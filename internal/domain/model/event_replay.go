@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventReplayRange selects the events of a topic to handle again. Each
+// partition is read from FromOffset, or the first event at or after Since,
+// through ToOffset, or up to the first event at or after Until. Without a
+// start, a partition is read from its oldest retained event. Without an end,
+// it is read up to the newest event when the replay started.
+type EventReplayRange struct {
+	Topic      string
+	Partitions []int32 // Partitions to read; empty reads every partition
+	FromOffset *int64  // First offset replayed
+	ToOffset   *int64  // Last offset replayed
+	Since      time.Time
+	Until      time.Time
+}
+
+// Validate checks the range names a topic and doesn't mix offsets and times
+func (r EventReplayRange) Validate() error {
+	switch {
+	case r.Topic == "":
+		return ErrInvalidEventReplay{Message: "topic is required"}
+	case r.FromOffset != nil && !r.Since.IsZero():
+		return ErrInvalidEventReplay{Message: "from_offset and since can't be combined"}
+	case r.ToOffset != nil && !r.Until.IsZero():
+		return ErrInvalidEventReplay{Message: "to_offset and until can't be combined"}
+	case r.FromOffset != nil && *r.FromOffset < 0, r.ToOffset != nil && *r.ToOffset < 0:
+		return ErrInvalidEventReplay{Message: "offsets can't be negative"}
+	case r.FromOffset != nil && r.ToOffset != nil && *r.ToOffset < *r.FromOffset:
+		return ErrInvalidEventReplay{Message: "to_offset is before from_offset"}
+	case !r.Since.IsZero() && !r.Until.IsZero() && !r.Until.After(r.Since):
+		return ErrInvalidEventReplay{Message: "until must be after since"}
+	}
+	return nil
+}
+
+// EventReplayOptions controls how replayed events are handled
+type EventReplayOptions struct {
+	// DryRun counts the events that would be handled without handling them
+	DryRun bool
+	// Force handles events without a correlation ID, which can't be checked
+	// for notifications they already produced, instead of skipping them
+	Force bool
+}
+
+// EventReplayResult counts what a replay did with the events it read
+type EventReplayResult struct {
+	// Replayed events were handled again, or would have been in a dry run
+	Replayed int `json:"replayed"`
+	// AlreadySent events were skipped because they already produced a notification
+	AlreadySent int `json:"already_sent"`
+	// Unguarded events were skipped because they have no correlation ID to
+	// check for earlier notifications with
+	Unguarded int `json:"unguarded"`
+	// Failed events couldn't be handled; the errors are logged
+	Failed int `json:"failed"`
+}
+
+// ErrInvalidEventReplay is returned for an event replay range that can't be read
+type ErrInvalidEventReplay struct {
+	Message string
+}
+
+func (e ErrInvalidEventReplay) Error() string {
+	return fmt.Sprintf("invalid event replay: %s", e.Message)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrInvalidEventReplay) Is(target error) bool { return target == ErrValidation }
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventReplayRange_Validate(t *testing.T) {
+	offset := func(offset int64) *int64 { return &offset }
+	since := time.Date(2025, 1, 25, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		rng     EventReplayRange
+		wantErr string
+	}{
+		{"whole topic", EventReplayRange{Topic: "user-events"}, ""},
+		{"offsets", EventReplayRange{Topic: "user-events", Partitions: []int32{0}, FromOffset: offset(10), ToOffset: offset(20)}, ""},
+		{"times", EventReplayRange{Topic: "user-events", Since: since, Until: since.Add(time.Hour)}, ""},
+		{"offset to a time", EventReplayRange{Topic: "user-events", FromOffset: offset(10), Until: since}, ""},
+		{"no topic", EventReplayRange{}, "topic is required"},
+		{"start twice", EventReplayRange{Topic: "user-events", FromOffset: offset(10), Since: since}, "from_offset and since can't be combined"},
+		{"end twice", EventReplayRange{Topic: "user-events", ToOffset: offset(10), Until: since}, "to_offset and until can't be combined"},
+		{"negative offset", EventReplayRange{Topic: "user-events", ToOffset: offset(-1)}, "offsets can't be negative"},
+		{"offsets reversed", EventReplayRange{Topic: "user-events", FromOffset: offset(20), ToOffset: offset(10)}, "to_offset is before from_offset"},
+		{"times reversed", EventReplayRange{Topic: "user-events", Since: since, Until: since}, "until must be after since"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rng.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, "invalid event replay: "+tt.wantErr)
+			assert.True(t, errors.Is(err, ErrValidation))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the locale templates are rendered in when no other is configured
+const DefaultLocale = "en"
+
+// Locales are the locales templates are available in, and the one used when
+// none of a recipient's preferred locales is available
+type Locales struct {
+	Default   string
+	Available []string // Locales other than Default templates are available in
+}
+
+// Resolve returns the available locale best matching preferred, most preferred
+// first: the first preferred locale available as is, or else in its language
+// ("fr-CA" matches "fr" and "fr" matches "fr-FR"). Locales are compared ignoring
+// case, with "_" taken as "-". Default is returned when nothing matches.
+func (l Locales) Resolve(preferred ...string) string {
+	available := append([]string{l.Default}, l.Available...)
+	for _, locale := range preferred {
+		locale = normalizeLocale(locale)
+		if locale == "" {
+			continue
+		}
+		for _, candidate := range available {
+			if normalizeLocale(candidate) == locale {
+				return candidate
+			}
+		}
+		for _, candidate := range available {
+			if localeLanguage(normalizeLocale(candidate)) == localeLanguage(locale) {
+				return candidate
+			}
+		}
+	}
+	return l.Default
+}
+
+// normalizeLocale lowercases a locale and writes its subtags separated by "-"
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// localeLanguage returns the language subtag of a normalized locale
+func localeLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
+
+// ParseAcceptLanguage returns the locales of an HTTP Accept-Language header,
+// most preferred first by quality value ("en;q=0.9,fr;q=0.8"); locales of equal
+// quality keep the header's order. The "*" wildcard, locales with q=0 and
+// malformed entries are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(part, ";")
+		locale = strings.TrimSpace(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if params = strings.TrimSpace(params); params != "" {
+			name, value, ok := strings.Cut(params, "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				continue
+			}
+			quality = parsed
+		}
+		if quality == 0 {
+			continue
+		}
+		entries = append(entries, weighted{locale: locale, quality: quality})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].quality > entries[j].quality })
+
+	locales := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		locales = append(locales, entry.locale)
+	}
+	return locales
+}
+
+type preferredLocalesContextKey struct{}
+
+// ContextWithPreferredLocales returns a copy of ctx carrying the locales the
+// caller prefers, most preferred first
+func ContextWithPreferredLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, preferredLocalesContextKey{}, locales)
+}
+
+// PreferredLocalesFromContext returns the preferred locales ctx carries, or nil if it carries none
+func PreferredLocalesFromContext(ctx context.Context) []string {
+	locales, _ := ctx.Value(preferredLocalesContextKey{}).([]string)
+	return locales
+}
+
+// TemplateLocalePreview is a template rendered with the same data in every
+// locale templates are available in, for reviewing its translations together
+type TemplateLocalePreview struct {
+	Name          string
+	DefaultLocale string
+	Rendered      map[string]string // Rendered output by locale
+	Missing       []string          // Available locales the template isn't translated into
+	Errors        map[string]string // Why a translation failed to render, by locale
+}
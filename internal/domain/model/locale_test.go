@@ -0,0 +1,61 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected []string
+	}{
+		{name: "empty", header: "", expected: []string{}},
+		{name: "single", header: "fr-CA", expected: []string{"fr-CA"}},
+		{name: "ordered by quality", header: "en;q=0.9,fr;q=0.8, de", expected: []string{"de", "en", "fr"}},
+		{name: "equal quality keeps the header order", header: "es;q=0.5, it;q=0.5", expected: []string{"es", "it"}},
+		{name: "drops the wildcard and q=0", header: "fr, *;q=0.1, en;q=0", expected: []string{"fr"}},
+		{name: "skips malformed entries", header: "fr;q=high, de;q=2, nl;level=1, pt;q=0.3", expected: []string{"pt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestLocales_Resolve(t *testing.T) {
+	locales := Locales{Default: "en", Available: []string{"fr", "de-DE", "pt_BR"}}
+
+	tests := []struct {
+		name      string
+		preferred []string
+		expected  string
+	}{
+		{name: "exact match", preferred: []string{"fr"}, expected: "fr"},
+		{name: "first available preference wins", preferred: []string{"ja", "de-DE", "fr"}, expected: "de-DE"},
+		{name: "ignores case and separators", preferred: []string{"PT-br"}, expected: "pt_BR"},
+		{name: "regional preference matches the language", preferred: []string{"fr-CA"}, expected: "fr"},
+		{name: "language preference matches a region", preferred: []string{"de"}, expected: "de-DE"},
+		{name: "an earlier preference's language beats a later exact match", preferred: []string{"en-GB", "fr"}, expected: "en"},
+		{name: "falls back to the default", preferred: []string{"ja", "ko"}, expected: "en"},
+		{name: "no preference", expected: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, locales.Resolve(tt.preferred...))
+		})
+	}
+}
+
+func TestPreferredLocalesFromContext(t *testing.T) {
+	assert.Nil(t, PreferredLocalesFromContext(context.Background()))
+
+	ctx := ContextWithPreferredLocales(context.Background(), []string{"fr", "en"})
+	assert.Equal(t, []string{"fr", "en"}, PreferredLocalesFromContext(ctx))
+}
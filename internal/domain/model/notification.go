@@ -1,6 +1,10 @@
 package model
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,22 +15,80 @@ type NotificationType string
 
 const (
 	// Notification types
-	EmailNotification NotificationType = "email"
-	SMSNotification  NotificationType = "sms"
-	PushNotification NotificationType = "push"
+	EmailNotification    NotificationType = "email"
+	SMSNotification      NotificationType = "sms"
+	PushNotification     NotificationType = "push"
+	WhatsAppNotification NotificationType = "whatsapp"
 )
 
+// NotificationTypes lists every notification type
+var NotificationTypes = []NotificationType{EmailNotification, SMSNotification, PushNotification, WhatsAppNotification}
+
+// IsValid reports whether the notification type is one the service can dispatch
+func (t NotificationType) IsValid() bool {
+	switch t {
+	case EmailNotification, SMSNotification, PushNotification, WhatsAppNotification:
+		return true
+	}
+	return false
+}
+
 // NotificationStatus represents the status of a notification
 type NotificationStatus string
 
 const (
 	// Notification statuses
-	StatusPending   NotificationStatus = "pending"
-	StatusSent      NotificationStatus = "sent"
-	StatusFailed    NotificationStatus = "failed"
-	StatusCancelled NotificationStatus = "cancelled"
+	StatusPending    NotificationStatus = "pending"
+	StatusSent       NotificationStatus = "sent"
+	StatusFailed     NotificationStatus = "failed"
+	StatusCancelled  NotificationStatus = "cancelled"
+	StatusDryRun     NotificationStatus = "dry_run"
+	StatusDelivered  NotificationStatus = "delivered"
+	StatusBounced    NotificationStatus = "bounced"
+	StatusSuppressed NotificationStatus = "suppressed"
+	StatusThrottled  NotificationStatus = "throttled"
 )
 
+// NotificationStatuses lists every notification status
+var NotificationStatuses = []NotificationStatus{StatusPending, StatusSent, StatusFailed, StatusCancelled, StatusDryRun, StatusDelivered, StatusBounced, StatusSuppressed, StatusThrottled}
+
+// IsValid reports whether the status is a known notification status
+func (s NotificationStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusSent, StatusFailed, StatusCancelled, StatusDryRun, StatusDelivered, StatusBounced, StatusSuppressed, StatusThrottled:
+		return true
+	}
+	return false
+}
+
+// statusTransitions lists the statuses a notification may move to from each
+// status. Sent means handed to the provider; delivered and bounced are what the
+// provider later reports. Throttled notifications were held back by the send
+// limit and, like failed ones, can be retried. Statuses without an entry are final.
+var statusTransitions = map[NotificationStatus][]NotificationStatus{
+	StatusPending:   {StatusSent, StatusFailed, StatusCancelled, StatusDryRun, StatusSuppressed, StatusThrottled},
+	StatusSent:      {StatusDelivered, StatusBounced},
+	StatusFailed:    {StatusPending},
+	StatusThrottled: {StatusPending},
+}
+
+// CanTransition reports whether a notification may move from one status to another
+func CanTransition(from, to NotificationStatus) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether the status ends a delivery attempt: the notification
+// was delivered, bounced or failed, or won't be sent at all. A failed or throttled
+// notification may still be retried, which starts a new attempt.
+func (s NotificationStatus) IsTerminal() bool {
+	return s != StatusPending && s != StatusSent
+}
+
 // Priority represents the priority level of a notification
 type Priority string
 
@@ -37,37 +99,81 @@ const (
 	PriorityLow    Priority = "low"
 )
 
+// Priorities lists every priority level
+var Priorities = []Priority{PriorityHigh, PriorityMedium, PriorityLow}
+
+// IsValid reports whether the priority is a known priority level
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityHigh, PriorityMedium, PriorityLow:
+		return true
+	}
+	return false
+}
+
 // TemplateType represents the type of template
 type TemplateType string
 
 const (
 	// Template types
 	EmailTemplate TemplateType = "email"
-	SMSTemplate  TemplateType = "sms"
-	PushTemplate TemplateType = "push"
+	SMSTemplate   TemplateType = "sms"
+	PushTemplate  TemplateType = "push"
 )
 
 // Notification represents a notification entity
 type Notification struct {
 	ID           uuid.UUID          `json:"id" redis:"id"`
-	Recipient    string            `json:"recipient" redis:"recipient"`
-	Type         NotificationType  `json:"type" redis:"type"`
-	Subject      string            `json:"subject" redis:"subject"`
-	Content      string            `json:"content" redis:"content"`
+	TenantID     string             `json:"tenant_id" redis:"tenant_id"`
+	Recipient    string             `json:"recipient" redis:"recipient"`
+	Type         NotificationType   `json:"type" redis:"type"`
+	Subject      string             `json:"subject" redis:"subject"`
+	Content      string             `json:"content" redis:"content"`
 	Status       NotificationStatus `json:"status" redis:"status"`
-	Priority     Priority          `json:"priority" redis:"priority"`
-	TemplateID   uuid.UUID         `json:"template_id,omitempty" redis:"template_id"`
-	TemplateType TemplateType      `json:"template_type,omitempty" redis:"template_type"`
-	TemplateData map[string]string `json:"template_data,omitempty" redis:"template_data"`
-	Metadata     map[string]string `json:"metadata,omitempty" redis:"metadata"`
-	ErrorMessage string            `json:"error_message,omitempty" redis:"error_message"`
-	RetryCount   int               `json:"retry_count" redis:"retry_count"`
-	CreatedAt    time.Time         `json:"created_at" redis:"created_at"`
-	UpdatedAt    time.Time         `json:"updated_at" redis:"updated_at"`
+	Priority     Priority           `json:"priority" redis:"priority"`
+	TemplateID   uuid.UUID          `json:"template_id,omitempty" redis:"template_id"`
+	TemplateType TemplateType       `json:"template_type,omitempty" redis:"template_type"`
+	TemplateData TemplateData       `json:"template_data,omitempty" redis:"template_data"`
+	Metadata     map[string]string  `json:"metadata,omitempty" redis:"metadata"`
+	ErrorMessage string             `json:"error_message,omitempty" redis:"error_message"`
+	RetryCount   int                `json:"retry_count" redis:"retry_count"`
+	CreatedAt    time.Time          `json:"created_at" redis:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" redis:"updated_at"`
+	// ProviderMessageID is the ID the provider assigned the message, used to
+	// correlate its delivery webhooks. It is only unique within Provider.
+	ProviderMessageID string `json:"provider_message_id,omitempty" redis:"provider_message_id"`
+	// Provider names the provider that sent the notification, if it reports one
+	Provider string `json:"provider,omitempty" redis:"provider"`
+	// Category says what kind of notification this is, such as "security" or
+	// "marketing". It selects the identity emails are sent from and
+	// notifications can be searched by it; Tags label them further.
+	Category string   `json:"category,omitempty" redis:"category"`
+	Tags     []string `json:"tags,omitempty" redis:"tags"`
+	// CorrelationID ties the notification to the HTTP request or event that
+	// produced it; notifications produced together share it
+	CorrelationID string `json:"correlation_id,omitempty" redis:"correlation_id"`
+	// GroupID ties the notification to the other channels of a multi-channel
+	// send; it is empty for a notification sent on its own
+	GroupID string `json:"group_id,omitempty" redis:"group_id"`
+	// ContentType, CC and InlineImages only apply to emails; see EmailOptions
+	ContentType  EmailContentType `json:"content_type,omitempty" redis:"content_type"`
+	CC           []string         `json:"cc,omitempty" redis:"cc"`
+	InlineImages []InlineImage    `json:"inline_images,omitempty" redis:"inline_images"`
+	// ThreadID groups emails mail clients should show as one conversation;
+	// EmailMessageID is the Message-ID the email was sent with, which later
+	// emails in the thread reference. Other channels ignore both.
+	ThreadID       string `json:"thread_id,omitempty" redis:"thread_id"`
+	EmailMessageID string `json:"email_message_id,omitempty" redis:"email_message_id"`
+	// NextRetryAt is when delivery of a pending notification whose last attempt
+	// didn't finish is attempted again; nil when no retry is scheduled
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty" redis:"next_retry_at"`
+	// ScheduledAt is when a notification deferred to its category's send window
+	// goes out; nil when it went out as soon as it arrived
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty" redis:"scheduled_at"`
 }
 
 // NewNotification creates a new notification
-func NewNotification(recipient string, notificationType NotificationType, templateType TemplateType, templateID uuid.UUID, templateData map[string]string) *Notification {
+func NewNotification(recipient string, notificationType NotificationType, templateType TemplateType, templateID uuid.UUID, templateData TemplateData) *Notification {
 	now := time.Now()
 	return &Notification{
 		ID:           uuid.New(),
@@ -78,6 +184,7 @@ func NewNotification(recipient string, notificationType NotificationType, templa
 		TemplateID:   templateID,
 		TemplateType: templateType,
 		TemplateData: templateData,
+		Metadata:     make(map[string]string),
 		RetryCount:   0,
 		CreatedAt:    now,
 		UpdatedAt:    now,
@@ -92,20 +199,204 @@ func (n *Notification) Validate() error {
 	if n.Type == "" {
 		return ErrInvalidNotification{Message: "notification type is required"}
 	}
-	if n.TemplateID == uuid.Nil {
-		return ErrInvalidNotification{Message: "template ID is required"}
+	if !n.Type.IsValid() {
+		return ErrInvalidNotification{Message: fmt.Sprintf("unsupported notification type: %s", n.Type)}
+	}
+	// A missing priority is filled in with the type's default when the notification is sent
+	if n.Priority != "" && !n.Priority.IsValid() {
+		return ErrInvalidNotification{Message: fmt.Sprintf("invalid priority: %s", n.Priority)}
+	}
+	if n.Type != EmailNotification && (n.ContentType != "" || len(n.CC) > 0 || len(n.InlineImages) > 0) {
+		return ErrInvalidNotification{Message: "content type, CC and inline images only apply to email notifications"}
+	}
+	if len(n.ThreadID) > MaxThreadIDLength {
+		return ErrInvalidNotification{Message: fmt.Sprintf("thread ID must be at most %d characters", MaxThreadIDLength)}
 	}
-	if n.TemplateType == "" {
-		return ErrInvalidNotification{Message: "template type is required"}
+	if !n.ContentType.IsValid() {
+		return ErrInvalidNotification{Message: fmt.Sprintf("unsupported content type: %s", n.ContentType)}
+	}
+	if len(n.CC) > MaxEmailCC {
+		return ErrInvalidNotification{Message: fmt.Sprintf("an email can be copied to at most %d addresses", MaxEmailCC)}
+	}
+	if len(n.InlineImages) > 0 {
+		if n.ContentType == EmailContentText {
+			return ErrInvalidNotification{Message: "inline images can only be embedded in HTML emails"}
+		}
+		if err := validateInlineImages(n.InlineImages); err != nil {
+			return err
+		}
+	}
+	if n.Type == WhatsAppNotification {
+		if !e164Pattern.MatchString(n.Recipient) {
+			return ErrInvalidNotification{Message: fmt.Sprintf("recipient must be an E.164 phone number: %s", n.Recipient)}
+		}
+		if _, err := n.WhatsAppTemplate(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// UpdateStatus updates the notification status
-func (n *Notification) UpdateStatus(status NotificationStatus, errorMessage string) {
-	n.Status = status
+// e164Pattern matches phone numbers in E.164 format, e.g. +14155552671
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// Template data keys naming the pre-approved template a WhatsApp notification is sent with
+const (
+	TemplateDataWhatsAppTemplate = "whatsapp_template"
+	TemplateDataWhatsAppLanguage = "whatsapp_language"
+)
+
+// DefaultWhatsAppLanguage is used when a WhatsApp notification doesn't name a template language
+const DefaultWhatsAppLanguage = "en_US"
+
+// WhatsAppTemplateMessage is a message built from a pre-approved WhatsApp template
+type WhatsAppTemplateMessage struct {
+	Name       string
+	Language   string
+	Parameters []string
+}
+
+// WhatsAppTemplate reads the template a WhatsApp notification is sent with from
+// its template data. Body parameters are keyed by position: "1", "2", ...
+func (n *Notification) WhatsAppTemplate() (WhatsAppTemplateMessage, error) {
+	message := WhatsAppTemplateMessage{
+		Name:     n.TemplateData.String(TemplateDataWhatsAppTemplate),
+		Language: n.TemplateData.String(TemplateDataWhatsAppLanguage),
+	}
+	if message.Name == "" {
+		return message, ErrInvalidNotification{Message: fmt.Sprintf("template data must name a WhatsApp template (%s)", TemplateDataWhatsAppTemplate)}
+	}
+	if message.Language == "" {
+		message.Language = DefaultWhatsAppLanguage
+	}
+
+	positional := 0
+	for key := range n.TemplateData {
+		if _, err := strconv.Atoi(key); err == nil {
+			positional++
+		}
+	}
+	for i := 1; i <= positional; i++ {
+		raw, ok := n.TemplateData[strconv.Itoa(i)]
+		if !ok {
+			return message, ErrInvalidNotification{Message: "WhatsApp template parameters must be numbered consecutively from 1"}
+		}
+		value, ok := raw.(string)
+		if !ok {
+			return message, ErrInvalidNotification{Message: fmt.Sprintf("WhatsApp template parameter %d must be a string", i)}
+		}
+		message.Parameters = append(message.Parameters, value)
+	}
+
+	return message, nil
+}
+
+// TransitionTo moves the notification to the target status, recording
+// errorMessage as why it got there. It returns ErrInvalidTransition, leaving the
+// notification unchanged, when CanTransition doesn't allow the move.
+func (n *Notification) TransitionTo(target NotificationStatus, errorMessage string) error {
+	if !CanTransition(n.Status, target) {
+		return ErrInvalidTransition{ID: n.ID.String(), From: n.Status, To: target}
+	}
+	n.Status = target
 	n.ErrorMessage = errorMessage
+	n.NextRetryAt = nil
 	n.UpdatedAt = time.Now()
+	return nil
+}
+
+// ScheduleRetry records when delivery of the notification is next attempted
+func (n *Notification) ScheduleRetry(at time.Time) {
+	n.NextRetryAt = &at
+	n.UpdatedAt = time.Now()
+}
+
+// ResetForRetry returns a failed notification to pending so it can be dispatched again
+func (n *Notification) ResetForRetry() error {
+	if !CanTransition(n.Status, StatusPending) {
+		return ErrNotificationNotRetryable{ID: n.ID.String(), Status: n.Status}
+	}
+	if err := n.TransitionTo(StatusPending, ""); err != nil {
+		return err
+	}
+	n.IncrementRetryCount()
+	return nil
+}
+
+// MetadataResendOf is the metadata key linking a resent notification to its original
+const MetadataResendOf = "resend_of"
+
+// CloneForResend returns a new pending notification with the same content,
+// linked to n through its metadata and correlation ID. A non-empty recipient
+// replaces n's.
+func (n *Notification) CloneForResend(recipient string) *Notification {
+	clone := n.clone()
+	if recipient != "" {
+		clone.Recipient = recipient
+	}
+	clone.Metadata[MetadataResendOf] = n.ID.String()
+	return clone
+}
+
+// ForChannel returns a new pending notification with n's content, to be sent
+// to recipient on the channel of type t as part of the group groupID
+func (n *Notification) ForChannel(t NotificationType, recipient, groupID string) *Notification {
+	clone := n.clone()
+	clone.Type = t
+	clone.Recipient = recipient
+	clone.GroupID = groupID
+	return clone
+}
+
+// clone returns a new pending notification with n's content and thread and a
+// copy of its tags, CC addresses, template data and metadata. Inline images are
+// never modified, so they are shared.
+func (n *Notification) clone() *Notification {
+	metadata := make(map[string]string, len(n.Metadata)+1)
+	for key, value := range n.Metadata {
+		metadata[key] = value
+	}
+
+	var tags []string
+	if n.Tags != nil {
+		tags = append([]string(nil), n.Tags...)
+	}
+
+	var cc []string
+	if n.CC != nil {
+		cc = append([]string(nil), n.CC...)
+	}
+
+	now := time.Now()
+	return &Notification{
+		ID:            uuid.New(),
+		TenantID:      n.TenantID,
+		Recipient:     n.Recipient,
+		Type:          n.Type,
+		Subject:       n.Subject,
+		Content:       n.Content,
+		Status:        StatusPending,
+		Priority:      n.Priority,
+		Category:      n.Category,
+		Tags:          tags,
+		TemplateID:    n.TemplateID,
+		TemplateType:  n.TemplateType,
+		TemplateData:  n.TemplateData.Clone(),
+		Metadata:      metadata,
+		CorrelationID: n.CorrelationID,
+		ContentType:   n.ContentType,
+		CC:            cc,
+		InlineImages:  n.InlineImages,
+		ThreadID:      n.ThreadID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// EmailOptions returns the options the notification is sent with, if it is an
+// email. Its threading headers are set when it is sent; see ThreadEmail.
+func (n *Notification) EmailOptions() EmailOptions {
+	return EmailOptions{ContentType: n.ContentType, CC: n.CC, InlineImages: n.InlineImages}
 }
 
 // IncrementRetryCount increments the retry count
@@ -122,3 +413,173 @@ type ErrInvalidNotification struct {
 func (e ErrInvalidNotification) Error() string {
 	return e.Message
 }
+
+// Is reports the error as ErrValidation
+func (e ErrInvalidNotification) Is(target error) bool { return target == ErrValidation }
+
+// ErrInvalidRecipient is returned by providers that reject a notification's recipient
+type ErrInvalidRecipient struct {
+	Recipient string
+}
+
+func (e ErrInvalidRecipient) Error() string {
+	return fmt.Sprintf("invalid recipient: %s", e.Recipient)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrInvalidRecipient) Is(target error) bool { return target == ErrValidation }
+
+// ErrNotificationNotFound is returned when a notification does not exist
+type ErrNotificationNotFound struct {
+	ID string
+}
+
+func (e ErrNotificationNotFound) Error() string {
+	return fmt.Sprintf("notification not found: %s", e.ID)
+}
+
+// Is reports the error as ErrNotFound
+func (e ErrNotificationNotFound) Is(target error) bool { return target == ErrNotFound }
+
+// ErrMetadataQueryUnsupported is returned when notifications are queried by
+// metadata but the notification store can't run such queries
+type ErrMetadataQueryUnsupported struct{}
+
+func (e ErrMetadataQueryUnsupported) Error() string {
+	return "notifications can't be queried by metadata in this store"
+}
+
+// Is reports the error as ErrValidation
+func (e ErrMetadataQueryUnsupported) Is(target error) bool { return target == ErrValidation }
+
+// ErrCategoryQueryUnsupported is returned when notifications are queried by
+// category but the notification store can't run such queries
+type ErrCategoryQueryUnsupported struct{}
+
+func (e ErrCategoryQueryUnsupported) Error() string {
+	return "notifications can't be queried by category in this store"
+}
+
+// Is reports the error as ErrValidation
+func (e ErrCategoryQueryUnsupported) Is(target error) bool { return target == ErrValidation }
+
+// ErrExportUnsupported is returned when notifications are exported but the
+// notification store can't stream them
+type ErrExportUnsupported struct{}
+
+func (e ErrExportUnsupported) Error() string {
+	return "notifications can't be exported from this store"
+}
+
+// Is reports the error as ErrValidation
+func (e ErrExportUnsupported) Is(target error) bool { return target == ErrValidation }
+
+// ErrPurgeUnsupported is returned when old notifications are purged but the
+// notification store can't delete them in bulk
+type ErrPurgeUnsupported struct{}
+
+func (e ErrPurgeUnsupported) Error() string {
+	return "notifications can't be purged from this store"
+}
+
+// Is reports the error as ErrValidation
+func (e ErrPurgeUnsupported) Is(target error) bool { return target == ErrValidation }
+
+// ErrNotificationNotRetryable is returned when retrying a notification that has not failed
+type ErrNotificationNotRetryable struct {
+	ID     string
+	Status NotificationStatus
+}
+
+func (e ErrNotificationNotRetryable) Error() string {
+	return fmt.Sprintf("notification %s is %s, only failed or throttled notifications can be retried", e.ID, e.Status)
+}
+
+// Is reports the error as ErrConflict
+func (e ErrNotificationNotRetryable) Is(target error) bool { return target == ErrConflict }
+
+// ErrInvalidTransition is returned when a notification is moved to a status its
+// current status doesn't allow
+type ErrInvalidTransition struct {
+	ID   string
+	From NotificationStatus
+	To   NotificationStatus
+}
+
+func (e ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("notification %s cannot move from %s to %s", e.ID, e.From, e.To)
+}
+
+// Is reports the error as ErrConflict
+func (e ErrInvalidTransition) Is(target error) bool { return target == ErrConflict }
+
+// ErrProviderFailure is returned when a provider fails to deliver a notification
+type ErrProviderFailure struct {
+	Type NotificationType
+	Err  error
+}
+
+func (e ErrProviderFailure) Error() string {
+	return fmt.Sprintf("%s provider failed: %v", e.Type, e.Err)
+}
+
+func (e ErrProviderFailure) Unwrap() error {
+	return e.Err
+}
+
+// Is reports the error as ErrProviderUnavailable
+func (e ErrProviderFailure) Is(target error) bool { return target == ErrProviderUnavailable }
+
+// ErrProviderTimeout is returned when a provider doesn't respond within the
+// send timeout. It reads as a "timeout" failure reason on the notification.
+type ErrProviderTimeout struct {
+	Timeout time.Duration
+}
+
+func (e ErrProviderTimeout) Error() string {
+	return fmt.Sprintf("timeout: no response within %s", e.Timeout)
+}
+
+// Is reports the error as ErrProviderUnavailable and context.DeadlineExceeded
+func (e ErrProviderTimeout) Is(target error) bool {
+	return target == ErrProviderUnavailable || target == context.DeadlineExceeded
+}
+
+// ErrProviderNotConfigured is returned when sending a notification of a type
+// the service has no provider for
+type ErrProviderNotConfigured struct {
+	Type NotificationType
+}
+
+func (e ErrProviderNotConfigured) Error() string {
+	return fmt.Sprintf("no %s provider is configured", e.Type)
+}
+
+// Is reports the error as ErrProviderUnavailable
+func (e ErrProviderNotConfigured) Is(target error) bool { return target == ErrProviderUnavailable }
+
+// ErrBatchSave reports which notifications of a non-atomic batch save failed.
+// Errors is aligned with the batch; entries for notifications that were saved are nil.
+type ErrBatchSave struct {
+	Errors []error
+}
+
+// Failed returns the number of notifications that were not saved
+func (e ErrBatchSave) Failed() int {
+	failed := 0
+	for _, err := range e.Errors {
+		if err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (e ErrBatchSave) Error() string {
+	for i, err := range e.Errors {
+		if err != nil {
+			return fmt.Sprintf("failed to save %d of %d notifications (first at index %d: %v)", e.Failed(), len(e.Errors), i, err)
+		}
+	}
+	return fmt.Sprintf("failed to save 0 of %d notifications", len(e.Errors))
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxGroupChannels caps how many channels a single multi-channel send may target
+const MaxGroupChannels = 10
+
+// ChannelTarget is a channel a multi-channel notification is sent on and who receives it there
+type ChannelTarget struct {
+	Type      NotificationType
+	Recipient string
+}
+
+// ChannelResult is the outcome of sending a multi-channel notification on one of
+// its channels. Notification is nil if the channel's notification was rejected
+// before it was stored, e.g. for an invalid recipient.
+type ChannelResult struct {
+	Target       ChannelTarget
+	Notification *Notification
+	Err          error
+}
+
+// NotificationGroup is one logical notification sent on several channels at
+// once, as a child notification per channel sharing the group's ID
+type NotificationGroup struct {
+	ID      string
+	Results []ChannelResult
+	// Duplicates is how many targets were left out because they repeated an
+	// earlier target's channel and recipient
+	Duplicates int
+}
+
+// NewGroupID returns a new notification group ID
+func NewGroupID() string {
+	return uuid.NewString()
+}
+
+type allowDuplicatesContextKey struct{}
+
+// ContextAllowingDuplicates returns a copy of ctx in which a multi-channel send
+// keeps targets that repeat another's channel and recipient, sending to each
+func ContextAllowingDuplicates(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowDuplicatesContextKey{}, true)
+}
+
+// DuplicatesAllowed reports whether ctx was marked with ContextAllowingDuplicates
+func DuplicatesAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(allowDuplicatesContextKey{}).(bool)
+	return allowed
+}
+
+// Failed returns how many of the group's channels failed
+func (g *NotificationGroup) Failed() int {
+	failed := 0
+	for _, result := range g.Results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// ErrNotificationGroupNotFound is returned when a notification group has no notifications
+type ErrNotificationGroupNotFound struct {
+	ID string
+}
+
+func (e ErrNotificationGroupNotFound) Error() string {
+	return fmt.Sprintf("notification group not found: %s", e.ID)
+}
+
+// Is reports the error as ErrNotFound
+func (e ErrNotificationGroupNotFound) Is(target error) bool { return target == ErrNotFound }
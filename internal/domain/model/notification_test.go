@@ -0,0 +1,212 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotification_TransitionTo(t *testing.T) {
+	allowed := map[NotificationStatus][]NotificationStatus{
+		StatusPending:   {StatusSent, StatusFailed, StatusCancelled, StatusDryRun, StatusSuppressed, StatusThrottled},
+		StatusSent:      {StatusDelivered, StatusBounced},
+		StatusFailed:    {StatusPending},
+		StatusThrottled: {StatusPending},
+	}
+
+	for _, from := range NotificationStatuses {
+		for _, to := range NotificationStatuses {
+			expected := false
+			for _, status := range allowed[from] {
+				if status == to {
+					expected = true
+				}
+			}
+
+			t.Run(string(from)+" to "+string(to), func(t *testing.T) {
+				assert.Equal(t, expected, CanTransition(from, to))
+
+				notification := &Notification{ID: uuid.New(), Status: from, ErrorMessage: "previous"}
+				err := notification.TransitionTo(to, "reason")
+				if expected {
+					require.NoError(t, err)
+					assert.Equal(t, to, notification.Status)
+					assert.Equal(t, "reason", notification.ErrorMessage)
+					assert.False(t, notification.UpdatedAt.IsZero())
+					return
+				}
+
+				assert.ErrorIs(t, err, ErrConflict)
+				assert.Equal(t, ErrInvalidTransition{ID: notification.ID.String(), From: from, To: to}, err)
+				assert.Equal(t, from, notification.Status)
+				assert.Equal(t, "previous", notification.ErrorMessage)
+			})
+		}
+	}
+}
+
+func TestNotificationStatus_IsTerminal(t *testing.T) {
+	terminal := map[NotificationStatus]bool{
+		StatusFailed:     true,
+		StatusCancelled:  true,
+		StatusDryRun:     true,
+		StatusDelivered:  true,
+		StatusBounced:    true,
+		StatusSuppressed: true,
+		StatusThrottled:  true,
+	}
+
+	for _, status := range NotificationStatuses {
+		assert.Equal(t, terminal[status], status.IsTerminal(), status)
+	}
+}
+
+func TestNotification_Erase(t *testing.T) {
+	notification := NewNotification("user@example.com", EmailNotification, EmailTemplate, uuid.New(), TemplateData{"name": "Ada"})
+	notification.Subject = "Welcome, Ada"
+	notification.Content = "Hello Ada"
+	notification.CC = []string{"manager@example.com"}
+	notification.Metadata["order_id"] = "42"
+	notification.Status = StatusFailed
+	notification.ErrorMessage = "mailbox user@example.com is full"
+	notification.Category = CategoryWelcome
+
+	erasedAs := NewErasedRecipient()
+	notification.Erase(erasedAs)
+
+	assert.Equal(t, erasedAs, notification.Recipient)
+	assert.NotContains(t, notification.Recipient, "user@example.com")
+	assert.Empty(t, notification.Subject)
+	assert.Empty(t, notification.Content)
+	assert.Nil(t, notification.CC)
+	assert.Nil(t, notification.TemplateData)
+	assert.Nil(t, notification.Metadata)
+	assert.Empty(t, notification.ErrorMessage)
+	assert.Equal(t, StatusFailed, notification.Status)
+	assert.Equal(t, CategoryWelcome, notification.Category)
+	// Tombstones aren't derived from the recipient, so they can't be matched back to it
+	assert.NotEqual(t, NewErasedRecipient(), NewErasedRecipient())
+}
+
+func TestNotification_ValidateEmailOptions(t *testing.T) {
+	logo := InlineImage{ContentID: "logo", ContentType: "image/png", Data: []byte("\x89PNG")}
+	tests := []struct {
+		name             string
+		notificationType NotificationType
+		recipient        string
+		contentType      EmailContentType
+		cc               []string
+		inlineImages     []InlineImage
+		wantErr          bool
+	}{
+		{name: "plain text email", notificationType: EmailNotification, recipient: "user@example.com", contentType: EmailContentText, cc: []string{"manager@example.com"}},
+		{name: "unknown content type", notificationType: EmailNotification, recipient: "user@example.com", contentType: "application/pdf", wantErr: true},
+		{name: "too many CC addresses", notificationType: EmailNotification, recipient: "user@example.com", cc: make([]string, MaxEmailCC+1), wantErr: true},
+		{name: "CC on an SMS", notificationType: SMSNotification, recipient: "+14155552671", cc: []string{"manager@example.com"}, wantErr: true},
+		{name: "content type on a push", notificationType: PushNotification, recipient: "abcdef", contentType: EmailContentText, wantErr: true},
+		{name: "inline image", notificationType: EmailNotification, recipient: "user@example.com", inlineImages: []InlineImage{logo}},
+		{name: "inline image on an SMS", notificationType: SMSNotification, recipient: "+14155552671", inlineImages: []InlineImage{logo}, wantErr: true},
+		{name: "inline image in a plain text email", notificationType: EmailNotification, recipient: "user@example.com", contentType: EmailContentText, inlineImages: []InlineImage{logo}, wantErr: true},
+		{name: "inline image with an invalid content ID", notificationType: EmailNotification, recipient: "user@example.com", inlineImages: []InlineImage{{ContentID: "<logo>", ContentType: "image/png", Data: logo.Data}}, wantErr: true},
+		{name: "duplicate inline image", notificationType: EmailNotification, recipient: "user@example.com", inlineImages: []InlineImage{logo, logo}, wantErr: true},
+		{name: "inline image that isn't an image", notificationType: EmailNotification, recipient: "user@example.com", inlineImages: []InlineImage{{ContentID: "logo", ContentType: "text/html", Data: logo.Data}}, wantErr: true},
+		{name: "empty inline image", notificationType: EmailNotification, recipient: "user@example.com", inlineImages: []InlineImage{{ContentID: "logo", ContentType: "image/png"}}, wantErr: true},
+		{name: "inline images over the size limit", notificationType: EmailNotification, recipient: "user@example.com", inlineImages: []InlineImage{{ContentID: "logo", ContentType: "image/png", Data: make([]byte, MaxInlineImagesSize+1)}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := NewNotification(tt.recipient, tt.notificationType, "", uuid.Nil, nil)
+			notification.ContentType = tt.contentType
+			notification.CC = tt.cc
+			notification.InlineImages = tt.inlineImages
+
+			err := notification.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrValidation)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNotification_CloneForResend(t *testing.T) {
+	original := NewNotification("user@example.com", EmailNotification, EmailTemplate, uuid.New(), TemplateData{
+		"name":  "Ada",
+		"items": []interface{}{map[string]interface{}{"name": "Notebook"}},
+	})
+	original.Subject = "Welcome, Ada"
+	original.Content = "Hello Ada"
+	original.Category = CategoryWelcome
+	original.Tags = []string{"onboarding"}
+	original.CorrelationID = "req-123"
+	original.ThreadID = "order-42"
+	original.EmailMessageID = "original@example.com"
+	original.Status = StatusFailed
+
+	t.Run("keeps the recipient", func(t *testing.T) {
+		resend := original.CloneForResend("")
+
+		assert.NotEqual(t, original.ID, resend.ID)
+		assert.Equal(t, original.Recipient, resend.Recipient)
+		assert.Equal(t, StatusPending, resend.Status)
+		assert.Equal(t, original.Subject, resend.Subject)
+		assert.Equal(t, original.Content, resend.Content)
+		assert.Equal(t, original.Category, resend.Category)
+		assert.Equal(t, original.Tags, resend.Tags)
+		assert.Equal(t, original.TemplateData, resend.TemplateData)
+		assert.Equal(t, original.CorrelationID, resend.CorrelationID)
+		assert.Equal(t, original.ThreadID, resend.ThreadID)
+		assert.Empty(t, resend.EmailMessageID)
+		assert.Equal(t, original.ID.String(), resend.Metadata[MetadataResendOf])
+	})
+
+	t.Run("replaces the recipient", func(t *testing.T) {
+		resend := original.CloneForResend("other@example.com")
+
+		assert.Equal(t, "other@example.com", resend.Recipient)
+		assert.Equal(t, original.CorrelationID, resend.CorrelationID)
+	})
+
+	t.Run("doesn't share collections with the original", func(t *testing.T) {
+		resend := original.CloneForResend("")
+		resend.Tags[0] = "changed"
+		resend.TemplateData["name"] = "Grace"
+		resend.TemplateData["items"].([]interface{})[0].(map[string]interface{})["name"] = "Pencil"
+
+		assert.Equal(t, "onboarding", original.Tags[0])
+		assert.Equal(t, "Ada", original.TemplateData["name"])
+		assert.Equal(t, "Notebook", original.TemplateData["items"].([]interface{})[0].(map[string]interface{})["name"])
+		assert.NotContains(t, original.Metadata, MetadataResendOf)
+	})
+}
+
+func TestThreadEmail(t *testing.T) {
+	t.Run("first email in a thread", func(t *testing.T) {
+		threading := ThreadEmail("first@example.com", nil)
+		assert.Equal(t, EmailThreading{MessageID: "first@example.com"}, threading)
+	})
+
+	t.Run("replies to the latest email", func(t *testing.T) {
+		threading := ThreadEmail("third@example.com", []string{"first@example.com", "second@example.com"})
+		assert.Equal(t, "second@example.com", threading.InReplyTo)
+		assert.Equal(t, []string{"first@example.com", "second@example.com"}, threading.References)
+	})
+
+	t.Run("keeps the first and latest references", func(t *testing.T) {
+		var earlier []string
+		for i := 0; i < MaxThreadReferences+5; i++ {
+			earlier = append(earlier, fmt.Sprintf("%d@example.com", i))
+		}
+
+		threading := ThreadEmail("next@example.com", earlier)
+		require.Len(t, threading.References, MaxThreadReferences)
+		assert.Equal(t, "0@example.com", threading.References[0])
+		assert.Equal(t, earlier[len(earlier)-1], threading.References[MaxThreadReferences-1])
+		assert.Equal(t, earlier[len(earlier)-1], threading.InReplyTo)
+	})
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEntry is a saved notification waiting to be handed to its provider
+type OutboxEntry struct {
+	ID             int64     `json:"id"`
+	TenantID       string    `json:"tenant_id"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	// AvailableAt is when the entry can be claimed again unless it is completed first
+	AvailableAt time.Time `json:"available_at"`
+}
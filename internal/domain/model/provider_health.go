@@ -0,0 +1,83 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProviderHealthStatus is the outcome of checking a delivery provider
+type ProviderHealthStatus string
+
+const (
+	// ProviderHealthy means the provider answered and accepted the credentials
+	ProviderHealthy ProviderHealthStatus = "healthy"
+	// ProviderUnauthorized means the provider answered but rejected the credentials
+	ProviderUnauthorized ProviderHealthStatus = "unauthorized"
+	// ProviderUnreachable means the provider couldn't be reached or didn't
+	// answer in time
+	ProviderUnreachable ProviderHealthStatus = "unreachable"
+	// ProviderUnhealthy means the provider answered and accepted the
+	// credentials, but reported it won't send, e.g. because the account is paused
+	ProviderUnhealthy ProviderHealthStatus = "unhealthy"
+	// ProviderUnchecked means the provider can't be checked without sending
+	ProviderUnchecked ProviderHealthStatus = "unchecked"
+)
+
+// ErrHealthCheckUnsupported is returned by providers that can't check their
+// health without sending a message
+var ErrHealthCheckUnsupported = errors.New("provider can't be checked without sending")
+
+// ProviderHealth is the outcome of checking the provider of one channel
+type ProviderHealth struct {
+	Type      NotificationType     `json:"type"`
+	Provider  string               `json:"provider,omitempty"`
+	Status    ProviderHealthStatus `json:"status"`
+	LatencyMs int64                `json:"latency_ms"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// Healthy reports whether the provider can be relied on to send
+func (h ProviderHealth) Healthy() bool {
+	return h.Status == ProviderHealthy || h.Status == ProviderUnchecked
+}
+
+// ErrProviderUnauthorized is returned by a provider health check when the
+// provider rejects the credentials it was configured with
+type ErrProviderUnauthorized struct {
+	Provider string
+	Reason   string
+}
+
+func (e ErrProviderUnauthorized) Error() string {
+	return fmt.Sprintf("%s rejected the credentials: %s", e.Provider, e.Reason)
+}
+
+// ErrProviderNotSending is returned by a provider health check when the
+// provider accepts the credentials but reports it won't send
+type ErrProviderNotSending struct {
+	Provider string
+	Reason   string
+}
+
+func (e ErrProviderNotSending) Error() string {
+	return fmt.Sprintf("%s isn't sending: %s", e.Provider, e.Reason)
+}
+
+// HealthStatusForError returns the status a provider health check failing with
+// err is reported as
+func HealthStatusForError(err error) ProviderHealthStatus {
+	var unauthorized ErrProviderUnauthorized
+	var notSending ErrProviderNotSending
+	switch {
+	case err == nil:
+		return ProviderHealthy
+	case errors.Is(err, ErrHealthCheckUnsupported):
+		return ProviderUnchecked
+	case errors.As(err, &unauthorized):
+		return ProviderUnauthorized
+	case errors.As(err, &notSending):
+		return ProviderUnhealthy
+	default:
+		return ProviderUnreachable
+	}
+}
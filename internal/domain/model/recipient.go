@@ -0,0 +1,56 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// gmailDomains are the domains whose mailboxes ignore dots in the local part
+var gmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+}
+
+// phoneSeparators are the characters people write phone numbers with that
+// E.164 leaves out
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// internationalPhonePattern matches a phone number with its country code, given
+// with a leading + or the 00 international call prefix
+var internationalPhonePattern = regexp.MustCompile(`^(\+|00)([1-9][0-9]{1,14})$`)
+
+// RecipientNormalizer rewrites recipients to the one form notifications are
+// stored and looked up by, so the same recipient written differently shares one
+// history:
+//
+//   - Email addresses are lowercased.
+//   - Phone numbers with a country code are written in E.164: separators are
+//     removed and a 00 prefix becomes +, e.g. "0044 20 7946 0958" is "+442079460958".
+//   - Anything else, such as a push device token, is only trimmed.
+//
+// The kind of recipient is read from its form, so lookups can be normalized
+// without knowing the notification type.
+type RecipientNormalizer struct {
+	// StripGmailDots removes the dots from the local part of Gmail addresses,
+	// which Gmail ignores: "first.last@gmail.com" is "firstlast@gmail.com"
+	StripGmailDots bool
+}
+
+// Normalize returns recipient in its normalized form
+func (n RecipientNormalizer) Normalize(recipient string) string {
+	recipient = strings.TrimSpace(recipient)
+
+	if local, domain, ok := strings.Cut(recipient, "@"); ok {
+		local, domain = strings.ToLower(local), strings.ToLower(domain)
+		if n.StripGmailDots && gmailDomains[domain] {
+			local = strings.ReplaceAll(local, ".", "")
+		}
+		return local + "@" + domain
+	}
+
+	if match := internationalPhonePattern.FindStringSubmatch(phoneSeparators.Replace(recipient)); match != nil {
+		return "+" + match[2]
+	}
+
+	return recipient
+}
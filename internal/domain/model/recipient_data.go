@@ -0,0 +1,64 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// erasedRecipientPrefix marks a recipient that has been erased
+const erasedRecipientPrefix = "erased:"
+
+// NewErasedRecipient returns a tombstone to replace a recipient with when their
+// data is erased. It is random rather than derived from the address or number,
+// so it can't be reversed by hashing candidate addresses; the notifications
+// erased together share it.
+func NewErasedRecipient() string {
+	return erasedRecipientPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// Erase anonymizes the notification: the recipient is replaced by erasedAs, a
+// tombstone from NewErasedRecipient, and everything that may hold their personal
+// data is cleared. What was sent when, and how it went, is kept for reporting.
+func (n *Notification) Erase(erasedAs string) {
+	n.Recipient = erasedAs
+	n.Subject = ""
+	n.Content = ""
+	n.CC = nil
+	n.InlineImages = nil
+	n.TemplateData = nil
+	n.Metadata = nil
+	n.ErrorMessage = ""
+	n.UpdatedAt = time.Now()
+}
+
+// RecipientData is everything the service stores about a recipient
+type RecipientData struct {
+	Recipient     string
+	Notifications []*Notification
+	// Suppression is the recipient's suppression list entry, or nil if they aren't suppressed
+	Suppression *Suppression
+}
+
+// RecipientErasure reports a recipient's data being erased, or what would be
+// erased for a dry run
+type RecipientErasure struct {
+	Recipient string
+	// ErasedAs is the tombstone the recipient's notifications are kept under;
+	// empty for a dry run
+	ErasedAs      string
+	Notifications int
+	DryRun        bool
+}
+
+// ErrErasureUnsupported is returned when a recipient's data is erased but the
+// notification store can't erase it
+type ErrErasureUnsupported struct{}
+
+func (e ErrErasureUnsupported) Error() string {
+	return "recipient data can't be erased from this store"
+}
+
+// Is reports the error as ErrValidation
+func (e ErrErasureUnsupported) Is(target error) bool { return target == ErrValidation }
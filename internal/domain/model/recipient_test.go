@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecipientNormalizer_Normalize(t *testing.T) {
+	tests := []struct {
+		name           string
+		stripGmailDots bool
+		recipient      string
+		expected       string
+	}{
+		{name: "email is lowercased", recipient: "User@Gmail.COM", expected: "user@gmail.com"},
+		{name: "email is trimmed", recipient: "  user@example.com ", expected: "user@example.com"},
+		{name: "gmail dots kept by default", recipient: "First.Last@gmail.com", expected: "first.last@gmail.com"},
+		{name: "gmail dots stripped", stripGmailDots: true, recipient: "First.Last@gmail.com", expected: "firstlast@gmail.com"},
+		{name: "googlemail dots stripped", stripGmailDots: true, recipient: "first.last@googlemail.com", expected: "firstlast@googlemail.com"},
+		{name: "other domains keep dots", stripGmailDots: true, recipient: "first.last@example.com", expected: "first.last@example.com"},
+		{name: "E.164 number unchanged", recipient: "+14155552671", expected: "+14155552671"},
+		{name: "phone separators removed", recipient: "+1 (415) 555-2671", expected: "+14155552671"},
+		{name: "dotted phone number", recipient: "+44.20.7946.0958", expected: "+442079460958"},
+		{name: "international call prefix", recipient: "0044 20 7946 0958", expected: "+442079460958"},
+		{name: "national number left alone", recipient: "(415) 555-2671", expected: "(415) 555-2671"},
+		{name: "too long for E.164", recipient: "+1234567890123456", expected: "+1234567890123456"},
+		{
+			name:      "device token only trimmed",
+			recipient: " 740F4707BEBCF74F9B7C25D48E3358945F6AA01DA5DDB387462C7EAF61BB78AD ",
+			expected:  "740F4707BEBCF74F9B7C25D48E3358945F6AA01DA5DDB387462C7EAF61BB78AD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := RecipientNormalizer{StripGmailDots: tt.stripGmailDots}
+			assert.Equal(t, tt.expected, normalizer.Normalize(tt.recipient))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import "fmt"
+
+// MetadataOriginalRecipient is the metadata key holding the recipient a
+// notification was addressed to before sandbox mode redirected it
+const MetadataOriginalRecipient = "original_recipient"
+
+// Sandbox holds the catch-all recipients every outbound notification is
+// redirected to in sandbox mode, so a non-production environment can run the
+// full pipeline without reaching real users. A channel without a catch-all
+// recipient can't send at all.
+type Sandbox struct {
+	Email     string // Catch-all email address
+	Phone     string // Catch-all E.164 phone number, for SMS and WhatsApp
+	PushToken string // Catch-all push device token
+}
+
+// Recipient returns the catch-all recipient for notifications of type t, or ""
+// if the channel has none
+func (s Sandbox) Recipient(t NotificationType) string {
+	switch t {
+	case EmailNotification:
+		return s.Email
+	case SMSNotification, WhatsAppNotification:
+		return s.Phone
+	case PushNotification:
+		return s.PushToken
+	}
+	return ""
+}
+
+// SandboxLabel marks content sent in sandbox mode with its original recipient
+func SandboxLabel(recipient, text string) string {
+	return fmt.Sprintf("[sandbox: %s] %s", recipient, text)
+}
+
+// ErrSandboxBlocked is returned when sandbox mode stops a send on a channel
+// without a catch-all recipient
+type ErrSandboxBlocked struct {
+	Type NotificationType
+}
+
+func (e ErrSandboxBlocked) Error() string {
+	return fmt.Sprintf("sandbox mode has no catch-all %s recipient; not sending", e.Type)
+}
+
+// Is reports the error as ErrRejected
+func (e ErrSandboxBlocked) Is(target error) bool { return target == ErrRejected }
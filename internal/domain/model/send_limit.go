@@ -0,0 +1,35 @@
+package model
+
+import "fmt"
+
+// SendLimitState describes the global send limit, the emergency brake on a
+// runaway producer
+type SendLimitState struct {
+	PerMinute int  `json:"per_minute"` // Notifications allowed per minute
+	Sent      int  `json:"sent"`       // Notifications counted in the current minute
+	Tripped   bool `json:"tripped"`    // Whether notifications are being throttled
+}
+
+// ErrSendLimitExceeded is returned when the global send limit is tripped and a
+// notification is saved as throttled instead of being sent
+type ErrSendLimitExceeded struct {
+	PerMinute int
+}
+
+func (e ErrSendLimitExceeded) Error() string {
+	return fmt.Sprintf("more than %d notifications were sent in a minute; sending is paused", e.PerMinute)
+}
+
+// Is reports the error as ErrThrottled
+func (e ErrSendLimitExceeded) Is(target error) bool { return target == ErrThrottled }
+
+// ErrSendLimitNotConfigured is returned when the send limit is queried or
+// cleared but none is configured
+type ErrSendLimitNotConfigured struct{}
+
+func (e ErrSendLimitNotConfigured) Error() string {
+	return "no send limit is configured"
+}
+
+// Is reports the error as ErrNotFound
+func (e ErrSendLimitNotConfigured) Is(target error) bool { return target == ErrNotFound }
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SendWindow is when notifications of a category may go out, such as marketing
+// emails on weekdays from 9:00 to 17:00 New York time
+type SendWindow struct {
+	// Days are the days of the week the window is open
+	Days [7]bool
+	// Start and End are the times of day, as offsets from midnight, the window
+	// opens and closes on each of Days
+	Start, End time.Duration
+	// Location is the time zone Days and the times of day are in
+	Location *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseSendWindow parses a send window written as days, a time range and an
+// optional time zone, UTC by default: "mon-fri 09:00-17:00 America/New_York".
+// Days are comma-separated days or ranges of days, such as "mon,wed,fri" or
+// "sat-sun"; the window closes at the end of the range.
+func ParseSendWindow(value string) (SendWindow, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 || len(fields) > 3 {
+		return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: `expected "<days> <start>-<end> [time zone]"`}
+	}
+
+	window := SendWindow{Location: time.UTC}
+	for _, days := range strings.Split(fields[0], ",") {
+		first, last, isRange := strings.Cut(strings.ToLower(days), "-")
+		from, ok := weekdays[first]
+		if !ok {
+			return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: fmt.Sprintf("unknown day %q", first)}
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdays[last]; !ok {
+				return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: fmt.Sprintf("unknown day %q", last)}
+			}
+		}
+		// Ranges may wrap around the end of the week, e.g. "fri-mon"
+		for day := from; ; day = (day + 1) % 7 {
+			window.Days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: fmt.Sprintf("invalid time range %q", fields[1])}
+	}
+	var err error
+	if window.Start, err = parseTimeOfDay(start); err != nil {
+		return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: err.Error()}
+	}
+	if window.End, err = parseTimeOfDay(end); err != nil {
+		return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: err.Error()}
+	}
+	if window.Start >= window.End {
+		return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: "the window must close after it opens"}
+	}
+
+	if len(fields) == 3 {
+		if window.Location, err = time.LoadLocation(fields[2]); err != nil {
+			return SendWindow{}, ErrInvalidSendWindow{Value: value, Reason: fmt.Sprintf("unknown time zone %q", fields[2])}
+		}
+	}
+
+	return window, nil
+}
+
+// parseTimeOfDay parses a 24-hour time of day such as "09:00", or "24:00" for
+// the end of the day, as an offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// Contains reports whether the window is open at t. Times of day are read off
+// the clock in the window's time zone, so a 09:00 window opens at 09:00 on the
+// days clocks change too.
+func (w SendWindow) Contains(t time.Time) bool {
+	local := t.In(w.location())
+	if !w.Days[local.Weekday()] {
+		return false
+	}
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second + time.Duration(local.Nanosecond())
+	return clock >= w.Start && clock < w.End
+}
+
+// Next returns t if the window is open at t, and otherwise when it next opens.
+// A window open on no day never opens, and Next returns t.
+func (w SendWindow) Next(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+
+	local := t.In(w.location())
+	for day := 0; day <= 7; day++ {
+		date := local.AddDate(0, 0, day)
+		if !w.Days[date.Weekday()] {
+			continue
+		}
+		opens := time.Date(date.Year(), date.Month(), date.Day(), int(w.Start/time.Hour), int(w.Start%time.Hour/time.Minute), 0, 0, date.Location())
+		if opens.After(t) {
+			return opens
+		}
+	}
+	return t
+}
+
+func (w SendWindow) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+// ErrInvalidSendWindow is returned when a send window can't be parsed
+type ErrInvalidSendWindow struct {
+	Value  string
+	Reason string
+}
+
+func (e ErrInvalidSendWindow) Error() string {
+	return fmt.Sprintf("invalid send window %q: %s", e.Value, e.Reason)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrInvalidSendWindow) Is(target error) bool { return target == ErrValidation }
+
+// ErrOutsideSendWindow is returned when a notification arrives outside its
+// category's send window and can't be deferred to when the window opens
+type ErrOutsideSendWindow struct {
+	Category string
+	OpensAt  time.Time
+}
+
+func (e ErrOutsideSendWindow) Error() string {
+	return fmt.Sprintf("%s notifications can't be sent until %s", e.Category, e.OpensAt.Format(time.RFC3339))
+}
+
+// Is reports the error as ErrConflict
+func (e ErrOutsideSendWindow) Is(target error) bool { return target == ErrConflict }
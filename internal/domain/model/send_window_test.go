@@ -0,0 +1,99 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSendWindow(t *testing.T) {
+	window, err := ParseSendWindow("mon-fri 09:00-17:00 America/New_York")
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{false, true, true, true, true, true, false}, window.Days)
+	assert.Equal(t, 9*time.Hour, window.Start)
+	assert.Equal(t, 17*time.Hour, window.End)
+	assert.Equal(t, "America/New_York", window.Location.String())
+
+	window, err = ParseSendWindow("fri-sun,wed 10:30-24:00")
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{true, false, false, true, false, true, true}, window.Days)
+	assert.Equal(t, 10*time.Hour+30*time.Minute, window.Start)
+	assert.Equal(t, 24*time.Hour, window.End)
+	assert.Equal(t, time.UTC, window.Location)
+
+	for _, value := range []string{
+		"",
+		"weekdays 09:00-17:00",
+		"mon-fri 09:00",
+		"mon-fri 9am-5pm",
+		"mon-fri 17:00-09:00",
+		"mon-fri 09:00-17:00 Mars/Olympus_Mons",
+		"mon-fri 09:00-17:00 UTC extra",
+	} {
+		_, err := ParseSendWindow(value)
+		assert.ErrorIs(t, err, ErrValidation, value)
+	}
+}
+
+func TestSendWindow_Next(t *testing.T) {
+	window, err := ParseSendWindow("mon-fri 09:00-17:00 America/New_York")
+	require.NoError(t, err)
+	newYork := window.Location
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{
+			name: "open during business hours",
+			at:   time.Date(2025, 1, 15, 11, 0, 0, 0, newYork),
+			want: time.Date(2025, 1, 15, 11, 0, 0, 0, newYork),
+		},
+		{
+			name: "before the window opens",
+			at:   time.Date(2025, 1, 15, 7, 30, 0, 0, newYork),
+			want: time.Date(2025, 1, 15, 9, 0, 0, 0, newYork),
+		},
+		{
+			name: "closes at the end of the range",
+			at:   time.Date(2025, 1, 15, 17, 0, 0, 0, newYork),
+			want: time.Date(2025, 1, 16, 9, 0, 0, 0, newYork),
+		},
+		{
+			name: "Friday evening waits for Monday",
+			at:   time.Date(2025, 1, 17, 18, 0, 0, 0, newYork),
+			want: time.Date(2025, 1, 20, 9, 0, 0, 0, newYork),
+		},
+		{
+			name: "weekend waits for Monday",
+			at:   time.Date(2025, 1, 18, 12, 0, 0, 0, newYork),
+			want: time.Date(2025, 1, 20, 9, 0, 0, 0, newYork),
+		},
+		{
+			name: "times in other zones are compared in the window's",
+			at:   time.Date(2025, 1, 15, 15, 0, 0, 0, time.UTC), // 10:00 in New York
+			want: time.Date(2025, 1, 15, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "already the next day in UTC",
+			at:   time.Date(2025, 1, 16, 1, 0, 0, 0, time.UTC), // 20:00 on Wednesday in New York
+			want: time.Date(2025, 1, 16, 9, 0, 0, 0, newYork),
+		},
+		{
+			name: "opens at 09:00 on the clock after clocks change",
+			at:   time.Date(2025, 3, 7, 18, 0, 0, 0, newYork),
+			want: time.Date(2025, 3, 10, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := window.Next(tt.at)
+			assert.True(t, tt.want.Equal(next), "want %s, got %s", tt.want, next)
+			assert.True(t, window.Contains(next))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+)
+
+// Categories the service assigns to the emails it sends for user events
+const (
+	CategoryWelcome  = "welcome"
+	CategorySecurity = "security"
+)
+
+// Sender is an identity emails are sent from
+type Sender struct {
+	Name    string // Display name; may be empty
+	Address string
+}
+
+// ParseSender parses an RFC 5322 address such as "Acme Security <security@example.com>"
+// or a bare "security@example.com"
+func ParseSender(value string) (Sender, error) {
+	address, err := mail.ParseAddress(value)
+	if err != nil {
+		return Sender{}, ErrInvalidSender{Value: value, Err: err}
+	}
+	return Sender{Name: address.Name, Address: address.Address}, nil
+}
+
+// Validate checks the sender has a well-formed address
+func (s Sender) Validate() error {
+	if _, err := mail.ParseAddress(s.Address); err != nil {
+		return ErrInvalidSender{Value: s.Address, Err: err}
+	}
+	return nil
+}
+
+// String formats the sender for a From header, quoting and encoding the display name as needed
+func (s Sender) String() string {
+	if s.Name == "" {
+		return s.Address
+	}
+	address := mail.Address{Name: s.Name, Address: s.Address}
+	return address.String()
+}
+
+// SenderResolver picks the identity an email is sent from by its category,
+// falling back to Default for categories without their own sender
+type SenderResolver struct {
+	Default    Sender
+	Categories map[string]Sender
+}
+
+// Resolve returns the sender for category
+func (r SenderResolver) Resolve(category string) Sender {
+	if sender, ok := r.Categories[category]; ok {
+		return sender
+	}
+	return r.Default
+}
+
+// Validate checks the default and every category's sender
+func (r SenderResolver) Validate() error {
+	if err := r.Default.Validate(); err != nil {
+		return fmt.Errorf("default sender: %w", err)
+	}
+	for category, sender := range r.Categories {
+		if err := sender.Validate(); err != nil {
+			return fmt.Errorf("sender for category %q: %w", category, err)
+		}
+	}
+	return nil
+}
+
+type emailCategoryContextKey struct{}
+
+// ContextWithEmailCategory returns a copy of ctx carrying the category of the
+// email being sent, for the email provider to resolve its sender by
+func ContextWithEmailCategory(ctx context.Context, category string) context.Context {
+	return context.WithValue(ctx, emailCategoryContextKey{}, category)
+}
+
+// EmailCategoryFromContext returns the category set with ContextWithEmailCategory, or ""
+func EmailCategoryFromContext(ctx context.Context) string {
+	category, _ := ctx.Value(emailCategoryContextKey{}).(string)
+	return category
+}
+
+// ErrInvalidSender is returned for a sender identity without a valid email address
+type ErrInvalidSender struct {
+	Value string
+	Err   error
+}
+
+func (e ErrInvalidSender) Error() string {
+	return fmt.Sprintf("invalid sender %q: %v", e.Value, e.Err)
+}
+
+func (e ErrInvalidSender) Unwrap() error {
+	return e.Err
+}
+
+// Is reports the error as ErrValidation
+func (e ErrInvalidSender) Is(target error) bool { return target == ErrValidation }
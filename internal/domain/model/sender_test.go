@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSender(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected Sender
+		wantErr  bool
+	}{
+		{name: "bare address", value: "hello@example.com", expected: Sender{Address: "hello@example.com"}},
+		{name: "display name", value: "Acme Security <security@example.com>", expected: Sender{Name: "Acme Security", Address: "security@example.com"}},
+		{name: "quoted display name", value: `"Acme, Inc." <hello@example.com>`, expected: Sender{Name: "Acme, Inc.", Address: "hello@example.com"}},
+		{name: "missing domain", value: "hello", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := ParseSender(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrValidation)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, sender)
+		})
+	}
+}
+
+func TestSenderResolver(t *testing.T) {
+	resolver := SenderResolver{
+		Default: Sender{Name: "Acme", Address: "noreply@example.com"},
+		Categories: map[string]Sender{
+			CategorySecurity: {Address: "security@example.com"},
+		},
+	}
+	require.NoError(t, resolver.Validate())
+
+	assert.Equal(t, "security@example.com", resolver.Resolve(CategorySecurity).String())
+	assert.Equal(t, `"Acme" <noreply@example.com>`, resolver.Resolve("marketing").String())
+	assert.Equal(t, `"Acme" <noreply@example.com>`, resolver.Resolve("").String())
+
+	resolver.Categories["marketing"] = Sender{Address: "hello"}
+	assert.ErrorIs(t, resolver.Validate(), ErrValidation)
+
+	assert.ErrorIs(t, SenderResolver{}.Validate(), ErrValidation)
+}
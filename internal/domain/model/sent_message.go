@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// SentMessage is a message a recording provider, such as the mock provider,
+// kept instead of delivering it. WhatsApp template messages are kept with the
+// template name as the subject and its parameters, one per line, as the content.
+type SentMessage struct {
+	ID       string           `json:"id"`
+	TenantID string           `json:"tenant_id"`
+	Type     NotificationType `json:"type"`
+	To       string           `json:"to"`
+	CC       []string         `json:"cc,omitempty"`
+	Subject  string           `json:"subject,omitempty"`
+	Content  string           `json:"content"`
+	SentAt   time.Time        `json:"sent_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// SortOrder is the order notifications are listed in by creation time
+type SortOrder string
+
+const (
+	// SortAscending lists the oldest notifications first
+	SortAscending SortOrder = "asc"
+	// SortDescending lists the newest notifications first; it is the default
+	SortDescending SortOrder = "desc"
+)
+
+// IsValid reports whether the order is a known sort order
+func (o SortOrder) IsValid() bool {
+	return o == SortAscending || o == SortDescending
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// NotificationStats counts a tenant's notifications created since a point in time
+type NotificationStats struct {
+	Since      time.Time                    `json:"since"`
+	Total      int64                        `json:"total"`
+	ByStatus   map[NotificationStatus]int64 `json:"by_status"`
+	ByType     map[NotificationType]int64   `json:"by_type"`
+	ByPriority map[Priority]int64           `json:"by_priority"`
+}
+
+// NewNotificationStats creates stats for notifications created since since,
+// counting zero for every known status, type and priority
+func NewNotificationStats(since time.Time) *NotificationStats {
+	stats := &NotificationStats{
+		Since:      since,
+		ByStatus:   make(map[NotificationStatus]int64, len(NotificationStatuses)),
+		ByType:     make(map[NotificationType]int64, len(NotificationTypes)),
+		ByPriority: make(map[Priority]int64, len(Priorities)),
+	}
+	for _, status := range NotificationStatuses {
+		stats.ByStatus[status] = 0
+	}
+	for _, notificationType := range NotificationTypes {
+		stats.ByType[notificationType] = 0
+	}
+	for _, priority := range Priorities {
+		stats.ByPriority[priority] = 0
+	}
+	return stats
+}
+
+// ErrStatsUnsupported is returned when notification stats are requested but
+// the notification store can't compute them
+type ErrStatsUnsupported struct{}
+
+func (e ErrStatsUnsupported) Error() string {
+	return "notification stats can't be computed in this store"
+}
+
+// Is reports the error as ErrValidation
+func (e ErrStatsUnsupported) Is(target error) bool { return target == ErrValidation }
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SuppressionReason is why a recipient was put on the suppression list
+type SuppressionReason string
+
+// Suppression reasons
+const (
+	SuppressionHardBounce SuppressionReason = "hard_bounce"
+	SuppressionComplaint  SuppressionReason = "complaint"
+)
+
+// Suppression is an email recipient that must not be sent to again, because
+// their address hard bounced or they reported a message as spam
+type Suppression struct {
+	TenantID  string            `json:"tenant_id"`
+	Recipient string            `json:"recipient"`
+	Reason    SuppressionReason `json:"reason"`
+	Provider  string            `json:"provider"`
+	Detail    string            `json:"detail,omitempty"` // The provider's bounce or complaint details, if any
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// NewSuppression creates a suppression list entry for a recipient
+func NewSuppression(recipient string, reason SuppressionReason, provider, detail string) *Suppression {
+	return &Suppression{
+		Recipient: NormalizeSuppressedRecipient(recipient),
+		Reason:    reason,
+		Provider:  provider,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NormalizeSuppressedRecipient returns the form recipients are stored and looked
+// up in on the suppression list, so differently cased addresses match
+func NormalizeSuppressedRecipient(recipient string) string {
+	return strings.ToLower(strings.TrimSpace(recipient))
+}
+
+// ErrSuppressionNotFound is returned when a recipient is not on the suppression list
+type ErrSuppressionNotFound struct {
+	Recipient string
+}
+
+func (e ErrSuppressionNotFound) Error() string {
+	return fmt.Sprintf("recipient is not suppressed: %s", e.Recipient)
+}
+
+// Is reports the error as ErrNotFound
+func (e ErrSuppressionNotFound) Is(target error) bool { return target == ErrNotFound }
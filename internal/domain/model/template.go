@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,11 +13,13 @@ const (
 	TwoFactorAuth     TemplateType = "2fa"
 	PasswordReset     TemplateType = "password_reset"
 	AccountActivation TemplateType = "account_activation"
+	PasswordChanged   TemplateType = "password_changed"
 )
 
 // Template represents a notification template
 type Template struct {
 	ID        uuid.UUID         `json:"id" redis:"id"`
+	TenantID  string            `json:"tenant_id" redis:"tenant_id"`
 	Name      string            `json:"name" redis:"name"`
 	Type      TemplateType      `json:"type" redis:"type"`
 	Subject   string            `json:"subject" redis:"subject"`
@@ -45,6 +48,46 @@ func NewTemplate(name string, templateType TemplateType, subject, content string
 	}
 }
 
+// TemplateVersion is a snapshot of a template's content as of a previous version
+type TemplateVersion struct {
+	TemplateID uuid.UUID `json:"template_id" redis:"template_id"`
+	Version    int       `json:"version" redis:"version"`
+	Subject    string    `json:"subject" redis:"subject"`
+	Content    string    `json:"content" redis:"content"`
+	Variables  []string  `json:"variables" redis:"variables"`
+	CreatedAt  time.Time `json:"created_at" redis:"created_at"`
+}
+
+// Snapshot captures the template's current version so it can be restored later
+func (t *Template) Snapshot() *TemplateVersion {
+	return &TemplateVersion{
+		TemplateID: t.ID,
+		Version:    t.Version,
+		Subject:    t.Subject,
+		Content:    t.Content,
+		Variables:  t.Variables,
+		CreatedAt:  t.UpdatedAt,
+	}
+}
+
+// Restore replaces the template's content with a previous version's. The
+// repository records the result as a new version rather than rewriting history.
+func (t *Template) Restore(version *TemplateVersion) {
+	t.Subject = version.Subject
+	t.Content = version.Content
+	t.Variables = version.Variables
+}
+
+// SetActive activates or deactivates the template, returning
+// ErrTemplateActiveState if it already is
+func (t *Template) SetActive(active bool) error {
+	if t.IsActive == active {
+		return ErrTemplateActiveState{ID: t.ID.String(), Active: active}
+	}
+	t.IsActive = active
+	return nil
+}
+
 // Validate validates the template
 func (t *Template) Validate() error {
 	if t.Name == "" {
@@ -59,6 +102,15 @@ func (t *Template) Validate() error {
 	if t.Content == "" {
 		return ErrInvalidTemplate{Message: "template content is required"}
 	}
+	return t.ValidateContent()
+}
+
+// ValidateContent checks the content parses under the rendering policy,
+// without requiring the fields a stored template needs
+func (t *Template) ValidateContent() error {
+	if _, err := parseTemplate(t.Name, t.Content); err != nil {
+		return ErrInvalidTemplate{Message: fmt.Sprintf("template content is not a valid template: %v", err)}
+	}
 	return nil
 }
 
@@ -70,3 +122,61 @@ type ErrInvalidTemplate struct {
 func (e ErrInvalidTemplate) Error() string {
 	return e.Message
 }
+
+// Is reports the error as ErrValidation
+func (e ErrInvalidTemplate) Is(target error) bool { return target == ErrValidation }
+
+// ErrTemplateNotFound is returned when a template does not exist
+type ErrTemplateNotFound struct {
+	ID string
+}
+
+func (e ErrTemplateNotFound) Error() string {
+	return fmt.Sprintf("template not found: %s", e.ID)
+}
+
+// Is reports the error as ErrNotFound
+func (e ErrTemplateNotFound) Is(target error) bool { return target == ErrNotFound }
+
+// ErrTemplateNameTaken is returned when a template is saved under a name
+// another of the tenant's templates already has
+type ErrTemplateNameTaken struct {
+	Name string
+}
+
+func (e ErrTemplateNameTaken) Error() string {
+	return fmt.Sprintf("a template named %s already exists", e.Name)
+}
+
+// Is reports the error as ErrConflict
+func (e ErrTemplateNameTaken) Is(target error) bool { return target == ErrConflict }
+
+// ErrTemplateActiveState is returned when a template is activated or
+// deactivated while already in that state
+type ErrTemplateActiveState struct {
+	ID     string
+	Active bool
+}
+
+func (e ErrTemplateActiveState) Error() string {
+	if e.Active {
+		return fmt.Sprintf("template %s is already active", e.ID)
+	}
+	return fmt.Sprintf("template %s is already inactive", e.ID)
+}
+
+// Is reports the error as ErrConflict
+func (e ErrTemplateActiveState) Is(target error) bool { return target == ErrConflict }
+
+// ErrTemplateVersionNotFound is returned when a template has no such previous version
+type ErrTemplateVersionNotFound struct {
+	ID      string
+	Version int
+}
+
+func (e ErrTemplateVersionNotFound) Error() string {
+	return fmt.Sprintf("template %s has no version %d", e.ID, e.Version)
+}
+
+// Is reports the error as ErrNotFound
+func (e ErrTemplateVersionNotFound) Is(target error) bool { return target == ErrNotFound }
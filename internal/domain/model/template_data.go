@@ -0,0 +1,55 @@
+package model
+
+// TemplateData is the data a notification's template is rendered with. Values
+// are strings or any JSON value (numbers, booleans, lists and nested objects),
+// so templates can {{range}} over lists and test values with {{if}}.
+type TemplateData map[string]interface{}
+
+// NewTemplateData returns template data holding only string values
+func NewTemplateData(values map[string]string) TemplateData {
+	if values == nil {
+		return nil
+	}
+	data := make(TemplateData, len(values))
+	for key, value := range values {
+		data[key] = value
+	}
+	return data
+}
+
+// String returns the value of key if it is a string, and "" otherwise
+func (d TemplateData) String(key string) string {
+	value, _ := d[key].(string)
+	return value
+}
+
+// Clone returns a deep copy of the data, so nested lists and objects of the
+// copy can be changed without affecting d
+func (d TemplateData) Clone() TemplateData {
+	if d == nil {
+		return nil
+	}
+	return TemplateData(cloneTemplateValue(map[string]interface{}(d)).(map[string]interface{}))
+}
+
+// cloneTemplateValue deep copies the lists and objects in value
+func cloneTemplateValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		cloned := make(map[string]interface{}, len(value))
+		for key, v := range value {
+			cloned[key] = cloneTemplateValue(v)
+		}
+		return cloned
+	case TemplateData:
+		return value.Clone()
+	case []interface{}:
+		cloned := make([]interface{}, len(value))
+		for i, v := range value {
+			cloned[i] = cloneTemplateValue(v)
+		}
+		return cloned
+	default:
+		return value
+	}
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetadataTemplateLocale is the template metadata key holding the locale given in a template file
+const MetadataTemplateLocale = "locale"
+
+// templateFrontMatterDelimiter opens and closes a template file's front-matter
+const templateFrontMatterDelimiter = "---"
+
+// ParseTemplateFile reads a template from a file's content: a front-matter
+// block of "key: value" lines between "---" lines, followed by the body.
+//
+//	---
+//	name: welcome
+//	type: welcome_email
+//	subject: Welcome to {{.AppName}}
+//	variables: [Username, AppName]
+//	locale: en
+//	weight: 1
+//	---
+//	<p>Hello {{.Username}}</p>
+//
+// The name defaults to defaultName, usually the file name without its
+// extension. The template is validated before it is returned.
+func ParseTemplateFile(defaultName, content string) (*Template, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != templateFrontMatterDelimiter {
+		return nil, ErrInvalidTemplate{Message: "template file must start with a --- front-matter block"}
+	}
+
+	template := NewTemplate(defaultName, "", "", "")
+	template.Variables = []string{}
+	template.Metadata = make(map[string]string)
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == templateFrontMatterDelimiter {
+			end = i
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, ErrInvalidTemplate{Message: fmt.Sprintf("front-matter line %d is not a key: value pair", i+1)}
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "name":
+			template.Name = value
+		case "type":
+			template.Type = TemplateType(value)
+		case "subject":
+			template.Subject = value
+		case "variables":
+			template.Variables = parseFrontMatterList(value)
+		case "locale":
+			if value != "" {
+				template.Metadata[MetadataTemplateLocale] = value
+			}
+		case "weight":
+			weight, err := parseTemplateWeight(value)
+			if err != nil {
+				return nil, err
+			}
+			template.Metadata[MetadataTemplateWeight] = weight
+		default:
+			return nil, ErrInvalidTemplate{Message: fmt.Sprintf("unknown front-matter key %q", strings.TrimSpace(key))}
+		}
+	}
+	if end < 0 {
+		return nil, ErrInvalidTemplate{Message: "template file's front-matter block is not closed"}
+	}
+
+	template.Content = strings.TrimSpace(strings.Join(lines[end+1:], ""))
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// parseFrontMatterList reads a list written as "[a, b]" or "a, b"
+func parseFrontMatterList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SameContent reports whether other has the same type, subject, content,
+// variables, locale and weight as the template, i.e. saving it would change nothing
+func (t *Template) SameContent(other *Template) bool {
+	if t.Type != other.Type || t.Subject != other.Subject || t.Content != other.Content {
+		return false
+	}
+	if t.Metadata[MetadataTemplateLocale] != other.Metadata[MetadataTemplateLocale] {
+		return false
+	}
+	if t.Weight() != other.Weight() {
+		return false
+	}
+	if len(t.Variables) != len(other.Variables) {
+		return false
+	}
+	for i := range t.Variables {
+		if t.Variables[i] != other.Variables[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TemplateSyncResult reports what syncing templates from files changed, by template name
+type TemplateSyncResult struct {
+	Created   []string            `json:"created"`
+	Updated   []string            `json:"updated"`
+	Unchanged []string            `json:"unchanged"`
+	Failed    []TemplateSyncError `json:"failed"`
+}
+
+// TemplateSyncError is a template file that couldn't be synced
+type TemplateSyncError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// NewTemplateSyncResult creates an empty sync result
+func NewTemplateSyncResult() *TemplateSyncResult {
+	return &TemplateSyncResult{
+		Created:   []string{},
+		Updated:   []string{},
+		Unchanged: []string{},
+		Failed:    []TemplateSyncError{},
+	}
+}
+
+// ErrTemplateSyncNotConfigured is returned when syncing templates without a templates directory
+type ErrTemplateSyncNotConfigured struct{}
+
+func (e ErrTemplateSyncNotConfigured) Error() string {
+	return "no templates directory is configured"
+}
+
+// Is reports the error as ErrNotFound
+func (e ErrTemplateSyncNotConfigured) Is(target error) bool { return target == ErrNotFound }
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplateFile(t *testing.T) {
+	t.Run("front-matter and body", func(t *testing.T) {
+		template, err := ParseTemplateFile("welcome_en", "---\n"+
+			"name: welcome\n"+
+			"type: welcome_email\n"+
+			"# Shown in the inbox\n"+
+			"subject: Welcome to {{.AppName}}\n"+
+			"variables: [Username, AppName]\n"+
+			"locale: en\n"+
+			"---\n"+
+			"<p>Hello {{.Username}}</p>\n")
+		require.NoError(t, err)
+		assert.Equal(t, "welcome", template.Name)
+		assert.Equal(t, WelcomeEmail, template.Type)
+		assert.Equal(t, "Welcome to {{.AppName}}", template.Subject)
+		assert.Equal(t, []string{"Username", "AppName"}, template.Variables)
+		assert.Equal(t, map[string]string{MetadataTemplateLocale: "en"}, template.Metadata)
+		assert.Equal(t, "<p>Hello {{.Username}}</p>", template.Content)
+	})
+
+	t.Run("name defaults to the file name", func(t *testing.T) {
+		template, err := ParseTemplateFile("password_reset", "---\ntype: password_reset\nsubject: Reset\nvariables: Link\n---\n{{.Link}}")
+		require.NoError(t, err)
+		assert.Equal(t, "password_reset", template.Name)
+		assert.Equal(t, []string{"Link"}, template.Variables)
+		assert.Empty(t, template.Metadata)
+	})
+
+	t.Run("weight", func(t *testing.T) {
+		template, err := ParseTemplateFile("welcome_b", "---\ntype: welcome_email\nsubject: Hi\nweight: 3\n---\nHello")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{MetadataTemplateWeight: "3"}, template.Metadata)
+		assert.Equal(t, 3, template.Weight())
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{name: "no front-matter", content: "<p>Hello</p>", message: "template file must start with a --- front-matter block"},
+		{name: "unclosed front-matter", content: "---\ntype: welcome_email\nsubject: Welcome", message: "template file's front-matter block is not closed"},
+		{name: "unknown key", content: "---\nsender: me\n---\nHello", message: `unknown front-matter key "sender"`},
+		{name: "not a pair", content: "---\nwelcome_email\n---\nHello", message: "front-matter line 2 is not a key: value pair"},
+		{name: "missing subject", content: "---\ntype: welcome_email\n---\nHello", message: "template subject is required"},
+		{name: "invalid weight", content: "---\ntype: welcome_email\nsubject: Hi\nweight: 0\n---\nHello", message: `template weight must be a positive integer, got "0"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplateFile("welcome", tt.content)
+			assert.Equal(t, ErrInvalidTemplate{Message: tt.message}, err)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
+
+func TestTemplate_SameContent(t *testing.T) {
+	template := NewTemplate("welcome", WelcomeEmail, "Welcome", "Hello {{.Username}}")
+	template.Variables = []string{"Username"}
+	same := *template
+	assert.True(t, template.SameContent(&same))
+
+	changed := *template
+	changed.Variables = []string{"Username", "AppName"}
+	assert.False(t, template.SameContent(&changed))
+
+	localized := *template
+	localized.Metadata = map[string]string{MetadataTemplateLocale: "fr"}
+	assert.False(t, template.SameContent(&localized))
+
+	weighted := *template
+	weighted.Metadata = map[string]string{MetadataTemplateWeight: "2"}
+	assert.False(t, template.SameContent(&weighted))
+}
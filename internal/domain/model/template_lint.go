@@ -0,0 +1,104 @@
+package model
+
+import (
+	"sort"
+	"text/template/parse"
+)
+
+// TemplateLint reports the problems found in a template's content without
+// rendering or saving it
+type TemplateLint struct {
+	Errors     []string // Why the content doesn't parse; a template with errors can't be rendered
+	Undeclared []string // Variables the content references that aren't in Variables
+	Unused     []string // Variables that the content never references
+}
+
+// Valid reports whether the template parses; variable warnings don't make it invalid
+func (l TemplateLint) Valid() bool {
+	return len(l.Errors) == 0
+}
+
+// Lint parses the template's content and compares the variables it references
+// against the declared Variables. Only the content itself is checked: variables
+// used by the partials it includes are not looked up.
+func (t *Template) Lint() TemplateLint {
+	lint := TemplateLint{Errors: []string{}, Undeclared: []string{}, Unused: []string{}}
+
+	tmpl, err := parseTemplate(t.Name, t.Content)
+	if err != nil {
+		lint.Errors = append(lint.Errors, err.Error())
+		return lint
+	}
+
+	referenced := make(map[string]bool)
+	for _, defined := range tmpl.Templates() {
+		if defined.Tree != nil {
+			collectTemplateVariables(defined.Tree.Root, true, referenced)
+		}
+	}
+
+	declared := make(map[string]bool, len(t.Variables))
+	for _, variable := range t.Variables {
+		declared[variable] = true
+		if !referenced[variable] {
+			lint.Unused = append(lint.Unused, variable)
+		}
+	}
+	for variable := range referenced {
+		if !declared[variable] {
+			lint.Undeclared = append(lint.Undeclared, variable)
+		}
+	}
+	sort.Strings(lint.Undeclared)
+	return lint
+}
+
+// collectTemplateVariables adds the top-level data fields referenced under node
+// to variables. atRoot reports whether dot is still the template data: inside
+// range and with blocks it isn't, so only $.Field references count there.
+func collectTemplateVariables(node parse.Node, atRoot bool, variables map[string]bool) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			collectTemplateVariables(child, atRoot, variables)
+		}
+	case *parse.ActionNode:
+		collectTemplateVariables(node.Pipe, atRoot, variables)
+	case *parse.TemplateNode:
+		collectTemplateVariables(node.Pipe, atRoot, variables)
+	case *parse.IfNode:
+		collectTemplateVariables(node.Pipe, atRoot, variables)
+		collectTemplateVariables(node.List, atRoot, variables)
+		collectTemplateVariables(node.ElseList, atRoot, variables)
+	case *parse.RangeNode:
+		collectTemplateVariables(node.Pipe, atRoot, variables)
+		collectTemplateVariables(node.List, false, variables)
+		collectTemplateVariables(node.ElseList, atRoot, variables)
+	case *parse.WithNode:
+		collectTemplateVariables(node.Pipe, atRoot, variables)
+		collectTemplateVariables(node.List, false, variables)
+		collectTemplateVariables(node.ElseList, atRoot, variables)
+	case *parse.PipeNode:
+		if node == nil {
+			return
+		}
+		for _, cmd := range node.Cmds {
+			for _, arg := range cmd.Args {
+				collectTemplateVariables(arg, atRoot, variables)
+			}
+		}
+	case *parse.ChainNode:
+		collectTemplateVariables(node.Node, atRoot, variables)
+	case *parse.FieldNode:
+		if atRoot {
+			variables[node.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if node.Ident[0] == "$" && len(node.Ident) > 1 {
+			variables[node.Ident[1]] = true
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate_Lint(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		variables  []string
+		undeclared []string
+		unused     []string
+	}{
+		{
+			name:       "all variables declared and used",
+			content:    `<p>Hello {{.Username}}</p>{{if .ResetLink}}<a href="{{.ResetLink}}">reset</a>{{end}}`,
+			variables:  []string{"Username", "ResetLink"},
+			undeclared: []string{},
+			unused:     []string{},
+		},
+		{
+			name:       "undeclared and unused variables",
+			content:    `Hello {{.Username}}, © {{.Year}}`,
+			variables:  []string{"Username", "Email"},
+			undeclared: []string{"Year"},
+			unused:     []string{"Email"},
+		},
+		{
+			name:       "fields of range and with elements are not variables",
+			content:    `{{range .Items}}{{.Name}} for {{$.Username}}{{end}}{{with .Account}}{{.Plan}}{{end}}`,
+			variables:  []string{"Items", "Account"},
+			undeclared: []string{"Username"},
+			unused:     []string{},
+		},
+		{
+			name:       "variables in defined templates and include arguments",
+			content:    `{{define "body"}}{{.Body}}{{end}}{{template "layout" .Page}}`,
+			variables:  []string{"Body", "Page"},
+			undeclared: []string{},
+			unused:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := NewTemplate("welcome", WelcomeEmail, "Welcome", tt.content)
+			template.Variables = tt.variables
+
+			lint := template.Lint()
+			assert.True(t, lint.Valid())
+			assert.Empty(t, lint.Errors)
+			assert.Equal(t, tt.undeclared, lint.Undeclared)
+			assert.Equal(t, tt.unused, lint.Unused)
+		})
+	}
+
+	t.Run("parse error", func(t *testing.T) {
+		lint := NewTemplate("welcome", WelcomeEmail, "Welcome", `Hello {{.Username`).Lint()
+		assert.False(t, lint.Valid())
+		assert.Len(t, lint.Errors, 1)
+	})
+}
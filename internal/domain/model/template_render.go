@@ -0,0 +1,326 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// Templates are rendered as HTML. Template data comes from event payloads and
+// API requests, so it is treated as untrusted:
+//
+//   - Rendering always uses html/template, which escapes every value for the
+//     context it appears in (element text, attribute, URL, script...).
+//   - The only template function is cid, which references an inline image
+//     ({{cid "logo"}} is cid:logo). It accepts valid content IDs only, so it
+//     cannot mark any other value as safe; references to unknown functions fail
+//     to parse.
+//   - Data holding html/template's pre-escaped types (template.HTML,
+//     template.JS, ...) is rejected, since those skip escaping entirely.
+
+// ErrUnsafeTemplateData is returned when template data holds a value that
+// would bypass escaping
+type ErrUnsafeTemplateData struct {
+	Field string
+	Type  string
+}
+
+func (e ErrUnsafeTemplateData) Error() string {
+	return fmt.Sprintf("template data %s is pre-escaped %s, which is not allowed", e.Field, e.Type)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrUnsafeTemplateData) Is(target error) bool { return target == ErrValidation }
+
+// unsafeTemplateTypes are the html/template types whose values are inserted verbatim
+var unsafeTemplateTypes = map[reflect.Type]bool{
+	reflect.TypeOf(template.HTML("")):     true,
+	reflect.TypeOf(template.HTMLAttr("")): true,
+	reflect.TypeOf(template.JS("")):       true,
+	reflect.TypeOf(template.JSStr("")):    true,
+	reflect.TypeOf(template.CSS("")):      true,
+	reflect.TypeOf(template.URL("")):      true,
+	reflect.TypeOf(template.Srcset("")):   true,
+}
+
+// templateFuncs are the functions templates can call
+var templateFuncs = template.FuncMap{
+	"cid": inlineImageURL,
+}
+
+// inlineImageURL returns the URL an email's content references the inline image
+// contentID by. html/template drops URLs with schemes it doesn't know, so the
+// URL is returned as already safe, which holds since contentID is validated.
+func inlineImageURL(contentID string) (template.URL, error) {
+	if !ValidContentID(contentID) {
+		return "", fmt.Errorf("invalid inline image content ID: %q", contentID)
+	}
+	return template.URL("cid:" + contentID), nil
+}
+
+// parseTemplate parses template text under the rendering policy
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// Render renders the template's content with data, escaping every value. The
+// content can only include templates it defines itself; see RenderWithIncludes.
+func (t *Template) Render(data interface{}) (string, error) {
+	return t.RenderWithIncludes(context.Background(), data, nil)
+}
+
+// TemplateFinder looks up a template by name, returning nil if there is none
+type TemplateFinder func(name string) (*Template, error)
+
+// RenderWithIncludes renders like Render, resolving the partial templates the
+// content includes ({{template "footer" .}}) by name with find. Partials may
+// include other partials; the whole set is assembled before anything is
+// executed, so a missing or cyclic include fails the render up front.
+//
+// Rendering stops once ctx is done, returning an error wrapping ctx.Err(), so a
+// pathological template can't hold up its caller past ctx's deadline.
+func (t *Template) RenderWithIncludes(ctx context.Context, data interface{}, find TemplateFinder) (string, error) {
+	if err := checkTemplateData("data", reflect.ValueOf(data)); err != nil {
+		return "", err
+	}
+
+	tmpl, err := parseTemplate(t.Name, t.Content)
+	if err != nil {
+		return "", ErrInvalidTemplate{Message: fmt.Sprintf("template content is not a valid template: %v", err)}
+	}
+	set := &templateSet{ctx: ctx, root: tmpl, find: find, state: make(map[string]includeState)}
+	if err := set.resolve(t.Name, tmpl, nil); err != nil {
+		return "", err
+	}
+
+	// Execution can't be interrupted, so it runs on its own: the render returns
+	// as soon as ctx is done, and execution stops at its next write
+	var rendered strings.Builder
+	done := make(chan error, 1)
+	go func() {
+		done <- tmpl.Execute(contextWriter{ctx: ctx, w: &rendered}, data)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", fmt.Errorf("error executing template %s: %w", t.Name, err)
+		}
+	case <-ctx.Done():
+		return "", fmt.Errorf("error executing template %s: %w", t.Name, ctx.Err())
+	}
+	return rendered.String(), nil
+}
+
+// contextWriter fails every write once ctx is done
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w contextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+// ErrTemplateIncludeNotFound is returned when a template includes a partial
+// that doesn't exist
+type ErrTemplateIncludeNotFound struct {
+	Template string
+	Include  string
+}
+
+func (e ErrTemplateIncludeNotFound) Error() string {
+	return fmt.Sprintf("template %s includes %s, which does not exist", e.Template, e.Include)
+}
+
+// Is reports the error as ErrValidation
+func (e ErrTemplateIncludeNotFound) Is(target error) bool { return target == ErrValidation }
+
+// ErrTemplateIncludeCycle is returned when templates include each other in a cycle
+type ErrTemplateIncludeCycle struct {
+	Path []string // The templates in the cycle, starting and ending with the same one
+}
+
+func (e ErrTemplateIncludeCycle) Error() string {
+	return fmt.Sprintf("template include cycle: %s", strings.Join(e.Path, " -> "))
+}
+
+// Is reports the error as ErrValidation
+func (e ErrTemplateIncludeCycle) Is(target error) bool { return target == ErrValidation }
+
+type includeState int
+
+const (
+	includeResolving includeState = iota + 1
+	includeResolved
+)
+
+// templateSet assembles a template and the partials it includes into one set
+type templateSet struct {
+	ctx   context.Context
+	root  *template.Template
+	find  TemplateFinder
+	state map[string]includeState
+}
+
+// resolve adds the partials tmpl includes to the set, depth first; path holds
+// the templates being resolved that lead to tmpl
+func (s *templateSet) resolve(name string, tmpl *template.Template, path []string) error {
+	s.state[name] = includeResolving
+	path = append(path, name)
+
+	for _, include := range templateIncludes(tmpl) {
+		if err := s.ctx.Err(); err != nil {
+			return fmt.Errorf("error assembling template %s: %w", name, err)
+		}
+		switch s.state[include] {
+		case includeResolved:
+			continue
+		case includeResolving:
+			cycle := append([]string{}, path[indexOf(path, include):]...)
+			return ErrTemplateIncludeCycle{Path: append(cycle, include)}
+		}
+		// Defined by a partial already in the set
+		if s.root.Lookup(include) != nil {
+			continue
+		}
+
+		var partial *Template
+		var err error
+		if s.find != nil {
+			partial, err = s.find(include)
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to find template %s included by %s: %w", include, name, err)
+		}
+		if partial == nil {
+			return ErrTemplateIncludeNotFound{Template: name, Include: include}
+		}
+
+		included, err := parseTemplate(include, partial.Content)
+		if err != nil {
+			return ErrInvalidTemplate{Message: fmt.Sprintf("template %s is not a valid template: %v", include, err)}
+		}
+		for _, defined := range included.Templates() {
+			if defined.Tree == nil {
+				continue
+			}
+			if _, err := s.root.AddParseTree(defined.Name(), defined.Tree); err != nil {
+				return ErrInvalidTemplate{Message: fmt.Sprintf("template %s is not a valid template: %v", include, err)}
+			}
+		}
+		if err := s.resolve(include, included, path); err != nil {
+			return err
+		}
+	}
+
+	s.state[name] = includeResolved
+	return nil
+}
+
+// templateIncludes lists the names of the templates tmpl's content includes,
+// other than those it defines ({{define}}) itself. A reference to tmpl's own
+// name is an include, so a template including itself is reported as a cycle.
+func templateIncludes(tmpl *template.Template) []string {
+	defined := make(map[string]bool)
+	for _, t := range tmpl.Templates() {
+		if t.Name() != tmpl.Name() {
+			defined[t.Name()] = true
+		}
+	}
+
+	var includes []string
+	seen := make(map[string]bool)
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		walkTemplateNodes(t.Tree.Root, func(node *parse.TemplateNode) {
+			if !defined[node.Name] && !seen[node.Name] {
+				seen[node.Name] = true
+				includes = append(includes, node.Name)
+			}
+		})
+	}
+	sort.Strings(includes)
+	return includes
+}
+
+// walkTemplateNodes calls fn for every {{template}} action under node
+func walkTemplateNodes(node parse.Node, fn func(*parse.TemplateNode)) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			walkTemplateNodes(child, fn)
+		}
+	case *parse.TemplateNode:
+		fn(node)
+	case *parse.IfNode:
+		walkTemplateNodes(node.List, fn)
+		walkTemplateNodes(node.ElseList, fn)
+	case *parse.RangeNode:
+		walkTemplateNodes(node.List, fn)
+		walkTemplateNodes(node.ElseList, fn)
+	case *parse.WithNode:
+		walkTemplateNodes(node.List, fn)
+		walkTemplateNodes(node.ElseList, fn)
+	}
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkTemplateData walks data and rejects any value of a pre-escaped type
+func checkTemplateData(field string, value reflect.Value) error {
+	if !value.IsValid() {
+		return nil
+	}
+	if unsafeTemplateTypes[value.Type()] {
+		return ErrUnsafeTemplateData{Field: field, Type: value.Type().String()}
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return checkTemplateData(field, value.Elem())
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := checkTemplateData(fmt.Sprintf("%s.%v", field, iter.Key()), iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := checkTemplateData(fmt.Sprintf("%s[%d]", field, i), value.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			// Templates can only read exported fields
+			if value.Type().Field(i).IsExported() {
+				if err := checkTemplateData(field+"."+value.Type().Field(i).Name, value.Field(i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
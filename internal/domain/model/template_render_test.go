@@ -0,0 +1,171 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// templateFinder looks templates up in a map, counting the lookups
+func templateFinder(templates map[string]string, lookups map[string]int) TemplateFinder {
+	return func(name string) (*Template, error) {
+		lookups[name]++
+		content, ok := templates[name]
+		if !ok {
+			return nil, nil
+		}
+		return NewTemplate(name, WelcomeEmail, "Subject", content), nil
+	}
+}
+
+func TestTemplate_RenderWithIncludes(t *testing.T) {
+	partials := map[string]string{
+		"layout":    `<html><body>{{template "body" .}}{{template "footer" .}}</body></html>`,
+		"footer":    `<footer>Sent to {{.Email}}{{template "signature" .}}</footer>`,
+		"signature": `<p>The Team</p>`,
+		"cycle-a":   `a{{template "cycle-b" .}}`,
+		"cycle-b":   `b{{template "cycle-a" .}}`,
+	}
+	data := map[string]interface{}{"Username": "<b>jane</b>", "Email": "jane@example.com"}
+
+	t.Run("content template in a base layout", func(t *testing.T) {
+		lookups := make(map[string]int)
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome",
+			`{{define "body"}}<p>Hello {{.Username}}</p>{{end}}{{template "layout" .}}`)
+
+		rendered, err := welcome.RenderWithIncludes(context.Background(), data, templateFinder(partials, lookups))
+		require.NoError(t, err)
+		assert.Equal(t, "<html><body><p>Hello &lt;b&gt;jane&lt;/b&gt;</p>"+
+			"<footer>Sent to jane@example.com<p>The Team</p></footer></body></html>", rendered)
+		// The body is defined by the content template, not looked up
+		assert.Equal(t, map[string]int{"layout": 1, "footer": 1, "signature": 1}, lookups)
+	})
+
+	t.Run("partial included twice is looked up once", func(t *testing.T) {
+		lookups := make(map[string]int)
+		receipt := NewTemplate("receipt", WelcomeEmail, "Receipt", `{{template "signature" .}}{{if .Email}}{{template "signature" .}}{{end}}`)
+
+		rendered, err := receipt.RenderWithIncludes(context.Background(), data, templateFinder(partials, lookups))
+		require.NoError(t, err)
+		assert.Equal(t, "<p>The Team</p><p>The Team</p>", rendered)
+		assert.Equal(t, map[string]int{"signature": 1}, lookups)
+	})
+
+	t.Run("missing include", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `{{template "layout" .}}`)
+
+		_, err := welcome.RenderWithIncludes(context.Background(), data, templateFinder(map[string]string{"layout": `{{template "header" .}}`}, make(map[string]int)))
+		assert.Equal(t, ErrTemplateIncludeNotFound{Template: "layout", Include: "header"}, err)
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("cyclic includes", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `{{template "cycle-a" .}}`)
+
+		_, err := welcome.RenderWithIncludes(context.Background(), data, templateFinder(partials, make(map[string]int)))
+		assert.Equal(t, ErrTemplateIncludeCycle{Path: []string{"cycle-a", "cycle-b", "cycle-a"}}, err)
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("template including itself", func(t *testing.T) {
+		loop := NewTemplate("loop", WelcomeEmail, "Loop", `{{template "loop" .}}`)
+
+		_, err := loop.RenderWithIncludes(context.Background(), data, templateFinder(partials, make(map[string]int)))
+		assert.Equal(t, ErrTemplateIncludeCycle{Path: []string{"loop", "loop"}}, err)
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `{{template "layout" .}}`)
+		unavailable := errors.New("database unavailable")
+
+		_, err := welcome.RenderWithIncludes(context.Background(), data, func(string) (*Template, error) { return nil, unavailable })
+		assert.ErrorIs(t, err, unavailable)
+	})
+
+	t.Run("render without a finder", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `{{template "layout" .}}`)
+
+		_, err := welcome.Render(data)
+		assert.Equal(t, ErrTemplateIncludeNotFound{Template: "welcome", Include: "layout"}, err)
+	})
+}
+
+func TestTemplate_RenderInlineImages(t *testing.T) {
+	t.Run("cid references an inline image", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `<img src="{{cid "logo"}}" alt="{{.Name}}"><img src="{{cid .Banner}}">`)
+
+		rendered, err := welcome.Render(map[string]interface{}{"Name": "Acme", "Banner": "banner-2025.png"})
+		require.NoError(t, err)
+		assert.Equal(t, `<img src="cid:logo" alt="Acme"><img src="cid:banner-2025.png">`, rendered)
+	})
+
+	t.Run("invalid content ID", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `<img src="{{cid .Banner}}">`)
+
+		_, err := welcome.Render(map[string]interface{}{"Banner": `x" onerror="alert(1)`})
+		assert.Error(t, err)
+	})
+
+	t.Run("other functions are unknown", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `{{html .Name}}{{safe .Name}}`)
+
+		_, err := welcome.Render(map[string]interface{}{"Name": "Acme"})
+		assert.Error(t, err)
+	})
+}
+
+func TestTemplate_RenderNestedData(t *testing.T) {
+	var notification Notification
+	require.NoError(t, json.Unmarshal([]byte(`{"template_data": {
+		"name": "Ada",
+		"items": [{"name": "Notebook", "quantity": 2}, {"name": "Pen & ink", "quantity": 1}],
+		"discount": {"code": "SPRING", "percent": 10}
+	}}`), &notification))
+
+	order := NewTemplate("order_confirmation", WelcomeEmail, "Your order",
+		`<p>Hi {{.name}}</p><ul>{{range .items}}<li>{{.quantity}} x {{.name}}</li>{{end}}</ul>`+
+			`{{if .discount}}<p>{{.discount.code}} saved you {{.discount.percent}}%</p>{{end}}`)
+
+	t.Run("loops and conditions", func(t *testing.T) {
+		rendered, err := order.Render(notification.TemplateData)
+		require.NoError(t, err)
+		assert.Equal(t, `<p>Hi Ada</p><ul><li>2 x Notebook</li><li>1 x Pen &amp; ink</li></ul><p>SPRING saved you 10%</p>`, rendered)
+	})
+
+	t.Run("skips missing sections", func(t *testing.T) {
+		rendered, err := order.Render(TemplateData{"name": "Ada"})
+		require.NoError(t, err)
+		assert.Equal(t, `<p>Hi Ada</p><ul></ul>`, rendered)
+	})
+}
+
+func TestTemplate_RenderHonorsContext(t *testing.T) {
+	t.Run("cancelled before assembly", func(t *testing.T) {
+		welcome := NewTemplate("welcome", WelcomeEmail, "Welcome", `{{template "layout" .}}`)
+		lookups := make(map[string]int)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := welcome.RenderWithIncludes(ctx, nil, templateFinder(map[string]string{"layout": "<p>Hi</p>"}, lookups))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, lookups)
+	})
+
+	t.Run("deadline during execution", func(t *testing.T) {
+		// Renders a billion rows
+		rows := make([]int, 1000)
+		slow := NewTemplate("slow", WelcomeEmail, "Slow", `{{range .}}{{range $}}{{range $}}<p>row</p>{{end}}{{end}}{{end}}`)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := slow.RenderWithIncludes(ctx, rows, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
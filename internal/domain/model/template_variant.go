@@ -0,0 +1,103 @@
+package model
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// MetadataTemplateWeight is the template metadata key holding the weight given in
+// a template file. A type's active templates are its variants; each gets a share of
+// recipients proportional to its weight.
+const MetadataTemplateWeight = "weight"
+
+// DefaultTemplateWeight is the weight of a template that doesn't set one
+const DefaultTemplateWeight = 1
+
+// MetadataTemplateVariantID is the notification metadata key recording the
+// template variant a notification was rendered from, so conversions can be attributed
+const MetadataTemplateVariantID = "template_variant_id"
+
+// VariantSelection is how one of a template type's variants is picked for a notification
+type VariantSelection string
+
+const (
+	// VariantByRecipient picks by a hash of the recipient, so a recipient always gets the same variant
+	VariantByRecipient VariantSelection = "recipient"
+	// VariantByWeight picks at random, in proportion to the variants' weights
+	VariantByWeight VariantSelection = "weight"
+)
+
+// IsValid reports whether the selection is a known one
+func (s VariantSelection) IsValid() bool {
+	return s == VariantByRecipient || s == VariantByWeight
+}
+
+// Weight returns the template's weight as a variant of its type
+func (t *Template) Weight() int {
+	if weight, err := strconv.Atoi(t.Metadata[MetadataTemplateWeight]); err == nil && weight > 0 {
+		return weight
+	}
+	return DefaultTemplateWeight
+}
+
+// parseTemplateWeight reads a weight given in a template file
+func parseTemplateWeight(value string) (string, error) {
+	weight, err := strconv.Atoi(value)
+	if err != nil || weight < 1 {
+		return "", ErrInvalidTemplate{Message: fmt.Sprintf("template weight must be a positive integer, got %q", value)}
+	}
+	return strconv.Itoa(weight), nil
+}
+
+// SelectVariant picks one of a template type's active templates for a recipient,
+// or returns nil if there are none. Variants are ordered by name first, so which
+// one a recipient gets doesn't depend on the order the store lists them in.
+func SelectVariant(variants []*Template, recipient string, selection VariantSelection) *Template {
+	return selectVariant(variants, func(total int) int {
+		if selection == VariantByRecipient {
+			return recipientBucket(variants, recipient, total)
+		}
+		return rand.Intn(total)
+	})
+}
+
+// selectVariant orders the variants and picks the one whose share of the total
+// weight holds the point roll returns, in [0, total)
+func selectVariant(variants []*Template, roll func(total int) int) *Template {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	ordered := append([]*Template{}, variants...)
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Name != ordered[j].Name {
+			return ordered[i].Name < ordered[j].Name
+		}
+		return ordered[i].ID.String() < ordered[j].ID.String()
+	})
+
+	total := 0
+	for _, variant := range ordered {
+		total += variant.Weight()
+	}
+
+	point := roll(total)
+	for _, variant := range ordered {
+		if point < variant.Weight() {
+			return variant
+		}
+		point -= variant.Weight()
+	}
+	return ordered[len(ordered)-1]
+}
+
+// recipientBucket hashes the recipient into [0, total). The type is mixed in so
+// a recipient's bucket in one experiment doesn't decide it in the others.
+func recipientBucket(variants []*Template, recipient string, total int) int {
+	hash := fnv.New64a()
+	hash.Write([]byte(string(variants[0].Type) + ":" + recipient))
+	return int(hash.Sum64() % uint64(total))
+}
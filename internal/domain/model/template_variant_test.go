@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate_Weight(t *testing.T) {
+	template := NewTemplate("welcome", WelcomeEmail, "Welcome", "Hello")
+	assert.Equal(t, DefaultTemplateWeight, template.Weight())
+
+	template.Metadata = map[string]string{MetadataTemplateWeight: "4"}
+	assert.Equal(t, 4, template.Weight())
+
+	template.Metadata[MetadataTemplateWeight] = "-1"
+	assert.Equal(t, DefaultTemplateWeight, template.Weight())
+}
+
+func TestSelectVariant(t *testing.T) {
+	a := NewTemplate("welcome_a", WelcomeEmail, "Welcome", "Hello")
+	b := NewTemplate("welcome_b", WelcomeEmail, "You're in", "Hello")
+	b.Metadata = map[string]string{MetadataTemplateWeight: "3"}
+
+	t.Run("no variants", func(t *testing.T) {
+		assert.Nil(t, SelectVariant(nil, "user@example.com", VariantByRecipient))
+	})
+
+	t.Run("single variant", func(t *testing.T) {
+		assert.Same(t, a, SelectVariant([]*Template{a}, "user@example.com", VariantByWeight))
+	})
+
+	t.Run("by recipient is stable", func(t *testing.T) {
+		for _, recipient := range []string{"ada@example.com", "grace@example.com", "+14155552671"} {
+			first := SelectVariant([]*Template{a, b}, recipient, VariantByRecipient)
+			for i := 0; i < 10; i++ {
+				assert.Same(t, first, SelectVariant([]*Template{b, a}, recipient, VariantByRecipient))
+			}
+		}
+	})
+
+	t.Run("by recipient spreads recipients", func(t *testing.T) {
+		picked := make(map[*Template]int)
+		for i := 0; i < 200; i++ {
+			picked[SelectVariant([]*Template{a, b}, string(rune('a'+i%26))+string(rune('0'+i/26))+"@example.com", VariantByRecipient)]++
+		}
+		assert.Len(t, picked, 2)
+	})
+
+	t.Run("weights split the range", func(t *testing.T) {
+		// a holds [0, 1) and b holds [1, 4), whatever order they are given in
+		for point, expected := range []*Template{a, b, b, b} {
+			assert.Same(t, expected, selectVariant([]*Template{b, a}, func(total int) int {
+				assert.Equal(t, 4, total)
+				return point
+			}))
+		}
+	})
+}
+
+func TestVariantSelection_IsValid(t *testing.T) {
+	assert.True(t, VariantByRecipient.IsValid())
+	assert.True(t, VariantByWeight.IsValid())
+	assert.False(t, VariantSelection("round_robin").IsValid())
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultTenantID is the tenant used when a request carries no tenant information
+const DefaultTenantID = "default"
+
+type tenantContextKey struct{}
+
+// ContextWithTenant returns a copy of ctx scoped to the given tenant
+func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant the context is scoped to, or DefaultTenantID
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenantID
+}
+
+// ResolveTenantID returns the tenant an entity must be written under. Entities
+// without a tenant inherit the context's tenant; entities that already belong
+// to a different tenant are rejected so one tenant can never write another's data.
+func ResolveTenantID(ctx context.Context, ownerTenantID string) (string, error) {
+	tenantID := TenantFromContext(ctx)
+	if ownerTenantID != "" && ownerTenantID != tenantID {
+		return "", ErrTenantMismatch{Owner: ownerTenantID, Requested: tenantID}
+	}
+	return tenantID, nil
+}
+
+// ErrTenantMismatch is returned when an entity is accessed from another tenant's scope
+type ErrTenantMismatch struct {
+	Owner     string
+	Requested string
+}
+
+func (e ErrTenantMismatch) Error() string {
+	return fmt.Sprintf("entity belongs to tenant %q, not %q", e.Owner, e.Requested)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// BroadcastProgressRepository defines the interface for storing how far each
+// broadcast's fan-out got. Progress is scoped to the caller's tenant.
+type BroadcastProgressRepository interface {
+	// FindBroadcastProgress finds a broadcast's progress, or nil if it hasn't started
+	FindBroadcastProgress(ctx context.Context, broadcastID string) (*model.BroadcastProgress, error)
+
+	// SaveBroadcastProgress creates or replaces a broadcast's progress
+	SaveBroadcastProgress(ctx context.Context, progress *model.BroadcastProgress) error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// NotificationRepository defines the interface for notification storage operations.
+// Every storage backend implements it, so they can be swapped without code changes.
+type NotificationRepository interface {
+	// Save saves a notification
+	Save(ctx context.Context, notification *model.Notification) error
+
+	// SaveBatch saves many notifications in as few round trips as the store allows.
+	// Stores that can't save the batch atomically report failures with model.ErrBatchSave.
+	SaveBatch(ctx context.Context, notifications []*model.Notification) error
+
+	// FindByID finds a notification by ID, or nil if there is none
+	FindByID(ctx context.Context, id string) (*model.Notification, error)
+
+	// FindByIDs finds the notifications with the given IDs, skipping missing ones
+	FindByIDs(ctx context.Context, ids []string) ([]*model.Notification, error)
+
+	// FindByProviderMessageID finds the notification the named provider assigned
+	// messageID to, or nil if there is none. A provider's message IDs are unique
+	// across tenants, so the lookup is not scoped to the caller's tenant.
+	// Notifications sent by a provider that doesn't report its name are matched
+	// by messageID alone.
+	FindByProviderMessageID(ctx context.Context, provider, messageID string) (*model.Notification, error)
+
+	// FindByRecipient finds a recipient's notifications in order of creation:
+	// newest first for model.SortDescending, oldest first for model.SortAscending
+	FindByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error)
+
+	// FindByCorrelationID finds the notifications produced by the request or
+	// event with the given correlation ID, oldest first
+	FindByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error)
+
+	// FindByGroupID finds the notifications sent together as a multi-channel
+	// notification, oldest first. A group has at most model.MaxGroupChannels.
+	FindByGroupID(ctx context.Context, groupID string) ([]*model.Notification, error)
+
+	// FindByStatus finds notifications with the given status
+	FindByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error)
+
+	// Update updates a notification
+	Update(ctx context.Context, notification *model.Notification) error
+
+	// Delete deletes a notification, returning model.ErrNotificationNotFound if there is none
+	Delete(ctx context.Context, id string) error
+}
+
+// NotificationMetadataFinder is implemented by notification stores that can
+// query notifications by their metadata
+type NotificationMetadataFinder interface {
+	// FindByMetadata finds notifications whose metadata contains every key/value
+	// pair in filters, newest first
+	FindByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error)
+}
+
+// NotificationCategoryFinder is implemented by notification stores that can
+// query notifications by their category
+type NotificationCategoryFinder interface {
+	// FindByCategory finds notifications in category, newest first
+	FindByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error)
+}
+
+// NotificationStatsCounter is implemented by notification stores that can
+// summarize notifications by status, type and priority
+type NotificationStatsCounter interface {
+	// CountSince counts the tenant's notifications created since since
+	CountSince(ctx context.Context, since time.Time) (*model.NotificationStats, error)
+}
+
+// NotificationScanner is implemented by notification stores that can stream
+// notifications without loading them all into memory
+type NotificationScanner interface {
+	// ScanByRecipient calls fn with each of the recipient's notifications created
+	// in [from, to), oldest first, stopping at the first error fn returns
+	ScanByRecipient(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error
+}
+
+// NotificationPurger is implemented by notification stores that can delete old
+// notifications in bulk
+type NotificationPurger interface {
+	// DeleteOlderThan deletes the tenant's notifications created before cutoff
+	// in batches, so no single statement runs for long, and returns how many it deleted
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// NotificationEraser is implemented by notification stores that can erase a
+// recipient's personal data
+type NotificationEraser interface {
+	// EraseRecipient erases the tenant's notifications to recipient as
+	// model.Notification.Erase does, replacing the recipient with erasedAs, and
+	// returns how many it erased. Erased notifications no longer match the
+	// recipient, so erasing again finds none.
+	EraseRecipient(ctx context.Context, recipient, erasedAs string) (int, error)
+}
+
+// NotificationThreadFinder is implemented by notification stores that can
+// query notifications by the email thread they belong to
+type NotificationThreadFinder interface {
+	// FindByThreadID finds the tenant's notifications in the thread with the
+	// given ID, oldest first
+	FindByThreadID(ctx context.Context, threadID string) ([]*model.Notification, error)
+}
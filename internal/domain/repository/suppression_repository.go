@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// SuppressionRepository defines the interface for the suppression list: email
+// recipients that must not be sent to again. Entries are scoped to the caller's
+// tenant and recipients are matched case-insensitively.
+type SuppressionRepository interface {
+	// Save adds a recipient to the suppression list. A recipient that is already
+	// suppressed keeps its original entry.
+	Save(ctx context.Context, suppression *model.Suppression) error
+
+	// FindByRecipient finds a recipient's suppression, or nil if they aren't suppressed
+	FindByRecipient(ctx context.Context, recipient string) (*model.Suppression, error)
+
+	// List lists suppressions, newest first
+	List(ctx context.Context, limit, offset int) ([]*model.Suppression, error)
+
+	// Delete removes a recipient from the suppression list, returning
+	// model.ErrSuppressionNotFound if they aren't on it
+	Delete(ctx context.Context, recipient string) error
+}
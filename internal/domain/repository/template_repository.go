@@ -15,15 +15,32 @@ type TemplateRepository interface {
 	// FindByID finds a template by ID
 	FindByID(ctx context.Context, id uuid.UUID) (*model.Template, error)
 
+	// FindByName finds the active template with the given name, or nil if there is none
+	FindByName(ctx context.Context, name string) (*model.Template, error)
+
 	// FindByType finds templates by type
 	FindByType(ctx context.Context, templateType model.TemplateType) ([]*model.Template, error)
 
 	// FindActiveByType finds active templates by type
 	FindActiveByType(ctx context.Context, templateType model.TemplateType) ([]*model.Template, error)
 
-	// Update updates a template
+	// Upsert saves a template, or if the tenant already has a template with its
+	// name, updates that one as a new version instead. template is given the
+	// stored template's ID and version. It reports whether the template was created.
+	Upsert(ctx context.Context, template *model.Template) (created bool, err error)
+
+	// Update updates a template, keeping its previous version in the template's history
 	Update(ctx context.Context, template *model.Template) error
 
+	// GetTemplateVersions lists a template's previous versions, newest first
+	GetTemplateVersions(ctx context.Context, id uuid.UUID) ([]*model.TemplateVersion, error)
+
+	// RollbackTemplate restores a previous version's content as a new current version
+	RollbackTemplate(ctx context.Context, id uuid.UUID, version int) (*model.Template, error)
+
+	// SetTemplateActive activates or deactivates a template as a new version
+	SetTemplateActive(ctx context.Context, id uuid.UUID, active bool) (*model.Template, error)
+
 	// Delete deletes a template
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
 )
@@ -14,26 +15,61 @@ type NotificationService interface {
 	// GetNotification retrieves a notification by ID
 	GetNotification(ctx context.Context, id string) (*model.Notification, error)
 
+	// GetNotificationsByIDs retrieves the notifications with the given IDs
+	GetNotificationsByIDs(ctx context.Context, ids []string) ([]*model.Notification, error)
+
 	// GetNotificationHistory retrieves notification history for a recipient
-	GetNotificationHistory(ctx context.Context, recipient string, limit, offset int) ([]*model.Notification, error)
+	GetNotificationHistory(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error)
 
 	// HandleUserEvent processes user-related events and sends appropriate notifications
 	HandleUserEvent(ctx context.Context, eventType string, payload []byte) error
 }
 
-// EmailProvider defines the interface for email providers
+// EmailProvider defines the interface for email providers. Send methods return
+// the ID the provider assigned the message, which its delivery webhooks refer to.
 type EmailProvider interface {
-	SendEmail(ctx context.Context, to, subject, content string) error
+	SendEmail(ctx context.Context, to, subject, content string) (messageID string, err error)
+}
+
+// EmailOptionsProvider is implemented by email providers that can send plain
+// text content and copy other addresses on an email
+type EmailOptionsProvider interface {
+	SendEmailWithOptions(ctx context.Context, to, subject, content string, options model.EmailOptions) (messageID string, err error)
 }
 
 // SMSProvider defines the interface for SMS providers
 type SMSProvider interface {
-	SendSMS(ctx context.Context, to, message string) error
+	SendSMS(ctx context.Context, to, message string) (messageID string, err error)
+
+	// Segment reports the encoding and number of segments message is sent as
+	Segment(message string) model.SMSSegmentation
 }
 
 // PushProvider defines the interface for push notification providers
 type PushProvider interface {
-	SendPush(ctx context.Context, token, title, message string) error
+	SendPush(ctx context.Context, token, title, message string) (messageID string, err error)
+}
+
+// WhatsAppProvider defines the interface for WhatsApp providers
+type WhatsAppProvider interface {
+	// SendWhatsApp sends a pre-approved template message to an E.164 phone number
+	SendWhatsApp(ctx context.Context, to string, message model.WhatsAppTemplateMessage) (messageID string, err error)
+}
+
+// NamedProvider is implemented by providers that report their name. The name is
+// recorded on the notifications they send, scoping the provider's message IDs so
+// they can't collide with another provider's.
+type NamedProvider interface {
+	Name() string
+}
+
+// HealthCheckedProvider is implemented by providers that can confirm they are
+// reachable and accept their credentials without sending a message
+type HealthCheckedProvider interface {
+	// CheckHealth returns nil if the provider is ready to send, an
+	// model.ErrProviderUnauthorized if it rejects the credentials or
+	// model.ErrHealthCheckUnsupported if it can't be checked
+	CheckHealth(ctx context.Context) error
 }
 
 // TemplateEngine defines the interface for template processing
@@ -45,10 +81,53 @@ type TemplateEngine interface {
 	GetTemplate(ctx context.Context, templateName, locale string) (string, error)
 }
 
-// NotificationRepository defines the interface for notification persistence
-type NotificationRepository interface {
-	Save(ctx context.Context, notification *model.Notification) error
-	FindByID(ctx context.Context, id string) (*model.Notification, error)
-	FindByRecipient(ctx context.Context, recipient string, limit, offset int) ([]*model.Notification, error)
-	Update(ctx context.Context, notification *model.Notification) error
+// TemplateVariantSelector picks which of a template type's variants a notification is rendered from
+type TemplateVariantSelector interface {
+	// SelectVariant returns the active template of the type to use for the recipient,
+	// or nil if variants aren't enabled for the type or it has no active templates
+	SelectVariant(ctx context.Context, templateType model.TemplateType, recipient string) (*model.Template, error)
+}
+
+// RecipientSegmentResolver resolves a named segment, such as "newsletter", to
+// the recipients in it for broadcasts
+type RecipientSegmentResolver interface {
+	// ResolveSegment returns up to limit of the segment's recipients starting at
+	// cursor, "" for the first page, and the cursor of the page after them, or ""
+	// if they are the last. A cursor stays valid across restarts.
+	ResolveSegment(ctx context.Context, segment, cursor string, limit int) (recipients []string, next string, err error)
+}
+
+// NotificationOutbox defines the transactional outbox used to deliver notifications at least once
+type NotificationOutbox interface {
+	// SaveAndEnqueue stores a notification and queues it for delivery in a
+	// single transaction, no earlier than its ScheduledAt if it has one
+	SaveAndEnqueue(ctx context.Context, notification *model.Notification) error
+
+	// SaveAndEnqueueBatch stores notifications and queues them for delivery in
+	// a single transaction, so either every notification is queued or none is
+	SaveAndEnqueueBatch(ctx context.Context, notifications []*model.Notification) error
+
+	// ClaimPending leases up to limit pending entries across all tenants. Claimed
+	// entries become claimable again once the lease expires unless marked done.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEntry, error)
+
+	// MarkDone removes an entry from the pending set
+	MarkDone(ctx context.Context, id int64) error
+
+	// MarkFailed records why an attempt failed, leaving the entry to be claimed again
+	MarkFailed(ctx context.Context, id int64, reason string) error
+}
+
+// LeaderLock is a lock at most one instance holds at a time. It expires unless
+// its holder keeps renewing it, so another instance takes over if the holder dies.
+type LeaderLock interface {
+	// Acquire takes the lock for ttl if it is free or already held by this
+	// instance, reporting whether this instance holds it
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+
+	// Renew extends the lock by ttl, reporting false if this instance no longer holds it
+	Renew(ctx context.Context, ttl time.Duration) (bool, error)
+
+	// Release gives the lock up if this instance holds it
+	Release(ctx context.Context) error
 }
@@ -3,12 +3,12 @@ package db
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
 )
 
 var (
@@ -31,15 +31,17 @@ type HealthChecker struct {
 	stopOnce  sync.Once
 	isHealthy bool
 	mu        sync.RWMutex
+	logger    *zap.Logger
 }
 
 // NewHealthChecker creates a new database health checker
-func NewHealthChecker(db *sql.DB, interval, timeout time.Duration) *HealthChecker {
+func NewHealthChecker(db *sql.DB, interval, timeout time.Duration, logger *zap.Logger) *HealthChecker {
 	return &HealthChecker{
 		db:       db,
 		interval: interval,
 		timeout:  timeout,
 		stopChan: make(chan struct{}),
+		logger:   logger,
 	}
 }
 
@@ -90,7 +92,7 @@ func (h *HealthChecker) checkHealth() {
 	h.mu.Unlock()
 
 	if err != nil {
-		fmt.Printf("Database health check failed: %v\n", err)
+		h.logger.Warn("Database health check failed", zap.Error(err))
 	}
 
 	if h.isHealthy {
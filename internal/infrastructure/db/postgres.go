@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
+	"go.uber.org/zap"
 )
 
 // PostgresConfig holds the configuration for PostgreSQL connection
@@ -19,13 +22,36 @@ type PostgresConfig struct {
 	DBName   string
 	SSLMode  string
 
+	// ApplicationName is reported to Postgres as application_name, so the
+	// service's connections can be told apart in pg_stat_activity and the server
+	// logs; empty leaves it unset. Postgres keeps the first 63 bytes.
+	ApplicationName string
+
 	// Pool settings
 	MaxOpenConns    int           // Maximum number of open connections to the database
 	MaxIdleConns    int           // Maximum number of connections in the idle connection pool
 	ConnMaxLifetime time.Duration // Maximum amount of time a connection may be reused
 	ConnMaxIdleTime time.Duration // Maximum amount of time a connection may be idle
+
+	// Startup retry settings
+	ConnectTimeout       time.Duration // Maximum total time to keep retrying the initial connection; zero fails on the first error
+	ConnectRetryInterval time.Duration // Delay before the first retry, doubled after each failed attempt
+
+	// Logger reports failed connection attempts; nil discards them
+	Logger *zap.Logger
 }
 
+const (
+	// pingTimeout bounds each connection attempt
+	pingTimeout = 5 * time.Second
+
+	// maxConnectRetryInterval caps the backoff between connection attempts
+	maxConnectRetryInterval = 10 * time.Second
+)
+
+// DefaultApplicationName is the application_name connections report by default
+const DefaultApplicationName = "notification-service"
+
 // DefaultConfig returns a PostgresConfig with recommended default values
 func DefaultConfig() PostgresConfig {
 	return PostgresConfig{
@@ -35,26 +61,20 @@ func DefaultConfig() PostgresConfig {
 		Password:        "postgres",
 		DBName:          "notification_service",
 		SSLMode:         "disable",
+		ApplicationName: DefaultApplicationName,
 		MaxOpenConns:    25,
 		MaxIdleConns:    25,
 		ConnMaxLifetime: 5 * time.Minute,
 		ConnMaxIdleTime: 5 * time.Minute,
+
+		ConnectTimeout:       60 * time.Second,
+		ConnectRetryInterval: 500 * time.Millisecond,
 	}
 }
 
 // NewPostgresDB creates a new PostgreSQL database connection with connection pooling
 func NewPostgresDB(config PostgresConfig) (*sql.DB, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		config.Host,
-		config.Port,
-		config.User,
-		config.Password,
-		config.DBName,
-		config.SSLMode,
-	)
-
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", ConnectionString(config))
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %w", err)
 	}
@@ -65,17 +85,103 @@ func NewPostgresDB(config PostgresConfig) (*sql.DB, error) {
 	db.SetConnMaxLifetime(config.ConnMaxLifetime)
 	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	// Verify connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err = db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("error connecting to the database: %w", err)
+	// Verify connection, retrying while the database comes up
+	logger := config.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if err = connectWithRetry(db, config.ConnectTimeout, config.ConnectRetryInterval, logger); err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	return db, nil
 }
 
+// ApplicationNameWithHost returns name followed by the host it runs on, such as
+// notification-service@notification-7d9f-x2l4k, so connections can be traced
+// back to a pod. If the hostname can't be read, name is returned as it is.
+func ApplicationNameWithHost(name string) string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return name
+	}
+	return name + "@" + hostname
+}
+
+// ConnectionString builds the key/value connection string for config
+func ConnectionString(config PostgresConfig) string {
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quoteConnValue(config.Host),
+		config.Port,
+		quoteConnValue(config.User),
+		quoteConnValue(config.Password),
+		quoteConnValue(config.DBName),
+		quoteConnValue(config.SSLMode),
+	)
+	if config.ApplicationName != "" {
+		connStr += " application_name=" + quoteConnValue(config.ApplicationName)
+	}
+	return connStr
+}
+
+// connValueQuoter escapes the characters that are special inside a quoted
+// connection string value
+var connValueQuoter = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// quoteConnValue quotes value for a key/value connection string if it is empty
+// or contains spaces, quotes or backslashes, and returns it unchanged otherwise
+func quoteConnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r'\\") {
+		return value
+	}
+	return "'" + connValueQuoter.Replace(value) + "'"
+}
+
+// connectWithRetry pings the database until it responds, backing off exponentially
+// between attempts. The last attempt is made at the deadline; its error is returned.
+func connectWithRetry(db *sql.DB, timeout, interval time.Duration, logger *zap.Logger) error {
+	deadline := time.Now().Add(timeout)
+	backoff := interval
+
+	for attempt := 1; ; attempt++ {
+		err := ping(db)
+		if err == nil {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if backoff <= 0 || remaining <= 0 {
+			return fmt.Errorf("error connecting to the database after %d attempt(s): %w", attempt, err)
+		}
+
+		wait := backoff
+		if wait > remaining {
+			wait = remaining
+		}
+		logger.Warn("Database connection attempt failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+		time.Sleep(wait)
+
+		backoff *= 2
+		if backoff > maxConnectRetryInterval {
+			backoff = maxConnectRetryInterval
+		}
+	}
+}
+
+// ping verifies a single connection attempt within pingTimeout
+func ping(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	return db.PingContext(ctx)
+}
+
 // Close closes the database connection pool
 func Close(db *sql.DB) error {
 	if err := db.Close(); err != nil {
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionString(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t,
+		"host=localhost port=5432 user=postgres password=postgres dbname=notification_service sslmode=disable application_name=notification-service",
+		ConnectionString(config),
+	)
+
+	config.ApplicationName = ""
+	assert.NotContains(t, ConnectionString(config), "application_name")
+
+	config.Password = `it's a \secret`
+	config.ApplicationName = "notification-service@pod 1"
+	assert.Equal(t,
+		`host=localhost port=5432 user=postgres password='it\'s a \\secret' dbname=notification_service sslmode=disable application_name='notification-service@pod 1'`,
+		ConnectionString(config),
+	)
+
+	config.Password = ""
+	assert.Contains(t, ConnectionString(config), "password='' ")
+}
+
+func TestApplicationNameWithHost(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		t.Skip("hostname unavailable")
+	}
+	assert.Equal(t, "notification-service@"+hostname, ApplicationNameWithHost("notification-service"))
+}
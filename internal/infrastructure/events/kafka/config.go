@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/IBM/sarama"
+)
+
+// SASL mechanisms a client can authenticate with
+const (
+	SASLPlain       = sarama.SASLTypePlaintext   // Username and password sent as is; use with TLS
+	SASLSCRAMSHA256 = sarama.SASLTypeSCRAMSHA256 // Salted challenge-response, as on MSK and Confluent
+	SASLSCRAMSHA512 = sarama.SASLTypeSCRAMSHA512
+)
+
+// ClientConfig holds how the consumer and other Kafka clients connect to the
+// brokers. The zero value connects in plaintext, without authentication, as
+// the oldest protocol version sarama defaults to.
+type ClientConfig struct {
+	Version       string // Broker version, e.g. "3.6.0"; empty uses sarama's default
+	SASLMechanism string // SASLPlain, SASLSCRAMSHA256 or SASLSCRAMSHA512; empty disables SASL
+	SASLUsername  string
+	SASLPassword  string
+	TLSEnabled    bool
+	TLSCAFile     string // PEM file of the CAs trusted to sign the brokers' certificates; empty trusts the system's
+}
+
+// saramaConfig returns a sarama configuration with the version, SASL and TLS
+// settings applied, or an error naming what is misconfigured so the service
+// fails at startup rather than retrying a connection that can't succeed
+func (c ClientConfig) saramaConfig() (*sarama.Config, error) {
+	config := sarama.NewConfig()
+
+	if c.Version != "" {
+		version, err := sarama.ParseKafkaVersion(c.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kafka version %q: %w", c.Version, err)
+		}
+		config.Version = version
+	}
+
+	switch c.SASLMechanism {
+	case "":
+		if c.SASLUsername != "" || c.SASLPassword != "" {
+			return nil, fmt.Errorf("kafka SASL credentials are set without a SASL mechanism")
+		}
+	case SASLPlain, SASLSCRAMSHA256, SASLSCRAMSHA512:
+		if c.SASLUsername == "" || c.SASLPassword == "" {
+			return nil, fmt.Errorf("kafka SASL mechanism %s needs a username and password", c.SASLMechanism)
+		}
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = sarama.SASLMechanism(c.SASLMechanism)
+		config.Net.SASL.User = c.SASLUsername
+		config.Net.SASL.Password = c.SASLPassword
+		switch c.SASLMechanism {
+		case SASLSCRAMSHA256:
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return newSCRAMClient(scramSHA256) }
+		case SASLSCRAMSHA512:
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return newSCRAMClient(scramSHA512) }
+		}
+	default:
+		return nil, fmt.Errorf("unknown kafka SASL mechanism %q, expected %s, %s or %s", c.SASLMechanism, SASLPlain, SASLSCRAMSHA256, SASLSCRAMSHA512)
+	}
+
+	if c.TLSCAFile != "" && !c.TLSEnabled {
+		return nil, fmt.Errorf("kafka TLS CA file is set without TLS enabled")
+	}
+	if c.TLSEnabled {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if c.TLSCAFile != "" {
+			pem, err := os.ReadFile(c.TLSCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading kafka TLS CA file: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("kafka TLS CA file %s holds no PEM certificates", c.TLSCAFile)
+			}
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka configuration: %w", err)
+	}
+	return config, nil
+}
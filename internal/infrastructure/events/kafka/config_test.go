@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCAFile writes a self-signed CA certificate to a PEM file
+func writeCAFile(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return path
+}
+
+func TestClientConfig_SaramaConfig(t *testing.T) {
+	t.Run("defaults to plaintext", func(t *testing.T) {
+		config, err := ClientConfig{}.saramaConfig()
+		require.NoError(t, err)
+		assert.Equal(t, sarama.DefaultVersion, config.Version)
+		assert.False(t, config.Net.SASL.Enable)
+		assert.False(t, config.Net.TLS.Enable)
+	})
+
+	t.Run("SCRAM over TLS", func(t *testing.T) {
+		config, err := ClientConfig{
+			Version:       "3.6.0",
+			SASLMechanism: SASLSCRAMSHA512,
+			SASLUsername:  "notification-service",
+			SASLPassword:  "secret",
+			TLSEnabled:    true,
+			TLSCAFile:     writeCAFile(t),
+		}.saramaConfig()
+		require.NoError(t, err)
+		assert.Equal(t, sarama.V3_6_0_0, config.Version)
+		assert.True(t, config.Net.SASL.Enable)
+		assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), config.Net.SASL.Mechanism)
+		assert.Equal(t, "notification-service", config.Net.SASL.User)
+		require.NotNil(t, config.Net.SASL.SCRAMClientGeneratorFunc)
+		assert.NotNil(t, config.Net.SASL.SCRAMClientGeneratorFunc())
+		assert.True(t, config.Net.TLS.Enable)
+		assert.NotNil(t, config.Net.TLS.Config.RootCAs)
+	})
+
+	t.Run("PLAIN with the system CAs", func(t *testing.T) {
+		config, err := ClientConfig{SASLMechanism: SASLPlain, SASLUsername: "user", SASLPassword: "secret", TLSEnabled: true}.saramaConfig()
+		require.NoError(t, err)
+		assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypePlaintext), config.Net.SASL.Mechanism)
+		assert.Nil(t, config.Net.TLS.Config.RootCAs)
+	})
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	for name, config := range map[string]ClientConfig{
+		"invalid version":          {Version: "three"},
+		"unknown mechanism":        {SASLMechanism: "GSSAPI", SASLUsername: "user", SASLPassword: "secret"},
+		"mechanism without secret": {SASLMechanism: SASLSCRAMSHA256, SASLUsername: "user"},
+		"credentials without SASL": {SASLUsername: "user", SASLPassword: "secret"},
+		"CA file without TLS":      {TLSCAFile: writeCAFile(t)},
+		"missing CA file":          {TLSEnabled: true, TLSCAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"CA file without PEM":      {TLSEnabled: true, TLSCAFile: notPEM},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.saramaConfig()
+			assert.Error(t, err)
+		})
+	}
+}
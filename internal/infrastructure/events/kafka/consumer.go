@@ -2,18 +2,69 @@ package kafka
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
 	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/logging"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
 	"go.uber.org/zap"
 )
 
+const (
+	// tenantHeader is the message header carrying the tenant an event belongs to
+	tenantHeader = "X-Tenant-ID"
+
+	// correlationIDHeader is the message header carrying the ID of the request
+	// that caused the event, as with HTTP requests
+	correlationIDHeader = "X-Request-ID"
+)
+
+// ConnectRetryConfig controls how long NewConsumer waits for brokers to become reachable
+type ConnectRetryConfig struct {
+	MaxAttempts     int           // Maximum number of connection attempts; 1 or less fails on the first error
+	Timeout         time.Duration // Maximum total time to keep retrying; zero means bounded by MaxAttempts only
+	InitialInterval time.Duration // Delay before the first retry, doubled after each failed attempt
+	MaxInterval     time.Duration // Upper bound for the delay between attempts
+}
+
+// DefaultConnectRetryConfig returns a ConnectRetryConfig suited to brokers starting alongside the service
+func DefaultConnectRetryConfig() ConnectRetryConfig {
+	return ConnectRetryConfig{
+		MaxAttempts:     10,
+		Timeout:         2 * time.Minute,
+		InitialInterval: time.Second,
+		MaxInterval:     15 * time.Second,
+	}
+}
+
+// JobSubmitter runs jobs concurrently, one at a time and in order per ordering
+// key. Jobs are passed a context that is done if they should not start.
+type JobSubmitter interface {
+	Submit(ctx context.Context, key string, job func(ctx context.Context)) error
+}
+
+// DeadLetterSender keeps messages that can't be handled, such as
+// DeadLetterProducer
+type DeadLetterSender interface {
+	Send(message *sarama.ConsumerMessage, reason string) error
+}
+
+// maxLoggedPayload is how much of a poison message's payload is logged
+const maxLoggedPayload = 512
+
 // Consumer represents a Kafka consumer
 type Consumer struct {
 	consumer        sarama.ConsumerGroup
 	notificationSvc services.NotificationService
+	pool            JobSubmitter
+	deadLetters     DeadLetterSender
 	logger          *zap.Logger
 	topics          []string
 	ready           chan bool
@@ -21,21 +72,32 @@ type Consumer struct {
 	cancel          context.CancelFunc
 }
 
-// NewConsumer creates a new Kafka consumer
+// NewConsumer creates a new Kafka consumer. When pool is nil, each partition's
+// events are handled one at a time; otherwise they are handed to the pool,
+// in order per recipient, and the partition moves on as soon as the pool
+// accepts them. Either way, a message is only committed once it and every
+// earlier message of its partition have been handled. A misconfigured client
+// fails immediately rather than being retried like unreachable brokers.
 func NewConsumer(
 	brokers []string,
 	groupID string,
 	topics []string,
+	client ClientConfig,
+	retry ConnectRetryConfig,
 	notificationSvc services.NotificationService,
+	pool JobSubmitter,
 	logger *zap.Logger,
 ) (*Consumer, error) {
-	config := sarama.NewConfig()
+	config, err := client.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
 	config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
 	config.Consumer.Offsets.Initial = sarama.OffsetNewest
 
-	consumer, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	consumer, err := newConsumerGroupWithRetry(brokers, groupID, config, retry, logger)
 	if err != nil {
-		return nil, fmt.Errorf("error creating consumer group: %w", err)
+		return nil, err
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -43,6 +105,7 @@ func NewConsumer(
 	return &Consumer{
 		consumer:        consumer,
 		notificationSvc: notificationSvc,
+		pool:            pool,
 		logger:          logger,
 		topics:          topics,
 		ready:           make(chan bool),
@@ -51,6 +114,58 @@ func NewConsumer(
 	}, nil
 }
 
+// newConsumerGroupWithRetry creates the consumer group, retrying with exponential backoff
+// while the brokers are unreachable
+func newConsumerGroupWithRetry(
+	brokers []string,
+	groupID string,
+	config *sarama.Config,
+	retry ConnectRetryConfig,
+	logger *zap.Logger,
+) (sarama.ConsumerGroup, error) {
+	var deadline time.Time
+	if retry.Timeout > 0 {
+		deadline = time.Now().Add(retry.Timeout)
+	}
+	backoff := retry.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		consumer, err := sarama.NewConsumerGroup(brokers, groupID, config)
+		if err == nil {
+			return consumer, nil
+		}
+
+		wait := backoff
+		if !deadline.IsZero() {
+			if remaining := time.Until(deadline); remaining < wait {
+				wait = remaining
+			}
+		}
+		if attempt >= retry.MaxAttempts || wait <= 0 {
+			return nil, fmt.Errorf("error creating consumer group after %d attempt(s): %w", attempt, err)
+		}
+
+		logger.Warn("kafka brokers unavailable, retrying",
+			zap.Error(err),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", wait),
+		)
+		time.Sleep(wait)
+
+		backoff *= 2
+		if retry.MaxInterval > 0 && backoff > retry.MaxInterval {
+			backoff = retry.MaxInterval
+		}
+	}
+}
+
+// SetDeadLetters sets where poison messages, whose payload can't be decoded,
+// are moved; without it, they are only logged and counted. It must be called
+// before Start.
+func (c *Consumer) SetDeadLetters(deadLetters DeadLetterSender) {
+	c.deadLetters = deadLetters
+}
+
 // Start begins consuming messages
 func (c *Consumer) Start() error {
 	wg := &sync.WaitGroup{}
@@ -96,13 +211,25 @@ func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
 }
 
 // ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages().
+// Messages are only marked from this goroutine, while the session is live: a
+// message handed to the pool is marked once it and every earlier message of the
+// partition have been handled.
 func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	offsets := newPartitionOffsets()
+	lag := newPartitionLag(claim)
+	defer lag.clear()
+	defer c.markFinished(session, offsets, lag)
+
 	for {
 		select {
 		case message := <-claim.Messages():
 			if message == nil {
+				// The claim ends with a rebalance
+				c.drain(session, offsets, lag)
 				return nil
 			}
+			metrics.RecordKafkaMessageConsumed(message.Topic, message.Partition)
+			lag.received(message)
 
 			c.logger.Info("received message",
 				zap.String("topic", message.Topic),
@@ -111,15 +238,37 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 				zap.Int32("partition", message.Partition),
 			)
 
-			if err := c.handleMessage(message); err != nil {
-				c.logger.Error("error handling message",
+			if c.pool == nil {
+				c.processMessage(message)
+				session.MarkMessage(message, "")
+				lag.marked(message)
+				continue
+			}
+
+			offsets.start(message)
+			err := c.pool.Submit(c.ctx, orderingKey(message), func(ctx context.Context) {
+				// Left unfinished, so it is redelivered after a restart
+				if ctx.Err() == nil {
+					c.processMessage(message)
+					offsets.finish(message)
+				}
+			})
+			if err != nil {
+				// Not marked, so the message is redelivered after a restart or rebalance
+				c.logger.Error("error queueing message",
 					zap.Error(err),
 					zap.String("topic", message.Topic),
 					zap.Int64("offset", message.Offset),
 				)
+				return nil
 			}
 
-			session.MarkMessage(message, "")
+		case <-offsets.notify:
+			c.markFinished(session, offsets, lag)
+
+		case <-session.Context().Done():
+			c.drain(session, offsets, lag)
+			return nil
 
 		case <-c.ctx.Done():
 			return nil
@@ -127,12 +276,129 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 	}
 }
 
-func (c *Consumer) handleMessage(message *sarama.ConsumerMessage) error {
+// drain waits for the partition's messages still on the pool, marking them as
+// they finish, so the partition's next owner doesn't handle them again. It stops
+// waiting once the consumer is stopped; unmarked messages are redelivered.
+func (c *Consumer) drain(session sarama.ConsumerGroupSession, offsets *partitionOffsets, lag *partitionLag) {
+	for offsets.pending() > 0 {
+		select {
+		case <-offsets.notify:
+			c.markFinished(session, offsets, lag)
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// markFinished marks the last message before which every message has been handled
+func (c *Consumer) markFinished(session sarama.ConsumerGroupSession, offsets *partitionOffsets, lag *partitionLag) {
+	if message := offsets.ready(); message != nil {
+		session.MarkMessage(message, "")
+		lag.marked(message)
+	}
+}
+
+// processMessage handles a message, logging the error if handling failed. The
+// message counts as consumed either way.
+func (c *Consumer) processMessage(message *sarama.ConsumerMessage) {
+	start := time.Now()
+	correlationID := correlationID(message)
+	err := c.handleMessage(message, correlationID)
+
+	var malformed model.ErrMalformedEvent
+	poison := errors.As(err, &malformed)
+	metrics.RecordKafkaMessageHandled(message.Topic, message.Partition, time.Since(start).Seconds(), err != nil && !poison)
+	switch {
+	case poison:
+		c.handlePoisonMessage(message, correlationID, err)
+	case err != nil:
+		logging.WithCorrelationID(c.logger, correlationID).Error("error handling message",
+			zap.Error(err),
+			zap.String("topic", message.Topic),
+			zap.Int64("offset", message.Offset),
+		)
+	}
+}
+
+// handlePoisonMessage reports a message whose payload can't be decoded, which
+// would fail the same way however often it was handled, and moves it to the
+// dead-letter topic so it isn't lost when the partition moves past it
+func (c *Consumer) handlePoisonMessage(message *sarama.ConsumerMessage, correlationID string, err error) {
+	metrics.RecordPoisonMessage(message.Topic)
+
+	logger := logging.WithCorrelationID(c.logger, correlationID).With(
+		zap.String("topic", message.Topic),
+		zap.Int32("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
+		zap.String("key", string(message.Key)),
+	)
+	logger.Warn("poison message", zap.Error(err), zap.String("payload", truncatePayload(message.Value)))
+
+	if c.deadLetters == nil {
+		return
+	}
+	if err := c.deadLetters.Send(message, err.Error()); err != nil {
+		logger.Error("error moving poison message to the dead-letter topic", zap.Error(err))
+	}
+}
+
+// truncatePayload returns the start of a payload for logging
+func truncatePayload(payload []byte) string {
+	if len(payload) <= maxLoggedPayload {
+		return string(payload)
+	}
+	return string(payload[:maxLoggedPayload]) + "..."
+}
+
+// orderingKey keeps the events for one recipient in order: every user event
+// carries the user's email, so events are keyed by tenant and email
+func orderingKey(message *sarama.ConsumerMessage) string {
+	var event struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(message.Value, &event); err != nil || event.Email == "" {
+		return ""
+	}
+	return tenantID(message) + ":" + event.Email
+}
+
+// tenantID returns the tenant in the message's header, or "" if the producer didn't set one
+func tenantID(message *sarama.ConsumerMessage) string {
+	return headerValue(message, tenantHeader)
+}
+
+// correlationID returns the correlation ID in the message's header or, if the
+// producer didn't set a usable one, a new ID shared by every notification the
+// event produces
+func correlationID(message *sarama.ConsumerMessage) string {
+	if correlationID := headerValue(message, correlationIDHeader); model.IsValidCorrelationID(correlationID) {
+		return correlationID
+	}
+	return uuid.NewString()
+}
+
+// headerValue returns the value of the message's header with the given key, or "" if there is none
+func headerValue(message *sarama.ConsumerMessage, key string) string {
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c *Consumer) handleMessage(message *sarama.ConsumerMessage, correlationID string) error {
 	// Extract event type from message key
 	eventType := string(message.Key)
 
+	// Scope the event to its tenant, if the producer provided one
+	ctx := model.ContextWithCorrelationID(c.ctx, correlationID)
+	if tenant := tenantID(message); tenant != "" {
+		ctx = model.ContextWithTenant(ctx, tenant)
+	}
+
 	// Handle the event using notification service
-	if err := c.notificationSvc.HandleUserEvent(c.ctx, eventType, message.Value); err != nil {
+	if err := c.notificationSvc.HandleUserEvent(ctx, eventType, message.Value); err != nil {
 		return fmt.Errorf("error handling user event: %w", err)
 	}
 
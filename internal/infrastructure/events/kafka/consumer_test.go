@@ -0,0 +1,264 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// heldPool holds submitted jobs until the test runs them
+type heldPool struct {
+	mu   sync.Mutex
+	jobs []func(context.Context)
+}
+
+func (p *heldPool) Submit(ctx context.Context, key string, job func(ctx context.Context)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jobs = append(p.jobs, job)
+	return nil
+}
+
+func (p *heldPool) job(i int) func(context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jobs[i]
+}
+
+func (p *heldPool) held() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.jobs)
+}
+
+// recordingSession records the offsets marked in it
+type recordingSession struct {
+	sarama.ConsumerGroupSession
+
+	ctx    context.Context
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *recordingSession) Context() context.Context { return s.ctx }
+
+func (s *recordingSession) MarkMessage(message *sarama.ConsumerMessage, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, message.Offset)
+}
+
+func (s *recordingSession) offsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+// channelClaim delivers the messages sent on its channel
+type channelClaim struct {
+	sarama.ConsumerGroupClaim
+
+	messages      chan *sarama.ConsumerMessage
+	highWaterMark int64
+}
+
+func (c *channelClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func (c *channelClaim) HighWaterMarkOffset() int64 { return c.highWaterMark }
+
+// nopNotificationService accepts every event
+type nopNotificationService struct {
+	services.NotificationService
+}
+
+func (nopNotificationService) HandleUserEvent(ctx context.Context, eventType string, payload []byte) error {
+	return nil
+}
+
+func newTestConsumer(pool JobSubmitter) *Consumer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Consumer{
+		notificationSvc: nopNotificationService{},
+		pool:            pool,
+		logger:          zap.NewNop(),
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+func TestConsumer_ConsumeClaimDrainsPool(t *testing.T) {
+	pool := &heldPool{}
+	consumer := newTestConsumer(pool)
+	defer consumer.cancel()
+
+	session := &recordingSession{ctx: context.Background()}
+	claim := &channelClaim{messages: make(chan *sarama.ConsumerMessage, 3)}
+	for offset := int64(0); offset < 3; offset++ {
+		claim.messages <- &sarama.ConsumerMessage{Key: []byte("user.registered"), Value: []byte(`{}`), Offset: offset}
+	}
+	// The claim ends, as it does on a rebalance, with every message still on the pool
+	close(claim.messages)
+
+	returned := make(chan error)
+	go func() { returned <- consumer.ConsumeClaim(session, claim) }()
+	require.Eventually(t, func() bool { return pool.held() == 3 }, time.Second, time.Millisecond)
+
+	// A later message finishing first isn't marked past the earlier ones in flight
+	pool.job(2)(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, session.offsets())
+
+	pool.job(0)(context.Background())
+	assert.Eventually(t, func() bool { return assert.ObjectsAreEqual([]int64{0}, session.offsets()) }, time.Second, time.Millisecond)
+
+	select {
+	case <-returned:
+		t.Fatal("claim returned with a message still on the pool")
+	default:
+	}
+
+	// The claim returns once the last message is handled, having marked it
+	pool.job(1)(context.Background())
+	select {
+	case err := <-returned:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("claim didn't return once its messages were handled")
+	}
+	assert.Equal(t, []int64{0, 2}, session.offsets())
+}
+
+func TestConsumer_StopLeavesUnhandledMessagesUnmarked(t *testing.T) {
+	pool := &heldPool{}
+	consumer := newTestConsumer(pool)
+
+	session := &recordingSession{ctx: context.Background()}
+	claim := &channelClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Key: []byte("user.registered"), Value: []byte(`{}`), Offset: 0}
+	claim.messages <- &sarama.ConsumerMessage{Key: []byte("user.registered"), Value: []byte(`{}`), Offset: 1}
+	close(claim.messages)
+
+	returned := make(chan error)
+	go func() { returned <- consumer.ConsumeClaim(session, claim) }()
+	require.Eventually(t, func() bool { return pool.held() == 2 }, time.Second, time.Millisecond)
+	pool.job(0)(context.Background())
+
+	// Stopping the consumer ends the wait; the message still in flight is
+	// redelivered after a restart
+	consumer.cancel()
+	select {
+	case err := <-returned:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("claim didn't return once the consumer stopped")
+	}
+	pool.job(1)(context.Background())
+	assert.Equal(t, []int64{0}, session.offsets())
+}
+
+func TestConsumer_ConsumeClaimReportsLag(t *testing.T) {
+	pool := &heldPool{}
+	consumer := newTestConsumer(pool)
+	defer consumer.cancel()
+
+	lag := metrics.KafkaConsumerLag.WithLabelValues("lag-events", "2")
+	consumed := metrics.KafkaMessagesConsumedTotal.WithLabelValues("lag-events", "2")
+	consumedBefore := testutil.ToFloat64(consumed)
+
+	session := &recordingSession{ctx: context.Background()}
+	claim := &channelClaim{messages: make(chan *sarama.ConsumerMessage, 3), highWaterMark: 13}
+	for offset := int64(10); offset < 13; offset++ {
+		claim.messages <- &sarama.ConsumerMessage{Topic: "lag-events", Partition: 2, Key: []byte("user.registered"), Value: []byte(`{}`), Offset: offset}
+	}
+
+	returned := make(chan error)
+	go func() { returned <- consumer.ConsumeClaim(session, claim) }()
+	require.Eventually(t, func() bool { return pool.held() == 3 }, time.Second, time.Millisecond)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(consumed)-consumedBefore)
+
+	// Nothing is committed until the messages are handled
+	assert.Equal(t, float64(3), testutil.ToFloat64(lag))
+
+	pool.job(0)(context.Background())
+	pool.job(1)(context.Background())
+	assert.Eventually(t, func() bool { return testutil.ToFloat64(lag) == 1 }, time.Second, time.Millisecond)
+
+	// The lag of a partition no longer claimed isn't reported
+	pool.job(2)(context.Background())
+	close(claim.messages)
+	select {
+	case err := <-returned:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("claim didn't return once its messages were handled")
+	}
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.KafkaConsumerLag))
+}
+
+// decodingNotificationService reports payloads that aren't JSON as malformed
+// and fails every other event
+type decodingNotificationService struct {
+	services.NotificationService
+}
+
+func (decodingNotificationService) HandleUserEvent(ctx context.Context, eventType string, payload []byte) error {
+	if !json.Valid(payload) {
+		return model.ErrMalformedEvent{EventType: eventType, Err: errors.New("unexpected end of JSON input")}
+	}
+	return errors.New("template store unavailable")
+}
+
+// recordingDeadLetters records the messages sent to it
+type recordingDeadLetters struct {
+	messages []*sarama.ConsumerMessage
+	reasons  []string
+}
+
+func (d *recordingDeadLetters) Send(message *sarama.ConsumerMessage, reason string) error {
+	d.messages = append(d.messages, message)
+	d.reasons = append(d.reasons, reason)
+	return nil
+}
+
+func TestConsumer_PoisonMessages(t *testing.T) {
+	consumer := newTestConsumer(nil)
+	defer consumer.cancel()
+	consumer.notificationSvc = decodingNotificationService{}
+	deadLetters := &recordingDeadLetters{}
+	consumer.SetDeadLetters(deadLetters)
+
+	poison := metrics.PoisonMessagesTotal.WithLabelValues("poison-events")
+	failed := metrics.KafkaMessageErrorsTotal.WithLabelValues("poison-events", "0")
+	poisonBefore, failedBefore := testutil.ToFloat64(poison), testutil.ToFloat64(failed)
+
+	corrupt := &sarama.ConsumerMessage{Topic: "poison-events", Key: []byte("user.registered"), Value: []byte(`{"email": "user@`), Offset: 0}
+	valid := &sarama.ConsumerMessage{Topic: "poison-events", Key: []byte("user.registered"), Value: []byte(`{"email": "user@example.com"}`), Offset: 1}
+	consumer.processMessage(corrupt)
+	consumer.processMessage(valid)
+
+	// Only the corrupt payload is poison and moved aside; the valid event failed downstream
+	assert.Equal(t, float64(1), testutil.ToFloat64(poison)-poisonBefore)
+	assert.Equal(t, float64(1), testutil.ToFloat64(failed)-failedBefore)
+	require.Equal(t, []*sarama.ConsumerMessage{corrupt}, deadLetters.messages)
+	assert.Contains(t, deadLetters.reasons[0], "malformed user.registered event")
+}
+
+func TestTruncatePayload(t *testing.T) {
+	assert.Equal(t, "short", truncatePayload([]byte("short")))
+	truncated := truncatePayload([]byte(strings.Repeat("x", maxLoggedPayload+10)))
+	assert.Equal(t, strings.Repeat("x", maxLoggedPayload)+"...", truncated)
+}
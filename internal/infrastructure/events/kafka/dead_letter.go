@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+// Headers a dead-lettered message carries besides its original ones
+const (
+	deadLetterReasonHeader    = "X-Dead-Letter-Reason"
+	deadLetterTopicHeader     = "X-Original-Topic"
+	deadLetterPartitionHeader = "X-Original-Partition"
+	deadLetterOffsetHeader    = "X-Original-Offset"
+)
+
+// DeadLetterProducer moves messages that can't be handled to a dead-letter
+// topic, keeping their key, value and headers, so they can be inspected and
+// replayed once fixed instead of being lost when the consumer moves on
+type DeadLetterProducer struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewDeadLetterProducer connects a producer for the dead-letter topic to brokers
+func NewDeadLetterProducer(brokers []string, client ClientConfig, topic string) (*DeadLetterProducer, error) {
+	config, err := client.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dead-letter producer: %w", err)
+	}
+	return &DeadLetterProducer{producer: producer, topic: topic}, nil
+}
+
+// Send writes message to the dead-letter topic with the reason it couldn't be
+// handled and where it was read from
+func (p *DeadLetterProducer) Send(message *sarama.ConsumerMessage, reason string) error {
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+4)
+	for _, header := range message.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(deadLetterReasonHeader), Value: []byte(reason)},
+		sarama.RecordHeader{Key: []byte(deadLetterTopicHeader), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(deadLetterPartitionHeader), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(deadLetterOffsetHeader), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+	)
+
+	_, _, err := p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   p.topic,
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("error sending message to dead-letter topic %s: %w", p.topic, err)
+	}
+	return nil
+}
+
+// Close disconnects the producer
+func (p *DeadLetterProducer) Close() error {
+	if err := p.producer.Close(); err != nil {
+		return fmt.Errorf("error closing dead-letter producer: %w", err)
+	}
+	return nil
+}
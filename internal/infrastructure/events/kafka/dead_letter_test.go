@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterProducer_Send(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	defer producer.Close()
+	deadLetters := &DeadLetterProducer{producer: producer, topic: "user-events-dlq"}
+
+	var sent *sarama.ProducerMessage
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+		sent = message
+		return nil
+	})
+	require.NoError(t, deadLetters.Send(&sarama.ConsumerMessage{
+		Topic:     "user-events",
+		Partition: 3,
+		Offset:    42,
+		Key:       []byte("user.registered"),
+		Value:     []byte(`{"email":`),
+		Headers:   []*sarama.RecordHeader{{Key: []byte(tenantHeader), Value: []byte("tenant-a")}},
+	}, "malformed user.registered event"))
+
+	// The message keeps its key, value and headers, and records where it came from
+	require.NotNil(t, sent)
+	assert.Equal(t, "user-events-dlq", sent.Topic)
+	assert.Equal(t, sarama.ByteEncoder("user.registered"), sent.Key)
+	assert.Equal(t, sarama.ByteEncoder(`{"email":`), sent.Value)
+	headers := make(map[string]string)
+	for _, header := range sent.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	assert.Equal(t, map[string]string{
+		tenantHeader:              "tenant-a",
+		deadLetterReasonHeader:    "malformed user.registered event",
+		deadLetterTopicHeader:     "user-events",
+		deadLetterPartitionHeader: "3",
+		deadLetterOffsetHeader:    "42",
+	}, headers)
+
+	producer.ExpectSendMessageAndFail(errors.New("broker down"))
+	assert.ErrorContains(t, deadLetters.Send(&sarama.ConsumerMessage{}, "malformed"), "broker down")
+}
@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// partitionLag reports how many messages of a claimed partition are beyond
+// its committed offset, i.e. its high-water mark less the offset of the next
+// message to commit. It is only used from the partition's ConsumeClaim.
+type partitionLag struct {
+	claim     sarama.ConsumerGroupClaim
+	topic     string
+	partition int32
+	committed int64 // Offset of the next message to commit, or -1 before the first message
+}
+
+func newPartitionLag(claim sarama.ConsumerGroupClaim) *partitionLag {
+	return &partitionLag{claim: claim, committed: -1}
+}
+
+// received records a message received from the partition. Until a message is
+// marked, the committed offset is taken to be that of the first one received.
+func (l *partitionLag) received(message *sarama.ConsumerMessage) {
+	if l.committed < 0 {
+		l.topic, l.partition, l.committed = message.Topic, message.Partition, message.Offset
+	}
+	l.record()
+}
+
+// marked records a message marked for committing
+func (l *partitionLag) marked(message *sarama.ConsumerMessage) {
+	l.committed = message.Offset + 1
+	l.record()
+}
+
+func (l *partitionLag) record() {
+	lag := l.claim.HighWaterMarkOffset() - l.committed
+	if lag < 0 {
+		lag = 0
+	}
+	metrics.SetKafkaConsumerLag(l.topic, l.partition, lag)
+}
+
+// clear stops reporting the lag once the claim ends, since the partition may
+// be consumed by another instance after a rebalance
+func (l *partitionLag) clear() {
+	if l.committed >= 0 {
+		metrics.ClearKafkaConsumerLag(l.topic, l.partition)
+	}
+}
@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// partitionOffsets tracks the messages of one claimed partition that were handed
+// to the pool. Workers finish messages in any order, but committing an offset
+// commits every offset before it, so a message is only marked once every earlier
+// message has finished too. Otherwise a restart would skip messages still in flight.
+type partitionOffsets struct {
+	mu       sync.Mutex
+	inFlight []*sarama.ConsumerMessage // In the order they were received
+	finished map[int64]bool
+
+	// notify is signalled when a message finishes
+	notify chan struct{}
+}
+
+func newPartitionOffsets() *partitionOffsets {
+	return &partitionOffsets{
+		finished: make(map[int64]bool),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// start records a message handed to a worker
+func (o *partitionOffsets) start(message *sarama.ConsumerMessage) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.inFlight = append(o.inFlight, message)
+}
+
+// finish records a message as handled. It is safe to call from any goroutine.
+func (o *partitionOffsets) finish(message *sarama.ConsumerMessage) {
+	o.mu.Lock()
+	o.finished[message.Offset] = true
+	o.mu.Unlock()
+
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// ready removes the finished messages no unfinished message precedes and returns
+// the last of them, which is the one to mark; it returns nil if there is none
+func (o *partitionOffsets) ready() *sarama.ConsumerMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var last *sarama.ConsumerMessage
+	for len(o.inFlight) > 0 && o.finished[o.inFlight[0].Offset] {
+		last = o.inFlight[0]
+		delete(o.finished, last.Offset)
+		o.inFlight = o.inFlight[1:]
+	}
+	return last
+}
+
+// pending returns the number of messages not yet removed by ready
+func (o *partitionOffsets) pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.inFlight)
+}
@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionOffsets_MarksOnlyContiguousFinishedMessages(t *testing.T) {
+	offsets := newPartitionOffsets()
+	messages := make([]*sarama.ConsumerMessage, 4)
+	for i := range messages {
+		messages[i] = &sarama.ConsumerMessage{Partition: 0, Offset: int64(10 + i)}
+		offsets.start(messages[i])
+	}
+
+	// Later messages finishing first don't move the offset past an earlier one in flight
+	offsets.finish(messages[1])
+	offsets.finish(messages[3])
+	assert.Nil(t, offsets.ready())
+	assert.Equal(t, 4, offsets.pending())
+
+	offsets.finish(messages[0])
+	assert.Equal(t, messages[1], offsets.ready())
+	assert.Equal(t, 2, offsets.pending())
+
+	offsets.finish(messages[2])
+	assert.Equal(t, messages[3], offsets.ready())
+	assert.Equal(t, 0, offsets.pending())
+	assert.Nil(t, offsets.ready())
+}
+
+func TestPartitionOffsets_NotifiesWhenMessagesFinish(t *testing.T) {
+	offsets := newPartitionOffsets()
+	first := &sarama.ConsumerMessage{Offset: 1}
+	second := &sarama.ConsumerMessage{Offset: 2}
+	offsets.start(first)
+	offsets.start(second)
+
+	// Finishing never blocks, however many notifications are outstanding
+	offsets.finish(first)
+	offsets.finish(second)
+
+	select {
+	case <-offsets.notify:
+	default:
+		t.Fatal("finishing a message didn't notify")
+	}
+	require.Equal(t, second, offsets.ready())
+}
@@ -0,0 +1,245 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"go.uber.org/zap"
+)
+
+// EventReplayHandler handles replayed user events, checking first whether an
+// event already produced a notification
+type EventReplayHandler interface {
+	HandleUserEvent(ctx context.Context, eventType string, payload []byte) error
+	UserEventHandled(ctx context.Context, eventType string) (bool, error)
+}
+
+// offsetFinder finds a partition's offsets, the oldest, the newest or the
+// first at or after a time in milliseconds; sarama.Client implements it
+type offsetFinder interface {
+	GetOffset(topic string, partition int32, time int64) (int64, error)
+}
+
+// Replayer handles a range of user events again, e.g. after a bug kept them
+// from producing their notifications. It reads partitions directly rather
+// than joining a consumer group, so it commits no offsets and the live
+// consumer's progress is left alone.
+type Replayer struct {
+	offsets  offsetFinder
+	consumer sarama.Consumer
+	handler  EventReplayHandler
+	logger   *zap.Logger
+	close    func() error
+}
+
+// NewReplayer connects a replayer to brokers
+func NewReplayer(brokers []string, clientConfig ClientConfig, handler EventReplayHandler, logger *zap.Logger) (*Replayer, error) {
+	config, err := clientConfig.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka client: %w", err)
+	}
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error creating kafka consumer: %w", err)
+	}
+
+	replayer := newReplayer(client, consumer, handler, logger)
+	replayer.close = func() error {
+		consumer.Close()
+		return client.Close()
+	}
+	return replayer, nil
+}
+
+func newReplayer(offsets offsetFinder, consumer sarama.Consumer, handler EventReplayHandler, logger *zap.Logger) *Replayer {
+	return &Replayer{
+		offsets:  offsets,
+		consumer: consumer,
+		handler:  handler,
+		logger:   logger,
+		close:    consumer.Close,
+	}
+}
+
+// Close disconnects the replayer
+func (r *Replayer) Close() error {
+	if err := r.close(); err != nil {
+		return fmt.Errorf("error closing replayer: %w", err)
+	}
+	return nil
+}
+
+// Replay reads the events in rng, one partition after another, and handles
+// those that didn't already produce a notification. Events are handled one at
+// a time with the tenant and correlation ID their headers carry. It stops at
+// the first error reading a partition or when ctx is done, returning what it
+// had done so far.
+func (r *Replayer) Replay(ctx context.Context, rng model.EventReplayRange, options model.EventReplayOptions) (model.EventReplayResult, error) {
+	var result model.EventReplayResult
+	if err := rng.Validate(); err != nil {
+		return result, err
+	}
+
+	partitions := rng.Partitions
+	if len(partitions) == 0 {
+		var err error
+		if partitions, err = r.consumer.Partitions(rng.Topic); err != nil {
+			return result, fmt.Errorf("error listing partitions of %s: %w", rng.Topic, err)
+		}
+	}
+
+	for _, partition := range partitions {
+		if err := r.replayPartition(ctx, rng, partition, options, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// replayPartition handles the events of one partition in rng
+func (r *Replayer) replayPartition(ctx context.Context, rng model.EventReplayRange, partition int32, options model.EventReplayOptions, result *model.EventReplayResult) error {
+	start, end, err := r.partitionRange(rng, partition)
+	if err != nil {
+		return err
+	}
+	logger := r.logger.With(zap.String("topic", rng.Topic), zap.Int32("partition", partition))
+	logger.Info("replaying partition", zap.Int64("from", start), zap.Int64("until", end))
+	if start >= end {
+		return nil
+	}
+
+	consumer, err := r.consumer.ConsumePartition(rng.Topic, partition, start)
+	if err != nil {
+		return fmt.Errorf("error reading %s/%d: %w", rng.Topic, partition, err)
+	}
+	defer consumer.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-consumer.Errors():
+			return fmt.Errorf("error reading %s/%d: %w", rng.Topic, partition, err)
+		case message := <-consumer.Messages():
+			if message.Offset >= end {
+				return nil
+			}
+			r.replayMessage(ctx, message, options, result)
+			if message.Offset+1 >= end {
+				return nil
+			}
+		}
+	}
+}
+
+// partitionRange resolves the offsets of a partition's events in rng, from
+// the first up to but excluding end, within those the partition retains
+func (r *Replayer) partitionRange(rng model.EventReplayRange, partition int32) (start, end int64, err error) {
+	oldest, err := r.offsets.GetOffset(rng.Topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error finding oldest offset of %s/%d: %w", rng.Topic, partition, err)
+	}
+	newest, err := r.offsets.GetOffset(rng.Topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error finding newest offset of %s/%d: %w", rng.Topic, partition, err)
+	}
+
+	start, end = oldest, newest
+	switch {
+	case rng.FromOffset != nil:
+		start = *rng.FromOffset
+	case !rng.Since.IsZero():
+		if start, err = r.offsetAt(rng.Topic, partition, rng.Since.UnixMilli(), newest); err != nil {
+			return 0, 0, err
+		}
+	}
+	switch {
+	case rng.ToOffset != nil:
+		end = *rng.ToOffset + 1
+	case !rng.Until.IsZero():
+		if end, err = r.offsetAt(rng.Topic, partition, rng.Until.UnixMilli(), newest); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if start < oldest {
+		start = oldest
+	}
+	if end > newest {
+		end = newest
+	}
+	return start, end, nil
+}
+
+// offsetAt returns the offset of a partition's first event at or after
+// millis, or newest if there is none
+func (r *Replayer) offsetAt(topic string, partition int32, millis, newest int64) (int64, error) {
+	offset, err := r.offsets.GetOffset(topic, partition, millis)
+	if err != nil {
+		return 0, fmt.Errorf("error finding offset of %s/%d by time: %w", topic, partition, err)
+	}
+	if offset < 0 {
+		return newest, nil
+	}
+	return offset, nil
+}
+
+// replayMessage handles a single event unless it already produced a
+// notification or can't be checked for one, recording the outcome in result
+func (r *Replayer) replayMessage(ctx context.Context, message *sarama.ConsumerMessage, options model.EventReplayOptions, result *model.EventReplayResult) {
+	eventType := string(message.Key)
+	logger := r.logger.With(
+		zap.String("topic", message.Topic),
+		zap.Int32("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
+		zap.String("eventType", eventType),
+	)
+
+	if tenant := tenantID(message); tenant != "" {
+		ctx = model.ContextWithTenant(ctx, tenant)
+	}
+
+	correlationID := headerValue(message, correlationIDHeader)
+	switch {
+	case model.IsValidCorrelationID(correlationID):
+		ctx = model.ContextWithCorrelationID(ctx, correlationID)
+		handled, err := r.handler.UserEventHandled(ctx, eventType)
+		if err != nil {
+			logger.Error("error checking replayed event", zap.Error(err))
+			result.Failed++
+			return
+		}
+		if handled {
+			result.AlreadySent++
+			return
+		}
+	case options.Force:
+		ctx = model.ContextWithCorrelationID(ctx, uuid.NewString())
+	default:
+		logger.Warn("skipping replayed event without a correlation ID")
+		result.Unguarded++
+		return
+	}
+
+	if options.DryRun {
+		logger.Info("would replay event")
+		result.Replayed++
+		return
+	}
+
+	if err := r.handler.HandleUserEvent(ctx, eventType, message.Value); err != nil {
+		logger.Error("error handling replayed event", zap.Error(err))
+		result.Failed++
+		return
+	}
+	logger.Info("replayed event")
+	result.Replayed++
+}
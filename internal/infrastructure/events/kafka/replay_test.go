@@ -0,0 +1,131 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeOffsets serves a partition's oldest and newest offsets and looks up
+// offsets by time in a table
+type fakeOffsets struct {
+	oldest, newest int64
+	byTime         map[int64]int64
+}
+
+func (o *fakeOffsets) GetOffset(topic string, partition int32, time int64) (int64, error) {
+	switch time {
+	case sarama.OffsetOldest:
+		return o.oldest, nil
+	case sarama.OffsetNewest:
+		return o.newest, nil
+	}
+	if offset, ok := o.byTime[time]; ok {
+		return offset, nil
+	}
+	return -1, nil
+}
+
+// replayHandler records the events handled and reports those whose
+// correlation ID is in handled as already sent
+type replayHandler struct {
+	mu       sync.Mutex
+	handled  map[string]bool
+	replayed []string
+}
+
+func (h *replayHandler) HandleUserEvent(ctx context.Context, eventType string, payload []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replayed = append(h.replayed, string(payload))
+	return nil
+}
+
+func (h *replayHandler) UserEventHandled(ctx context.Context, eventType string) (bool, error) {
+	return h.handled[model.CorrelationIDFromContext(ctx)], nil
+}
+
+func replayMessage(value, correlationID string) *sarama.ConsumerMessage {
+	message := &sarama.ConsumerMessage{Key: []byte("user.registered"), Value: []byte(value)}
+	if correlationID != "" {
+		message.Headers = []*sarama.RecordHeader{{Key: []byte(correlationIDHeader), Value: []byte(correlationID)}}
+	}
+	return message
+}
+
+func TestReplayer_ReplaysOffsetRange(t *testing.T) {
+	consumer := mocks.NewConsumer(t, nil)
+	partition := consumer.ExpectConsumePartition("user-events", 0, 2)
+	for _, message := range []*sarama.ConsumerMessage{
+		replayMessage("sent", "req-sent"),
+		replayMessage("lost", "req-lost"),
+		replayMessage("no-id", ""),
+		replayMessage("after", "req-after"),
+	} {
+		partition.YieldMessage(message)
+	}
+
+	handler := &replayHandler{handled: map[string]bool{"req-sent": true}}
+	replayer := newReplayer(&fakeOffsets{oldest: 0, newest: 10}, consumer, handler, zap.NewNop())
+	defer replayer.Close()
+
+	from, to := int64(2), int64(4)
+	result, err := replayer.Replay(context.Background(), model.EventReplayRange{
+		Topic:      "user-events",
+		Partitions: []int32{0},
+		FromOffset: &from,
+		ToOffset:   &to,
+	}, model.EventReplayOptions{})
+	require.NoError(t, err)
+
+	// Only the event that produced no notification is handled again
+	assert.Equal(t, model.EventReplayResult{Replayed: 1, AlreadySent: 1, Unguarded: 1}, result)
+	assert.Equal(t, []string{"lost"}, handler.replayed)
+}
+
+func TestReplayer_DryRunAndForce(t *testing.T) {
+	consumer := mocks.NewConsumer(t, nil)
+	consumer.SetTopicMetadata(map[string][]int32{"user-events": {0}})
+	partition := consumer.ExpectConsumePartition("user-events", 0, 5)
+	partition.YieldMessage(replayMessage("guarded", "req-1"))
+	partition.YieldMessage(replayMessage("no-id", ""))
+
+	handler := &replayHandler{}
+	offsets := &fakeOffsets{oldest: 3, newest: 7, byTime: map[int64]int64{1000: 5}}
+	replayer := newReplayer(offsets, consumer, handler, zap.NewNop())
+	defer replayer.Close()
+
+	// A dry run reads every partition from the time given to the newest event
+	result, err := replayer.Replay(context.Background(), model.EventReplayRange{
+		Topic: "user-events",
+		Since: time.UnixMilli(1000),
+	}, model.EventReplayOptions{DryRun: true, Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, model.EventReplayResult{Replayed: 2}, result)
+	assert.Empty(t, handler.replayed)
+}
+
+func TestReplayer_SkipsEmptyRanges(t *testing.T) {
+	consumer := mocks.NewConsumer(t, nil)
+	replayer := newReplayer(&fakeOffsets{oldest: 3, newest: 3}, consumer, &replayHandler{}, zap.NewNop())
+	defer replayer.Close()
+
+	// Nothing is read from a partition with no events in the range
+	result, err := replayer.Replay(context.Background(), model.EventReplayRange{
+		Topic:      "user-events",
+		Partitions: []int32{0},
+	}, model.EventReplayOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, model.EventReplayResult{}, result)
+
+	_, err = replayer.Replay(context.Background(), model.EventReplayRange{}, model.EventReplayOptions{})
+	assert.ErrorIs(t, err, model.ErrValidation)
+}
@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"github.com/xdg-go/scram"
+)
+
+// SCRAM hash functions, for newSCRAMClient
+var (
+	scramSHA256 scram.HashGeneratorFcn = sha256.New
+	scramSHA512 scram.HashGeneratorFcn = sha512.New
+)
+
+// scramClient authenticates with SCRAM (RFC 5802), which sarama leaves to the
+// application, through github.com/xdg-go/scram as sarama's examples do
+type scramClient struct {
+	*scram.Client
+	*scram.ClientConversation
+	scram.HashGeneratorFcn
+
+	// newNonce replaces the random client nonce, for tests
+	newNonce scram.NonceGeneratorFcn
+}
+
+func newSCRAMClient(hash scram.HashGeneratorFcn) *scramClient {
+	return &scramClient{HashGeneratorFcn: hash}
+}
+
+// Begin starts an exchange with the given credentials
+func (c *scramClient) Begin(username, password, authzID string) error {
+	client, err := c.HashGeneratorFcn.NewClient(username, password, authzID)
+	if err != nil {
+		return err
+	}
+	if c.newNonce != nil {
+		client = client.WithNonceGenerator(c.newNonce)
+	}
+	c.Client = client
+	c.ClientConversation = client.NewConversation()
+	return nil
+}
+
+// Step answers the server's challenge: the first step sends the client's
+// first message, the second proves the password and the third checks the
+// server's signature
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.ClientConversation.Step(challenge)
+}
+
+// Done reports whether the exchange is over
+func (c *scramClient) Done() bool {
+	return c.ClientConversation.Done()
+}
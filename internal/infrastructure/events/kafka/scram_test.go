@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The SCRAM-SHA-256 exchange from RFC 7677
+func TestSCRAMClient_Exchange(t *testing.T) {
+	client := newSCRAMClient(scramSHA256)
+	client.newNonce = func() string { return "rOprNGfwEbeRWgbNEkqO" }
+	require.NoError(t, client.Begin("user", "pencil", ""))
+
+	first, err := client.Step("")
+	require.NoError(t, err)
+	assert.Equal(t, "n,,n=user,r=rOprNGfwEbeRWgbNEkqO", first)
+	assert.False(t, client.Done())
+
+	final, err := client.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	require.NoError(t, err)
+	assert.Equal(t, "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", final)
+	assert.False(t, client.Done())
+
+	_, err = client.Step("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")
+	require.NoError(t, err)
+	assert.True(t, client.Done())
+}
+
+func TestSCRAMClient_RejectsImpostors(t *testing.T) {
+	begin := func() *scramClient {
+		client := newSCRAMClient(scramSHA256)
+		client.newNonce = func() string { return "rOprNGfwEbeRWgbNEkqO" }
+		require.NoError(t, client.Begin("user", "pencil", ""))
+		_, err := client.Step("")
+		require.NoError(t, err)
+		return client
+	}
+
+	// A server must extend the client's nonce
+	_, err := begin().Step("r=someoneElse,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	assert.Error(t, err)
+
+	// and prove it knows the password
+	client := begin()
+	_, err = client.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	require.NoError(t, err)
+	_, err = client.Step("v=AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	assert.ErrorContains(t, err, "server validation failed")
+
+	client = begin()
+	_, err = client.Step("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	require.NoError(t, err)
+	_, err = client.Step("e=invalid-proof")
+	assert.ErrorContains(t, err, "invalid-proof")
+}
@@ -0,0 +1,23 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"go.uber.org/zap"
+)
+
+// WithContext returns logger annotated with the correlation ID ctx carries, so
+// every line logged while handling a request or event can be tied back to it
+func WithContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	return WithCorrelationID(logger, model.CorrelationIDFromContext(ctx))
+}
+
+// WithCorrelationID returns logger annotated with correlationID, or logger
+// itself if correlationID is empty
+func WithCorrelationID(logger *zap.Logger, correlationID string) *zap.Logger {
+	if correlationID == "" {
+		return logger
+	}
+	return logger.With(zap.String("correlation_id", correlationID))
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log formats
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Config holds the logger configuration
+type Config struct {
+	Level  string // Minimum level logged: debug, info, warn, error, dpanic, panic or fatal
+	Format string // FormatJSON or FormatConsole
+}
+
+// DefaultConfig returns the production defaults: JSON at info level
+func DefaultConfig() Config {
+	return Config{
+		Level:  "info",
+		Format: FormatJSON,
+	}
+}
+
+// NewLogger builds a logger from config. JSON output uses zap's production
+// encoding; console output is the human-readable development encoding, with
+// the same sampling so local runs behave like production.
+func NewLogger(config Config) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(strings.ToLower(strings.TrimSpace(config.Level)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", config.Level, err)
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+
+	switch strings.ToLower(strings.TrimSpace(config.Format)) {
+	case FormatJSON, "":
+	case FormatConsole:
+		zapConfig.Encoding = FormatConsole
+		zapConfig.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be %s or %s", config.Format, FormatJSON, FormatConsole)
+	}
+
+	return zapConfig.Build()
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		expectedLevel zapcore.Level
+		expectError   bool
+	}{
+		{name: "defaults", config: DefaultConfig(), expectedLevel: zapcore.InfoLevel},
+		{name: "debug console", config: Config{Level: "debug", Format: "console"}, expectedLevel: zapcore.DebugLevel},
+		{name: "level is case insensitive", config: Config{Level: "Warn", Format: "JSON"}, expectedLevel: zapcore.WarnLevel},
+		{name: "empty format is json", config: Config{Level: "error"}, expectedLevel: zapcore.ErrorLevel},
+		{name: "invalid level", config: Config{Level: "verbose", Format: FormatJSON}, expectError: true},
+		{name: "empty level", config: Config{Format: FormatJSON}, expectedLevel: zapcore.InfoLevel},
+		{name: "invalid format", config: Config{Level: "info", Format: "xml"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := NewLogger(tt.config)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, logger.Core().Enabled(tt.expectedLevel))
+			assert.False(t, logger.Core().Enabled(tt.expectedLevel-1))
+		})
+	}
+}
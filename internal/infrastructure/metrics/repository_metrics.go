@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -59,6 +61,188 @@ var (
 		},
 		[]string{"type"}, // hit or miss
 	)
+
+	// OversizeContentTotal tracks notifications whose content exceeded its channel's limit
+	OversizeContentTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_oversize_content_total",
+			Help: "Number of notifications with content over the channel limit",
+		},
+		[]string{"type", "action"}, // rejected or truncated
+	)
+
+	// SMSSegmentsSentTotal tracks the SMS segments sent, which SMS is billed by
+	SMSSegmentsSentTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_sms_segments_sent_total",
+			Help: "Number of SMS segments sent",
+		},
+		[]string{"encoding"},
+	)
+
+	// SuppressedNotificationsTotal tracks emails not sent because their recipient is on the suppression list
+	SuppressedNotificationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_suppressed_total",
+			Help: "Number of notifications not sent to suppressed recipients",
+		},
+		[]string{"reason"},
+	)
+
+	// NotificationEndToEndLatency tracks the time from a notification's creation
+	// to its terminal status, which is how long a user waits for it
+	NotificationEndToEndLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "notification_end_to_end_latency_seconds",
+			Help:    "Time from notification creation to its terminal status in seconds",
+			Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600},
+		},
+		[]string{"type", "outcome"},
+	)
+
+	// ProviderRateLimit tracks the configured send rate of each rate-limited provider
+	ProviderRateLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "notification_provider_rate_limit_per_second",
+			Help: "Configured maximum calls per second to a provider",
+		},
+		[]string{"provider"},
+	)
+
+	// ProviderCallsTotal tracks the calls let through each provider's rate limiter;
+	// its rate is the current send rate
+	ProviderCallsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_provider_rate_limited_calls_total",
+			Help: "Number of calls let through a provider's rate limiter",
+		},
+		[]string{"provider"},
+	)
+
+	// ProviderCallsWaiting tracks the calls waiting for their turn under each provider's rate limit
+	ProviderCallsWaiting = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "notification_provider_rate_limit_waiting",
+			Help: "Number of calls waiting for a provider's rate limit",
+		},
+		[]string{"provider"},
+	)
+
+	// ProviderTimeoutsTotal tracks the provider calls abandoned at their send timeout
+	ProviderTimeoutsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_provider_timeouts_total",
+			Help: "Number of provider calls that did not complete within the send timeout",
+		},
+		[]string{"provider"},
+	)
+
+	// SendLimitPerMinute tracks the configured global send limit
+	SendLimitPerMinute = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_send_limit_per_minute",
+			Help: "Configured maximum notifications sent per minute before sending is paused",
+		},
+	)
+
+	// SendLimitSendsTotal tracks the notifications counted against the send
+	// limit; its rate is the current send rate
+	SendLimitSendsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "notification_send_limit_sends_total",
+			Help: "Number of notifications counted against the global send limit",
+		},
+	)
+
+	// SendLimitTripped is 1 while the send limit is throttling notifications
+	SendLimitTripped = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_send_limit_tripped",
+			Help: "Whether the global send limit is throttling notifications (1) or not (0)",
+		},
+	)
+
+	// Leader is 1 while this instance holds the leader lock
+	Leader = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_leader",
+			Help: "Whether this instance is the leader running the single-instance background jobs (1) or not (0)",
+		},
+	)
+
+	// DispatchQueueDepth tracks the notifications waiting for a dispatch worker
+	DispatchQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_dispatch_queue_depth",
+			Help: "Number of notifications queued for a dispatch worker",
+		},
+	)
+
+	// HTTPPanicsTotal tracks the HTTP requests whose handler panicked
+	HTTPPanicsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "notification_http_panics_total",
+			Help: "Number of HTTP requests whose handler panicked and was recovered",
+		},
+	)
+
+	// TemplateFallbacksTotal tracks the event notifications sent with a plain
+	// fallback body because their template wasn't found
+	TemplateFallbacksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_template_fallbacks_total",
+			Help: "Number of event notifications sent with a fallback body because their template was not found",
+		},
+		[]string{"template_type"},
+	)
+
+	// KafkaMessagesConsumedTotal tracks the event messages received from each partition
+	KafkaMessagesConsumedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_kafka_messages_consumed_total",
+			Help: "Number of event messages consumed from Kafka",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	// KafkaMessageHandlingDuration tracks how long handling an event message took
+	KafkaMessageHandlingDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "notification_kafka_message_handling_duration_seconds",
+			Help:    "Duration of handling an event message from Kafka in seconds",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"topic", "partition"},
+	)
+
+	// KafkaMessageErrorsTotal tracks the event messages whose handling failed;
+	// payloads that can't be decoded are counted by PoisonMessagesTotal instead
+	KafkaMessageErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_kafka_message_errors_total",
+			Help: "Number of decoded event messages from Kafka whose handling failed",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	// PoisonMessagesTotal tracks the event messages whose payload couldn't be decoded
+	PoisonMessagesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_poison_messages_total",
+			Help: "Number of event messages from Kafka whose payload could not be decoded",
+		},
+		[]string{"topic"},
+	)
+
+	// KafkaConsumerLag tracks how many messages each partition claimed by this
+	// instance holds beyond its committed offset
+	KafkaConsumerLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "notification_kafka_consumer_lag",
+			Help: "Number of messages in a Kafka partition after the consumer's committed offset",
+		},
+		[]string{"topic", "partition"},
+	)
 )
 
 // RecordOperationDuration records the duration of a repository operation
@@ -72,7 +256,7 @@ func UpdateNotificationStorageSize(notificationType string, sizeBytes float64) {
 	NotificationStorageSize.WithLabelValues(notificationType).Set(sizeBytes)
 }
 
-// UpdateNotificationStatus updates the count of notifications by status
+// UpdateNotificationStatus sets the number of notifications in a status; see StatusReconciler
 func UpdateNotificationStatus(status string, count float64) {
 	NotificationsByStatus.WithLabelValues(status).Set(count)
 }
@@ -95,3 +279,120 @@ func RecordCacheHit() {
 func RecordCacheMiss() {
 	RedisCacheHits.WithLabelValues("miss").Inc()
 }
+
+// RecordOversizeContent records a notification whose content was over its channel's limit
+func RecordOversizeContent(notificationType, action string) {
+	OversizeContentTotal.WithLabelValues(notificationType, action).Inc()
+}
+
+// RecordSMSSegments records the segments of a sent SMS
+func RecordSMSSegments(encoding string, segments int) {
+	SMSSegmentsSentTotal.WithLabelValues(encoding).Add(float64(segments))
+}
+
+// RecordSuppressedNotification records a notification that wasn't sent because its recipient is suppressed
+func RecordSuppressedNotification(reason string) {
+	SuppressedNotificationsTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordDispatchQueued records a notification queued for a dispatch worker
+func RecordDispatchQueued() {
+	DispatchQueueDepth.Inc()
+}
+
+// RecordDispatchDequeued records a queued notification taken by a dispatch worker
+func RecordDispatchDequeued() {
+	DispatchQueueDepth.Dec()
+}
+
+// RecordEndToEndLatency records how long a notification took to reach its terminal status
+func RecordEndToEndLatency(notificationType, outcome string, seconds float64) {
+	NotificationEndToEndLatency.WithLabelValues(notificationType, outcome).Observe(seconds)
+}
+
+// SetProviderRateLimit records a provider's configured rate limit
+func SetProviderRateLimit(provider string, perSecond float64) {
+	ProviderRateLimit.WithLabelValues(provider).Set(perSecond)
+}
+
+// RecordProviderCall records a call let through a provider's rate limiter
+func RecordProviderCall(provider string) {
+	ProviderCallsTotal.WithLabelValues(provider).Inc()
+}
+
+// SetSendLimit records the configured global send limit
+func SetSendLimit(perMinute int) {
+	SendLimitPerMinute.Set(float64(perMinute))
+}
+
+// RecordSendLimitSend records a notification counted against the send limit
+func RecordSendLimitSend() {
+	SendLimitSendsTotal.Inc()
+}
+
+// SetSendLimitTripped records whether the send limit is throttling notifications
+func SetSendLimitTripped(tripped bool) {
+	if tripped {
+		SendLimitTripped.Set(1)
+	} else {
+		SendLimitTripped.Set(0)
+	}
+}
+
+// RecordProviderTimeout records a provider call abandoned at its send timeout
+func RecordProviderTimeout(provider string) {
+	ProviderTimeoutsTotal.WithLabelValues(provider).Inc()
+}
+
+// RecordProviderCallWaiting adds delta to the calls waiting for a provider's rate limit
+func RecordProviderCallWaiting(provider string, delta float64) {
+	ProviderCallsWaiting.WithLabelValues(provider).Add(delta)
+}
+
+// RecordHTTPPanic records an HTTP handler panic that was recovered
+func RecordHTTPPanic() {
+	HTTPPanicsTotal.Inc()
+}
+
+// RecordTemplateFallback records an event notification sent with a fallback body
+func RecordTemplateFallback(templateType string) {
+	TemplateFallbacksTotal.WithLabelValues(templateType).Inc()
+}
+
+// SetLeader records whether this instance is the leader
+func SetLeader(leader bool) {
+	if leader {
+		Leader.Set(1)
+	} else {
+		Leader.Set(0)
+	}
+}
+
+// RecordKafkaMessageConsumed records an event message received from a partition
+func RecordKafkaMessageConsumed(topic string, partition int32) {
+	KafkaMessagesConsumedTotal.WithLabelValues(topic, strconv.Itoa(int(partition))).Inc()
+}
+
+// RecordKafkaMessageHandled records how long handling an event message took and whether it failed
+func RecordKafkaMessageHandled(topic string, partition int32, seconds float64, failed bool) {
+	labels := []string{topic, strconv.Itoa(int(partition))}
+	KafkaMessageHandlingDuration.WithLabelValues(labels...).Observe(seconds)
+	if failed {
+		KafkaMessageErrorsTotal.WithLabelValues(labels...).Inc()
+	}
+}
+
+// SetKafkaConsumerLag records the messages in a partition after its committed offset
+func SetKafkaConsumerLag(topic string, partition int32, lag int64) {
+	KafkaConsumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+// ClearKafkaConsumerLag stops reporting the lag of a partition this instance no longer consumes
+func ClearKafkaConsumerLag(topic string, partition int32) {
+	KafkaConsumerLag.DeleteLabelValues(topic, strconv.Itoa(int(partition)))
+}
+
+// RecordPoisonMessage records an event message whose payload couldn't be decoded
+func RecordPoisonMessage(topic string) {
+	PoisonMessagesTotal.WithLabelValues(topic).Inc()
+}
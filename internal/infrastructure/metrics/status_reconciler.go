@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"go.uber.org/zap"
+)
+
+// StatusCounter counts stored notifications per status across all tenants
+type StatusCounter interface {
+	CountByStatus(ctx context.Context) (map[model.NotificationStatus]int64, error)
+}
+
+// StatusReconciler periodically sets the notifications_by_status_total gauge
+// from the store. Counting at the source keeps the gauge correct across
+// restarts and multiple instances, which per-write deltas cannot.
+type StatusReconciler struct {
+	counter  StatusCounter
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewStatusReconciler creates a reconciler refreshing the gauge every interval
+func NewStatusReconciler(counter StatusCounter, interval time.Duration, logger *zap.Logger) *StatusReconciler {
+	return &StatusReconciler{
+		counter:  counter,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run reconciles immediately and then every interval until ctx is cancelled
+func (r *StatusReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Reconcile(ctx); err != nil {
+			r.logger.Error("error reconciling notification status counts", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile sets the gauge for every status to its current count; statuses
+// without notifications are reported as zero
+func (r *StatusReconciler) Reconcile(ctx context.Context) error {
+	counts, err := r.counter.CountByStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("error counting notifications by status: %w", err)
+	}
+
+	for _, status := range model.NotificationStatuses {
+		UpdateNotificationStatus(string(status), float64(counts[status]))
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package apns
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// ProviderName identifies APNs on the messages it sends
+const ProviderName = "apns"
+
+// APNs environments
+const (
+	EnvironmentSandbox    = "sandbox"
+	EnvironmentProduction = "production"
+)
+
+// Endpoints for each APNs environment
+const (
+	sandboxURL    = "https://api.sandbox.push.apple.com"
+	productionURL = "https://api.push.apple.com"
+)
+
+// tokenRefreshInterval is how long a provider token is reused. APNs rejects
+// tokens older than an hour and throttles refreshing more than every 20 minutes.
+const tokenRefreshInterval = 50 * time.Minute
+
+// Config holds the APNs configuration
+type Config struct {
+	// KeyID identifies the .p8 signing key in the Apple developer account
+	KeyID string
+	// TeamID is the Apple developer team the key belongs to
+	TeamID string
+	// BundleID is the app's bundle ID, sent as the push topic
+	BundleID string
+	// PrivateKey is the PEM-encoded .p8 signing key
+	PrivateKey []byte
+	// Environment selects the sandbox or production APNs endpoint
+	Environment string
+	// BaseURL overrides the endpoint derived from Environment
+	BaseURL string
+	// Timeout bounds each request to APNs
+	Timeout time.Duration
+}
+
+// DefaultConfig returns a default APNs configuration
+func DefaultConfig() Config {
+	return Config{
+		Environment: EnvironmentProduction,
+		Timeout:     10 * time.Second,
+	}
+}
+
+// ErrDeviceTokenInvalid is returned when APNs will never deliver to a device
+// token, because it is malformed, belongs to another app or was unregistered
+type ErrDeviceTokenInvalid struct {
+	Token  string
+	Reason string
+}
+
+func (e ErrDeviceTokenInvalid) Error() string {
+	return fmt.Sprintf("APNs device token is no longer valid: %s", e.Reason)
+}
+
+// Unwrap reports the token as an invalid recipient
+func (e ErrDeviceTokenInvalid) Unwrap() error {
+	return model.ErrInvalidRecipient{Recipient: e.Token}
+}
+
+// ErrTooManyRequests is returned when APNs throttles pushes to a device; the
+// push can be sent again later
+type ErrTooManyRequests struct{}
+
+func (e ErrTooManyRequests) Error() string {
+	return "APNs is throttling pushes to the device"
+}
+
+// Is reports the error as model.ErrProviderUnavailable
+func (e ErrTooManyRequests) Is(target error) bool { return target == model.ErrProviderUnavailable }
+
+// APIError is an error returned by APNs that has no more specific type
+type APIError struct {
+	StatusCode int
+	Reason     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("APNs error (HTTP %d): %s", e.StatusCode, e.Reason)
+}
+
+// Provider sends push notifications to iOS devices through APNs, authenticating
+// with a JWT signed by a .p8 key
+type Provider struct {
+	config  Config
+	baseURL string
+	key     *ecdsa.PrivateKey
+	client  *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewProvider creates a new APNs provider
+func NewProvider(config Config) (*Provider, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM(config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing APNs signing key: %w", err)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		switch config.Environment {
+		case EnvironmentSandbox:
+			baseURL = sandboxURL
+		case EnvironmentProduction, "":
+			baseURL = productionURL
+		default:
+			return nil, fmt.Errorf("unknown APNs environment: %s", config.Environment)
+		}
+	}
+
+	return &Provider{
+		config:  config,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		key:     key,
+		// The default transport negotiates HTTP/2, which APNs requires, over TLS
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+type payload struct {
+	APS aps `json:"aps"`
+}
+
+type aps struct {
+	Alert alert `json:"alert"`
+}
+
+type alert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body"`
+}
+
+type errorResponse struct {
+	Reason string `json:"reason"`
+}
+
+// SendPush sends an alert with the given title and body to a device token,
+// returning the apns-id APNs assigned it
+func (p *Provider) SendPush(ctx context.Context, token, title, message string) (string, error) {
+	start := time.Now()
+	operation := "apns_send_push"
+
+	id, err := p.send(ctx, token, title, message)
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	metrics.RecordOperationDuration(operation, status, time.Since(start).Seconds())
+
+	return id, err
+}
+
+func (p *Provider) send(ctx context.Context, token, title, message string) (string, error) {
+	body, err := json.Marshal(payload{APS: aps{Alert: alert{Title: title, Body: message}}})
+	if err != nil {
+		return "", fmt.Errorf("error encoding APNs payload: %w", err)
+	}
+
+	authToken, err := p.authToken()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error creating APNs request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", p.config.BundleID)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error calling APNs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		// APNs echoes the apns-id request header, generating one when it's absent
+		return resp.Header.Get("apns-id"), nil
+	}
+
+	var apiErr errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+		apiErr.Reason = http.StatusText(resp.StatusCode)
+	}
+
+	return "", classifyError(resp.StatusCode, apiErr.Reason, token)
+}
+
+// classifyError maps APNs failures the caller should treat differently to typed errors
+func classifyError(statusCode int, reason, token string) error {
+	switch reason {
+	case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+		return ErrDeviceTokenInvalid{Token: token, Reason: reason}
+	case "TooManyRequests":
+		return ErrTooManyRequests{}
+	default:
+		return &APIError{StatusCode: statusCode, Reason: reason}
+	}
+}
+
+// authToken returns the provider token, signing a new one once the current one is due for refresh
+func (p *Provider) authToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.token != "" && now.Sub(p.issuedAt) < tokenRefreshInterval {
+		return p.token, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.config.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.config.KeyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("error signing APNs provider token: %w", err)
+	}
+
+	p.token = signed
+	p.issuedAt = now
+	return signed, nil
+}
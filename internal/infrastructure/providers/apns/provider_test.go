@@ -0,0 +1,191 @@
+package apns
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ services.PushProvider = (*Provider)(nil)
+
+func generateKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func setupTestProvider(t *testing.T, handler func(key *ecdsa.PrivateKey) http.HandlerFunc) *Provider {
+	key, keyPEM := generateKey(t)
+	server := httptest.NewServer(handler(key))
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.KeyID = "ABC123DEFG"
+	config.TeamID = "DEF123GHIJ"
+	config.BundleID = "com.example.app"
+	config.PrivateKey = keyPEM
+	config.BaseURL = server.URL
+
+	provider, err := NewProvider(config)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestProvider_SendPush(t *testing.T) {
+	var received payload
+	var requests int
+	provider := setupTestProvider(t, func(key *ecdsa.PrivateKey) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			assert.Equal(t, "/3/device/device-token", r.URL.Path)
+			assert.Equal(t, "com.example.app", r.Header.Get("apns-topic"))
+			assert.Equal(t, "alert", r.Header.Get("apns-push-type"))
+
+			// The provider token is an ES256 JWT naming the key and team
+			authToken := strings.TrimPrefix(r.Header.Get("Authorization"), "bearer ")
+			token, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
+				return &key.PublicKey, nil
+			}, jwt.WithValidMethods([]string{"ES256"}))
+			require.NoError(t, err)
+			assert.Equal(t, "ABC123DEFG", token.Header["kid"])
+			issuer, err := token.Claims.GetIssuer()
+			require.NoError(t, err)
+			assert.Equal(t, "DEF123GHIJ", issuer)
+
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.Header().Set("apns-id", "EC1BF194-B3B2-4B4B-9B1C-8CE6A5A7C0F1")
+		}
+	})
+
+	messageID, err := provider.SendPush(context.Background(), "device-token", "Order shipped", "Your order is on its way")
+	require.NoError(t, err)
+	assert.Equal(t, "EC1BF194-B3B2-4B4B-9B1C-8CE6A5A7C0F1", messageID)
+	assert.Equal(t, "Order shipped", received.APS.Alert.Title)
+	assert.Equal(t, "Your order is on its way", received.APS.Alert.Body)
+
+	// The provider token is reused rather than signed for every push
+	first := provider.token
+	_, err = provider.SendPush(context.Background(), "device-token", "Order shipped", "Again")
+	require.NoError(t, err)
+	assert.Equal(t, first, provider.token)
+	assert.Equal(t, 2, requests)
+}
+
+func TestProvider_SendPush_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		reason     string
+		check      func(t *testing.T, err error)
+	}{
+		{
+			name:       "bad device token",
+			statusCode: http.StatusBadRequest,
+			reason:     "BadDeviceToken",
+			check: func(t *testing.T, err error) {
+				var invalid ErrDeviceTokenInvalid
+				require.ErrorAs(t, err, &invalid)
+				assert.Equal(t, "BadDeviceToken", invalid.Reason)
+				assert.ErrorAs(t, err, &model.ErrInvalidRecipient{})
+				assert.ErrorIs(t, err, model.ErrValidation)
+			},
+		},
+		{
+			name:       "unregistered",
+			statusCode: http.StatusGone,
+			reason:     "Unregistered",
+			check: func(t *testing.T, err error) {
+				assert.ErrorAs(t, err, &ErrDeviceTokenInvalid{})
+			},
+		},
+		{
+			name:       "too many requests",
+			statusCode: http.StatusTooManyRequests,
+			reason:     "TooManyRequests",
+			check: func(t *testing.T, err error) {
+				assert.ErrorAs(t, err, &ErrTooManyRequests{})
+				assert.ErrorIs(t, err, model.ErrProviderUnavailable)
+				assert.NotErrorIs(t, err, model.ErrValidation)
+			},
+		},
+		{
+			name:       "other error",
+			statusCode: http.StatusServiceUnavailable,
+			reason:     "ServiceUnavailable",
+			check: func(t *testing.T, err error) {
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+				assert.NotErrorIs(t, err, model.ErrValidation)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := setupTestProvider(t, func(*ecdsa.PrivateKey) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.statusCode)
+					json.NewEncoder(w).Encode(errorResponse{Reason: tt.reason})
+				}
+			})
+
+			_, err := provider.SendPush(context.Background(), "device-token", "Title", "Body")
+			require.Error(t, err)
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	_, keyPEM := generateKey(t)
+
+	t.Run("environment selects the endpoint", func(t *testing.T) {
+		config := DefaultConfig()
+		config.PrivateKey = keyPEM
+
+		provider, err := NewProvider(config)
+		require.NoError(t, err)
+		assert.Equal(t, productionURL, provider.baseURL)
+
+		config.Environment = EnvironmentSandbox
+		provider, err = NewProvider(config)
+		require.NoError(t, err)
+		assert.Equal(t, sandboxURL, provider.baseURL)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		config := DefaultConfig()
+		config.PrivateKey = keyPEM
+		config.Environment = "staging"
+
+		_, err := NewProvider(config)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		config := DefaultConfig()
+		config.PrivateKey = []byte("not a key")
+
+		_, err := NewProvider(config)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,175 @@
+// Package mock provides a provider that pretends to send notifications on
+// every channel. It logs each message and keeps it in memory, and optionally in
+// a file, so the service can run end to end in local development and
+// integration tests without reaching a real provider.
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"go.uber.org/zap"
+)
+
+// ProviderName identifies the mock provider on the messages it sends
+const ProviderName = "mock"
+
+// DefaultCapacity is how many messages are kept in memory by default
+const DefaultCapacity = 1000
+
+// Config holds the mock provider configuration
+type Config struct {
+	// Capacity caps how many messages are kept in memory, dropping the oldest
+	// first; 0 or less uses DefaultCapacity
+	Capacity int
+	// FilePath is a file every message is appended to as a line of JSON; empty
+	// keeps messages in memory only
+	FilePath string
+}
+
+// DefaultConfig returns a default mock provider configuration
+func DefaultConfig() Config {
+	return Config{Capacity: DefaultCapacity}
+}
+
+// Provider records the messages it is asked to send instead of sending them
+type Provider struct {
+	config Config
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	messages []model.SentMessage
+	file     *os.File
+}
+
+// NewProvider creates a new mock provider, opening its file if it has one
+func NewProvider(config Config, logger *zap.Logger) (*Provider, error) {
+	if config.Capacity <= 0 {
+		config.Capacity = DefaultCapacity
+	}
+
+	provider := &Provider{config: config, logger: logger}
+	if config.FilePath != "" {
+		file, err := os.OpenFile(config.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("error opening mock provider file: %w", err)
+		}
+		provider.file = file
+	}
+	return provider, nil
+}
+
+// Close closes the provider's file, if it has one
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.file == nil {
+		return nil
+	}
+	err := p.file.Close()
+	p.file = nil
+	return err
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// SendEmail records an email
+func (p *Provider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	return p.record(ctx, model.SentMessage{Type: model.EmailNotification, To: to, Subject: subject, Content: content})
+}
+
+// SendEmailWithOptions records an email with the addresses it copies; the
+// other options are accepted but not recorded
+func (p *Provider) SendEmailWithOptions(ctx context.Context, to, subject, content string, options model.EmailOptions) (string, error) {
+	return p.record(ctx, model.SentMessage{Type: model.EmailNotification, To: to, CC: options.CC, Subject: subject, Content: content})
+}
+
+// SendSMS records an SMS
+func (p *Provider) SendSMS(ctx context.Context, to, message string) (string, error) {
+	return p.record(ctx, model.SentMessage{Type: model.SMSNotification, To: to, Content: message})
+}
+
+// Segment reports how message would be split into SMS segments
+func (p *Provider) Segment(message string) model.SMSSegmentation {
+	return model.SegmentSMS(message)
+}
+
+// SendPush records a push notification
+func (p *Provider) SendPush(ctx context.Context, token, title, message string) (string, error) {
+	return p.record(ctx, model.SentMessage{Type: model.PushNotification, To: token, Subject: title, Content: message})
+}
+
+// SendWhatsApp records a WhatsApp template message
+func (p *Provider) SendWhatsApp(ctx context.Context, to string, message model.WhatsAppTemplateMessage) (string, error) {
+	return p.record(ctx, model.SentMessage{
+		Type:    model.WhatsAppNotification,
+		To:      to,
+		Subject: message.Name,
+		Content: strings.Join(message.Parameters, "\n"),
+	})
+}
+
+// CheckHealth always reports the provider healthy
+func (p *Provider) CheckHealth(ctx context.Context) error {
+	return nil
+}
+
+// SentMessages returns the messages kept in memory, oldest first
+func (p *Provider) SentMessages() []model.SentMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]model.SentMessage(nil), p.messages...)
+}
+
+// ClearSentMessages forgets the messages kept in memory; the file is left as it is
+func (p *Provider) ClearSentMessages() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = nil
+}
+
+// record logs and keeps message, returning the ID it was given
+func (p *Provider) record(ctx context.Context, message model.SentMessage) (string, error) {
+	message.ID = ProviderName + "-" + uuid.NewString()
+	message.TenantID = model.TenantFromContext(ctx)
+	message.SentAt = time.Now()
+
+	p.logger.Info("mock provider sent message",
+		zap.String("id", message.ID),
+		zap.String("type", string(message.Type)),
+		zap.String("to", message.To),
+		zap.String("subject", message.Subject),
+	)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = append(p.messages, message)
+	if over := len(p.messages) - p.config.Capacity; over > 0 {
+		p.messages = append([]model.SentMessage(nil), p.messages[over:]...)
+	}
+
+	if p.file != nil {
+		line, err := json.Marshal(message)
+		if err != nil {
+			return "", fmt.Errorf("error encoding mock message: %w", err)
+		}
+		if _, err := p.file.Write(append(line, '\n')); err != nil {
+			return "", fmt.Errorf("error writing mock message: %w", err)
+		}
+	}
+	return message.ID, nil
+}
@@ -0,0 +1,98 @@
+package mock
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var (
+	_ services.EmailProvider         = (*Provider)(nil)
+	_ services.EmailOptionsProvider  = (*Provider)(nil)
+	_ services.SMSProvider           = (*Provider)(nil)
+	_ services.PushProvider          = (*Provider)(nil)
+	_ services.WhatsAppProvider      = (*Provider)(nil)
+	_ services.NamedProvider         = (*Provider)(nil)
+	_ services.HealthCheckedProvider = (*Provider)(nil)
+)
+
+func TestProvider_RecordsEveryChannel(t *testing.T) {
+	provider, err := NewProvider(DefaultConfig(), zap.NewNop())
+	require.NoError(t, err)
+	ctx := model.ContextWithTenant(context.Background(), "tenant-a")
+
+	emailID, err := provider.SendEmail(ctx, "user@example.com", "Welcome", "<p>Hi</p>")
+	require.NoError(t, err)
+	_, err = provider.SendEmailWithOptions(ctx, "user@example.com", "Receipt", "Thanks", model.EmailOptions{CC: []string{"billing@example.com"}})
+	require.NoError(t, err)
+	_, err = provider.SendSMS(ctx, "+14155552671", "Your code is 123456")
+	require.NoError(t, err)
+	_, err = provider.SendPush(ctx, "device-token", "Hello", "You have a message")
+	require.NoError(t, err)
+	_, err = provider.SendWhatsApp(ctx, "+14155552671", model.WhatsAppTemplateMessage{Name: "order_shipped", Language: "en_US", Parameters: []string{"Jane", "#1234"}})
+	require.NoError(t, err)
+
+	messages := provider.SentMessages()
+	require.Len(t, messages, 5)
+	assert.Equal(t, emailID, messages[0].ID)
+	assert.Equal(t, "tenant-a", messages[0].TenantID)
+	assert.Equal(t, model.EmailNotification, messages[0].Type)
+	assert.Equal(t, "Welcome", messages[0].Subject)
+	assert.Equal(t, []string{"billing@example.com"}, messages[1].CC)
+	assert.Equal(t, model.SMSNotification, messages[2].Type)
+	assert.Equal(t, "device-token", messages[3].To)
+	assert.Equal(t, "order_shipped", messages[4].Subject)
+	assert.Equal(t, "Jane\n#1234", messages[4].Content)
+
+	provider.ClearSentMessages()
+	assert.Empty(t, provider.SentMessages())
+}
+
+func TestProvider_KeepsTheNewestMessages(t *testing.T) {
+	provider, err := NewProvider(Config{Capacity: 2}, zap.NewNop())
+	require.NoError(t, err)
+
+	for _, to := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := provider.SendEmail(context.Background(), to, "Hi", "Hi")
+		require.NoError(t, err)
+	}
+
+	messages := provider.SentMessages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "b@example.com", messages[0].To)
+	assert.Equal(t, "c@example.com", messages[1].To)
+}
+
+func TestProvider_AppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	provider, err := NewProvider(Config{FilePath: path}, zap.NewNop())
+	require.NoError(t, err)
+
+	_, err = provider.SendSMS(context.Background(), "+14155552671", "first")
+	require.NoError(t, err)
+	_, err = provider.SendSMS(context.Background(), "+14155552671", "second")
+	require.NoError(t, err)
+	require.NoError(t, provider.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var contents []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var message model.SentMessage
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &message))
+		contents = append(contents, message.Content)
+	}
+	assert.Equal(t, []string{"first", "second"}, contents)
+}
@@ -0,0 +1,177 @@
+// Package sandbox wraps notification providers so every send goes to a
+// catch-all recipient instead of the real one. Wrapping the providers
+// themselves, rather than rewriting notifications, means no code path can
+// reach a real recipient while sandbox mode is on.
+package sandbox
+
+import (
+	"context"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+)
+
+// EmailProvider redirects emails to the sandbox's catch-all address, with the
+// original recipient prepended to the subject
+type EmailProvider struct {
+	next    services.EmailProvider
+	sandbox model.Sandbox
+}
+
+// NewEmailProvider wraps next so it only ever sends to sandbox's catch-all address
+func NewEmailProvider(next services.EmailProvider, sandbox model.Sandbox) *EmailProvider {
+	return &EmailProvider{next: next, sandbox: sandbox}
+}
+
+// SendEmail sends the email to the catch-all address instead of to
+func (p *EmailProvider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	recipient := p.sandbox.Recipient(model.EmailNotification)
+	if recipient == "" {
+		return "", model.ErrSandboxBlocked{Type: model.EmailNotification}
+	}
+	return p.next.SendEmail(ctx, recipient, model.SandboxLabel(to, subject), content)
+}
+
+// SendEmailWithOptions sends the email to the catch-all address instead of to,
+// keeping its content type. Nobody is copied, so the CC addresses can't be reached.
+func (p *EmailProvider) SendEmailWithOptions(ctx context.Context, to, subject, content string, options model.EmailOptions) (string, error) {
+	recipient := p.sandbox.Recipient(model.EmailNotification)
+	if recipient == "" {
+		return "", model.ErrSandboxBlocked{Type: model.EmailNotification}
+	}
+	options.CC = nil
+	if options.IsZero() {
+		return p.next.SendEmail(ctx, recipient, model.SandboxLabel(to, subject), content)
+	}
+	next, ok := p.next.(services.EmailOptionsProvider)
+	if !ok {
+		return "", model.ErrEmailOptionsUnsupported{}
+	}
+	return next.SendEmailWithOptions(ctx, recipient, model.SandboxLabel(to, subject), content, options)
+}
+
+// Name returns the wrapped provider's name
+func (p *EmailProvider) Name() string {
+	return providerName(p.next)
+}
+
+// CheckHealth checks the wrapped provider's health
+func (p *EmailProvider) CheckHealth(ctx context.Context) error {
+	return checkHealth(ctx, p.next)
+}
+
+// SMSProvider redirects SMS to the sandbox's catch-all phone number, with the
+// original recipient prepended to the message
+type SMSProvider struct {
+	next    services.SMSProvider
+	sandbox model.Sandbox
+}
+
+// NewSMSProvider wraps next so it only ever sends to sandbox's catch-all phone number
+func NewSMSProvider(next services.SMSProvider, sandbox model.Sandbox) *SMSProvider {
+	return &SMSProvider{next: next, sandbox: sandbox}
+}
+
+// SendSMS sends the message to the catch-all phone number instead of to
+func (p *SMSProvider) SendSMS(ctx context.Context, to, message string) (string, error) {
+	recipient := p.sandbox.Recipient(model.SMSNotification)
+	if recipient == "" {
+		return "", model.ErrSandboxBlocked{Type: model.SMSNotification}
+	}
+	return p.next.SendSMS(ctx, recipient, model.SandboxLabel(to, message))
+}
+
+// Segment reports how the wrapped provider segments message
+func (p *SMSProvider) Segment(message string) model.SMSSegmentation {
+	return p.next.Segment(message)
+}
+
+// Name returns the wrapped provider's name
+func (p *SMSProvider) Name() string {
+	return providerName(p.next)
+}
+
+// CheckHealth checks the wrapped provider's health
+func (p *SMSProvider) CheckHealth(ctx context.Context) error {
+	return checkHealth(ctx, p.next)
+}
+
+// PushProvider redirects push notifications to the sandbox's catch-all device
+// token, with the original token prepended to the title
+type PushProvider struct {
+	next    services.PushProvider
+	sandbox model.Sandbox
+}
+
+// NewPushProvider wraps next so it only ever sends to sandbox's catch-all device token
+func NewPushProvider(next services.PushProvider, sandbox model.Sandbox) *PushProvider {
+	return &PushProvider{next: next, sandbox: sandbox}
+}
+
+// SendPush sends the notification to the catch-all device token instead of token
+func (p *PushProvider) SendPush(ctx context.Context, token, title, message string) (string, error) {
+	recipient := p.sandbox.Recipient(model.PushNotification)
+	if recipient == "" {
+		return "", model.ErrSandboxBlocked{Type: model.PushNotification}
+	}
+	return p.next.SendPush(ctx, recipient, model.SandboxLabel(token, title), message)
+}
+
+// Name returns the wrapped provider's name
+func (p *PushProvider) Name() string {
+	return providerName(p.next)
+}
+
+// CheckHealth checks the wrapped provider's health
+func (p *PushProvider) CheckHealth(ctx context.Context) error {
+	return checkHealth(ctx, p.next)
+}
+
+// WhatsAppProvider redirects WhatsApp messages to the sandbox's catch-all phone
+// number. Template messages can't be altered, so the original recipient is
+// only kept in the notification's metadata.
+type WhatsAppProvider struct {
+	next    services.WhatsAppProvider
+	sandbox model.Sandbox
+}
+
+// NewWhatsAppProvider wraps next so it only ever sends to sandbox's catch-all phone number
+func NewWhatsAppProvider(next services.WhatsAppProvider, sandbox model.Sandbox) *WhatsAppProvider {
+	return &WhatsAppProvider{next: next, sandbox: sandbox}
+}
+
+// SendWhatsApp sends the template message to the catch-all phone number instead of to
+func (p *WhatsAppProvider) SendWhatsApp(ctx context.Context, to string, message model.WhatsAppTemplateMessage) (string, error) {
+	recipient := p.sandbox.Recipient(model.WhatsAppNotification)
+	if recipient == "" {
+		return "", model.ErrSandboxBlocked{Type: model.WhatsAppNotification}
+	}
+	return p.next.SendWhatsApp(ctx, recipient, message)
+}
+
+// Name returns the wrapped provider's name
+func (p *WhatsAppProvider) Name() string {
+	return providerName(p.next)
+}
+
+// CheckHealth checks the wrapped provider's health
+func (p *WhatsAppProvider) CheckHealth(ctx context.Context) error {
+	return checkHealth(ctx, p.next)
+}
+
+// providerName returns the name of provider, or "" if it doesn't report one
+func providerName(provider interface{}) string {
+	if named, ok := provider.(services.NamedProvider); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// checkHealth checks the health of provider, or returns
+// model.ErrHealthCheckUnsupported if it can't be checked
+func checkHealth(ctx context.Context, provider interface{}) error {
+	if checker, ok := provider.(services.HealthCheckedProvider); ok {
+		return checker.CheckHealth(ctx)
+	}
+	return model.ErrHealthCheckUnsupported
+}
@@ -0,0 +1,160 @@
+package sandbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ services.EmailProvider        = (*EmailProvider)(nil)
+	_ services.EmailOptionsProvider = (*EmailProvider)(nil)
+	_ services.SMSProvider          = (*SMSProvider)(nil)
+	_ services.PushProvider         = (*PushProvider)(nil)
+	_ services.WhatsAppProvider     = (*WhatsAppProvider)(nil)
+)
+
+// sent records a call to a wrapped provider
+type sent struct {
+	to, title, content string
+	options            model.EmailOptions
+}
+
+// recordingProvider implements every provider interface, recording each send
+type recordingProvider struct {
+	sends []sent
+}
+
+func (p *recordingProvider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	p.sends = append(p.sends, sent{to: to, title: subject, content: content})
+	return "message-1", nil
+}
+
+func (p *recordingProvider) SendEmailWithOptions(ctx context.Context, to, subject, content string, options model.EmailOptions) (string, error) {
+	p.sends = append(p.sends, sent{to: to, title: subject, content: content, options: options})
+	return "message-1", nil
+}
+
+func (p *recordingProvider) SendSMS(ctx context.Context, to, message string) (string, error) {
+	p.sends = append(p.sends, sent{to: to, content: message})
+	return "message-1", nil
+}
+
+func (p *recordingProvider) Segment(message string) model.SMSSegmentation {
+	return model.SegmentSMS(message)
+}
+
+func (p *recordingProvider) SendPush(ctx context.Context, token, title, message string) (string, error) {
+	p.sends = append(p.sends, sent{to: token, title: title, content: message})
+	return "message-1", nil
+}
+
+func (p *recordingProvider) SendWhatsApp(ctx context.Context, to string, message model.WhatsAppTemplateMessage) (string, error) {
+	p.sends = append(p.sends, sent{to: to, content: message.Name})
+	return "message-1", nil
+}
+
+func TestProviders_RedirectToCatchAll(t *testing.T) {
+	sandbox := model.Sandbox{Email: "qa@example.com", Phone: "+14155550000", PushToken: "abcdef"}
+	next := &recordingProvider{}
+	ctx := context.Background()
+
+	_, err := NewEmailProvider(next, sandbox).SendEmail(ctx, "user@example.com", "Welcome", "<p>Hi</p>")
+	require.NoError(t, err)
+	_, err = NewSMSProvider(next, sandbox).SendSMS(ctx, "+14155552671", "Your code is 1234")
+	require.NoError(t, err)
+	_, err = NewPushProvider(next, sandbox).SendPush(ctx, "0123456789", "Alert", "Body")
+	require.NoError(t, err)
+	_, err = NewWhatsAppProvider(next, sandbox).SendWhatsApp(ctx, "+14155552671", model.WhatsAppTemplateMessage{Name: "order_shipped"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []sent{
+		{to: "qa@example.com", title: "[sandbox: user@example.com] Welcome", content: "<p>Hi</p>"},
+		{to: "+14155550000", content: "[sandbox: +14155552671] Your code is 1234"},
+		{to: "abcdef", title: "[sandbox: 0123456789] Alert", content: "Body"},
+		{to: "+14155550000", content: "order_shipped"},
+	}, next.sends)
+}
+
+func TestProviders_BlockChannelsWithoutCatchAll(t *testing.T) {
+	next := &recordingProvider{}
+	ctx := context.Background()
+
+	_, err := NewEmailProvider(next, model.Sandbox{}).SendEmail(ctx, "user@example.com", "Welcome", "<p>Hi</p>")
+	assert.Equal(t, model.ErrSandboxBlocked{Type: model.EmailNotification}, err)
+	assert.ErrorIs(t, err, model.ErrRejected)
+	_, err = NewSMSProvider(next, model.Sandbox{}).SendSMS(ctx, "+14155552671", "Your code is 1234")
+	assert.Equal(t, model.ErrSandboxBlocked{Type: model.SMSNotification}, err)
+	_, err = NewPushProvider(next, model.Sandbox{}).SendPush(ctx, "0123456789", "Alert", "Body")
+	assert.Equal(t, model.ErrSandboxBlocked{Type: model.PushNotification}, err)
+	_, err = NewWhatsAppProvider(next, model.Sandbox{}).SendWhatsApp(ctx, "+14155552671", model.WhatsAppTemplateMessage{Name: "order_shipped"})
+	assert.Equal(t, model.ErrSandboxBlocked{Type: model.WhatsAppNotification}, err)
+
+	assert.Empty(t, next.sends)
+}
+
+func TestEmailProvider_DropsCC(t *testing.T) {
+	sandbox := model.Sandbox{Email: "qa@example.com"}
+	next := &recordingProvider{}
+	ctx := context.Background()
+
+	_, err := NewEmailProvider(next, sandbox).SendEmailWithOptions(ctx, "user@example.com", "Welcome", "Hi",
+		model.EmailOptions{ContentType: model.EmailContentText, CC: []string{"manager@example.com"}})
+	require.NoError(t, err)
+	// With nobody copied, an HTML email is sent without options
+	_, err = NewEmailProvider(next, sandbox).SendEmailWithOptions(ctx, "user@example.com", "Welcome", "<p>Hi</p>",
+		model.EmailOptions{CC: []string{"manager@example.com"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []sent{
+		{to: "qa@example.com", title: "[sandbox: user@example.com] Welcome", content: "Hi", options: model.EmailOptions{ContentType: model.EmailContentText}},
+		{to: "qa@example.com", title: "[sandbox: user@example.com] Welcome", content: "<p>Hi</p>"},
+	}, next.sends)
+}
+
+// namedProvider is a recordingProvider that reports its name
+type namedProvider struct {
+	recordingProvider
+}
+
+func (p *namedProvider) Name() string { return "recording" }
+
+func TestProviders_ReportWrappedName(t *testing.T) {
+	sandbox := model.Sandbox{}
+	named := &namedProvider{}
+
+	assert.Equal(t, "recording", NewEmailProvider(named, sandbox).Name())
+	assert.Equal(t, "recording", NewSMSProvider(named, sandbox).Name())
+	assert.Equal(t, "recording", NewPushProvider(named, sandbox).Name())
+	assert.Equal(t, "recording", NewWhatsAppProvider(named, sandbox).Name())
+
+	assert.Empty(t, NewEmailProvider(&recordingProvider{}, sandbox).Name())
+}
+
+// checkedProvider reports err from its health check
+type checkedProvider struct {
+	recordingProvider
+	err error
+}
+
+func (p *checkedProvider) CheckHealth(ctx context.Context) error { return p.err }
+
+func TestProviders_CheckWrappedHealth(t *testing.T) {
+	sandbox := model.Sandbox{}
+	unauthorized := model.ErrProviderUnauthorized{Provider: "recording", Reason: "bad key"}
+	checked := &checkedProvider{err: unauthorized}
+
+	assert.Equal(t, unauthorized, NewEmailProvider(checked, sandbox).CheckHealth(context.Background()))
+	assert.Equal(t, unauthorized, NewSMSProvider(checked, sandbox).CheckHealth(context.Background()))
+	assert.Equal(t, unauthorized, NewPushProvider(checked, sandbox).CheckHealth(context.Background()))
+	assert.Equal(t, unauthorized, NewWhatsAppProvider(checked, sandbox).CheckHealth(context.Background()))
+
+	// Checking never sends through the sandbox
+	assert.Empty(t, checked.sends)
+
+	assert.ErrorIs(t, NewEmailProvider(&recordingProvider{}, sandbox).CheckHealth(context.Background()), model.ErrHealthCheckUnsupported)
+}
@@ -0,0 +1,135 @@
+package sendgrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// ProviderName identifies SendGrid in delivery events and webhook routes
+const ProviderName = "sendgrid"
+
+// Headers carrying the signed Event Webhook's signature
+const (
+	SignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	TimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+// Webhook parses SendGrid Event Webhook deliveries, verifying their ECDSA signature
+type Webhook struct {
+	key *ecdsa.PublicKey
+}
+
+// NewWebhook creates a webhook parser from the verification key shown in the
+// SendGrid mail settings, a base64-encoded ECDSA public key
+func NewWebhook(verificationKey string) (*Webhook, error) {
+	der, err := base64.StdEncoding.DecodeString(verificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding SendGrid verification key: %w", err)
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SendGrid verification key: %w", err)
+	}
+
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("SendGrid verification key is %T, not an ECDSA key", parsed)
+	}
+
+	return &Webhook{key: key}, nil
+}
+
+// event is a single entry of an Event Webhook delivery
+type event struct {
+	Event       string `json:"event"`
+	SGMessageID string `json:"sg_message_id"`
+	Timestamp   int64  `json:"timestamp"`
+	Reason      string `json:"reason"`
+	BounceType  string `json:"type"` // "bounce" for hard bounces, "blocked" for soft ones
+	URL         string `json:"url"`
+}
+
+// eventTypes maps the SendGrid events that affect a notification; others are ignored
+var eventTypes = map[string]model.DeliveryEventType{
+	"delivered":  model.DeliveryEventDelivered,
+	"bounce":     model.DeliveryEventBounced,
+	"dropped":    model.DeliveryEventBounced,
+	"open":       model.DeliveryEventOpened,
+	"click":      model.DeliveryEventClicked,
+	"spamreport": model.DeliveryEventComplained,
+}
+
+// ParseDeliveryEvents verifies the request's signature and returns the delivery
+// events it carries
+func (w *Webhook) ParseDeliveryEvents(r *http.Request) ([]model.DeliveryEvent, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading SendGrid webhook: %w", err)
+	}
+
+	if err := w.verify(r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body); err != nil {
+		return nil, err
+	}
+
+	var events []event
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, fmt.Errorf("error decoding SendGrid webhook: %w", err)
+	}
+
+	deliveryEvents := make([]model.DeliveryEvent, 0, len(events))
+	for _, e := range events {
+		eventType, ok := eventTypes[e.Event]
+		if !ok || e.SGMessageID == "" {
+			continue
+		}
+
+		deliveryEvents = append(deliveryEvents, model.DeliveryEvent{
+			Provider:          ProviderName,
+			ProviderMessageID: MessageID(e.SGMessageID),
+			Type:              eventType,
+			Reason:            e.Reason,
+			Permanent:         e.Event == "bounce" && e.BounceType != "blocked",
+			URL:               e.URL,
+			OccurredAt:        time.Unix(e.Timestamp, 0),
+		})
+	}
+
+	return deliveryEvents, nil
+}
+
+// verify checks the signature SendGrid computed over the timestamp and body
+func (w *Webhook) verify(timestamp, signature string, body []byte) error {
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("%w: missing SendGrid signature headers", model.ErrInvalidSignature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed SendGrid signature", model.ErrInvalidSignature)
+	}
+
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	if !ecdsa.VerifyASN1(w.key, digest[:], sig) {
+		return model.ErrInvalidSignature
+	}
+	return nil
+}
+
+// MessageID returns the message ID SendGrid returned when the message was sent
+// (its X-Message-Id header) for an event's sg_message_id, which extends it with
+// the ID of the mail server that handled the message
+func MessageID(sgMessageID string) string {
+	messageID, _, _ := strings.Cut(sgMessageID, ".filter")
+	return messageID
+}
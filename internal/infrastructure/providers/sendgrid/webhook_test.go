@@ -0,0 +1,109 @@
+package sendgrid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestWebhook(t *testing.T) (*Webhook, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	webhook, err := NewWebhook(base64.StdEncoding.EncodeToString(der))
+	require.NoError(t, err)
+	return webhook, key
+}
+
+func signedRequest(t *testing.T, key *ecdsa.PrivateKey, body string) *http.Request {
+	timestamp := "1700000000"
+	digest := sha256.Sum256([]byte(timestamp + body))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/providers/sendgrid", strings.NewReader(body))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(signature))
+	return req
+}
+
+func TestWebhook_ParseDeliveryEvents(t *testing.T) {
+	webhook, key := setupTestWebhook(t)
+
+	body := `[
+		{"event": "processed", "sg_message_id": "abc123.filterdrecv-1", "timestamp": 1700000000},
+		{"event": "delivered", "sg_message_id": "abc123.filterdrecv-1", "timestamp": 1700000001},
+		{"event": "bounce", "sg_message_id": "def456.filterdrecv-2", "timestamp": 1700000002, "reason": "550 5.1.1 unknown user", "type": "bounce"},
+		{"event": "click", "sg_message_id": "abc123.filterdrecv-1", "timestamp": 1700000003, "url": "https://example.com/offer"},
+		{"event": "bounce", "sg_message_id": "ghi789.filterdrecv-3", "timestamp": 1700000004, "reason": "421 try again later", "type": "blocked"},
+		{"event": "spamreport", "sg_message_id": "abc123.filterdrecv-1", "timestamp": 1700000005}
+	]`
+
+	events, err := webhook.ParseDeliveryEvents(signedRequest(t, key, body))
+	require.NoError(t, err)
+
+	// Events that don't affect a notification are skipped
+	require.Len(t, events, 5)
+	assert.Equal(t, model.DeliveryEvent{
+		Provider:          ProviderName,
+		ProviderMessageID: "abc123",
+		Type:              model.DeliveryEventDelivered,
+		OccurredAt:        time.Unix(1700000001, 0),
+	}, events[0])
+	assert.Equal(t, model.DeliveryEventBounced, events[1].Type)
+	assert.Equal(t, "def456", events[1].ProviderMessageID)
+	assert.Equal(t, "550 5.1.1 unknown user", events[1].Reason)
+	assert.True(t, events[1].Permanent)
+	assert.Equal(t, model.DeliveryEventClicked, events[2].Type)
+	assert.Equal(t, "https://example.com/offer", events[2].URL)
+
+	// Blocks are soft bounces; only hard bounces and spam reports suppress the recipient
+	assert.Equal(t, model.DeliveryEventBounced, events[3].Type)
+	assert.False(t, events[3].Permanent)
+	_, suppress := events[3].SuppressionReason()
+	assert.False(t, suppress)
+	assert.Equal(t, model.DeliveryEventComplained, events[4].Type)
+	reason, suppress := events[4].SuppressionReason()
+	assert.True(t, suppress)
+	assert.Equal(t, model.SuppressionComplaint, reason)
+}
+
+func TestWebhook_ParseDeliveryEvents_InvalidSignature(t *testing.T) {
+	webhook, key := setupTestWebhook(t)
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := signedRequest(t, key, `[{"event": "delivered", "sg_message_id": "abc123"}]`)
+		req.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[{"event": "bounce", "sg_message_id": "abc123"}]`)).Body
+
+		_, err := webhook.ParseDeliveryEvents(req)
+		assert.ErrorIs(t, err, model.ErrInvalidSignature)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[]`))
+
+		_, err := webhook.ParseDeliveryEvents(req)
+		assert.ErrorIs(t, err, model.ErrInvalidSignature)
+	})
+}
+
+func TestMessageID(t *testing.T) {
+	assert.Equal(t, "W3sj2fGpTNO0qnEUuJ0yIA", MessageID("W3sj2fGpTNO0qnEUuJ0yIA.filterdrecv-5645d9c87f-78xgx-1-5DB9B9E9-2C.0"))
+	assert.Equal(t, "14c5d75ce93.dfd.64b469", MessageID("14c5d75ce93.dfd.64b469.filter0001.16648.5515E0B88.0"))
+	assert.Equal(t, "abc123", MessageID("abc123"))
+}
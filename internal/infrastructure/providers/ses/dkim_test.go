@@ -0,0 +1,115 @@
+package ses
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/mail"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dkim "github.com/toorop/go-dkim"
+)
+
+// testDKIMKey returns a PEM-encoded RSA key and the DNS record publishing its public key
+func testDKIMKey(t *testing.T) ([]byte, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return privatePEM, "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(public)
+}
+
+func TestProvider_SignsRawEmailsWithDKIM(t *testing.T) {
+	privateKey, record := testDKIMKey(t)
+	config := testConfig()
+	config.DKIM = DKIMConfig{Domain: "example.com", Selector: "mail", PrivateKey: privateKey}
+
+	client := &fakeSESClient{}
+	provider := NewProvider(client, config)
+
+	attachment := Attachment{Filename: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF")}
+	_, err := provider.SendEmailWithAttachments(context.Background(), "user@example.com", "Your invoice", "<p>Attached</p>", []Attachment{attachment})
+	require.NoError(t, err)
+	require.NotNil(t, client.input.Content.Raw)
+	raw := client.input.Content.Raw.Data
+
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	signature := message.Header.Get("DKIM-Signature")
+	require.NotEmpty(t, signature)
+	assert.Contains(t, signature, "d=example.com")
+	assert.Contains(t, signature, "s=mail")
+	assert.Equal(t, "Your invoice", message.Header.Get("Subject"))
+
+	lookup := dkim.DNSOptLookupTXT(func(name string) ([]string, error) {
+		assert.Equal(t, "mail._domainkey.example.com", name)
+		return []string{record}, nil
+	})
+	status, err := dkim.Verify(&raw, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, dkim.SUCCESS, status)
+
+	// Simple content is assembled, and signed with Easy DKIM, by SES
+	_, err = provider.SendEmail(context.Background(), "user@example.com", "Welcome", "<p>Hello</p>")
+	require.NoError(t, err)
+	assert.NotNil(t, client.input.Content.Simple)
+}
+
+func TestProvider_SendsUnsignedWithoutDKIM(t *testing.T) {
+	client := &fakeSESClient{}
+	provider := NewProvider(client, testConfig())
+
+	images := []model.InlineImage{{ContentID: "logo", ContentType: "image/png", Data: []byte("png")}}
+	_, err := provider.SendEmailWithOptions(context.Background(), "user@example.com", "Welcome", `<img src="cid:logo">`, model.EmailOptions{InlineImages: images})
+	require.NoError(t, err)
+	require.NotNil(t, client.input.Content.Raw)
+
+	message, err := mail.ReadMessage(bytes.NewReader(client.input.Content.Raw.Data))
+	require.NoError(t, err)
+	assert.Empty(t, message.Header.Get("DKIM-Signature"))
+}
+
+func TestDKIMConfig_Validate(t *testing.T) {
+	privateKey, _ := testDKIMKey(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	ecPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER})
+
+	tests := []struct {
+		name    string
+		config  DKIMConfig
+		wantErr string
+	}{
+		{name: "disabled", config: DKIMConfig{}},
+		{name: "complete", config: DKIMConfig{Domain: "example.com", Selector: "mail", PrivateKey: privateKey}},
+		{name: "no selector", config: DKIMConfig{Domain: "example.com", PrivateKey: privateKey}, wantErr: "needs a domain, a selector and a private key"},
+		{name: "not PEM", config: DKIMConfig{Domain: "example.com", Selector: "mail", PrivateKey: []byte("secret")}, wantErr: "not PEM-encoded"},
+		{name: "not RSA", config: DKIMConfig{Domain: "example.com", Selector: "mail", PrivateKey: ecPEM}, wantErr: "must be an RSA key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package ses
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/aws/smithy-go"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// Config holds the SES configuration
+type Config struct {
+	// Region is the AWS region SES is called in; empty uses the SDK's default resolution
+	Region string
+	// Senders resolves the verified identity each email is sent from by the
+	// category in its context
+	Senders model.SenderResolver
+	// ConfigurationSetName selects the configuration set whose event destinations
+	// track sends, deliveries and bounces; empty sends without one
+	ConfigurationSetName string
+	// DKIM signs the raw messages the provider builds, those with attachments,
+	// inline images or threading headers; simple content is assembled and
+	// signed by SES itself.
+	// The zero value sends them unsigned.
+	DKIM DKIMConfig
+}
+
+// DefaultConfig returns a default SES configuration
+func DefaultConfig() Config {
+	return Config{}
+}
+
+// SendEmailAPI is the part of the SESv2 client the provider uses
+type SendEmailAPI interface {
+	SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error)
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// ErrThrottled is returned when SES is throttling sends; the email can be sent again later
+type ErrThrottled struct {
+	Err error
+}
+
+func (e ErrThrottled) Error() string {
+	return fmt.Sprintf("SES is throttling sends: %v", e.Err)
+}
+
+func (e ErrThrottled) Unwrap() error {
+	return e.Err
+}
+
+// Is reports the error as model.ErrProviderUnavailable
+func (e ErrThrottled) Is(target error) bool { return target == model.ErrProviderUnavailable }
+
+// ErrMessageRejected is returned when SES refuses an email, e.g. because it
+// contains a virus or the sender isn't verified; sending it again won't help
+type ErrMessageRejected struct {
+	Reason string
+}
+
+func (e ErrMessageRejected) Error() string {
+	return fmt.Sprintf("SES rejected the message: %s", e.Reason)
+}
+
+// Is reports the error as model.ErrRejected
+func (e ErrMessageRejected) Is(target error) bool { return target == model.ErrRejected }
+
+// Provider sends emails through Amazon SES
+type Provider struct {
+	client SendEmailAPI
+	config Config
+}
+
+// NewProvider creates a new SES provider using client
+func NewProvider(client SendEmailAPI, config Config) *Provider {
+	return &Provider{
+		client: client,
+		config: config,
+	}
+}
+
+// NewProviderFromEnvironment creates a new SES provider with credentials
+// resolved by the AWS SDK from the environment, shared config or instance role
+func NewProviderFromEnvironment(ctx context.Context, config Config) (*Provider, error) {
+	if err := config.Senders.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SES sender configuration: %w", err)
+	}
+	if err := config.DKIM.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SES DKIM configuration: %w", err)
+	}
+
+	var options []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		options = append(options, awsconfig.WithRegion(config.Region))
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error loading AWS config: %w", err)
+	}
+
+	return NewProvider(sesv2.NewFromConfig(awsConfig), config), nil
+}
+
+// Name returns the provider's name, which its delivery events are reported under
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// SendEmail sends an HTML email as simple content, returning its SES message ID
+func (p *Provider) SendEmail(ctx context.Context, to, subject, content string) (string, error) {
+	return p.SendEmailWithOptions(ctx, to, subject, content, model.EmailOptions{})
+}
+
+// SendEmailWithOptions sends an email as simple content in the options'
+// content type, copied to their CC addresses, returning its SES message ID.
+// Emails embedding inline images are sent as a raw multipart/related message,
+// and threaded emails as a raw message carrying their threading headers.
+func (p *Provider) SendEmailWithOptions(ctx context.Context, to, subject, content string, options model.EmailOptions) (string, error) {
+	destination := &types.Destination{ToAddresses: []string{to}, CcAddresses: options.CC}
+	if len(options.InlineImages) > 0 {
+		raw, err := buildRelatedMessage(p.sender(ctx).String(), to, options.CC, subject, content, options.InlineImages, options.Threading)
+		if err != nil {
+			return "", err
+		}
+		return p.send(ctx, destination, &types.EmailContent{
+			Raw: &types.RawMessage{Data: raw},
+		})
+	}
+	if !options.Threading.IsZero() {
+		raw := buildThreadedMessage(p.sender(ctx).String(), to, options.CC, subject, content, options.ContentType, options.Threading)
+		return p.send(ctx, destination, &types.EmailContent{
+			Raw: &types.RawMessage{Data: raw},
+		})
+	}
+
+	body := &types.Body{}
+	if options.ContentType == model.EmailContentText {
+		body.Text = &types.Content{Data: aws.String(content), Charset: aws.String("UTF-8")}
+	} else {
+		body.Html = &types.Content{Data: aws.String(content), Charset: aws.String("UTF-8")}
+	}
+
+	return p.send(ctx, destination, &types.EmailContent{
+		Simple: &types.Message{
+			Subject: &types.Content{Data: aws.String(subject), Charset: aws.String("UTF-8")},
+			Body:    body,
+		},
+	})
+}
+
+// SendEmailWithAttachments sends an HTML email with attachments as a raw MIME message, returning its SES message ID
+func (p *Provider) SendEmailWithAttachments(ctx context.Context, to, subject, content string, attachments []Attachment) (string, error) {
+	raw, err := buildRawMessage(p.sender(ctx).String(), to, subject, content, attachments)
+	if err != nil {
+		return "", err
+	}
+
+	return p.send(ctx, &types.Destination{ToAddresses: []string{to}}, &types.EmailContent{
+		Raw: &types.RawMessage{Data: raw},
+	})
+}
+
+func (p *Provider) send(ctx context.Context, destination *types.Destination, content *types.EmailContent) (string, error) {
+	start := time.Now()
+	operation := "ses_send_email"
+
+	if content.Raw != nil && p.config.DKIM.Enabled() {
+		signed, err := p.config.DKIM.sign(content.Raw.Data)
+		if err != nil {
+			return "", err
+		}
+		content.Raw.Data = signed
+	}
+
+	input := &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(p.sender(ctx).String()),
+		Destination:      destination,
+		Content:          content,
+	}
+	if p.config.ConfigurationSetName != "" {
+		input.ConfigurationSetName = aws.String(p.config.ConfigurationSetName)
+	}
+
+	// The SDK stops retrying and aborts the request once ctx's deadline passes
+	output, err := p.client.SendEmail(ctx, input)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return "", classifyError(err)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return aws.ToString(output.MessageId), nil
+}
+
+// sender resolves the identity to send from by the email category in ctx
+func (p *Provider) sender(ctx context.Context) model.Sender {
+	return p.config.Senders.Resolve(model.EmailCategoryFromContext(ctx))
+}
+
+// classifyError maps SES errors the caller should treat differently to typed errors
+func classifyError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("error sending email through SES: %w", err)
+	}
+
+	switch apiErr.ErrorCode() {
+	case "Throttling", "TooManyRequestsException":
+		return ErrThrottled{Err: err}
+	case "MessageRejected":
+		return ErrMessageRejected{Reason: apiErr.ErrorMessage()}
+	default:
+		return fmt.Errorf("error sending email through SES: %w", err)
+	}
+}
+
+// GetAccountAPI is the part of the SESv2 client the provider's health check uses
+type GetAccountAPI interface {
+	GetAccount(ctx context.Context, params *sesv2.GetAccountInput, optFns ...func(*sesv2.Options)) (*sesv2.GetAccountOutput, error)
+}
+
+// credentialErrorCodes are the AWS error codes for credentials SES doesn't accept
+var credentialErrorCodes = map[string]bool{
+	"UnrecognizedClientException": true,
+	"InvalidClientTokenId":        true,
+	"InvalidSignatureException":   true,
+	"SignatureDoesNotMatch":       true,
+	"AccessDeniedException":       true,
+	"ExpiredToken":                true,
+	"ExpiredTokenException":       true,
+}
+
+// CheckHealth reads the SES account, which confirms SES is reachable and
+// accepts the credentials without sending an email, and reports SES unhealthy
+// if sending is paused for the account
+func (p *Provider) CheckHealth(ctx context.Context) error {
+	client, ok := p.client.(GetAccountAPI)
+	if !ok {
+		return model.ErrHealthCheckUnsupported
+	}
+
+	account, err := client.GetAccount(ctx, &sesv2.GetAccountInput{})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && credentialErrorCodes[apiErr.ErrorCode()] {
+			return model.ErrProviderUnauthorized{Provider: ProviderName, Reason: apiErr.ErrorMessage()}
+		}
+		return fmt.Errorf("error reading SES account: %w", err)
+	}
+
+	if !account.SendingEnabled {
+		return model.ErrProviderNotSending{Provider: ProviderName, Reason: fmt.Sprintf("sending is paused for the account (enforcement status %s)", aws.ToString(account.EnforcementStatus))}
+	}
+	return nil
+}
+
+// writeMessageHeaders writes the headers of a MIME message, up to its content type
+func writeMessageHeaders(buf *bytes.Buffer, from, to string, cc []string, subject string, threading model.EmailThreading) {
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", to)
+	if len(cc) > 0 {
+		fmt.Fprintf(buf, "Cc: %s\r\n", strings.Join(cc, ", "))
+	}
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	writeThreadingHeaders(buf, threading)
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+}
+
+// writeMultipartHeaders writes the headers of a multipart MIME message
+func writeMultipartHeaders(buf *bytes.Buffer, from, to string, cc []string, subject string, threading model.EmailThreading, mediaType, boundary string) {
+	writeMessageHeaders(buf, from, to, cc, subject, threading)
+	fmt.Fprintf(buf, "Content-Type: %s; boundary=%q\r\n\r\n", mediaType, boundary)
+}
+
+// writeThreadingHeaders writes the headers mail clients thread an email by.
+// SES keeps a Message-ID the message already has.
+func writeThreadingHeaders(buf *bytes.Buffer, threading model.EmailThreading) {
+	if threading.MessageID != "" {
+		fmt.Fprintf(buf, "Message-ID: <%s>\r\n", threading.MessageID)
+	}
+	if threading.InReplyTo != "" {
+		fmt.Fprintf(buf, "In-Reply-To: <%s>\r\n", threading.InReplyTo)
+	}
+	if len(threading.References) > 0 {
+		fmt.Fprintf(buf, "References: <%s>\r\n", strings.Join(threading.References, "> <"))
+	}
+}
+
+// writeHTMLPart adds the HTML content of an email to a multipart message
+func writeHTMLPart(writer *multipart.Writer, content string) error {
+	body, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return fmt.Errorf("error building email body: %w", err)
+	}
+	if err := writeBase64(body, []byte(content)); err != nil {
+		return fmt.Errorf("error building email body: %w", err)
+	}
+	return nil
+}
+
+// buildRelatedMessage renders an HTML email embedding images as a
+// multipart/related MIME message; the content references each image as
+// cid:<content ID>, which its part's Content-ID header matches
+func buildRelatedMessage(from, to string, cc []string, subject, content string, images []model.InlineImage, threading model.EmailThreading) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writeMultipartHeaders(&buf, from, to, cc, subject, threading, `multipart/related; type="text/html"`, writer.Boundary())
+
+	if err := writeHTMLPart(writer, content); err != nil {
+		return nil, err
+	}
+
+	for _, image := range images {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {image.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + image.ContentID + ">"},
+			"Content-Disposition":       {mime.FormatMediaType("inline", map[string]string{"filename": image.ContentID})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error embedding image %s: %w", image.ContentID, err)
+		}
+		if err := writeBase64(part, image.Data); err != nil {
+			return nil, fmt.Errorf("error embedding image %s: %w", image.ContentID, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error building email: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// buildThreadedMessage renders an email as a single-part MIME message in
+// contentType, HTML unless it is model.EmailContentText, with its threading headers
+func buildThreadedMessage(from, to string, cc []string, subject, content string, contentType model.EmailContentType, threading model.EmailThreading) []byte {
+	if contentType == "" {
+		contentType = model.EmailContentHTML
+	}
+
+	var buf bytes.Buffer
+	writeMessageHeaders(&buf, from, to, cc, subject, threading)
+	fmt.Fprintf(&buf, "Content-Type: %s; charset=UTF-8\r\n", contentType)
+	fmt.Fprintf(&buf, "Content-Transfer-Encoding: base64\r\n\r\n")
+	// Writing to a bytes.Buffer doesn't fail
+	_ = writeBase64(&buf, []byte(content))
+	return buf.Bytes()
+}
+
+// buildRawMessage renders an HTML email with attachments as a multipart/mixed MIME message
+func buildRawMessage(from, to, subject, content string, attachments []Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writeMultipartHeaders(&buf, from, to, nil, subject, model.EmailThreading{}, "multipart/mixed", writer.Boundary())
+
+	if err := writeHTMLPart(writer, content); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error attaching %s: %w", attachment.Filename, err)
+		}
+		if err := writeBase64(part, attachment.Data); err != nil {
+			return nil, fmt.Errorf("error attaching %s: %w", attachment.Filename, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error building email: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in lines of 76 characters, as MIME requires
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
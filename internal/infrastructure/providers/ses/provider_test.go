@@ -0,0 +1,352 @@
+package ses
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/aws/smithy-go"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ services.EmailProvider         = (*Provider)(nil)
+	_ services.EmailOptionsProvider  = (*Provider)(nil)
+	_ services.HealthCheckedProvider = (*Provider)(nil)
+	_ GetAccountAPI                  = (*sesv2.Client)(nil)
+)
+
+type fakeSESClient struct {
+	input *sesv2.SendEmailInput
+	err   error
+}
+
+func (f *fakeSESClient) SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error) {
+	f.input = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sesv2.SendEmailOutput{MessageId: aws.String("message-1")}, nil
+}
+
+func testConfig() Config {
+	config := DefaultConfig()
+	config.Senders = model.SenderResolver{
+		Default: model.Sender{Address: "noreply@example.com"},
+		Categories: map[string]model.Sender{
+			model.CategorySecurity: {Name: "Acme Security", Address: "security@example.com"},
+		},
+	}
+	config.ConfigurationSetName = "notifications"
+	return config
+}
+
+func TestProvider_SendEmail(t *testing.T) {
+	client := &fakeSESClient{}
+	provider := NewProvider(client, testConfig())
+
+	messageID, err := provider.SendEmail(context.Background(), "user@example.com", "Welcome", "<p>Hello</p>")
+	require.NoError(t, err)
+	assert.Equal(t, "message-1", messageID)
+
+	input := client.input
+	assert.Equal(t, "noreply@example.com", aws.ToString(input.FromEmailAddress))
+	assert.Equal(t, []string{"user@example.com"}, input.Destination.ToAddresses)
+	assert.Equal(t, "notifications", aws.ToString(input.ConfigurationSetName))
+	require.NotNil(t, input.Content.Simple)
+	assert.Nil(t, input.Content.Raw)
+	assert.Equal(t, "Welcome", aws.ToString(input.Content.Simple.Subject.Data))
+	assert.Equal(t, "<p>Hello</p>", aws.ToString(input.Content.Simple.Body.Html.Data))
+}
+
+func TestProvider_SendEmailWithOptions(t *testing.T) {
+	client := &fakeSESClient{}
+	provider := NewProvider(client, testConfig())
+
+	options := model.EmailOptions{ContentType: model.EmailContentText, CC: []string{"manager@example.com"}}
+	_, err := provider.SendEmailWithOptions(context.Background(), "user@example.com", "Welcome", "Hello", options)
+	require.NoError(t, err)
+
+	input := client.input
+	assert.Equal(t, []string{"user@example.com"}, input.Destination.ToAddresses)
+	assert.Equal(t, []string{"manager@example.com"}, input.Destination.CcAddresses)
+	require.NotNil(t, input.Content.Simple)
+	assert.Nil(t, input.Content.Simple.Body.Html)
+	assert.Equal(t, "Hello", aws.ToString(input.Content.Simple.Body.Text.Data))
+}
+
+func TestProvider_SendEmailFromCategorySender(t *testing.T) {
+	client := &fakeSESClient{}
+	provider := NewProvider(client, testConfig())
+
+	ctx := model.ContextWithEmailCategory(context.Background(), model.CategorySecurity)
+	_, err := provider.SendEmail(ctx, "user@example.com", "Reset your password", "<p>Hello</p>")
+	require.NoError(t, err)
+	assert.Equal(t, `"Acme Security" <security@example.com>`, aws.ToString(client.input.FromEmailAddress))
+
+	// Categories without their own sender use the default
+	ctx = model.ContextWithEmailCategory(context.Background(), "marketing")
+	_, err = provider.SendEmail(ctx, "user@example.com", "News", "<p>Hello</p>")
+	require.NoError(t, err)
+	assert.Equal(t, "noreply@example.com", aws.ToString(client.input.FromEmailAddress))
+}
+
+func TestNewProviderFromEnvironment_InvalidSender(t *testing.T) {
+	config := testConfig()
+	config.Senders.Categories["marketing"] = model.Sender{Address: "not-an-address"}
+
+	_, err := NewProviderFromEnvironment(context.Background(), config)
+	assert.ErrorIs(t, err, model.ErrValidation)
+}
+
+func TestProvider_SendEmailWithoutConfigurationSet(t *testing.T) {
+	client := &fakeSESClient{}
+	config := testConfig()
+	config.ConfigurationSetName = ""
+	provider := NewProvider(client, config)
+
+	_, err := provider.SendEmail(context.Background(), "user@example.com", "Welcome", "<p>Hello</p>")
+	require.NoError(t, err)
+	assert.Nil(t, client.input.ConfigurationSetName)
+}
+
+func TestProvider_SendEmailWithAttachments(t *testing.T) {
+	client := &fakeSESClient{}
+	provider := NewProvider(client, testConfig())
+
+	attachment := Attachment{Filename: "invoice.pdf", ContentType: "application/pdf", Data: bytes.Repeat([]byte("%PDF"), 100)}
+	ctx := model.ContextWithEmailCategory(context.Background(), model.CategorySecurity)
+	_, err := provider.SendEmailWithAttachments(ctx, "user@example.com", "Your invoice", "<p>Attached</p>", []Attachment{attachment})
+	require.NoError(t, err)
+
+	require.NotNil(t, client.input.Content.Raw)
+	assert.Nil(t, client.input.Content.Simple)
+	assert.Equal(t, "notifications", aws.ToString(client.input.ConfigurationSetName))
+
+	message, err := mail.ReadMessage(bytes.NewReader(client.input.Content.Raw.Data))
+	require.NoError(t, err)
+	assert.Equal(t, `"Acme Security" <security@example.com>`, message.Header.Get("From"))
+	assert.Equal(t, "user@example.com", message.Header.Get("To"))
+	assert.Equal(t, "Your invoice", message.Header.Get("Subject"))
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	body, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=UTF-8", body.Header.Get("Content-Type"))
+
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "invoice.pdf", part.FileName())
+	assert.Equal(t, "application/pdf", part.Header.Get("Content-Type"))
+
+	_, err = reader.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestProvider_SendEmailWithInlineImages(t *testing.T) {
+	client := &fakeSESClient{}
+	provider := NewProvider(client, testConfig())
+
+	logo := model.InlineImage{ContentID: "logo", ContentType: "image/png", Data: []byte("\x89PNG\r\n")}
+	options := model.EmailOptions{CC: []string{"manager@example.com"}, InlineImages: []model.InlineImage{logo}}
+	_, err := provider.SendEmailWithOptions(context.Background(), "user@example.com", "Welcome", `<img src="cid:logo">`, options)
+	require.NoError(t, err)
+
+	require.NotNil(t, client.input.Content.Raw)
+	assert.Nil(t, client.input.Content.Simple)
+	assert.Equal(t, []string{"manager@example.com"}, client.input.Destination.CcAddresses)
+
+	message, err := mail.ReadMessage(bytes.NewReader(client.input.Content.Raw.Data))
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", message.Header.Get("To"))
+	assert.Equal(t, "manager@example.com", message.Header.Get("Cc"))
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/related", mediaType)
+	assert.Equal(t, "text/html", params["type"])
+
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	body, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=UTF-8", body.Header.Get("Content-Type"))
+
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "<logo>", part.Header.Get("Content-ID"))
+	assert.Equal(t, "image/png", part.Header.Get("Content-Type"))
+	encoded, err := io.ReadAll(part)
+	require.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	require.NoError(t, err)
+	assert.Equal(t, logo.Data, data)
+
+	_, err = reader.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestProvider_SendThreadedEmails(t *testing.T) {
+	client := &fakeSESClient{}
+	provider := NewProvider(client, testConfig())
+
+	send := func(threading model.EmailThreading) *mail.Message {
+		options := model.EmailOptions{ContentType: model.EmailContentText, Threading: threading}
+		_, err := provider.SendEmailWithOptions(context.Background(), "user@example.com", "Your order", "Hello", options)
+		require.NoError(t, err)
+		require.NotNil(t, client.input.Content.Raw)
+		assert.Nil(t, client.input.Content.Simple)
+
+		message, err := mail.ReadMessage(bytes.NewReader(client.input.Content.Raw.Data))
+		require.NoError(t, err)
+		return message
+	}
+
+	first := send(model.ThreadEmail("order-1@example.com", nil))
+	assert.Equal(t, "<order-1@example.com>", first.Header.Get("Message-ID"))
+	assert.Empty(t, first.Header.Get("In-Reply-To"))
+	assert.Empty(t, first.Header.Get("References"))
+	assert.Equal(t, "text/plain; charset=UTF-8", first.Header.Get("Content-Type"))
+	encoded, err := io.ReadAll(first.Body)
+	require.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", string(data))
+
+	second := send(model.ThreadEmail("order-2@example.com", []string{"order-1@example.com"}))
+	assert.Equal(t, "<order-2@example.com>", second.Header.Get("Message-ID"))
+	assert.Equal(t, "<order-1@example.com>", second.Header.Get("In-Reply-To"))
+	assert.Equal(t, "<order-1@example.com>", second.Header.Get("References"))
+}
+
+func TestProvider_SendEmail_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		check func(t *testing.T, err error)
+	}{
+		{
+			name: "throttling",
+			err:  &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"},
+			check: func(t *testing.T, err error) {
+				assert.ErrorAs(t, err, &ErrThrottled{})
+				assert.ErrorIs(t, err, model.ErrProviderUnavailable)
+				assert.NotErrorIs(t, err, model.ErrRejected)
+			},
+		},
+		{
+			name: "too many requests",
+			err:  &types.TooManyRequestsException{Message: aws.String("Too many requests")},
+			check: func(t *testing.T, err error) {
+				assert.ErrorAs(t, err, &ErrThrottled{})
+			},
+		},
+		{
+			name: "message rejected",
+			err:  &types.MessageRejected{Message: aws.String("Email address is not verified")},
+			check: func(t *testing.T, err error) {
+				var rejected ErrMessageRejected
+				require.ErrorAs(t, err, &rejected)
+				assert.Equal(t, "Email address is not verified", rejected.Reason)
+				assert.ErrorIs(t, err, model.ErrRejected)
+				assert.NotErrorIs(t, err, model.ErrProviderUnavailable)
+			},
+		},
+		{
+			name: "deadline exceeded",
+			err:  context.DeadlineExceeded,
+			check: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				assert.NotErrorIs(t, err, model.ErrRejected)
+			},
+		},
+		{
+			name: "other API error",
+			err:  &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "Access denied"},
+			check: func(t *testing.T, err error) {
+				var apiErr smithy.APIError
+				require.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, "AccessDeniedException", apiErr.ErrorCode())
+				assert.NotErrorIs(t, err, model.ErrRejected)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewProvider(&fakeSESClient{err: tt.err}, testConfig())
+
+			_, err := provider.SendEmail(context.Background(), "user@example.com", "Welcome", "<p>Hello</p>")
+			require.Error(t, err)
+			tt.check(t, err)
+		})
+	}
+}
+
+// fakeAccountClient answers GetAccount with account, or err
+type fakeAccountClient struct {
+	fakeSESClient
+	account *sesv2.GetAccountOutput
+	err     error
+}
+
+func (f *fakeAccountClient) GetAccount(ctx context.Context, params *sesv2.GetAccountInput, optFns ...func(*sesv2.Options)) (*sesv2.GetAccountOutput, error) {
+	return f.account, f.err
+}
+
+func TestProvider_CheckHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   SendEmailAPI
+		expected model.ProviderHealthStatus
+	}{
+		{
+			name:     "sending enabled",
+			client:   &fakeAccountClient{account: &sesv2.GetAccountOutput{SendingEnabled: true, EnforcementStatus: aws.String("HEALTHY")}},
+			expected: model.ProviderHealthy,
+		},
+		{
+			name:     "sending paused",
+			client:   &fakeAccountClient{account: &sesv2.GetAccountOutput{EnforcementStatus: aws.String("SHUTDOWN")}},
+			expected: model.ProviderUnhealthy,
+		},
+		{
+			name:     "unknown access key",
+			client:   &fakeAccountClient{err: &smithy.GenericAPIError{Code: "UnrecognizedClientException", Message: "The security token included in the request is invalid"}},
+			expected: model.ProviderUnauthorized,
+		},
+		{
+			name:     "network failure",
+			client:   &fakeAccountClient{err: errors.New("dial tcp: i/o timeout")},
+			expected: model.ProviderUnreachable,
+		},
+		{
+			name:     "client without GetAccount",
+			client:   &fakeSESClient{},
+			expected: model.ProviderUnchecked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewProvider(tt.client, testConfig()).CheckHealth(context.Background())
+			assert.Equal(t, tt.expected, model.HealthStatusForError(err))
+		})
+	}
+}
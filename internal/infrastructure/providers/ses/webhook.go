@@ -0,0 +1,331 @@
+package ses
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// ProviderName identifies SES in delivery events and webhook routes
+const ProviderName = "ses"
+
+// maxCertificateSize bounds the signing certificates read into memory
+const maxCertificateSize = 64 << 10
+
+// snsHost matches the hosts SNS serves signing certificates and subscription confirmations from
+var snsHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// Webhook parses the SES events an SNS topic posts to an HTTPS subscription. It
+// verifies each message's SNS signature, only accepts messages from the
+// configured topic, and confirms the topic's subscription when SNS asks.
+type Webhook struct {
+	topicARN string
+	client   *http.Client
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate // Signing certificates by URL
+}
+
+// NewWebhook creates a webhook parser for the SNS topic the configuration set's
+// event destination publishes to. The subscription must not use raw message
+// delivery, which strips the signature.
+func NewWebhook(topicARN string) *Webhook {
+	return &Webhook{
+		topicARN: topicARN,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		certs:    make(map[string]*x509.Certificate),
+	}
+}
+
+// snsMessage is an SNS HTTP(S) delivery
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicARN         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// sesEvent is the SES event an SNS notification carries
+type sesEvent struct {
+	EventType        string `json:"eventType"`        // Set by configuration set event destinations
+	NotificationType string `json:"notificationType"` // Set by identity feedback notifications
+	Mail             struct {
+		MessageID string `json:"messageId"`
+	} `json:"mail"`
+	Bounce *struct {
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		BouncedRecipients []struct {
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"bounce"`
+	Complaint *struct {
+		ComplaintFeedbackType string    `json:"complaintFeedbackType"`
+		Timestamp             time.Time `json:"timestamp"`
+	} `json:"complaint"`
+	Delivery *struct {
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"delivery"`
+	Open *struct {
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"open"`
+	Click *struct {
+		Link      string    `json:"link"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"click"`
+}
+
+// ParseDeliveryEvents verifies the SNS message in the request and returns the
+// delivery event it carries, if any
+func (w *Webhook) ParseDeliveryEvents(r *http.Request) ([]model.DeliveryEvent, error) {
+	var message snsMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		return nil, fmt.Errorf("error decoding SNS message: %w", err)
+	}
+
+	// SNS signs every topic's messages, so the topic must be checked too
+	if message.TopicARN != w.topicARN {
+		return nil, fmt.Errorf("%w: message from unexpected SNS topic %q", model.ErrInvalidSignature, message.TopicARN)
+	}
+
+	if err := w.verify(r.Context(), message); err != nil {
+		return nil, err
+	}
+
+	switch message.Type {
+	case "SubscriptionConfirmation":
+		return nil, w.confirmSubscription(r.Context(), message.SubscribeURL)
+	case "Notification":
+		event, ok, err := parseEvent(message.Message)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []model.DeliveryEvent{event}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// parseEvent maps an SES event to a delivery event, reporting false for events
+// that don't affect a notification
+func parseEvent(message string) (model.DeliveryEvent, bool, error) {
+	var e sesEvent
+	if err := json.Unmarshal([]byte(message), &e); err != nil {
+		return model.DeliveryEvent{}, false, fmt.Errorf("error decoding SES event: %w", err)
+	}
+
+	event := model.DeliveryEvent{
+		Provider:          ProviderName,
+		ProviderMessageID: e.Mail.MessageID,
+	}
+
+	eventType := e.EventType
+	if eventType == "" {
+		eventType = e.NotificationType
+	}
+
+	switch {
+	case eventType == "Delivery" && e.Delivery != nil:
+		event.Type = model.DeliveryEventDelivered
+		event.OccurredAt = e.Delivery.Timestamp
+	case eventType == "Bounce" && e.Bounce != nil:
+		event.Type = model.DeliveryEventBounced
+		event.Permanent = e.Bounce.BounceType == "Permanent"
+		event.Reason = fmt.Sprintf("%s bounce (%s)", e.Bounce.BounceType, e.Bounce.BounceSubType)
+		if len(e.Bounce.BouncedRecipients) > 0 && e.Bounce.BouncedRecipients[0].DiagnosticCode != "" {
+			event.Reason = e.Bounce.BouncedRecipients[0].DiagnosticCode
+		}
+		event.OccurredAt = e.Bounce.Timestamp
+	case eventType == "Complaint" && e.Complaint != nil:
+		event.Type = model.DeliveryEventComplained
+		event.Reason = e.Complaint.ComplaintFeedbackType
+		event.OccurredAt = e.Complaint.Timestamp
+	case eventType == "Open" && e.Open != nil:
+		event.Type = model.DeliveryEventOpened
+		event.OccurredAt = e.Open.Timestamp
+	case eventType == "Click" && e.Click != nil:
+		event.Type = model.DeliveryEventClicked
+		event.URL = e.Click.Link
+		event.OccurredAt = e.Click.Timestamp
+	default:
+		return model.DeliveryEvent{}, false, nil
+	}
+
+	if event.ProviderMessageID == "" {
+		return model.DeliveryEvent{}, false, nil
+	}
+	return event, true, nil
+}
+
+// verify checks the message's signature against the SNS certificate it names
+func (w *Webhook) verify(ctx context.Context, message snsMessage) error {
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil || len(signature) == 0 {
+		return fmt.Errorf("%w: malformed SNS signature", model.ErrInvalidSignature)
+	}
+
+	var hash crypto.Hash
+	var digest []byte
+	switch message.SignatureVersion {
+	case "1":
+		sum := sha1.Sum([]byte(message.stringToSign()))
+		hash, digest = crypto.SHA1, sum[:]
+	case "2":
+		sum := sha256.Sum256([]byte(message.stringToSign()))
+		hash, digest = crypto.SHA256, sum[:]
+	default:
+		return fmt.Errorf("%w: unsupported SNS signature version %q", model.ErrInvalidSignature, message.SignatureVersion)
+	}
+
+	cert, err := w.certificate(ctx, message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: SNS signing certificate has a %T key", model.ErrInvalidSignature, cert.PublicKey)
+	}
+
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return model.ErrInvalidSignature
+	}
+	return nil
+}
+
+// stringToSign builds the string SNS signs: the message's fields for its type,
+// in alphabetical order, each as its name and value on their own lines
+func (m snsMessage) stringToSign() string {
+	fields := [][2]string{{"Message", m.Message}, {"MessageId", m.MessageID}}
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			fields = append(fields, [2]string{"Subject", m.Subject})
+		}
+		fields = append(fields,
+			[2]string{"Timestamp", m.Timestamp},
+			[2]string{"TopicArn", m.TopicARN},
+			[2]string{"Type", m.Type},
+		)
+	} else {
+		fields = append(fields,
+			[2]string{"SubscribeURL", m.SubscribeURL},
+			[2]string{"Timestamp", m.Timestamp},
+			[2]string{"Token", m.Token},
+			[2]string{"TopicArn", m.TopicARN},
+			[2]string{"Type", m.Type},
+		)
+	}
+
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(field[0])
+		b.WriteByte('\n')
+		b.WriteString(field[1])
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// certificate returns the SNS signing certificate at certURL, fetching it once
+func (w *Webhook) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	// Anyone can sign with a certificate they host, so only trust SNS's own
+	if !isSNSURL(certURL) {
+		return nil, fmt.Errorf("%w: signing certificate not served by SNS: %q", model.ErrInvalidSignature, certURL)
+	}
+
+	w.mu.Lock()
+	cert, ok := w.certs[certURL]
+	w.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating SNS certificate request: %w", err)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching SNS signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching SNS signing certificate: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCertificateSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading SNS signing certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("SNS signing certificate at %s is not PEM encoded", certURL)
+	}
+
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SNS signing certificate: %w", err)
+	}
+
+	w.mu.Lock()
+	w.certs[certURL] = cert
+	w.mu.Unlock()
+
+	return cert, nil
+}
+
+// confirmSubscription confirms the topic's subscription to this webhook
+func (w *Webhook) confirmSubscription(ctx context.Context, subscribeURL string) error {
+	if !isSNSURL(subscribeURL) {
+		return fmt.Errorf("SNS subscription confirmation URL not served by SNS: %q", subscribeURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating SNS subscription confirmation: %w", err)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error confirming SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error confirming SNS subscription: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// isSNSURL reports whether rawURL is an HTTPS URL on an SNS endpoint
+func isSNSURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "https" && snsHost.MatchString(u.Hostname())
+}
@@ -0,0 +1,195 @@
+package ses
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTopicARN = "arn:aws:sns:us-east-1:123456789012:ses-events"
+	testCertURL  = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-test.pem"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// setupTestWebhook returns a webhook whose HTTP client serves a test signing
+// certificate from testCertURL, and the URLs it requested
+func setupTestWebhook(t *testing.T) (*Webhook, *rsa.PrivateKey, *[]string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	var requested []string
+	webhook := NewWebhook(testTopicARN)
+	webhook.client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requested = append(requested, r.URL.String())
+		body := []byte("<ConfirmSubscriptionResponse/>")
+		if r.URL.String() == testCertURL {
+			body = certPEM
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Header: make(http.Header)}, nil
+	})}
+
+	return webhook, key, &requested
+}
+
+func signedRequest(t *testing.T, key *rsa.PrivateKey, message snsMessage) *http.Request {
+	if message.TopicARN == "" {
+		message.TopicARN = testTopicARN
+	}
+	if message.SigningCertURL == "" {
+		message.SigningCertURL = testCertURL
+	}
+	message.MessageID = "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324"
+	message.Timestamp = "2025-01-11T09:00:00.000Z"
+	message.SignatureVersion = "2"
+
+	digest := sha256.Sum256([]byte(message.stringToSign()))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	message.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	body, err := json.Marshal(message)
+	require.NoError(t, err)
+	return httptest.NewRequest(http.MethodPost, "/webhooks/providers/ses", bytes.NewReader(body))
+}
+
+func notification(event string) snsMessage {
+	return snsMessage{Type: "Notification", Message: event}
+}
+
+func TestWebhook_ParseDeliveryEvents(t *testing.T) {
+	webhook, key, requested := setupTestWebhook(t)
+
+	tests := []struct {
+		name     string
+		event    string
+		expected []model.DeliveryEvent
+	}{
+		{
+			name:  "delivery",
+			event: `{"eventType": "Delivery", "mail": {"messageId": "msg-1"}, "delivery": {"timestamp": "2025-01-11T09:00:01Z"}}`,
+			expected: []model.DeliveryEvent{
+				{Provider: ProviderName, ProviderMessageID: "msg-1", Type: model.DeliveryEventDelivered, OccurredAt: time.Date(2025, 1, 11, 9, 0, 1, 0, time.UTC)},
+			},
+		},
+		{
+			name: "permanent bounce",
+			event: `{"eventType": "Bounce", "mail": {"messageId": "msg-1"}, "bounce": {"bounceType": "Permanent", "bounceSubType": "General",
+				"bouncedRecipients": [{"emailAddress": "user@example.com", "diagnosticCode": "smtp; 550 5.1.1 user unknown"}], "timestamp": "2025-01-11T09:00:01Z"}}`,
+			expected: []model.DeliveryEvent{
+				{Provider: ProviderName, ProviderMessageID: "msg-1", Type: model.DeliveryEventBounced, Permanent: true, Reason: "smtp; 550 5.1.1 user unknown", OccurredAt: time.Date(2025, 1, 11, 9, 0, 1, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "transient bounce",
+			event: `{"notificationType": "Bounce", "mail": {"messageId": "msg-1"}, "bounce": {"bounceType": "Transient", "bounceSubType": "MailboxFull", "timestamp": "2025-01-11T09:00:01Z"}}`,
+			expected: []model.DeliveryEvent{
+				{Provider: ProviderName, ProviderMessageID: "msg-1", Type: model.DeliveryEventBounced, Reason: "Transient bounce (MailboxFull)", OccurredAt: time.Date(2025, 1, 11, 9, 0, 1, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "complaint",
+			event: `{"eventType": "Complaint", "mail": {"messageId": "msg-1"}, "complaint": {"complaintFeedbackType": "abuse", "timestamp": "2025-01-11T09:00:01Z"}}`,
+			expected: []model.DeliveryEvent{
+				{Provider: ProviderName, ProviderMessageID: "msg-1", Type: model.DeliveryEventComplained, Reason: "abuse", OccurredAt: time.Date(2025, 1, 11, 9, 0, 1, 0, time.UTC)},
+			},
+		},
+		{
+			name:     "send is ignored",
+			event:    `{"eventType": "Send", "mail": {"messageId": "msg-1"}, "send": {}}`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := webhook.ParseDeliveryEvents(signedRequest(t, key, notification(tt.event)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, events)
+		})
+	}
+
+	// The signing certificate is fetched once
+	assert.Equal(t, []string{testCertURL}, *requested)
+}
+
+func TestWebhook_ParseDeliveryEvents_InvalidSignature(t *testing.T) {
+	webhook, key, requested := setupTestWebhook(t)
+	event := `{"eventType": "Complaint", "mail": {"messageId": "msg-1"}, "complaint": {"timestamp": "2025-01-11T09:00:01Z"}}`
+
+	t.Run("tampered message", func(t *testing.T) {
+		req := signedRequest(t, key, notification(event))
+		var message snsMessage
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&message))
+		message.Message = `{"eventType": "Complaint", "mail": {"messageId": "msg-2"}, "complaint": {"timestamp": "2025-01-11T09:00:01Z"}}`
+		body, err := json.Marshal(message)
+		require.NoError(t, err)
+
+		_, err = webhook.ParseDeliveryEvents(httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+		assert.ErrorIs(t, err, model.ErrInvalidSignature)
+	})
+
+	t.Run("another topic", func(t *testing.T) {
+		message := notification(event)
+		message.TopicARN = "arn:aws:sns:us-east-1:999999999999:someone-else"
+
+		_, err := webhook.ParseDeliveryEvents(signedRequest(t, key, message))
+		assert.ErrorIs(t, err, model.ErrInvalidSignature)
+	})
+
+	t.Run("certificate not served by SNS", func(t *testing.T) {
+		message := notification(event)
+		message.SigningCertURL = "https://sns.us-east-1.amazonaws.com.example.com/cert.pem"
+
+		_, err := webhook.ParseDeliveryEvents(signedRequest(t, key, message))
+		assert.ErrorIs(t, err, model.ErrInvalidSignature)
+	})
+
+	// Only the trusted certificate was ever fetched
+	assert.Equal(t, []string{testCertURL}, *requested)
+}
+
+func TestWebhook_ParseDeliveryEvents_ConfirmsSubscription(t *testing.T) {
+	webhook, key, requested := setupTestWebhook(t)
+	subscribeURL := "https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription&TopicArn=" + testTopicARN + "&Token=token"
+
+	events, err := webhook.ParseDeliveryEvents(signedRequest(t, key, snsMessage{
+		Type:         "SubscriptionConfirmation",
+		Message:      "You have chosen to subscribe to the topic.",
+		Token:        "token",
+		SubscribeURL: subscribeURL,
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, []string{testCertURL, subscribeURL}, *requested)
+}
@@ -0,0 +1,114 @@
+package twilio
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+)
+
+// ProviderName identifies Twilio in delivery events and webhook routes
+const ProviderName = "twilio"
+
+// SignatureHeader carries the signature Twilio computes for every webhook request
+const SignatureHeader = "X-Twilio-Signature"
+
+// Webhook parses Twilio message status callbacks, verifying their signature
+type Webhook struct {
+	authToken string
+	baseURL   string
+}
+
+// NewWebhook creates a webhook parser. Twilio signs the full URL it called, so
+// baseURL is the service's public URL (e.g. https://notify.example.com) as
+// configured in Twilio; when empty it is derived from the request.
+func NewWebhook(authToken, baseURL string) *Webhook {
+	return &Webhook{
+		authToken: authToken,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// ParseDeliveryEvents verifies the request's signature and returns the delivery
+// event it carries, if its status affects the notification
+func (w *Webhook) ParseDeliveryEvents(r *http.Request) ([]model.DeliveryEvent, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("error decoding Twilio webhook: %w", err)
+	}
+
+	signature := r.Header.Get(SignatureHeader)
+	if signature == "" {
+		return nil, fmt.Errorf("%w: missing Twilio signature header", model.ErrInvalidSignature)
+	}
+	expected := Signature(w.authToken, w.requestURL(r), r.PostForm)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, model.ErrInvalidSignature
+	}
+
+	event := model.DeliveryEvent{
+		Provider:          ProviderName,
+		ProviderMessageID: r.PostForm.Get("MessageSid"),
+		OccurredAt:        time.Now(),
+	}
+
+	switch r.PostForm.Get("MessageStatus") {
+	case "delivered":
+		event.Type = model.DeliveryEventDelivered
+	case "undelivered", "failed":
+		event.Type = model.DeliveryEventBounced
+		event.Reason = fmt.Sprintf("Twilio error %s", r.PostForm.Get("ErrorCode"))
+	case "read":
+		event.Type = model.DeliveryEventOpened
+	default:
+		// queued, sending, sent... are progress we already know about
+		return nil, nil
+	}
+
+	if event.ProviderMessageID == "" {
+		return nil, nil
+	}
+	return []model.DeliveryEvent{event}, nil
+}
+
+// requestURL reconstructs the URL Twilio called
+func (w *Webhook) requestURL(r *http.Request) string {
+	if w.baseURL != "" {
+		return w.baseURL + r.URL.RequestURI()
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// Signature computes Twilio's request signature: an HMAC-SHA1, keyed with the
+// auth token, of the URL followed by every POST parameter name and value in
+// parameter name order
+func Signature(authToken, url string, params map[string][]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var payload strings.Builder
+	payload.WriteString(url)
+	for _, name := range names {
+		for _, value := range params[name] {
+			payload.WriteString(name)
+			payload.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(payload.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,98 @@
+package twilio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAuthToken = "12345"
+	testBaseURL   = "https://notify.example.com"
+)
+
+func statusCallback(params url.Values, signature string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/providers/twilio", strings.NewReader(params.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(SignatureHeader, signature)
+	return req
+}
+
+func TestSignature(t *testing.T) {
+	// Example from Twilio's webhook security documentation
+	params := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	assert.Equal(t, "0/KCTR6DLpKmkAf8muzZqo1nDgQ=", Signature(testAuthToken, "https://mycompany.com/myapp.php?foo=1&bar=2", params))
+}
+
+func TestWebhook_ParseDeliveryEvents(t *testing.T) {
+	webhook := NewWebhook(testAuthToken, testBaseURL+"/")
+
+	tests := []struct {
+		name     string
+		params   url.Values
+		expected []model.DeliveryEvent
+	}{
+		{
+			name:   "delivered",
+			params: url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}},
+			expected: []model.DeliveryEvent{
+				{Provider: ProviderName, ProviderMessageID: "SM123", Type: model.DeliveryEventDelivered},
+			},
+		},
+		{
+			name:   "undelivered",
+			params: url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"undelivered"}, "ErrorCode": {"30003"}},
+			expected: []model.DeliveryEvent{
+				{Provider: ProviderName, ProviderMessageID: "SM123", Type: model.DeliveryEventBounced, Reason: "Twilio error 30003"},
+			},
+		},
+		{
+			name:     "progress is ignored",
+			params:   url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"sent"}},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := Signature(testAuthToken, testBaseURL+"/webhooks/providers/twilio", tt.params)
+
+			events, err := webhook.ParseDeliveryEvents(statusCallback(tt.params, signature))
+			require.NoError(t, err)
+			for i := range events {
+				assert.False(t, events[i].OccurredAt.IsZero())
+				events[i].OccurredAt = tt.expected[i].OccurredAt
+			}
+			assert.Equal(t, tt.expected, events)
+		})
+	}
+}
+
+func TestWebhook_ParseDeliveryEvents_InvalidSignature(t *testing.T) {
+	webhook := NewWebhook(testAuthToken, testBaseURL)
+	params := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}}
+
+	t.Run("signed with another token", func(t *testing.T) {
+		signature := Signature("other", testBaseURL+"/webhooks/providers/twilio", params)
+
+		_, err := webhook.ParseDeliveryEvents(statusCallback(params, signature))
+		assert.ErrorIs(t, err, model.ErrInvalidSignature)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		_, err := webhook.ParseDeliveryEvents(statusCallback(params, ""))
+		assert.ErrorIs(t, err, model.ErrInvalidSignature)
+	})
+}
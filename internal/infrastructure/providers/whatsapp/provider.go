@@ -0,0 +1,271 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// ProviderName identifies the WhatsApp Cloud API on the messages it sends
+const ProviderName = "whatsapp"
+
+// Config holds the WhatsApp Cloud API configuration
+type Config struct {
+	// BaseURL is the Graph API root, including its version
+	BaseURL string
+	// PhoneNumberID identifies the business phone number messages are sent from
+	PhoneNumberID string
+	// AccessToken authenticates requests to the Graph API
+	AccessToken string
+	// Timeout bounds each request to the API
+	Timeout time.Duration
+}
+
+// DefaultConfig returns a default WhatsApp Cloud API configuration
+func DefaultConfig() Config {
+	return Config{
+		BaseURL: "https://graph.facebook.com/v19.0",
+		Timeout: 10 * time.Second,
+	}
+}
+
+// Cloud API error codes the provider reports as typed errors
+const (
+	codeInvalidAccessToken     = 190
+	codeSessionWindowClosed    = 131047
+	codeRecipientUndeliverable = 131026
+	codeRecipientNotAllowed    = 131030
+	codeTemplateNotFound       = 132001
+	codeTemplatePaused         = 132015
+	codeTemplateDisabled       = 132016
+)
+
+// ErrSessionWindowClosed is returned when a message is only allowed within 24
+// hours of the recipient's last message to the business
+type ErrSessionWindowClosed struct {
+	Recipient string
+}
+
+func (e ErrSessionWindowClosed) Error() string {
+	return fmt.Sprintf("more than 24 hours have passed since %s last replied; only approved templates can be sent", e.Recipient)
+}
+
+// Is reports the error as model.ErrValidation
+func (e ErrSessionWindowClosed) Is(target error) bool { return target == model.ErrValidation }
+
+// ErrTemplateNotApproved is returned when a template doesn't exist in the
+// requested language or has been paused or disabled by WhatsApp
+type ErrTemplateNotApproved struct {
+	Name     string
+	Language string
+	Reason   string
+}
+
+func (e ErrTemplateNotApproved) Error() string {
+	return fmt.Sprintf("WhatsApp template %s (%s) can't be used: %s", e.Name, e.Language, e.Reason)
+}
+
+// Is reports the error as model.ErrValidation
+func (e ErrTemplateNotApproved) Is(target error) bool { return target == model.ErrValidation }
+
+// APIError is an error returned by the Cloud API that has no more specific type
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("WhatsApp API error %d (HTTP %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// Provider sends WhatsApp template messages through the WhatsApp Cloud API
+type Provider struct {
+	config Config
+	client *http.Client
+}
+
+// NewProvider creates a new WhatsApp Cloud API provider
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+type messageRequest struct {
+	MessagingProduct string          `json:"messaging_product"`
+	To               string          `json:"to"`
+	Type             string          `json:"type"`
+	Template         templateRequest `json:"template"`
+}
+
+type templateRequest struct {
+	Name       string              `json:"name"`
+	Language   templateLanguage    `json:"language"`
+	Components []templateComponent `json:"components,omitempty"`
+}
+
+type templateLanguage struct {
+	Code string `json:"code"`
+}
+
+type templateComponent struct {
+	Type       string              `json:"type"`
+	Parameters []templateParameter `json:"parameters"`
+}
+
+type templateParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type messageResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message   string `json:"message"`
+		Code      int    `json:"code"`
+		ErrorData struct {
+			Details string `json:"details"`
+		} `json:"error_data"`
+	} `json:"error"`
+}
+
+// SendWhatsApp sends a template message to an E.164 phone number, returning
+// the WhatsApp message ID (wamid) the Cloud API assigned it
+func (p *Provider) SendWhatsApp(ctx context.Context, to string, message model.WhatsAppTemplateMessage) (string, error) {
+	start := time.Now()
+	operation := "whatsapp_send"
+
+	id, err := p.send(ctx, to, message)
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	metrics.RecordOperationDuration(operation, status, time.Since(start).Seconds())
+
+	return id, err
+}
+
+func (p *Provider) send(ctx context.Context, to string, message model.WhatsAppTemplateMessage) (string, error) {
+	request := messageRequest{
+		MessagingProduct: "whatsapp",
+		To:               strings.TrimPrefix(to, "+"),
+		Type:             "template",
+		Template: templateRequest{
+			Name:     message.Name,
+			Language: templateLanguage{Code: message.Language},
+		},
+	}
+	if len(message.Parameters) > 0 {
+		body := templateComponent{Type: "body"}
+		for _, parameter := range message.Parameters {
+			body.Parameters = append(body.Parameters, templateParameter{Type: "text", Text: parameter})
+		}
+		request.Template.Components = []templateComponent{body}
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("error encoding WhatsApp message: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/%s/messages", strings.TrimSuffix(p.config.BaseURL, "/"), p.config.PhoneNumberID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("error creating WhatsApp request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.config.AccessToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error calling WhatsApp API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var sent messageResponse
+		if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil {
+			return "", fmt.Errorf("error decoding WhatsApp response: %w", err)
+		}
+		if len(sent.Messages) == 0 {
+			return "", nil
+		}
+		return sent.Messages[0].ID, nil
+	}
+
+	var apiErr errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+		return "", &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	}
+
+	return "", classifyError(resp.StatusCode, apiErr, to, message)
+}
+
+// classifyError maps Cloud API errors the caller can act on to typed errors
+func classifyError(statusCode int, apiErr errorResponse, to string, message model.WhatsAppTemplateMessage) error {
+	reason := apiErr.Error.ErrorData.Details
+	if reason == "" {
+		reason = apiErr.Error.Message
+	}
+
+	switch apiErr.Error.Code {
+	case codeSessionWindowClosed:
+		return ErrSessionWindowClosed{Recipient: to}
+	case codeRecipientUndeliverable, codeRecipientNotAllowed:
+		return model.ErrInvalidRecipient{Recipient: to}
+	case codeTemplateNotFound, codeTemplatePaused, codeTemplateDisabled:
+		return ErrTemplateNotApproved{Name: message.Name, Language: message.Language, Reason: reason}
+	default:
+		return &APIError{StatusCode: statusCode, Code: apiErr.Error.Code, Message: reason}
+	}
+}
+
+// CheckHealth looks up the business phone number messages are sent from, which
+// confirms the Cloud API is reachable and accepts the access token without
+// sending a message
+func (p *Provider) CheckHealth(ctx context.Context) error {
+	url := fmt.Sprintf("%s/%s?fields=id", strings.TrimSuffix(p.config.BaseURL, "/"), p.config.PhoneNumberID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating WhatsApp request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.AccessToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling WhatsApp API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var apiErr errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+		apiErr.Error.Message = http.StatusText(resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || apiErr.Error.Code == codeInvalidAccessToken {
+		return model.ErrProviderUnauthorized{Provider: ProviderName, Reason: apiErr.Error.Message}
+	}
+	return &APIError{StatusCode: resp.StatusCode, Code: apiErr.Error.Code, Message: apiErr.Error.Message}
+}
@@ -0,0 +1,159 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ services.WhatsAppProvider = (*Provider)(nil)
+
+func setupTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.PhoneNumberID = "1234567890"
+	config.AccessToken = "test-token"
+	return NewProvider(config)
+}
+
+func TestProvider_SendWhatsApp(t *testing.T) {
+	var received messageRequest
+	provider := setupTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/1234567890/messages", r.URL.Path)
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{"messages": [{"id": "wamid.1"}]}`))
+	})
+
+	messageID, err := provider.SendWhatsApp(context.Background(), "+14155552671", model.WhatsAppTemplateMessage{
+		Name:       "order_shipped",
+		Language:   "en_US",
+		Parameters: []string{"Jane", "#1042"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "wamid.1", messageID)
+
+	assert.Equal(t, "whatsapp", received.MessagingProduct)
+	assert.Equal(t, "14155552671", received.To)
+	assert.Equal(t, "template", received.Type)
+	assert.Equal(t, "order_shipped", received.Template.Name)
+	assert.Equal(t, "en_US", received.Template.Language.Code)
+	require.Len(t, received.Template.Components, 1)
+	assert.Equal(t, []templateParameter{{Type: "text", Text: "Jane"}, {Type: "text", Text: "#1042"}}, received.Template.Components[0].Parameters)
+}
+
+func TestProvider_SendWhatsApp_Errors(t *testing.T) {
+	message := model.WhatsAppTemplateMessage{Name: "order_shipped", Language: "en_US"}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		check      func(t *testing.T, err error)
+	}{
+		{
+			name:       "session window closed",
+			statusCode: http.StatusBadRequest,
+			body:       `{"error": {"message": "Re-engagement message", "code": 131047}}`,
+			check: func(t *testing.T, err error) {
+				assert.ErrorAs(t, err, &ErrSessionWindowClosed{})
+				assert.ErrorIs(t, err, model.ErrValidation)
+			},
+		},
+		{
+			name:       "template not approved",
+			statusCode: http.StatusNotFound,
+			body:       `{"error": {"message": "Template name does not exist in the translation", "code": 132001, "error_data": {"details": "template name (order_shipped) does not exist in en_US"}}}`,
+			check: func(t *testing.T, err error) {
+				var notApproved ErrTemplateNotApproved
+				require.ErrorAs(t, err, &notApproved)
+				assert.Equal(t, "template name (order_shipped) does not exist in en_US", notApproved.Reason)
+				assert.ErrorIs(t, err, model.ErrValidation)
+			},
+		},
+		{
+			name:       "recipient not on WhatsApp",
+			statusCode: http.StatusBadRequest,
+			body:       `{"error": {"message": "Message undeliverable", "code": 131026}}`,
+			check: func(t *testing.T, err error) {
+				assert.ErrorAs(t, err, &model.ErrInvalidRecipient{})
+			},
+		},
+		{
+			name:       "other API error",
+			statusCode: http.StatusInternalServerError,
+			body:       `{"error": {"message": "Service temporarily unavailable", "code": 2}}`,
+			check: func(t *testing.T, err error) {
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, 2, apiErr.Code)
+				assert.NotErrorIs(t, err, model.ErrValidation)
+			},
+		},
+		{
+			name:       "unreadable error body",
+			statusCode: http.StatusBadGateway,
+			body:       `<html>Bad Gateway</html>`,
+			check: func(t *testing.T, err error) {
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := setupTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := provider.SendWhatsApp(context.Background(), "+14155552671", message)
+			require.Error(t, err)
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestProvider_CheckHealth(t *testing.T) {
+	var _ services.HealthCheckedProvider = (*Provider)(nil)
+
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		expectedStatus model.ProviderHealthStatus
+	}{
+		{name: "healthy", status: http.StatusOK, body: `{"id": "1234567890"}`, expectedStatus: model.ProviderHealthy},
+		{name: "expired token", status: http.StatusBadRequest, body: `{"error": {"message": "Session has expired", "code": 190}}`, expectedStatus: model.ProviderUnauthorized},
+		{name: "forbidden", status: http.StatusForbidden, body: `{}`, expectedStatus: model.ProviderUnauthorized},
+		{name: "server error", status: http.StatusInternalServerError, body: `not json`, expectedStatus: model.ProviderUnreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := setupTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				// Nothing is sent: the phone number is only looked up
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/1234567890", r.URL.Path)
+				assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			err := provider.CheckHealth(context.Background())
+			assert.Equal(t, tt.expectedStatus, model.HealthStatusForError(err))
+		})
+	}
+}
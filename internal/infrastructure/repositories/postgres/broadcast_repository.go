@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// broadcastProgressColumns lists the broadcast progress columns in scan order
+const broadcastProgressColumns = `tenant_id, broadcast_id, cursor, enqueued, skipped, completed, created_at, updated_at`
+
+// BroadcastProgressRepository implements repository.BroadcastProgressRepository using PostgreSQL
+type BroadcastProgressRepository struct {
+	db *sql.DB
+}
+
+// NewBroadcastProgressRepository creates a new PostgreSQL-based broadcast progress repository
+func NewBroadcastProgressRepository(db *sql.DB) *BroadcastProgressRepository {
+	return &BroadcastProgressRepository{
+		db: db,
+	}
+}
+
+// FindBroadcastProgress finds a broadcast's progress in PostgreSQL
+func (r *BroadcastProgressRepository) FindBroadcastProgress(ctx context.Context, broadcastID string) (*model.BroadcastProgress, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_broadcast_progress", status, duration)
+	}()
+
+	query := `
+		SELECT ` + broadcastProgressColumns + `
+		FROM broadcast_progress
+		WHERE tenant_id = $1 AND broadcast_id = $2`
+
+	var progress model.BroadcastProgress
+	err = r.db.QueryRowContext(ctx, query, model.TenantFromContext(ctx), broadcastID).Scan(
+		&progress.TenantID,
+		&progress.BroadcastID,
+		&progress.Cursor,
+		&progress.Enqueued,
+		&progress.Skipped,
+		&progress.Completed,
+		&progress.CreatedAt,
+		&progress.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		err = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find broadcast progress: %w", err)
+	}
+
+	return &progress, nil
+}
+
+// SaveBroadcastProgress creates or replaces a broadcast's progress in PostgreSQL
+func (r *BroadcastProgressRepository) SaveBroadcastProgress(ctx context.Context, progress *model.BroadcastProgress) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_save_broadcast_progress", status, duration)
+	}()
+
+	tenantID, err := model.ResolveTenantID(ctx, progress.TenantID)
+	if err != nil {
+		return err
+	}
+	progress.TenantID = tenantID
+
+	query := `
+		INSERT INTO broadcast_progress (` + broadcastProgressColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, broadcast_id) DO UPDATE SET
+			cursor = EXCLUDED.cursor,
+			enqueued = EXCLUDED.enqueued,
+			skipped = EXCLUDED.skipped,
+			completed = EXCLUDED.completed,
+			updated_at = EXCLUDED.updated_at`
+
+	_, err = r.db.ExecContext(ctx, query,
+		progress.TenantID,
+		progress.BroadcastID,
+		progress.Cursor,
+		progress.Enqueued,
+		progress.Skipped,
+		progress.Completed,
+		progress.CreatedAt,
+		progress.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save broadcast progress: %w", err)
+	}
+
+	return nil
+}
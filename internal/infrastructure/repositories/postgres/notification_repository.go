@@ -5,27 +5,615 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
 	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
 )
 
+// notificationColumns lists the notification columns in scan order
+const notificationColumns = `
+			id, tenant_id, recipient, type, subject, content, status, priority,
+			template_id, template_type, template_data, metadata,
+			error_message, retry_count, created_at, updated_at,
+			provider_message_id, category, tags, correlation_id, provider, group_id,
+			content_type, cc, next_retry_at, inline_images,
+			thread_id, email_message_id, scheduled_at`
+
+const (
+	// notificationColumnCount is the number of columns in notificationColumns
+	notificationColumnCount = 29
+
+	// maxBatchInsertRows keeps a multi-row INSERT under Postgres' limit of 65535 bind parameters
+	maxBatchInsertRows = 1000
+
+	// scanPageSize is how many notifications a scan reads per query
+	scanPageSize = 500
+
+	// purgeBatchSize is how many notifications a purge deletes per statement,
+	// keeping each statement's locks short
+	purgeBatchSize = 1000
+)
+
+// insertNotificationQuery inserts a single notification
+var insertNotificationQuery = `
+		INSERT INTO notifications (` + notificationColumns + `
+		) VALUES (` + placeholders(1, notificationColumnCount) + `)`
+
+// updateNotificationQuery updates a notification's mutable columns
+const updateNotificationQuery = `
+		UPDATE notifications
+		SET recipient = $2,
+			type = $3,
+			subject = $4,
+			content = $5,
+			status = $6,
+			priority = $7,
+			template_id = $8,
+			template_type = $9,
+			template_data = $10,
+			metadata = $11,
+			error_message = $12,
+			retry_count = $13,
+			provider_message_id = $14,
+			category = $16,
+			tags = $17,
+			provider = $18,
+			next_retry_at = $19,
+			email_message_id = $20,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $15`
+
+// findNotificationByIDQuery selects one of a tenant's notifications
+const findNotificationByIDQuery = `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE id = $1 AND tenant_id = $2`
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 // NotificationRepository implements repository.NotificationRepository using PostgreSQL
 type NotificationRepository struct {
 	db *sql.DB
+
+	// Statements for the hot paths, prepared once so Postgres doesn't parse
+	// and plan them on every call. database/sql prepares each of them on a
+	// pooled connection the first time it is used there, and again if that
+	// connection is replaced.
+	insertStmt   *sql.Stmt
+	updateStmt   *sql.Stmt
+	findByIDStmt *sql.Stmt
+}
+
+// NewNotificationRepository creates a new PostgreSQL-based notification
+// repository, preparing its statements. The schema must already be migrated.
+func NewNotificationRepository(db *sql.DB) (*NotificationRepository, error) {
+	r := &NotificationRepository{db: db}
+
+	statements := []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&r.insertStmt, insertNotificationQuery},
+		{&r.updateStmt, updateNotificationQuery},
+		{&r.findByIDStmt, findNotificationByIDQuery},
+	}
+	for _, statement := range statements {
+		stmt, err := db.PrepareContext(context.Background(), statement.query)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to prepare notification statement: %w", err)
+		}
+		*statement.stmt = stmt
+	}
+
+	return r, nil
+}
+
+// Close closes the repository's prepared statements; the database is left open
+func (r *NotificationRepository) Close() error {
+	var firstErr error
+	for _, stmt := range []*sql.Stmt{r.insertStmt, r.updateStmt, r.findByIDStmt} {
+		if stmt == nil {
+			continue
+		}
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Save saves a notification to PostgreSQL
+func (r *NotificationRepository) Save(ctx context.Context, notification *model.Notification) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_save_notification", status, duration)
+	}()
+
+	tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
+	if err != nil {
+		return err
+	}
+	notification.TenantID = tenantID
+
+	values, err := notificationValues(notification)
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.insertStmt.ExecContext(ctx, values...); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	return nil
+}
+
+// SaveBatch saves notifications to PostgreSQL with multi-row INSERTs in a single
+// transaction, so either every notification is saved or none is
+func (r *NotificationRepository) SaveBatch(ctx context.Context, notifications []*model.Notification) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_save_notification_batch", status, duration)
+	}()
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	for _, notification := range notifications {
+		tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
+		if err != nil {
+			return err
+		}
+		notification.TenantID = tenantID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for first := 0; first < len(notifications); first += maxBatchInsertRows {
+		last := first + maxBatchInsertRows
+		if last > len(notifications) {
+			last = len(notifications)
+		}
+		if err = insertNotifications(ctx, tx, notifications[first:last]); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CountByStatus counts notifications per status across all tenants in PostgreSQL
+func (r *NotificationRepository) CountByStatus(ctx context.Context) (map[model.NotificationStatus]int64, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_count_notifications_by_status", status, duration)
+	}()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM notifications GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[model.NotificationStatus]int64)
+	for rows.Next() {
+		var status model.NotificationStatus
+		var count int64
+		if err = rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan notification count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountSince counts the tenant's notifications created since since per status,
+// type and priority from PostgreSQL. The three groupings are computed in a
+// single pass over the window; rows of one grouping leave the other columns NULL.
+func (r *NotificationRepository) CountSince(ctx context.Context, since time.Time) (*model.NotificationStats, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_count_notifications_since", status, duration)
+	}()
+
+	query := `
+		SELECT status, type, priority, COUNT(*)
+		FROM notifications
+		WHERE tenant_id = $1 AND created_at >= $2
+		GROUP BY GROUPING SETS ((status), (type), (priority))`
+
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	defer rows.Close()
+
+	stats := model.NewNotificationStats(since)
+	for rows.Next() {
+		var status, notificationType, priority sql.NullString
+		var count int64
+		if err = rows.Scan(&status, &notificationType, &priority, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan notification count: %w", err)
+		}
+		switch {
+		case status.Valid:
+			stats.ByStatus[model.NotificationStatus(status.String)] = count
+			stats.Total += count
+		case notificationType.Valid:
+			stats.ByType[model.NotificationType(notificationType.String)] = count
+		case priority.Valid:
+			stats.ByPriority[model.Priority(priority.String)] = count
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification counts: %w", err)
+	}
+
+	return stats, nil
+}
+
+// FindByID finds a notification by ID from PostgreSQL
+func (r *NotificationRepository) FindByID(ctx context.Context, id string) (*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notification_by_id", status, duration)
+	}()
+
+	// No notification has an ID that isn't a UUID
+	uid, parseErr := uuid.Parse(id)
+	if parseErr != nil {
+		return nil, nil
+	}
+
+	notification, err := scanNotification(r.findByIDStmt.QueryRowContext(ctx, uid, model.TenantFromContext(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification: %w", err)
+	}
+
+	return notification, nil
+}
+
+// FindByProviderMessageID finds a notification by the provider that sent it and
+// its provider message ID across all tenants from PostgreSQL. Notifications
+// without a provider are only matched if no notification of provider has messageID.
+func (r *NotificationRepository) FindByProviderMessageID(ctx context.Context, provider, messageID string) (*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notification_by_provider_message_id", status, duration)
+	}()
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE provider_message_id = $2 AND (provider = $1 OR provider = '')
+		ORDER BY provider = $1 DESC
+		LIMIT 1`
+
+	notification, err := scanNotification(r.db.QueryRowContext(ctx, query, provider, messageID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification: %w", err)
+	}
+
+	return notification, nil
 }
 
-// NewNotificationRepository creates a new PostgreSQL-based notification repository
-func NewNotificationRepository(db *sql.DB) *NotificationRepository {
-	return &NotificationRepository{
-		db: db,
+// FindByIDs finds the notifications with the given IDs from PostgreSQL in a single query.
+// IDs that don't exist are omitted from the result.
+func (r *NotificationRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notifications_by_ids", status, duration)
+	}()
+
+	// IDs that aren't UUIDs can't exist, and would fail the cast below
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, parseErr := uuid.Parse(id); parseErr == nil {
+			valid = append(valid, id)
+		}
+	}
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = $1 AND id = ANY($2::uuid[])`
+
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), pq.Array(valid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*model.Notification, 0, len(ids))
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// FindByRecipient finds notifications by recipient from PostgreSQL with
+// pagination, in order of creation
+func (r *NotificationRepository) FindByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notifications_by_recipient", status, duration)
+	}()
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = $1 AND recipient = $2
+		ORDER BY created_at ` + sortDirection(order) + `
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), recipient, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// FindByStatus finds notifications in the given status from PostgreSQL with pagination, newest first
+func (r *NotificationRepository) FindByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notifications_by_status", status, duration)
+	}()
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = $1 AND status = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// FindByMetadata finds notifications whose metadata contains every key/value
+// pair in filters from PostgreSQL with pagination, newest first. The
+// containment check is served by the metadata GIN index.
+func (r *NotificationRepository) FindByMetadata(ctx context.Context, filters map[string]string, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notifications_by_metadata", status, duration)
+	}()
+
+	containment, err := json.Marshal(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata filters: %w", err)
+	}
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = $1 AND metadata @> $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), containment, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// FindByCategory finds notifications in category from PostgreSQL with
+// pagination, newest first
+func (r *NotificationRepository) FindByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notifications_by_category", status, duration)
+	}()
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = $1 AND category = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	notifications, err := r.findPage(ctx, query, model.TenantFromContext(ctx), category, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// FindByCorrelationID finds the notifications produced by the request or
+// event with the given correlation ID from PostgreSQL, oldest first
+func (r *NotificationRepository) FindByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_notifications_by_correlation_id", status, duration)
+	}()
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = $1 AND correlation_id = $2
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4`
+
+	notifications, err := r.findPage(ctx, query, model.TenantFromContext(ctx), correlationID, limit, offset)
+	if err != nil {
+		return nil, err
 	}
+
+	return notifications, nil
 }
 
-// Save saves a notification to PostgreSQL
-func (r *NotificationRepository) Save(ctx context.Context, notification *model.Notification) error {
+// FindByGroupID finds the notifications sent together as a multi-channel
+// notification from PostgreSQL, oldest first
+func (r *NotificationRepository) FindByGroupID(ctx context.Context, groupID string) ([]*model.Notification, error) {
 	start := time.Now()
 	var err error
 	defer func() {
@@ -34,55 +622,25 @@ func (r *NotificationRepository) Save(ctx context.Context, notification *model.N
 		if err != nil {
 			status = "error"
 		}
-		metrics.RecordOperationDuration("postgres_save_notification", status, duration)
+		metrics.RecordOperationDuration("postgres_find_notifications_by_group_id", status, duration)
 	}()
 
-	templateData, err := json.Marshal(notification.TemplateData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal template data: %w", err)
-	}
-
-	metadata, err := json.Marshal(notification.Metadata)
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
-	}
-
 	query := `
-		INSERT INTO notifications (
-			id, recipient, type, subject, content, status, priority,
-			template_id, template_type, template_data, metadata,
-			error_message, retry_count, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
-		)`
-
-	_, err = r.db.ExecContext(ctx, query,
-		notification.ID,
-		notification.Recipient,
-		notification.Type,
-		notification.Subject,
-		notification.Content,
-		notification.Status,
-		notification.Priority,
-		notification.TemplateID,
-		notification.TemplateType,
-		templateData,
-		metadata,
-		notification.ErrorMessage,
-		notification.RetryCount,
-		notification.CreatedAt,
-		notification.UpdatedAt,
-	)
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE tenant_id = $1 AND group_id = $2
+		ORDER BY created_at, id`
 
+	notifications, err := r.findPage(ctx, query, model.TenantFromContext(ctx), groupID)
 	if err != nil {
-		return fmt.Errorf("failed to save notification: %w", err)
+		return nil, err
 	}
 
-	return nil
+	return notifications, nil
 }
 
-// FindByID finds a notification by ID from PostgreSQL
-func (r *NotificationRepository) FindByID(ctx context.Context, id string) (*model.Notification, error) {
+// FindByThreadID finds the notifications in an email thread from PostgreSQL, oldest first
+func (r *NotificationRepository) FindByThreadID(ctx context.Context, threadID string) ([]*model.Notification, error) {
 	start := time.Now()
 	var err error
 	defer func() {
@@ -91,63 +649,29 @@ func (r *NotificationRepository) FindByID(ctx context.Context, id string) (*mode
 		if err != nil {
 			status = "error"
 		}
-		metrics.RecordOperationDuration("postgres_find_notification_by_id", status, duration)
+		metrics.RecordOperationDuration("postgres_find_notifications_by_thread_id", status, duration)
 	}()
 
-	// Convert string ID to UUID
-	uid, err := uuid.Parse(id)
-	if err != nil {
-		return nil, fmt.Errorf("invalid notification ID format: %w", err)
-	}
-
 	query := `
-		SELECT id, recipient, type, subject, content, status, priority,
-			   template_id, template_type, template_data, metadata,
-			   error_message, retry_count, created_at, updated_at
+		SELECT ` + notificationColumns + `
 		FROM notifications
-		WHERE id = $1`
-
-	var notification model.Notification
-	var templateData, metadata []byte
-
-	err = r.db.QueryRowContext(ctx, query, uid).Scan(
-		&notification.ID,
-		&notification.Recipient,
-		&notification.Type,
-		&notification.Subject,
-		&notification.Content,
-		&notification.Status,
-		&notification.Priority,
-		&notification.TemplateID,
-		&notification.TemplateType,
-		&templateData,
-		&metadata,
-		&notification.ErrorMessage,
-		&notification.RetryCount,
-		&notification.CreatedAt,
-		&notification.UpdatedAt,
-	)
+		WHERE tenant_id = $1 AND thread_id = $2
+		ORDER BY created_at, id`
 
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	notifications, err := r.findPage(ctx, query, model.TenantFromContext(ctx), threadID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find notification: %w", err)
-	}
-
-	if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal template data: %w", err)
-	}
-
-	if err := json.Unmarshal(metadata, &notification.Metadata); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		return nil, err
 	}
 
-	return &notification, nil
+	return notifications, nil
 }
 
-// FindByRecipient finds notifications by recipient from PostgreSQL with pagination
-func (r *NotificationRepository) FindByRecipient(ctx context.Context, recipient string, limit, offset int) ([]*model.Notification, error) {
+// ScanByRecipient streams a recipient's notifications created in [from, to)
+// from PostgreSQL, oldest first. Notifications are read a page at a time,
+// continuing after the last (created_at, id) seen rather than at an offset, so
+// each page is an index range scan however deep the export goes. No connection
+// is held while fn runs.
+func (r *NotificationRepository) ScanByRecipient(ctx context.Context, recipient string, from, to time.Time, fn func(*model.Notification) error) error {
 	start := time.Now()
 	var err error
 	defer func() {
@@ -156,19 +680,42 @@ func (r *NotificationRepository) FindByRecipient(ctx context.Context, recipient
 		if err != nil {
 			status = "error"
 		}
-		metrics.RecordOperationDuration("postgres_find_notifications_by_recipient", status, duration)
+		metrics.RecordOperationDuration("postgres_scan_notifications_by_recipient", status, duration)
 	}()
 
 	query := `
-		SELECT id, recipient, type, subject, content, status, priority,
-			   template_id, template_type, template_data, metadata,
-			   error_message, retry_count, created_at, updated_at
+		SELECT ` + notificationColumns + `
 		FROM notifications
-		WHERE recipient = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3`
+		WHERE tenant_id = $1 AND recipient = $2
+			AND created_at >= $3 AND created_at < $4
+			AND (created_at, id) > ($5, $6)
+		ORDER BY created_at, id
+		LIMIT $7`
+
+	afterCreatedAt, afterID := from, uuid.Nil
+	for {
+		var page []*model.Notification
+		page, err = r.findPage(ctx, query, model.TenantFromContext(ctx), recipient, from, to, afterCreatedAt, afterID, scanPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, notification := range page {
+			if err = fn(notification); err != nil {
+				return err
+			}
+		}
+		if len(page) < scanPageSize {
+			return nil
+		}
+		last := page[len(page)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+}
 
-	rows, err := r.db.QueryContext(ctx, query, recipient, limit, offset)
+// findPage runs a query selecting notificationColumns and returns the notifications it finds
+func (r *NotificationRepository) findPage(ctx context.Context, query string, args ...interface{}) ([]*model.Notification, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query notifications: %w", err)
 	}
@@ -176,39 +723,12 @@ func (r *NotificationRepository) FindByRecipient(ctx context.Context, recipient
 
 	var notifications []*model.Notification
 	for rows.Next() {
-		var notification model.Notification
-		var templateData, metadata []byte
-
-		err := rows.Scan(
-			&notification.ID,
-			&notification.Recipient,
-			&notification.Type,
-			&notification.Subject,
-			&notification.Content,
-			&notification.Status,
-			&notification.Priority,
-			&notification.TemplateID,
-			&notification.TemplateType,
-			&templateData,
-			&metadata,
-			&notification.ErrorMessage,
-			&notification.RetryCount,
-			&notification.CreatedAt,
-			&notification.UpdatedAt,
-		)
+		notification, err := scanNotification(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan notification: %w", err)
 		}
 
-		if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal template data: %w", err)
-		}
-
-		if err := json.Unmarshal(metadata, &notification.Metadata); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-
-		notifications = append(notifications, &notification)
+		notifications = append(notifications, notification)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -231,6 +751,12 @@ func (r *NotificationRepository) Update(ctx context.Context, notification *model
 		metrics.RecordOperationDuration("postgres_update_notification", status, duration)
 	}()
 
+	tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
+	if err != nil {
+		return err
+	}
+	notification.TenantID = tenantID
+
 	templateData, err := json.Marshal(notification.TemplateData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal template data: %w", err)
@@ -241,24 +767,7 @@ func (r *NotificationRepository) Update(ctx context.Context, notification *model
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	query := `
-		UPDATE notifications
-		SET recipient = $2,
-			type = $3,
-			subject = $4,
-			content = $5,
-			status = $6,
-			priority = $7,
-			template_id = $8,
-			template_type = $9,
-			template_data = $10,
-			metadata = $11,
-			error_message = $12,
-			retry_count = $13,
-			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1`
-
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.updateStmt.ExecContext(ctx,
 		notification.ID,
 		notification.Recipient,
 		notification.Type,
@@ -266,12 +775,19 @@ func (r *NotificationRepository) Update(ctx context.Context, notification *model
 		notification.Content,
 		notification.Status,
 		notification.Priority,
-		notification.TemplateID,
+		templateIDValue(notification.TemplateID),
 		notification.TemplateType,
 		templateData,
 		metadata,
 		notification.ErrorMessage,
 		notification.RetryCount,
+		notification.ProviderMessageID,
+		notification.TenantID,
+		notification.Category,
+		pq.Array(notification.Tags),
+		notification.Provider,
+		notification.NextRetryAt,
+		notification.EmailMessageID,
 	)
 
 	if err != nil {
@@ -291,7 +807,7 @@ func (r *NotificationRepository) Update(ctx context.Context, notification *model
 }
 
 // Delete deletes a notification from PostgreSQL
-func (r *NotificationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (r *NotificationRepository) Delete(ctx context.Context, id string) error {
 	start := time.Now()
 	var err error
 	defer func() {
@@ -303,9 +819,15 @@ func (r *NotificationRepository) Delete(ctx context.Context, id uuid.UUID) error
 		metrics.RecordOperationDuration("postgres_delete_notification", status, duration)
 	}()
 
-	query := `DELETE FROM notifications WHERE id = $1`
+	uid, parseErr := uuid.Parse(id)
+	if parseErr != nil {
+		err = model.ErrNotificationNotFound{ID: id}
+		return err
+	}
+
+	query := `DELETE FROM notifications WHERE id = $1 AND tenant_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, uid, model.TenantFromContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete notification: %w", err)
 	}
@@ -316,8 +838,277 @@ func (r *NotificationRepository) Delete(ctx context.Context, id uuid.UUID) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("notification not found: %s", id)
+		err = model.ErrNotificationNotFound{ID: id}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteOlderThan deletes the tenant's notifications created before cutoff
+// from PostgreSQL, purgeBatchSize rows per statement so no statement holds its
+// locks for long. Their outbox entries are deleted with them.
+func (r *NotificationRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_delete_notifications_older_than", status, duration)
+	}()
+
+	query := `
+		DELETE FROM notifications
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE tenant_id = $1 AND created_at < $2
+			LIMIT $3
+		)`
+
+	tenantID := model.TenantFromContext(ctx)
+	deleted := 0
+	for {
+		var result sql.Result
+		result, err = r.db.ExecContext(ctx, query, tenantID, cutoff, purgeBatchSize)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete notifications: %w", err)
+		}
+
+		var rowsAffected int64
+		if rowsAffected, err = result.RowsAffected(); err != nil {
+			return deleted, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		deleted += int(rowsAffected)
+		if rowsAffected < purgeBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// EraseRecipient anonymizes the tenant's notifications to recipient in
+// PostgreSQL, clearing the same fields model.Notification.Erase does
+func (r *NotificationRepository) EraseRecipient(ctx context.Context, recipient, erasedAs string) (int, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_erase_recipient", status, duration)
+	}()
+
+	query := `
+		UPDATE notifications
+		SET recipient = $3, subject = '', content = '', cc = NULL, inline_images = NULL, template_data = 'null',
+			metadata = 'null', error_message = '', updated_at = $4
+		WHERE tenant_id = $1 AND recipient = $2`
+
+	var result sql.Result
+	result, err = r.db.ExecContext(ctx, query, model.TenantFromContext(ctx), recipient, erasedAs, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to erase notifications: %w", err)
+	}
+
+	var rowsAffected int64
+	if rowsAffected, err = result.RowsAffected(); err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// insertNotification inserts a notification whose tenant has already been resolved
+func insertNotification(ctx context.Context, db execer, notification *model.Notification) error {
+	values, err := notificationValues(notification)
+	if err != nil {
+		return err
+	}
+
+	if _, err = db.ExecContext(ctx, insertNotificationQuery, values...); err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+
+	return nil
+}
+
+// notificationValues returns a notification's column values in notificationColumns order
+func notificationValues(notification *model.Notification) ([]interface{}, error) {
+	templateData, err := json.Marshal(notification.TemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template data: %w", err)
+	}
+
+	metadata, err := json.Marshal(notification.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	inlineImages, err := marshalInlineImages(notification.InlineImages)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		notification.ID,
+		notification.TenantID,
+		notification.Recipient,
+		notification.Type,
+		notification.Subject,
+		notification.Content,
+		notification.Status,
+		notification.Priority,
+		templateIDValue(notification.TemplateID),
+		notification.TemplateType,
+		templateData,
+		metadata,
+		notification.ErrorMessage,
+		notification.RetryCount,
+		notification.CreatedAt,
+		notification.UpdatedAt,
+		notification.ProviderMessageID,
+		notification.Category,
+		pq.Array(notification.Tags),
+		notification.CorrelationID,
+		notification.Provider,
+		notification.GroupID,
+		notification.ContentType,
+		pq.Array(notification.CC),
+		notification.NextRetryAt,
+		inlineImages,
+		notification.ThreadID,
+		notification.EmailMessageID,
+		notification.ScheduledAt,
+	}, nil
+}
+
+// templateIDValue binds a notification's template ID for the template_id
+// column, which is NULL for notifications sent without a template, such as
+// direct content and event fallbacks. The column references templates(id),
+// so the all-zero UUID would fail the insert.
+func templateIDValue(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+// marshalInlineImages encodes a notification's inline images for the
+// inline_images column, which is NULL when there are none
+func marshalInlineImages(images []model.InlineImage) (interface{}, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(images)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal inline images: %w", err)
+	}
+	return data, nil
+}
+
+// insertNotifications inserts notifications with a single multi-row INSERT
+func insertNotifications(ctx context.Context, db execer, notifications []*model.Notification) error {
+	var query strings.Builder
+	query.WriteString(`INSERT INTO notifications (` + notificationColumns + `) VALUES `)
+
+	args := make([]interface{}, 0, len(notifications)*notificationColumnCount)
+	for i, notification := range notifications {
+		values, err := notificationValues(notification)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(" + placeholders(len(args)+1, len(values)) + ")")
+
+		args = append(args, values...)
+	}
+
+	if _, err := db.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("failed to save notifications: %w", err)
 	}
 
 	return nil
 }
+
+// placeholders returns count comma-separated bind parameters numbered from first
+func placeholders(first, count int) string {
+	var list strings.Builder
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			list.WriteString(", ")
+		}
+		fmt.Fprintf(&list, "$%d", first+i)
+	}
+	return list.String()
+}
+
+// scanNotification scans a row selected with notificationColumns
+func scanNotification(row rowScanner) (*model.Notification, error) {
+	var notification model.Notification
+	var templateData, metadata, inlineImages []byte
+
+	err := row.Scan(
+		&notification.ID,
+		&notification.TenantID,
+		&notification.Recipient,
+		&notification.Type,
+		&notification.Subject,
+		&notification.Content,
+		&notification.Status,
+		&notification.Priority,
+		&notification.TemplateID,
+		&notification.TemplateType,
+		&templateData,
+		&metadata,
+		&notification.ErrorMessage,
+		&notification.RetryCount,
+		&notification.CreatedAt,
+		&notification.UpdatedAt,
+		&notification.ProviderMessageID,
+		&notification.Category,
+		pq.Array(&notification.Tags),
+		&notification.CorrelationID,
+		&notification.Provider,
+		&notification.GroupID,
+		&notification.ContentType,
+		pq.Array(&notification.CC),
+		&notification.NextRetryAt,
+		&inlineImages,
+		&notification.ThreadID,
+		&notification.EmailMessageID,
+		&notification.ScheduledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(templateData, &notification.TemplateData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template data: %w", err)
+	}
+
+	if err := json.Unmarshal(metadata, &notification.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	if inlineImages != nil {
+		if err := json.Unmarshal(inlineImages, &notification.InlineImages); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal inline images: %w", err)
+		}
+	}
+
+	return &notification, nil
+}
+
+// sortDirection returns the SQL direction listing in order sorts by
+func sortDirection(order model.SortOrder) string {
+	if order == model.SortAscending {
+		return "ASC"
+	}
+	return "DESC"
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// OutboxChannel is the channel the notification_outbox trigger announces new
+// entries on, with the time each becomes due as seconds since the epoch
+const OutboxChannel = "notification_outbox"
+
+const (
+	// outboxListenerMinReconnect and outboxListenerMaxReconnect bound the
+	// backoff between attempts to re-establish a dropped listener connection
+	outboxListenerMinReconnect = time.Second
+	outboxListenerMaxReconnect = time.Minute
+
+	// outboxListenerPingInterval is how often an idle listener checks its
+	// connection is still alive, so a silently dropped one is re-established
+	outboxListenerPingInterval = 90 * time.Second
+
+	// outboxWakeupBuffer is how many announcements are held for a busy
+	// dispatcher; later ones are dropped and left to its periodic poll
+	outboxWakeupBuffer = 100
+)
+
+// OutboxListener listens for the outbox entries the notification_outbox trigger
+// announces, over a connection of its own outside the pool
+type OutboxListener struct {
+	listener *pq.Listener
+	wakeups  chan time.Time
+	logger   *zap.Logger
+}
+
+// NewOutboxListener connects to the database connStr names and listens on
+// OutboxChannel. A dropped connection is re-established in the background.
+func NewOutboxListener(connStr string, logger *zap.Logger) (*OutboxListener, error) {
+	listener := pq.NewListener(connStr, outboxListenerMinReconnect, outboxListenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("outbox listener connection lost", zap.Error(err))
+		}
+	})
+	if err := listener.Listen(OutboxChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen for outbox entries: %w", err)
+	}
+
+	return &OutboxListener{
+		listener: listener,
+		wakeups:  make(chan time.Time, outboxWakeupBuffer),
+		logger:   logger,
+	}, nil
+}
+
+// Wakeups returns the times announced entries become due. The zero time is
+// sent after a dropped connection is re-established, since announcements made
+// while it was down were missed.
+func (l *OutboxListener) Wakeups() <-chan time.Time {
+	return l.wakeups
+}
+
+// Run forwards announcements to Wakeups until ctx is cancelled
+func (l *OutboxListener) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxListenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.listener.Ping(); err != nil {
+				l.logger.Warn("outbox listener ping failed", zap.Error(err))
+			}
+		case notification := <-l.listener.NotificationChannel():
+			var due time.Time
+			if notification != nil {
+				var err error
+				if due, err = parseOutboxAnnouncement(notification.Extra); err != nil {
+					l.logger.Warn("ignoring outbox announcement", zap.String("payload", notification.Extra), zap.Error(err))
+					continue
+				}
+			}
+			select {
+			case l.wakeups <- due:
+			default:
+			}
+		}
+	}
+}
+
+// Close stops listening and closes the listener's connection
+func (l *OutboxListener) Close() error {
+	return l.listener.Close()
+}
+
+// parseOutboxAnnouncement parses the due time the trigger announces an entry with
+func parseOutboxAnnouncement(payload string) (time.Time, error) {
+	seconds, err := strconv.ParseFloat(payload, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid due time: %w", err)
+	}
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(math.Round(fraction*1e6))*int64(time.Microsecond)), nil
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutboxAnnouncement(t *testing.T) {
+	due, err := parseOutboxAnnouncement("1737795600.123456")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2025, 1, 25, 9, 0, 0, 123456000, time.UTC).Equal(due), due)
+
+	due, err = parseOutboxAnnouncement("1737795600")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2025, 1, 25, 9, 0, 0, 0, time.UTC).Equal(due), due)
+
+	_, err = parseOutboxAnnouncement("soon")
+	assert.Error(t, err)
+}
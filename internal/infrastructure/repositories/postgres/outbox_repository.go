@@ -0,0 +1,251 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// OutboxRepository implements services.NotificationOutbox using PostgreSQL
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new PostgreSQL-based notification outbox
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// SaveAndEnqueue stores a notification and its outbox entry in one
+// transaction. The entry becomes claimable at the notification's ScheduledAt,
+// or right away if it has none.
+func (r *OutboxRepository) SaveAndEnqueue(ctx context.Context, notification *model.Notification) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_save_and_enqueue_notification", status, duration)
+	}()
+
+	tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
+	if err != nil {
+		return err
+	}
+	notification.TenantID = tenantID
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = insertNotification(ctx, tx, notification); err != nil {
+		return err
+	}
+
+	// Notifications deferred to their send window can't be claimed before it opens
+	query := `
+		INSERT INTO notification_outbox (tenant_id, notification_id, available_at)
+		VALUES ($1, $2, COALESCE($3, CURRENT_TIMESTAMP))`
+
+	if _, err = tx.ExecContext(ctx, query, notification.TenantID, notification.ID, notification.ScheduledAt); err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SaveAndEnqueueBatch stores notifications and their outbox entries in one
+// transaction with multi-row INSERTs. Like SaveAndEnqueue, each entry becomes
+// claimable at its notification's ScheduledAt, or right away if it has none.
+func (r *OutboxRepository) SaveAndEnqueueBatch(ctx context.Context, notifications []*model.Notification) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_save_and_enqueue_notification_batch", status, duration)
+	}()
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	for _, notification := range notifications {
+		tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
+		if err != nil {
+			return err
+		}
+		notification.TenantID = tenantID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for first := 0; first < len(notifications); first += maxBatchInsertRows {
+		last := first + maxBatchInsertRows
+		if last > len(notifications) {
+			last = len(notifications)
+		}
+		if err = insertNotifications(ctx, tx, notifications[first:last]); err != nil {
+			return err
+		}
+		if err = enqueueNotifications(ctx, tx, notifications[first:last]); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// enqueueNotifications adds the outbox entries of notifications with a single multi-row INSERT
+func enqueueNotifications(ctx context.Context, db execer, notifications []*model.Notification) error {
+	var query strings.Builder
+	query.WriteString(`INSERT INTO notification_outbox (tenant_id, notification_id, available_at) VALUES `)
+
+	args := make([]interface{}, 0, len(notifications)*3)
+	for i, notification := range notifications {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		fmt.Fprintf(&query, "($%d, $%d, COALESCE($%d, CURRENT_TIMESTAMP))", len(args)+1, len(args)+2, len(args)+3)
+		args = append(args, notification.TenantID, notification.ID, notification.ScheduledAt)
+	}
+
+	if _, err := db.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("failed to enqueue notifications: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPending leases up to limit pending entries, oldest first. Rows locked by
+// another dispatcher are skipped so several instances can drain the outbox concurrently.
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEntry, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_claim_outbox_entries", status, duration)
+	}()
+
+	query := `
+		UPDATE notification_outbox
+		SET available_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond',
+			attempts = attempts + 1
+		WHERE id IN (
+			SELECT id
+			FROM notification_outbox
+			WHERE status = 'pending' AND available_at <= CURRENT_TIMESTAMP
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, tenant_id, notification_id, attempts, COALESCE(last_error, ''), created_at, available_at`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.OutboxEntry
+	for rows.Next() {
+		var entry model.OutboxEntry
+		if err = rows.Scan(
+			&entry.ID,
+			&entry.TenantID,
+			&entry.NotificationID,
+			&entry.Attempts,
+			&entry.LastError,
+			&entry.CreatedAt,
+			&entry.AvailableAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// MarkDone removes an entry from the pending set
+func (r *OutboxRepository) MarkDone(ctx context.Context, id int64) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_mark_outbox_entry_done", status, duration)
+	}()
+
+	query := `
+		UPDATE notification_outbox
+		SET status = 'done', processed_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+
+	if _, err = r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox entry done: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records the reason an attempt failed; the entry is claimed again once its lease expires
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_mark_outbox_entry_failed", status, duration)
+	}()
+
+	query := `UPDATE notification_outbox SET last_error = $2 WHERE id = $1`
+
+	if _, err = r.db.ExecContext(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("failed to mark outbox entry failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepository_ClaimsEachDueEntryOnce(t *testing.T) {
+	db := testDatabase(t)
+	outbox := NewOutboxRepository(db)
+	ctx := model.ContextWithTenant(context.Background(), "claim-test")
+	t.Cleanup(func() { db.Exec(`DELETE FROM notifications WHERE tenant_id = 'claim-test'`) })
+
+	// The fixtures have no template, stored as a NULL template_id; see
+	// TestOutboxInserts_BindNoTemplateAsNULL
+	due := make(map[uuid.UUID]bool)
+	for i := 0; i < 20; i++ {
+		notification := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+		require.NoError(t, outbox.SaveAndEnqueue(ctx, notification))
+		due[notification.ID] = true
+	}
+	scheduled := model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{})
+	sendAt := time.Now().Add(time.Hour)
+	scheduled.ScheduledAt = &sendAt
+	require.NoError(t, outbox.SaveAndEnqueue(ctx, scheduled))
+
+	// Several dispatchers drain the outbox at once, a few entries at a time
+	var mu sync.Mutex
+	claims := make(map[uuid.UUID]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				entries, err := outbox.ClaimPending(context.Background(), 3, time.Minute)
+				if !assert.NoError(t, err) || len(entries) == 0 {
+					return
+				}
+				assert.LessOrEqual(t, len(entries), 3)
+
+				mu.Lock()
+				for _, entry := range entries {
+					claims[entry.NotificationID]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every due entry was claimed by exactly one of them, and the scheduled one by none
+	for id := range due {
+		assert.Equal(t, 1, claims[id], "claims of %s", id)
+	}
+	assert.Zero(t, claims[scheduled.ID])
+
+	// Claimed entries stay hidden until their lease expires
+	entries, err := outbox.ClaimPending(context.Background(), 100, time.Minute)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, due[entry.NotificationID])
+	}
+}
+
+// recordingExecer records the arguments of the statements it is given
+type recordingExecer struct {
+	args [][]interface{}
+}
+
+func (e *recordingExecer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.args = append(e.args, args)
+	return nil, nil
+}
+
+func TestOutboxInserts_BindNoTemplateAsNULL(t *testing.T) {
+	ctx := context.Background()
+	notifications := []*model.Notification{
+		model.NewNotification("user@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{}),
+		model.NewNotification("other@example.com", model.EmailNotification, model.EmailTemplate, uuid.Nil, model.TemplateData{}),
+	}
+
+	single := &recordingExecer{}
+	require.NoError(t, insertNotification(ctx, single, notifications[0]))
+	require.Len(t, single.args, 1)
+	assert.Nil(t, columnValue(t, single.args[0], "template_id"))
+
+	batch := &recordingExecer{}
+	require.NoError(t, insertNotifications(ctx, batch, notifications))
+	require.Len(t, batch.args, 1)
+	require.Len(t, batch.args[0], 2*notificationColumnCount)
+	for i := range notifications {
+		assert.Nil(t, columnValue(t, batch.args[0][i*notificationColumnCount:], "template_id"))
+	}
+}
@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+)
+
+// suppressionColumns lists the suppression columns in scan order
+const suppressionColumns = `tenant_id, recipient, reason, provider, detail, created_at`
+
+// SuppressionRepository implements repository.SuppressionRepository using PostgreSQL
+type SuppressionRepository struct {
+	db *sql.DB
+}
+
+// NewSuppressionRepository creates a new PostgreSQL-based suppression repository
+func NewSuppressionRepository(db *sql.DB) *SuppressionRepository {
+	return &SuppressionRepository{
+		db: db,
+	}
+}
+
+// Save adds a recipient to the suppression list in PostgreSQL, keeping an existing entry
+func (r *SuppressionRepository) Save(ctx context.Context, suppression *model.Suppression) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_save_suppression", status, duration)
+	}()
+
+	tenantID, err := model.ResolveTenantID(ctx, suppression.TenantID)
+	if err != nil {
+		return err
+	}
+	suppression.TenantID = tenantID
+	suppression.Recipient = model.NormalizeSuppressedRecipient(suppression.Recipient)
+
+	query := `
+		INSERT INTO suppressions (` + suppressionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, recipient) DO NOTHING`
+
+	_, err = r.db.ExecContext(ctx, query,
+		suppression.TenantID,
+		suppression.Recipient,
+		suppression.Reason,
+		suppression.Provider,
+		suppression.Detail,
+		suppression.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save suppression: %w", err)
+	}
+
+	return nil
+}
+
+// FindByRecipient finds a recipient's suppression in PostgreSQL
+func (r *SuppressionRepository) FindByRecipient(ctx context.Context, recipient string) (*model.Suppression, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_suppression_by_recipient", status, duration)
+	}()
+
+	query := `
+		SELECT ` + suppressionColumns + `
+		FROM suppressions
+		WHERE tenant_id = $1 AND recipient = $2`
+
+	suppression, err := scanSuppression(r.db.QueryRowContext(ctx, query, model.TenantFromContext(ctx), model.NormalizeSuppressedRecipient(recipient)))
+	if err == sql.ErrNoRows {
+		err = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find suppression: %w", err)
+	}
+
+	return suppression, nil
+}
+
+// List lists suppressions from PostgreSQL with pagination, newest first
+func (r *SuppressionRepository) List(ctx context.Context, limit, offset int) ([]*model.Suppression, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_list_suppressions", status, duration)
+	}()
+
+	query := `
+		SELECT ` + suppressionColumns + `
+		FROM suppressions
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suppressions: %w", err)
+	}
+	defer rows.Close()
+
+	var suppressions []*model.Suppression
+	for rows.Next() {
+		suppression, err := scanSuppression(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan suppression: %w", err)
+		}
+
+		suppressions = append(suppressions, suppression)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suppressions: %w", err)
+	}
+
+	return suppressions, nil
+}
+
+// Delete removes a recipient from the suppression list in PostgreSQL
+func (r *SuppressionRepository) Delete(ctx context.Context, recipient string) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_delete_suppression", status, duration)
+	}()
+
+	query := `DELETE FROM suppressions WHERE tenant_id = $1 AND recipient = $2`
+
+	result, err := r.db.ExecContext(ctx, query, model.TenantFromContext(ctx), model.NormalizeSuppressedRecipient(recipient))
+	if err != nil {
+		return fmt.Errorf("failed to delete suppression: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		err = model.ErrSuppressionNotFound{Recipient: recipient}
+		return err
+	}
+
+	return nil
+}
+
+func scanSuppression(row rowScanner) (*model.Suppression, error) {
+	var suppression model.Suppression
+	err := row.Scan(
+		&suppression.TenantID,
+		&suppression.Recipient,
+		&suppression.Reason,
+		&suppression.Provider,
+		&suppression.Detail,
+		&suppression.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &suppression, nil
+}
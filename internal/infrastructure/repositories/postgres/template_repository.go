@@ -4,14 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
 	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
 )
 
+// templateColumns lists the template columns in scan order
+const templateColumns = `
+			id, tenant_id, name, type, subject, content, variables, metadata,
+			version, is_active, created_at, updated_at`
+
+// uniqueViolation is the Postgres error code for a unique constraint violation
+const uniqueViolation = "23505"
+
 // TemplateRepository implements repository.TemplateRepository using PostgreSQL
 type TemplateRepository struct {
 	db *sql.DB
@@ -37,6 +47,12 @@ func (r *TemplateRepository) Save(ctx context.Context, template *model.Template)
 		metrics.RecordOperationDuration("postgres_save_template", status, duration)
 	}()
 
+	tenantID, err := model.ResolveTenantID(ctx, template.TenantID)
+	if err != nil {
+		return err
+	}
+	template.TenantID = tenantID
+
 	variables, err := json.Marshal(template.Variables)
 	if err != nil {
 		return fmt.Errorf("failed to marshal variables: %w", err)
@@ -48,15 +64,14 @@ func (r *TemplateRepository) Save(ctx context.Context, template *model.Template)
 	}
 
 	query := `
-		INSERT INTO templates (
-			id, name, type, subject, content, variables, metadata,
-			version, is_active, created_at, updated_at
+		INSERT INTO templates (` + templateColumns + `
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		)`
 
 	_, err = r.db.ExecContext(ctx, query,
 		template.ID,
+		template.TenantID,
 		template.Name,
 		template.Type,
 		template.Subject,
@@ -69,6 +84,10 @@ func (r *TemplateRepository) Save(ctx context.Context, template *model.Template)
 		template.UpdatedAt,
 	)
 
+	if isUniqueViolation(err) {
+		err = model.ErrTemplateNameTaken{Name: template.Name}
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("failed to save template: %w", err)
 	}
@@ -76,6 +95,83 @@ func (r *TemplateRepository) Save(ctx context.Context, template *model.Template)
 	return nil
 }
 
+// Upsert inserts a template into PostgreSQL or, if the tenant already has a
+// template with its name, updates that one in a single INSERT ... ON CONFLICT
+// statement. The version being replaced is copied to template_versions and the
+// stored version is incremented; whether the template is active is left alone,
+// so a deactivated template stays deactivated.
+func (r *TemplateRepository) Upsert(ctx context.Context, template *model.Template) (bool, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_upsert_template", status, duration)
+	}()
+
+	tenantID, err := model.ResolveTenantID(ctx, template.TenantID)
+	if err != nil {
+		return false, err
+	}
+	template.TenantID = tenantID
+
+	variables, err := json.Marshal(template.Variables)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal variables: %w", err)
+	}
+
+	metadata, err := json.Marshal(template.Metadata)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	// xmax is zero only for a row this statement inserted
+	query := `
+		WITH snapshot AS (
+			INSERT INTO template_versions (template_id, tenant_id, version, subject, content, variables, created_at)
+			SELECT id, tenant_id, version, subject, content, variables, updated_at
+			FROM templates
+			WHERE tenant_id = $2 AND name = $3
+		)
+		INSERT INTO templates (` + templateColumns + `
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+		ON CONFLICT (tenant_id, name) DO UPDATE
+		SET type = EXCLUDED.type,
+			subject = EXCLUDED.subject,
+			content = EXCLUDED.content,
+			variables = EXCLUDED.variables,
+			metadata = EXCLUDED.metadata,
+			version = templates.version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, version, is_active, created_at, updated_at, xmax = 0`
+
+	var created bool
+	err = r.db.QueryRowContext(ctx, query,
+		template.ID,
+		template.TenantID,
+		template.Name,
+		template.Type,
+		template.Subject,
+		template.Content,
+		variables,
+		metadata,
+		template.Version,
+		template.IsActive,
+		template.CreatedAt,
+		template.UpdatedAt,
+	).Scan(&template.ID, &template.Version, &template.IsActive, &template.CreatedAt, &template.UpdatedAt, &created)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert template: %w", err)
+	}
+
+	return created, nil
+}
+
 // FindByID finds a template by ID from PostgreSQL
 func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Template, error) {
 	start := time.Now()
@@ -90,28 +186,11 @@ func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model
 	}()
 
 	query := `
-		SELECT id, name, type, subject, content, variables, metadata,
-			   version, is_active, created_at, updated_at
+		SELECT ` + templateColumns + `
 		FROM templates
-		WHERE id = $1`
-
-	var template model.Template
-	var variables, metadata []byte
-
-	err = r.db.QueryRowContext(ctx, query, id).Scan(
-		&template.ID,
-		&template.Name,
-		&template.Type,
-		&template.Subject,
-		&template.Content,
-		&variables,
-		&metadata,
-		&template.Version,
-		&template.IsActive,
-		&template.CreatedAt,
-		&template.UpdatedAt,
-	)
+		WHERE id = $1 AND tenant_id = $2`
 
+	template, err := scanTemplate(r.db.QueryRowContext(ctx, query, id, model.TenantFromContext(ctx)))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -119,15 +198,38 @@ func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model
 		return nil, fmt.Errorf("failed to find template: %w", err)
 	}
 
-	if err := json.Unmarshal(variables, &template.Variables); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal variables: %w", err)
-	}
+	return template, nil
+}
 
-	if err := json.Unmarshal(metadata, &template.Metadata); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+// FindByName finds the active template with the given name from PostgreSQL
+func (r *TemplateRepository) FindByName(ctx context.Context, name string) (*model.Template, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_find_template_by_name", status, duration)
+	}()
+
+	query := `
+		SELECT ` + templateColumns + `
+		FROM templates
+		WHERE tenant_id = $1 AND name = $2 AND is_active = true
+		LIMIT 1`
+
+	template, err := scanTemplate(r.db.QueryRowContext(ctx, query, model.TenantFromContext(ctx), name))
+	if err == sql.ErrNoRows {
+		err = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find template: %w", err)
 	}
 
-	return &template, nil
+	return template, nil
 }
 
 // FindByType finds templates by type from PostgreSQL
@@ -144,13 +246,12 @@ func (r *TemplateRepository) FindByType(ctx context.Context, templateType model.
 	}()
 
 	query := `
-		SELECT id, name, type, subject, content, variables, metadata,
-			   version, is_active, created_at, updated_at
+		SELECT ` + templateColumns + `
 		FROM templates
-		WHERE type = $1
+		WHERE tenant_id = $1 AND type = $2
 		ORDER BY version DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, templateType)
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), templateType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query templates: %w", err)
 	}
@@ -158,35 +259,12 @@ func (r *TemplateRepository) FindByType(ctx context.Context, templateType model.
 
 	var templates []*model.Template
 	for rows.Next() {
-		var template model.Template
-		var variables, metadata []byte
-
-		err := rows.Scan(
-			&template.ID,
-			&template.Name,
-			&template.Type,
-			&template.Subject,
-			&template.Content,
-			&variables,
-			&metadata,
-			&template.Version,
-			&template.IsActive,
-			&template.CreatedAt,
-			&template.UpdatedAt,
-		)
+		template, err := scanTemplate(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan template: %w", err)
 		}
 
-		if err := json.Unmarshal(variables, &template.Variables); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal variables: %w", err)
-		}
-
-		if err := json.Unmarshal(metadata, &template.Metadata); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-
-		templates = append(templates, &template)
+		templates = append(templates, template)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -210,13 +288,12 @@ func (r *TemplateRepository) FindActiveByType(ctx context.Context, templateType
 	}()
 
 	query := `
-		SELECT id, name, type, subject, content, variables, metadata,
-			   version, is_active, created_at, updated_at
+		SELECT ` + templateColumns + `
 		FROM templates
-		WHERE type = $1 AND is_active = true
+		WHERE tenant_id = $1 AND type = $2 AND is_active = true
 		ORDER BY version DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, templateType)
+	rows, err := r.db.QueryContext(ctx, query, model.TenantFromContext(ctx), templateType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query templates: %w", err)
 	}
@@ -224,35 +301,12 @@ func (r *TemplateRepository) FindActiveByType(ctx context.Context, templateType
 
 	var templates []*model.Template
 	for rows.Next() {
-		var template model.Template
-		var variables, metadata []byte
-
-		err := rows.Scan(
-			&template.ID,
-			&template.Name,
-			&template.Type,
-			&template.Subject,
-			&template.Content,
-			&variables,
-			&metadata,
-			&template.Version,
-			&template.IsActive,
-			&template.CreatedAt,
-			&template.UpdatedAt,
-		)
+		template, err := scanTemplate(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan template: %w", err)
 		}
 
-		if err := json.Unmarshal(variables, &template.Variables); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal variables: %w", err)
-		}
-
-		if err := json.Unmarshal(metadata, &template.Metadata); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-
-		templates = append(templates, &template)
+		templates = append(templates, template)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -262,7 +316,8 @@ func (r *TemplateRepository) FindActiveByType(ctx context.Context, templateType
 	return templates, nil
 }
 
-// Update updates a template in PostgreSQL
+// Update updates a template in PostgreSQL. The version being replaced is copied
+// to template_versions and the stored version is incremented in the same transaction.
 func (r *TemplateRepository) Update(ctx context.Context, template *model.Template) error {
 	start := time.Now()
 	var err error
@@ -275,6 +330,12 @@ func (r *TemplateRepository) Update(ctx context.Context, template *model.Templat
 		metrics.RecordOperationDuration("postgres_update_template", status, duration)
 	}()
 
+	tenantID, err := model.ResolveTenantID(ctx, template.TenantID)
+	if err != nil {
+		return err
+	}
+	template.TenantID = tenantID
+
 	variables, err := json.Marshal(template.Variables)
 	if err != nil {
 		return fmt.Errorf("failed to marshal variables: %w", err)
@@ -285,6 +346,36 @@ func (r *TemplateRepository) Update(ctx context.Context, template *model.Templat
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the row so concurrent updates snapshot distinct versions
+	var current int
+	err = tx.QueryRowContext(ctx,
+		`SELECT version FROM templates WHERE id = $1 AND tenant_id = $2 FOR UPDATE`,
+		template.ID, template.TenantID,
+	).Scan(&current)
+	if err == sql.ErrNoRows {
+		err = model.ErrTemplateNotFound{ID: template.ID.String()}
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock template: %w", err)
+	}
+
+	snapshot := `
+		INSERT INTO template_versions (template_id, tenant_id, version, subject, content, variables, created_at)
+		SELECT id, tenant_id, version, subject, content, variables, updated_at
+		FROM templates
+		WHERE id = $1 AND tenant_id = $2`
+
+	if _, err = tx.ExecContext(ctx, snapshot, template.ID, template.TenantID); err != nil {
+		return fmt.Errorf("failed to snapshot template version: %w", err)
+	}
+
 	query := `
 		UPDATE templates
 		SET name = $2,
@@ -293,12 +384,13 @@ func (r *TemplateRepository) Update(ctx context.Context, template *model.Templat
 			content = $5,
 			variables = $6,
 			metadata = $7,
-			version = $8,
-			is_active = $9,
+			version = version + 1,
+			is_active = $8,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1`
+		WHERE id = $1 AND tenant_id = $9
+		RETURNING version, updated_at`
 
-	result, err := r.db.ExecContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		template.ID,
 		template.Name,
 		template.Type,
@@ -306,24 +398,152 @@ func (r *TemplateRepository) Update(ctx context.Context, template *model.Templat
 		template.Content,
 		variables,
 		metadata,
-		template.Version,
 		template.IsActive,
-	)
-
+		template.TenantID,
+	).Scan(&template.Version, &template.UpdatedAt)
+	if isUniqueViolation(err) {
+		err = model.ErrTemplateNameTaken{Name: template.Name}
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update template: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateVersions lists a template's previous versions from PostgreSQL, newest first
+func (r *TemplateRepository) GetTemplateVersions(ctx context.Context, id uuid.UUID) ([]*model.TemplateVersion, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_get_template_versions", status, duration)
+	}()
+
+	query := `
+		SELECT template_id, version, subject, content, variables, created_at
+		FROM template_versions
+		WHERE template_id = $1 AND tenant_id = $2
+		ORDER BY version DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, id, model.TenantFromContext(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to query template versions: %w", err)
 	}
+	defer rows.Close()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("template not found: %s", template.ID)
+	versions := []*model.TemplateVersion{}
+	for rows.Next() {
+		version, err := scanTemplateVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template version: %w", err)
+		}
+
+		versions = append(versions, version)
 	}
 
-	return nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating template versions: %w", err)
+	}
+
+	// A template that was never updated has no history; tell it apart from an unknown one
+	if len(versions) == 0 {
+		template, err := r.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if template == nil {
+			return nil, model.ErrTemplateNotFound{ID: id.String()}
+		}
+	}
+
+	return versions, nil
+}
+
+// RollbackTemplate restores a previous version's content in PostgreSQL. The
+// restored content is stored as a new version, so the rollback itself can be undone.
+func (r *TemplateRepository) RollbackTemplate(ctx context.Context, id uuid.UUID, version int) (*model.Template, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_rollback_template", status, duration)
+	}()
+
+	query := `
+		SELECT template_id, version, subject, content, variables, created_at
+		FROM template_versions
+		WHERE template_id = $1 AND tenant_id = $2 AND version = $3`
+
+	previous, err := scanTemplateVersion(r.db.QueryRowContext(ctx, query, id, model.TenantFromContext(ctx), version))
+	if err == sql.ErrNoRows {
+		err = model.ErrTemplateVersionNotFound{ID: id.String(), Version: version}
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find template version: %w", err)
+	}
+
+	template, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		err = model.ErrTemplateNotFound{ID: id.String()}
+		return nil, err
+	}
+
+	template.Restore(previous)
+	if err = r.Update(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// SetTemplateActive activates or deactivates a template in PostgreSQL as a new version
+func (r *TemplateRepository) SetTemplateActive(ctx context.Context, id uuid.UUID, active bool) (*model.Template, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("postgres_set_template_active", status, duration)
+	}()
+
+	template, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		err = model.ErrTemplateNotFound{ID: id.String()}
+		return nil, err
+	}
+
+	if err = template.SetActive(active); err != nil {
+		return nil, err
+	}
+	if err = r.Update(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
 }
 
 // Delete deletes a template from PostgreSQL
@@ -339,9 +559,9 @@ func (r *TemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		metrics.RecordOperationDuration("postgres_delete_template", status, duration)
 	}()
 
-	query := `DELETE FROM templates WHERE id = $1`
+	query := `DELETE FROM templates WHERE id = $1 AND tenant_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, model.TenantFromContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to delete template: %w", err)
 	}
@@ -352,73 +572,68 @@ func (r *TemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("template not found: %s", id)
+		err = model.ErrTemplateNotFound{ID: id.String()}
+		return err
 	}
 
 	return nil
 }
 
-// ProcessTemplate processes a template with given data
+// ProcessTemplate renders a template with given data, including the partial
+// templates it names from the same tenant; see model.Template.RenderWithIncludes
 func (r *TemplateRepository) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
 	// Find the template by name
-	template, err := r.findByName(ctx, templateName)
+	template, err := r.FindByName(ctx, templateName)
 	if err != nil {
 		return "", fmt.Errorf("failed to find template: %w", err)
 	}
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
 
-	// TODO: Implement actual template processing logic
-	// For now, return the raw content
-	return template.Content, nil
+	content, err := template.RenderWithIncludes(ctx, data, func(name string) (*model.Template, error) {
+		return r.FindByName(ctx, name)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error rendering template %s: %w", templateName, err)
+	}
+	return content, nil
 }
 
 // GetTemplate retrieves a template by name and locale
 func (r *TemplateRepository) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
-	template, err := r.findByName(ctx, templateName)
+	template, err := r.FindByName(ctx, templateName)
 	if err != nil {
 		return "", fmt.Errorf("failed to find template: %w", err)
 	}
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
 
 	return template.Content, nil
 }
 
-// findByName finds a template by name from PostgreSQL
-func (r *TemplateRepository) findByName(ctx context.Context, name string) (*model.Template, error) {
-	start := time.Now()
-	var err error
-	defer func() {
-		duration := time.Since(start).Seconds()
-		status := "success"
-		if err != nil {
-			status = "error"
-		}
-		metrics.RecordOperationDuration("postgres_find_template_by_name", status, duration)
-	}()
-
-	query := `
-		SELECT id, name, type, content, variables, metadata, is_active, created_at, updated_at
-		FROM templates
-		WHERE name = $1 AND is_active = true
-		LIMIT 1`
-
+// scanTemplate scans a row selected with templateColumns
+func scanTemplate(row rowScanner) (*model.Template, error) {
 	var template model.Template
 	var variables, metadata []byte
 
-	err = r.db.QueryRowContext(ctx, query, name).Scan(
+	err := row.Scan(
 		&template.ID,
+		&template.TenantID,
 		&template.Name,
 		&template.Type,
+		&template.Subject,
 		&template.Content,
 		&variables,
 		&metadata,
+		&template.Version,
 		&template.IsActive,
 		&template.CreatedAt,
 		&template.UpdatedAt,
 	)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("template not found: %s", name)
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan template: %w", err)
+		return nil, err
 	}
 
 	if err := json.Unmarshal(variables, &template.Variables); err != nil {
@@ -431,3 +646,33 @@ func (r *TemplateRepository) findByName(ctx context.Context, name string) (*mode
 
 	return &template, nil
 }
+
+// isUniqueViolation reports whether err is Postgres refusing a duplicate key
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// scanTemplateVersion scans a template_versions row
+func scanTemplateVersion(row rowScanner) (*model.TemplateVersion, error) {
+	var version model.TemplateVersion
+	var variables []byte
+
+	err := row.Scan(
+		&version.TemplateID,
+		&version.Version,
+		&version.Subject,
+		&version.Content,
+		&variables,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(variables, &version.Variables); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal variables: %w", err)
+	}
+
+	return &version, nil
+}
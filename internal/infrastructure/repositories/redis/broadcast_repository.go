@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const broadcastProgressPrefix = "broadcast:"
+
+// broadcastProgressKey builds the key holding a tenant's progress of a broadcast
+func (r *BroadcastProgressRepository) broadcastProgressKey(tenantID, broadcastID string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, broadcastProgressPrefix, tenantID, broadcastID)
+}
+
+// BroadcastProgressRepository implements repository.BroadcastProgressRepository
+// using Redis. Progress never expires.
+type BroadcastProgressRepository struct {
+	client    redis.UniversalClient
+	namespace string
+}
+
+// NewBroadcastProgressRepository creates a new Redis-based broadcast progress
+// repository whose keys are prefixed with namespace, see Namespace
+func NewBroadcastProgressRepository(client redis.UniversalClient, namespace string) *BroadcastProgressRepository {
+	return &BroadcastProgressRepository{
+		client:    client,
+		namespace: Namespace(namespace),
+	}
+}
+
+// FindBroadcastProgress finds a broadcast's progress in Redis
+func (r *BroadcastProgressRepository) FindBroadcastProgress(ctx context.Context, broadcastID string) (*model.BroadcastProgress, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_find_broadcast_progress", status, duration)
+	}()
+
+	data, err := r.client.Get(ctx, r.broadcastProgressKey(model.TenantFromContext(ctx), broadcastID)).Bytes()
+	if err == redis.Nil {
+		err = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast progress: %w", err)
+	}
+
+	var progress model.BroadcastProgress
+	if err = json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal broadcast progress: %w", err)
+	}
+
+	return &progress, nil
+}
+
+// SaveBroadcastProgress creates or replaces a broadcast's progress in Redis
+func (r *BroadcastProgressRepository) SaveBroadcastProgress(ctx context.Context, progress *model.BroadcastProgress) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_save_broadcast_progress", status, duration)
+	}()
+
+	tenantID, err := model.ResolveTenantID(ctx, progress.TenantID)
+	if err != nil {
+		return err
+	}
+	progress.TenantID = tenantID
+
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast progress: %w", err)
+	}
+
+	if err = r.client.Set(ctx, r.broadcastProgressKey(tenantID, progress.BroadcastID), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save broadcast progress: %w", err)
+	}
+
+	return nil
+}
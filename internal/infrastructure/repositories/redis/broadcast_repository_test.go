@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ repository.BroadcastProgressRepository = (*BroadcastProgressRepository)(nil)
+
+func TestBroadcastProgressRepository_SaveAndFind(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	repo := NewBroadcastProgressRepository(client, "")
+	ctx := context.Background()
+
+	found, err := repo.FindBroadcastProgress(ctx, "launch")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	progress := model.NewBroadcastProgress("launch")
+	progress.Advance("500", 499, 1)
+	require.NoError(t, repo.SaveBroadcastProgress(ctx, progress))
+	assert.Equal(t, model.DefaultTenantID, progress.TenantID)
+
+	progress.Advance("", 200, 0)
+	require.NoError(t, repo.SaveBroadcastProgress(ctx, progress))
+
+	found, err = repo.FindBroadcastProgress(ctx, "launch")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "", found.Cursor)
+	assert.Equal(t, 699, found.Enqueued)
+	assert.Equal(t, 1, found.Skipped)
+	assert.True(t, found.Completed)
+
+	// Progress is scoped to its tenant
+	found, err = repo.FindBroadcastProgress(model.ContextWithTenant(ctx, "acme"), "launch")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
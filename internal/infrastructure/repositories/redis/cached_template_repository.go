@@ -0,0 +1,243 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const templateCacheKeyPrefix = "template:cache:"
+
+// DefaultTemplateCacheTTL bounds how long a cached template may outlive a write
+// whose invalidation failed
+const DefaultTemplateCacheTTL = 5 * time.Minute
+
+// templateCacheIDKey builds the cache key of a tenant's template by ID
+func (r *CachedTemplateRepository) templateCacheIDKey(tenantID string, id uuid.UUID) string {
+	return fmt.Sprintf("%s%s%s:id:%s", r.namespace, templateCacheKeyPrefix, tenantID, id)
+}
+
+// templateCacheNameKey builds the cache key of a tenant's active template by name
+func (r *CachedTemplateRepository) templateCacheNameKey(tenantID, name string) string {
+	return fmt.Sprintf("%s%s%s:name:%s", r.namespace, templateCacheKeyPrefix, tenantID, name)
+}
+
+// CachedTemplateRepository caches single-template lookups of another
+// repository.TemplateRepository in Redis. Reads populate the cache and writes
+// invalidate it; list queries always go to the underlying repository.
+type CachedTemplateRepository struct {
+	repository.TemplateRepository
+	client    redis.UniversalClient
+	ttl       time.Duration
+	namespace string
+}
+
+// NewCachedTemplateRepository wraps a template repository with a Redis cache
+// whose keys are prefixed with namespace, see Namespace. A ttl of zero or less
+// uses DefaultTemplateCacheTTL.
+func NewCachedTemplateRepository(next repository.TemplateRepository, client redis.UniversalClient, ttl time.Duration, namespace string) *CachedTemplateRepository {
+	if ttl <= 0 {
+		ttl = DefaultTemplateCacheTTL
+	}
+	return &CachedTemplateRepository{
+		TemplateRepository: next,
+		client:             client,
+		ttl:                ttl,
+		namespace:          Namespace(namespace),
+	}
+}
+
+// FindByID finds a template by ID, serving it from the cache when possible
+func (r *CachedTemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Template, error) {
+	key := r.templateCacheIDKey(model.TenantFromContext(ctx), id)
+	if template, ok := r.get(ctx, key); ok {
+		return template, nil
+	}
+
+	template, err := r.TemplateRepository.FindByID(ctx, id)
+	if err != nil || template == nil {
+		return template, err
+	}
+
+	r.set(ctx, key, template)
+	return template, nil
+}
+
+// FindByName finds the active template with the given name, serving it from the cache when possible
+func (r *CachedTemplateRepository) FindByName(ctx context.Context, name string) (*model.Template, error) {
+	key := r.templateCacheNameKey(model.TenantFromContext(ctx), name)
+	if template, ok := r.get(ctx, key); ok {
+		return template, nil
+	}
+
+	template, err := r.TemplateRepository.FindByName(ctx, name)
+	if err != nil || template == nil {
+		return template, err
+	}
+
+	r.set(ctx, key, template)
+	return template, nil
+}
+
+// Save saves a template and invalidates any cached lookup of its name
+func (r *CachedTemplateRepository) Save(ctx context.Context, template *model.Template) error {
+	if err := r.TemplateRepository.Save(ctx, template); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, template.ID, template.Name)
+	return nil
+}
+
+// Upsert saves or updates a template by name and invalidates its cached lookups
+func (r *CachedTemplateRepository) Upsert(ctx context.Context, template *model.Template) (bool, error) {
+	created, err := r.TemplateRepository.Upsert(ctx, template)
+	if err != nil {
+		return false, err
+	}
+
+	r.invalidate(ctx, template.ID, template.Name)
+	return created, nil
+}
+
+// Update updates a template and invalidates its cached lookups under both its old and new name
+func (r *CachedTemplateRepository) Update(ctx context.Context, template *model.Template) error {
+	previous, err := r.TemplateRepository.FindByID(ctx, template.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.TemplateRepository.Update(ctx, template); err != nil {
+		return err
+	}
+
+	names := []string{template.Name}
+	if previous != nil && previous.Name != template.Name {
+		names = append(names, previous.Name)
+	}
+	r.invalidate(ctx, template.ID, names...)
+	return nil
+}
+
+// RollbackTemplate restores a previous template version and invalidates its cached lookups
+func (r *CachedTemplateRepository) RollbackTemplate(ctx context.Context, id uuid.UUID, version int) (*model.Template, error) {
+	template, err := r.TemplateRepository.RollbackTemplate(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx, id, template.Name)
+	return template, nil
+}
+
+// SetTemplateActive activates or deactivates a template and invalidates its cached lookups
+func (r *CachedTemplateRepository) SetTemplateActive(ctx context.Context, id uuid.UUID, active bool) (*model.Template, error) {
+	template, err := r.TemplateRepository.SetTemplateActive(ctx, id, active)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx, id, template.Name)
+	return template, nil
+}
+
+// Delete deletes a template and invalidates its cached lookups
+func (r *CachedTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	previous, err := r.TemplateRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.TemplateRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	var names []string
+	if previous != nil {
+		names = append(names, previous.Name)
+	}
+	r.invalidate(ctx, id, names...)
+	return nil
+}
+
+// ProcessTemplate renders a template with given data, including the partial
+// templates it names from the same tenant; see model.Template.RenderWithIncludes
+func (r *CachedTemplateRepository) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	// Find the template by name
+	template, err := r.FindByName(ctx, templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find template: %w", err)
+	}
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
+
+	content, err := template.RenderWithIncludes(ctx, data, func(name string) (*model.Template, error) {
+		return r.FindByName(ctx, name)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error rendering template %s: %w", templateName, err)
+	}
+	return content, nil
+}
+
+// GetTemplate retrieves a template by name and locale
+func (r *CachedTemplateRepository) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	template, err := r.FindByName(ctx, templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find template: %w", err)
+	}
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
+
+	return template.Content, nil
+}
+
+// get reads a cached template. Cache errors are treated as misses so an
+// unavailable cache only costs a trip to the underlying repository.
+func (r *CachedTemplateRepository) get(ctx context.Context, key string) (*model.Template, bool) {
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		metrics.RecordCacheMiss()
+		return nil, false
+	}
+
+	var template model.Template
+	if err := json.Unmarshal(data, &template); err != nil {
+		metrics.RecordCacheMiss()
+		return nil, false
+	}
+
+	metrics.RecordCacheHit()
+	return &template, true
+}
+
+// set caches a template; failures are ignored since the entry can be rebuilt
+func (r *CachedTemplateRepository) set(ctx context.Context, key string, template *model.Template) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return
+	}
+	r.client.Set(ctx, key, data, r.ttl)
+}
+
+// invalidate drops a template's cached lookups. The write has already succeeded,
+// so a failure here is not reported; the TTL bounds how long stale entries live.
+// Each key is deleted on its own so the keys needn't share a Redis Cluster slot.
+func (r *CachedTemplateRepository) invalidate(ctx context.Context, id uuid.UUID, names ...string) {
+	tenantID := model.TenantFromContext(ctx)
+	pipe := r.client.Pipeline()
+	pipe.Del(ctx, r.templateCacheIDKey(tenantID, id))
+	for _, name := range names {
+		pipe.Del(ctx, r.templateCacheNameKey(tenantID, name))
+	}
+	pipe.Exec(ctx)
+}
@@ -0,0 +1,264 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTemplateRepository is a mock implementation of repository.TemplateRepository
+type MockTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockTemplateRepository) Save(ctx context.Context, template *model.Template) error {
+	return m.Called(ctx, template).Error(0)
+}
+
+func (m *MockTemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Template, error) {
+	args := m.Called(ctx, id)
+	template, _ := args.Get(0).(*model.Template)
+	return template, args.Error(1)
+}
+
+func (m *MockTemplateRepository) FindByName(ctx context.Context, name string) (*model.Template, error) {
+	args := m.Called(ctx, name)
+	template, _ := args.Get(0).(*model.Template)
+	return template, args.Error(1)
+}
+
+func (m *MockTemplateRepository) FindByType(ctx context.Context, templateType model.TemplateType) ([]*model.Template, error) {
+	args := m.Called(ctx, templateType)
+	templates, _ := args.Get(0).([]*model.Template)
+	return templates, args.Error(1)
+}
+
+func (m *MockTemplateRepository) FindActiveByType(ctx context.Context, templateType model.TemplateType) ([]*model.Template, error) {
+	args := m.Called(ctx, templateType)
+	templates, _ := args.Get(0).([]*model.Template)
+	return templates, args.Error(1)
+}
+
+func (m *MockTemplateRepository) Upsert(ctx context.Context, template *model.Template) (bool, error) {
+	args := m.Called(ctx, template)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTemplateRepository) Update(ctx context.Context, template *model.Template) error {
+	return m.Called(ctx, template).Error(0)
+}
+
+func (m *MockTemplateRepository) GetTemplateVersions(ctx context.Context, id uuid.UUID) ([]*model.TemplateVersion, error) {
+	args := m.Called(ctx, id)
+	versions, _ := args.Get(0).([]*model.TemplateVersion)
+	return versions, args.Error(1)
+}
+
+func (m *MockTemplateRepository) RollbackTemplate(ctx context.Context, id uuid.UUID, version int) (*model.Template, error) {
+	args := m.Called(ctx, id, version)
+	template, _ := args.Get(0).(*model.Template)
+	return template, args.Error(1)
+}
+
+func (m *MockTemplateRepository) SetTemplateActive(ctx context.Context, id uuid.UUID, active bool) (*model.Template, error) {
+	args := m.Called(ctx, id, active)
+	template, _ := args.Get(0).(*model.Template)
+	return template, args.Error(1)
+}
+
+func (m *MockTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+var _ repository.TemplateRepository = (*CachedTemplateRepository)(nil)
+
+func setupCachedTemplateRepo(t *testing.T) (*CachedTemplateRepository, *MockTemplateRepository, *miniredis.Miniredis, func()) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	next := new(MockTemplateRepository)
+	repo := NewCachedTemplateRepository(next, client, time.Minute, "")
+
+	cleanup := func() {
+		client.Close()
+		mr.Close()
+	}
+
+	return repo, next, mr, cleanup
+}
+
+func TestCachedTemplateRepository_FindByIDServesSecondReadFromCache(t *testing.T) {
+	repo, next, _, cleanup := setupCachedTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	next.On("FindByID", mock.Anything, template.ID).Return(template, nil).Once()
+
+	first, err := repo.FindByID(ctx, template.ID)
+	require.NoError(t, err)
+	second, err := repo.FindByID(ctx, template.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, template.Content, first.Content)
+	assert.Equal(t, template.Content, second.Content)
+	next.AssertNumberOfCalls(t, "FindByID", 1)
+}
+
+func TestCachedTemplateRepository_GetTemplateServesSecondReadFromCache(t *testing.T) {
+	repo, next, _, cleanup := setupCachedTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	next.On("FindByName", mock.Anything, "welcome").Return(template, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		content, err := repo.GetTemplate(ctx, "welcome", "en")
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", content)
+	}
+	next.AssertNumberOfCalls(t, "FindByName", 1)
+
+	// Cache entries are scoped to the tenant
+	next.On("FindByName", mock.Anything, "welcome").Return(nil, nil).Once()
+	_, err := repo.GetTemplate(model.ContextWithTenant(ctx, "other"), "welcome", "en")
+	assert.ErrorAs(t, err, &model.ErrTemplateNotFound{})
+	next.AssertNumberOfCalls(t, "FindByName", 2)
+}
+
+func TestCachedTemplateRepository_UpdateInvalidates(t *testing.T) {
+	repo, next, _, cleanup := setupCachedTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	original := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	renamed := *original
+	renamed.Name = "greeting"
+	renamed.Content = "Hello v2"
+
+	next.On("FindByID", mock.Anything, original.ID).Return(original, nil).Once()
+	next.On("FindByName", mock.Anything, "welcome").Return(original, nil).Once()
+	_, err := repo.FindByID(ctx, original.ID)
+	require.NoError(t, err)
+	_, err = repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+
+	// Update reads the stored template to find its old name
+	next.On("FindByID", mock.Anything, original.ID).Return(original, nil).Once()
+	next.On("Update", mock.Anything, &renamed).Return(nil).Once()
+	require.NoError(t, repo.Update(ctx, &renamed))
+
+	// Both the ID and the old name are read from the repository again
+	next.On("FindByID", mock.Anything, original.ID).Return(&renamed, nil).Once()
+	next.On("FindByName", mock.Anything, "welcome").Return(nil, nil).Once()
+	found, err := repo.FindByID(ctx, original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello v2", found.Content)
+	found, err = repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	next.AssertExpectations(t)
+}
+
+func TestCachedTemplateRepository_UpsertInvalidates(t *testing.T) {
+	repo, next, _, cleanup := setupCachedTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	original := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	next.On("FindByName", mock.Anything, "welcome").Return(original, nil).Once()
+	_, err := repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+
+	updated := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v2")
+	next.On("Upsert", mock.Anything, updated).Return(false, nil).Once()
+	created, err := repo.Upsert(ctx, updated)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// The name is read from the repository again
+	next.On("FindByName", mock.Anything, "welcome").Return(updated, nil).Once()
+	found, err := repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello v2", found.Content)
+
+	next.AssertExpectations(t)
+}
+
+func TestCachedTemplateRepository_SetTemplateActiveInvalidates(t *testing.T) {
+	repo, next, _, cleanup := setupCachedTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	original := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	deactivated := *original
+	deactivated.IsActive = false
+	deactivated.Version = 2
+
+	next.On("FindByName", mock.Anything, "welcome").Return(original, nil).Once()
+	_, err := repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+
+	next.On("SetTemplateActive", mock.Anything, original.ID, false).Return(&deactivated, nil).Once()
+	_, err = repo.SetTemplateActive(ctx, original.ID, false)
+	require.NoError(t, err)
+
+	// The deactivated template is no longer served from the cache
+	next.On("FindByName", mock.Anything, "welcome").Return(nil, nil).Once()
+	found, err := repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	next.AssertExpectations(t)
+}
+
+func TestCachedTemplateRepository_DeleteInvalidates(t *testing.T) {
+	repo, next, mr, cleanup := setupCachedTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	next.On("FindByID", mock.Anything, template.ID).Return(template, nil).Once()
+	_, err := repo.FindByID(ctx, template.ID)
+	require.NoError(t, err)
+	assert.True(t, mr.Exists(repo.templateCacheIDKey(model.DefaultTenantID, template.ID)))
+
+	next.On("FindByID", mock.Anything, template.ID).Return(template, nil).Once()
+	next.On("Delete", mock.Anything, template.ID).Return(nil).Once()
+	require.NoError(t, repo.Delete(ctx, template.ID))
+
+	assert.False(t, mr.Exists(repo.templateCacheIDKey(model.DefaultTenantID, template.ID)))
+	next.AssertExpectations(t)
+}
+
+func TestCachedTemplateRepository_EntriesExpire(t *testing.T) {
+	repo, next, mr, cleanup := setupCachedTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	next.On("FindByID", mock.Anything, template.ID).Return(template, nil)
+
+	_, err := repo.FindByID(ctx, template.ID)
+	require.NoError(t, err)
+	mr.FastForward(2 * time.Minute)
+	_, err = repo.FindByID(ctx, template.ID)
+	require.NoError(t, err)
+
+	next.AssertNumberOfCalls(t, "FindByID", 2)
+}
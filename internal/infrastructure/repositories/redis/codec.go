@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// gzipMagic opens every gzip stream. A serialized notification never starts
+// with it, so it marks compressed values and lets uncompressed ones be read as is.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compress gzips data if it is at least threshold bytes long. A threshold of
+// zero or less disables compression.
+func compress(data []byte, threshold int) ([]byte, error) {
+	if threshold <= 0 || len(data) < threshold {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("error compressing payload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error compressing payload: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress reverses compress, returning values stored uncompressed unchanged
+func decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing payload: %w", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing payload: %w", err)
+	}
+
+	return decompressed, nil
+}
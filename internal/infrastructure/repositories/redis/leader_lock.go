@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const leaderLockKey = "leader"
+
+// acquireLeaderLock sets the lock to the caller's token unless another token
+// holds it, extending it if the caller already does
+var acquireLeaderLock = redis.NewScript(`
+local holder = redis.call("GET", KEYS[1])
+if holder == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if holder then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1`)
+
+// renewLeaderLock extends the lock if the caller's token holds it
+var renewLeaderLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseLeaderLock deletes the lock if the caller's token holds it
+var releaseLeaderLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// LeaderLock implements services.LeaderLock with a Redis key holding a token
+// unique to this instance, which expires unless renewed. Every instance
+// sharing the namespace campaigns for the same lock.
+type LeaderLock struct {
+	client redis.UniversalClient
+	key    string
+	token  string
+}
+
+// NewLeaderLock creates a leader lock whose key is prefixed with namespace, see Namespace
+func NewLeaderLock(client redis.UniversalClient, namespace string) *LeaderLock {
+	return &LeaderLock{
+		client: client,
+		key:    Namespace(namespace) + leaderLockKey,
+		token:  uuid.NewString(),
+	}
+}
+
+// Acquire takes the lock for ttl if it is free or already held by this instance
+func (l *LeaderLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	acquired, err := acquireLeaderLock.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire leader lock: %w", err)
+	}
+	return acquired == 1, nil
+}
+
+// Renew extends the lock by ttl if this instance still holds it
+func (l *LeaderLock) Renew(ctx context.Context, ttl time.Duration) (bool, error) {
+	renewed, err := renewLeaderLock.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew leader lock: %w", err)
+	}
+	return renewed == 1, nil
+}
+
+// Release deletes the lock if this instance holds it
+func (l *LeaderLock) Release(ctx context.Context) error {
+	if err := releaseLeaderLock.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to release leader lock: %w", err)
+	}
+	return nil
+}
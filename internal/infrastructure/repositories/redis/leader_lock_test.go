@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ services.LeaderLock = (*LeaderLock)(nil)
+
+func TestLeaderLock_AcquireAndExpire(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	first := NewLeaderLock(client, "prod")
+	second := NewLeaderLock(client, "prod")
+	ctx := context.Background()
+
+	// Only one instance holds the lock at a time
+	acquired, err := first.Acquire(ctx, 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.True(t, mr.Exists("prod:leader"))
+
+	acquired, err = second.Acquire(ctx, 15*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// The holder can acquire again and renew; renewing extends the lock
+	acquired, err = first.Acquire(ctx, 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	mr.FastForward(10 * time.Second)
+	renewed, err := first.Renew(ctx, 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	mr.FastForward(10 * time.Second)
+	acquired, err = second.Acquire(ctx, 15*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// Once the holder stops renewing, the lock expires and another takes over
+	mr.FastForward(6 * time.Second)
+	acquired, err = second.Acquire(ctx, 15*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	renewed, err = first.Renew(ctx, 15*time.Second)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+
+	// Only the holder's release frees the lock
+	require.NoError(t, first.Release(ctx))
+	assert.True(t, mr.Exists("prod:leader"))
+	require.NoError(t, second.Release(ctx))
+	assert.False(t, mr.Exists("prod:leader"))
+}
@@ -2,8 +2,10 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
@@ -14,69 +16,356 @@ import (
 
 const (
 	// Key prefixes
-	notificationPrefix = "notification:"
-	recipientPrefix   = "recipient:"
-	
+	notificationPrefix      = "notification:"
+	recipientPrefix         = "recipient:"
+	statusPrefix            = "status:"
+	recipientStatusPrefix   = "recipient_status:"
+	recipientPriorityPrefix = "recipient_priority:"
+	providerMessagePrefix   = "provider_message:"
+	categoryPrefix          = "category:"
+	correlationPrefix       = "correlation:"
+	groupPrefix             = "group:"
+	threadPrefix            = "thread:"
+
 	// Default expiration for notifications (30 days)
 	defaultExpiration = 30 * 24 * time.Hour
+
+	// DefaultPurgeBatchSize is how many notifications DeleteOlderThan removes per round trip by default
+	DefaultPurgeBatchSize = 500
+
+	// priorityBandWidth separates the priority bands of priority index scores.
+	// Creation times in Unix seconds stay below it until the year 2286.
+	priorityBandWidth = 1e10
 )
 
+// ErrPriorityIndexDisabled is returned when notifications are queried by
+// priority but the repository doesn't maintain the priority index
+var ErrPriorityIndexDisabled = errors.New("the priority index is disabled")
+
+// priorityBands ranks priorities in the priority index, higher first
+var priorityBands = map[model.Priority]float64{
+	model.PriorityLow:    0,
+	model.PriorityMedium: 1,
+	model.PriorityHigh:   2,
+}
+
+// priorityScore scores a notification in the priority index. The priority
+// band is the score's high part and the creation time in Unix seconds its low
+// part, so a descending range returns high priority notifications first,
+// newest first within a priority. Scores stay below 2^53, so they are exact.
+func priorityScore(notification *model.Notification) float64 {
+	return priorityBands[notification.Priority]*priorityBandWidth + float64(notification.CreatedAt.Unix())
+}
+
+// notificationKey builds the key holding a tenant's notification data
+func (r *NotificationRepository) notificationKey(tenantID, id string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, notificationPrefix, tenantID, id)
+}
+
+// recipientKey builds the key of a tenant's per-recipient notification index
+func (r *NotificationRepository) recipientKey(tenantID, recipient string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, recipientPrefix, tenantID, recipient)
+}
+
+// statusKey builds the key of a tenant's per-status notification index
+func (r *NotificationRepository) statusKey(tenantID string, status model.NotificationStatus) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, statusPrefix, tenantID, status)
+}
+
+// recipientStatusKey builds the key of a tenant's per-recipient, per-status notification index.
+//
+// FindByRecipientAndStatus could instead filter the recipient index in Go, or
+// intersect it with the tenant-wide status index, without this extra index.
+// Both cost time proportional to the recipient's whole history on every page,
+// which grows without bound for an inbox that is mostly read. A dedicated
+// index costs one more ZADD/ZREM per status change but pages in O(log N).
+func (r *NotificationRepository) recipientStatusKey(tenantID, recipient string, status model.NotificationStatus) string {
+	return fmt.Sprintf("%s%s%s:%s:%s", r.namespace, recipientStatusPrefix, tenantID, status, recipient)
+}
+
+// recipientPriorityKey builds the key of a tenant's per-recipient notification
+// index scored by priority, see priorityScore. It is kept apart from the
+// recipient index so the inbox listing stays ordered by time alone.
+func (r *NotificationRepository) recipientPriorityKey(tenantID, recipient string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, recipientPriorityPrefix, tenantID, recipient)
+}
+
+// categoryKey builds the key of a tenant's per-category notification index
+func (r *NotificationRepository) categoryKey(tenantID, category string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, categoryPrefix, tenantID, category)
+}
+
+// correlationKey builds the key of a tenant's per-correlation ID notification index
+func (r *NotificationRepository) correlationKey(tenantID, correlationID string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, correlationPrefix, tenantID, correlationID)
+}
+
+// groupKey builds the key of a tenant's index of a multi-channel send's notifications
+func (r *NotificationRepository) groupKey(tenantID, groupID string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, groupPrefix, tenantID, groupID)
+}
+
+// threadKey builds the key of a tenant's index of an email thread's notifications
+func (r *NotificationRepository) threadKey(tenantID, threadID string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, threadPrefix, tenantID, threadID)
+}
+
+// providerMessageKey builds the key mapping a provider's message ID to its
+// notification. A provider's message IDs are unique across tenants, so the key
+// is global and its value is "tenantID:notificationID". Message IDs of
+// providers that don't report a name are keyed by the ID alone.
+func (r *NotificationRepository) providerMessageKey(provider, messageID string) string {
+	if provider == "" {
+		return r.namespace + providerMessagePrefix + messageID
+	}
+	return r.namespace + providerMessagePrefix + provider + ":" + messageID
+}
+
+// NotificationRepositoryConfig controls how notifications are stored in Redis
+type NotificationRepositoryConfig struct {
+	Serializer           Serializer // Format new values are written in; existing values are read in whichever format they were written
+	CompressionThreshold int        // Serialized size in bytes from which payloads are gzipped; zero disables compression
+	Namespace            string     // Prefix put in front of every key, see Namespace
+	PurgeBatchSize       int        // How many notifications DeleteOlderThan removes per round trip; zero uses DefaultPurgeBatchSize
+	PriorityIndex        bool       // Also index each recipient's notifications by priority, see FindByRecipientByPriority
+}
+
+// DefaultNotificationRepositoryConfig returns a NotificationRepositoryConfig that stores payloads as uncompressed JSON
+func DefaultNotificationRepositoryConfig() NotificationRepositoryConfig {
+	return NotificationRepositoryConfig{
+		Serializer:           JSONSerializer{},
+		CompressionThreshold: 0,
+	}
+}
+
 // NotificationRepository implements repository interface using Redis
 type NotificationRepository struct {
-	client *redis.Client
-	logger *zap.Logger
+	client    redis.UniversalClient
+	config    NotificationRepositoryConfig
+	namespace string
+	logger    *zap.Logger
 }
 
 // NewNotificationRepository creates a new Redis-based notification repository
-func NewNotificationRepository(client *redis.Client, logger *zap.Logger) *NotificationRepository {
+func NewNotificationRepository(client redis.UniversalClient, config NotificationRepositoryConfig, logger *zap.Logger) *NotificationRepository {
 	// Set initial connection status
 	metrics.SetRedisConnectionStatus(true)
 
+	if config.Serializer == nil {
+		config.Serializer = JSONSerializer{}
+	}
+	if config.PurgeBatchSize <= 0 {
+		config.PurgeBatchSize = DefaultPurgeBatchSize
+	}
+
 	return &NotificationRepository{
-		client: client,
-		logger: logger,
+		client:    client,
+		config:    config,
+		namespace: Namespace(config.Namespace),
+		logger:    logger,
 	}
 }
 
+// encode serializes a notification for storage, compressing it if it reaches the configured threshold
+func (r *NotificationRepository) encode(notification *model.Notification) ([]byte, error) {
+	data, err := r.config.Serializer.Marshal(notification)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling notification: %w", err)
+	}
+
+	return compress(data, r.config.CompressionThreshold)
+}
+
+// decodeNotification reads a stored notification in any supported format, compressed or not
+func decodeNotification(data []byte) (*model.Notification, error) {
+	data, err := decompress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var notification model.Notification
+	if err := detectSerializer(data).Unmarshal(data, &notification); err != nil {
+		return nil, fmt.Errorf("error unmarshaling notification: %w", err)
+	}
+
+	return &notification, nil
+}
+
 // Save stores a notification in Redis
 func (r *NotificationRepository) Save(ctx context.Context, notification *model.Notification) error {
 	start := time.Now()
 	operation := "save"
 
-	// Marshal notification to JSON
-	data, err := json.Marshal(notification)
+	tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
 	if err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
-		return fmt.Errorf("error marshaling notification: %w", err)
+		return err
 	}
+	notification.TenantID = tenantID
 
-	// Update storage size metric
+	// Serialize notification, compressing large payloads
+	data, err := r.encode(notification)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return err
+	}
+
+	// Update storage size metric with the bytes actually stored
 	metrics.UpdateNotificationStorageSize(string(notification.Type), float64(len(data)))
 
 	// Create pipeline for atomic operations
 	pipe := r.client.Pipeline()
+	r.queueSave(ctx, pipe, tenantID, notification, data)
+
+	// Execute pipeline
+	if _, err := pipe.Exec(ctx); err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return fmt.Errorf("error saving notification: %w", err)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return nil
+}
 
+// SaveBatch stores notifications in Redis with a single pipeline. Redis doesn't
+// apply the batch atomically, so failures are reported per notification with model.ErrBatchSave.
+func (r *NotificationRepository) SaveBatch(ctx context.Context, notifications []*model.Notification) error {
+	start := time.Now()
+	operation := "save_batch"
+
+	errs := make([]error, len(notifications))
+	// ranges[i] holds the positions of notification i's commands in the pipeline
+	ranges := make([][2]int, len(notifications))
+	pipe := r.client.Pipeline()
+
+	for i, notification := range notifications {
+		tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		notification.TenantID = tenantID
+
+		data, err := r.encode(notification)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		metrics.UpdateNotificationStorageSize(string(notification.Type), float64(len(data)))
+		ranges[i][0] = pipe.Len()
+		r.queueSave(ctx, pipe, tenantID, notification, data)
+		ranges[i][1] = pipe.Len()
+	}
+
+	// Exec reports only the first failure; each command's own error is inspected below
+	var cmds []redis.Cmder
+	if queued := pipe.Len(); queued > 0 {
+		var err error
+		if cmds, err = pipe.Exec(ctx); err != nil && len(cmds) != queued {
+			metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+			return fmt.Errorf("error saving notifications: %w", err)
+		}
+	}
+
+	failed := false
+	for i := range notifications {
+		if errs[i] == nil {
+			for _, cmd := range cmds[ranges[i][0]:ranges[i][1]] {
+				if err := cmd.Err(); err != nil {
+					errs[i] = fmt.Errorf("error saving notification: %w", err)
+					break
+				}
+			}
+		}
+		if errs[i] != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return model.ErrBatchSave{Errors: errs}
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return nil
+}
+
+// queueSave queues the commands storing a notification and indexing it by
+// recipient, status, category, correlation ID and group, and by priority if enabled
+func (r *NotificationRepository) queueSave(ctx context.Context, pipe redis.Pipeliner, tenantID string, notification *model.Notification, data []byte) {
 	// Store notification data
-	notificationKey := fmt.Sprintf("%s%s", notificationPrefix, notification.ID)
-	pipe.Set(ctx, notificationKey, data, defaultExpiration)
+	pipe.Set(ctx, r.notificationKey(tenantID, notification.ID.String()), data, defaultExpiration)
 
 	// Add to recipient's notification list
-	recipientKey := fmt.Sprintf("%s%s", recipientPrefix, notification.Recipient)
-	pipe.ZAdd(ctx, recipientKey, redis.Z{
+	indexKey := r.recipientKey(tenantID, notification.Recipient)
+	pipe.ZAdd(ctx, indexKey, redis.Z{
 		Score:  float64(notification.CreatedAt.Unix()),
 		Member: notification.ID.String(),
 	})
-	pipe.Expire(ctx, recipientKey, defaultExpiration)
+	pipe.Expire(ctx, indexKey, defaultExpiration)
 
-	// Execute pipeline
-	if _, err := pipe.Exec(ctx); err != nil {
-		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
-		return fmt.Errorf("error saving notification: %w", err)
+	// Add to recipient's priority index
+	if r.config.PriorityIndex {
+		priorityKey := r.recipientPriorityKey(tenantID, notification.Recipient)
+		pipe.ZAdd(ctx, priorityKey, redis.Z{
+			Score:  priorityScore(notification),
+			Member: notification.ID.String(),
+		})
+		pipe.Expire(ctx, priorityKey, defaultExpiration)
 	}
 
-	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
-	metrics.UpdateNotificationStatus(string(notification.Status), 1)
-	return nil
+	// Add to the category index
+	if notification.Category != "" {
+		categoryKey := r.categoryKey(tenantID, notification.Category)
+		pipe.ZAdd(ctx, categoryKey, redis.Z{
+			Score:  float64(notification.CreatedAt.Unix()),
+			Member: notification.ID.String(),
+		})
+		pipe.Expire(ctx, categoryKey, defaultExpiration)
+	}
+
+	// Add to the correlation index
+	if notification.CorrelationID != "" {
+		correlationKey := r.correlationKey(tenantID, notification.CorrelationID)
+		pipe.ZAdd(ctx, correlationKey, redis.Z{
+			Score:  float64(notification.CreatedAt.Unix()),
+			Member: notification.ID.String(),
+		})
+		pipe.Expire(ctx, correlationKey, defaultExpiration)
+	}
+
+	// Add to the group index
+	if notification.GroupID != "" {
+		groupKey := r.groupKey(tenantID, notification.GroupID)
+		pipe.ZAdd(ctx, groupKey, redis.Z{
+			Score:  float64(notification.CreatedAt.Unix()),
+			Member: notification.ID.String(),
+		})
+		pipe.Expire(ctx, groupKey, defaultExpiration)
+	}
+
+	// Add to the thread index
+	if notification.ThreadID != "" {
+		threadKey := r.threadKey(tenantID, notification.ThreadID)
+		pipe.ZAdd(ctx, threadKey, redis.Z{
+			Score:  float64(notification.CreatedAt.Unix()),
+			Member: notification.ID.String(),
+		})
+		pipe.Expire(ctx, threadKey, defaultExpiration)
+	}
+
+	// Add to the status index
+	r.indexStatus(ctx, pipe, tenantID, notification)
+	r.indexProviderMessage(ctx, pipe, tenantID, notification)
+}
+
+// indexProviderMessage maps the notification's provider message ID, once it has one, to the notification
+func (r *NotificationRepository) indexProviderMessage(ctx context.Context, pipe redis.Pipeliner, tenantID string, notification *model.Notification) {
+	if notification.ProviderMessageID != "" {
+		pipe.Set(ctx, r.providerMessageKey(notification.Provider, notification.ProviderMessageID), tenantID+":"+notification.ID.String(), defaultExpiration)
+	}
 }
 
 // FindByID retrieves a notification by ID
@@ -84,7 +373,7 @@ func (r *NotificationRepository) FindByID(ctx context.Context, id string) (*mode
 	start := time.Now()
 	operation := "find_by_id"
 
-	key := fmt.Sprintf("%s%s", notificationPrefix, id)
+	key := r.notificationKey(model.TenantFromContext(ctx), id)
 	data, err := r.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
@@ -98,24 +387,88 @@ func (r *NotificationRepository) FindByID(ctx context.Context, id string) (*mode
 
 	metrics.RecordCacheHit()
 
-	var notification model.Notification
-	if err := json.Unmarshal(data, &notification); err != nil {
+	notification, err := decodeNotification(data)
+	if err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
-		return nil, fmt.Errorf("error unmarshaling notification: %w", err)
+		return nil, err
 	}
 
 	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
-	return &notification, nil
+	return notification, nil
 }
 
-// FindByRecipient retrieves notifications for a recipient with pagination
-func (r *NotificationRepository) FindByRecipient(ctx context.Context, recipient string, limit, offset int) ([]*model.Notification, error) {
+// FindByProviderMessageID retrieves a notification by the provider that sent it
+// and its provider message ID, in whichever tenant it belongs to. Notifications
+// without a provider are only matched if no notification of provider has messageID.
+func (r *NotificationRepository) FindByProviderMessageID(ctx context.Context, provider, messageID string) (*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_provider_message_id"
+
+	mapped, err := r.client.Get(ctx, r.providerMessageKey(provider, messageID)).Result()
+	if err == redis.Nil && provider != "" {
+		mapped, err = r.client.Get(ctx, r.providerMessageKey("", messageID)).Result()
+	}
+	if err == redis.Nil {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return nil, nil
+	}
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("error retrieving provider message mapping: %w", err)
+	}
+
+	tenantID, id, ok := strings.Cut(mapped, ":")
+	if !ok {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("malformed provider message mapping for %s: %q", messageID, mapped)
+	}
+
+	// The notification may have expired before its mapping
+	notification, err := r.FindByID(model.ContextWithTenant(ctx, tenantID), id)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notification, nil
+}
+
+// FindByIDs retrieves the notifications with the given IDs in a single round trip.
+// IDs that don't exist are omitted from the result.
+func (r *NotificationRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_ids"
+
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, _, err := r.loadNotifications(ctx, model.TenantFromContext(ctx), ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByRecipient retrieves notifications for a recipient with pagination, in
+// order of creation
+func (r *NotificationRepository) FindByRecipient(ctx context.Context, recipient string, order model.SortOrder, limit, offset int) ([]*model.Notification, error) {
 	start := time.Now()
 	operation := "find_by_recipient"
 
-	// Get notification IDs from sorted set
-	recipientKey := fmt.Sprintf("%s%s", recipientPrefix, recipient)
-	ids, err := r.client.ZRevRange(ctx, recipientKey, int64(offset), int64(offset+limit-1)).Result()
+	// Get notification IDs from sorted set, which is scored by creation time
+	tenantID := model.TenantFromContext(ctx)
+	key := r.recipientKey(tenantID, recipient)
+	page := r.client.ZRevRange
+	if order == model.SortAscending {
+		page = r.client.ZRange
+	}
+	ids, err := page(ctx, key, int64(offset), int64(offset+limit-1)).Result()
 	if err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
 		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
@@ -126,52 +479,358 @@ func (r *NotificationRepository) FindByRecipient(ctx context.Context, recipient
 		return []*model.Notification{}, nil
 	}
 
-	// Create pipeline for batch retrieval
-	pipe := r.client.Pipeline()
-	cmds := make(map[string]*redis.StringCmd)
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, recipient, missing)
 
-	for _, id := range ids {
-		key := fmt.Sprintf("%s%s", notificationPrefix, id)
-		cmds[id] = pipe.Get(ctx, key)
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByRecipientByPriority retrieves notifications for a recipient with
+// pagination, high priority first and newest first within a priority. It
+// requires the PriorityIndex option and returns ErrPriorityIndexDisabled
+// without it; notifications saved before the option was enabled aren't listed.
+func (r *NotificationRepository) FindByRecipientByPriority(ctx context.Context, recipient string, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_recipient_by_priority"
+
+	if !r.config.PriorityIndex {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, ErrPriorityIndexDisabled
 	}
 
-	// Execute pipeline
-	if _, err := pipe.Exec(ctx); err != nil {
+	tenantID := model.TenantFromContext(ctx)
+	ids, err := r.client.ZRevRange(ctx, r.recipientPriorityKey(tenantID, recipient), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
-		return nil, fmt.Errorf("error retrieving notifications: %w", err)
+		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
 	}
 
-	// Process results
-	notifications := make([]*model.Notification, 0, len(ids))
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, recipient, missing)
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByStatus retrieves notifications in the given status with pagination, newest first
+func (r *NotificationRepository) FindByStatus(ctx context.Context, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_status"
+
+	tenantID := model.TenantFromContext(ctx)
+	ids, err := r.client.ZRevRange(ctx, r.statusKey(tenantID, status), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
+	}
+
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, "", missing)
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByRecipientAndStatus retrieves a recipient's notifications in a given status, newest first
+func (r *NotificationRepository) FindByRecipientAndStatus(ctx context.Context, recipient string, status model.NotificationStatus, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_recipient_and_status"
+
+	tenantID := model.TenantFromContext(ctx)
+	ids, err := r.client.ZRevRange(ctx, r.recipientStatusKey(tenantID, recipient, status), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
+	}
+
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, recipient, missing)
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByCategory retrieves notifications in a category with pagination, newest first
+func (r *NotificationRepository) FindByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_category"
+
+	tenantID := model.TenantFromContext(ctx)
+	indexKey := r.categoryKey(tenantID, category)
+	ids, err := r.client.ZRevRange(ctx, indexKey, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
+	}
+
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, "", missing)
+	if len(missing) > 0 {
+		members := make([]interface{}, 0, len(missing))
+		for _, id := range missing {
+			members = append(members, id)
+		}
+		r.client.ZRem(ctx, indexKey, members...)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByCorrelationID retrieves the notifications produced by the request or
+// event with the given correlation ID with pagination, oldest first
+func (r *NotificationRepository) FindByCorrelationID(ctx context.Context, correlationID string, limit, offset int) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_correlation_id"
+
+	tenantID := model.TenantFromContext(ctx)
+	indexKey := r.correlationKey(tenantID, correlationID)
+	ids, err := r.client.ZRange(ctx, indexKey, int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
+	}
+
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, "", missing)
+	if len(missing) > 0 {
+		members := make([]interface{}, 0, len(missing))
+		for _, id := range missing {
+			members = append(members, id)
+		}
+		r.client.ZRem(ctx, indexKey, members...)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByGroupID retrieves the notifications sent together as a multi-channel
+// notification, oldest first
+func (r *NotificationRepository) FindByGroupID(ctx context.Context, groupID string) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_group_id"
+
+	tenantID := model.TenantFromContext(ctx)
+	indexKey := r.groupKey(tenantID, groupID)
+	ids, err := r.client.ZRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
+	}
+
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, "", missing)
+	if len(missing) > 0 {
+		members := make([]interface{}, 0, len(missing))
+		for _, id := range missing {
+			members = append(members, id)
+		}
+		r.client.ZRem(ctx, indexKey, members...)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// FindByThreadID retrieves the notifications in an email thread, oldest first
+func (r *NotificationRepository) FindByThreadID(ctx context.Context, threadID string) ([]*model.Notification, error) {
+	start := time.Now()
+	operation := "find_by_thread_id"
+
+	tenantID := model.TenantFromContext(ctx)
+	indexKey := r.threadKey(tenantID, threadID)
+	ids, err := r.client.ZRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, fmt.Errorf("error retrieving notification IDs: %w", err)
+	}
+
+	if len(ids) == 0 {
+		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
+		return []*model.Notification{}, nil
+	}
+
+	notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return nil, err
+	}
+	r.pruneIndexes(ctx, tenantID, "", missing)
+	if len(missing) > 0 {
+		members := make([]interface{}, 0, len(missing))
+		for _, id := range missing {
+			members = append(members, id)
+		}
+		r.client.ZRem(ctx, indexKey, members...)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return notifications, nil
+}
+
+// loadNotifications fetches a tenant's notifications by ID in one round trip. IDs whose
+// notification no longer exists, typically because its key expired, are returned as missing.
+func (r *NotificationRepository) loadNotifications(ctx context.Context, tenantID string, ids []string) ([]*model.Notification, []string, error) {
+	keys := make([]string, 0, len(ids))
 	for _, id := range ids {
-		data, err := cmds[id].Bytes()
-		if err != nil {
-			if err != redis.Nil {
-				r.logger.Error("error retrieving notification",
-					zap.Error(err),
-					zap.String("id", id),
-				)
-			}
+		keys = append(keys, r.notificationKey(tenantID, id))
+	}
+
+	values, err := getMany(ctx, r.client, keys)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving notifications: %w", err)
+	}
+
+	notifications := make([]*model.Notification, 0, len(ids))
+	var missing []string
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
 			metrics.RecordCacheMiss()
+			missing = append(missing, ids[i])
 			continue
 		}
 
 		metrics.RecordCacheHit()
 
-		var notification model.Notification
-		if err := json.Unmarshal(data, &notification); err != nil {
+		notification, err := decodeNotification([]byte(data))
+		if err != nil {
 			r.logger.Error("error unmarshaling notification",
 				zap.Error(err),
-				zap.String("id", id),
+				zap.String("id", ids[i]),
 			)
 			continue
 		}
 
-		notifications = append(notifications, &notification)
+		notifications = append(notifications, notification)
 	}
 
-	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
-	return notifications, nil
+	return notifications, missing, nil
+}
+
+// pruneIndexes removes IDs whose notification has expired from the tenant's
+// status indexes and, if recipient is set, from that recipient's indexes.
+// Index entries don't expire with the notification key, so without this they
+// would resolve to misses until the whole index expires. Pruning is best
+// effort: a failure is logged and retried on the next read.
+func (r *NotificationRepository) pruneIndexes(ctx context.Context, tenantID, recipient string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, id)
+	}
+
+	pipe := r.client.Pipeline()
+	for _, status := range model.NotificationStatuses {
+		pipe.ZRem(ctx, r.statusKey(tenantID, status), members...)
+		if recipient != "" {
+			pipe.ZRem(ctx, r.recipientStatusKey(tenantID, recipient, status), members...)
+		}
+	}
+	if recipient != "" {
+		pipe.ZRem(ctx, r.recipientKey(tenantID, recipient), members...)
+		r.unindexPriority(ctx, pipe, tenantID, recipient, members)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.Warn("error pruning expired notifications from indexes",
+			zap.Error(err),
+			zap.Int("count", len(ids)),
+		)
+	}
+}
+
+// unindexPriority removes members from the recipient's priority index, if it is maintained
+func (r *NotificationRepository) unindexPriority(ctx context.Context, pipe redis.Pipeliner, tenantID, recipient string, members []interface{}) {
+	if r.config.PriorityIndex {
+		pipe.ZRem(ctx, r.recipientPriorityKey(tenantID, recipient), members...)
+	}
+}
+
+// indexStatus moves a notification into the tenant-wide and per-recipient indexes for its current status
+func (r *NotificationRepository) indexStatus(ctx context.Context, pipe redis.Pipeliner, tenantID string, notification *model.Notification) {
+	id := notification.ID.String()
+	for _, status := range model.NotificationStatuses {
+		if status != notification.Status {
+			pipe.ZRem(ctx, r.statusKey(tenantID, status), id)
+			pipe.ZRem(ctx, r.recipientStatusKey(tenantID, notification.Recipient, status), id)
+		}
+	}
+
+	member := redis.Z{
+		Score:  float64(notification.CreatedAt.Unix()),
+		Member: id,
+	}
+	for _, indexKey := range []string{
+		r.statusKey(tenantID, notification.Status),
+		r.recipientStatusKey(tenantID, notification.Recipient, notification.Status),
+	} {
+		pipe.ZAdd(ctx, indexKey, member)
+		pipe.Expire(ctx, indexKey, defaultExpiration)
+	}
 }
 
 // Update updates an existing notification
@@ -179,8 +838,15 @@ func (r *NotificationRepository) Update(ctx context.Context, notification *model
 	start := time.Now()
 	operation := "update"
 
+	tenantID, err := model.ResolveTenantID(ctx, notification.TenantID)
+	if err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return err
+	}
+	notification.TenantID = tenantID
+
 	// Check if notification exists
-	key := fmt.Sprintf("%s%s", notificationPrefix, notification.ID)
+	key := r.notificationKey(tenantID, notification.ID.String())
 	exists, err := r.client.Exists(ctx, key).Result()
 	if err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
@@ -192,24 +858,28 @@ func (r *NotificationRepository) Update(ctx context.Context, notification *model
 	}
 
 	// Update notification
-	data, err := json.Marshal(notification)
+	data, err := r.encode(notification)
 	if err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
-		return fmt.Errorf("error marshaling notification: %w", err)
+		return err
 	}
 
-	if err := r.client.Set(ctx, key, data, defaultExpiration).Err(); err != nil {
+	pipe := r.client.Pipeline()
+	pipe.Set(ctx, key, data, defaultExpiration)
+	r.indexStatus(ctx, pipe, tenantID, notification)
+	r.indexProviderMessage(ctx, pipe, tenantID, notification)
+
+	if _, err := pipe.Exec(ctx); err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
 		return fmt.Errorf("error updating notification: %w", err)
 	}
 
 	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
-	metrics.UpdateNotificationStatus(string(notification.Status), 1)
 	return nil
 }
 
-// DeleteByID deletes a notification by ID
-func (r *NotificationRepository) DeleteByID(ctx context.Context, id string) error {
+// Delete deletes a notification by ID
+func (r *NotificationRepository) Delete(ctx context.Context, id string) error {
 	start := time.Now()
 	operation := "delete"
 
@@ -220,27 +890,193 @@ func (r *NotificationRepository) DeleteByID(ctx context.Context, id string) erro
 	}
 	if notification == nil {
 		metrics.RecordOperationDuration(operation, "not_found", time.Since(start).Seconds())
-		return nil // Already deleted
+		return model.ErrNotificationNotFound{ID: id}
 	}
 
 	pipe := r.client.Pipeline()
+	r.queueDelete(ctx, pipe, notification)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+		return fmt.Errorf("error deleting notification: %w", err)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return nil
+}
+
+// queueDelete queues the commands removing a notification and its index entries
+func (r *NotificationRepository) queueDelete(ctx context.Context, pipe redis.Pipeliner, notification *model.Notification) {
+	id := notification.ID.String()
 
 	// Remove notification data
-	notificationKey := fmt.Sprintf("%s%s", notificationPrefix, id)
-	pipe.Del(ctx, notificationKey)
+	pipe.Del(ctx, r.notificationKey(notification.TenantID, id))
 
 	// Remove from recipient's list
-	recipientKey := fmt.Sprintf("%s%s", recipientPrefix, notification.Recipient)
-	pipe.ZRem(ctx, recipientKey, id)
+	pipe.ZRem(ctx, r.recipientKey(notification.TenantID, notification.Recipient), id)
+	r.unindexPriority(ctx, pipe, notification.TenantID, notification.Recipient, []interface{}{id})
 
-	if _, err := pipe.Exec(ctx); err != nil {
+	// Remove from the status indexes
+	pipe.ZRem(ctx, r.statusKey(notification.TenantID, notification.Status), id)
+	pipe.ZRem(ctx, r.recipientStatusKey(notification.TenantID, notification.Recipient, notification.Status), id)
+
+	if notification.Category != "" {
+		pipe.ZRem(ctx, r.categoryKey(notification.TenantID, notification.Category), id)
+	}
+
+	if notification.CorrelationID != "" {
+		pipe.ZRem(ctx, r.correlationKey(notification.TenantID, notification.CorrelationID), id)
+	}
+
+	if notification.GroupID != "" {
+		pipe.ZRem(ctx, r.groupKey(notification.TenantID, notification.GroupID), id)
+	}
+
+	if notification.ThreadID != "" {
+		pipe.ZRem(ctx, r.threadKey(notification.TenantID, notification.ThreadID), id)
+	}
+
+	if notification.ProviderMessageID != "" {
+		pipe.Del(ctx, r.providerMessageKey(notification.Provider, notification.ProviderMessageID))
+	}
+}
+
+// DeleteOlderThan deletes the tenant's notifications created before cutoff. Every
+// notification is in its recipient's index, so the recipient indexes are walked
+// and each is emptied of old entries PurgeBatchSize notifications at a time,
+// removing the notifications and their entries in the other indexes with them.
+func (r *NotificationRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	start := time.Now()
+	operation := "delete_older_than"
+
+	tenantID := model.TenantFromContext(ctx)
+	indexPrefix := r.recipientKey(tenantID, "")
+	deleted := 0
+	err := scanKeys(ctx, r.client, indexPrefix+"*", func(indexKey string) error {
+		n, err := r.purgeRecipient(ctx, tenantID, strings.TrimPrefix(indexKey, indexPrefix), cutoff)
+		deleted += n
+		return err
+	})
+	if err != nil {
 		metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
-		return fmt.Errorf("error deleting notification: %w", err)
+		return deleted, fmt.Errorf("error purging notifications: %w", err)
 	}
 
 	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
-	metrics.UpdateNotificationStatus(string(notification.Status), -1)
-	return nil
+	return deleted, nil
+}
+
+// purgeRecipient deletes a recipient's notifications created before cutoff in
+// batches and returns how many it deleted. Index scores are creation times in
+// whole seconds, so only entries scored below cutoff's second are certainly older.
+func (r *NotificationRepository) purgeRecipient(ctx context.Context, tenantID, recipient string, cutoff time.Time) (int, error) {
+	indexKey := r.recipientKey(tenantID, recipient)
+	olderThan := &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   "(" + strconv.FormatInt(cutoff.Unix(), 10),
+		Count: int64(r.config.PurgeBatchSize),
+	}
+
+	deleted := 0
+	for {
+		ids, err := r.client.ZRangeByScore(ctx, indexKey, olderThan).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("error retrieving notification IDs: %w", err)
+		}
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+
+		notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+		if err != nil {
+			return deleted, err
+		}
+
+		pipe := r.client.Pipeline()
+		members := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			// Also drops notifications that couldn't be decoded
+			pipe.Del(ctx, r.notificationKey(tenantID, id))
+			members = append(members, id)
+		}
+		for _, notification := range notifications {
+			r.queueDelete(ctx, pipe, notification)
+		}
+		pipe.ZRem(ctx, indexKey, members...)
+		r.unindexPriority(ctx, pipe, tenantID, recipient, members)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return deleted, fmt.Errorf("error deleting notifications: %w", err)
+		}
+		r.pruneIndexes(ctx, tenantID, recipient, missing)
+
+		deleted += len(ids) - len(missing)
+		if len(ids) < r.config.PurgeBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// EraseRecipient anonymizes the tenant's notifications to recipient,
+// PurgeBatchSize notifications at a time. Erased notifications are stored and
+// indexed under erasedAs, and taken out of the recipient's indexes.
+func (r *NotificationRepository) EraseRecipient(ctx context.Context, recipient, erasedAs string) (int, error) {
+	start := time.Now()
+	operation := "erase_recipient"
+
+	tenantID := model.TenantFromContext(ctx)
+	indexKey := r.recipientKey(tenantID, recipient)
+	erased := 0
+	for {
+		ids, err := r.client.ZRange(ctx, indexKey, 0, int64(r.config.PurgeBatchSize-1)).Result()
+		if err != nil {
+			metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+			return erased, fmt.Errorf("error retrieving notification IDs: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		notifications, missing, err := r.loadNotifications(ctx, tenantID, ids)
+		if err != nil {
+			metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+			return erased, err
+		}
+
+		pipe := r.client.Pipeline()
+		loaded := make(map[string]bool, len(notifications))
+		for _, notification := range notifications {
+			id := notification.ID.String()
+			loaded[id] = true
+			pipe.ZRem(ctx, r.recipientStatusKey(tenantID, notification.Recipient, notification.Status), id)
+
+			notification.Erase(erasedAs)
+			data, err := r.encode(notification)
+			if err != nil {
+				metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+				return erased, err
+			}
+			r.queueSave(ctx, pipe, tenantID, notification, data)
+		}
+		members := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			// Notifications that couldn't be decoded can't be anonymized, so they're deleted
+			if !loaded[id] {
+				pipe.Del(ctx, r.notificationKey(tenantID, id))
+			}
+			members = append(members, id)
+		}
+		pipe.ZRem(ctx, indexKey, members...)
+		r.unindexPriority(ctx, pipe, tenantID, recipient, members)
+		if _, err := pipe.Exec(ctx); err != nil {
+			metrics.RecordOperationDuration(operation, "error", time.Since(start).Seconds())
+			return erased, fmt.Errorf("error erasing notifications: %w", err)
+		}
+		r.pruneIndexes(ctx, tenantID, recipient, missing)
+		erased += len(notifications)
+	}
+
+	metrics.RecordOperationDuration(operation, "success", time.Since(start).Seconds())
+	return erased, nil
 }
 
 // monitorRedisConnection periodically checks Redis connection status
@@ -1,25 +1,41 @@
 package redis
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
+var (
+	_ repository.NotificationRepository     = (*NotificationRepository)(nil)
+	_ repository.NotificationCategoryFinder = (*NotificationRepository)(nil)
+	_ repository.NotificationPurger         = (*NotificationRepository)(nil)
+	_ repository.NotificationThreadFinder   = (*NotificationRepository)(nil)
+)
+
 type redisMock struct {
 	*redis.Client
 	commands map[string]func(args ...interface{}) (interface{}, error)
 }
 
 func setupTestRepo(t *testing.T) (*NotificationRepository, func()) {
+	repo, _, cleanup := setupTestRepoWithConfig(t, DefaultNotificationRepositoryConfig())
+	return repo, cleanup
+}
+
+func setupTestRepoWithConfig(t *testing.T, config NotificationRepositoryConfig) (*NotificationRepository, *miniredis.Miniredis, func()) {
 	// Create a miniredis server
 	mr, err := miniredis.Run()
 	require.NoError(t, err)
@@ -30,14 +46,14 @@ func setupTestRepo(t *testing.T) (*NotificationRepository, func()) {
 	})
 
 	logger, _ := zap.NewDevelopment()
-	repo := NewNotificationRepository(client, logger)
+	repo := NewNotificationRepository(client, config, logger)
 
 	cleanup := func() {
 		client.Close()
 		mr.Close()
 	}
 
-	return repo, cleanup
+	return repo, mr, cleanup
 }
 
 func createTestNotification(recipient string) *model.Notification {
@@ -46,7 +62,7 @@ func createTestNotification(recipient string) *model.Notification {
 		model.EmailNotification,
 		model.EmailTemplate,
 		uuid.New(),
-		map[string]string{
+		model.TemplateData{
 			"testKey": "testValue",
 		},
 	)
@@ -76,6 +92,135 @@ func TestNotificationRepository_Save(t *testing.T) {
 	}
 }
 
+func TestNotificationRepository_SaveBatch(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	notifications := []*model.Notification{
+		createTestNotification("first@example.com"),
+		createTestNotification("second@example.com"),
+		createTestNotification("first@example.com"),
+	}
+
+	// Test SaveBatch
+	err := repo.SaveBatch(ctx, notifications)
+	require.NoError(t, err)
+
+	// Verify every notification was saved and indexed
+	for _, notification := range notifications {
+		saved, err := repo.FindByID(ctx, notification.ID.String())
+		assert.NoError(t, err)
+		if assert.NotNil(t, saved) {
+			assert.Equal(t, notification.Recipient, saved.Recipient)
+		}
+	}
+
+	byRecipient, err := repo.FindByRecipient(ctx, "first@example.com", model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, byRecipient, 2)
+
+	byStatus, err := repo.FindByStatus(ctx, model.StatusPending, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, byStatus, 3)
+
+	// An empty batch is a no-op
+	assert.NoError(t, repo.SaveBatch(ctx, nil))
+}
+
+func TestNotificationRepository_SaveBatchPartialFailure(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// A notification owned by another tenant is rejected before it is sent
+	foreign := createTestNotification("foreign@example.com")
+	foreign.TenantID = "other"
+
+	// A recipient index holding the wrong type makes that notification's commands fail
+	broken := createTestNotification("broken@example.com")
+	require.NoError(t, repo.client.Set(ctx, repo.recipientKey(model.DefaultTenantID, "broken@example.com"), "not a sorted set", 0).Err())
+
+	saved := createTestNotification("saved@example.com")
+
+	err := repo.SaveBatch(ctx, []*model.Notification{foreign, broken, saved})
+	var batchErr model.ErrBatchSave
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr.Errors, 3)
+	assert.ErrorAs(t, batchErr.Errors[0], &model.ErrTenantMismatch{})
+	assert.Error(t, batchErr.Errors[1])
+	assert.NoError(t, batchErr.Errors[2])
+	assert.Equal(t, 2, batchErr.Failed())
+
+	// The rest of the batch is still saved
+	found, err := repo.FindByID(ctx, saved.ID.String())
+	require.NoError(t, err)
+	assert.NotNil(t, found)
+}
+
+func TestNotificationRepository_Compression(t *testing.T) {
+	repo, _, cleanup := setupTestRepoWithConfig(t, NotificationRepositoryConfig{CompressionThreshold: 1024})
+	defer cleanup()
+
+	ctx := context.Background()
+
+	large := createTestNotification("large@example.com")
+	large.Content = "<html><body>" + strings.Repeat("<p>Hello there</p>", 500) + "</body></html>"
+	small := createTestNotification("small@example.com")
+	require.NoError(t, repo.Save(ctx, large))
+	require.NoError(t, repo.Save(ctx, small))
+
+	// Large payloads are stored gzipped, small ones as plain JSON
+	stored, err := repo.client.Get(ctx, repo.notificationKey(model.DefaultTenantID, large.ID.String())).Bytes()
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(stored, gzipMagic))
+	assert.Less(t, len(stored), len(large.Content))
+
+	stored, err = repo.client.Get(ctx, repo.notificationKey(model.DefaultTenantID, small.ID.String())).Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, byte('{'), stored[0])
+
+	// Both read back transparently on every read path
+	found, err := repo.FindByID(ctx, large.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, large.Content, found.Content)
+
+	byIDs, err := repo.FindByIDs(ctx, []string{large.ID.String(), small.ID.String()})
+	require.NoError(t, err)
+	assert.Len(t, byIDs, 2)
+
+	byRecipient, err := repo.FindByRecipient(ctx, "large@example.com", model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, byRecipient, 1)
+	assert.Equal(t, large.Content, byRecipient[0].Content)
+}
+
+func TestNotificationRepository_ReadsUncompressedValues(t *testing.T) {
+	ctx := context.Background()
+	notification := createTestNotification("legacy@example.com")
+	notification.Content = strings.Repeat("legacy content ", 200)
+
+	// Values written before compression was enabled are plain JSON
+	plain, cleanup := setupTestRepo(t)
+	defer cleanup()
+	require.NoError(t, plain.Save(ctx, notification))
+
+	repo := NewNotificationRepository(plain.client, NotificationRepositoryConfig{CompressionThreshold: 64}, plain.logger)
+	found, err := repo.FindByID(ctx, notification.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, notification.Content, found.Content)
+
+	// Updating rewrites the value compressed
+	require.NoError(t, found.TransitionTo(model.StatusSent, ""))
+	require.NoError(t, repo.Update(ctx, found))
+	stored, err := repo.client.Get(ctx, repo.notificationKey(model.DefaultTenantID, notification.ID.String())).Bytes()
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(stored, gzipMagic))
+}
+
 func TestNotificationRepository_FindByID(t *testing.T) {
 	repo, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -103,6 +248,363 @@ func TestNotificationRepository_FindByID(t *testing.T) {
 	})
 }
 
+func TestNotificationRepository_FindByIDs(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	first := createTestNotification("first@example.com")
+	second := createTestNotification("second@example.com")
+	require.NoError(t, repo.Save(ctx, first))
+	require.NoError(t, repo.Save(ctx, second))
+
+	t.Run("Existing and missing IDs", func(t *testing.T) {
+		found, err := repo.FindByIDs(ctx, []string{first.ID.String(), uuid.New().String(), second.ID.String()})
+		assert.NoError(t, err)
+		require.Len(t, found, 2)
+		assert.Equal(t, first.ID, found[0].ID)
+		assert.Equal(t, second.ID, found[1].ID)
+	})
+
+	t.Run("Other tenant", func(t *testing.T) {
+		found, err := repo.FindByIDs(model.ContextWithTenant(ctx, "other"), []string{first.ID.String()})
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("No IDs", func(t *testing.T) {
+		found, err := repo.FindByIDs(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestNotificationRepository_FindByStatus(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	notification := createTestNotification("test@example.com")
+	require.NoError(t, repo.Save(ctx, notification))
+
+	found, err := repo.FindByStatus(ctx, notification.Status, 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, notification.ID, found[0].ID)
+
+	t.Run("Status change moves the notification between indexes", func(t *testing.T) {
+		previous := notification.Status
+		require.NoError(t, notification.TransitionTo(model.StatusFailed, "provider unavailable"))
+		require.NoError(t, repo.Update(ctx, notification))
+
+		found, err := repo.FindByStatus(ctx, previous, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+
+		found, err = repo.FindByStatus(ctx, model.StatusFailed, 10, 0)
+		assert.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, "provider unavailable", found[0].ErrorMessage)
+	})
+
+	t.Run("Delete removes the notification from the index", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, notification.ID.String()))
+
+		found, err := repo.FindByStatus(ctx, model.StatusFailed, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestNotificationRepository_FindByCategory(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	security := createTestNotification("test@example.com")
+	security.Category = model.CategorySecurity
+	security.Tags = []string{"password", "account"}
+	require.NoError(t, repo.Save(ctx, security))
+
+	uncategorized := createTestNotification("test@example.com")
+	require.NoError(t, repo.Save(ctx, uncategorized))
+
+	found, err := repo.FindByCategory(ctx, model.CategorySecurity, 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, security.ID, found[0].ID)
+	assert.Equal(t, []string{"password", "account"}, found[0].Tags)
+
+	found, err = repo.FindByCategory(ctx, model.CategoryWelcome, 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	t.Run("Delete removes the notification from the index", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, security.ID.String()))
+
+		found, err := repo.FindByCategory(ctx, model.CategorySecurity, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestNotificationRepository_DeleteOlderThan(t *testing.T) {
+	config := DefaultNotificationRepositoryConfig()
+	config.PurgeBatchSize = 2
+	repo, _, cleanup := setupTestRepoWithConfig(t, config)
+	defer cleanup()
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-24 * time.Hour)
+
+	// Five old notifications span three batches for the first recipient
+	var old []*model.Notification
+	for i := 0; i < 5; i++ {
+		notification := createTestNotification("first@example.com")
+		notification.CreatedAt = cutoff.Add(-time.Duration(i+1) * time.Hour)
+		notification.Category = model.CategorySecurity
+		old = append(old, notification)
+	}
+	other := createTestNotification("second@example.com")
+	other.CreatedAt = cutoff.Add(-time.Hour)
+	old = append(old, other)
+	for _, notification := range old {
+		require.NoError(t, repo.Save(ctx, notification))
+	}
+
+	recent := createTestNotification("first@example.com")
+	require.NoError(t, repo.Save(ctx, recent))
+
+	deleted, err := repo.DeleteOlderThan(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 6, deleted)
+
+	for _, notification := range old {
+		found, err := repo.FindByID(ctx, notification.ID.String())
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	}
+
+	found, err := repo.FindByRecipient(ctx, "first@example.com", model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, recent.ID, found[0].ID)
+
+	found, err = repo.FindByStatus(ctx, model.StatusPending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	found, err = repo.FindByCategory(ctx, model.CategorySecurity, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	t.Run("Other tenants are untouched", func(t *testing.T) {
+		tenantCtx := model.ContextWithTenant(ctx, "other")
+		notification := createTestNotification("first@example.com")
+		notification.CreatedAt = cutoff.Add(-time.Hour)
+		require.NoError(t, repo.Save(tenantCtx, notification))
+
+		deleted, err := repo.DeleteOlderThan(ctx, cutoff)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+
+		found, err := repo.FindByID(tenantCtx, notification.ID.String())
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+	})
+}
+
+func TestNotificationRepository_FindByCorrelationID(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	baseTime := time.Now()
+
+	var correlated []*model.Notification
+	for i, recipient := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		notification := createTestNotification(recipient)
+		notification.CorrelationID = "req-123"
+		notification.CreatedAt = baseTime.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, repo.Save(ctx, notification))
+		correlated = append(correlated, notification)
+	}
+	other := createTestNotification("first@example.com")
+	other.CorrelationID = "req-456"
+	require.NoError(t, repo.Save(ctx, other))
+
+	found, err := repo.FindByCorrelationID(ctx, "req-123", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	for i, notification := range found {
+		assert.Equal(t, correlated[i].ID, notification.ID)
+		assert.Equal(t, "req-123", notification.CorrelationID)
+	}
+
+	found, err = repo.FindByCorrelationID(ctx, "req-123", 2, 2)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, correlated[2].ID, found[0].ID)
+
+	t.Run("Deleted notifications leave the index", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, other.ID.String()))
+		found, err := repo.FindByCorrelationID(ctx, "req-456", 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("Other tenants don't see them", func(t *testing.T) {
+		found, err := repo.FindByCorrelationID(model.ContextWithTenant(ctx, "other"), "req-123", 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestNotificationRepository_FindByGroupID(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	groupID := model.NewGroupID()
+	original := createTestNotification("user@example.com")
+
+	var grouped []*model.Notification
+	for i, notificationType := range []model.NotificationType{model.EmailNotification, model.SMSNotification, model.PushNotification} {
+		notification := original.ForChannel(notificationType, original.Recipient, groupID)
+		notification.CreatedAt = notification.CreatedAt.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.Save(ctx, notification))
+		grouped = append(grouped, notification)
+	}
+	require.NoError(t, repo.Save(ctx, original))
+
+	found, err := repo.FindByGroupID(ctx, groupID)
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	for i, notification := range found {
+		assert.Equal(t, grouped[i].ID, notification.ID)
+		assert.Equal(t, grouped[i].Type, notification.Type)
+		assert.Equal(t, groupID, notification.GroupID)
+	}
+
+	t.Run("Deleted notifications leave the index", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, grouped[0].ID.String()))
+		found, err := repo.FindByGroupID(ctx, groupID)
+		require.NoError(t, err)
+		assert.Len(t, found, 2)
+	})
+
+	t.Run("Other tenants don't see them", func(t *testing.T) {
+		found, err := repo.FindByGroupID(model.ContextWithTenant(ctx, "other"), groupID)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestNotificationRepository_FindByThreadID(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	var thread []*model.Notification
+	for i := 0; i < 2; i++ {
+		notification := createTestNotification("user@example.com")
+		notification.ThreadID = "order-42"
+		notification.EmailMessageID = fmt.Sprintf("%d@example.com", i)
+		notification.CreatedAt = notification.CreatedAt.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.Save(ctx, notification))
+		thread = append(thread, notification)
+	}
+	require.NoError(t, repo.Save(ctx, createTestNotification("user@example.com")))
+
+	found, err := repo.FindByThreadID(ctx, "order-42")
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	for i, notification := range found {
+		assert.Equal(t, thread[i].ID, notification.ID)
+		assert.Equal(t, thread[i].EmailMessageID, notification.EmailMessageID)
+	}
+
+	t.Run("Deleted notifications leave the index", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, thread[0].ID.String()))
+		found, err := repo.FindByThreadID(ctx, "order-42")
+		require.NoError(t, err)
+		assert.Len(t, found, 1)
+	})
+
+	t.Run("Other tenants don't see them", func(t *testing.T) {
+		found, err := repo.FindByThreadID(model.ContextWithTenant(ctx, "other"), "order-42")
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestNotificationRepository_EraseRecipient(t *testing.T) {
+	config := DefaultNotificationRepositoryConfig()
+	config.PurgeBatchSize = 2
+	repo, _, cleanup := setupTestRepoWithConfig(t, config)
+	defer cleanup()
+
+	ctx := context.Background()
+	recipient := "user@example.com"
+	erasedAs := model.NewErasedRecipient()
+
+	// Three notifications span two batches
+	var notifications []*model.Notification
+	for i := 0; i < 3; i++ {
+		notification := createTestNotification(recipient)
+		notification.Subject = "Hello"
+		notification.Content = "Hello, user@example.com"
+		notification.Category = model.CategoryWelcome
+		require.NoError(t, repo.Save(ctx, notification))
+		notifications = append(notifications, notification)
+	}
+	other := createTestNotification("other@example.com")
+	require.NoError(t, repo.Save(ctx, other))
+
+	erased, err := repo.EraseRecipient(ctx, recipient, erasedAs)
+	require.NoError(t, err)
+	assert.Equal(t, 3, erased)
+
+	for _, notification := range notifications {
+		found, err := repo.FindByID(ctx, notification.ID.String())
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, erasedAs, found.Recipient)
+		assert.Empty(t, found.Subject)
+		assert.Empty(t, found.Content)
+		assert.Nil(t, found.TemplateData)
+		assert.Equal(t, model.StatusPending, found.Status)
+	}
+
+	found, err := repo.FindByRecipient(ctx, recipient, model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	found, err = repo.FindByRecipientAndStatus(ctx, recipient, model.StatusPending, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = repo.FindByRecipient(ctx, erasedAs, model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, found, 3)
+	found, err = repo.FindByCategory(ctx, model.CategoryWelcome, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, found, 3)
+
+	found, err = repo.FindByRecipient(ctx, "other@example.com", model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, other.TemplateData, found[0].TemplateData)
+
+	t.Run("Erasing again finds nothing", func(t *testing.T) {
+		erased, err := repo.EraseRecipient(ctx, recipient, erasedAs)
+		require.NoError(t, err)
+		assert.Zero(t, erased)
+	})
+}
+
 func TestNotificationRepository_FindByRecipient(t *testing.T) {
 	repo, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -113,7 +615,7 @@ func TestNotificationRepository_FindByRecipient(t *testing.T) {
 	// Create test notifications with explicit timestamps
 	notifications := make([]*model.Notification, 5)
 	baseTime := time.Now()
-	
+
 	for i := 0; i < 5; i++ {
 		notification := createTestNotification(recipient)
 		notification.CreatedAt = baseTime.Add(time.Duration(i) * time.Hour)
@@ -124,33 +626,229 @@ func TestNotificationRepository_FindByRecipient(t *testing.T) {
 
 	t.Run("Pagination test", func(t *testing.T) {
 		// Test first page (should get the two most recent notifications)
-		found, err := repo.FindByRecipient(ctx, recipient, 2, 0)
+		found, err := repo.FindByRecipient(ctx, recipient, model.SortDescending, 2, 0)
 		assert.NoError(t, err)
 		assert.Len(t, found, 2)
-		
+
 		// Should be ordered by CreatedAt descending
 		assert.True(t, found[0].CreatedAt.After(found[1].CreatedAt), "First notification should be more recent than second")
 		assert.Equal(t, notifications[4].ID, found[0].ID, "Should get the most recent notification first")
 		assert.Equal(t, notifications[3].ID, found[1].ID, "Should get the second most recent notification")
 
 		// Test second page
-		found, err = repo.FindByRecipient(ctx, recipient, 2, 2)
+		found, err = repo.FindByRecipient(ctx, recipient, model.SortDescending, 2, 2)
 		assert.NoError(t, err)
 		assert.Len(t, found, 2)
-		
+
 		// Verify ordering continues
 		assert.True(t, found[0].CreatedAt.After(found[1].CreatedAt), "First notification should be more recent than second")
 		assert.Equal(t, notifications[2].ID, found[0].ID, "Should get the third most recent notification")
 		assert.Equal(t, notifications[1].ID, found[1].ID, "Should get the fourth most recent notification")
 	})
 
+	t.Run("Oldest first", func(t *testing.T) {
+		found, err := repo.FindByRecipient(ctx, recipient, model.SortAscending, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+		assert.Equal(t, notifications[0].ID, found[0].ID)
+		assert.Equal(t, notifications[1].ID, found[1].ID)
+
+		found, err = repo.FindByRecipient(ctx, recipient, model.SortAscending, 2, 4)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, notifications[4].ID, found[0].ID)
+	})
+
 	t.Run("Empty result", func(t *testing.T) {
-		found, err := repo.FindByRecipient(ctx, "nonexistent@example.com", 10, 0)
+		found, err := repo.FindByRecipient(ctx, "nonexistent@example.com", model.SortDescending, 10, 0)
 		assert.NoError(t, err)
 		assert.Empty(t, found)
 	})
 }
 
+func TestNotificationRepository_FindByRecipientByPriority(t *testing.T) {
+	config := DefaultNotificationRepositoryConfig()
+	config.PriorityIndex = true
+	repo, mr, cleanup := setupTestRepoWithConfig(t, config)
+	defer cleanup()
+
+	ctx := context.Background()
+	recipient := "test@example.com"
+	baseTime := time.Now()
+
+	save := func(priority model.Priority, age time.Duration) *model.Notification {
+		notification := createTestNotification(recipient)
+		notification.Priority = priority
+		notification.CreatedAt = baseTime.Add(-age)
+		require.NoError(t, repo.Save(ctx, notification))
+		return notification
+	}
+	oldHigh := save(model.PriorityHigh, 48*time.Hour)
+	newLow := save(model.PriorityLow, 0)
+	newHigh := save(model.PriorityHigh, time.Hour)
+	medium := save(model.PriorityMedium, 2*time.Hour)
+
+	ids := func(notifications []*model.Notification) []uuid.UUID {
+		result := make([]uuid.UUID, 0, len(notifications))
+		for _, notification := range notifications {
+			result = append(result, notification.ID)
+		}
+		return result
+	}
+
+	t.Run("High priority first, newest first within a priority", func(t *testing.T) {
+		found, err := repo.FindByRecipientByPriority(ctx, recipient, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{newHigh.ID, oldHigh.ID, medium.ID, newLow.ID}, ids(found))
+
+		found, err = repo.FindByRecipientByPriority(ctx, recipient, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{medium.ID, newLow.ID}, ids(found))
+	})
+
+	t.Run("The inbox listing stays ordered by time", func(t *testing.T) {
+		found, err := repo.FindByRecipient(ctx, recipient, model.SortDescending, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{newLow.ID, newHigh.ID, medium.ID, oldHigh.ID}, ids(found))
+	})
+
+	t.Run("Deleted notifications leave the index", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, newHigh.ID.String()))
+
+		found, err := repo.FindByRecipientByPriority(ctx, recipient, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{oldHigh.ID, medium.ID, newLow.ID}, ids(found))
+	})
+
+	t.Run("Erased notifications leave the recipient's index", func(t *testing.T) {
+		_, err := repo.EraseRecipient(ctx, recipient, "erased")
+		require.NoError(t, err)
+		assert.False(t, mr.Exists(repo.recipientPriorityKey(model.DefaultTenantID, recipient)))
+
+		found, err := repo.FindByRecipientByPriority(ctx, "erased", 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, found, 3)
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		repo, cleanup := setupTestRepo(t)
+		defer cleanup()
+
+		_, err := repo.FindByRecipientByPriority(ctx, recipient, 10, 0)
+		assert.ErrorIs(t, err, ErrPriorityIndexDisabled)
+	})
+}
+
+func TestNotificationRepository_FindByRecipientAndStatus(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	recipient := "inbox@example.com"
+
+	// Create test notifications
+	var notifications []*model.Notification
+	for i := 0; i < 3; i++ {
+		notification := createTestNotification(recipient)
+		notification.CreatedAt = time.Now().Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Save(ctx, notification))
+		notifications = append(notifications, notification)
+	}
+	require.NoError(t, repo.Save(ctx, createTestNotification("other@example.com")))
+
+	// Mark one as sent
+	require.NoError(t, notifications[1].TransitionTo(model.StatusSent, ""))
+	require.NoError(t, repo.Update(ctx, notifications[1]))
+
+	pending, err := repo.FindByRecipientAndStatus(ctx, recipient, model.StatusPending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, notifications[2].ID, pending[0].ID) // newest first
+	assert.Equal(t, notifications[0].ID, pending[1].ID)
+
+	sent, err := repo.FindByRecipientAndStatus(ctx, recipient, model.StatusSent, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, notifications[1].ID, sent[0].ID)
+
+	// Pagination
+	page, err := repo.FindByRecipientAndStatus(ctx, recipient, model.StatusPending, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, notifications[0].ID, page[0].ID)
+
+	// Deleting removes the notification from the index
+	require.NoError(t, repo.Delete(ctx, notifications[1].ID.String()))
+	sent, err = repo.FindByRecipientAndStatus(ctx, recipient, model.StatusSent, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, sent)
+
+	// The index is scoped to the tenant
+	other, err := repo.FindByRecipientAndStatus(model.ContextWithTenant(ctx, "other"), recipient, model.StatusPending, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+}
+
+func TestNotificationRepository_PrunesExpiredNotifications(t *testing.T) {
+	repo, mr, cleanup := setupTestRepoWithConfig(t, DefaultNotificationRepositoryConfig())
+	defer cleanup()
+
+	ctx := context.Background()
+	recipient := "expiring@example.com"
+	tenantID := model.DefaultTenantID
+
+	expired := createTestNotification(recipient)
+	require.NoError(t, repo.Save(ctx, expired))
+
+	// A later save keeps the indexes alive after the first notification expires
+	mr.FastForward(defaultExpiration - time.Hour)
+	current := createTestNotification(recipient)
+	require.NoError(t, repo.Save(ctx, current))
+	mr.FastForward(2 * time.Hour)
+
+	assert.False(t, mr.Exists(repo.notificationKey(tenantID, expired.ID.String())))
+	count, err := repo.client.ZCard(ctx, repo.recipientKey(tenantID, recipient)).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Reading skips the expired notification and prunes it from every index
+	found, err := repo.FindByRecipient(ctx, recipient, model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, current.ID, found[0].ID)
+
+	for _, key := range []string{
+		repo.recipientKey(tenantID, recipient),
+		repo.statusKey(tenantID, model.StatusPending),
+		repo.recipientStatusKey(tenantID, recipient, model.StatusPending),
+	} {
+		members, err := repo.client.ZRange(ctx, key, 0, -1).Result()
+		require.NoError(t, err)
+		assert.Equal(t, []string{current.ID.String()}, members, key)
+	}
+}
+
+func TestNotificationRepository_FindByStatusPrunesExpiredNotifications(t *testing.T) {
+	repo, mr, cleanup := setupTestRepoWithConfig(t, DefaultNotificationRepositoryConfig())
+	defer cleanup()
+
+	ctx := context.Background()
+	expired := createTestNotification("first@example.com")
+	require.NoError(t, repo.Save(ctx, expired))
+	mr.FastForward(defaultExpiration - time.Hour)
+	current := createTestNotification("second@example.com")
+	require.NoError(t, repo.Save(ctx, current))
+	mr.FastForward(2 * time.Hour)
+
+	found, err := repo.FindByStatus(ctx, model.StatusPending, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	members, err := repo.client.ZRange(ctx, repo.statusKey(model.DefaultTenantID, model.StatusPending), 0, -1).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{current.ID.String()}, members)
+}
+
 func TestNotificationRepository_Update(t *testing.T) {
 	repo, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -196,7 +894,7 @@ func TestNotificationRepository_Delete(t *testing.T) {
 		require.NoError(t, err)
 
 		// Delete notification
-		err = repo.DeleteByID(ctx, notification.ID.String())
+		err = repo.Delete(ctx, notification.ID.String())
 		assert.NoError(t, err)
 
 		// Verify deletion
@@ -205,13 +903,183 @@ func TestNotificationRepository_Delete(t *testing.T) {
 		assert.Nil(t, found)
 
 		// Verify removal from recipient's list
-		notifications, err := repo.FindByRecipient(ctx, notification.Recipient, 10, 0)
+		notifications, err := repo.FindByRecipient(ctx, notification.Recipient, model.SortDescending, 10, 0)
 		assert.NoError(t, err)
 		assert.Empty(t, notifications)
 	})
 
 	t.Run("Delete non-existing notification", func(t *testing.T) {
-		err := repo.DeleteByID(ctx, uuid.New().String())
-		assert.NoError(t, err) // Should not return error for non-existing notification
+		err := repo.Delete(ctx, uuid.New().String())
+		assert.ErrorAs(t, err, &model.ErrNotificationNotFound{})
+	})
+}
+
+func TestNotificationRepository_FindByProviderMessageID(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Notifications are saved in their tenant, webhooks look them up without one
+	tenantCtx := model.ContextWithTenant(context.Background(), "acme")
+	ctx := context.Background()
+
+	notification := createTestNotification("test@example.com")
+	require.NoError(t, repo.Save(tenantCtx, notification))
+
+	// The ID is only known once the provider accepted the message
+	found, err := repo.FindByProviderMessageID(ctx, "ses", "msg-1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	notification.ProviderMessageID = "msg-1"
+	notification.Provider = "ses"
+	require.NoError(t, repo.Update(tenantCtx, notification))
+
+	found, err = repo.FindByProviderMessageID(ctx, "ses", "msg-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, notification.ID, found.ID)
+	assert.Equal(t, "acme", found.TenantID)
+
+	// Another provider's message with the same ID belongs to another notification
+	found, err = repo.FindByProviderMessageID(ctx, "sendgrid", "msg-1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	other := createTestNotification("other@example.com")
+	other.ProviderMessageID = "msg-1"
+	other.Provider = "sendgrid"
+	require.NoError(t, repo.Save(model.ContextWithTenant(ctx, "globex"), other))
+
+	found, err = repo.FindByProviderMessageID(ctx, "sendgrid", "msg-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, other.ID, found.ID)
+	assert.Equal(t, "globex", found.TenantID)
+
+	found, err = repo.FindByProviderMessageID(ctx, "ses", "msg-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, notification.ID, found.ID)
+
+	// Deleting the notification removes the mapping
+	require.NoError(t, repo.Delete(tenantCtx, notification.ID.String()))
+	found, err = repo.FindByProviderMessageID(ctx, "ses", "msg-1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestNotificationRepository_FindByProviderMessageIDWithoutProvider(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	tenantCtx := model.ContextWithTenant(context.Background(), "acme")
+	ctx := context.Background()
+
+	// Notifications sent by a provider that doesn't report its name, or saved
+	// before providers were recorded, are found by the message ID alone
+	notification := createTestNotification("test@example.com")
+	notification.ProviderMessageID = "msg-1"
+	require.NoError(t, repo.Save(tenantCtx, notification))
+
+	found, err := repo.FindByProviderMessageID(ctx, "sendgrid", "msg-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, notification.ID, found.ID)
+
+	found, err = repo.FindByProviderMessageID(ctx, "", "msg-1")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, notification.ID, found.ID)
+}
+
+func TestNotificationRepository_TenantIsolation(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	tenantA := model.ContextWithTenant(context.Background(), "tenant-a")
+	tenantB := model.ContextWithTenant(context.Background(), "tenant-b")
+	recipient := "test@example.com"
+
+	notification := createTestNotification(recipient)
+	require.NoError(t, repo.Save(tenantA, notification))
+	assert.Equal(t, "tenant-a", notification.TenantID)
+
+	t.Run("Owner tenant can read", func(t *testing.T) {
+		found, err := repo.FindByID(tenantA, notification.ID.String())
+		assert.NoError(t, err)
+		assert.NotNil(t, found)
+
+		notifications, err := repo.FindByRecipient(tenantA, recipient, model.SortDescending, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+	})
+
+	t.Run("Other tenant cannot read", func(t *testing.T) {
+		found, err := repo.FindByID(tenantB, notification.ID.String())
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+
+		notifications, err := repo.FindByRecipient(tenantB, recipient, model.SortDescending, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, notifications)
 	})
+
+	t.Run("Other tenant cannot update", func(t *testing.T) {
+		notification.Subject = "Hijacked"
+		err := repo.Update(tenantB, notification)
+		assert.ErrorAs(t, err, &model.ErrTenantMismatch{})
+
+		found, err := repo.FindByID(tenantA, notification.ID.String())
+		require.NoError(t, err)
+		assert.NotEqual(t, "Hijacked", found.Subject)
+	})
+
+	t.Run("Other tenant cannot delete", func(t *testing.T) {
+		err := repo.Delete(tenantB, notification.ID.String())
+		assert.ErrorAs(t, err, &model.ErrNotificationNotFound{})
+
+		found, err := repo.FindByID(tenantA, notification.ID.String())
+		assert.NoError(t, err)
+		assert.NotNil(t, found)
+	})
+
+	t.Run("Same recipient in another tenant is kept separate", func(t *testing.T) {
+		other := createTestNotification(recipient)
+		require.NoError(t, repo.Save(tenantB, other))
+
+		notifications, err := repo.FindByRecipient(tenantB, recipient, model.SortDescending, 10, 0)
+		assert.NoError(t, err)
+		if assert.Len(t, notifications, 1) {
+			assert.Equal(t, other.ID, notifications[0].ID)
+		}
+	})
+}
+
+func TestNotificationRepository_Namespace(t *testing.T) {
+	repo, mr, cleanup := setupTestRepoWithConfig(t, NotificationRepositoryConfig{Namespace: "prod"})
+	defer cleanup()
+
+	ctx := context.Background()
+	notification := createTestNotification("user@example.com")
+	notification.ProviderMessageID = "provider-1"
+	require.NoError(t, repo.Save(ctx, notification))
+
+	keys := mr.Keys()
+	require.NotEmpty(t, keys)
+	for _, key := range keys {
+		assert.True(t, strings.HasPrefix(key, "prod:"), key)
+	}
+
+	found, err := repo.FindByID(ctx, notification.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, found)
+
+	// Another environment sharing the instance doesn't see prod's notifications
+	staging := NewNotificationRepository(repo.client, NotificationRepositoryConfig{Namespace: "staging"}, repo.logger)
+	found, err = staging.FindByID(ctx, notification.ID.String())
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	recipients, err := staging.FindByRecipient(ctx, "user@example.com", model.SortDescending, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, recipients)
 }
@@ -3,34 +3,148 @@ package redis
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Redis topologies a client can connect to
+const (
+	ModeStandalone = "standalone" // A single Redis server
+	ModeCluster    = "cluster"    // Redis Cluster, keys sharded across nodes
+	ModeSentinel   = "sentinel"   // A primary and its replicas, failed over by Sentinel
+)
+
 // Config holds Redis configuration
 type Config struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
+	Mode             string   // ModeStandalone (default), ModeCluster or ModeSentinel
+	Host             string   // Standalone server host
+	Port             int      // Standalone server port
+	Addrs            []string // host:port of the cluster nodes to discover the cluster from, or of the sentinels
+	MasterName       string   // Name of the primary the sentinels monitor
+	Password         string
+	SentinelPassword string // Password of the sentinels themselves, if different
+	DB               int    // Not supported by Redis Cluster
 }
 
-// NewRedisClient creates a new Redis client
-func NewRedisClient(config *Config) (*redis.Client, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", config.Host, config.Port),
-		Password: config.Password,
-		DB:       config.DB,
-	})
+// Namespace normalizes a key namespace, such as "prod", into the prefix put in
+// front of every key so environments can share one Redis instance: "prod"
+// becomes "prod:". An empty namespace leaves keys unprefixed.
+func Namespace(namespace string) string {
+	if namespace == "" || strings.HasSuffix(namespace, ":") {
+		return namespace
+	}
+	return namespace + ":"
+}
+
+// NewRedisClient creates a Redis client for the configured topology. The
+// repositories accept any of them as a redis.UniversalClient. The cluster
+// client follows MOVED and ASK redirects itself and splits pipelines by the
+// node owning each key, so only commands touching several keys at once need
+// those keys in one slot.
+func NewRedisClient(config *Config) (redis.UniversalClient, error) {
+	var client redis.UniversalClient
+	switch config.Mode {
+	case "", ModeStandalone:
+		client = redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", config.Host, config.Port),
+			Password: config.Password,
+			DB:       config.DB,
+		})
+	case ModeCluster:
+		if len(config.Addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster mode needs at least one node address")
+		}
+		if config.DB != 0 {
+			return nil, fmt.Errorf("redis cluster only supports database 0, not %d", config.DB)
+		}
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    config.Addrs,
+			Password: config.Password,
+		})
+	case ModeSentinel:
+		if len(config.Addrs) == 0 || config.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode needs the sentinel addresses and the master name")
+		}
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       config.MasterName,
+			SentinelAddrs:    config.Addrs,
+			SentinelPassword: config.SentinelPassword,
+			Password:         config.Password,
+			DB:               config.DB,
+		})
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q, expected %s, %s or %s", config.Mode, ModeStandalone, ModeCluster, ModeSentinel)
+	}
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
 	return client, nil
 }
+
+// getMany gets the values of keys in one round trip, with nil for missing keys.
+// MGET fails with CROSSSLOT on Redis Cluster when the keys hash to different
+// slots; pipelined GETs are split across the nodes owning the keys instead.
+func getMany(ctx context.Context, client redis.UniversalClient, keys []string) ([]interface{}, error) {
+	pipe := client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// scanKeys calls fn with each key matching pattern, scanning scanCount keys per
+// round trip. Redis Cluster nodes only hold their own slots' keys, so there every
+// primary is scanned; fn is never called concurrently.
+func scanKeys(ctx context.Context, client redis.UniversalClient, pattern string, fn func(key string) error) error {
+	const scanCount = 1000
+
+	scan := func(ctx context.Context, node redis.Cmdable, fn func(key string) error) error {
+		iter := node.Scan(ctx, 0, pattern, scanCount).Iterator()
+		for iter.Next(ctx) {
+			if err := fn(iter.Val()); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return scan(ctx, client, fn)
+	}
+
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scan(ctx, node, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(key)
+		})
+	})
+}
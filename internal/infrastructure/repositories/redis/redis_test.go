@@ -0,0 +1,33 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	assert.Equal(t, "", Namespace(""))
+	assert.Equal(t, "prod:", Namespace("prod"))
+	assert.Equal(t, "prod:", Namespace("prod:"))
+}
+
+func TestNewRedisClient_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "unknown mode", config: Config{Mode: "replicated"}},
+		{name: "cluster without addresses", config: Config{Mode: ModeCluster}},
+		{name: "cluster with database", config: Config{Mode: ModeCluster, Addrs: []string{"localhost:7000"}, DB: 1}},
+		{name: "sentinel without master", config: Config{Mode: ModeSentinel, Addrs: []string{"localhost:26379"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewRedisClient(&tt.config)
+			assert.Error(t, err)
+			assert.Nil(t, client)
+		})
+	}
+}
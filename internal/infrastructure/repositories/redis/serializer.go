@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Serializer encodes values stored in Redis
+type Serializer interface {
+	// Name identifies the format in configuration
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONSerializer stores values as JSON
+type JSONSerializer struct{}
+
+// Name returns "json"
+func (JSONSerializer) Name() string { return "json" }
+
+// Marshal encodes v as JSON
+func (JSONSerializer) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal decodes JSON data into v
+func (JSONSerializer) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// MsgpackSerializer stores values as MessagePack, which is smaller and faster
+// to encode than JSON. Fields are named after their json tags so both formats
+// describe the same document.
+type MsgpackSerializer struct{}
+
+// Name returns "msgpack"
+func (MsgpackSerializer) Name() string { return "msgpack" }
+
+// Marshal encodes v as MessagePack
+func (MsgpackSerializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes MessagePack data into v
+func (MsgpackSerializer) Unmarshal(data []byte, v interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
+
+// SerializerByName returns the serializer configured by name
+func SerializerByName(name string) (Serializer, error) {
+	switch name {
+	case "", JSONSerializer{}.Name():
+		return JSONSerializer{}, nil
+	case MsgpackSerializer{}.Name():
+		return MsgpackSerializer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown serializer: %s", name)
+	}
+}
+
+// detectSerializer returns the serializer a stored value was written with.
+// Stored documents are JSON objects or MessagePack maps, and a MessagePack
+// map never starts with '{', so values stay readable after switching formats.
+func detectSerializer(data []byte) Serializer {
+	if len(data) > 0 && data[0] == '{' {
+		return JSONSerializer{}
+	}
+	return MsgpackSerializer{}
+}
@@ -0,0 +1,146 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializers_RoundTrip(t *testing.T) {
+	notification := createTestNotification("test@example.com")
+	notification.Subject = "Welcome"
+	notification.Metadata = map[string]string{"campaign": "spring"}
+	notification.TemplateData["items"] = []interface{}{map[string]interface{}{"name": "Notebook", "quantity": 2.0}}
+
+	for _, serializer := range []Serializer{JSONSerializer{}, MsgpackSerializer{}} {
+		t.Run(serializer.Name(), func(t *testing.T) {
+			data, err := serializer.Marshal(notification)
+			require.NoError(t, err)
+
+			// Stored values are recognised without knowing the configured format
+			assert.Equal(t, serializer.Name(), detectSerializer(data).Name())
+
+			decoded, err := decodeNotification(data)
+			require.NoError(t, err)
+			assert.Equal(t, notification.ID, decoded.ID)
+			assert.Equal(t, notification.Subject, decoded.Subject)
+			assert.Equal(t, notification.TemplateData, decoded.TemplateData)
+			assert.Equal(t, notification.Metadata, decoded.Metadata)
+			assert.True(t, notification.CreatedAt.Equal(decoded.CreatedAt))
+		})
+	}
+}
+
+func TestSerializerByName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Serializer
+		wantErr  bool
+	}{
+		{name: "", expected: JSONSerializer{}},
+		{name: "json", expected: JSONSerializer{}},
+		{name: "msgpack", expected: MsgpackSerializer{}},
+		{name: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer, err := SerializerByName(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, serializer)
+		})
+	}
+}
+
+func TestNotificationRepository_SwitchingSerializer(t *testing.T) {
+	ctx := context.Background()
+
+	jsonRepo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	msgpackRepo := NewNotificationRepository(jsonRepo.client, NotificationRepositoryConfig{
+		Serializer:           MsgpackSerializer{},
+		CompressionThreshold: 1024,
+	}, jsonRepo.logger)
+
+	written := createTestNotification("json@example.com")
+	require.NoError(t, jsonRepo.Save(ctx, written))
+
+	// Values written as JSON stay readable after switching to msgpack
+	found, err := msgpackRepo.FindByID(ctx, written.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, written.Recipient, found.Recipient)
+
+	// ...and msgpack values, compressed or not, are readable by a JSON-configured repository
+	small := createTestNotification("small@example.com")
+	large := createTestNotification("large@example.com")
+	large.Content = strings.Repeat("<p>Hello there</p>", 500)
+	require.NoError(t, msgpackRepo.SaveBatch(ctx, []*model.Notification{small, large}))
+
+	stored, err := jsonRepo.client.Get(ctx, jsonRepo.notificationKey(model.DefaultTenantID, small.ID.String())).Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, "msgpack", detectSerializer(stored).Name())
+
+	found, err = jsonRepo.FindByID(ctx, large.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, large.Content, found.Content)
+
+	byIDs, err := jsonRepo.FindByIDs(ctx, []string{written.ID.String(), small.ID.String(), large.ID.String()})
+	require.NoError(t, err)
+	assert.Len(t, byIDs, 3)
+}
+
+func benchmarkNotification() *model.Notification {
+	notification := createTestNotification("bench@example.com")
+	notification.Subject = "Your weekly digest"
+	notification.Content = "<html><body>" + strings.Repeat("<p>Hello there</p>", 50) + "</body></html>"
+	notification.Metadata = map[string]string{"campaign": "digest", "locale": "en"}
+	return notification
+}
+
+func BenchmarkSerializer_Marshal(b *testing.B) {
+	notification := benchmarkNotification()
+	for _, serializer := range []Serializer{JSONSerializer{}, MsgpackSerializer{}} {
+		b.Run(serializer.Name(), func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for i := 0; i < b.N; i++ {
+				data, err := serializer.Marshal(notification)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "bytes/op")
+		})
+	}
+}
+
+func BenchmarkSerializer_Unmarshal(b *testing.B) {
+	notification := benchmarkNotification()
+	for _, serializer := range []Serializer{JSONSerializer{}, MsgpackSerializer{}} {
+		data, err := serializer.Marshal(notification)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(serializer.Name(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var decoded model.Notification
+				if err := serializer.Unmarshal(data, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
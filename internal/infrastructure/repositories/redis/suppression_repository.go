@@ -0,0 +1,194 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	suppressionPrefix      = "suppression:"
+	suppressionIndexPrefix = "suppressions:"
+)
+
+// suppressionKey builds the key holding a tenant's suppression of a recipient
+func (r *SuppressionRepository) suppressionKey(tenantID, recipient string) string {
+	return fmt.Sprintf("%s%s%s:%s", r.namespace, suppressionPrefix, tenantID, recipient)
+}
+
+// suppressionIndexKey builds the key of a tenant's suppressed recipients, scored by when they were suppressed
+func (r *SuppressionRepository) suppressionIndexKey(tenantID string) string {
+	return r.namespace + suppressionIndexPrefix + tenantID
+}
+
+// SuppressionRepository implements repository.SuppressionRepository using Redis.
+// Suppressions never expire; they are only removed by Delete.
+type SuppressionRepository struct {
+	client    redis.UniversalClient
+	namespace string
+}
+
+// NewSuppressionRepository creates a new Redis-based suppression repository
+// whose keys are prefixed with namespace, see Namespace
+func NewSuppressionRepository(client redis.UniversalClient, namespace string) *SuppressionRepository {
+	return &SuppressionRepository{
+		client:    client,
+		namespace: Namespace(namespace),
+	}
+}
+
+// Save adds a recipient to the suppression list in Redis, keeping an existing entry
+func (r *SuppressionRepository) Save(ctx context.Context, suppression *model.Suppression) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_save_suppression", status, duration)
+	}()
+
+	tenantID, err := model.ResolveTenantID(ctx, suppression.TenantID)
+	if err != nil {
+		return err
+	}
+	suppression.TenantID = tenantID
+	suppression.Recipient = model.NormalizeSuppressedRecipient(suppression.Recipient)
+
+	data, err := json.Marshal(suppression)
+	if err != nil {
+		return fmt.Errorf("failed to marshal suppression: %w", err)
+	}
+
+	// Both writes leave an existing entry alone, so saving again is harmless
+	pipe := r.client.Pipeline()
+	pipe.SetNX(ctx, r.suppressionKey(tenantID, suppression.Recipient), data, 0)
+	pipe.ZAddNX(ctx, r.suppressionIndexKey(tenantID), redis.Z{
+		Score:  float64(suppression.CreatedAt.UnixNano()),
+		Member: suppression.Recipient,
+	})
+	if _, err = pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save suppression: %w", err)
+	}
+
+	return nil
+}
+
+// FindByRecipient finds a recipient's suppression in Redis
+func (r *SuppressionRepository) FindByRecipient(ctx context.Context, recipient string) (*model.Suppression, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_find_suppression_by_recipient", status, duration)
+	}()
+
+	key := r.suppressionKey(model.TenantFromContext(ctx), model.NormalizeSuppressedRecipient(recipient))
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		err = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppression: %w", err)
+	}
+
+	var suppression model.Suppression
+	if err = json.Unmarshal(data, &suppression); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal suppression: %w", err)
+	}
+
+	return &suppression, nil
+}
+
+// List lists suppressions from Redis with pagination, newest first
+func (r *SuppressionRepository) List(ctx context.Context, limit, offset int) ([]*model.Suppression, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_list_suppressions", status, duration)
+	}()
+
+	tenantID := model.TenantFromContext(ctx)
+	recipients, err := r.client.ZRevRange(ctx, r.suppressionIndexKey(tenantID), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppressions: %w", err)
+	}
+	if len(recipients) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		keys[i] = r.suppressionKey(tenantID, recipient)
+	}
+
+	values, err := getMany(ctx, r.client, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppressions: %w", err)
+	}
+
+	suppressions := make([]*model.Suppression, 0, len(values))
+	for _, value := range values {
+		// Skip index entries whose suppression was removed concurrently
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var suppression model.Suppression
+		if err = json.Unmarshal([]byte(data), &suppression); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal suppression: %w", err)
+		}
+		suppressions = append(suppressions, &suppression)
+	}
+
+	return suppressions, nil
+}
+
+// Delete removes a recipient from the suppression list in Redis
+func (r *SuppressionRepository) Delete(ctx context.Context, recipient string) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_delete_suppression", status, duration)
+	}()
+
+	tenantID := model.TenantFromContext(ctx)
+	normalized := model.NormalizeSuppressedRecipient(recipient)
+
+	pipe := r.client.Pipeline()
+	deleted := pipe.Del(ctx, r.suppressionKey(tenantID, normalized))
+	pipe.ZRem(ctx, r.suppressionIndexKey(tenantID), normalized)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete suppression: %w", err)
+	}
+
+	if deleted.Val() == 0 {
+		err = model.ErrSuppressionNotFound{Recipient: recipient}
+		return err
+	}
+
+	return nil
+}
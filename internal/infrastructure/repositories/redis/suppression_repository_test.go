@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ repository.SuppressionRepository = (*SuppressionRepository)(nil)
+
+func setupTestSuppressionRepo(t *testing.T) (*SuppressionRepository, func()) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	repo := NewSuppressionRepository(client, "")
+
+	cleanup := func() {
+		client.Close()
+		mr.Close()
+	}
+
+	return repo, cleanup
+}
+
+func TestSuppressionRepository_SaveAndFind(t *testing.T) {
+	repo, cleanup := setupTestSuppressionRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	found, err := repo.FindByRecipient(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	suppression := model.NewSuppression("User@Example.com", model.SuppressionHardBounce, "ses", "smtp; 550 5.1.1 user unknown")
+	require.NoError(t, repo.Save(ctx, suppression))
+
+	// Lookups match regardless of case
+	found, err = repo.FindByRecipient(ctx, "USER@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "user@example.com", found.Recipient)
+	assert.Equal(t, model.SuppressionHardBounce, found.Reason)
+	assert.Equal(t, model.DefaultTenantID, found.TenantID)
+
+	// A later complaint doesn't replace the original entry
+	require.NoError(t, repo.Save(ctx, model.NewSuppression("user@example.com", model.SuppressionComplaint, "ses", "")))
+	found, err = repo.FindByRecipient(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, model.SuppressionHardBounce, found.Reason)
+
+	suppressions, err := repo.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, suppressions, 1)
+}
+
+func TestSuppressionRepository_List(t *testing.T) {
+	repo, cleanup := setupTestSuppressionRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	for i, recipient := range []string{"first@example.com", "second@example.com", "third@example.com"} {
+		suppression := model.NewSuppression(recipient, model.SuppressionComplaint, "sendgrid", "")
+		suppression.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Save(ctx, suppression))
+	}
+
+	// Newest first
+	suppressions, err := repo.List(ctx, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, suppressions, 2)
+	assert.Equal(t, "third@example.com", suppressions[0].Recipient)
+	assert.Equal(t, "second@example.com", suppressions[1].Recipient)
+
+	suppressions, err = repo.List(ctx, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, suppressions, 1)
+	assert.Equal(t, "first@example.com", suppressions[0].Recipient)
+}
+
+func TestSuppressionRepository_Delete(t *testing.T) {
+	repo, cleanup := setupTestSuppressionRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, repo.Save(ctx, model.NewSuppression("user@example.com", model.SuppressionHardBounce, "ses", "")))
+
+	require.NoError(t, repo.Delete(ctx, "User@Example.com"))
+
+	found, err := repo.FindByRecipient(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	suppressions, err := repo.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, suppressions)
+
+	err = repo.Delete(ctx, "user@example.com")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+}
+
+func TestSuppressionRepository_TenantIsolation(t *testing.T) {
+	repo, cleanup := setupTestSuppressionRepo(t)
+	defer cleanup()
+
+	acme := model.ContextWithTenant(context.Background(), "acme")
+	globex := model.ContextWithTenant(context.Background(), "globex")
+
+	require.NoError(t, repo.Save(acme, model.NewSuppression("user@example.com", model.SuppressionHardBounce, "ses", "")))
+
+	found, err := repo.FindByRecipient(globex, "user@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	suppressions, err := repo.List(globex, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, suppressions)
+
+	assert.ErrorIs(t, repo.Delete(globex, "user@example.com"), model.ErrNotFound)
+
+	found, err = repo.FindByRecipient(acme, "user@example.com")
+	require.NoError(t, err)
+	assert.NotNil(t, found)
+}
@@ -6,26 +6,56 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/mibrahim2344/notification-service/internal/domain/model"
 	"github.com/mibrahim2344/notification-service/internal/infrastructure/metrics"
+	"github.com/redis/go-redis/v9"
 )
 
+// Template keys put the tenant in a hash tag, {tenant}, so all of a tenant's
+// template keys hash to one Redis Cluster slot and Update's transaction, which
+// touches the template, its history and the tenant's type and name indexes,
+// can run as a single MULTI/EXEC. Tenants have few templates, so this doesn't
+// unbalance the cluster.
 const (
-	templateKeyPrefix     = "template:"
-	templateTypeKeyPrefix = "template:type:"
+	templateKeyPrefix         = "template:"
+	templateTypeKeyPrefix     = "template:type:"
+	templateNameKeyPrefix     = "template:name:"
+	templateVersionsKeyPrefix = "template:versions:"
 )
 
+// templateKey builds the key holding a tenant's template data
+func (r *TemplateRepository) templateKey(tenantID, id string) string {
+	return fmt.Sprintf("%s%s{%s}:%s", r.namespace, templateKeyPrefix, tenantID, id)
+}
+
+// templateTypeKey builds the key of a tenant's per-type template index
+func (r *TemplateRepository) templateTypeKey(tenantID string, templateType model.TemplateType) string {
+	return fmt.Sprintf("%s%s{%s}:%s", r.namespace, templateTypeKeyPrefix, tenantID, templateType)
+}
+
+// templateNameKey builds the key mapping a tenant's template name to its ID
+func (r *TemplateRepository) templateNameKey(tenantID, name string) string {
+	return fmt.Sprintf("%s%s{%s}:%s", r.namespace, templateNameKeyPrefix, tenantID, name)
+}
+
+// templateVersionsKey builds the key of a template's version history, newest first
+func (r *TemplateRepository) templateVersionsKey(tenantID, id string) string {
+	return fmt.Sprintf("%s%s{%s}:%s", r.namespace, templateVersionsKeyPrefix, tenantID, id)
+}
+
 // TemplateRepository implements repository.TemplateRepository using Redis
 type TemplateRepository struct {
-	client *redis.Client
+	client    redis.UniversalClient
+	namespace string
 }
 
-// NewTemplateRepository creates a new Redis-based template repository
-func NewTemplateRepository(client *redis.Client) *TemplateRepository {
+// NewTemplateRepository creates a new Redis-based template repository whose
+// keys are prefixed with namespace, see Namespace
+func NewTemplateRepository(client redis.UniversalClient, namespace string) *TemplateRepository {
 	return &TemplateRepository{
-		client: client,
+		client:    client,
+		namespace: Namespace(namespace),
 	}
 }
 
@@ -42,6 +72,12 @@ func (r *TemplateRepository) Save(ctx context.Context, template *model.Template)
 		metrics.RecordOperationDuration("redis_save_template", status, duration)
 	}()
 
+	tenantID, err := model.ResolveTenantID(ctx, template.TenantID)
+	if err != nil {
+		return err
+	}
+	template.TenantID = tenantID
+
 	// Marshal template to JSON
 	data, err := json.Marshal(template)
 	if err != nil {
@@ -53,12 +89,11 @@ func (r *TemplateRepository) Save(ctx context.Context, template *model.Template)
 	pipe := r.client.Pipeline()
 
 	// Save template data
-	key := fmt.Sprintf("%s%s", templateKeyPrefix, template.ID.String())
-	pipe.Set(ctx, key, data, 0)
+	pipe.Set(ctx, r.templateKey(tenantID, template.ID.String()), data, 0)
 
-	// Add to type index
-	typeKey := fmt.Sprintf("%s%s", templateTypeKeyPrefix, template.Type)
-	pipe.SAdd(ctx, typeKey, template.ID.String())
+	// Add to type and name indexes
+	pipe.SAdd(ctx, r.templateTypeKey(tenantID, template.Type), template.ID.String())
+	pipe.Set(ctx, r.templateNameKey(tenantID, template.Name), template.ID.String(), 0)
 
 	// Execute transaction
 	_, err = pipe.Exec(ctx)
@@ -83,7 +118,7 @@ func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model
 		metrics.RecordOperationDuration("redis_find_template_by_id", status, duration)
 	}()
 
-	key := fmt.Sprintf("%s%s", templateKeyPrefix, id.String())
+	key := r.templateKey(model.TenantFromContext(ctx), id.String())
 	data, err := r.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if err == redis.Nil {
@@ -102,6 +137,44 @@ func (r *TemplateRepository) FindByID(ctx context.Context, id uuid.UUID) (*model
 	return &template, nil
 }
 
+// FindByName finds the active template with the given name from Redis
+func (r *TemplateRepository) FindByName(ctx context.Context, name string) (*model.Template, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_find_template_by_name", status, duration)
+	}()
+
+	id, err := r.client.Get(ctx, r.templateNameKey(model.TenantFromContext(ctx), name)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			err = nil
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get template ID: %w", err)
+	}
+
+	templateID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template ID in name index: %w", err)
+	}
+
+	template, err := r.FindByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil || !template.IsActive {
+		return nil, nil
+	}
+
+	return template, nil
+}
+
 // FindByType finds templates by type from Redis
 func (r *TemplateRepository) FindByType(ctx context.Context, templateType model.TemplateType) ([]*model.Template, error) {
 	start := time.Now()
@@ -115,7 +188,7 @@ func (r *TemplateRepository) FindByType(ctx context.Context, templateType model.
 		metrics.RecordOperationDuration("redis_find_templates_by_type", status, duration)
 	}()
 
-	typeKey := fmt.Sprintf("%s%s", templateTypeKeyPrefix, templateType)
+	typeKey := r.templateTypeKey(model.TenantFromContext(ctx), templateType)
 	templateIDs, err := r.client.SMembers(ctx, typeKey).Result()
 	if err != nil {
 		metrics.RecordOperationDuration("redis_find_templates_by_type", "error", time.Since(start).Seconds())
@@ -164,7 +237,43 @@ func (r *TemplateRepository) FindActiveByType(ctx context.Context, templateType
 	return activeTemplates, nil
 }
 
-// Update updates a template in Redis
+// Upsert saves a template to Redis or, if the tenant already has a template
+// with its name, updates that one as a new version. Whether the template is
+// active is left alone, so a deactivated template stays deactivated.
+func (r *TemplateRepository) Upsert(ctx context.Context, template *model.Template) (bool, error) {
+	tenantID, err := model.ResolveTenantID(ctx, template.TenantID)
+	if err != nil {
+		return false, err
+	}
+	template.TenantID = tenantID
+
+	id, err := r.client.Get(ctx, r.templateNameKey(tenantID, template.Name)).Result()
+	if err != nil && err != redis.Nil {
+		return false, fmt.Errorf("failed to get template ID: %w", err)
+	}
+
+	var existing *model.Template
+	if err == nil {
+		templateID, err := uuid.Parse(id)
+		if err != nil {
+			return false, fmt.Errorf("invalid template ID in name index: %w", err)
+		}
+		if existing, err = r.FindByID(ctx, templateID); err != nil {
+			return false, err
+		}
+	}
+
+	if existing == nil {
+		return true, r.Save(ctx, template)
+	}
+
+	template.ID = existing.ID
+	template.IsActive = existing.IsActive
+	template.CreatedAt = existing.CreatedAt
+	return false, r.Update(ctx, template)
+}
+
+// Update updates a template in Redis, pushing the version it replaces onto the template's history
 func (r *TemplateRepository) Update(ctx context.Context, template *model.Template) error {
 	start := time.Now()
 	var err error
@@ -177,13 +286,188 @@ func (r *TemplateRepository) Update(ctx context.Context, template *model.Templat
 		metrics.RecordOperationDuration("redis_update_template", status, duration)
 	}()
 
+	tenantID, err := model.ResolveTenantID(ctx, template.TenantID)
+	if err != nil {
+		return err
+	}
+	template.TenantID = tenantID
+
+	current, err := r.FindByID(ctx, template.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		err = model.ErrTemplateNotFound{ID: template.ID.String()}
+		return err
+	}
+
+	ownsOldName, err := r.ownsName(ctx, tenantID, current.Name, template.ID)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := json.Marshal(current.Snapshot())
+	if err != nil {
+		return fmt.Errorf("failed to marshal template version: %w", err)
+	}
+
 	// Increment version
-	template.Version++
+	template.Version = current.Version + 1
 	template.UpdatedAt = time.Now()
 
-	// Save updated template
-	err = r.Save(ctx, template)
-	return err
+	data, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template: %w", err)
+	}
+
+	// Create a transaction
+	pipe := r.client.TxPipeline()
+
+	// Keep the replaced version
+	pipe.LPush(ctx, r.templateVersionsKey(tenantID, template.ID.String()), snapshot)
+
+	// Save template data
+	pipe.Set(ctx, r.templateKey(tenantID, template.ID.String()), data, 0)
+
+	// Move the template between type indexes if its type changed
+	if current.Type != template.Type {
+		pipe.SRem(ctx, r.templateTypeKey(tenantID, current.Type), template.ID.String())
+	}
+	pipe.SAdd(ctx, r.templateTypeKey(tenantID, template.Type), template.ID.String())
+
+	// Drop the old name mapping on rename so the old name stops resolving
+	if current.Name != template.Name && ownsOldName {
+		pipe.Del(ctx, r.templateNameKey(tenantID, current.Name))
+	}
+	pipe.Set(ctx, r.templateNameKey(tenantID, template.Name), template.ID.String(), 0)
+
+	// Execute transaction
+	if _, err = pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplateVersions lists a template's previous versions from Redis, newest first
+func (r *TemplateRepository) GetTemplateVersions(ctx context.Context, id uuid.UUID) ([]*model.TemplateVersion, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_get_template_versions", status, duration)
+	}()
+
+	key := r.templateVersionsKey(model.TenantFromContext(ctx), id.String())
+	entries, err := r.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template versions: %w", err)
+	}
+
+	versions := make([]*model.TemplateVersion, 0, len(entries))
+	for _, entry := range entries {
+		var version model.TemplateVersion
+		if err = json.Unmarshal([]byte(entry), &version); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal template version: %w", err)
+		}
+		versions = append(versions, &version)
+	}
+
+	// A template that was never updated has no history; tell it apart from an unknown one
+	if len(versions) == 0 {
+		template, err := r.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if template == nil {
+			return nil, model.ErrTemplateNotFound{ID: id.String()}
+		}
+	}
+
+	return versions, nil
+}
+
+// RollbackTemplate restores a previous version's content in Redis as a new current version
+func (r *TemplateRepository) RollbackTemplate(ctx context.Context, id uuid.UUID, version int) (*model.Template, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_rollback_template", status, duration)
+	}()
+
+	versions, err := r.GetTemplateVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *model.TemplateVersion
+	for _, v := range versions {
+		if v.Version == version {
+			previous = v
+			break
+		}
+	}
+	if previous == nil {
+		err = model.ErrTemplateVersionNotFound{ID: id.String(), Version: version}
+		return nil, err
+	}
+
+	template, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		err = model.ErrTemplateNotFound{ID: id.String()}
+		return nil, err
+	}
+
+	template.Restore(previous)
+	if err = r.Update(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// SetTemplateActive activates or deactivates a template in Redis as a new version
+func (r *TemplateRepository) SetTemplateActive(ctx context.Context, id uuid.UUID, active bool) (*model.Template, error) {
+	start := time.Now()
+	var err error
+	defer func() {
+		duration := time.Since(start).Seconds()
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOperationDuration("redis_set_template_active", status, duration)
+	}()
+
+	template, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		err = model.ErrTemplateNotFound{ID: id.String()}
+		return nil, err
+	}
+
+	if err = template.SetActive(active); err != nil {
+		return nil, err
+	}
+	if err = r.Update(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
 }
 
 // Delete deletes a template from Redis
@@ -208,16 +492,24 @@ func (r *TemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		return nil
 	}
 
+	ownsName, err := r.ownsName(ctx, template.TenantID, template.Name, id)
+	if err != nil {
+		return err
+	}
+
 	// Create a transaction
 	pipe := r.client.Pipeline()
 
-	// Delete template data
-	key := fmt.Sprintf("%s%s", templateKeyPrefix, id.String())
-	pipe.Del(ctx, key)
+	// Remove the name mapping unless another template has since taken the name
+	if ownsName {
+		pipe.Del(ctx, r.templateNameKey(template.TenantID, template.Name))
+	}
+
+	// Delete template data and its history
+	pipe.Del(ctx, r.templateKey(template.TenantID, id.String()), r.templateVersionsKey(template.TenantID, id.String()))
 
 	// Remove from type index
-	typeKey := fmt.Sprintf("%s%s", templateTypeKeyPrefix, template.Type)
-	pipe.SRem(ctx, typeKey, id.String())
+	pipe.SRem(ctx, r.templateTypeKey(template.TenantID, template.Type), id.String())
 
 	// Execute transaction
 	_, err = pipe.Exec(ctx)
@@ -228,3 +520,49 @@ func (r *TemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// ownsName reports whether a tenant's name index currently maps name to the given template
+func (r *TemplateRepository) ownsName(ctx context.Context, tenantID, name string, id uuid.UUID) (bool, error) {
+	mapped, err := r.client.Get(ctx, r.templateNameKey(tenantID, name)).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get template ID: %w", err)
+	}
+	return mapped == id.String(), nil
+}
+
+// ProcessTemplate renders a template with given data, including the partial
+// templates it names from the same tenant; see model.Template.RenderWithIncludes
+func (r *TemplateRepository) ProcessTemplate(ctx context.Context, templateName string, data interface{}) (string, error) {
+	// Find the template by name
+	template, err := r.FindByName(ctx, templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find template: %w", err)
+	}
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
+
+	content, err := template.RenderWithIncludes(ctx, data, func(name string) (*model.Template, error) {
+		return r.FindByName(ctx, name)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error rendering template %s: %w", templateName, err)
+	}
+	return content, nil
+}
+
+// GetTemplate retrieves a template by name and locale
+func (r *TemplateRepository) GetTemplate(ctx context.Context, templateName, locale string) (string, error) {
+	template, err := r.FindByName(ctx, templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find template: %w", err)
+	}
+	if template == nil {
+		return "", model.ErrTemplateNotFound{ID: templateName}
+	}
+
+	return template.Content, nil
+}
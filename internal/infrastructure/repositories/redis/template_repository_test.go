@@ -0,0 +1,398 @@
+package redis
+
+import (
+	"context"
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/mibrahim2344/notification-service/internal/domain/model"
+	"github.com/mibrahim2344/notification-service/internal/domain/repository"
+	"github.com/mibrahim2344/notification-service/internal/domain/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The Redis repository must be usable wherever the domain interfaces are expected
+var (
+	_ repository.TemplateRepository = (*TemplateRepository)(nil)
+	_ services.TemplateEngine       = (*TemplateRepository)(nil)
+)
+
+func setupTestTemplateRepo(t *testing.T) (*TemplateRepository, func()) {
+	// Create a miniredis server
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	// Create Redis client connected to miniredis
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	repo := NewTemplateRepository(client, "")
+
+	cleanup := func() {
+		client.Close()
+		mr.Close()
+	}
+
+	return repo, cleanup
+}
+
+func TestTemplateRepository_FindByName(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	welcome := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	reset := model.NewTemplate("reset", model.PasswordReset, "Reset", "Reset your password")
+	inactive := model.NewTemplate("retired", model.WelcomeEmail, "Old", "Old content")
+	inactive.IsActive = false
+	for _, template := range []*model.Template{welcome, reset, inactive} {
+		require.NoError(t, repo.Save(ctx, template))
+	}
+
+	found, err := repo.FindByName(ctx, "reset")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, reset.ID, found.ID)
+
+	// Inactive and unknown templates are not found
+	found, err = repo.FindByName(ctx, "retired")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	found, err = repo.FindByName(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	// Names resolve within the caller's tenant only
+	found, err = repo.FindByName(model.ContextWithTenant(ctx, "other"), "welcome")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestTemplateRepository_NameIndex(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	require.NoError(t, repo.Save(ctx, template))
+
+	mapped, err := repo.client.Get(ctx, repo.templateNameKey(model.DefaultTenantID, "welcome")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, template.ID.String(), mapped)
+
+	// Renaming removes the old mapping
+	template.Name = "greeting"
+	require.NoError(t, repo.Update(ctx, template))
+
+	found, err := repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	exists, err := repo.client.Exists(ctx, repo.templateNameKey(model.DefaultTenantID, "welcome")).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+
+	found, err = repo.FindByName(ctx, "greeting")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, template.ID, found.ID)
+
+	// Deleting removes the mapping
+	require.NoError(t, repo.Delete(ctx, template.ID))
+	found, err = repo.FindByName(ctx, "greeting")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	exists, err = repo.client.Exists(ctx, repo.templateNameKey(model.DefaultTenantID, "greeting")).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestTemplateRepository_GetTemplate(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, repo.Save(ctx, model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")))
+
+	content, err := repo.GetTemplate(ctx, "welcome", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", content)
+
+	_, err = repo.GetTemplate(ctx, "missing", "en")
+	assert.ErrorAs(t, err, &model.ErrTemplateNotFound{})
+}
+
+func TestTemplateRepository_ProcessTemplate(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	content := `<p>Hello {{.Username}}</p><a href="/u/{{.Username}}">profile</a>`
+	require.NoError(t, repo.Save(ctx, model.NewTemplate("welcome.html", model.WelcomeEmail, "Welcome", content)))
+
+	t.Run("user data is escaped", func(t *testing.T) {
+		rendered, err := repo.ProcessTemplate(ctx, "welcome.html", map[string]interface{}{
+			"Username": `<script>alert("x")</script>`,
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, rendered, "<script>")
+		assert.Contains(t, rendered, "<p>Hello &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</p>")
+		assert.Contains(t, rendered, `href="/u/%3cscript%3ealert%28%22x%22%29%3c/script%3e"`)
+	})
+
+	t.Run("pre-escaped data is rejected", func(t *testing.T) {
+		_, err := repo.ProcessTemplate(ctx, "welcome.html", map[string]interface{}{
+			"Username": htmltemplate.HTML("<script>alert(1)</script>"),
+		})
+		var unsafe model.ErrUnsafeTemplateData
+		require.ErrorAs(t, err, &unsafe)
+		assert.Equal(t, "data.Username", unsafe.Field)
+		assert.ErrorIs(t, err, model.ErrValidation)
+	})
+
+	t.Run("partials are included by name", func(t *testing.T) {
+		require.NoError(t, repo.Save(ctx, model.NewTemplate("layout.html", model.WelcomeEmail, "Layout", `<main>{{template "body" .}}</main>`)))
+		require.NoError(t, repo.Save(ctx, model.NewTemplate("reset.html", model.PasswordReset, "Reset",
+			`{{define "body"}}Hi {{.Username}}{{end}}{{template "layout.html" .}}`)))
+
+		rendered, err := repo.ProcessTemplate(ctx, "reset.html", map[string]interface{}{"Username": "jane"})
+		require.NoError(t, err)
+		assert.Equal(t, "<main>Hi jane</main>", rendered)
+
+		require.NoError(t, repo.Save(ctx, model.NewTemplate("orphan.html", model.WelcomeEmail, "Orphan", `{{template "missing.html" .}}`)))
+		_, err = repo.ProcessTemplate(ctx, "orphan.html", nil)
+		assert.ErrorAs(t, err, &model.ErrTemplateIncludeNotFound{})
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		broken := model.NewTemplate("broken.html", model.WelcomeEmail, "Welcome", `{{.Username | safeHTML}}`)
+		assert.ErrorAs(t, broken.Validate(), &model.ErrInvalidTemplate{})
+	})
+}
+
+func TestTemplateRepository_NameIndexKeepsNewOwner(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	original := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	require.NoError(t, repo.Save(ctx, original))
+
+	// A second template takes over the name
+	replacement := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v2")
+	require.NoError(t, repo.Save(ctx, replacement))
+
+	// Renaming or deleting the original must not unmap the replacement
+	original.Name = "welcome-old"
+	require.NoError(t, repo.Update(ctx, original))
+	require.NoError(t, repo.Delete(ctx, original.ID))
+
+	found, err := repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, replacement.ID, found.ID)
+}
+
+func TestTemplateRepository_Upsert(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	original := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	created, err := repo.Upsert(ctx, original)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// Deactivated templates are updated by name and stay deactivated
+	original.IsActive = false
+	require.NoError(t, repo.Update(ctx, original))
+
+	replacement := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v3")
+	created, err = repo.Upsert(ctx, replacement)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, original.ID, replacement.ID)
+	assert.Equal(t, 3, replacement.Version)
+
+	found, err := repo.FindByID(ctx, original.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Hello v3", found.Content)
+	assert.False(t, found.IsActive)
+
+	versions, err := repo.GetTemplateVersions(ctx, original.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Hello v1", versions[0].Content)
+
+	// Names are upserted within the caller's tenant only
+	other := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	created, err = repo.Upsert(model.ContextWithTenant(ctx, "other"), other)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEqual(t, original.ID, other.ID)
+}
+
+func TestTemplateRepository_Versions(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	require.NoError(t, repo.Save(ctx, template))
+
+	// A template that was never updated has an empty history
+	versions, err := repo.GetTemplateVersions(ctx, template.ID)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	template.Content = "Hello v2"
+	require.NoError(t, repo.Update(ctx, template))
+	assert.Equal(t, 2, template.Version)
+
+	template.Content = "Hello v3"
+	require.NoError(t, repo.Update(ctx, template))
+	assert.Equal(t, 3, template.Version)
+
+	// Previous versions are listed newest first
+	versions, err = repo.GetTemplateVersions(ctx, template.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Equal(t, "Hello v2", versions[0].Content)
+	assert.Equal(t, 1, versions[1].Version)
+	assert.Equal(t, "Hello v1", versions[1].Content)
+
+	// Unknown templates are reported as not found
+	_, err = repo.GetTemplateVersions(ctx, uuid.New())
+	assert.ErrorAs(t, err, &model.ErrTemplateNotFound{})
+}
+
+func TestTemplateRepository_RollbackTemplate(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	require.NoError(t, repo.Save(ctx, template))
+
+	template.Subject = "Welcome aboard"
+	template.Content = "Hello v2"
+	require.NoError(t, repo.Update(ctx, template))
+
+	// Rolling back stores the old content as a new version
+	restored, err := repo.RollbackTemplate(ctx, template.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Version)
+	assert.Equal(t, "Welcome", restored.Subject)
+	assert.Equal(t, "Hello v1", restored.Content)
+
+	stored, err := repo.FindByID(ctx, template.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Version)
+	assert.Equal(t, "Hello v1", stored.Content)
+
+	// The rolled-back version stays in the history
+	versions, err := repo.GetTemplateVersions(ctx, template.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Hello v2", versions[0].Content)
+
+	// Versions that were never stored cannot be restored
+	_, err = repo.RollbackTemplate(ctx, template.ID, 7)
+	assert.ErrorAs(t, err, &model.ErrTemplateVersionNotFound{})
+}
+
+func TestTemplateRepository_SetTemplateActive(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	require.NoError(t, repo.Save(ctx, template))
+
+	// Deactivating stores a new version
+	deactivated, err := repo.SetTemplateActive(ctx, template.ID, false)
+	require.NoError(t, err)
+	assert.False(t, deactivated.IsActive)
+	assert.Equal(t, 2, deactivated.Version)
+
+	stored, err := repo.FindByID(ctx, template.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.IsActive)
+	assert.Equal(t, 2, stored.Version)
+
+	// A template already in the requested state is a conflict
+	_, err = repo.SetTemplateActive(ctx, template.ID, false)
+	assert.ErrorIs(t, err, model.ErrConflict)
+
+	activated, err := repo.SetTemplateActive(ctx, template.ID, true)
+	require.NoError(t, err)
+	assert.True(t, activated.IsActive)
+	assert.Equal(t, 3, activated.Version)
+
+	_, err = repo.SetTemplateActive(ctx, uuid.New(), true)
+	assert.ErrorAs(t, err, &model.ErrTemplateNotFound{})
+}
+
+func TestTemplateRepository_UpdateNotFound(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	err := repo.Update(context.Background(), template)
+	assert.ErrorAs(t, err, &model.ErrTemplateNotFound{})
+}
+
+func TestTemplateRepository_DeleteRemovesHistory(t *testing.T) {
+	repo, cleanup := setupTestTemplateRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello v1")
+	require.NoError(t, repo.Save(ctx, template))
+
+	template.Content = "Hello v2"
+	require.NoError(t, repo.Update(ctx, template))
+	require.NoError(t, repo.Delete(ctx, template.ID))
+
+	exists, err := repo.client.Exists(ctx, repo.templateVersionsKey(model.DefaultTenantID, template.ID.String())).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestTemplateRepository_Namespace(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	repo := NewTemplateRepository(client, "prod")
+	ctx := context.Background()
+	template := model.NewTemplate("welcome", model.WelcomeEmail, "Welcome", "Hello")
+	require.NoError(t, repo.Save(ctx, template))
+	template.Content = "Hello again"
+	require.NoError(t, repo.Update(ctx, template))
+
+	keys := mr.Keys()
+	require.NotEmpty(t, keys)
+	for _, key := range keys {
+		assert.True(t, strings.HasPrefix(key, "prod:template:"), key)
+	}
+
+	found, err := repo.FindByName(ctx, "welcome")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Hello again", found.Content)
+
+	found, err = NewTemplateRepository(client, "staging").FindByName(ctx, "welcome")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
@@ -0,0 +1,9 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_notifications_tenant_recipient;
+DROP INDEX IF EXISTS idx_notifications_tenant_id;
+DROP INDEX IF EXISTS idx_templates_tenant_name;
+DROP INDEX IF EXISTS idx_templates_tenant_type;
+
+-- Drop columns
+ALTER TABLE notifications DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE templates DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,9 @@
+-- Scope templates and notifications to a tenant
+ALTER TABLE templates ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Create composite indexes so every tenant-filtered lookup stays indexed
+CREATE INDEX IF NOT EXISTS idx_templates_tenant_type ON templates(tenant_id, type);
+CREATE INDEX IF NOT EXISTS idx_templates_tenant_name ON templates(tenant_id, name);
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_id ON notifications(tenant_id, id);
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_recipient ON notifications(tenant_id, recipient, created_at DESC);
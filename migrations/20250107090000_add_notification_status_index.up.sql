@@ -0,0 +1,2 @@
+-- Index tenant-scoped status lookups used by the admin notifications view
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_status ON notifications(tenant_id, status, created_at DESC);
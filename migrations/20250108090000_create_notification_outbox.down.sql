@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_notification_outbox_pending;
+
+-- Drop tables
+DROP TABLE IF EXISTS notification_outbox;
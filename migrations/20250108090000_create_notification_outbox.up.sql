@@ -0,0 +1,16 @@
+-- Create outbox table: a row is written in the same transaction as its
+-- notification and removed from the pending set once delivery was attempted
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Index the dispatcher's claim query
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_pending ON notification_outbox(available_at) WHERE status = 'pending';
@@ -0,0 +1,12 @@
+-- Create template history table: each update snapshots the version it replaces
+CREATE TABLE IF NOT EXISTS template_versions (
+    id BIGSERIAL PRIMARY KEY,
+    template_id UUID NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    version INTEGER NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    variables JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (template_id, version)
+);
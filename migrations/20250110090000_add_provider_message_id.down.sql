@@ -0,0 +1,5 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_notifications_provider_message_id;
+
+-- Drop column
+ALTER TABLE notifications DROP COLUMN IF EXISTS provider_message_id;
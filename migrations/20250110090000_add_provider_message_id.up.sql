@@ -0,0 +1,5 @@
+-- Store the ID each provider assigned a message so delivery webhooks can be correlated
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS provider_message_id VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Webhook lookups are by message ID alone, across tenants
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_provider_message_id ON notifications(provider_message_id) WHERE provider_message_id <> '';
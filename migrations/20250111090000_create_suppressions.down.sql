@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_suppressions_tenant_created_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS suppressions;
@@ -0,0 +1,14 @@
+-- Create suppression list: email recipients that hard bounced or complained,
+-- stored lowercased so lookups match regardless of case
+CREATE TABLE IF NOT EXISTS suppressions (
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    recipient VARCHAR(255) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    provider VARCHAR(50) NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, recipient)
+);
+
+-- Index the newest-first listing
+CREATE INDEX IF NOT EXISTS idx_suppressions_tenant_created_at ON suppressions(tenant_id, created_at DESC);
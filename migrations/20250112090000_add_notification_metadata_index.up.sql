@@ -0,0 +1,2 @@
+-- Index metadata containment queries, e.g. finding a user's notifications by metadata userId
+CREATE INDEX IF NOT EXISTS idx_notifications_metadata ON notifications USING GIN (metadata jsonb_path_ops);
@@ -0,0 +1,2 @@
+-- Index the admin stats window so counts by status, type and priority are served from the index
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_created_at ON notifications(tenant_id, created_at) INCLUDE (status, type, priority);
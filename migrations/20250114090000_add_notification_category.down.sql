@@ -0,0 +1,6 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_notifications_tenant_category;
+
+-- Drop columns
+ALTER TABLE notifications DROP COLUMN IF EXISTS tags;
+ALTER TABLE notifications DROP COLUMN IF EXISTS category;
@@ -0,0 +1,10 @@
+-- Give notifications a first-class category and tags instead of overloading metadata
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS category VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS tags TEXT[];
+
+-- Carry over categories previously kept in metadata
+UPDATE notifications SET category = metadata->>'category'
+WHERE category = '' AND metadata ? 'category' AND length(metadata->>'category') <= 64;
+
+-- Category searches page newest first within a tenant
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_category ON notifications(tenant_id, category, created_at DESC) WHERE category <> '';
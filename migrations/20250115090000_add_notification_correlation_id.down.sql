@@ -0,0 +1,5 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_notifications_tenant_correlation_id;
+
+-- Drop column
+ALTER TABLE notifications DROP COLUMN IF EXISTS correlation_id;
@@ -0,0 +1,5 @@
+-- Tie notifications to the HTTP request or event that produced them
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(128) NOT NULL DEFAULT '';
+
+-- Correlation lookups list a request's notifications within a tenant
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_correlation_id ON notifications(tenant_id, correlation_id, created_at) WHERE correlation_id <> '';
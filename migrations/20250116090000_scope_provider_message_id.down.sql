@@ -0,0 +1,8 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_notifications_provider_provider_message_id;
+
+-- Drop column
+ALTER TABLE notifications DROP COLUMN IF EXISTS provider;
+
+-- Restore the message ID index
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_provider_message_id ON notifications(provider_message_id) WHERE provider_message_id <> '';
@@ -0,0 +1,6 @@
+-- Record which provider sent each notification; message IDs are only unique per provider
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS provider VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Webhook lookups are by provider and message ID, across tenants
+DROP INDEX IF EXISTS idx_notifications_provider_message_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_provider_provider_message_id ON notifications(provider, provider_message_id) WHERE provider_message_id <> '';
@@ -0,0 +1,5 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_notifications_tenant_group_id;
+
+-- Drop column
+ALTER TABLE notifications DROP COLUMN IF EXISTS group_id;
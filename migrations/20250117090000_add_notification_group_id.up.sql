@@ -0,0 +1,5 @@
+-- Tie the notifications of a multi-channel send together
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS group_id VARCHAR(36) NOT NULL DEFAULT '';
+
+-- Group lookups list a multi-channel send's notifications within a tenant
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_group_id ON notifications(tenant_id, group_id, created_at) WHERE group_id <> '';
@@ -0,0 +1,3 @@
+-- Drop columns
+ALTER TABLE notifications DROP COLUMN IF EXISTS cc;
+ALTER TABLE notifications DROP COLUMN IF EXISTS content_type;
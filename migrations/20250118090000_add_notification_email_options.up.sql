@@ -0,0 +1,3 @@
+-- Let emails be sent as plain text and copied to other addresses
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS content_type VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS cc TEXT[];
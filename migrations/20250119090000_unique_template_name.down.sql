@@ -0,0 +1,5 @@
+-- Restore the non-unique name index
+CREATE INDEX IF NOT EXISTS idx_templates_tenant_name ON templates(tenant_id, name);
+
+-- Drop index
+DROP INDEX IF EXISTS idx_templates_tenant_name_unique;
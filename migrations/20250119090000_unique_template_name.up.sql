@@ -0,0 +1,6 @@
+-- Template names are unique within a tenant, which template upserts rely on.
+-- Rename any duplicate names before applying this migration.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_templates_tenant_name_unique ON templates(tenant_id, name);
+
+-- The unique index serves name lookups too
+DROP INDEX IF EXISTS idx_templates_tenant_name;
@@ -0,0 +1,2 @@
+-- Record when a notification's delivery is next attempted
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- Let emails embed images their HTML references by content ID
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS inline_images JSONB;
@@ -0,0 +1,6 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_notifications_tenant_thread_id;
+
+-- Drop columns
+ALTER TABLE notifications DROP COLUMN IF EXISTS email_message_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS thread_id;
@@ -0,0 +1,6 @@
+-- Thread related emails together in mail clients
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS thread_id VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS email_message_id VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Thread lookups list an email thread's notifications within a tenant
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_thread_id ON notifications(tenant_id, thread_id, created_at) WHERE thread_id <> '';
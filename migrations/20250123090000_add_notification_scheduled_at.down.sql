@@ -0,0 +1,2 @@
+-- Drop column
+ALTER TABLE notifications DROP COLUMN IF EXISTS scheduled_at;
@@ -0,0 +1,2 @@
+-- Record when a notification deferred to its category's send window goes out
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,13 @@
+-- Create broadcast progress: how far the fan-out of each broadcast event got,
+-- so a restarted consumer resumes a broadcast instead of starting it over
+CREATE TABLE IF NOT EXISTS broadcast_progress (
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    broadcast_id VARCHAR(128) NOT NULL,
+    cursor TEXT NOT NULL DEFAULT '',
+    enqueued INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, broadcast_id)
+);
@@ -0,0 +1,5 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS notification_outbox_notify ON notification_outbox;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS notify_notification_outbox();
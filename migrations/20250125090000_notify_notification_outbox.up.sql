@@ -0,0 +1,16 @@
+-- Announce each new outbox entry on the notification_outbox channel, with the
+-- time it becomes due as seconds since the epoch, so listening dispatchers
+-- claim it without waiting for their next poll. Postgres delivers the
+-- announcement when the inserting transaction commits, and only once per
+-- distinct due time within a transaction.
+CREATE OR REPLACE FUNCTION notify_notification_outbox() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('notification_outbox', extract(epoch FROM NEW.available_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS notification_outbox_notify ON notification_outbox;
+CREATE TRIGGER notification_outbox_notify
+    AFTER INSERT ON notification_outbox
+    FOR EACH ROW EXECUTE FUNCTION notify_notification_outbox();